@@ -76,6 +76,34 @@ func buildJobCmd() *cobra.Command {
 			},
 			InitFlags: job.InitDeleteFlags,
 		},
+		"boost": {
+			Short: "bump a pending job to the front of its queue",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, job.BoostJob(cmd.Context()))
+			},
+			InitFlags: job.InitBoostFlags,
+		},
+		"hibernate": {
+			Short: "release the resources of a job, keeping its place in its queue",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, job.HibernateJob(cmd.Context()))
+			},
+			InitFlags: job.InitHibernateFlags,
+		},
+		"wake": {
+			Short: "re-admit a hibernated job",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, job.WakeJob(cmd.Context()))
+			},
+			InitFlags: job.InitWakeFlags,
+		},
+		"migrate": {
+			Short: "move a pending or running job to another queue",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, job.MigrateJob(cmd.Context()))
+			},
+			InitFlags: job.InitMigrateFlags,
+		},
 	}
 
 	for command, config := range jobCommandMap {
@@ -62,6 +62,13 @@ func buildJobTemplateCmd() *cobra.Command {
 			},
 			InitFlags: jobtemplate.InitDeleteFlags,
 		},
+		"submit": {
+			Short: "submit a job from a jobtemplate",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, jobtemplate.SubmitJobTemplate(cmd.Context()))
+			},
+			InitFlags: jobtemplate.InitSubmitFlags,
+		},
 		"describe": {
 			Short: "describe a jobtemplate",
 			RunFunction: func(cmd *cobra.Command, args []string) {
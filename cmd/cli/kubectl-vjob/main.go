@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-vjob is the kubectl plugin inspecting the volcano jobs and their podgroups, installed
+// in the PATH it is run as "kubectl vjob".
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/component-base/cli"
+
+	"volcano.sh/volcano/cmd/cli/util"
+	"volcano.sh/volcano/pkg/cli/job"
+	"volcano.sh/volcano/pkg/cli/podgroup"
+)
+
+func main() {
+	rootCmd := cobra.Command{
+		Use:   "kubectl-vjob",
+		Short: "inspect volcano jobs",
+	}
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	commands := []struct {
+		Use         string
+		Short       string
+		RunFunction func(cmd *cobra.Command, args []string)
+		InitFlags   func(cmd *cobra.Command)
+	}{
+		{
+			Use:   "list",
+			Short: "list job information",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, job.ListJobs(cmd.Context()))
+			},
+			InitFlags: job.InitListFlags,
+		},
+		{
+			Use:   "view",
+			Short: "show job information",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, job.ViewJob(cmd.Context()))
+			},
+			InitFlags: job.InitViewFlags,
+		},
+		{
+			Use:   "explain",
+			Short: "explain why the podgroup of a job, or a podgroup, is pending",
+			RunFunction: func(cmd *cobra.Command, args []string) {
+				util.CheckError(cmd, podgroup.ExplainPodGroup(cmd.Context()))
+			},
+			InitFlags: podgroup.InitExplainFlags,
+		},
+	}
+
+	for _, command := range commands {
+		cmd := &cobra.Command{
+			Use:   command.Use,
+			Short: command.Short,
+			Run:   command.RunFunction,
+		}
+		command.InitFlags(cmd)
+		rootCmd.AddCommand(cmd)
+	}
+
+	code := cli.Run(&rootCmd)
+	os.Exit(code)
+}
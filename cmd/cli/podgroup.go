@@ -0,0 +1,43 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"volcano.sh/volcano/cmd/cli/util"
+	"volcano.sh/volcano/pkg/cli/podgroup"
+)
+
+func buildPodGroupCmd() *cobra.Command {
+	podGroupCmd := &cobra.Command{
+		Use:   "podgroup",
+		Short: "vcctl command line operation podgroup",
+	}
+
+	explainCmd := &cobra.Command{
+		Use:   "explain",
+		Short: "explain why a podgroup is pending",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckError(cmd, podgroup.ExplainPodGroup(cmd.Context()))
+		},
+	}
+	podgroup.InitExplainFlags(explainCmd)
+	podGroupCmd.AddCommand(explainCmd)
+
+	return podGroupCmd
+}
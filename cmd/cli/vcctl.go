@@ -38,6 +38,9 @@ func main() {
 	rootCmd.AddCommand(buildJobTemplateCmd())
 	rootCmd.AddCommand(buildJobFlowCmd())
 	rootCmd.AddCommand(buildPodCmd())
+	rootCmd.AddCommand(buildDefragCmd())
+	rootCmd.AddCommand(buildNodeCmd())
+	rootCmd.AddCommand(buildPodGroupCmd())
 	rootCmd.AddCommand(versionCommand())
 
 	code := cli.Run(&rootCmd)
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/errors"
@@ -41,6 +42,7 @@ const (
 	defaultPodGroupWorkers     = 5
 	defaultGCWorkers           = 1
 	defaultControllers         = "*"
+	defaultHistoryRetention    = 30 * 24 * time.Hour
 )
 
 // ServerOption is the main context object for the controllers.
@@ -85,6 +87,14 @@ type ServerOption struct {
 	// Case3: "-gc-controller,-job-controller,-jobflow-controller,-jobtemplate-controller,-pg-controller,-queue-controller"
 	// to disable specific controllers,
 	Controllers []string
+	// HistoryStore is the URL of the store the finished jobs are archived to by the history
+	// controller, e.g. file:///var/lib/volcano/history; the jobs are not archived if empty
+	HistoryStore string
+	// HistoryRetention is how long the archived jobs are kept in stores that support pruning
+	HistoryRetention time.Duration
+	// NotificationEndpoints are the only endpoints the jobs and queues may ask to be notified of
+	// the state changes of the jobs
+	NotificationEndpoints []string
 }
 
 type DecryptFunc func(c *ServerOption) error
@@ -119,6 +129,13 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet, knownControllers []string) {
 	fs.Uint32Var(&s.WorkerThreadsForGC, "worker-threads-for-gc", defaultGCWorkers, "The number of threads for recycling jobs. The larger the number, the faster the job recycling, but requires more CPU load.")
 	fs.StringSliceVar(&s.Controllers, "controllers", []string{defaultControllers}, fmt.Sprintf("Specify controller gates. Use '*' for all controllers, all knownController: %s ,and we can use "+
 		"'-' to disable controllers, e.g. \"-job-controller,-queue-controller\" to disable job and queue controllers.", knownControllers))
+	fs.StringVar(&s.HistoryStore, "history-store", "", "The URL of the store the finished jobs are archived to, e.g. file:///var/lib/volcano/history "+
+		"or http://elasticsearch:9200/volcano-history/_doc; the jobs are not archived if empty")
+	fs.DurationVar(&s.HistoryRetention, "history-retention", defaultHistoryRetention, "How long the archived jobs are kept in the history store, 0 keeps them forever")
+	fs.StringSliceVar(&s.NotificationEndpoints, "notification-endpoints", nil, "The endpoints the jobs and queues may list in their "+
+		"volcano.sh/notification-webhook annotation to be notified of the state changes of the jobs, e.g. a Slack incoming webhook "+
+		"or the topic of a Kafka REST proxy such as http://kafka-rest:8082/topics/volcano; the other endpoints are ignored and no "+
+		"notification is sent if empty")
 }
 
 // CheckOptionOrDie checks all options and returns all errors if they are invalid.
@@ -131,6 +148,10 @@ func (s *ServerOption) CheckOptionOrDie() error {
 		allErrors = append(allErrors, err)
 	}
 
+	if s.HistoryRetention < 0 {
+		allErrors = append(allErrors, fmt.Errorf("history-retention must not be negative"))
+	}
+
 	// Check leader election flag when LeaderElection is enabled.
 	leaderElectionErr := componentbaseconfigvalidation.ValidateLeaderElectionConfiguration(
 		&s.LeaderElection, field.NewPath("leaderElection")).ToAggregate()
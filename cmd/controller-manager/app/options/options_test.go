@@ -103,6 +103,7 @@ func TestAddFlags(t *testing.T) {
 		WorkerThreadsForPG:  5,
 		WorkerThreadsForGC:  1,
 		Controllers:         []string{"*"},
+		HistoryRetention:    defaultHistoryRetention,
 	}
 	expectedFeatureGates := map[featuregate.Feature]bool{features.ResourceTopology: false}
 
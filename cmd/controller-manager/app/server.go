@@ -127,6 +127,9 @@ func startControllers(config *rest.Config, opt *options.ServerOption) func(ctx c
 	controllerOpt.InheritOwnerAnnotations = opt.InheritOwnerAnnotations
 	controllerOpt.WorkerThreadsForPG = opt.WorkerThreadsForPG
 	controllerOpt.WorkerThreadsForGC = opt.WorkerThreadsForGC
+	controllerOpt.HistoryStore = opt.HistoryStore
+	controllerOpt.HistoryRetention = opt.HistoryRetention
+	controllerOpt.NotificationEndpoints = opt.NotificationEndpoints
 	controllerOpt.Config = config
 
 	return func(ctx context.Context) {
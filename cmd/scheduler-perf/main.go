@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// scheduler-perf runs the scheduler against a synthesized fake cluster and reports its
+// throughput, cycle latency and allocation quality.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/cmd/scheduler/app/options"
+	"volcano.sh/volcano/pkg/scheduler/perf"
+
+	// Import default actions/plugins.
+	_ "volcano.sh/volcano/pkg/scheduler/actions"
+	_ "volcano.sh/volcano/pkg/scheduler/plugins"
+)
+
+func main() {
+	klog.InitFlags(nil)
+
+	c := &perf.Config{}
+	var confFile string
+	fs := pflag.CommandLine
+	fs.IntVar(&c.Nodes, "nodes", 100, "Number of nodes to synthesize")
+	fs.StringVar(&c.NodeCPU, "node-cpu", "32", "Allocatable cpu of each node")
+	fs.StringVar(&c.NodeMemory, "node-memory", "128Gi", "Allocatable memory of each node")
+	fs.IntVar(&c.Jobs, "jobs", 100, "Number of jobs to synthesize")
+	fs.IntVar(&c.TasksPerJob, "tasks-per-job", 10, "Number of pods of each job, all required by its gang")
+	fs.StringVar(&c.TaskCPU, "task-cpu", "1", "Requested cpu of each pod")
+	fs.StringVar(&c.TaskMemory, "task-memory", "2Gi", "Requested memory of each pod")
+	fs.IntVar(&c.Cycles, "cycles", 10, "Number of scheduling cycles to run")
+	fs.StringVar(&confFile, "scheduler-conf", "", "The absolute path of scheduler configuration file, the default configuration if empty")
+	pflag.Parse()
+
+	if confFile != "" {
+		data, err := os.ReadFile(confFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read scheduler conf: %v\n", err)
+			os.Exit(1)
+		}
+		c.SchedulerConf = string(data)
+	}
+
+	options.Default()
+	report, err := perf.Run(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	report.Print(os.Stdout)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// scheduler-replay replays the events recorded by the scheduler with --record-events-file through
+// the scheduler offline and prints its decisions, e.g.
+//
+//	vc-scheduler-replay --scheduler-conf /etc/volcano/scheduler.conf /var/log/volcano/events.json
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/cmd/scheduler/app/options"
+	"volcano.sh/volcano/pkg/scheduler/replay"
+	"volcano.sh/volcano/pkg/scheduler/snapshot"
+
+	// Import default actions/plugins.
+	_ "volcano.sh/volcano/pkg/scheduler/actions"
+	_ "volcano.sh/volcano/pkg/scheduler/plugins"
+)
+
+func main() {
+	klog.InitFlags(nil)
+
+	var confFile, schedulerName, snapshotFile string
+	fs := pflag.CommandLine
+	fs.StringVar(&confFile, "scheduler-conf", "", "The absolute path of scheduler configuration file, the default configuration if empty")
+	fs.StringVar(&snapshotFile, "snapshot", "", "The cache snapshot the recording started from, dumped by the scheduler on SIGUSR1")
+	fs.StringVar(&schedulerName, "scheduler-name", "volcano", "The name of the scheduler which recorded the events")
+	pflag.Parse()
+
+	if pflag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: vc-scheduler-replay [flags] RECORDING\n")
+		os.Exit(1)
+	}
+
+	var schedulerConf string
+	if confFile != "" {
+		data, err := os.ReadFile(confFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read scheduler conf: %v\n", err)
+			os.Exit(1)
+		}
+		schedulerConf = string(data)
+	}
+
+	var from *snapshot.Snapshot
+	if snapshotFile != "" {
+		sf, err := os.Open(snapshotFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open snapshot: %v\n", err)
+			os.Exit(1)
+		}
+		from, err = snapshot.Read(sf)
+		sf.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read snapshot: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	f, err := os.Open(pflag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open recording: %v\n", err)
+		os.Exit(1)
+	}
+
+	options.Default()
+	results, err := replay.ReplayFromSnapshot(from, f, schedulerName, schedulerConf)
+	f.Close()
+	replay.Print(os.Stdout, results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
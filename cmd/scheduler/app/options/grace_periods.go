@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// NeverEvict is the grace period of the priority bands whose pods are never chosen as victims.
+const NeverEvict = "never"
+
+// GracePeriodBand is the eviction grace period of the victims whose priority is at least
+// MinPriority and lower than the MinPriority of the next band.
+type GracePeriodBand struct {
+	MinPriority int32
+	// GracePeriod is nil if the victims of the band are never evicted
+	GracePeriod *time.Duration
+}
+
+// GracePeriodBands are the bands of eviction grace periods, by decreasing MinPriority.
+type GracePeriodBands []GracePeriodBand
+
+// ParseGracePeriodBands parses the grace periods by lowest priority of their band, each a
+// duration or NeverEvict.
+func ParseGracePeriodBands(periods map[string]string) (GracePeriodBands, error) {
+	var bands GracePeriodBands
+	for priority, period := range periods {
+		minPriority, err := strconv.ParseInt(priority, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority %q of eviction grace period: %v", priority, err)
+		}
+		band := GracePeriodBand{MinPriority: int32(minPriority)}
+		if period != NeverEvict {
+			grace, err := time.ParseDuration(period)
+			if err != nil || grace < 0 {
+				return nil, fmt.Errorf("invalid eviction grace period %q of priority %s, must be a non negative duration or %s", period, priority, NeverEvict)
+			}
+			band.GracePeriod = &grace
+		}
+		bands = append(bands, band)
+	}
+	sort.Slice(bands, func(i, j int) bool {
+		return bands[i].MinPriority > bands[j].MinPriority
+	})
+	return bands, nil
+}
+
+// Band returns the band of the priority, nil if the priority is lower than all bands.
+func (b GracePeriodBands) Band(priority int32) *GracePeriodBand {
+	for i := range b {
+		if priority >= b[i].MinPriority {
+			return &b[i]
+		}
+	}
+	return nil
+}
+
+// Protects returns whether the pods of the priority are never evicted.
+func (b GracePeriodBands) Protects(priority int32) bool {
+	band := b.Band(priority)
+	return band != nil && band.GracePeriod == nil
+}
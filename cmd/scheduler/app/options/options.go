@@ -46,6 +46,15 @@ const (
 	defaultPercentageOfNodesToFind    = 0
 	defaultLockObjectNamespace        = "volcano-system"
 	defaultNodeWorkers                = 20
+
+	defaultGangBindFailurePolicy   = "none"
+	defaultGangBindRetryPeriod     = 30 * time.Second
+	defaultUnschedulableBackoffMax = 5 * time.Minute
+	defaultNodeQuarantineWindow    = 10 * time.Minute
+	defaultNodeQuarantineDuration  = 10 * time.Minute
+	defaultExternalBindTimeout     = 5 * time.Minute
+	defaultShutdownGracePeriod     = 10 * time.Second
+	defaultRecordEventsMaxBytes    = 1 << 30
 )
 
 // ServerOption is the main context object for the controller manager.
@@ -60,6 +69,10 @@ type ServerOption struct {
 	SchedulerNames    []string
 	SchedulerConf     string
 	SchedulePeriod    time.Duration
+	// MinSchedulePeriod and MaxSchedulePeriod, when both set, bound the period between scheduling
+	// cycles which then adapts to the pending tasks and the cluster events
+	MinSchedulePeriod time.Duration
+	MaxSchedulePeriod time.Duration
 	// leaderElection defines the configuration of leader election.
 	LeaderElection config.LeaderElectionConfiguration
 	// Deprecated: use ResourceNamespace instead.
@@ -68,6 +81,25 @@ type ServerOption struct {
 	PrintVersion        bool
 	EnableMetrics       bool
 	ListenAddress       string
+	// EnablePreview serves the read-only job preview API on ListenAddress
+	EnablePreview bool
+	// EnableExplain records the placement decisions of each cycle and serves them on ListenAddress
+	EnableExplain bool
+	// EnableStats aggregates each cycle into the statistics of the scheduling dashboard and serves
+	// them on ListenAddress
+	EnableStats bool
+	// EnableQuotaReservations serves on ListenAddress the API the submission portals reserve the
+	// quota of the queues with before creating their jobs
+	EnableQuotaReservations bool
+	// EnableQueryAPI serves the read-only views of the queues, jobs and utilization built from the
+	// cache on ListenAddress
+	EnableQueryAPI bool
+	// EnableOfferAPI serves on ListenAddress the offer/claim API of the external framework schedulers
+	EnableOfferAPI bool
+	// ListenTLSCertFile and ListenTLSKeyFile serve the HTTP requests on ListenAddress over TLS, the
+	// APIs authenticating their users by bearer tokens are only served with them
+	ListenTLSCertFile   string
+	ListenTLSKeyFile    string
 	EnablePriorityClass bool
 	EnableCSIStorage    bool
 	// vc-scheduler will load (not activate) custom plugins which are in this directory
@@ -85,6 +117,66 @@ type ServerOption struct {
 	CacheDumpFileDir  string
 	EnableCacheDumper bool
 	NodeWorkerThreads uint32
+	// NodeLeaseStaleDuration is how long a node may miss renewing its lease before it is
+	// excluded from scheduling, 0 disables the check
+	NodeLeaseStaleDuration time.Duration
+	// DaemonSetReservationPeriod is how long after their creation the nodes keep room for the pods
+	// of the DaemonSets not started on them yet, 0 disables the reservation
+	DaemonSetReservationPeriod time.Duration
+	// GangBindFailurePolicy is what to do with the bound tasks of a gang some of whose binds failed:
+	// none, evict or retry
+	GangBindFailurePolicy string
+	// GangBindRetryPeriod is how long the failed tasks of a gang are given to be placed again
+	// before its bound tasks are evicted, with the retry policy
+	GangBindRetryPeriod time.Duration
+	// ExternalBindTimeout is how long an external binder is given to bind the pods whose node
+	// decision is handed to it, before the decision is revoked and the pods placed again
+	ExternalBindTimeout time.Duration
+	// ShutdownGracePeriod is how long the scheduler waits, once asked to stop, for its session and
+	// for the binds and evictions in flight to complete
+	ShutdownGracePeriod time.Duration
+	// EvictByDelete deletes the victims of preemption and reclaim instead of evicting them
+	// through the Eviction API, which honors PodDisruptionBudgets
+	EvictByDelete bool
+	// RecordEventsFile is the file the cache events are recorded to, for the replay of
+	// scheduling issues; empty disables the recording
+	RecordEventsFile string
+	// RecordEventsMaxBytes caps the size of the recorded events, the recording stops once
+	// it is reached; 0 leaves it unbounded
+	RecordEventsMaxBytes int64
+	// PodGroupStatusQPS rate limits the writes of the podgroup statuses, the phase transitions
+	// being written before the refreshes of the conditions; 0 writes them at once
+	PodGroupStatusQPS float32
+	// Deterministic makes the placements depend only on the inputs of the scheduling cycle: the
+	// nodes are considered in name order and the ties are broken by a random source seeded with
+	// DeterministicSeed at the start of each cycle
+	Deterministic     bool
+	DeterministicSeed int64
+	// ScoreBreakdownVerbosity reports, when a task is bound, the score given by each plugin to its
+	// node and to the runner-up: 1 logs it, 2 also records it as an event of the pod
+	ScoreBreakdownVerbosity int
+	// TaskRoleLabels are the labels giving the roles of the pods created by other operators,
+	// which have no "volcano.sh/task-spec"
+	TaskRoleLabels []string
+	// UnschedulableBackoffBase is how long a podgroup which failed to be scheduled is not allocated
+	// in the scheduling cycles, doubled on each consecutive failure up to UnschedulableBackoffMax;
+	// 0 disables the backoff
+	UnschedulableBackoffBase time.Duration
+	UnschedulableBackoffMax  time.Duration
+	// EnableVPARecommendations uses the recommendations of the VerticalPodAutoscalers targeting the
+	// controllers of the pods instead of their requests in the fair-share and binpack math
+	EnableVPARecommendations bool
+	// EnableMaintenanceWindows stops admitting the jobs of the queues and placing pods on the nodes
+	// covered by a MaintenanceWindow in progress
+	EnableMaintenanceWindows bool
+	// NodeQuarantineFailures is the number of bind or admission failures on a node within
+	// NodeQuarantineWindow which quarantine it for NodeQuarantineDuration; 0 disables the quarantine
+	NodeQuarantineFailures int
+	NodeQuarantineWindow   time.Duration
+	NodeQuarantineDuration time.Duration
+	// EvictionGracePeriods are the grace periods of the victims of preempt, reclaim and the other
+	// evicting actions, by the lowest priority of their band; NeverEvict protects the band
+	EvictionGracePeriods map[string]string
 
 	// IgnoredCSIProvisioners contains a list of provisioners, and pod request pvc with these provisioners will
 	// not be counted in pod pvc resource request and node.Allocatable, because the spec.drivers of csinode resource
@@ -118,6 +210,10 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.StringArrayVar(&s.SchedulerNames, "scheduler-name", []string{defaultSchedulerName}, "vc-scheduler will handle pods whose .spec.SchedulerName is same as scheduler-name")
 	fs.StringVar(&s.SchedulerConf, "scheduler-conf", "", "The absolute path of scheduler configuration file")
 	fs.DurationVar(&s.SchedulePeriod, "schedule-period", defaultSchedulerPeriod, "The period between each scheduling cycle")
+	fs.DurationVar(&s.MinSchedulePeriod, "min-schedule-period", 0,
+		"The shortest period between scheduling cycles, used with max-schedule-period to adapt the period to the pending tasks and cluster events instead of schedule-period")
+	fs.DurationVar(&s.MaxSchedulePeriod, "max-schedule-period", 0,
+		"The longest period between scheduling cycles, used with min-schedule-period to adapt the period to the pending tasks and cluster events instead of schedule-period")
 	fs.StringVar(&s.DefaultQueue, "default-queue", defaultQueue, "The default queue name of the job")
 	fs.BoolVar(&s.PrintVersion, "version", false, "Show version and quit")
 	fs.StringVar(&s.ListenAddress, "listen-address", defaultListenAddress, "The address to listen on for HTTP requests.")
@@ -141,18 +237,108 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 		"Enable tracking of available storage capacity that CSI drivers provide; it is false by default")
 	fs.BoolVar(&s.EnableHealthz, "enable-healthz", false, "Enable the health check; it is false by default")
 	fs.BoolVar(&s.EnableMetrics, "enable-metrics", false, "Enable the metrics function; it is false by default")
+	fs.BoolVar(&s.EnablePreview, "enable-preview", false, "Enable the job preview API on the listen address; it is false by default")
+	fs.BoolVar(&s.EnableExplain, "enable-explain", false, "Record the placement decisions of the last cycle and serve them per podgroup on the listen address; it is false by default")
+	fs.BoolVar(&s.EnableStats, "enable-stats", false, "Serve the statistics of the last cycle backing a scheduling dashboard on the listen address; it is false by default")
+	fs.BoolVar(&s.EnableQueryAPI, "enable-query-api", false, "Serve the read-only API listing the queues, jobs and utilization from the scheduler cache on the listen address; it is false by default")
+	fs.BoolVar(&s.EnableOfferAPI, "enable-offer-api", false, "Serve the offer/claim API of the external framework schedulers on the listen address; it is false by default")
+	fs.StringVar(&s.ListenTLSCertFile, "listen-tls-cert-file", "", "File containing the x509 certificate the HTTP requests on the listen address are served over TLS with; the APIs are only served with it")
+	fs.StringVar(&s.ListenTLSKeyFile, "listen-tls-private-key-file", "", "File containing the x509 private key matching --listen-tls-cert-file")
+	fs.BoolVar(&s.EnableQuotaReservations, "enable-quota-reservations", false, "Serve the API reserving the quota of the queues for the jobs of the submission portals, with a TTL, on the listen address; it is false by default")
 	fs.StringSliceVar(&s.NodeSelector, "node-selector", nil, "volcano only work with the labeled node, like: --node-selector=volcano.sh/role:train --node-selector=volcano.sh/role:serving")
 	fs.BoolVar(&s.EnableCacheDumper, "cache-dumper", true, "Enable the cache dumper, it's true by default")
 	fs.StringVar(&s.CacheDumpFileDir, "cache-dump-dir", "/tmp", "The target dir where the json file put at when dump cache info to json file")
 	fs.Uint32Var(&s.NodeWorkerThreads, "node-worker-threads", defaultNodeWorkers, "The number of threads syncing node operations.")
+	fs.DurationVar(&s.NodeLeaseStaleDuration, "node-lease-stale-duration", 0,
+		"Exclude nodes whose lease has not been renewed for this duration from scheduling before they turn NotReady; 0 disables it")
+	fs.DurationVar(&s.DaemonSetReservationPeriod, "daemonset-reservation-period", 0,
+		"Keep room on the nodes created within this period for the pods of the DaemonSets not started on them yet; 0 disables it")
+	fs.StringVar(&s.GangBindFailurePolicy, "gang-bind-failure-policy", defaultGangBindFailurePolicy,
+		"What to do when some binds of a gang fail: none keeps the bound tasks, evict evicts them at once, retry evicts them if the gang is still not ready after gang-bind-retry-period")
+	fs.DurationVar(&s.GangBindRetryPeriod, "gang-bind-retry-period", defaultGangBindRetryPeriod,
+		"The time the failed tasks of a gang are given to be bound again before its bound tasks are evicted, with the retry gang bind failure policy")
+	fs.DurationVar(&s.ExternalBindTimeout, "external-bind-timeout", defaultExternalBindTimeout,
+		"The time an external binder is given to bind the pods annotated volcano.sh/external-binder=true after the scheduler annotated them with its node decision; the decision is revoked after it")
+	fs.DurationVar(&s.ShutdownGracePeriod, "shutdown-grace-period", defaultShutdownGracePeriod,
+		"The time the scheduler waits on SIGTERM for its session and for the binds and evictions in flight to complete before exiting; keep it below the leader election lease duration")
+	fs.BoolVar(&s.EvictByDelete, "evict-by-delete", false,
+		"Delete the victims of preemption and reclaim directly instead of evicting them through the Eviction API, bypassing PodDisruptionBudgets")
+	fs.StringVar(&s.RecordEventsFile, "record-events-file", "",
+		"Record the pod, node, podgroup and queue events received by the scheduler to this file, to replay them with vc-scheduler-replay")
+	fs.Int64Var(&s.RecordEventsMaxBytes, "record-events-max-bytes", defaultRecordEventsMaxBytes,
+		"The maximum size of the recorded events in bytes, the recording stops once it is reached; 0 leaves it unbounded")
+	fs.Float32Var(&s.PodGroupStatusQPS, "podgroup-status-qps", 0,
+		"The maximum rate of the podgroup status writes, phase transitions first and the writes of one podgroup coalesced; 0 writes them at once without limit")
+	fs.BoolVar(&s.Deterministic, "deterministic", false,
+		"Schedule deterministically, so that identical inputs give identical placements, e.g. for tests and the reproduction of incidents; it is slower")
+	fs.Int64Var(&s.DeterministicSeed, "deterministic-seed", 0, "The seed of the tie-breaking between equally scored nodes in deterministic mode")
+	fs.IntVar(&s.ScoreBreakdownVerbosity, "score-breakdown-verbosity", 0,
+		"Report the score given by each node order plugin to the node of a bound task and to the runner-up: 1 logs it, 2 also records it as an event of the pod; 0 disables it")
+	fs.StringSliceVar(&s.TaskRoleLabels, "task-role-labels", nil,
+		"The labels giving the roles of the pods without volcano.sh/task-spec, the first one set on a pod is its role, like: --task-role-labels=training.kubeflow.org/replica-type,spark-role")
+	fs.DurationVar(&s.UnschedulableBackoffBase, "unschedulable-backoff-base", 0,
+		"Skip the allocation of the podgroups which failed to be scheduled for this duration, doubled on each consecutive failure; they are retried at once when pods complete or nodes change. 0 disables it")
+	fs.DurationVar(&s.UnschedulableBackoffMax, "unschedulable-backoff-max", defaultUnschedulableBackoffMax,
+		"The maximum duration the allocation of a podgroup which keeps failing to be scheduled is skipped")
+	fs.BoolVar(&s.EnableVPARecommendations, "enable-vpa-recommendations", false,
+		"Use the VerticalPodAutoscaler recommendations of the pods, when lower than their requests, in the fair-share and binpack math; the placement still honors the requests")
+	fs.BoolVar(&s.EnableMaintenanceWindows, "enable-maintenance-windows", false,
+		"Stop admitting the jobs of the queues and placing pods on the nodes covered by a MaintenanceWindow in progress, and evict their pods if it drains them")
+	fs.IntVar(&s.NodeQuarantineFailures, "node-quarantine-failures", 0,
+		"Quarantine the nodes, leaving them out of scheduling, after this number of failed binds to them, API server errors excepted, or kubelet admission failures within node-quarantine-window; 0 disables it")
+	fs.DurationVar(&s.NodeQuarantineWindow, "node-quarantine-window", defaultNodeQuarantineWindow,
+		"The window the bind and admission failures of a node are counted in for its quarantine")
+	fs.DurationVar(&s.NodeQuarantineDuration, "node-quarantine-duration", defaultNodeQuarantineDuration,
+		"How long a node with repeated bind or admission failures is quarantined")
+	fs.StringToStringVar(&s.EvictionGracePeriods, "eviction-grace-periods", nil,
+		"The eviction grace periods of the victims by the lowest priority of their band, e.g. 0=5s,1000=30s,1000000=never; never protects the band from eviction, the victims below all bands keep their own grace period")
 	fs.StringSliceVar(&s.IgnoredCSIProvisioners, "ignored-provisioners", nil, "The provisioners that will be ignored during pod pvc request computation and preemption.")
 }
 
 // CheckOptionOrDie check leader election flag when LeaderElection is enabled.
 func (s *ServerOption) CheckOptionOrDie() error {
+	switch s.GangBindFailurePolicy {
+	case "none", "evict", "retry":
+	default:
+		return fmt.Errorf("invalid gang-bind-failure-policy %q, must be one of none, evict and retry", s.GangBindFailurePolicy)
+	}
+	if (s.MinSchedulePeriod > 0) != (s.MaxSchedulePeriod > 0) || s.MinSchedulePeriod > s.MaxSchedulePeriod {
+		return fmt.Errorf("min-schedule-period %v and max-schedule-period %v must be set together, the former not greater than the latter",
+			s.MinSchedulePeriod, s.MaxSchedulePeriod)
+	}
+	if _, err := ParseGracePeriodBands(s.EvictionGracePeriods); err != nil {
+		return err
+	}
+	if s.NodeQuarantineFailures < 0 || (s.NodeQuarantineFailures > 0 && (s.NodeQuarantineWindow <= 0 || s.NodeQuarantineDuration <= 0)) {
+		return fmt.Errorf("node-quarantine-failures %d must not be negative, node-quarantine-window %v and node-quarantine-duration %v must be positive with it",
+			s.NodeQuarantineFailures, s.NodeQuarantineWindow, s.NodeQuarantineDuration)
+	}
+	if s.ExternalBindTimeout <= 0 {
+		return fmt.Errorf("external-bind-timeout %v must be positive", s.ExternalBindTimeout)
+	}
+	if s.ShutdownGracePeriod < 0 {
+		return fmt.Errorf("shutdown-grace-period %v must not be negative", s.ShutdownGracePeriod)
+	}
+	if s.ScoreBreakdownVerbosity < 0 || s.ScoreBreakdownVerbosity > 2 {
+		return fmt.Errorf("score-breakdown-verbosity %d must be between 0 and 2", s.ScoreBreakdownVerbosity)
+	}
+	if (len(s.ListenTLSCertFile) == 0) != (len(s.ListenTLSKeyFile) == 0) {
+		return fmt.Errorf("listen-tls-cert-file and listen-tls-private-key-file must be set together")
+	}
+	if s.APIsEnabled() && len(s.ListenTLSCertFile) == 0 {
+		return fmt.Errorf("the HTTP APIs authenticate their users by bearer tokens, listen-tls-cert-file and listen-tls-private-key-file are required to enable them")
+	}
+	if s.PodGroupStatusQPS < 0 {
+		return fmt.Errorf("podgroup-status-qps %v must not be negative", s.PodGroupStatusQPS)
+	}
 	return componentbaseconfigvalidation.ValidateLeaderElectionConfiguration(&s.LeaderElection, field.NewPath("leaderElection")).ToAggregate()
 }
 
+// APIsEnabled returns whether any of the HTTP APIs authenticating their users is enabled.
+func (s *ServerOption) APIsEnabled() bool {
+	return s.EnablePreview || s.EnableExplain || s.EnableStats || s.EnableQueryAPI || s.EnableQuotaReservations || s.EnableOfferAPI
+}
+
 // RegisterOptions registers options.
 func (s *ServerOption) RegisterOptions() {
 	ServerOpts = s
@@ -82,6 +82,14 @@ func TestAddFlags(t *testing.T) {
 		PercentageOfNodesToFind:    defaultPercentageOfNodesToFind,
 		NodeWorkerThreads:          defaultNodeWorkers,
 		CacheDumpFileDir:           "/tmp",
+		GangBindFailurePolicy:      defaultGangBindFailurePolicy,
+		GangBindRetryPeriod:        defaultGangBindRetryPeriod,
+		ExternalBindTimeout:        defaultExternalBindTimeout,
+		ShutdownGracePeriod:        defaultShutdownGracePeriod,
+		UnschedulableBackoffMax:    defaultUnschedulableBackoffMax,
+		NodeQuarantineWindow:       defaultNodeQuarantineWindow,
+		NodeQuarantineDuration:     defaultNodeQuarantineDuration,
+		RecordEventsMaxBytes:       defaultRecordEventsMaxBytes,
 	}
 	expectedFeatureGates := map[featuregate.Feature]bool{
 		features.PodDisruptionBudgetsSupport: false,
@@ -95,3 +103,44 @@ func TestAddFlags(t *testing.T) {
 		assert.Equal(t, v, utilfeature.DefaultFeatureGate.Enabled(k))
 	}
 }
+
+func TestCheckOptionListenTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name: "metrics are served without TLS",
+			args: []string{"--enable-metrics"},
+		},
+		{
+			name:    "the offer API requires TLS",
+			args:    []string{"--enable-offer-api"},
+			wantErr: true,
+		},
+		{
+			name: "the offer API is served over TLS",
+			args: []string{"--enable-offer-api", "--listen-tls-cert-file=tls.crt", "--listen-tls-private-key-file=tls.key"},
+		},
+		{
+			name:    "the certificate requires its key",
+			args:    []string{"--enable-stats", "--listen-tls-cert-file=tls.crt"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("checkoptiontest", pflag.ContinueOnError)
+			s := NewServerOption()
+			commonutil.LeaderElectionDefault(&s.LeaderElection)
+			s.LeaderElection.ResourceName = "volcano"
+			s.AddFlags(fs)
+			assert.NoError(t, fs.Parse(test.args))
+
+			err := s.CheckOptionOrDie()
+			assert.Equal(t, test.wantErr, err != nil, "unexpected error: %v", err)
+		})
+	}
+}
@@ -21,13 +21,24 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sync"
 
 	"volcano.sh/apis/pkg/apis/helpers"
+	vcclientset "volcano.sh/apis/pkg/client/clientset/versioned"
 
 	"volcano.sh/volcano/cmd/scheduler/app/options"
 	"volcano.sh/volcano/pkg/kube"
 	"volcano.sh/volcano/pkg/scheduler"
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+	"volcano.sh/volcano/pkg/scheduler/defrag"
+	"volcano.sh/volcano/pkg/scheduler/explain"
 	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/offer"
+	defragplugin "volcano.sh/volcano/pkg/scheduler/plugins/defrag"
+	"volcano.sh/volcano/pkg/scheduler/preview"
+	"volcano.sh/volcano/pkg/scheduler/query"
+	"volcano.sh/volcano/pkg/scheduler/quota"
+	"volcano.sh/volcano/pkg/scheduler/stats"
 	"volcano.sh/volcano/pkg/signals"
 	commonutil "volcano.sh/volcano/pkg/util"
 
@@ -73,10 +84,57 @@ func Run(opt *options.ServerOption) error {
 		panic(err)
 	}
 
-	if opt.EnableMetrics {
+	// the HTTP APIs are only served to the users allowed by the RBAC of the cluster
+	authorizer := apiauth.New(sched.Cache().Client())
+	mux := authorizer.ServeMux(http.DefaultServeMux)
+
+	if opt.EnablePreview {
+		mux.Handle(preview.Path, preview.NewHandler(sched.RunPreviewSession, authorizer))
+	}
+
+	if opt.EnableExplain {
+		explain.Default().Enable()
+		mux.Handle(explain.Path, explain.NewHandler(explain.Default(), authorizer))
+	}
+
+	if opt.EnableStats {
+		stats.Default().Enable()
+		mux.Handle(stats.Path, stats.NewHandler(stats.Default(), authorizer))
+	}
+
+	if opt.EnableQueryAPI {
+		query.NewServer(sched.Cache()).Register(mux, authorizer)
+	}
+
+	if opt.EnableQuotaReservations {
+		quota.NewManager(sched.Cache().Snapshot, vcclientset.NewForConfigOrDie(config)).Register(mux, authorizer)
+	}
+
+	if opt.EnableOfferAPI {
+		mux.Handle(offer.Path, offer.NewHandler(offer.Default(), authorizer))
+		mux.Handle(offer.ClaimPath, offer.NewClaimHandler(offer.Default(), authorizer))
+	}
+
+	// the fragmentation report is only computed by the defrag plugin, and like the other APIs
+	// only served over TLS as the users send their bearer tokens
+	serveDefrag := sched.PluginConfigured(defragplugin.PluginName)
+	if serveDefrag && len(opt.ListenTLSCertFile) == 0 {
+		klog.Warningf("The fragmentation report of the %s plugin is not served, --listen-tls-cert-file is not set", defragplugin.PluginName)
+		serveDefrag = false
+	}
+	if serveDefrag {
+		mux.Handle(defrag.Path, defrag.NewHandler(authorizer))
+	}
+
+	if opt.EnableMetrics || opt.APIsEnabled() || serveDefrag {
 		go func() {
-			http.Handle("/metrics", promHandler())
-			klog.Fatalf("Prometheus Http Server failed %s", http.ListenAndServe(opt.ListenAddress, nil))
+			if opt.EnableMetrics {
+				http.Handle("/metrics", promHandler())
+			}
+			if len(opt.ListenTLSCertFile) != 0 {
+				klog.Fatalf("Http Server failed %s", http.ListenAndServeTLS(opt.ListenAddress, opt.ListenTLSCertFile, opt.ListenTLSKeyFile, nil))
+			}
+			klog.Fatalf("Http Server failed %s", http.ListenAndServe(opt.ListenAddress, nil))
 		}()
 	}
 
@@ -87,9 +145,30 @@ func Run(opt *options.ServerOption) error {
 	}
 
 	ctx := signals.SetupSignalContext()
-	run := func(ctx context.Context) {
+	// the leader election outlives the signal until the scheduler finished its shutdown, so that
+	// the lease is only released once drained
+	leaderCtx, releaseLease := context.WithCancel(context.Background())
+	defer releaseLease()
+	// drained is released once the scheduler finished its shutdown
+	var drained sync.WaitGroup
+	drained.Add(1)
+	// started is set once the scheduler leads, it then releases the lease itself once drained
+	var startedMutex sync.Mutex
+	started := false
+	hasStarted := func() bool {
+		startedMutex.Lock()
+		defer startedMutex.Unlock()
+		return started
+	}
+	run := func(context.Context) {
+		startedMutex.Lock()
+		started = true
+		startedMutex.Unlock()
+		defer releaseLease()
+		defer drained.Done()
 		sched.Run(ctx.Done())
 		<-ctx.Done()
+		sched.Shutdown(opt.ShutdownGracePeriod)
 	}
 
 	if !opt.LeaderElection.LeaderElect {
@@ -130,14 +209,32 @@ func Run(opt *options.ServerOption) error {
 		return fmt.Errorf("couldn't create resource lock: %v", err)
 	}
 
-	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+	// a standby scheduler stops competing for the lease on the signal
+	go func() {
+		<-ctx.Done()
+		startedMutex.Lock()
+		defer startedMutex.Unlock()
+		if !started {
+			releaseLease()
+		}
+	}()
+
+	leaderelection.RunOrDie(leaderCtx, leaderelection.LeaderElectionConfig{
 		Lock:          rl,
 		LeaseDuration: opt.LeaderElection.LeaseDuration.Duration,
 		RenewDeadline: opt.LeaderElection.RenewDeadline.Duration,
 		RetryPeriod:   opt.LeaderElection.RetryPeriod.Duration,
+		// the lease is released once run returned, after the drain on shutdown
+		ReleaseOnCancel: true,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: run,
 			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					if hasStarted() {
+						drained.Wait()
+					}
+					return
+				}
 				klog.Fatalf("leaderelection lost")
 			},
 		},
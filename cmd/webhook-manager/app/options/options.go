@@ -51,6 +51,9 @@ type Config struct {
 	WebhookURL        string
 	ConfigPath        string
 	EnabledAdmission  string
+	// ControllerServiceAccounts are the service accounts of the controllers trusted to create
+	// the podgroups and pods of the users, e.g. of the jobs and ReplicaSets
+	ControllerServiceAccounts []string
 
 	EnableHealthz bool
 	// HealthzBindAddress is the IP address and port for the health check server to serve on
@@ -85,6 +88,7 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.WebhookURL, "webhook-url", "", "The url of this webhook")
 	fs.StringVar(&c.EnabledAdmission, "enabled-admission", defaultEnabledAdmission, "enabled admission webhooks, if this parameter is modified, make sure corresponding webhook configurations are the same.")
 	fs.StringArrayVar(&c.SchedulerNames, "scheduler-name", []string{defaultSchedulerName}, "Volcano will handle pods whose .spec.SchedulerName is same as scheduler-name")
+	fs.StringSliceVar(&c.ControllerServiceAccounts, "controller-service-accounts", []string{"kube-system:*"}, "The service accounts of the controllers, in the form of namespace:name or namespace:*, whose podgroups and pods of the users are checked against the access annotations of the queues by their namespace only; the service accounts of the webhook namespace are trusted too")
 	fs.StringVar(&c.ConfigPath, "admission-conf", "", "The configmap file of this webhook")
 	fs.BoolVar(&c.EnableHealthz, "enable-healthz", false, "Enable the health check; it is false by default")
 	fs.StringVar(&c.HealthzBindAddress, "healthz-address", defaultHealthzAddress, "The address to listen on for the health check server.")
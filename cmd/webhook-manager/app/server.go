@@ -25,12 +25,14 @@ import (
 	"syscall"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	"volcano.sh/apis/pkg/apis/helpers"
 	"volcano.sh/apis/pkg/apis/scheduling/scheme"
+	vcinformer "volcano.sh/apis/pkg/client/informers/externalversions"
 	"volcano.sh/volcano/cmd/webhook-manager/app/options"
 	"volcano.sh/volcano/pkg/kube"
 	commonutil "volcano.sh/volcano/pkg/util"
@@ -68,6 +70,32 @@ func Run(config *options.Config) error {
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	recorder := broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: commonutil.GenerateComponentName(config.SchedulerNames)})
+
+	// the nodes, podgroups and queues are cached rather than got from the API server on every admission
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	nodeLister := informerFactory.Core().V1().Nodes().Lister()
+	vcInformerFactory := vcinformer.NewSharedInformerFactory(vClient, 0)
+	podGroupLister := vcInformerFactory.Scheduling().V1beta1().PodGroups().Lister()
+	queueLister := vcInformerFactory.Scheduling().V1beta1().Queues().Lister()
+	informerStopCh := make(chan struct{})
+	defer close(informerStopCh)
+	informerFactory.Start(informerStopCh)
+	vcInformerFactory.Start(informerStopCh)
+	for informerType, synced := range informerFactory.WaitForCacheSync(informerStopCh) {
+		if !synced {
+			return fmt.Errorf("failed to sync the cache of %v", informerType)
+		}
+	}
+	for informerType, synced := range vcInformerFactory.WaitForCacheSync(informerStopCh) {
+		if !synced {
+			return fmt.Errorf("failed to sync the cache of %v", informerType)
+		}
+	}
+	// the volcano controllers run in the namespace of the webhook manager
+	controllers := config.ControllerServiceAccounts
+	if len(config.WebhookNamespace) != 0 {
+		controllers = append(controllers, config.WebhookNamespace+":*")
+	}
 	if err := router.ForEachAdmission(config, func(service *router.AdmissionService) error {
 		if service.Config != nil {
 			service.Config.VolcanoClient = vClient
@@ -75,6 +103,10 @@ func Run(config *options.Config) error {
 			service.Config.SchedulerNames = config.SchedulerNames
 			service.Config.Recorder = recorder
 			service.Config.ConfigData = admissionConf
+			service.Config.NodeLister = nodeLister
+			service.Config.PodGroupLister = podGroupLister
+			service.Config.QueueLister = queueLister
+			service.Config.ControllerServiceAccounts = controllers
 		}
 
 		klog.V(3).Infof("Registered '%s' as webhook.", service.Path)
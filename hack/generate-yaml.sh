@@ -90,6 +90,9 @@ tail -n +2 ${VOLCANO_CRD_DIR}/bases/batch.volcano.sh_jobs.yaml > ${HELM_VOLCANO_
 tail -n +2 ${VOLCANO_CRD_DIR}/bases/bus.volcano.sh_commands.yaml > ${HELM_VOLCANO_CRD_DIR}/bases/bus.volcano.sh_commands.yaml
 tail -n +2 ${VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_podgroups.yaml > ${HELM_VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_podgroups.yaml
 tail -n +2 ${VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_queues.yaml > ${HELM_VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_queues.yaml
+tail -n +2 ${VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_maintenancewindows.yaml > ${HELM_VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_maintenancewindows.yaml
+tail -n +2 ${VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_reservations.yaml > ${HELM_VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_reservations.yaml
+tail -n +2 ${VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_resourceflavors.yaml > ${HELM_VOLCANO_CRD_DIR}/bases/scheduling.volcano.sh_resourceflavors.yaml
 tail -n +2 ${VOLCANO_CRD_DIR}/bases/nodeinfo.volcano.sh_numatopologies.yaml > ${HELM_VOLCANO_CRD_DIR}/bases/nodeinfo.volcano.sh_numatopologies.yaml
 
 # sync jobflow bases
@@ -127,6 +130,9 @@ ${HELM_BIN_DIR}/helm template ${VK_ROOT}/installer/helm/chart/volcano --namespac
       -s templates/scheduler.yaml \
       -s templates/scheduling_v1beta1_podgroup.yaml \
       -s templates/scheduling_v1beta1_queue.yaml \
+      -s templates/scheduling_v1alpha1_maintenancewindow.yaml \
+      -s templates/scheduling_v1alpha1_reservation.yaml \
+      -s templates/scheduling_v1alpha1_resourceflavor.yaml \
       -s templates/nodeinfo_v1alpha1_numatopologies.yaml \
       -s templates/webhooks.yaml \
       >> ${DEPLOYMENT_FILE}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defrag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/kubernetes"
+
+	"volcano.sh/volcano/pkg/cli/util"
+	"volcano.sh/volcano/pkg/scheduler/defrag"
+)
+
+type reportFlags struct {
+	util.CommonFlags
+
+	// Namespace, Service and Port locate the metrics service of the scheduler
+	Namespace string
+	Service   string
+	Port      string
+}
+
+var reportDefragFlags = &reportFlags{}
+
+// InitReportFlags is used to init all flags.
+func InitReportFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &reportDefragFlags.CommonFlags)
+
+	cmd.Flags().StringVarP(&reportDefragFlags.Namespace, "namespace", "n", "volcano-system", "the namespace of the scheduler service")
+	cmd.Flags().StringVarP(&reportDefragFlags.Service, "service", "", "volcano-scheduler-service", "the name of the scheduler service")
+	cmd.Flags().StringVarP(&reportDefragFlags.Port, "port", "", "8080", "the metrics port of the scheduler service")
+}
+
+// Report prints the latest fragmentation report of the scheduler, fetched through the api server proxy.
+func Report(ctx context.Context) error {
+	config, err := util.BuildConfig(reportDefragFlags.Master, reportDefragFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	data, err := client.CoreV1().Services(reportDefragFlags.Namespace).
+		ProxyGet("https", reportDefragFlags.Service, reportDefragFlags.Port, defrag.Path, nil).DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the fragmentation report: %v", err)
+	}
+	report := &defrag.Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return fmt.Errorf("failed to decode the fragmentation report: %v", err)
+	}
+	PrintReport(report, os.Stdout)
+	return nil
+}
+
+// PrintReport prints the fragmentation report.
+func PrintReport(report *defrag.Report, writer io.Writer) {
+	fmt.Fprintf(writer, "Analyzed at %s\n\n", report.Time.Format("2006-01-02 15:04:05"))
+
+	fmt.Fprintf(writer, "%-30s%-8s%-10s%-14s%-11s%-14s\n", "GPU Type", "Nodes", "IdleGPUs", "LargestPod", "IdleNodes", "Fragmentation")
+	for _, stats := range report.GPUTypes {
+		fmt.Fprintf(writer, "%-30s%-8d%-10d%-14d%-11d%-14s\n", stats.Type, stats.Nodes, stats.IdleGPUs,
+			stats.LargestPodGPUs, stats.IdleNodes, fmt.Sprintf("%.0f%%", stats.Fragmentation*100))
+	}
+
+	if len(report.Nodes) != 0 {
+		fmt.Fprintf(writer, "\n%-30s%-16s%-18s%-8s\n", "Node", "StrandedCPU(m)", "StrandedMemory", "StrandedGPUs")
+		for _, node := range report.Nodes {
+			fmt.Fprintf(writer, "%-30s%-16.0f%-18.0f%-8d\n", node.Name, node.MilliCPU, node.Memory, node.GPUs)
+		}
+	}
+
+	if len(report.Migrations) != 0 {
+		fmt.Fprintf(writer, "\nSuggested migrations:\n")
+		for _, migration := range report.Migrations {
+			fmt.Fprintf(writer, "  %s (%d gpus): %s -> %s\n", migration.Task, migration.GPUs, migration.From, migration.To)
+		}
+	}
+}
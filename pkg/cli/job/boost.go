@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/cli/util"
+)
+
+const (
+	// priorityBoostAnnotation, priorityBoostExpiryAnnotation and priorityBoostAuditAnnotation are
+	// the podgroup annotations read by the scheduler, see JobPriorityBoost in the scheduler api
+	priorityBoostAnnotation       = "volcano.sh/priority-boost"
+	priorityBoostExpiryAnnotation = "volcano.sh/priority-boost-expiry"
+	priorityBoostAuditAnnotation  = "volcano.sh/priority-boost-audit"
+
+	defaultBoostDuration = 24 * time.Hour
+)
+
+type boostFlags struct {
+	util.CommonFlags
+
+	Namespace string
+	JobName   string
+	Reason    string
+	Duration  time.Duration
+	Revoke    bool
+}
+
+var boostJobFlags = &boostFlags{}
+
+// boostAudit is the record of a boost kept on the podgroup, its user is set by the admission
+// webhook to the user who requested the boost.
+type boostAudit struct {
+	User   string    `json:"user,omitempty"`
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	Revoke bool      `json:"revoke,omitempty"`
+}
+
+// InitBoostFlags init boost related flags.
+func InitBoostFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &boostJobFlags.CommonFlags)
+
+	cmd.Flags().StringVarP(&boostJobFlags.Namespace, "namespace", "n", "default", "the namespace of job")
+	cmd.Flags().StringVarP(&boostJobFlags.JobName, "name", "N", "", "the name of job")
+	cmd.Flags().StringVarP(&boostJobFlags.Reason, "reason", "r", "", "why the job is boosted, recorded for audit")
+	cmd.Flags().DurationVar(&boostJobFlags.Duration, "duration", defaultBoostDuration, "how long the job stays boosted")
+	cmd.Flags().BoolVar(&boostJobFlags.Revoke, "revoke", false, "revoke the boost of the job")
+}
+
+// BoostJob bumps a pending job to the front of its queue, or revokes its boost.
+func BoostJob(ctx context.Context) error {
+	config, err := util.BuildConfig(boostJobFlags.Master, boostJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if boostJobFlags.JobName == "" {
+		return fmt.Errorf("job name is mandatory to boost a particular job")
+	}
+	if boostJobFlags.Reason == "" && !boostJobFlags.Revoke {
+		return fmt.Errorf("a reason is mandatory to boost a job")
+	}
+	if boostJobFlags.Duration <= 0 && !boostJobFlags.Revoke {
+		return fmt.Errorf("the duration of the boost must be positive")
+	}
+
+	client := versioned.NewForConfigOrDie(config)
+	job, err := client.BatchV1alpha1().Jobs(boostJobFlags.Namespace).Get(ctx, boostJobFlags.JobName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	// the podgroups of the jobs created by older releases are named after the job only
+	pg, err := client.SchedulingV1beta1().PodGroups(job.Namespace).Get(ctx, job.Name+"-"+string(job.UID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		pg, err = client.SchedulingV1beta1().PodGroups(job.Namespace).Get(ctx, job.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the podgroup of job <%s/%s>: %v", job.Namespace, job.Name, err)
+	}
+	if !boostJobFlags.Revoke && pg.Status.Phase != v1beta1.PodGroupPending && pg.Status.Phase != v1beta1.PodGroupInqueue {
+		return fmt.Errorf("job <%s/%s> is %s, only pending jobs can be boosted", job.Namespace, job.Name, pg.Status.Phase)
+	}
+
+	patch, err := boostPatch(time.Now(), boostJobFlags.Duration, boostJobFlags.Reason, boostJobFlags.Revoke)
+	if err != nil {
+		return err
+	}
+	_, err = client.SchedulingV1beta1().PodGroups(pg.Namespace).Patch(ctx, pg.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// boostPatch returns the merge patch setting, for duration, or removing the boost of a podgroup
+// with its audit record.
+func boostPatch(now time.Time, duration time.Duration, reason string, revoke bool) ([]byte, error) {
+	audit, err := json.Marshal(&boostAudit{Time: now.UTC(), Reason: reason, Revoke: revoke})
+	if err != nil {
+		return nil, err
+	}
+	var boost, expiry interface{} = "true", now.Add(duration).UTC().Format(time.RFC3339)
+	if revoke {
+		boost, expiry = nil, nil
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				priorityBoostAnnotation:       boost,
+				priorityBoostExpiryAnnotation: expiry,
+				priorityBoostAuditAnnotation:  string(audit),
+			},
+		},
+	})
+}
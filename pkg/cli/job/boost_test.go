@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBoostPatch(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, revoke := range []bool{false, true} {
+		patch, err := boostPatch(now, time.Hour, "release blocker", revoke)
+		if err != nil {
+			t.Fatalf("failed to build patch: %v", err)
+		}
+		var decoded struct {
+			Metadata struct {
+				Annotations map[string]*string `json:"annotations"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(patch, &decoded); err != nil {
+			t.Fatalf("failed to decode patch %s: %v", patch, err)
+		}
+
+		boost, found := decoded.Metadata.Annotations[priorityBoostAnnotation]
+		if !found || (revoke && boost != nil) || (!revoke && (boost == nil || *boost != "true")) {
+			t.Errorf("revoke %v: unexpected boost annotation in %s", revoke, patch)
+		}
+		expiry, found := decoded.Metadata.Annotations[priorityBoostExpiryAnnotation]
+		if !found || (revoke && expiry != nil) || (!revoke && (expiry == nil || *expiry != "2024-05-01T13:00:00Z")) {
+			t.Errorf("revoke %v: unexpected boost expiry in %s", revoke, patch)
+		}
+		audit := &boostAudit{}
+		if err := json.Unmarshal([]byte(*decoded.Metadata.Annotations[priorityBoostAuditAnnotation]), audit); err != nil {
+			t.Fatalf("failed to decode audit: %v", err)
+		}
+		if audit.User != "" || !audit.Time.Equal(now) || audit.Reason != "release blocker" || audit.Revoke != revoke {
+			t.Errorf("revoke %v: unexpected audit %+v", revoke, audit)
+		}
+	}
+}
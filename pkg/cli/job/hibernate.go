@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/cli/util"
+)
+
+// hibernateAnnotation is the job annotation the job controller hibernates the job on, see
+// HibernateKey in the job controller
+const hibernateAnnotation = "volcano.sh/hibernate"
+
+type hibernateFlags struct {
+	util.CommonFlags
+
+	Namespace string
+	JobName   string
+}
+
+type wakeFlags struct {
+	util.CommonFlags
+
+	Namespace string
+	JobName   string
+	Ahead     bool
+}
+
+var hibernateJobFlags = &hibernateFlags{}
+var wakeJobFlags = &wakeFlags{}
+
+// InitHibernateFlags init hibernate related flags.
+func InitHibernateFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &hibernateJobFlags.CommonFlags)
+
+	cmd.Flags().StringVarP(&hibernateJobFlags.Namespace, "namespace", "n", "default", "the namespace of job")
+	cmd.Flags().StringVarP(&hibernateJobFlags.JobName, "name", "N", "", "the name of job")
+}
+
+// InitWakeFlags init wake related flags.
+func InitWakeFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &wakeJobFlags.CommonFlags)
+
+	cmd.Flags().StringVarP(&wakeJobFlags.Namespace, "namespace", "n", "default", "the namespace of job")
+	cmd.Flags().StringVarP(&wakeJobFlags.JobName, "name", "N", "", "the name of job")
+	cmd.Flags().BoolVar(&wakeJobFlags.Ahead, "ahead", false, "boost the job ahead of the other jobs of its queue")
+}
+
+// HibernateJob deletes the pods of the job, keeping its podgroup, volumes and seniority in its queue.
+func HibernateJob(ctx context.Context) error {
+	config, err := util.BuildConfig(hibernateJobFlags.Master, hibernateJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if hibernateJobFlags.JobName == "" {
+		return fmt.Errorf("job name is mandatory to hibernate a particular job")
+	}
+
+	client := versioned.NewForConfigOrDie(config)
+	_, err = client.BatchV1alpha1().Jobs(hibernateJobFlags.Namespace).Patch(ctx, hibernateJobFlags.JobName,
+		types.MergePatchType, hibernatePatch(true), metav1.PatchOptions{})
+	return err
+}
+
+// WakeJob re-admits a hibernated job, optionally boosted ahead of the other jobs of its queue.
+func WakeJob(ctx context.Context) error {
+	config, err := util.BuildConfig(wakeJobFlags.Master, wakeJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if wakeJobFlags.JobName == "" {
+		return fmt.Errorf("job name is mandatory to wake a particular job")
+	}
+
+	client := versioned.NewForConfigOrDie(config)
+	job, err := client.BatchV1alpha1().Jobs(wakeJobFlags.Namespace).Get(ctx, wakeJobFlags.JobName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if job.Annotations[hibernateAnnotation] != "true" {
+		return fmt.Errorf("job <%s/%s> is not hibernated", job.Namespace, job.Name)
+	}
+
+	if wakeJobFlags.Ahead {
+		pg, err := client.SchedulingV1beta1().PodGroups(job.Namespace).Get(ctx, job.Name+"-"+string(job.UID), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			pg, err = client.SchedulingV1beta1().PodGroups(job.Namespace).Get(ctx, job.Name, metav1.GetOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get the podgroup of job <%s/%s>: %v", job.Namespace, job.Name, err)
+		}
+		patch, err := boostPatch(time.Now(), defaultBoostDuration, "woken from hibernation", false)
+		if err != nil {
+			return err
+		}
+		if _, err := client.SchedulingV1beta1().PodGroups(pg.Namespace).Patch(ctx, pg.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+	}
+
+	_, err = client.BatchV1alpha1().Jobs(job.Namespace).Patch(ctx, job.Name, types.MergePatchType, hibernatePatch(false), metav1.PatchOptions{})
+	return err
+}
+
+// hibernatePatch returns the merge patch setting or removing the hibernation of a job.
+func hibernatePatch(hibernate bool) []byte {
+	var value interface{}
+	if hibernate {
+		value = "true"
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{hibernateAnnotation: value},
+		},
+	})
+	return patch
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"testing"
+)
+
+func TestHibernatePatch(t *testing.T) {
+	for hibernate, expected := range map[bool]string{
+		true:  `{"metadata":{"annotations":{"volcano.sh/hibernate":"true"}}}`,
+		false: `{"metadata":{"annotations":{"volcano.sh/hibernate":null}}}`,
+	} {
+		if got := string(hibernatePatch(hibernate)); got != expected {
+			t.Errorf("hibernate %v: expected patch %s, got %s", hibernate, expected, got)
+		}
+	}
+}
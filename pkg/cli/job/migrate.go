@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/cli/util"
+)
+
+type migrateFlags struct {
+	util.CommonFlags
+
+	Namespace string
+	JobName   string
+	Queue     string
+}
+
+var migrateJobFlags = &migrateFlags{}
+
+// InitMigrateFlags init migrate related flags.
+func InitMigrateFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &migrateJobFlags.CommonFlags)
+
+	cmd.Flags().StringVarP(&migrateJobFlags.Namespace, "namespace", "n", "default", "the namespace of job")
+	cmd.Flags().StringVarP(&migrateJobFlags.JobName, "name", "N", "", "the name of job")
+	cmd.Flags().StringVarP(&migrateJobFlags.Queue, "queue", "q", "", "the queue to migrate the job to")
+}
+
+// MigrateJob moves a pending or running job to another queue; the admission of the job checks
+// that the queue has room for it, and its pods and allocation move with its podgroup.
+func MigrateJob(ctx context.Context) error {
+	config, err := util.BuildConfig(migrateJobFlags.Master, migrateJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if migrateJobFlags.JobName == "" {
+		return fmt.Errorf("job name is mandatory to migrate a particular job")
+	}
+	if migrateJobFlags.Queue == "" {
+		return fmt.Errorf("queue is mandatory to migrate a job")
+	}
+
+	client := versioned.NewForConfigOrDie(config)
+	job, err := client.BatchV1alpha1().Jobs(migrateJobFlags.Namespace).Get(ctx, migrateJobFlags.JobName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if job.Spec.Queue == migrateJobFlags.Queue {
+		return fmt.Errorf("job <%s/%s> is already in queue %s", job.Namespace, job.Name, job.Spec.Queue)
+	}
+
+	_, err = client.BatchV1alpha1().Jobs(job.Namespace).Patch(ctx, job.Name, types.MergePatchType, migratePatch(migrateJobFlags.Queue), metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Job <%s/%s> migrated from queue %s to queue %s\n", job.Namespace, job.Name, job.Spec.Queue, migrateJobFlags.Queue)
+	return nil
+}
+
+// migratePatch returns the merge patch moving a job to the queue.
+func migratePatch(queue string) []byte {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"queue": queue},
+	})
+	return patch
+}
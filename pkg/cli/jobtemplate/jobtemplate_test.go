@@ -415,6 +415,16 @@ func TestInitDeleteFlags(t *testing.T) {
 	}
 }
 
+func TestInitSubmitFlags(t *testing.T) {
+	var cmd cobra.Command
+	InitSubmitFlags(&cmd)
+	for _, name := range []string{"name", "namespace", "job-name", "param"} {
+		if cmd.Flag(name) == nil {
+			t.Errorf("Could not find the flag %s", name)
+		}
+	}
+}
+
 var content = `apiVersion: flow.volcano.sh/v1alpha1
 kind: JobTemplate
 metadata:
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobtemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchv1alpha1 "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/cli/util"
+	"volcano.sh/volcano/pkg/util/template"
+)
+
+type submitFlags struct {
+	util.CommonFlags
+
+	// Name is name of job template
+	Name string
+	// Namespace is namespace of job template and of the job
+	Namespace string
+	// JobName is the name of the job to create
+	JobName string
+	// Parameters are the key=value pairs substituted into the job template
+	Parameters []string
+}
+
+var submitJobTemplateFlags = &submitFlags{}
+
+// InitSubmitFlags is used to init all flags during job template submitting.
+func InitSubmitFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &submitJobTemplateFlags.CommonFlags)
+	cmd.Flags().StringVarP(&submitJobTemplateFlags.Name, "name", "N", "", "the name of job template")
+	cmd.Flags().StringVarP(&submitJobTemplateFlags.Namespace, "namespace", "n", "default", "the namespace of job template")
+	cmd.Flags().StringVarP(&submitJobTemplateFlags.JobName, "job-name", "j", "", "the name of the job to create, generated from the job template name if empty")
+	cmd.Flags().StringArrayVarP(&submitJobTemplateFlags.Parameters, "param", "p", nil, "a key=value parameter of the job template, may be repeated")
+}
+
+// SubmitJobTemplate creates a job from a job template with its parameters substituted.
+func SubmitJobTemplate(ctx context.Context) error {
+	config, err := util.BuildConfig(submitJobTemplateFlags.Master, submitJobTemplateFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if submitJobTemplateFlags.Name == "" {
+		return fmt.Errorf("name is mandatory to submit a job template")
+	}
+
+	params := map[string]string{}
+	for _, pair := range submitJobTemplateFlags.Parameters {
+		if err := template.SetParameter(params, pair); err != nil {
+			return err
+		}
+	}
+
+	client := versioned.NewForConfigOrDie(config)
+	jobTemplate, err := client.FlowV1alpha1().JobTemplates(submitJobTemplateFlags.Namespace).Get(ctx, submitJobTemplateFlags.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	value, found := jobTemplate.Annotations[template.ParametersAnnotation]
+	defaults, err := template.ParseParameters(value)
+	if err != nil {
+		return err
+	}
+	for name := range params {
+		if _, declared := defaults[name]; !declared {
+			return fmt.Errorf("parameter %s is not declared by job template %s", name, jobTemplate.Name)
+		}
+	}
+	spec := jobTemplate.Spec.DeepCopy()
+	if found {
+		if spec, err = template.Render(spec, defaults, params); err != nil {
+			return err
+		}
+	}
+
+	job := &batchv1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: submitJobTemplateFlags.Namespace,
+		},
+		Spec: *spec,
+	}
+	if submitJobTemplateFlags.JobName != "" {
+		job.Name = submitJobTemplateFlags.JobName
+	} else {
+		job.GenerateName = jobTemplate.Name + "-"
+	}
+
+	created, err := client.BatchV1alpha1().Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created Job: %s/%s\n", created.Namespace, created.Name)
+	return nil
+}
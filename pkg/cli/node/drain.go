@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"volcano.sh/volcano/pkg/cli/util"
+	"volcano.sh/volcano/pkg/controllers/drain"
+)
+
+type drainFlags struct {
+	util.CommonFlags
+
+	// Name is the name of the node to drain
+	Name string
+	// SmallGangSize is the number of pods up to which a gang is migrated at once
+	SmallGangSize int
+	// DryRun prints the plan of the drain without requesting it
+	DryRun bool
+}
+
+var drainNodeFlags = &drainFlags{}
+
+// InitDrainFlags is used to init all flags during node draining.
+func InitDrainFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &drainNodeFlags.CommonFlags)
+
+	cmd.Flags().StringVarP(&drainNodeFlags.Name, "name", "n", "", "the name of the node")
+	cmd.Flags().IntVarP(&drainNodeFlags.SmallGangSize, "small-gang-size", "", drain.DefaultSmallGangSize,
+		"the number of pods up to which a gang is migrated at once, larger gangs are requested to checkpoint first")
+	cmd.Flags().BoolVarP(&drainNodeFlags.DryRun, "dry-run", "", false, "print how the pods of the node would be evicted")
+}
+
+// DrainNode requests the drain controller to drain the node gang by gang.
+func DrainNode(ctx context.Context) error {
+	if len(drainNodeFlags.Name) == 0 {
+		return fmt.Errorf("node name must be specified")
+	}
+	if drainNodeFlags.SmallGangSize < 0 {
+		return fmt.Errorf("small gang size must not be negative")
+	}
+	config, err := util.BuildConfig(drainNodeFlags.Master, drainNodeFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if drainNodeFlags.DryRun {
+		pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		podList := make([]*v1.Pod, 0, len(pods.Items))
+		for i := range pods.Items {
+			podList = append(podList, &pods.Items[i])
+		}
+		PrintPlan(drain.BuildPlan(drainNodeFlags.Name, podList, drainNodeFlags.SmallGangSize), os.Stdout)
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				drain.DrainAnnotation:         "true",
+				drain.SmallGangSizeAnnotation: strconv.Itoa(drainNodeFlags.SmallGangSize),
+			},
+		},
+		"spec": map[string]interface{}{"unschedulable": true},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := client.CoreV1().Nodes().Patch(ctx, drainNodeFlags.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to request the drain of node %s: %v", drainNodeFlags.Name, err)
+	}
+	fmt.Printf("node/%s cordoned, its pods are evicted by the drain controller\n", drainNodeFlags.Name)
+	return nil
+}
+
+// PrintPlan prints how the pods of the node are evicted.
+func PrintPlan(plan *drain.Plan, writer io.Writer) {
+	if plan.Done() {
+		fmt.Fprintf(writer, "No pod to evict from node %s\n", plan.Node)
+		return
+	}
+	for _, gang := range plan.Gangs {
+		action := "evict gang"
+		if gang.Checkpoint {
+			action = "checkpoint then evict gang"
+		}
+		fmt.Fprintf(writer, "%s %s/%s (%d pods)\n", action, gang.Namespace, gang.PodGroup, len(gang.Pods))
+	}
+	for _, pod := range plan.Pods {
+		fmt.Fprintf(writer, "evict pod %s/%s\n", pod.Namespace, pod.Name)
+	}
+}
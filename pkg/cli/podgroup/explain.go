@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podgroup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+
+	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/cli/util"
+)
+
+// maxEvents is the number of the latest warning events of the podgroup explained.
+const maxEvents = 5
+
+type explainFlags struct {
+	util.CommonFlags
+
+	Namespace string
+	Name      string
+	JobName   string
+}
+
+var explainPodGroupFlags = &explainFlags{}
+
+// InitExplainFlags init explain related flags.
+func InitExplainFlags(cmd *cobra.Command) {
+	util.InitFlags(cmd, &explainPodGroupFlags.CommonFlags)
+
+	cmd.Flags().StringVarP(&explainPodGroupFlags.Namespace, "namespace", "n", "default", "the namespace of podgroup")
+	cmd.Flags().StringVarP(&explainPodGroupFlags.Name, "name", "N", "", "the name of podgroup")
+	cmd.Flags().StringVarP(&explainPodGroupFlags.JobName, "job", "j", "", "the name of the job whose podgroup is explained")
+}
+
+// ExplainPodGroup prints why a podgroup is pending: the state and the room left in its queue, the
+// unschedulable condition set by the scheduler and the latest events of the podgroup.
+func ExplainPodGroup(ctx context.Context) error {
+	config, err := util.BuildConfig(explainPodGroupFlags.Master, explainPodGroupFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	namespace, name := explainPodGroupFlags.Namespace, explainPodGroupFlags.Name
+	if name == "" && explainPodGroupFlags.JobName == "" {
+		return fmt.Errorf("podgroup or job name is mandatory to explain a particular podgroup")
+	}
+
+	vcClient := versioned.NewForConfigOrDie(config)
+	if explainPodGroupFlags.JobName != "" {
+		job, err := vcClient.BatchV1alpha1().Jobs(namespace).Get(ctx, explainPodGroupFlags.JobName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// the podgroup of a vcjob is named after the job and its uid
+		name = fmt.Sprintf("%s-%s", job.Name, job.UID)
+	}
+
+	pg, err := vcClient.SchedulingV1beta1().PodGroups(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	queueName := pg.Spec.Queue
+	if queueName == "" {
+		queueName = "default"
+	}
+	queue, err := vcClient.SchedulingV1beta1().Queues().Get(ctx, queueName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err != nil {
+		queue = nil
+	}
+
+	kubeClient := kubernetes.NewForConfigOrDie(config)
+	events, err := kubeClient.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{"involvedObject.kind": "PodGroup", "involvedObject.name": pg.Name}.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	PrintExplanation(os.Stdout, pg, queueName, queue, events.Items)
+	return nil
+}
+
+// PrintExplanation prints the reasons why the podgroup is pending, queue is nil if it does not exist.
+func PrintExplanation(writer io.Writer, pg *v1beta1.PodGroup, queueName string, queue *v1beta1.Queue, events []v1.Event) {
+	for _, reason := range explain(pg, queueName, queue, events) {
+		if _, err := fmt.Fprintln(writer, reason); err != nil {
+			fmt.Printf("Failed to print podgroup command result: %s.\n", err)
+		}
+	}
+}
+
+// explain returns the reasons why the podgroup is pending, most significant first.
+func explain(pg *v1beta1.PodGroup, queueName string, queue *v1beta1.Queue, events []v1.Event) []string {
+	reasons := []string{fmt.Sprintf("PodGroup %s/%s is %s, minMember %d, queue %s",
+		pg.Namespace, pg.Name, pg.Status.Phase, pg.Spec.MinMember, queueName)}
+	if pg.Status.Phase == v1beta1.PodGroupRunning || pg.Status.Phase == v1beta1.PodGroupCompleted {
+		return append(reasons, "It is not pending")
+	}
+	found := false
+
+	switch {
+	case queue == nil:
+		reasons = append(reasons, fmt.Sprintf("- Queue %s does not exist", queueName))
+		found = true
+	case queue.Status.State != "" && queue.Status.State != v1beta1.QueueStateOpen:
+		reasons = append(reasons, fmt.Sprintf("- Queue %s is %s, it admits no job", queueName, queue.Status.State))
+		found = true
+	case pg.Spec.MinResources != nil:
+		for _, name := range sortedNames(*pg.Spec.MinResources) {
+			capability, bounded := queue.Spec.Capability[name]
+			if !bounded {
+				continue
+			}
+			left := capability.DeepCopy()
+			left.Sub(queue.Status.Allocated[name])
+			if required := (*pg.Spec.MinResources)[name]; left.Cmp(required) < 0 {
+				reasons = append(reasons, fmt.Sprintf("- Queue %s has %s %s left below its capability, the podgroup needs %s",
+					queueName, left.String(), name, required.String()))
+				found = true
+			}
+		}
+	}
+
+	for _, condition := range pg.Status.Conditions {
+		if condition.Type == v1beta1.PodGroupUnschedulableType && condition.Status == v1.ConditionTrue {
+			reasons = append(reasons, fmt.Sprintf("- %s: %s", condition.Reason, condition.Message))
+			found = true
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastTimestamp.After(events[j].LastTimestamp.Time)
+	})
+	warnings := 0
+	for _, event := range events {
+		if event.Type != v1.EventTypeWarning {
+			continue
+		}
+		if warnings == maxEvents {
+			break
+		}
+		warnings++
+		reasons = append(reasons, fmt.Sprintf("- %s (%s ago): %s",
+			event.Reason, util.TranslateTimestampSince(event.LastTimestamp), event.Message))
+		found = true
+	}
+
+	if !found {
+		reasons = append(reasons, "- No reason recorded yet, the scheduler may not have considered it")
+	}
+	return reasons
+}
+
+// sortedNames returns the names of the resources in order.
+func sortedNames(resources v1.ResourceList) []v1.ResourceName {
+	names := make([]v1.ResourceName, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podgroup
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+func TestExplain(t *testing.T) {
+	minResources := v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+	pg := &v1beta1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg1"},
+		Spec:       v1beta1.PodGroupSpec{MinMember: 2, Queue: "q1", MinResources: &minResources},
+		Status: v1beta1.PodGroupStatus{
+			Phase: v1beta1.PodGroupPending,
+			Conditions: []v1beta1.PodGroupCondition{
+				{Type: v1beta1.PodGroupUnschedulableType, Status: v1.ConditionTrue, Reason: "NotEnoughResources", Message: "2/2 tasks in gang unschedulable"},
+			},
+		},
+	}
+	queue := &v1beta1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       v1beta1.QueueSpec{Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}},
+		Status: v1beta1.QueueStatus{
+			State:     v1beta1.QueueStateOpen,
+			Allocated: v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")},
+		},
+	}
+	events := []v1.Event{
+		{Type: v1.EventTypeNormal, Reason: "Scheduled", LastTimestamp: metav1.NewTime(time.Now())},
+		{Type: v1.EventTypeWarning, Reason: "Unschedulable", Message: "0/3 nodes are available", LastTimestamp: metav1.NewTime(time.Now().Add(-time.Minute))},
+	}
+	// the warning is older than more normal events than are explained
+	manyEvents := []v1.Event{events[1]}
+	for i := 0; i <= maxEvents; i++ {
+		manyEvents = append(manyEvents, v1.Event{Type: v1.EventTypeNormal, Reason: "Pulled", LastTimestamp: metav1.NewTime(time.Now())})
+	}
+
+	tests := []struct {
+		name     string
+		pg       *v1beta1.PodGroup
+		queue    *v1beta1.Queue
+		events   []v1.Event
+		expected []string
+	}{
+		{
+			name:     "pending",
+			pg:       pg,
+			queue:    queue,
+			events:   events,
+			expected: []string{"has 2 cpu left below its capability", "NotEnoughResources: 2/2 tasks", "0/3 nodes are available"},
+		},
+		{
+			name:     "warning after normal events",
+			pg:       pg,
+			queue:    queue,
+			events:   manyEvents,
+			expected: []string{"0/3 nodes are available"},
+		},
+		{
+			name:     "missing queue",
+			pg:       pg,
+			expected: []string{"Queue q1 does not exist"},
+		},
+		{
+			name:     "running",
+			pg:       &v1beta1.PodGroup{Status: v1beta1.PodGroupStatus{Phase: v1beta1.PodGroupRunning}},
+			queue:    queue,
+			expected: []string{"It is not pending"},
+		},
+		{
+			name:     "no reason",
+			pg:       &v1beta1.PodGroup{Status: v1beta1.PodGroupStatus{Phase: v1beta1.PodGroupPending}},
+			queue:    queue,
+			expected: []string{"No reason recorded yet"},
+		},
+	}
+	for _, test := range tests {
+		explanation := strings.Join(explain(test.pg, "q1", test.queue, test.events), "\n")
+		for _, expected := range test.expected {
+			if !strings.Contains(explanation, expected) {
+				t.Errorf("%s: expected %q in the explanation, got:\n%s", test.name, expected, explanation)
+			}
+		}
+	}
+}
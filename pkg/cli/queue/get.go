@@ -45,6 +45,14 @@ func InitGetFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&getQueueFlags.Name, "name", "n", "", "the name of queue")
 }
 
+// InitPluginGetFlags inits the flags of the get command of the kubectl plugin, the name of the
+// queue has no shorthand as -n is the namespace of kubectl.
+func InitPluginGetFlags(cmd *cobra.Command) {
+	initFlags(cmd, &getQueueFlags.commonFlags)
+
+	cmd.Flags().StringVar(&getQueueFlags.Name, "name", "", "the name of queue")
+}
+
 // GetQueue gets a queue.
 func GetQueue(ctx context.Context) error {
 	config, err := buildConfig(getQueueFlags.Master, getQueueFlags.Kubeconfig)
@@ -24,6 +24,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
@@ -32,6 +33,8 @@ import (
 
 type listFlags struct {
 	commonFlags
+
+	Shares bool
 }
 
 const (
@@ -55,6 +58,12 @@ const (
 
 	// State is state of queue
 	State string = "State"
+
+	// WeightShare is the share of the queue in the total weight of the queues
+	WeightShare string = "WeightShare"
+
+	// AllocatedShare is the dominant share of the queue's allocated resources in its deserved ones
+	AllocatedShare string = "AllocatedShare"
 )
 
 var listQueueFlags = &listFlags{}
@@ -62,6 +71,8 @@ var listQueueFlags = &listFlags{}
 // InitListFlags inits all flags.
 func InitListFlags(cmd *cobra.Command) {
 	initFlags(cmd, &listQueueFlags.commonFlags)
+
+	cmd.Flags().BoolVarP(&listQueueFlags.Shares, "shares", "", false, "print the weight and allocated shares of the queues")
 }
 
 // ListQueue lists all the queue.
@@ -81,7 +92,11 @@ func ListQueue(ctx context.Context) error {
 		fmt.Printf("No resources found\n")
 		return nil
 	}
-	PrintQueues(queues, os.Stdout)
+	if listQueueFlags.Shares {
+		PrintQueueShares(queues, os.Stdout)
+	} else {
+		PrintQueues(queues, os.Stdout)
+	}
 
 	return nil
 }
@@ -102,3 +117,55 @@ func PrintQueues(queues *v1beta1.QueueList, writer io.Writer) {
 		}
 	}
 }
+
+// PrintQueueShares prints the weight share of the queues and the dominant share of their allocated
+// resources in their deserved ones, or their capability if they deserve none.
+func PrintQueueShares(queues *v1beta1.QueueList, writer io.Writer) {
+	var totalWeight int32
+	for _, queue := range queues.Items {
+		totalWeight += queue.Spec.Weight
+	}
+
+	_, err := fmt.Fprintf(writer, "%-25s%-8s%-8s%-13s%-16s\n",
+		Name, Weight, State, WeightShare, AllocatedShare)
+	if err != nil {
+		fmt.Printf("Failed to print queue command result: %s.\n", err)
+	}
+	for _, queue := range queues.Items {
+		weightShare := "-"
+		if totalWeight > 0 {
+			weightShare = fmt.Sprintf("%.1f%%", float64(queue.Spec.Weight)*100/float64(totalWeight))
+		}
+		allocatedShare := "-"
+		if share, ok := dominantShare(queue.Status.Allocated, queue.Spec.Deserved, queue.Spec.Capability); ok {
+			allocatedShare = fmt.Sprintf("%.1f%%", share*100)
+		}
+		_, err = fmt.Fprintf(writer, "%-25s%-8d%-8s%-13s%-16s\n",
+			queue.Name, queue.Spec.Weight, queue.Status.State, weightShare, allocatedShare)
+		if err != nil {
+			fmt.Printf("Failed to print queue command result: %s.\n", err)
+		}
+	}
+}
+
+// dominantShare returns the largest share of the allocated resources in the deserved ones, or in
+// the capability if nothing is deserved, false if the queue is bounded by neither.
+func dominantShare(allocated, deserved, capability v1.ResourceList) (float64, bool) {
+	total := deserved
+	if len(total) == 0 {
+		total = capability
+	}
+	var share float64
+	found := false
+	for name, quantity := range total {
+		if quantity.IsZero() {
+			continue
+		}
+		found = true
+		used := allocated[name]
+		if s := used.AsApproximateFloat64() / quantity.AsApproximateFloat64(); s > share {
+			share = s
+		}
+	}
+	return share, found
+}
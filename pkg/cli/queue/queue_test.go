@@ -17,15 +17,19 @@ limitations under the License.
 package queue
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
@@ -192,3 +196,39 @@ func TestListQueue_nonempty(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintQueueShares(t *testing.T) {
+	queues := &v1beta1.QueueList{
+		Items: []v1beta1.Queue{
+			{
+				ObjectMeta: v1.ObjectMeta{Name: "q1"},
+				Spec: v1beta1.QueueSpec{
+					Weight:   3,
+					Deserved: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi")},
+				},
+				Status: v1beta1.QueueStatus{
+					State:     v1beta1.QueueStateOpen,
+					Allocated: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("6Gi")},
+				},
+			},
+			{
+				ObjectMeta: v1.ObjectMeta{Name: "q2"},
+				Spec:       v1beta1.QueueSpec{Weight: 1},
+				Status:     v1beta1.QueueStatus{State: v1beta1.QueueStateOpen},
+			},
+		},
+	}
+
+	var buffer bytes.Buffer
+	PrintQueueShares(queues, &buffer)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 queues, got %q", buffer.String())
+	}
+	if fields := strings.Fields(lines[1]); fields[3] != "75.0%" || fields[4] != "75.0%" {
+		t.Errorf("expected q1 with 75%% of the weight and 75%% of its deserved memory, got %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); fields[3] != "25.0%" || fields[4] != "-" {
+		t.Errorf("expected q2 with 25%% of the weight and no allocated share, got %q", lines[2])
+	}
+}
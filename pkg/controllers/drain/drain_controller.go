@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/controllers/framework"
+)
+
+const (
+	// checkpointTimeout is how long the large gangs are given to checkpoint before being evicted
+	checkpointTimeout = 10 * time.Minute
+	// resyncPeriod is how often a node is drained again while pods are left on it
+	resyncPeriod = 30 * time.Second
+)
+
+func init() {
+	framework.RegisterController(&draincontroller{})
+}
+
+// draincontroller drains the nodes annotated with DrainAnnotation gang by gang: the small gangs with
+// pods on the node are evicted as a whole so that they are rescheduled together, the large ones
+// are requested to checkpoint first, and the other pods are evicted one by one.
+type draincontroller struct {
+	kubeClient kubernetes.Interface
+
+	informerFactory informers.SharedInformerFactory
+	nodeInformer    coreinformers.NodeInformer
+	podInformer     coreinformers.PodInformer
+	nodeLister      corelisters.NodeLister
+	podLister       corelisters.PodLister
+	nodeSynced      func() bool
+	podSynced       func() bool
+
+	// queue of the names of the nodes to drain
+	queue workqueue.RateLimitingInterface
+}
+
+func (dc *draincontroller) Name() string {
+	return "drain-controller"
+}
+
+// Initialize creates an instance of draincontroller.
+func (dc *draincontroller) Initialize(opt *framework.ControllerOption) error {
+	dc.kubeClient = opt.KubeClient
+	dc.informerFactory = opt.SharedInformerFactory
+	dc.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	dc.nodeInformer = opt.SharedInformerFactory.Core().V1().Nodes()
+	dc.nodeLister = dc.nodeInformer.Lister()
+	dc.nodeSynced = dc.nodeInformer.Informer().HasSynced
+	dc.nodeInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			node, ok := obj.(*v1.Node)
+			return ok && drainRequested(node)
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: dc.enqueueNode,
+			UpdateFunc: func(_, newObj interface{}) {
+				dc.enqueueNode(newObj)
+			},
+		},
+	})
+
+	dc.podInformer = opt.SharedInformerFactory.Core().V1().Pods()
+	dc.podLister = dc.podInformer.Lister()
+	dc.podSynced = dc.podInformer.Informer().HasSynced
+	return nil
+}
+
+// Run starts the worker draining the nodes.
+func (dc *draincontroller) Run(stopCh <-chan struct{}) {
+	defer dc.queue.ShutDown()
+
+	dc.informerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, dc.nodeSynced, dc.podSynced) {
+		klog.Errorf("caches failed to sync for %s", dc.Name())
+		return
+	}
+
+	go wait.Until(dc.worker, time.Second, stopCh)
+	klog.Infof("DrainController is running ...... ")
+	<-stopCh
+}
+
+func drainRequested(node *v1.Node) bool {
+	return node.Annotations[DrainAnnotation] == "true"
+}
+
+func (dc *draincontroller) enqueueNode(obj interface{}) {
+	if node, ok := obj.(*v1.Node); ok {
+		dc.queue.Add(node.Name)
+	}
+}
+
+func (dc *draincontroller) worker() {
+	for dc.processNextNode() {
+	}
+}
+
+func (dc *draincontroller) processNextNode() bool {
+	obj, shutdown := dc.queue.Get()
+	if shutdown {
+		return false
+	}
+	name := obj.(string)
+	defer dc.queue.Done(name)
+
+	done, err := dc.syncNode(context.TODO(), name, time.Now())
+	if err != nil {
+		klog.Errorf("Failed to drain node <%s>: %v", name, err)
+		dc.queue.AddRateLimited(name)
+		return true
+	}
+	dc.queue.Forget(name)
+	if !done {
+		dc.queue.AddAfter(name, resyncPeriod)
+	}
+	return true
+}
+
+// syncNode makes a step of the drain of the node, it returns whether no pod is left to evict.
+func (dc *draincontroller) syncNode(ctx context.Context, name string, now time.Time) (bool, error) {
+	node, err := dc.nodeLister.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if !drainRequested(node) {
+		return true, nil
+	}
+
+	if !node.Spec.Unschedulable {
+		patch := []byte(`{"spec":{"unschedulable":true}}`)
+		if _, err := dc.kubeClient.CoreV1().Nodes().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return false, fmt.Errorf("failed to cordon node: %v", err)
+		}
+	}
+
+	pods, err := dc.podLister.List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	plan := BuildPlan(name, pods, SmallGangSize(node))
+	if plan.Done() {
+		klog.V(3).Infof("Node <%s> is drained", name)
+		return true, nil
+	}
+
+	for _, gang := range plan.Gangs {
+		if gang.Checkpoint && !gang.CheckpointDue(now, checkpointTimeout) {
+			if err := dc.requestCheckpoint(ctx, gang, now); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err := dc.evictGang(ctx, gang, name); err != nil {
+			return false, err
+		}
+	}
+	for _, pod := range plan.Pods {
+		if err := dc.evictPod(ctx, pod, false); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// requestCheckpoint annotates the pods of the gang not requested to checkpoint yet.
+func (dc *draincontroller) requestCheckpoint(ctx context.Context, gang *Gang, now time.Time) error {
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, CheckpointAnnotation, now.UTC().Format(time.RFC3339)))
+	for _, pod := range gang.Pods {
+		if _, found := pod.Annotations[CheckpointAnnotation]; found {
+			continue
+		}
+		klog.V(3).Infof("Request pod <%s/%s> of gang <%s> to checkpoint", pod.Namespace, pod.Name, gang.PodGroup)
+		if _, err := dc.kubeClient.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to request pod <%s/%s> to checkpoint: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// evictGang evicts the pods of the gang as a whole or none of them: the evictions are only made
+// once all of them passed a dry-run, the gang is left for the next step of the drain otherwise.
+// Once started, the evictions of the gang are all attempted even if some of them fail.
+func (dc *draincontroller) evictGang(ctx context.Context, gang *Gang, node string) error {
+	for _, pod := range gang.Pods {
+		if err := dc.evictPod(ctx, pod, true); err != nil {
+			klog.V(3).Infof("Gang <%s/%s> is not evicted from node <%s> yet: %v", gang.Namespace, gang.PodGroup, node, err)
+			return nil
+		}
+	}
+
+	klog.V(3).Infof("Evict the %d pods of gang <%s/%s> to drain node <%s>",
+		len(gang.Pods), gang.Namespace, gang.PodGroup, node)
+	var errs []error
+	for _, pod := range gang.Pods {
+		if err := dc.evictPod(ctx, pod, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// evictPod evicts the pod through the eviction API so that its PodDisruptionBudget is respected, or
+// only checks that it can be evicted when dryRun is set.
+func (dc *draincontroller) evictPod(ctx context.Context, pod *v1.Pod, dryRun bool) error {
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name}}
+	if dryRun {
+		eviction.DeleteOptions = &metav1.DeleteOptions{DryRun: []string{metav1.DryRunAll}}
+	}
+	err := dc.kubeClient.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to evict pod <%s/%s>: %v", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
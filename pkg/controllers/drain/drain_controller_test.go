@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSyncNodeEvictsWholeGangs(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1", Annotations: map[string]string{DrainAnnotation: "true"}},
+		Spec:       v1.NodeSpec{Unschedulable: true},
+	}
+	pods := []*v1.Pod{
+		buildPod("free-0", "n1", "free"),
+		buildPod("free-1", "n2", "free"),
+		buildPod("budgeted-0", "n1", "budgeted"),
+		buildPod("budgeted-1", "n2", "budgeted"),
+	}
+
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	nodeIndexer.Add(node)
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, pod := range pods {
+		podIndexer.Add(pod)
+	}
+
+	// the PodDisruptionBudget of gang budgeted refuses the eviction of budgeted-1
+	client := fake.NewSimpleClientset(node)
+	var evicted []string
+	client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(clienttesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if eviction.Name == "budgeted-1" {
+			return true, nil, apierrors.NewTooManyRequests("disruption budget exceeded", 10)
+		}
+		if eviction.DeleteOptions == nil || len(eviction.DeleteOptions.DryRun) == 0 {
+			evicted = append(evicted, eviction.Name)
+		}
+		return true, nil, nil
+	})
+
+	dc := &draincontroller{
+		kubeClient: client,
+		nodeLister: corelisters.NewNodeLister(nodeIndexer),
+		podLister:  corelisters.NewPodLister(podIndexer),
+	}
+	done, err := dc.syncNode(context.TODO(), "n1", time.Now())
+	if err != nil || done {
+		t.Fatalf("expected the drain in progress, got done %v, error %v", done, err)
+	}
+	sort.Strings(evicted)
+	if len(evicted) != 2 || evicted[0] != "free-0" || evicted[1] != "free-1" {
+		t.Errorf("expected only the pods of gang free evicted, got %v", evicted)
+	}
+}
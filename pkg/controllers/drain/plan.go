@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+const (
+	// DrainAnnotation on a node requests the drain controller to drain it gang by gang
+	DrainAnnotation = "volcano.sh/drain"
+	// SmallGangSizeAnnotation on a node overrides the number of pods up to which a gang is migrated at once
+	SmallGangSizeAnnotation = "volcano.sh/drain-small-gang-size"
+	// CheckpointAnnotation is set on the pods of the gangs too large to be migrated at once, with the
+	// time the checkpoint was requested, so that the workload checkpoints before being evicted
+	CheckpointAnnotation = "volcano.sh/checkpoint-requested"
+
+	// DefaultSmallGangSize is the number of pods up to which a gang is migrated at once
+	DefaultSmallGangSize = 8
+)
+
+// Gang is a PodGroup with pods on the drained node.
+type Gang struct {
+	Namespace string
+	PodGroup  string
+	// Pods are all the pods of the gang, on any node
+	Pods []*v1.Pod
+	// Checkpoint is set when the gang is too large to be migrated at once and is requested to
+	// checkpoint before being evicted
+	Checkpoint bool
+}
+
+// Plan is how the pods of a node are evicted: the gangs as a whole, the other pods one by one.
+type Plan struct {
+	Node  string
+	Gangs []*Gang
+	// Pods are the pods on the node which are not part of a gang
+	Pods []*v1.Pod
+}
+
+// Done returns whether no pod is left to evict from the node.
+func (p *Plan) Done() bool {
+	return len(p.Gangs) == 0 && len(p.Pods) == 0
+}
+
+// SmallGangSize returns the number of pods up to which the gangs on the node are migrated at once.
+func SmallGangSize(node *v1.Node) int {
+	if value, found := node.Annotations[SmallGangSizeAnnotation]; found {
+		if size, err := strconv.Atoi(value); err == nil && size >= 0 {
+			return size
+		}
+	}
+	return DefaultSmallGangSize
+}
+
+// BuildPlan returns the plan to drain the node, pods are all the pods of the cluster so that the
+// gangs with pods on the node are evicted as a whole.
+func BuildPlan(node string, pods []*v1.Pod, smallGangSize int) *Plan {
+	plan := &Plan{Node: node}
+	gangPods := map[string][]*v1.Pod{}
+	onNode := map[string]bool{}
+	for _, pod := range pods {
+		if !evictable(pod) {
+			continue
+		}
+		pgName := pod.Annotations[scheduling.KubeGroupNameAnnotationKey]
+		if pgName == "" {
+			if pod.Spec.NodeName == node {
+				plan.Pods = append(plan.Pods, pod)
+			}
+			continue
+		}
+		key := pod.Namespace + "/" + pgName
+		gangPods[key] = append(gangPods[key], pod)
+		if pod.Spec.NodeName == node {
+			onNode[key] = true
+		}
+	}
+
+	for key := range onNode {
+		pods := gangPods[key]
+		sortPods(pods)
+		plan.Gangs = append(plan.Gangs, &Gang{
+			Namespace:  pods[0].Namespace,
+			PodGroup:   pods[0].Annotations[scheduling.KubeGroupNameAnnotationKey],
+			Pods:       pods,
+			Checkpoint: len(pods) > smallGangSize,
+		})
+	}
+	sort.Slice(plan.Gangs, func(i, j int) bool {
+		if plan.Gangs[i].Namespace != plan.Gangs[j].Namespace {
+			return plan.Gangs[i].Namespace < plan.Gangs[j].Namespace
+		}
+		return plan.Gangs[i].PodGroup < plan.Gangs[j].PodGroup
+	})
+	sortPods(plan.Pods)
+	return plan
+}
+
+// CheckpointDue returns whether the checkpoint of the gang was requested for longer than timeout,
+// false if it was not requested on all its pods yet.
+func (g *Gang) CheckpointDue(now time.Time, timeout time.Duration) bool {
+	for _, pod := range g.Pods {
+		requested, err := time.Parse(time.RFC3339, pod.Annotations[CheckpointAnnotation])
+		if err != nil || now.Sub(requested) < timeout {
+			return false
+		}
+	}
+	return true
+}
+
+// evictable returns whether the pod is evicted by a drain: the terminated pods, the mirror pods and
+// the pods of DaemonSets are left as kubectl drain does.
+func evictable(pod *v1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return false
+	}
+	if _, found := pod.Annotations[v1.MirrorPodAnnotationKey]; found {
+		return false
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller != nil && *owner.Controller && owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+func sortPods(pods []*v1.Pod) {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
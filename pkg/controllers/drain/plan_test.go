@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+func buildPod(name, node, podGroup string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name, Annotations: map[string]string{}},
+		Spec:       v1.PodSpec{NodeName: node},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+	if podGroup != "" {
+		pod.Annotations[scheduling.KubeGroupNameAnnotationKey] = podGroup
+	}
+	return pod
+}
+
+func TestBuildPlan(t *testing.T) {
+	isController := true
+	daemon := buildPod("daemon", "n1", "")
+	daemon.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "ds", Controller: &isController}}
+	succeeded := buildPod("succeeded", "n1", "")
+	succeeded.Status.Phase = v1.PodSucceeded
+
+	pods := []*v1.Pod{
+		buildPod("small-0", "n1", "small"),
+		buildPod("small-1", "n2", "small"),
+		buildPod("large-0", "n1", "large"),
+		buildPod("large-1", "n2", "large"),
+		buildPod("large-2", "n3", "large"),
+		buildPod("other-0", "n2", "other"),
+		buildPod("single", "n1", ""),
+		buildPod("elsewhere", "n2", ""),
+		daemon,
+		succeeded,
+	}
+
+	plan := BuildPlan("n1", pods, 2)
+	if len(plan.Gangs) != 2 {
+		t.Fatalf("expected 2 gangs with pods on the node, got %d", len(plan.Gangs))
+	}
+	large, small := plan.Gangs[0], plan.Gangs[1]
+	if large.PodGroup != "large" || !large.Checkpoint || len(large.Pods) != 3 {
+		t.Errorf("expected the 3 pods of gang large to checkpoint, got %+v", large)
+	}
+	if small.PodGroup != "small" || small.Checkpoint || len(small.Pods) != 2 {
+		t.Errorf("expected the 2 pods of gang small to be migrated at once, got %+v", small)
+	}
+	if len(plan.Pods) != 1 || plan.Pods[0].Name != "single" {
+		t.Errorf("expected only pod single to be evicted alone, got %v", plan.Pods)
+	}
+
+	if plan := BuildPlan("n4", pods, 2); !plan.Done() {
+		t.Errorf("expected nothing to evict from an empty node, got %+v", plan)
+	}
+}
+
+func TestCheckpointDue(t *testing.T) {
+	now := time.Now()
+	gang := &Gang{Pods: []*v1.Pod{buildPod("p0", "n1", "pg"), buildPod("p1", "n2", "pg")}}
+	if gang.CheckpointDue(now, time.Minute) {
+		t.Errorf("expected no checkpoint due before it was requested")
+	}
+
+	gang.Pods[0].Annotations[CheckpointAnnotation] = now.Add(-2 * time.Minute).Format(time.RFC3339)
+	if gang.CheckpointDue(now, time.Minute) {
+		t.Errorf("expected no checkpoint due before it was requested on all pods")
+	}
+
+	gang.Pods[1].Annotations[CheckpointAnnotation] = now.Add(-30 * time.Second).Format(time.RFC3339)
+	if gang.CheckpointDue(now, time.Minute) {
+		t.Errorf("expected no checkpoint due before the timeout")
+	}
+	if !gang.CheckpointDue(now.Add(time.Minute), time.Minute) {
+		t.Errorf("expected the checkpoint due after the timeout")
+	}
+}
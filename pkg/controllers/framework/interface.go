@@ -17,6 +17,8 @@ limitations under the License.
 package framework
 
 import (
+	"time"
+
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -39,6 +41,14 @@ type ControllerOption struct {
 	WorkerThreadsForPG      uint32
 	WorkerThreadsForGC      uint32
 
+	// HistoryStore is the URL of the store the finished jobs are archived to, HistoryRetention
+	// how long they are kept there
+	HistoryStore     string
+	HistoryRetention time.Duration
+
+	// NotificationEndpoints are the only endpoints notified of the state changes of the jobs
+	NotificationEndpoints []string
+
 	// Config holds the common attributes that can be passed to a Kubernetes client
 	// and controllers registered by the users can use it.
 	Config *rest.Config
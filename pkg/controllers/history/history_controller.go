@@ -0,0 +1,335 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	vcinformer "volcano.sh/apis/pkg/client/informers/externalversions"
+	batchlisters "volcano.sh/apis/pkg/client/listers/batch/v1alpha1"
+	schedulinglisters "volcano.sh/apis/pkg/client/listers/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/controllers/framework"
+)
+
+const (
+	// maxRetries is how many times archiving a job is retried before its record is dropped
+	maxRetries = 15
+	// pruneInterval is how often the records older than the retention are pruned
+	pruneInterval = time.Hour
+)
+
+func init() {
+	framework.RegisterController(&historycontroller{})
+}
+
+// Record is the archived history of a job: its spec and final status, its podgroup with the
+// phase history of its queueing, the timeline of its phases and where its pods last ran.
+type Record struct {
+	Job        *batch.Job           `json:"job"`
+	PodGroup   *scheduling.PodGroup `json:"podGroup,omitempty"`
+	Timeline   []batch.JobCondition `json:"timeline,omitempty"`
+	Placements []Placement          `json:"placements,omitempty"`
+	// Deleted is set when the job was deleted before it finished
+	Deleted    bool        `json:"deleted,omitempty"`
+	ArchivedAt metav1.Time `json:"archivedAt"`
+}
+
+// Placement is the node a pod of the job was bound to.
+type Placement struct {
+	Pod       string       `json:"pod"`
+	Task      string       `json:"task,omitempty"`
+	Node      string       `json:"node,omitempty"`
+	Phase     v1.PodPhase  `json:"phase"`
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// jobKey is the key of a job to archive in the queue, the UID tells apart the jobs recreated
+// with the same name.
+type jobKey struct {
+	namespace string
+	name      string
+	uid       types.UID
+}
+
+func keyOf(job *batch.Job) jobKey {
+	return jobKey{namespace: job.Namespace, name: job.Name, uid: job.UID}
+}
+
+// historycontroller archives the jobs to an external store once they finished or were deleted,
+// as the jobs, their podgroups and pods are garbage collected from the cluster.
+type historycontroller struct {
+	store     Store
+	retention time.Duration
+
+	informerFactory   informers.SharedInformerFactory
+	vcInformerFactory vcinformer.SharedInformerFactory
+	jobLister         batchlisters.JobLister
+	podLister         corelisters.PodLister
+	pgLister          schedulinglisters.PodGroupLister
+	jobSynced         func() bool
+	podSynced         func() bool
+	pgSynced          func() bool
+
+	// queue of the keys of the jobs to archive
+	queue workqueue.RateLimitingInterface
+
+	// archived are the UIDs of the existing jobs already archived, and deleted the last state of
+	// the deleted jobs to archive by UID
+	mutex    sync.Mutex
+	archived map[types.UID]bool
+	deleted  map[types.UID]*batch.Job
+}
+
+func (hc *historycontroller) Name() string {
+	return "history-controller"
+}
+
+// Initialize creates an instance of historycontroller, which does nothing without a store.
+func (hc *historycontroller) Initialize(opt *framework.ControllerOption) error {
+	if len(opt.HistoryStore) == 0 {
+		return nil
+	}
+	store, err := NewStore(opt.HistoryStore)
+	if err != nil {
+		return err
+	}
+	hc.store = store
+	hc.retention = opt.HistoryRetention
+	hc.archived = map[types.UID]bool{}
+	hc.deleted = map[types.UID]*batch.Job{}
+	hc.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	hc.informerFactory = opt.SharedInformerFactory
+	podInformer := opt.SharedInformerFactory.Core().V1().Pods()
+	hc.podLister = podInformer.Lister()
+	hc.podSynced = podInformer.Informer().HasSynced
+
+	hc.vcInformerFactory = opt.VCSharedInformerFactory
+	pgInformer := opt.VCSharedInformerFactory.Scheduling().V1beta1().PodGroups()
+	hc.pgLister = pgInformer.Lister()
+	hc.pgSynced = pgInformer.Informer().HasSynced
+
+	jobInformer := opt.VCSharedInformerFactory.Batch().V1alpha1().Jobs()
+	hc.jobLister = jobInformer.Lister()
+	hc.jobSynced = jobInformer.Informer().HasSynced
+	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: hc.addJob,
+		UpdateFunc: func(_, newObj interface{}) {
+			hc.addJob(newObj)
+		},
+		DeleteFunc: hc.deleteJob,
+	})
+	return nil
+}
+
+// Run starts the worker archiving the jobs.
+func (hc *historycontroller) Run(stopCh <-chan struct{}) {
+	if hc.store == nil {
+		klog.V(3).Infof("No history store configured, %s is idle", hc.Name())
+		return
+	}
+	defer hc.queue.ShutDown()
+
+	hc.informerFactory.Start(stopCh)
+	hc.vcInformerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, hc.jobSynced, hc.podSynced, hc.pgSynced) {
+		klog.Errorf("caches failed to sync for %s", hc.Name())
+		return
+	}
+
+	go wait.Until(hc.worker, time.Second, stopCh)
+	if hc.retention > 0 {
+		go wait.Until(hc.prune, pruneInterval, stopCh)
+	}
+	klog.Infof("HistoryController is running ...... ")
+	<-stopCh
+}
+
+func isFinished(job *batch.Job) bool {
+	switch job.Status.State.Phase {
+	case batch.Completed, batch.Failed, batch.Terminated:
+		return true
+	}
+	return false
+}
+
+func (hc *historycontroller) isArchived(uid types.UID) bool {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	return hc.archived[uid]
+}
+
+func (hc *historycontroller) addJob(obj interface{}) {
+	job, ok := obj.(*batch.Job)
+	if !ok || !isFinished(job) || hc.isArchived(job.UID) {
+		return
+	}
+	hc.queue.Add(keyOf(job))
+}
+
+func (hc *historycontroller) deleteJob(obj interface{}) {
+	job, ok := obj.(*batch.Job)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if job, ok = tombstone.Obj.(*batch.Job); !ok {
+			return
+		}
+	}
+
+	hc.mutex.Lock()
+	archived := hc.archived[job.UID]
+	delete(hc.archived, job.UID)
+	if !archived {
+		hc.deleted[job.UID] = job
+	}
+	hc.mutex.Unlock()
+	if !archived {
+		hc.queue.Add(keyOf(job))
+	}
+}
+
+// recordOf builds the record of the job of the key, from its last state if it was deleted. It
+// returns nil if the job is to be archived no more. The deleted job the record is built from is
+// returned too.
+func (hc *historycontroller) recordOf(key jobKey) (*Record, *batch.Job) {
+	hc.mutex.Lock()
+	deleted, found := hc.deleted[key.uid]
+	archived := hc.archived[key.uid]
+	hc.mutex.Unlock()
+	if found {
+		return hc.buildRecord(deleted, !isFinished(deleted)), deleted
+	}
+	if archived {
+		return nil, nil
+	}
+
+	job, err := hc.jobLister.Jobs(key.namespace).Get(key.name)
+	if err != nil || job.UID != key.uid || !isFinished(job) {
+		return nil, nil
+	}
+	return hc.buildRecord(job, false), nil
+}
+
+// buildRecord gathers the history of the job from its podgroup and pods while they still exist.
+func (hc *historycontroller) buildRecord(job *batch.Job, deleted bool) *Record {
+	record := &Record{
+		Job:        job.DeepCopy(),
+		Timeline:   job.Status.Conditions,
+		Deleted:    deleted,
+		ArchivedAt: metav1.Now(),
+	}
+	record.Job.ManagedFields = nil
+
+	pg, err := hc.pgLister.PodGroups(job.Namespace).Get(job.Name + "-" + string(job.UID))
+	if err == nil {
+		record.PodGroup = pg.DeepCopy()
+		record.PodGroup.ManagedFields = nil
+	} else if !apierrors.IsNotFound(err) {
+		klog.Warningf("Failed to get the podgroup of job <%s/%s>: %v", job.Namespace, job.Name, err)
+	}
+
+	pods, err := hc.podLister.Pods(job.Namespace).List(labels.SelectorFromSet(labels.Set{batch.JobNameKey: job.Name}))
+	if err != nil {
+		klog.Warningf("Failed to list the pods of job <%s/%s>: %v", job.Namespace, job.Name, err)
+	}
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(pod); owner == nil || owner.UID != job.UID {
+			continue
+		}
+		record.Placements = append(record.Placements, Placement{
+			Pod:       pod.Name,
+			Task:      pod.Labels[batch.TaskSpecKey],
+			Node:      pod.Spec.NodeName,
+			Phase:     pod.Status.Phase,
+			StartTime: pod.Status.StartTime,
+		})
+	}
+	sort.Slice(record.Placements, func(i, j int) bool {
+		return record.Placements[i].Pod < record.Placements[j].Pod
+	})
+	return record
+}
+
+func (hc *historycontroller) worker() {
+	for hc.processNextRecord() {
+	}
+}
+
+func (hc *historycontroller) processNextRecord() bool {
+	obj, shutdown := hc.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer hc.queue.Done(obj)
+	key := obj.(jobKey)
+
+	// the record is built when it is archived, the events of the job queued meanwhile make one
+	record, deleted := hc.recordOf(key)
+	if record == nil {
+		hc.queue.Forget(obj)
+		return true
+	}
+
+	if err := hc.store.Archive(record); err != nil {
+		if hc.queue.NumRequeues(obj) < maxRetries {
+			klog.Warningf("Failed to archive job <%s/%s>, will retry: %v", record.Job.Namespace, record.Job.Name, err)
+			hc.queue.AddRateLimited(obj)
+			return true
+		}
+		klog.Errorf("Dropped the history of job <%s/%s> after %d retries: %v", record.Job.Namespace, record.Job.Name, maxRetries, err)
+	} else {
+		klog.V(3).Infof("Archived job <%s/%s>", record.Job.Namespace, record.Job.Name)
+	}
+	hc.queue.Forget(obj)
+
+	// only the jobs still in the cluster are remembered, they are forgotten once deleted
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	if deleted != nil {
+		if hc.deleted[key.uid] == deleted {
+			delete(hc.deleted, key.uid)
+		}
+	} else if job, err := hc.jobLister.Jobs(key.namespace).Get(key.name); err == nil && job.UID == key.uid {
+		hc.archived[job.UID] = true
+	}
+	return true
+}
+
+func (hc *historycontroller) prune() {
+	if err := hc.store.Prune(time.Now().Add(-hc.retention)); err != nil {
+		klog.Errorf("Failed to prune the job history: %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+	vcinformer "volcano.sh/apis/pkg/client/informers/externalversions"
+)
+
+func newTestJob() *batch.Job {
+	return &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", UID: "uid"},
+		Status: batch.JobStatus{
+			State: batch.JobState{Phase: batch.Completed},
+			Conditions: []batch.JobCondition{
+				{Status: batch.Running},
+				{Status: batch.Completed},
+			},
+		},
+	}
+}
+
+func TestBuildRecord(t *testing.T) {
+	job := newTestJob()
+	controller := true
+	newPod := func(name, node string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      name,
+				Labels:    map[string]string{batch.JobNameKey: "job", batch.TaskSpecKey: "worker"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Job", Name: "job", UID: job.UID, Controller: &controller},
+				},
+			},
+			Spec:   v1.PodSpec{NodeName: node},
+			Status: v1.PodStatus{Phase: v1.PodSucceeded},
+		}
+	}
+	stale := newPod("job-worker-9", "n9")
+	stale.OwnerReferences[0].UID = "old"
+
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	vcInformerFactory := vcinformer.NewSharedInformerFactory(vcfake.NewSimpleClientset(), 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	pgInformer := vcInformerFactory.Scheduling().V1beta1().PodGroups()
+	for _, pod := range []*v1.Pod{newPod("job-worker-1", "n2"), newPod("job-worker-0", "n1"), stale} {
+		podInformer.Informer().GetIndexer().Add(pod)
+	}
+	pgInformer.Informer().GetIndexer().Add(&scheduling.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job-uid"},
+	})
+	hc := &historycontroller{
+		podLister: podInformer.Lister(),
+		pgLister:  pgInformer.Lister(),
+	}
+
+	record := hc.buildRecord(job, false)
+	if record.PodGroup == nil || record.PodGroup.Name != "job-uid" {
+		t.Errorf("expected the podgroup job-uid in the record, got %v", record.PodGroup)
+	}
+	if len(record.Timeline) != 2 {
+		t.Errorf("expected 2 phases in the timeline, got %v", record.Timeline)
+	}
+	expected := []Placement{
+		{Pod: "job-worker-0", Task: "worker", Node: "n1", Phase: v1.PodSucceeded},
+		{Pod: "job-worker-1", Task: "worker", Node: "n2", Phase: v1.PodSucceeded},
+	}
+	if len(record.Placements) != len(expected) {
+		t.Fatalf("expected placements %v, got %v", expected, record.Placements)
+	}
+	for i := range expected {
+		if record.Placements[i] != expected[i] {
+			t.Errorf("expected placement %v, got %v", expected[i], record.Placements[i])
+		}
+	}
+}
+
+// fakeStore keeps the archived records.
+type fakeStore struct {
+	records []*Record
+}
+
+func (fs *fakeStore) Archive(record *Record) error {
+	fs.records = append(fs.records, record)
+	return nil
+}
+
+func (fs *fakeStore) Prune(time.Time) error {
+	return nil
+}
+
+func TestProcessNextRecord(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	vcInformerFactory := vcinformer.NewSharedInformerFactory(vcfake.NewSimpleClientset(), 0)
+	jobInformer := vcInformerFactory.Batch().V1alpha1().Jobs()
+	store := &fakeStore{}
+	hc := &historycontroller{
+		store:     store,
+		jobLister: jobInformer.Lister(),
+		podLister: informerFactory.Core().V1().Pods().Lister(),
+		pgLister:  vcInformerFactory.Scheduling().V1beta1().PodGroups().Lister(),
+		queue:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		archived:  map[types.UID]bool{},
+		deleted:   map[types.UID]*batch.Job{},
+	}
+	defer hc.queue.ShutDown()
+
+	// the updates of a finished job make one record
+	job := newTestJob()
+	jobInformer.Informer().GetIndexer().Add(job)
+	hc.addJob(job)
+	hc.addJob(job)
+	if hc.queue.Len() != 1 {
+		t.Fatalf("expected the updates of the job to be queued once, got %d", hc.queue.Len())
+	}
+	hc.processNextRecord()
+	if len(store.records) != 1 || store.records[0].Deleted {
+		t.Fatalf("expected one record of the finished job, got %v", store.records)
+	}
+	hc.addJob(job)
+	if hc.queue.Len() != 0 {
+		t.Fatalf("expected the archived job not to be queued again, got %d", hc.queue.Len())
+	}
+
+	// the update of a finished job and its deletion queued before it is archived make one record
+	deleted := newTestJob()
+	deleted.UID = "deleted"
+	jobInformer.Informer().GetIndexer().Add(deleted)
+	hc.addJob(deleted)
+	jobInformer.Informer().GetIndexer().Delete(deleted)
+	hc.deleteJob(deleted)
+	if hc.queue.Len() != 1 {
+		t.Fatalf("expected the update and the deletion of the job to be queued once, got %d", hc.queue.Len())
+	}
+	hc.processNextRecord()
+	if len(store.records) != 2 || store.records[1].Job.UID != "deleted" {
+		t.Fatalf("expected one record of the deleted job, got %v", store.records)
+	}
+
+	// a job deleted before it finished is archived from its last state
+	running := newTestJob()
+	running.UID = "running"
+	running.Status.State.Phase = batch.Running
+	hc.deleteJob(running)
+	hc.processNextRecord()
+	if len(store.records) != 3 || !store.records[2].Deleted || store.records[2].Job.UID != "running" {
+		t.Fatalf("expected the record of the job deleted while running, got %v", store.records)
+	}
+	if len(hc.deleted) != 0 {
+		t.Errorf("expected the deleted jobs to be forgotten once archived, got %v", hc.deleted)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore("file://" + dir)
+	if err != nil {
+		t.Fatalf("failed to create the store: %v", err)
+	}
+	if err := store.Archive(&Record{Job: newTestJob()}); err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+
+	path := filepath.Join(dir, "ns", "job-uid.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected the record at %s: %v", path, err)
+	}
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil || record.Job.Name != "job" {
+		t.Errorf("expected the record of job, got %s: %v", data, err)
+	}
+
+	if err := store.Prune(time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the recent record to be kept: %v", err)
+	}
+	if err := store.Prune(time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the old record to be pruned, got %v", err)
+	}
+}
+
+func TestHTTPStore(t *testing.T) {
+	var method, path string
+	var record Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &record)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	store, err := NewStore(server.URL + "/volcano-history/_doc/")
+	if err != nil {
+		t.Fatalf("failed to create the store: %v", err)
+	}
+	if err := store.Archive(&Record{Job: newTestJob()}); err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+	if method != http.MethodPut || path != "/volcano-history/_doc/uid" {
+		t.Errorf("expected PUT /volcano-history/_doc/uid, got %s %s", method, path)
+	}
+	if record.Job == nil || record.Job.Name != "job" {
+		t.Errorf("expected the record of job, got %v", record)
+	}
+
+	if _, err := NewStore("s3://bucket/history"); err == nil {
+		t.Errorf("expected an error for an unregistered scheme")
+	}
+}
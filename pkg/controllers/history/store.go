@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const storeTimeout = 30 * time.Second
+
+// Store archives the records of the finished jobs.
+type Store interface {
+	// Archive stores the record, archiving the same job again replaces its record
+	Archive(record *Record) error
+	// Prune removes the records archived before the given time, stores relying on their own
+	// retention policy do nothing
+	Prune(before time.Time) error
+}
+
+// StoreFactory builds the store configured by the URL.
+type StoreFactory func(u *url.URL) (Store, error)
+
+var (
+	storeMutex     sync.Mutex
+	storeFactories = map[string]StoreFactory{
+		"file":  newFileStore,
+		"http":  newHTTPStore,
+		"https": newHTTPStore,
+	}
+)
+
+// RegisterStore registers the factory of the stores of one URL scheme, e.g. to archive the
+// jobs to an object storage or a database.
+func RegisterStore(scheme string, factory StoreFactory) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	storeFactories[scheme] = factory
+}
+
+// NewStore returns the store configured by the URL.
+func NewStore(rawURL string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid history store <%s>: %v", rawURL, err)
+	}
+
+	storeMutex.Lock()
+	factory, found := storeFactories[u.Scheme]
+	storeMutex.Unlock()
+	if !found {
+		return nil, fmt.Errorf("unsupported history store scheme <%s>", u.Scheme)
+	}
+	return factory(u)
+}
+
+// fileStore writes each record as a JSON file named after the job in a directory per namespace.
+type fileStore struct {
+	dir string
+}
+
+func newFileStore(u *url.URL) (Store, error) {
+	if len(u.Path) == 0 {
+		return nil, fmt.Errorf("no directory in history store <%s>", u.String())
+	}
+	if err := os.MkdirAll(u.Path, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileStore{dir: u.Path}, nil
+}
+
+func (s *fileStore) Archive(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(s.dir, record.Job.Namespace)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.json", record.Job.Name, record.Job.UID)
+
+	// the record is renamed in place once written, so that no partial record is ever left
+	tmp, err := os.CreateTemp(dir, "."+name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+func (s *fileStore) Prune(before time.Time) error {
+	return filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(before) {
+			return os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// httpStore puts each record at the URL of the store followed by the UID of the job, e.g. as a
+// document of an Elasticsearch index. The retention is left to the server, e.g. a lifecycle policy.
+type httpStore struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newHTTPStore(u *url.URL) (Store, error) {
+	return &httpStore{
+		endpoint: strings.TrimSuffix(u.String(), "/"),
+		client:   &http.Client{Timeout: storeTimeout},
+	}, nil
+}
+
+func (s *httpStore) Archive(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, s.endpoint+"/"+url.PathEscape(string(record.Job.UID)), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *httpStore) Prune(before time.Time) error {
+	return nil
+}
@@ -31,3 +31,29 @@ const (
 	// is successfully deleted.
 	SuccessfulDeletePodReason = "SuccessfulDelete"
 )
+
+// Scheduling gates of the pods of a job.
+const (
+	// GateDependentTasksKey is the job annotation which, set to true, makes the pods of the tasks
+	// with dependencies created at once with the DependsOnGate scheduling gate, instead of only
+	// once their dependencies are ready, so that they count for the gang and the queue meanwhile.
+	GateDependentTasksKey = "volcano.sh/gate-dependent-tasks"
+	// DependsOnGate is the scheduling gate removed once the dependencies of the task are ready.
+	DependsOnGate = "volcano.sh/depends-on"
+)
+
+// NodeFailureTolerationSecondsKey is the job annotation giving how long, in seconds, the pods of
+// the job stay bound to a node which is not ready or unreachable. They are then evicted and the
+// job handles the PodEvicted event by its policies, replacing the pods by default.
+const NodeFailureTolerationSecondsKey = "volcano.sh/node-failure-toleration-seconds"
+
+// HibernateKey is the job annotation which, set to true, aborts the job keeping its podgroup, so
+// that it keeps its seniority in its queue, and its volumes. The annotation is copied to the
+// podgroup for the scheduler to ignore it until the job is woken by removing the annotation.
+const HibernateKey = "volcano.sh/hibernate"
+
+// TaskOverridesKey is the job annotation giving, by task name, the node selection and resources of
+// the pods of the task overriding the ones of its template, e.g.
+// {"driver": {"nodeSelector": {"node.kubernetes.io/lifecycle": "on-demand"}}, "worker": {"tolerations": [...]}}.
+// See TaskOverride for how each field is applied.
+const TaskOverridesKey = "volcano.sh/task-overrides"
@@ -35,6 +35,14 @@ const (
 	PodNameFmt = "%s-%s-%d"
 	// persistentVolumeClaimFmt represents persistent volume claim name format
 	persistentVolumeClaimFmt = "%s-pvc-%s"
+
+	// GangSchedulingFallbackKey is the job annotation selecting what is done once the gang scheduling
+	// timeout of the job expired: "abort" (the default) aborts the job, "shrink:<n>" lowers its
+	// minAvailable to n, "queue:<name>" migrates it to the queue, e.g. a scavenger one.
+	GangSchedulingFallbackKey = "volcano.sh/gang-scheduling-fallback"
+	// GangSchedulingTimedOutKey is the job annotation set to the time the shrink or queue fallback was
+	// applied, the fallback is applied once and the job then waits as any other.
+	GangSchedulingTimedOutKey = "volcano.sh/gang-scheduling-timed-out"
 )
 
 // GetPodIndexUnderTask returns task Index.
@@ -146,3 +154,13 @@ func GetPodsNameUnderTask(taskName string, job *batch.Job) []string {
 	}
 	return res
 }
+
+// GangFallbackQueue returns the queue the job is migrated to by its gang scheduling fallback, empty
+// if the fallback is not a queue one.
+func GangFallbackQueue(annotations map[string]string) string {
+	queue, found := strings.CutPrefix(annotations[GangSchedulingFallbackKey], "queue:")
+	if !found {
+		return ""
+	}
+	return queue
+}
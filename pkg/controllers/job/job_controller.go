@@ -111,6 +111,8 @@ type jobcontroller struct {
 	cache        jobcache.Cache
 	// Job Event recorder
 	recorder record.EventRecorder
+	// notifier posts the state changes of the jobs to their endpoints
+	notifier *notifier
 
 	errTasks      workqueue.RateLimitingInterface
 	workers       uint32
@@ -140,6 +142,7 @@ func (cc *jobcontroller) Initialize(opt *framework.ControllerOption) error {
 	cc.cache = jobcache.New()
 	cc.errTasks = newRateLimitingQueue()
 	cc.recorder = recorder
+	cc.notifier = newNotifier(opt.NotificationEndpoints)
 	cc.workers = workers
 	cc.maxRequeueNum = opt.MaxRequeueNum
 	if cc.maxRequeueNum < 0 {
@@ -229,6 +232,7 @@ func (cc *jobcontroller) Initialize(opt *framework.ControllerOption) error {
 	// Register actions
 	state.SyncJob = cc.syncJob
 	state.KillJob = cc.killJob
+	state.GangDegraded = cc.isGangDegraded
 
 	return nil
 }
@@ -253,6 +257,7 @@ func (cc *jobcontroller) Run(stopCh <-chan struct{}) {
 	}
 
 	go wait.Until(cc.handleCommands, 0, stopCh)
+	go cc.notifier.run(stopCh)
 	var i uint32
 	for i = 0; i < cc.workers; i++ {
 		go func(num uint32) {
@@ -341,6 +346,10 @@ func (cc *jobcontroller) processNextReq(count uint32) bool {
 		return true
 	}
 
+	if req.Event == busv1alpha1.PodEvictedEvent {
+		cc.notifyJob(jobInfo.Job, NotificationPreempted)
+	}
+
 	action := applyPolicies(jobInfo.Job, &req)
 	klog.V(3).Infof("Execute <%v> on Job <%s/%s> in <%s> by <%T>.",
 		action, req.Namespace, req.JobName, jobInfo.Job.Status.State.Phase, st)
@@ -18,17 +18,20 @@ package job
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
 	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
@@ -117,11 +120,10 @@ func (cc *jobcontroller) killJob(jobInfo *apis.JobInfo, podRetainPhase state.Pha
 	job.Status.Unknown = unknown
 	job.Status.TaskStatusCount = taskStatusCount
 
+	phaseChanged := false
 	if updateStatus != nil {
 		if updateStatus(&job.Status) {
-			job.Status.State.LastTransitionTime = metav1.Now()
-			jobCondition := newCondition(job.Status.State.Phase, &job.Status.State.LastTransitionTime)
-			job.Status.Conditions = append(job.Status.Conditions, jobCondition)
+			phaseChanged = cc.recordPhaseTransition(job)
 		}
 	}
 
@@ -151,6 +153,14 @@ func (cc *jobcontroller) killJob(jobInfo *apis.JobInfo, podRetainPhase state.Pha
 			newJob.Namespace, newJob.Name, e)
 		return e
 	}
+	if phaseChanged {
+		cc.notifyPhase(newJob)
+	}
+
+	// Keep the PodGroup of a hibernated job for it to keep its seniority
+	if keep, err := cc.keepHibernatedPodGroup(job); keep || err != nil {
+		return err
+	}
 
 	// Delete PodGroup
 	pgName := job.Name + "-" + string(job.UID)
@@ -288,9 +298,14 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 			syncTask = true
 		}
 		cc.recordPodGroupEvent(job, pg)
+		if applied, err := cc.handleGangSchedulingTimeout(job, pg); applied || err != nil {
+			return err
+		}
+		if degraded, err := cc.handleDegradedMode(job, pg); degraded || err != nil {
+			return err
+		}
 	}
 
-	var jobCondition batch.JobCondition
 	oldStatus := job.Status
 	if !syncTask {
 		if updateStatus != nil {
@@ -301,9 +316,7 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 			klog.V(4).Infof("Job <%s/%s> has not updated for no changing", job.Namespace, job.Name)
 			return nil
 		}
-		job.Status.State.LastTransitionTime = metav1.Now()
-		jobCondition = newCondition(job.Status.State.Phase, &job.Status.State.LastTransitionTime)
-		job.Status.Conditions = append(job.Status.Conditions, jobCondition)
+		phaseChanged := cc.recordPhaseTransition(job)
 		newJob, err := cc.vcClient.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(context.TODO(), job, metav1.UpdateOptions{})
 		if err != nil {
 			klog.Errorf("Failed to update status of Job %v/%v: %v",
@@ -315,6 +328,9 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 				newJob.Namespace, newJob.Name, e)
 			return e
 		}
+		if phaseChanged {
+			cc.notifyPhase(newJob)
+		}
 		return nil
 	}
 
@@ -323,6 +339,7 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 
 	podToCreate := make(map[string][]*v1.Pod)
 	var podToDelete []*v1.Pod
+	podToUngate := make(map[string][]*v1.Pod)
 	var creationErrs []error
 	var deletionErrs []error
 	appendMutex := sync.Mutex{}
@@ -337,7 +354,7 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 
 	for _, ts := range job.Spec.Tasks {
 		ts.Template.Name = ts.Name
-		tc := ts.Template.DeepCopy()
+		tc := taskTemplate(job, &ts)
 		name := ts.Template.Name
 
 		pods, found := jobInfo.Pods[name]
@@ -363,6 +380,14 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 					continue
 				}
 
+				if hasSchedulingGate(pod, DependsOnGate) {
+					podToUngate[ts.Name] = append(podToUngate[ts.Name], pod)
+				}
+				if pod.Labels[batch.QueueNameKey] != job.Spec.Queue {
+					if err := cc.migratePod(pod, job.Spec.Queue); err != nil {
+						klog.Errorf("Failed to migrate Pod <%s/%s> to queue %s: %v", pod.Namespace, pod.Name, job.Spec.Queue, err)
+					}
+				}
 				classifyAndAddUpPodBaseOnPhase(pod, &pending, &running, &succeeded, &failed, &unknown)
 				calcPodStatus(pod, taskStatusCount)
 			}
@@ -380,7 +405,14 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 		go func(taskName string, podToCreateEachTask []*v1.Pod) {
 			taskIndex := jobhelpers.GetTaskIndexUnderJob(taskName, job)
 			if job.Spec.Tasks[taskIndex].DependsOn != nil {
-				if !cc.waitDependsOnTaskMeetCondition(taskIndex, job) {
+				if gateDependentTasks(job) {
+					// the pods are created at once, the gate is removed once the dependencies are ready
+					if !cc.waitDependsOnTaskMeetCondition(taskIndex, job) {
+						for _, pod := range podToCreateEachTask {
+							addSchedulingGate(pod, DependsOnGate)
+						}
+					}
+				} else if !cc.waitDependsOnTaskMeetCondition(taskIndex, job) {
 					klog.V(3).Infof("Job %s/%s depends on task not ready", job.Name, job.Namespace)
 					// release wait group
 					for _, pod := range podToCreateEachTask {
@@ -422,6 +454,8 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 		return fmt.Errorf("failed to create %d pods of %d", len(creationErrs), len(podToCreate))
 	}
 
+	cc.ungateDependentPods(job, podToUngate)
+
 	// Delete pods when scale down.
 	waitDeletionGroup := sync.WaitGroup{}
 	waitDeletionGroup.Add(len(podToDelete))
@@ -478,9 +512,7 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 		return nil
 	}
 	job.Status = newStatus
-	job.Status.State.LastTransitionTime = metav1.Now()
-	jobCondition = newCondition(job.Status.State.Phase, &job.Status.State.LastTransitionTime)
-	job.Status.Conditions = append(job.Status.Conditions, jobCondition)
+	phaseChanged := cc.recordPhaseTransition(job)
 	newJob, err := cc.vcClient.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(context.TODO(), job, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to update status of Job %v/%v: %v",
@@ -492,10 +524,47 @@ func (cc *jobcontroller) syncJob(jobInfo *apis.JobInfo, updateStatus state.Updat
 			newJob.Namespace, newJob.Name, e)
 		return e
 	}
+	if phaseChanged {
+		cc.notifyPhase(newJob)
+	}
 
 	return nil
 }
 
+// ungateDependentPods removes the DependsOnGate of the pods of the tasks whose dependencies are
+// ready. The pods failed to be ungated are retried on the next sync of the job.
+func (cc *jobcontroller) ungateDependentPods(job *batch.Job, podToUngate map[string][]*v1.Pod) {
+	for taskName, pods := range podToUngate {
+		taskIndex := jobhelpers.GetTaskIndexUnderJob(taskName, job)
+		if taskIndex < 0 || !cc.waitDependsOnTaskMeetCondition(taskIndex, job) {
+			continue
+		}
+		for _, pod := range pods {
+			if err := cc.ungatePod(pod); err != nil {
+				klog.Errorf("Failed to remove the scheduling gate %s of pod %s/%s: %v", DependsOnGate, pod.Namespace, pod.Name, err)
+				continue
+			}
+			klog.V(3).Infof("Removed the scheduling gate %s of pod %s/%s", DependsOnGate, pod.Namespace, pod.Name)
+		}
+	}
+}
+
+// ungatePod patches the pod with its scheduling gates but the DependsOnGate.
+func (cc *jobcontroller) ungatePod(pod *v1.Pod) error {
+	newPod := pod.DeepCopy()
+	removeSchedulingGate(newPod, DependsOnGate)
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"schedulingGates": newPod.Spec.SchedulingGates,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = cc.kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
 func (cc *jobcontroller) waitDependsOnTaskMeetCondition(taskIndex int, job *batch.Job) bool {
 	if job.Spec.Tasks[taskIndex].DependsOn == nil {
 		return true
@@ -690,7 +759,7 @@ func (cc *jobcontroller) createOrUpdatePodGroup(job *batch.Job) error {
 					Namespace: job.Namespace,
 					// add job.UID into its name when create new PodGroup
 					Name:        pgName,
-					Annotations: job.Annotations,
+					Annotations: podGroupAnnotations(job),
 					Labels:      job.Labels,
 					OwnerReferences: []metav1.OwnerReference{
 						*metav1.NewControllerRef(job, helpers.JobKind),
@@ -717,6 +786,13 @@ func (cc *jobcontroller) createOrUpdatePodGroup(job *batch.Job) error {
 	}
 
 	pgShouldUpdate := false
+	// the job was migrated to another queue, its allocation moves with the podgroup
+	migratedFrom := ""
+	if pg.Spec.Queue != job.Spec.Queue {
+		migratedFrom = pg.Spec.Queue
+		pg.Spec.Queue = job.Spec.Queue
+		pgShouldUpdate = true
+	}
 	if pg.Spec.PriorityClassName != job.Spec.PriorityClassName {
 		pg.Spec.PriorityClassName = job.Spec.PriorityClassName
 		pgShouldUpdate = true
@@ -729,6 +805,13 @@ func (cc *jobcontroller) createOrUpdatePodGroup(job *batch.Job) error {
 		pgShouldUpdate = true
 	}
 
+	// the job was woken, the PodGroup was kept by keepHibernatedPodGroup
+	if _, found := pg.Annotations[HibernateKey]; found && !hibernated(job) {
+		pg = pg.DeepCopy()
+		delete(pg.Annotations, HibernateKey)
+		pgShouldUpdate = true
+	}
+
 	if pg.Spec.MinTaskMember == nil {
 		pgShouldUpdate = true
 		pg.Spec.MinTaskMember = make(map[string]int32)
@@ -761,10 +844,74 @@ func (cc *jobcontroller) createOrUpdatePodGroup(job *batch.Job) error {
 	if err != nil {
 		klog.V(3).Infof("Failed to update PodGroup for Job <%s/%s>: %v",
 			job.Namespace, job.Name, err)
+		return err
+	}
+	if migratedFrom != "" {
+		cc.recorder.Eventf(job, v1.EventTypeNormal, "Migrated", "Job was migrated from queue %s to queue %s", migratedFrom, job.Spec.Queue)
+	}
+	return nil
+}
+
+// migratePod updates the queue the pod is labeled and annotated with after its job was migrated.
+func (cc *jobcontroller) migratePod(pod *v1.Pod, queue string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      map[string]string{batch.QueueNameKey: queue},
+			"annotations": map[string]string{batch.QueueNameKey: queue},
+		},
+	})
+	if err != nil {
+		return err
 	}
+	_, err = cc.kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
 	return err
 }
 
+// keepHibernatedPodGroup marks the PodGroup of a hibernated job for the scheduler to ignore it and
+// resets it to pending, and returns whether the PodGroup is to be kept: while the job is hibernated, and while it is
+// woken until its pods are created again and the mark is removed in createOrUpdatePodGroup.
+func (cc *jobcontroller) keepHibernatedPodGroup(job *batch.Job) (bool, error) {
+	pg, err := cc.pgLister.PodGroups(job.Namespace).Get(job.Name + "-" + string(job.UID))
+	if apierrors.IsNotFound(err) {
+		pg, err = cc.pgLister.PodGroups(job.Namespace).Get(job.Name)
+	}
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	marked := pg.Annotations[HibernateKey] == "true"
+	if !hibernated(job) || (marked && pg.Status.Phase == scheduling.PodGroupPending) {
+		return marked, nil
+	}
+	pg = pg.DeepCopy()
+	if pg.Annotations == nil {
+		pg.Annotations = map[string]string{}
+	}
+	pg.Annotations[HibernateKey] = "true"
+	// the woken job is enqueued again by the scheduler, its queue is checked for capacity then,
+	// and the queue controller does not count it as running or inqueue meanwhile
+	pg.Status = scheduling.PodGroupStatus{Phase: scheduling.PodGroupPending}
+	if _, err := cc.vcClient.SchedulingV1beta1().PodGroups(pg.Namespace).Update(context.TODO(), pg, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("Failed to hibernate PodGroup of Job %v/%v: %v", job.Namespace, job.Name, err)
+		return true, err
+	}
+	return true, nil
+}
+
+// isGangDegraded returns whether the scheduler placed the running PodGroup of the job partially,
+// after the gang budget of its best-effort gang, see gangDegraded.
+func (cc *jobcontroller) isGangDegraded(jobInfo *apis.JobInfo) bool {
+	job := jobInfo.Job
+	pg, err := cc.pgLister.PodGroups(job.Namespace).Get(job.Name + "-" + string(job.UID))
+	if err != nil || pg.Status.Phase != scheduling.PodGroupRunning {
+		return false
+	}
+	return gangDegraded(pg)
+}
+
 func (cc *jobcontroller) deleteJobPod(jobName string, pod *v1.Pod) error {
 	err := cc.kubeClient.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
@@ -783,6 +930,7 @@ func (cc *jobcontroller) calcPGMinResources(job *batch.Job) *v1.ResourceList {
 	totalMinAvailable := int32(0)
 	for _, task := range job.Spec.Tasks {
 		tp := TaskPriority{0, task}
+		tp.Template = *taskTemplate(job, &task)
 		pc := task.Template.Spec.PriorityClassName
 
 		if pc != "" {
@@ -912,6 +1060,28 @@ func isInitiated(job *batch.Job) bool {
 	return true
 }
 
+// recordPhaseTransition stamps a status update of the job. When the phase changed it appends a
+// condition, so the conditions give the time the job entered each phase, records an event with
+// the time spent in the previous phase and returns true.
+func (cc *jobcontroller) recordPhaseTransition(job *batch.Job) bool {
+	now := metav1.Now()
+	job.Status.State.LastTransitionTime = now
+
+	var previous *batch.JobCondition
+	if n := len(job.Status.Conditions); n > 0 {
+		previous = &job.Status.Conditions[n-1]
+	}
+	if previous != nil && previous.Status == job.Status.State.Phase {
+		return false
+	}
+	job.Status.Conditions = append(job.Status.Conditions, newCondition(job.Status.State.Phase, &now))
+	if previous != nil && previous.LastTransitionTime != nil {
+		cc.recorder.Eventf(job, v1.EventTypeNormal, "PhaseTransition", "Job phase changed from %s to %s after %v",
+			previous.Status, job.Status.State.Phase, now.Sub(previous.LastTransitionTime.Time).Round(time.Second))
+	}
+	return true
+}
+
 func newCondition(status batch.JobPhase, lastTransitionTime *metav1.Time) batch.JobCondition {
 	return batch.JobCondition{
 		Status:             status,
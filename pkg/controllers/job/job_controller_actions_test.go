@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -422,6 +423,126 @@ func TestSyncJobFunc(t *testing.T) {
 	}
 }
 
+func TestSyncJobGateDependentTasks(t *testing.T) {
+	namespace := "test"
+	minAvailable := int32(1)
+	template := v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "Containers"}},
+		},
+	}
+	job := &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "job1",
+			Namespace:       namespace,
+			ResourceVersion: "100",
+			UID:             "e7f18111-1cec-11ea-b688-fa163ec79500",
+			Annotations:     map[string]string{GateDependentTasksKey: "true"},
+		},
+		Spec: v1alpha1.JobSpec{
+			Tasks: []v1alpha1.TaskSpec{
+				{
+					Name:         "master",
+					Replicas:     1,
+					MinAvailable: &minAvailable,
+					Template:     template,
+				},
+				{
+					Name:     "work",
+					Replicas: 2,
+					Template: template,
+					DependsOn: &v1alpha1.DependsOn{
+						Name: []string{"master"},
+					},
+				},
+			},
+		},
+		Status: v1alpha1.JobStatus{
+			State: v1alpha1.JobState{
+				Phase: v1alpha1.Pending,
+			},
+		},
+	}
+	pg := &schedulingapi.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job1-e7f18111-1cec-11ea-b688-fa163ec79500",
+			Namespace: namespace,
+		},
+		Spec: schedulingapi.PodGroupSpec{
+			MinResources:  &v1.ResourceList{},
+			MinTaskMember: map[string]int32{},
+		},
+		Status: schedulingapi.PodGroupStatus{
+			Phase: schedulingapi.PodGroupInqueue,
+		},
+	}
+
+	fakeController := newFakeController()
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(fakeController), "GetQueueInfo", func(_ *jobcontroller, _ string) (*schedulingapi.Queue, error) {
+		return &schedulingapi.Queue{}, nil
+	})
+	defer patches.Reset()
+
+	fakeController.pgInformer.Informer().GetIndexer().Add(pg)
+	fakeController.vcClient.SchedulingV1beta1().PodGroups(namespace).Create(context.TODO(), pg, metav1.CreateOptions{})
+	fakeController.jobInformer.Informer().GetIndexer().Add(job)
+	if _, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Create(context.TODO(), job, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Expected no Error while creating job, but got error: %s", err)
+	}
+	if err := fakeController.cache.Add(job); err != nil {
+		t.Fatalf("Error While Adding Job in cache: %v", err)
+	}
+
+	// syncJob syncs the job with the pods in the cluster, the master pod in the phase given
+	syncJob := func(masterPhase v1.PodPhase) map[string]*v1.Pod {
+		jobInfo := &apis.JobInfo{Namespace: namespace, Name: job.Name, Job: job, Pods: map[string]map[string]*v1.Pod{}}
+		podList, err := fakeController.kubeClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error while listing pods, but got error %s", err)
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if pod.Name == "job1-master-0" {
+				pod.Status.Phase = masterPhase
+			}
+			fakeController.podInformer.Informer().GetIndexer().Update(pod)
+			if err := jobInfo.AddPod(pod); err != nil {
+				t.Fatalf("Failed to add pod %s: %v", pod.Name, err)
+			}
+		}
+		if err := fakeController.syncJob(jobInfo, nil); err != nil {
+			t.Fatalf("Expected no error while syncing job, but got error: %s", err)
+		}
+
+		pods := map[string]*v1.Pod{}
+		podList, _ = fakeController.kubeClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		for i := range podList.Items {
+			pods[podList.Items[i].Name] = &podList.Items[i]
+		}
+		return pods
+	}
+	checkGates := func(pods map[string]*v1.Pod, gated bool) {
+		if len(pods) != 3 {
+			t.Fatalf("Expected the 3 pods of the job to be created, got %d", len(pods))
+		}
+		if hasSchedulingGate(pods["job1-master-0"], DependsOnGate) {
+			t.Errorf("Expected the master pod not to be gated")
+		}
+		for _, name := range []string{"job1-work-0", "job1-work-1"} {
+			if hasSchedulingGate(pods[name], DependsOnGate) != gated {
+				t.Errorf("Expected the gate of the dependent pod %s to be %v, got %v", name, gated, pods[name].Spec.SchedulingGates)
+			}
+		}
+	}
+
+	// the dependent pods are created at once with the gate
+	checkGates(syncJob(""), true)
+	// they keep it while the master is not running
+	checkGates(syncJob(v1.PodPending), true)
+	// they are ungated once it is
+	checkGates(syncJob(v1.PodRunning), false)
+}
+
 func TestCreateJobIOIfNotExistFunc(t *testing.T) {
 	namespace := "test"
 
@@ -616,6 +737,126 @@ func TestUpdatePodGroupIfJobUpdateFunc(t *testing.T) {
 
 }
 
+func TestKeepHibernatedPodGroup(t *testing.T) {
+	namespace := "test"
+	job := &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        "job1",
+			UID:         "e7f18111-1cec-11ea-b688-fa163ec79500",
+			Annotations: map[string]string{HibernateKey: "true"},
+		},
+	}
+	pgName := job.Name + "-" + string(job.UID)
+
+	fakeController := newFakeController()
+	pg := &schedulingapi.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      pgName,
+		},
+		Spec: schedulingapi.PodGroupSpec{
+			MinResources: &v1.ResourceList{},
+		},
+		Status: schedulingapi.PodGroupStatus{
+			Phase:   schedulingapi.PodGroupRunning,
+			Running: 2,
+		},
+	}
+	fakeController.pgInformer.Informer().GetIndexer().Add(pg)
+	fakeController.vcClient.SchedulingV1beta1().PodGroups(namespace).Create(context.TODO(), pg, metav1.CreateOptions{})
+
+	// hibernated, the podgroup is kept, marked and reset to pending
+	keep, err := fakeController.keepHibernatedPodGroup(job)
+	if err != nil || !keep {
+		t.Fatalf("Expected the podgroup of the hibernated job to be kept, got %v, %v", keep, err)
+	}
+	pg, _ = fakeController.vcClient.SchedulingV1beta1().PodGroups(namespace).Get(context.TODO(), pgName, metav1.GetOptions{})
+	if pg.Annotations[HibernateKey] != "true" {
+		t.Fatalf("Expected the podgroup to be marked hibernated, got %v", pg.Annotations)
+	}
+	if pg.Status.Phase != schedulingapi.PodGroupPending || pg.Status.Running != 0 {
+		t.Fatalf("Expected the podgroup of the hibernated job to be reset to pending, got %v", pg.Status)
+	}
+	fakeController.pgInformer.Informer().GetIndexer().Update(pg)
+
+	// woken, the podgroup is kept until it is updated by createOrUpdatePodGroup
+	job.Annotations = nil
+	if keep, err := fakeController.keepHibernatedPodGroup(job); err != nil || !keep {
+		t.Fatalf("Expected the podgroup of the woken job to be kept, got %v, %v", keep, err)
+	}
+	if err := fakeController.createOrUpdatePodGroup(job); err != nil {
+		t.Fatalf("Failed to update the podgroup: %v", err)
+	}
+	pg, _ = fakeController.vcClient.SchedulingV1beta1().PodGroups(namespace).Get(context.TODO(), pgName, metav1.GetOptions{})
+	if _, found := pg.Annotations[HibernateKey]; found {
+		t.Fatalf("Expected the hibernation of the podgroup to be removed, got %v", pg.Annotations)
+	}
+	// pending, the woken job goes through enqueue again before it is allocated
+	if pg.Status.Phase != schedulingapi.PodGroupPending {
+		t.Fatalf("Expected the podgroup of the woken job to be pending, got %v", pg.Status.Phase)
+	}
+	fakeController.pgInformer.Informer().GetIndexer().Update(pg)
+
+	if keep, err := fakeController.keepHibernatedPodGroup(job); err != nil || keep {
+		t.Errorf("Expected the podgroup of the running job not to be kept, got %v, %v", keep, err)
+	}
+}
+
+func TestMigrateJob(t *testing.T) {
+	namespace := "test"
+	job := &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "job1",
+			UID:       "e7f18111-1cec-11ea-b688-fa163ec79500",
+		},
+		Spec: v1alpha1.JobSpec{
+			Queue: "q2",
+		},
+	}
+	pgName := job.Name + "-" + string(job.UID)
+
+	fakeController := newFakeController()
+	pg := &schedulingapi.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      pgName,
+		},
+		Spec: schedulingapi.PodGroupSpec{
+			Queue:        "q1",
+			MinResources: &v1.ResourceList{},
+		},
+	}
+	fakeController.pgInformer.Informer().GetIndexer().Add(pg)
+	fakeController.vcClient.SchedulingV1beta1().PodGroups(namespace).Create(context.TODO(), pg, metav1.CreateOptions{})
+
+	if err := fakeController.createOrUpdatePodGroup(job); err != nil {
+		t.Fatalf("Failed to update the podgroup: %v", err)
+	}
+	pg, _ = fakeController.vcClient.SchedulingV1beta1().PodGroups(namespace).Get(context.TODO(), pgName, metav1.GetOptions{})
+	if pg.Spec.Queue != "q2" {
+		t.Errorf("Expected the podgroup to be migrated to queue q2, got %s", pg.Spec.Queue)
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        "job1-task-0",
+			Labels:      map[string]string{v1alpha1.QueueNameKey: "q1"},
+			Annotations: map[string]string{v1alpha1.QueueNameKey: "q1"},
+		},
+	}
+	fakeController.kubeClient.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err := fakeController.migratePod(pod, "q2"); err != nil {
+		t.Fatalf("Failed to migrate the pod: %v", err)
+	}
+	pod, _ = fakeController.kubeClient.CoreV1().Pods(namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	if pod.Labels[v1alpha1.QueueNameKey] != "q2" || pod.Annotations[v1alpha1.QueueNameKey] != "q2" {
+		t.Errorf("Expected the pod to be migrated to queue q2, got %v and %v", pod.Labels, pod.Annotations)
+	}
+}
+
 func TestDeleteJobPod(t *testing.T) {
 	namespace := "test"
 
@@ -787,3 +1028,39 @@ func TestRecordPodGroupEvent(t *testing.T) {
 	}
 
 }
+
+func TestRecordPhaseTransition(t *testing.T) {
+	fakeController := newFakeController()
+	recorder := record.NewFakeRecorder(100)
+	fakeController.recorder = recorder
+
+	pendingTime := metav1.NewTime(time.Now().Add(-time.Minute))
+	job := &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "test"},
+		Status: v1alpha1.JobStatus{
+			State:      v1alpha1.JobState{Phase: v1alpha1.Pending, LastTransitionTime: pendingTime},
+			Conditions: []v1alpha1.JobCondition{{Status: v1alpha1.Pending, LastTransitionTime: &pendingTime}},
+		},
+	}
+
+	// a status update in the same phase records no transition
+	fakeController.recordPhaseTransition(job)
+	if len(job.Status.Conditions) != 1 {
+		t.Fatalf("expected 1 condition, got %d", len(job.Status.Conditions))
+	}
+
+	job.Status.State.Phase = v1alpha1.Running
+	fakeController.recordPhaseTransition(job)
+	if len(job.Status.Conditions) != 2 || job.Status.Conditions[1].Status != v1alpha1.Running {
+		t.Fatalf("expected a Running condition, got %v", job.Status.Conditions)
+	}
+	if !job.Status.Conditions[1].LastTransitionTime.Equal(&job.Status.State.LastTransitionTime) {
+		t.Errorf("expected the condition to be stamped with the transition time")
+	}
+
+	close(recorder.Events)
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Normal PhaseTransition Job phase changed from Pending to Running after 1m0s") {
+		t.Errorf("unexpected event %q", event)
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+
+	"volcano.sh/volcano/pkg/controllers/apis"
+	jobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
+)
+
+const (
+	// DegradedModesKey is the job annotation listing, from the mildest, the reduced gangs the job
+	// accepts when its minAvailable can no longer be met, e.g. "75%,50%" or "6,4". Percentages are of
+	// the original minAvailable, rounded up.
+	DegradedModesKey = "volcano.sh/degraded-modes"
+	// DegradedModeDelayKey is the job annotation giving, as a duration, how long a job which already
+	// ran stays pending for lack of resources before it is degraded to the next mode, 5m by default.
+	DegradedModeDelayKey = "volcano.sh/degraded-mode-delay"
+	// DegradedModeKey is the job annotation set to the degraded mode applied to the job.
+	DegradedModeKey = "volcano.sh/degraded-mode"
+	// DegradedTimeKey is the job annotation set to the time the degraded mode was applied, the delay
+	// of the next mode starts then.
+	DegradedTimeKey = "volcano.sh/degraded-time"
+	// OriginalMinAvailableKey is the job annotation keeping the minAvailable of the job before it was
+	// degraded.
+	OriginalMinAvailableKey = "volcano.sh/original-min-available"
+
+	// MinAvailableDegradedReason is the reason of the events of the jobs whose minAvailable was
+	// lowered to a degraded mode.
+	MinAvailableDegradedReason = "MinAvailableDegraded"
+
+	defaultDegradedModeDelay = 5 * time.Minute
+)
+
+// degradedMode is an entry of the degraded mode table of a job.
+type degradedMode struct {
+	value   int32
+	percent bool
+}
+
+func (m degradedMode) String() string {
+	if m.percent {
+		return fmt.Sprintf("%d%%", m.value)
+	}
+	return strconv.Itoa(int(m.value))
+}
+
+// minAvailable returns the minAvailable of the mode for the original minAvailable.
+func (m degradedMode) minAvailable(original int32) int32 {
+	if m.percent {
+		return int32((int64(original)*int64(m.value) + 99) / 100)
+	}
+	return m.value
+}
+
+// parseDegradedModes parses the value of DegradedModesKey.
+func parseDegradedModes(value string) ([]degradedMode, error) {
+	var modes []degradedMode
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		number, percent := strings.CutSuffix(entry, "%")
+		n, err := strconv.ParseInt(number, 10, 32)
+		if err != nil || n <= 0 || (percent && n >= 100) {
+			return nil, fmt.Errorf("invalid degraded mode %q", entry)
+		}
+		modes = append(modes, degradedMode{value: int32(n), percent: percent})
+	}
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("no degraded mode")
+	}
+	return modes, nil
+}
+
+// nextDegradedMode returns the first mode of the table reducing the gang below the current
+// minAvailable, and the minAvailable it gives.
+func nextDegradedMode(modes []degradedMode, original, current int32) (degradedMode, int32, bool) {
+	for _, mode := range modes {
+		if minAvailable := mode.minAvailable(original); minAvailable > 0 && minAvailable < current {
+			return mode, minAvailable, true
+		}
+	}
+	return degradedMode{}, 0, false
+}
+
+// hasRun tells whether the job was running once, its gang was then feasible.
+func hasRun(job *batch.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status == batch.Running {
+			return true
+		}
+	}
+	return false
+}
+
+// lacksResources tells whether the podgroup is unschedulable for lack of resources.
+func lacksResources(pg *scheduling.PodGroup) bool {
+	for _, condition := range pg.Status.Conditions {
+		if condition.Type == scheduling.PodGroupUnschedulableType && condition.Status == v1.ConditionTrue &&
+			condition.Reason == scheduling.NotEnoughResourcesReason {
+			return true
+		}
+	}
+	return false
+}
+
+// degradationDue returns whether the job, which already ran, waited long enough for resources to
+// be degraded and, if it is still waiting, how long it has left.
+func degradationDue(job *batch.Job, pg *scheduling.PodGroup, now time.Time) (bool, time.Duration) {
+	if _, found := job.Annotations[DegradedModesKey]; !found || hibernated(job) {
+		return false, 0
+	}
+	if job.Status.State.Phase != batch.Pending || !hasRun(job) {
+		return false, 0
+	}
+	if phase := pg.Status.Phase; phase != scheduling.PodGroupPending && phase != scheduling.PodGroupInqueue {
+		return false, 0
+	}
+	if !lacksResources(pg) {
+		return false, 0
+	}
+
+	delay := defaultDegradedModeDelay
+	if value, found := job.Annotations[DegradedModeDelayKey]; found {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			delay = d
+		} else {
+			klog.Warningf("Invalid %s=%s of job <%s/%s>, use %v", DegradedModeDelayKey, value, job.Namespace, job.Name, delay)
+		}
+	}
+	since := pendingSince(job)
+	if degraded, err := time.Parse(time.RFC3339, job.Annotations[DegradedTimeKey]); err == nil && degraded.After(since) {
+		since = degraded
+	}
+	if waited := now.Sub(since); waited < delay {
+		return false, delay - waited
+	}
+	return true, 0
+}
+
+// degradeTaskMinAvailable lowers the minAvailable of the tasks in the proportion the minAvailable
+// of the job is lowered from current, rounded up so that each task keeps its pods in the gang.
+func degradeTaskMinAvailable(job *batch.Job, current, minAvailable int32) {
+	if current <= 0 {
+		return
+	}
+	for i := range job.Spec.Tasks {
+		task := &job.Spec.Tasks[i]
+		if task.MinAvailable == nil || *task.MinAvailable <= 0 {
+			continue
+		}
+		n := int32((int64(*task.MinAvailable)*int64(minAvailable) + int64(current) - 1) / int64(current))
+		if n < *task.MinAvailable {
+			task.MinAvailable = &n
+		}
+	}
+}
+
+// handleDegradedMode lowers the minAvailable of a job which ran but can no longer get its gang, and
+// those of its tasks, to the next mode of its degraded mode table once its delay expired, or
+// requeues the job for when it expires. It returns whether the job was degraded, the job is then synced again.
+func (cc *jobcontroller) handleDegradedMode(job *batch.Job, pg *scheduling.PodGroup) (bool, error) {
+	now := time.Now()
+	due, remaining := degradationDue(job, pg, now)
+	req := apis.Request{
+		Namespace: job.Namespace,
+		JobName:   job.Name,
+	}
+	if remaining > 0 {
+		cc.getWorkerQueue(jobhelpers.GetJobKeyByReq(&req)).AddAfter(req, remaining)
+		return false, nil
+	}
+	if !due {
+		return false, nil
+	}
+
+	modes, err := parseDegradedModes(job.Annotations[DegradedModesKey])
+	if err != nil {
+		klog.Warningf("Invalid %s of job <%s/%s>, ignored: %v", DegradedModesKey, job.Namespace, job.Name, err)
+		return false, nil
+	}
+	original := job.Spec.MinAvailable
+	if value, found := job.Annotations[OriginalMinAvailableKey]; found {
+		if n, err := strconv.ParseInt(value, 10, 32); err == nil {
+			original = int32(n)
+		}
+	}
+	mode, minAvailable, found := nextDegradedMode(modes, original, job.Spec.MinAvailable)
+	if !found {
+		return false, nil
+	}
+
+	message := fmt.Sprintf("Gang of %d not schedulable after the cluster shrank, degraded to %v: minAvailable %d",
+		job.Spec.MinAvailable, mode, minAvailable)
+	job.Annotations[OriginalMinAvailableKey] = strconv.Itoa(int(original))
+	job.Annotations[DegradedModeKey] = mode.String()
+	job.Annotations[DegradedTimeKey] = now.Format(time.RFC3339)
+	degradeTaskMinAvailable(job, job.Spec.MinAvailable, minAvailable)
+	job.Spec.MinAvailable = minAvailable
+	if _, err := cc.vcClient.BatchV1alpha1().Jobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("Failed to degrade job <%s/%s>: %v", job.Namespace, job.Name, err)
+		return true, err
+	}
+	cc.recorder.Event(job, v1.EventTypeWarning, MinAvailableDegradedReason, message)
+	return true, nil
+}
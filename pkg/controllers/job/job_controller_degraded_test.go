@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+func TestNextDegradedMode(t *testing.T) {
+	tests := []struct {
+		value        string
+		original     int32
+		current      int32
+		minAvailable int32
+		found        bool
+		err          bool
+	}{
+		{value: "75%,50%", original: 8, current: 8, minAvailable: 6, found: true},
+		{value: "75%,50%", original: 8, current: 6, minAvailable: 4, found: true},
+		{value: "75%,50%", original: 8, current: 4},
+		{value: "75%", original: 3, current: 3},
+		{value: "6, 4", original: 8, current: 6, minAvailable: 4, found: true},
+		{value: "100%", err: true},
+		{value: "half", err: true},
+		{value: "", err: true},
+	}
+	for _, test := range tests {
+		modes, err := parseDegradedModes(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("%q: expected error %t, got %v", test.value, test.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		_, minAvailable, found := nextDegradedMode(modes, test.original, test.current)
+		if found != test.found || (found && minAvailable != test.minAvailable) {
+			t.Errorf("%q from %d/%d: expected (%d, %t), got (%d, %t)", test.value, test.current, test.original,
+				test.minAvailable, test.found, minAvailable, found)
+		}
+	}
+}
+
+func TestHandleDegradedMode(t *testing.T) {
+	controller := newFakeController()
+	pendingSince := metav1.NewTime(time.Now().Add(-time.Hour))
+	masterMinAvailable, workerMinAvailable := int32(1), int32(4)
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "job1",
+			Namespace:   "test",
+			Annotations: map[string]string{DegradedModesKey: "75%,50%", DegradedModeDelayKey: "10m"},
+		},
+		Spec: batch.JobSpec{
+			MinAvailable: 8,
+			Queue:        "default",
+			Tasks: []batch.TaskSpec{
+				{Name: "master", Replicas: 1, MinAvailable: &masterMinAvailable},
+				{Name: "worker", Replicas: 8, MinAvailable: &workerMinAvailable},
+			},
+		},
+		Status: batch.JobStatus{
+			// the status of the job was updated since it turned pending, which does not restart the delay
+			State:      batch.JobState{Phase: batch.Pending, LastTransitionTime: metav1.Now()},
+			Conditions: []batch.JobCondition{{Status: batch.Pending}, {Status: batch.Running}, {Status: batch.Pending, LastTransitionTime: &pendingSince}},
+		},
+	}
+	if _, err := controller.vcClient.BatchV1alpha1().Jobs(job.Namespace).Create(context.TODO(), job, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create the job: %v", err)
+	}
+	pg := &scheduling.PodGroup{Status: scheduling.PodGroupStatus{
+		Phase: scheduling.PodGroupInqueue,
+		Conditions: []scheduling.PodGroupCondition{{
+			Type:   scheduling.PodGroupUnschedulableType,
+			Status: v1.ConditionTrue,
+			Reason: scheduling.NotEnoughResourcesReason,
+		}},
+	}}
+
+	degraded, err := controller.handleDegradedMode(job.DeepCopy(), pg)
+	if !degraded || err != nil {
+		t.Fatalf("expected the job degraded, got %t, %v", degraded, err)
+	}
+	updated, err := controller.vcClient.BatchV1alpha1().Jobs(job.Namespace).Get(context.TODO(), job.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the job: %v", err)
+	}
+	if updated.Spec.MinAvailable != 6 || updated.Annotations[OriginalMinAvailableKey] != "8" || updated.Annotations[DegradedModeKey] != "75%" {
+		t.Errorf("expected minAvailable degraded to 6, got %d, %v", updated.Spec.MinAvailable, updated.Annotations)
+	}
+	if master, worker := *updated.Spec.Tasks[0].MinAvailable, *updated.Spec.Tasks[1].MinAvailable; master != 1 || worker != 3 {
+		t.Errorf("expected the minAvailable of the tasks degraded to 1 and 3, got %d and %d", master, worker)
+	}
+	if degraded, _ := controller.handleDegradedMode(updated, pg); degraded {
+		t.Errorf("expected the next mode delayed")
+	}
+
+	neverRan := job.DeepCopy()
+	neverRan.Status.Conditions = []batch.JobCondition{{Status: batch.Pending}}
+	if due, _ := degradationDue(neverRan, pg, time.Now()); due {
+		t.Errorf("expected a job which never ran not degraded")
+	}
+}
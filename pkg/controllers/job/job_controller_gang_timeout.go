@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	bus "volcano.sh/apis/pkg/apis/bus/v1alpha1"
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+
+	"volcano.sh/volcano/pkg/controllers/apis"
+	jobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
+)
+
+const (
+	// GangSchedulingTimeoutKey is the job annotation giving, as a duration, how long a pending job
+	// waits for its gang to be scheduled, i.e. for its podgroup to be running, before the fallback
+	// of GangSchedulingFallbackKey is applied. The wait starts when the job turns pending.
+	GangSchedulingTimeoutKey = "volcano.sh/gang-scheduling-timeout"
+	// GangSchedulingFallbackKey is the job annotation selecting the fallback of the gang scheduling
+	// timeout, see jobhelpers.GangSchedulingFallbackKey; the access of the submitter to the queue of
+	// a queue fallback is checked on admission.
+	GangSchedulingFallbackKey = jobhelpers.GangSchedulingFallbackKey
+	// GangSchedulingTimedOutKey is the job annotation set to the time the shrink or queue fallback was
+	// applied, see jobhelpers.GangSchedulingTimedOutKey.
+	GangSchedulingTimedOutKey = jobhelpers.GangSchedulingTimedOutKey
+
+	// GangSchedulingTimeoutReason is the reason of the events of the gang scheduling timeouts.
+	GangSchedulingTimeoutReason = "GangSchedulingTimeout"
+)
+
+const (
+	gangFallbackAbort  = "abort"
+	gangFallbackShrink = "shrink"
+	gangFallbackQueue  = "queue"
+)
+
+// gangFallback is what is done once the gang scheduling timeout of a job expired.
+type gangFallback struct {
+	action       string
+	minAvailable int32
+	queue        string
+}
+
+func (f gangFallback) String() string {
+	switch f.action {
+	case gangFallbackShrink:
+		return fmt.Sprintf("shrinking minAvailable to %d", f.minAvailable)
+	case gangFallbackQueue:
+		return fmt.Sprintf("migrating to queue %s", f.queue)
+	}
+	return "aborting"
+}
+
+// parseGangFallback parses the value of GangSchedulingFallbackKey.
+func parseGangFallback(value string) (gangFallback, error) {
+	action, arg, _ := strings.Cut(value, ":")
+	switch action {
+	case "", gangFallbackAbort:
+		return gangFallback{action: gangFallbackAbort}, nil
+	case gangFallbackShrink:
+		minAvailable, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil || minAvailable < 0 {
+			return gangFallback{}, fmt.Errorf("invalid minAvailable %q", arg)
+		}
+		return gangFallback{action: gangFallbackShrink, minAvailable: int32(minAvailable)}, nil
+	case gangFallbackQueue:
+		if len(arg) == 0 {
+			return gangFallback{}, fmt.Errorf("missing queue")
+		}
+		return gangFallback{action: gangFallbackQueue, queue: arg}, nil
+	}
+	return gangFallback{}, fmt.Errorf("unknown fallback %q", action)
+}
+
+// gangSchedulingExpired returns whether the gang scheduling timeout of the job expired and, if it is
+// still running, how long it has left.
+func gangSchedulingExpired(job *batch.Job, pg *scheduling.PodGroup, now time.Time) (bool, time.Duration) {
+	value, found := job.Annotations[GangSchedulingTimeoutKey]
+	if !found || len(job.Annotations[GangSchedulingTimedOutKey]) != 0 || hibernated(job) {
+		return false, 0
+	}
+	if job.Status.State.Phase != batch.Pending {
+		return false, 0
+	}
+	if phase := pg.Status.Phase; phase != "" && phase != scheduling.PodGroupPending && phase != scheduling.PodGroupInqueue {
+		return false, 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		klog.Warningf("Invalid %s=%s of job <%s/%s>, ignored", GangSchedulingTimeoutKey, value, job.Namespace, job.Name)
+		return false, 0
+	}
+
+	if waited := now.Sub(pendingSince(job)); waited < timeout {
+		return false, timeout - waited
+	}
+	return true, 0
+}
+
+// pendingSince returns when the job entered the pending phase: the time of its last condition, as
+// recordPhaseTransition appends one on each phase change only, while the LastTransitionTime of its
+// state is stamped by every status update.
+func pendingSince(job *batch.Job) time.Time {
+	if n := len(job.Status.Conditions); n > 0 {
+		if condition := job.Status.Conditions[n-1]; condition.Status == batch.Pending && condition.LastTransitionTime != nil {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return job.CreationTimestamp.Time
+}
+
+// handleGangSchedulingTimeout applies the gang scheduling fallback of the job if its timeout expired,
+// or requeues the job for when it expires. It returns whether the fallback was applied, the job is
+// then synced again. A fallback which fails to be applied, e.g. a queue the job may not migrate to,
+// is reported as an event and the job is synced as usual.
+func (cc *jobcontroller) handleGangSchedulingTimeout(job *batch.Job, pg *scheduling.PodGroup) (bool, error) {
+	now := time.Now()
+	expired, remaining := gangSchedulingExpired(job, pg, now)
+	req := apis.Request{
+		Namespace: job.Namespace,
+		JobName:   job.Name,
+	}
+	key := jobhelpers.GetJobKeyByReq(&req)
+	if remaining > 0 {
+		cc.getWorkerQueue(key).AddAfter(req, remaining)
+		return false, nil
+	}
+	if !expired {
+		return false, nil
+	}
+
+	fallback, err := parseGangFallback(job.Annotations[GangSchedulingFallbackKey])
+	if err != nil {
+		klog.Warningf("Invalid %s of job <%s/%s>, abort it: %v", GangSchedulingFallbackKey, job.Namespace, job.Name, err)
+		fallback = gangFallback{action: gangFallbackAbort}
+	}
+	message := fmt.Sprintf("Gang not scheduled within %s, %v", job.Annotations[GangSchedulingTimeoutKey], fallback)
+
+	switch fallback.action {
+	case gangFallbackAbort:
+		cc.recorder.Event(job, v1.EventTypeWarning, GangSchedulingTimeoutReason, message)
+		req.Action = bus.AbortJobAction
+		cc.getWorkerQueue(key).Add(req)
+		return true, nil
+	case gangFallbackShrink:
+		if fallback.minAvailable < job.Spec.MinAvailable {
+			job.Spec.MinAvailable = fallback.minAvailable
+		}
+	case gangFallbackQueue:
+		job.Spec.Queue = fallback.queue
+	}
+	job.Annotations[GangSchedulingTimedOutKey] = now.Format(time.RFC3339)
+	if _, err := cc.vcClient.BatchV1alpha1().Jobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("Failed to apply the gang scheduling fallback of job <%s/%s>: %v", job.Namespace, job.Name, err)
+		cc.recorder.Eventf(job, v1.EventTypeWarning, GangSchedulingTimeoutReason, "%s failed: %v", message, err)
+		return false, nil
+	}
+	cc.recorder.Event(job, v1.EventTypeWarning, GangSchedulingTimeoutReason, message)
+	return true, nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	volcanoclient "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+func TestParseGangFallback(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected gangFallback
+		err      bool
+	}{
+		{value: "", expected: gangFallback{action: gangFallbackAbort}},
+		{value: "abort", expected: gangFallback{action: gangFallbackAbort}},
+		{value: "shrink:2", expected: gangFallback{action: gangFallbackShrink, minAvailable: 2}},
+		{value: "shrink:two", err: true},
+		{value: "queue:scavenger", expected: gangFallback{action: gangFallbackQueue, queue: "scavenger"}},
+		{value: "queue:", err: true},
+		{value: "retry", err: true},
+	}
+	for _, test := range tests {
+		fallback, err := parseGangFallback(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("%q: expected error %t, got %v", test.value, test.err, err)
+			continue
+		}
+		if err == nil && fallback != test.expected {
+			t.Errorf("%q: expected %+v, got %+v", test.value, test.expected, fallback)
+		}
+	}
+}
+
+func TestGangSchedulingExpired(t *testing.T) {
+	now := time.Now()
+	// the status of the job was updated since it turned pending, which does not restart the wait
+	pending := metav1.NewTime(now.Add(-5 * time.Minute))
+	pendingJob := func(annotations map[string]string) *batch.Job {
+		return &batch.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "test", Annotations: annotations},
+			Status: batch.JobStatus{
+				State:      batch.JobState{Phase: batch.Pending, LastTransitionTime: metav1.NewTime(now)},
+				Conditions: []batch.JobCondition{newCondition(batch.Pending, &pending)},
+			},
+		}
+	}
+	pg := func(phase scheduling.PodGroupPhase) *scheduling.PodGroup {
+		return &scheduling.PodGroup{Status: scheduling.PodGroupStatus{Phase: phase}}
+	}
+
+	tests := []struct {
+		name      string
+		job       *batch.Job
+		pg        *scheduling.PodGroup
+		expired   bool
+		remaining time.Duration
+	}{
+		{
+			name: "no timeout",
+			job:  pendingJob(nil),
+			pg:   pg(scheduling.PodGroupPending),
+		},
+		{
+			name:      "waiting",
+			job:       pendingJob(map[string]string{GangSchedulingTimeoutKey: "10m"}),
+			pg:        pg(scheduling.PodGroupInqueue),
+			remaining: 5 * time.Minute,
+		},
+		{
+			name:    "expired",
+			job:     pendingJob(map[string]string{GangSchedulingTimeoutKey: "2m"}),
+			pg:      pg(scheduling.PodGroupPending),
+			expired: true,
+		},
+		{
+			name: "scheduled",
+			job:  pendingJob(map[string]string{GangSchedulingTimeoutKey: "2m"}),
+			pg:   pg(scheduling.PodGroupRunning),
+		},
+		{
+			name: "fallback applied",
+			job:  pendingJob(map[string]string{GangSchedulingTimeoutKey: "2m", GangSchedulingTimedOutKey: now.Format(time.RFC3339)}),
+			pg:   pg(scheduling.PodGroupPending),
+		},
+		{
+			name: "invalid timeout",
+			job:  pendingJob(map[string]string{GangSchedulingTimeoutKey: "soon"}),
+			pg:   pg(scheduling.PodGroupPending),
+		},
+	}
+	for _, test := range tests {
+		expired, remaining := gangSchedulingExpired(test.job, test.pg, now)
+		if expired != test.expired || remaining != test.remaining {
+			t.Errorf("%s: expected (%t, %v), got (%t, %v)", test.name, test.expired, test.remaining, expired, remaining)
+		}
+	}
+}
+
+func TestHandleGangSchedulingTimeout(t *testing.T) {
+	controller := newFakeController()
+	pending := metav1.NewTime(time.Now().Add(-time.Hour))
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job1",
+			Namespace: "test",
+			Annotations: map[string]string{
+				GangSchedulingTimeoutKey:  "1m",
+				GangSchedulingFallbackKey: "shrink:2",
+			},
+		},
+		Spec: batch.JobSpec{MinAvailable: 4, Queue: "default"},
+		Status: batch.JobStatus{
+			State:      batch.JobState{Phase: batch.Pending},
+			Conditions: []batch.JobCondition{newCondition(batch.Pending, &pending)},
+		},
+	}
+	if _, err := controller.vcClient.BatchV1alpha1().Jobs(job.Namespace).Create(context.TODO(), job, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create the job: %v", err)
+	}
+
+	pg := &scheduling.PodGroup{Status: scheduling.PodGroupStatus{Phase: scheduling.PodGroupPending}}
+	applied, err := controller.handleGangSchedulingTimeout(job.DeepCopy(), pg)
+	if !applied || err != nil {
+		t.Fatalf("expected the fallback applied, got %t, %v", applied, err)
+	}
+	updated, err := controller.vcClient.BatchV1alpha1().Jobs(job.Namespace).Get(context.TODO(), job.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the job: %v", err)
+	}
+	if updated.Spec.MinAvailable != 2 || len(updated.Annotations[GangSchedulingTimedOutKey]) == 0 {
+		t.Errorf("expected minAvailable shrunk to 2 once, got %d, %v", updated.Spec.MinAvailable, updated.Annotations)
+	}
+	if applied, _ := controller.handleGangSchedulingTimeout(updated, pg); applied {
+		t.Errorf("expected the fallback applied once")
+	}
+}
+
+func TestHandleGangSchedulingTimeoutDenied(t *testing.T) {
+	controller := newFakeController()
+	pending := metav1.NewTime(time.Now().Add(-time.Hour))
+	recorder := record.NewFakeRecorder(10)
+	controller.recorder = recorder
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job1",
+			Namespace: "test",
+			Annotations: map[string]string{
+				GangSchedulingTimeoutKey:  "1m",
+				GangSchedulingFallbackKey: "queue:scavenger",
+			},
+		},
+		Spec: batch.JobSpec{MinAvailable: 4, Queue: "default"},
+		Status: batch.JobStatus{
+			State:      batch.JobState{Phase: batch.Pending},
+			Conditions: []batch.JobCondition{newCondition(batch.Pending, &pending)},
+		},
+	}
+	controller.vcClient.(*volcanoclient.Clientset).PrependReactor("update", "jobs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "batch.volcano.sh", Resource: "jobs"}, job.Name,
+			fmt.Errorf("service account `test:controller` is not allowed to submit jobs to queue `scavenger`"))
+	})
+
+	pg := &scheduling.PodGroup{Status: scheduling.PodGroupStatus{Phase: scheduling.PodGroupPending}}
+	applied, err := controller.handleGangSchedulingTimeout(job, pg)
+	if applied || err != nil {
+		t.Fatalf("expected the job synced as usual when the fallback is denied, got %t, %v", applied, err)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, GangSchedulingTimeoutReason) || !strings.Contains(event, "scavenger") {
+			t.Errorf("expected an event of the failed fallback, got %s", event)
+		}
+	default:
+		t.Errorf("expected an event of the failed fallback")
+	}
+}
@@ -95,6 +95,22 @@ func (cc *jobcontroller) updateJob(oldObj, newObj interface{}) {
 			newJob.Namespace, newJob.Name, err)
 	}
 
+	if hibernated(newJob) != hibernated(oldJob) {
+		action := bus.AbortJobAction
+		if !hibernated(newJob) {
+			action = bus.ResumeJobAction
+		}
+		req := apis.Request{
+			Namespace: newJob.Namespace,
+			JobName:   newJob.Name,
+			Action:    action,
+		}
+		key := jobhelpers.GetJobKeyByReq(&req)
+		queue := cc.getWorkerQueue(key)
+		queue.Add(req)
+		return
+	}
+
 	// NOTE: Since we only reconcile job based on Spec, we will ignore other attributes
 	// For Job status, it's used internally and always been updated via our controller.
 	if equality.Semantic.DeepEqual(newJob.Spec, oldJob.Spec) && newJob.Status.State.Phase == oldJob.Status.State.Phase {
@@ -132,6 +148,9 @@ func (cc *jobcontroller) deleteJob(obj interface{}) {
 		klog.Errorf("Failed to delete job <%s/%s>: %v in cache",
 			job.Namespace, job.Name, err)
 	}
+	if cc.notifier != nil {
+		cc.notifier.forget(job.UID)
+	}
 }
 
 func (cc *jobcontroller) addPod(obj interface{}) {
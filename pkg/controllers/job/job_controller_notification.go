@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+)
+
+const (
+	// NotificationWebhookAnnotation is the annotation of a job or a queue listing, comma separated,
+	// the endpoints notified of the state changes of the job, or of the jobs of the queue. Only the
+	// endpoints allowed by the administrator are notified. The Slack incoming webhooks are sent a
+	// message, the topics of a Kafka REST proxy a record, the other endpoints the Notification as JSON.
+	NotificationWebhookAnnotation = "volcano.sh/notification-webhook"
+
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+	notificationQueueSize = 1024
+	notificationTimeout   = 5 * time.Second
+)
+
+// NotificationType is the state change of a job notified.
+type NotificationType string
+
+const (
+	NotificationStarted   NotificationType = "started"
+	NotificationPreempted NotificationType = "preempted"
+	NotificationCompleted NotificationType = "completed"
+	NotificationFailed    NotificationType = "failed"
+)
+
+// Notification is posted to the endpoints on a state change of a job.
+type Notification struct {
+	Type      NotificationType  `json:"type"`
+	Namespace string            `json:"namespace"`
+	Job       string            `json:"job"`
+	UID       string            `json:"uid"`
+	Queue     string            `json:"queue"`
+	Phase     batch.JobPhase    `json:"phase"`
+	Message   string            `json:"message,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Time      metav1.Time       `json:"time"`
+}
+
+type notificationRequest struct {
+	endpoint     string
+	notification Notification
+}
+
+// notifier posts the notifications from a single goroutine, so that slow endpoints do not hold
+// the workers of the controller. The notifications are dropped while its queue is full.
+type notifier struct {
+	client   *http.Client
+	requests chan notificationRequest
+	// allowed are the endpoints the administrator allows to be notified
+	allowed sets.Set[string]
+
+	mutex sync.Mutex
+	// notified is the last notification of each job, the same notification is sent once per
+	// state change, e.g. once for all the pods of a job evicted together
+	notified map[types.UID]NotificationType
+}
+
+func newNotifier(allowed []string) *notifier {
+	n := &notifier{
+		client:   &http.Client{Timeout: notificationTimeout},
+		requests: make(chan notificationRequest, notificationQueueSize),
+		allowed:  sets.New[string](),
+		notified: map[types.UID]NotificationType{},
+	}
+	for _, endpoint := range allowed {
+		if endpoint = strings.TrimSpace(endpoint); len(endpoint) != 0 {
+			n.allowed.Insert(endpoint)
+		}
+	}
+	return n
+}
+
+func (n *notifier) run(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case req := <-n.requests:
+			if err := n.post(req.endpoint, req.notification); err != nil {
+				klog.Warningf("Failed to notify <%s> of job <%s/%s> %s: %v", req.endpoint,
+					req.notification.Namespace, req.notification.Job, req.notification.Type, err)
+			}
+		}
+	}
+}
+
+// record returns whether the notification of a job is a state change since its last notification.
+func (n *notifier) record(uid types.UID, notificationType NotificationType) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if last, found := n.notified[uid]; found && last == notificationType {
+		return false
+	}
+	n.notified[uid] = notificationType
+	return true
+}
+
+// forget drops the last notification of a deleted job.
+func (n *notifier) forget(uid types.UID) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	delete(n.notified, uid)
+}
+
+func (n *notifier) notify(endpoints []string, notification Notification) {
+	for _, endpoint := range endpoints {
+		select {
+		case n.requests <- notificationRequest{endpoint: endpoint, notification: notification}:
+		default:
+			klog.Warningf("Dropped the notification of job <%s/%s> %s to <%s>, too many are pending",
+				notification.Namespace, notification.Job, notification.Type, endpoint)
+		}
+	}
+}
+
+func (n *notifier) post(endpoint string, notification Notification) error {
+	var body interface{} = notification
+	contentType := "application/json"
+	switch {
+	case isSlackWebhook(endpoint):
+		body = map[string]string{"text": notification.String()}
+	case isKafkaTopic(endpoint):
+		body = map[string]interface{}{"records": []map[string]interface{}{{"key": notification.UID, "value": notification}}}
+		contentType = kafkaContentType
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(endpoint, contentType, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (n Notification) String() string {
+	s := fmt.Sprintf("Job %s/%s in queue %s %s, phase %s", n.Namespace, n.Job, n.Queue, n.Type, n.Phase)
+	if len(n.Message) != 0 {
+		s += ": " + n.Message
+	}
+	return s
+}
+
+func isSlackWebhook(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && u.Host == "hooks.slack.com"
+}
+
+// isKafkaTopic returns whether the endpoint is a topic of a Kafka REST proxy, to which the
+// notifications are produced as records keyed by the UID of the job.
+func isKafkaTopic(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && strings.HasPrefix(u.Path, "/topics/") && len(u.Path) > len("/topics/")
+}
+
+// notificationOfPhase returns the notification of the job entering phase, if any.
+func notificationOfPhase(phase batch.JobPhase) (NotificationType, bool) {
+	switch phase {
+	case batch.Running:
+		return NotificationStarted, true
+	case batch.Completed:
+		return NotificationCompleted, true
+	case batch.Failed, batch.Terminated, batch.Aborted:
+		return NotificationFailed, true
+	default:
+		return "", false
+	}
+}
+
+// notificationEndpoints returns the allowed endpoints of the job and of its queue.
+func (cc *jobcontroller) notificationEndpoints(job *batch.Job) []string {
+	var endpoints []string
+	annotations := []string{job.Annotations[NotificationWebhookAnnotation]}
+	if cc.queueLister != nil {
+		if queue, err := cc.queueLister.Get(job.Spec.Queue); err == nil {
+			annotations = append(annotations, queue.Annotations[NotificationWebhookAnnotation])
+		}
+	}
+	for _, annotation := range annotations {
+		for _, endpoint := range strings.Split(annotation, ",") {
+			if endpoint = strings.TrimSpace(endpoint); len(endpoint) == 0 {
+				continue
+			}
+			if !cc.notifier.allowed.Has(endpoint) {
+				klog.Warningf("Ignored the notification endpoint <%s> of job <%s/%s>, it is not allowed",
+					endpoint, job.Namespace, job.Name)
+				continue
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// notifyPhase notifies the phase of a job once its status was written.
+func (cc *jobcontroller) notifyPhase(job *batch.Job) {
+	if notificationType, found := notificationOfPhase(job.Status.State.Phase); found {
+		cc.notifyJob(job, notificationType)
+	}
+}
+
+func (cc *jobcontroller) notifyJob(job *batch.Job, notificationType NotificationType) {
+	if cc.notifier == nil || cc.notifier.allowed.Len() == 0 {
+		return
+	}
+	endpoints := cc.notificationEndpoints(job)
+	if len(endpoints) == 0 || !cc.notifier.record(job.UID, notificationType) {
+		return
+	}
+	cc.notifier.notify(endpoints, Notification{
+		Type:      notificationType,
+		Namespace: job.Namespace,
+		Job:       job.Name,
+		UID:       string(job.UID),
+		Queue:     job.Spec.Queue,
+		Phase:     job.Status.State.Phase,
+		Message:   job.Status.State.Message,
+		Labels:    job.Labels,
+		Time:      metav1.Now(),
+	})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+)
+
+func TestNotifyJob(t *testing.T) {
+	received := make(chan Notification, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+		received <- n
+	}))
+	defer server.Close()
+	disallowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected notification of an endpoint that is not allowed")
+	}))
+	defer disallowed.Close()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	cc := &jobcontroller{notifier: newNotifier([]string{server.URL})}
+	go cc.notifier.run(stopCh)
+
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns1",
+			Name:        "job1",
+			UID:         "uid1",
+			Annotations: map[string]string{NotificationWebhookAnnotation: " " + server.URL + " ," + disallowed.URL},
+		},
+		Spec: batch.JobSpec{Queue: "q1"},
+	}
+	job.Status.State.Phase = batch.Running
+	cc.notifyJob(job, NotificationPreempted)
+	// the other pods of the job evicted together are not notified again
+	cc.notifyJob(job, NotificationPreempted)
+	job.Status.State.Phase = batch.Completed
+	cc.notifyPhase(job)
+
+	for _, expected := range []NotificationType{NotificationPreempted, NotificationCompleted} {
+		select {
+		case n := <-received:
+			if n.Type != expected || n.Job != "job1" || n.Queue != "q1" {
+				t.Errorf("expected a %s notification, got %+v", expected, n)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s notification received", expected)
+		}
+	}
+	select {
+	case n := <-received:
+		t.Errorf("unexpected notification %+v", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifyKafkaTopic(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Records []struct {
+				Key   string       `json:"key"`
+				Value Notification `json:"value"`
+			} `json:"records"`
+		}
+		if r.URL.Path != "/topics/volcano" || r.Header.Get("Content-Type") != kafkaContentType {
+			t.Errorf("unexpected request of %s with content type %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Records) != 1 {
+			t.Errorf("failed to decode the records: %v", err)
+			received <- ""
+			return
+		}
+		received <- body.Records[0].Key + "/" + string(body.Records[0].Value.Type)
+	}))
+	defer server.Close()
+
+	n := newNotifier([]string{server.URL + "/topics/volcano"})
+	if err := n.post(server.URL+"/topics/volcano", Notification{Type: NotificationFailed, UID: "uid1"}); err != nil {
+		t.Fatalf("failed to post the notification: %v", err)
+	}
+	if record := <-received; record != "uid1/failed" {
+		t.Errorf("expected the record uid1/failed, got %q", record)
+	}
+}
+
+func TestNotificationOfPhase(t *testing.T) {
+	tests := []struct {
+		phase    batch.JobPhase
+		expected NotificationType
+		found    bool
+	}{
+		{phase: batch.Pending},
+		{phase: batch.Running, expected: NotificationStarted, found: true},
+		{phase: batch.Completed, expected: NotificationCompleted, found: true},
+		{phase: batch.Failed, expected: NotificationFailed, found: true},
+		{phase: batch.Restarting},
+	}
+	for _, test := range tests {
+		notificationType, found := notificationOfPhase(test.phase)
+		if notificationType != test.expected || found != test.found {
+			t.Errorf("phase %s: expected %q %v, got %q %v", test.phase, test.expected, test.found, notificationType, found)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package job
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
@@ -34,8 +35,22 @@ import (
 	"volcano.sh/volcano/pkg/controllers/apis"
 	jobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
 	"volcano.sh/volcano/pkg/controllers/util"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 )
 
+// taskRank returns the rank of the pod of the index in the task across the tasks of the job, the
+// pods of the tasks before it coming first.
+func taskRank(job *batch.Job, taskName string, index int) int {
+	rank := index
+	for _, ts := range job.Spec.Tasks {
+		if ts.Name == taskName {
+			break
+		}
+		rank += int(ts.Replicas)
+	}
+	return rank
+}
+
 // MakePodName append podname,jobname,taskName and index and returns the string.
 func MakePodName(jobName string, taskName string, index int) string {
 	return fmt.Sprintf(jobhelpers.PodNameFmt, jobName, taskName, index)
@@ -103,6 +118,7 @@ func createJobPod(job *batch.Job, template *v1.PodTemplateSpec, topologyPolicy b
 	index := strconv.Itoa(ix)
 	pod.Annotations[batch.TaskIndex] = index
 	pod.Annotations[batch.TaskSpecKey] = tsKey
+	pod.Annotations[schedulingapi.TaskRank] = strconv.Itoa(taskRank(job, template.Name, ix))
 	pgName := job.Name + "-" + string(job.UID)
 	pod.Annotations[schedulingv2.KubeGroupNameAnnotationKey] = pgName
 	pod.Annotations[batch.JobNameKey] = job.Name
@@ -157,9 +173,103 @@ func createJobPod(job *batch.Job, template *v1.PodTemplateSpec, topologyPolicy b
 		pod.Labels[batch.JobForwardingKey] = "true"
 	}
 
+	setNodeFailureTolerations(job, pod)
+
 	return pod
 }
 
+// TaskOverride is the node selection and resources of the pods of a task overriding the ones of
+// its template, set by the TaskOverridesKey annotation of the job.
+type TaskOverride struct {
+	// NodeSelector is merged into the node selector of the template, overriding the same keys
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Affinity replaces the affinity of the template
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// Tolerations are added to the tolerations of the template
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Resources are merged into the requests and limits of each container of the template
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// taskTemplate returns a copy of the pod template of the task with the override of the task set
+// by the job applied, an invalid TaskOverridesKey annotation is ignored.
+func taskTemplate(job *batch.Job, task *batch.TaskSpec) *v1.PodTemplateSpec {
+	template := task.Template.DeepCopy()
+	value, found := job.Annotations[TaskOverridesKey]
+	if !found {
+		return template
+	}
+	overrides := map[string]TaskOverride{}
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		klog.Warningf("Invalid %s of job <%s/%s>, ignored: %v", TaskOverridesKey, job.Namespace, job.Name, err)
+		return template
+	}
+	override, found := overrides[task.Name]
+	if !found {
+		return template
+	}
+
+	if len(override.NodeSelector) > 0 && template.Spec.NodeSelector == nil {
+		template.Spec.NodeSelector = map[string]string{}
+	}
+	for key, value := range override.NodeSelector {
+		template.Spec.NodeSelector[key] = value
+	}
+	if override.Affinity != nil {
+		template.Spec.Affinity = override.Affinity
+	}
+	template.Spec.Tolerations = append(template.Spec.Tolerations, override.Tolerations...)
+	if override.Resources != nil {
+		for i := range template.Spec.Containers {
+			resources := &template.Spec.Containers[i].Resources
+			resources.Requests = mergeResources(resources.Requests, override.Resources.Requests)
+			resources.Limits = mergeResources(resources.Limits, override.Resources.Limits)
+		}
+	}
+	return template
+}
+
+// mergeResources returns the resources with the quantities of override replacing theirs.
+func mergeResources(resources, override v1.ResourceList) v1.ResourceList {
+	if len(override) > 0 && resources == nil {
+		resources = v1.ResourceList{}
+	}
+	for name, quantity := range override {
+		resources[name] = quantity
+	}
+	return resources
+}
+
+// setNodeFailureTolerations makes the pod tolerate the failure of its node for the time set on the
+// job, replacing the tolerations of the pod template and the default ones of the apiserver.
+func setNodeFailureTolerations(job *batch.Job, pod *v1.Pod) {
+	value, found := job.Annotations[NodeFailureTolerationSecondsKey]
+	if !found {
+		return
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		klog.Warningf("Invalid %s=%s of job <%s/%s>, ignored", NodeFailureTolerationSecondsKey, value, job.Namespace, job.Name)
+		return
+	}
+
+	for _, key := range []string{v1.TaintNodeNotReady, v1.TaintNodeUnreachable} {
+		tolerations := make([]v1.Toleration, 0, len(pod.Spec.Tolerations)+1)
+		for _, t := range pod.Spec.Tolerations {
+			if t.Key == key && (t.Effect == v1.TaintEffectNoExecute || len(t.Effect) == 0) {
+				continue
+			}
+			tolerations = append(tolerations, t)
+		}
+		pod.Spec.Tolerations = append(tolerations, v1.Toleration{
+			Key:               key,
+			Operator:          v1.TolerationOpExists,
+			Effect:            v1.TaintEffectNoExecute,
+			TolerationSeconds: &seconds,
+		})
+	}
+}
+
 func applyPolicies(job *batch.Job, req *apis.Request) v1alpha1.Action {
 	if len(req.Action) != 0 {
 		return req.Action
@@ -343,3 +453,68 @@ func calTaskRequests(pod *v1.Pod, validReplica int32) v1.ResourceList {
 	}
 	return minReq
 }
+
+// gateDependentTasks tells whether the pods of the tasks with dependencies are created gated.
+func gateDependentTasks(job *batch.Job) bool {
+	return job.Annotations[GateDependentTasksKey] == "true"
+}
+
+// podGroupAnnotations returns the annotations of the job copied to its podgroup. The boost
+// annotations are left out, the jobs are only boosted on their podgroups by the allowed users.
+func podGroupAnnotations(job *batch.Job) map[string]string {
+	if job.Annotations == nil {
+		return nil
+	}
+	annotations := make(map[string]string, len(job.Annotations))
+	for key, value := range job.Annotations {
+		switch key {
+		case schedulingapi.JobPriorityBoost, schedulingapi.JobPriorityBoostExpiry, schedulingapi.JobPriorityBoostAudit:
+		default:
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// hibernated returns whether the job is hibernated, see HibernateKey.
+func hibernated(job *batch.Job) bool {
+	return job.Annotations[HibernateKey] == "true"
+}
+
+// gangDegraded tells whether the podgroup has the condition of a partially placed best-effort gang.
+func gangDegraded(pg *schedulingv2.PodGroup) bool {
+	for _, condition := range pg.Status.Conditions {
+		if condition.Type == schedulingv2.PodGroupConditionType(schedulingapi.PodGroupGangDegradedType) && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSchedulingGate tells whether the pod has the scheduling gate.
+func hasSchedulingGate(pod *v1.Pod, name string) bool {
+	for _, gate := range pod.Spec.SchedulingGates {
+		if gate.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addSchedulingGate adds the scheduling gate to the pod if it does not have it.
+func addSchedulingGate(pod *v1.Pod, name string) {
+	if !hasSchedulingGate(pod, name) {
+		pod.Spec.SchedulingGates = append(pod.Spec.SchedulingGates, v1.PodSchedulingGate{Name: name})
+	}
+}
+
+// removeSchedulingGate removes the scheduling gate from the pod, the others are kept.
+func removeSchedulingGate(pod *v1.Pod, name string) {
+	var gates []v1.PodSchedulingGate
+	for _, gate := range pod.Spec.SchedulingGates {
+		if gate.Name != name {
+			gates = append(gates, gate)
+		}
+	}
+	pod.Spec.SchedulingGates = gates
+}
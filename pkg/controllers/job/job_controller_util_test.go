@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -28,6 +29,7 @@ import (
 	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
 	busv1alpha1 "volcano.sh/apis/pkg/apis/bus/v1alpha1"
 	"volcano.sh/volcano/pkg/controllers/apis"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 )
 
 func TestMakePodName(t *testing.T) {
@@ -1070,3 +1072,117 @@ func TestCalcPGMinResources(t *testing.T) {
 
 	}
 }
+
+func TestSchedulingGates(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{SchedulingGates: []v1.PodSchedulingGate{{Name: "example.com/other"}}}}
+
+	addSchedulingGate(pod, DependsOnGate)
+	addSchedulingGate(pod, DependsOnGate)
+	expected := []v1.PodSchedulingGate{{Name: "example.com/other"}, {Name: DependsOnGate}}
+	if !reflect.DeepEqual(pod.Spec.SchedulingGates, expected) {
+		t.Errorf("expected gates %v, got %v", expected, pod.Spec.SchedulingGates)
+	}
+	if !hasSchedulingGate(pod, DependsOnGate) {
+		t.Errorf("expected pod to have gate %s", DependsOnGate)
+	}
+
+	removeSchedulingGate(pod, DependsOnGate)
+	expected = []v1.PodSchedulingGate{{Name: "example.com/other"}}
+	if !reflect.DeepEqual(pod.Spec.SchedulingGates, expected) {
+		t.Errorf("expected gates %v, got %v", expected, pod.Spec.SchedulingGates)
+	}
+}
+
+func TestNodeFailureTolerations(t *testing.T) {
+	seconds := int64(60)
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "job1",
+			Namespace:   "ns1",
+			Annotations: map[string]string{NodeFailureTolerationSecondsKey: "60"},
+		},
+	}
+	pod := &v1.Pod{Spec: v1.PodSpec{Tolerations: []v1.Toleration{
+		{Key: "example.com/dedicated", Operator: v1.TolerationOpExists},
+		{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	}}}
+
+	setNodeFailureTolerations(job, pod)
+	expected := []v1.Toleration{
+		{Key: "example.com/dedicated", Operator: v1.TolerationOpExists},
+		{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &seconds},
+		{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &seconds},
+	}
+	if !reflect.DeepEqual(pod.Spec.Tolerations, expected) {
+		t.Errorf("expected tolerations %v, got %v", expected, pod.Spec.Tolerations)
+	}
+
+	job.Annotations[NodeFailureTolerationSecondsKey] = "-1"
+	pod = &v1.Pod{}
+	setNodeFailureTolerations(job, pod)
+	if len(pod.Spec.Tolerations) != 0 {
+		t.Errorf("expected an invalid toleration ignored, got %v", pod.Spec.Tolerations)
+	}
+}
+
+func TestPodGroupAnnotations(t *testing.T) {
+	job := &v1alpha1.Job{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		HibernateKey:                         "true",
+		schedulingapi.JobPriorityBoost:       "true",
+		schedulingapi.JobPriorityBoostExpiry: "2030-01-01T00:00:00Z",
+	}}}
+	expected := map[string]string{HibernateKey: "true"}
+	if annotations := podGroupAnnotations(job); !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("expected the annotations %v copied to the podgroup, got %v", expected, annotations)
+	}
+}
+
+func TestTaskTemplate(t *testing.T) {
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job1",
+			Namespace: "ns1",
+			Annotations: map[string]string{TaskOverridesKey: `{"worker": {
+				"nodeSelector": {"node.kubernetes.io/lifecycle": "spot"},
+				"tolerations": [{"key": "nvidia.com/gpu", "operator": "Exists"}],
+				"resources": {"requests": {"nvidia.com/gpu": "1"}, "limits": {"nvidia.com/gpu": "1"}}
+			}}`},
+		},
+	}
+	template := v1.PodTemplateSpec{Spec: v1.PodSpec{
+		NodeSelector: map[string]string{"node.kubernetes.io/lifecycle": "on-demand", "zone": "a"},
+		Containers: []v1.Container{{Name: "main", Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+		}}},
+	}}
+	driver := &batch.TaskSpec{Name: "driver", Template: template}
+	worker := &batch.TaskSpec{Name: "worker", Template: template}
+
+	if got := taskTemplate(job, driver); !reflect.DeepEqual(got, &template) {
+		t.Errorf("expected the template of a task without override kept, got %v", got)
+	}
+	got := taskTemplate(job, worker)
+	expectedSelector := map[string]string{"node.kubernetes.io/lifecycle": "spot", "zone": "a"}
+	if !reflect.DeepEqual(got.Spec.NodeSelector, expectedSelector) {
+		t.Errorf("expected node selector %v, got %v", expectedSelector, got.Spec.NodeSelector)
+	}
+	expectedTolerations := []v1.Toleration{{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists}}
+	if !reflect.DeepEqual(got.Spec.Tolerations, expectedTolerations) {
+		t.Errorf("expected tolerations %v, got %v", expectedTolerations, got.Spec.Tolerations)
+	}
+	expectedResources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), "nvidia.com/gpu": resource.MustParse("1")},
+		Limits:   v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+	}
+	if !equality.Semantic.DeepEqual(got.Spec.Containers[0].Resources, expectedResources) {
+		t.Errorf("expected resources %v, got %v", expectedResources, got.Spec.Containers[0].Resources)
+	}
+	if template.Spec.NodeSelector["node.kubernetes.io/lifecycle"] != "on-demand" {
+		t.Errorf("expected the template of the task unchanged")
+	}
+
+	job.Annotations[TaskOverridesKey] = "{"
+	if got := taskTemplate(job, worker); !reflect.DeepEqual(got, &template) {
+		t.Errorf("expected an invalid override ignored, got %v", got)
+	}
+}
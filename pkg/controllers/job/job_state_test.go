@@ -31,6 +31,7 @@ import (
 	schedulingapi "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	"volcano.sh/volcano/pkg/controllers/apis"
 	"volcano.sh/volcano/pkg/controllers/job/state"
+	schedulerapi "volcano.sh/volcano/pkg/scheduler/api"
 )
 
 func TestAbortedState_Execute(t *testing.T) {
@@ -1486,3 +1487,129 @@ func TestTerminatingState_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestGangDegradedJobState(t *testing.T) {
+	namespace := "test"
+
+	testcases := []struct {
+		Name          string
+		Phase         v1alpha1.JobPhase
+		GangDegraded  bool
+		ExpectedPhase v1alpha1.JobPhase
+	}{
+		{
+			Name:          "pending job placed partially after its gang budget runs",
+			Phase:         v1alpha1.Pending,
+			GangDegraded:  true,
+			ExpectedPhase: v1alpha1.Running,
+		},
+		{
+			Name:          "pending job below its minAvailable stays pending",
+			Phase:         v1alpha1.Pending,
+			ExpectedPhase: v1alpha1.Pending,
+		},
+		{
+			Name:          "running job placed partially after its gang budget keeps running",
+			Phase:         v1alpha1.Running,
+			GangDegraded:  true,
+			ExpectedPhase: v1alpha1.Running,
+		},
+		{
+			Name:          "running job below its minAvailable goes back to pending",
+			Phase:         v1alpha1.Running,
+			ExpectedPhase: v1alpha1.Pending,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Name, func(t *testing.T) {
+			job := &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "job1",
+					Namespace:       namespace,
+					ResourceVersion: "100",
+					UID:             "e7f18111-1cec-11ea-b688-fa163ec79500",
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 3,
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task1",
+							Replicas: 3,
+							Template: v1.PodTemplateSpec{
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{{Name: "Containers"}},
+								},
+							},
+						},
+					},
+				},
+				Status: v1alpha1.JobStatus{
+					State: v1alpha1.JobState{
+						Phase: testcase.Phase,
+					},
+				},
+			}
+			jobInfo := &apis.JobInfo{
+				Namespace: namespace,
+				Name:      job.Name,
+				Job:       job,
+				Pods: map[string]map[string]*v1.Pod{
+					"task1": {
+						"job1-task1-0": buildPod(namespace, "job1-task1-0", v1.PodRunning, nil),
+						"job1-task1-1": buildPod(namespace, "job1-task1-1", v1.PodRunning, nil),
+						"job1-task1-2": buildPod(namespace, "job1-task1-2", v1.PodPending, nil),
+					},
+				},
+			}
+			pg := &schedulingapi.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1-e7f18111-1cec-11ea-b688-fa163ec79500",
+					Namespace: namespace,
+				},
+				Spec: schedulingapi.PodGroupSpec{
+					MinMember:    3,
+					MinResources: &v1.ResourceList{},
+				},
+				Status: schedulingapi.PodGroupStatus{
+					Phase: schedulingapi.PodGroupRunning,
+				},
+			}
+			if testcase.GangDegraded {
+				pg.Status.Conditions = []schedulingapi.PodGroupCondition{
+					{
+						Type:   schedulingapi.PodGroupConditionType(schedulerapi.PodGroupGangDegradedType),
+						Status: v1.ConditionTrue,
+					},
+				}
+			}
+
+			fakecontroller := newFakeController()
+			patches := gomonkey.ApplyMethod(reflect.TypeOf(fakecontroller), "GetQueueInfo", func(_ *jobcontroller, _ string) (*schedulingapi.Queue, error) {
+				return &schedulingapi.Queue{}, nil
+			})
+			defer patches.Reset()
+
+			fakecontroller.pgInformer.Informer().GetIndexer().Add(pg)
+			fakecontroller.vcClient.SchedulingV1beta1().PodGroups(namespace).Create(context.TODO(), pg, metav1.CreateOptions{})
+			if _, err := fakecontroller.vcClient.BatchV1alpha1().Jobs(namespace).Create(context.TODO(), job, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("Error while creating Job: %v", err)
+			}
+			if err := fakecontroller.cache.Add(job); err != nil {
+				t.Fatalf("Error while adding Job in cache: %v", err)
+			}
+
+			if err := state.NewState(jobInfo).Execute(busv1alpha1.SyncJobAction); err != nil {
+				t.Fatalf("Expected Error not to occur but got: %s", err)
+			}
+
+			cached, err := fakecontroller.cache.Get(fmt.Sprintf("%s/%s", namespace, job.Name))
+			if err != nil {
+				t.Fatalf("Error while retrieving value from Cache: %v", err)
+			}
+			if cached.Job.Status.State.Phase != testcase.ExpectedPhase {
+				t.Errorf("Expected Job phase to %s, but got %s", testcase.ExpectedPhase, cached.Job.Status.State.Phase)
+			}
+		})
+	}
+}
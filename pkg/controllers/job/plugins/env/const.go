@@ -25,4 +25,7 @@ const (
 
 	// TaskIndex is used as key in container env
 	TaskIndex = "VC_TASK_INDEX"
+
+	// Rank is used as key in container env, the rank of the pod across the tasks of the job
+	Rank = "VC_RANK"
 )
@@ -22,6 +22,7 @@ import (
 	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
 	jobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
 	pluginsinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 )
 
 type envPlugin struct {
@@ -57,6 +58,16 @@ func (ep *envPlugin) OnPodCreate(pod *v1.Pod, job *batch.Job) error {
 		pod.Spec.InitContainers[i].Env = append(pod.Spec.InitContainers[i].Env, v1.EnvVar{Name: TaskIndex, Value: index})
 	}
 
+	// add VC_RANK env to each container, for the collective libraries building their rings by rank
+	if rank, found := pod.Annotations[schedulingapi.TaskRank]; found {
+		for i := range pod.Spec.Containers {
+			pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, v1.EnvVar{Name: Rank, Value: rank})
+		}
+		for i := range pod.Spec.InitContainers {
+			pod.Spec.InitContainers[i].Env = append(pod.Spec.InitContainers[i].Env, v1.EnvVar{Name: Rank, Value: rank})
+		}
+	}
+
 	return nil
 }
 
@@ -36,6 +36,9 @@ type ActionFn func(job *apis.JobInfo, fn UpdateStatusFn) error
 // KillActionFn kill all Pods of Job with phase not in podRetainPhase.
 type KillActionFn func(job *apis.JobInfo, podRetainPhase PhaseMap, fn UpdateStatusFn) error
 
+// PodGroupFn tells whether the PodGroup of Job is in a given condition.
+type PodGroupFn func(job *apis.JobInfo) bool
+
 // PodRetainPhaseNone stores no phase.
 var PodRetainPhaseNone = PhaseMap{}
 
@@ -50,6 +53,9 @@ var (
 	SyncJob ActionFn
 	// KillJob kill all Pods of Job with phase not in podRetainPhase.
 	KillJob KillActionFn
+	// GangDegraded tells whether the scheduler placed the PodGroup of Job partially, with less
+	// pods than minAvailable, after the gang budget of its best-effort gang.
+	GangDegraded PodGroupFn
 )
 
 // State interface.
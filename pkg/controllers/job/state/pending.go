@@ -52,7 +52,9 @@ func (ps *pendingState) Execute(action v1alpha1.Action) error {
 		})
 	default:
 		return SyncJob(ps.job, func(status *vcbatch.JobStatus) bool {
-			if ps.job.Job.Spec.MinAvailable <= status.Running+status.Succeeded+status.Failed {
+			// a partially placed best-effort gang runs with less pods than minAvailable
+			if ps.job.Job.Spec.MinAvailable <= status.Running+status.Succeeded+status.Failed ||
+				(status.Running > 0 && isGangDegraded(ps.job)) {
 				status.State.Phase = vcbatch.Running
 				return true
 			}
@@ -18,6 +18,7 @@ package state
 
 import (
 	vcbatch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/controllers/apis"
 )
 
 // TotalTasks returns number of tasks in a given volcano job.
@@ -45,3 +46,8 @@ func TotalTaskMinAvailable(job *vcbatch.Job) int32 {
 
 	return rep
 }
+
+// isGangDegraded returns whether the Job runs partially placed, see GangDegraded.
+func isGangDegraded(job *apis.JobInfo) bool {
+	return GangDegraded != nil && GangDegraded(job)
+}
@@ -25,4 +25,12 @@ const (
 	CreatedByJobTemplate = "volcano.sh/createdByJobTemplate"
 	// CreatedByJobFlow the vcjob annotation and label of created by jobFlow
 	CreatedByJobFlow = "volcano.sh/createdByJobFlow"
+
+	// FailurePolicyAnnotation the jobFlow annotation of how the failure of a job is propagated
+	FailurePolicyAnnotation = "volcano.sh/jobflow-failure-policy"
+	// FailurePolicyContinue keeps creating the jobs which do not depend on a failed job, the jobFlow
+	// fails once no more job can run. It is the default policy.
+	FailurePolicyContinue = "Continue"
+	// FailurePolicyFailFast stops creating jobs and fails the jobFlow as soon as a job fails
+	FailurePolicyFailFast = "FailFast"
 )
@@ -63,6 +63,13 @@ func (jf *jobflowcontroller) syncJobFlow(jobFlow *v1alpha1flow.JobFlow, updateSt
 	}
 	jobFlow.Status = *jobFlowStatus
 	updateStateFn(&jobFlow.Status, len(jobFlow.Spec.Flows))
+	phases, err := jf.getFlowJobPhases(jobFlow)
+	if err != nil {
+		return err
+	}
+	if isJobFlowFailed(jobFlow, phases) {
+		jobFlow.Status.State.Phase = v1alpha1flow.Failed
+	}
 	_, err = jf.vcClient.FlowV1alpha1().JobFlows(jobFlow.Namespace).UpdateStatus(context.Background(), jobFlow, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to update status of JobFlow %v/%v: %v",
@@ -74,6 +81,19 @@ func (jf *jobflowcontroller) syncJobFlow(jobFlow *v1alpha1flow.JobFlow, updateSt
 }
 
 func (jf *jobflowcontroller) deployJob(jobFlow *v1alpha1flow.JobFlow) error {
+	if getFailurePolicy(jobFlow) == FailurePolicyFailFast {
+		phases, err := jf.getFlowJobPhases(jobFlow)
+		if err != nil {
+			return err
+		}
+		for name, phase := range phases {
+			if isJobFailed(phase) {
+				klog.V(3).Infof("Job of flow %s of JobFlow %s/%s failed, stop creating jobs", name, jobFlow.Namespace, jobFlow.Name)
+				return nil
+			}
+		}
+	}
+
 	// load jobTemplate by flow and deploy it
 	for _, flow := range jobFlow.Spec.Flows {
 		jobName := getJobName(jobFlow.Name, flow.Name)
@@ -123,6 +143,22 @@ func (jf *jobflowcontroller) judge(jobFlow *v1alpha1flow.JobFlow, flow v1alpha1f
 	return true, nil
 }
 
+// getFlowJobPhases returns the phase of the job of each flow which has been created.
+func (jf *jobflowcontroller) getFlowJobPhases(jobFlow *v1alpha1flow.JobFlow) (map[string]v1alpha1.JobPhase, error) {
+	phases := map[string]v1alpha1.JobPhase{}
+	for _, flow := range jobFlow.Spec.Flows {
+		job, err := jf.jobLister.Jobs(jobFlow.Namespace).Get(getJobName(jobFlow.Name, flow.Name))
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		phases[flow.Name] = job.Status.State.Phase
+	}
+	return phases, nil
+}
+
 // createJob
 func (jf *jobflowcontroller) createJob(jobFlow *v1alpha1flow.JobFlow, flow v1alpha1flow.Flow) error {
 	job := new(v1alpha1.Job)
@@ -257,6 +293,10 @@ func (jf *jobflowcontroller) loadJobTemplateAndSetJob(jobFlow *v1alpha1flow.JobF
 	if err != nil {
 		return err
 	}
+	spec, err := renderJobTemplate(jobTemplate, jobFlow)
+	if err != nil {
+		return fmt.Errorf("failed to render jobTemplate %s: %v", flowName, err)
+	}
 
 	*job = v1alpha1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -271,7 +311,7 @@ func (jf *jobflowcontroller) loadJobTemplateAndSetJob(jobFlow *v1alpha1flow.JobF
 				CreatedByJobFlow:     GenerateObjectString(jobFlow.Namespace, jobFlow.Name),
 			},
 		},
-		Spec:   jobTemplate.Spec,
+		Spec:   *spec,
 		Status: v1alpha1.JobStatus{},
 	}
 
@@ -23,6 +23,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	flow "volcano.sh/apis/pkg/apis/flow/v1alpha1"
+	"volcano.sh/volcano/pkg/util/template"
 )
 
 func getJobName(jobFlowName string, jobTemplateName string) string {
@@ -53,3 +55,80 @@ func getJobFlowNameByJob(job *batch.Job) string {
 	}
 	return ""
 }
+
+// renderJobTemplate substitutes the parameters of the jobFlow, or the defaults of the jobTemplate,
+// into the job spec of the jobTemplate. The jobTemplates declaring no parameters are used as is.
+func renderJobTemplate(jobTemplate *flow.JobTemplate, jobFlow *flow.JobFlow) (*batch.JobSpec, error) {
+	value, found := jobTemplate.Annotations[template.ParametersAnnotation]
+	if !found {
+		return jobTemplate.Spec.DeepCopy(), nil
+	}
+	defaults, err := template.ParseParameters(value)
+	if err != nil {
+		return nil, err
+	}
+	params, err := template.ParseParameters(jobFlow.Annotations[template.ParametersAnnotation])
+	if err != nil {
+		return nil, err
+	}
+	return template.Render(&jobTemplate.Spec, defaults, params)
+}
+
+// isJobFailed returns whether a job finished without completing. An aborted job is suspended,
+// it may be resumed, so it did not fail.
+func isJobFailed(phase batch.JobPhase) bool {
+	return phase == batch.Failed || phase == batch.Terminated
+}
+
+func getFailurePolicy(jobFlow *flow.JobFlow) string {
+	if jobFlow.Annotations[FailurePolicyAnnotation] == FailurePolicyFailFast {
+		return FailurePolicyFailFast
+	}
+	return FailurePolicyContinue
+}
+
+// blockedFlows returns the flows which depend, directly or not, on a failed job, so they never run.
+func blockedFlows(jobFlow *flow.JobFlow, phases map[string]batch.JobPhase) map[string]bool {
+	blocked := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, f := range jobFlow.Spec.Flows {
+			if blocked[f.Name] || f.DependsOn == nil {
+				continue
+			}
+			for _, target := range f.DependsOn.Targets {
+				if blocked[target] || isJobFailed(phases[target]) {
+					blocked[f.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return blocked
+}
+
+// isJobFlowFailed returns whether the jobFlow failed according to its failure policy: with
+// FailFast as soon as a job fails, with Continue once every job either finished or is blocked
+// by a failed job and one of them failed.
+func isJobFlowFailed(jobFlow *flow.JobFlow, phases map[string]batch.JobPhase) bool {
+	failed := false
+	for _, f := range jobFlow.Spec.Flows {
+		if isJobFailed(phases[f.Name]) {
+			failed = true
+			break
+		}
+	}
+	if !failed || getFailurePolicy(jobFlow) == FailurePolicyFailFast {
+		return failed
+	}
+
+	blocked := blockedFlows(jobFlow, phases)
+	for _, f := range jobFlow.Spec.Flows {
+		phase := phases[f.Name]
+		if !blocked[f.Name] && phase != batch.Completed && !isJobFailed(phase) {
+			return false
+		}
+	}
+	return true
+}
@@ -22,6 +22,8 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	flow "volcano.sh/apis/pkg/apis/flow/v1alpha1"
+	"volcano.sh/volcano/pkg/util/template"
 )
 
 func TestGetJobNameFunc(t *testing.T) {
@@ -121,3 +123,114 @@ func TestGetJobFlowNameByJob(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderJobTemplate(t *testing.T) {
+	jobTemplate := &flow.JobTemplate{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "train",
+			Annotations: map[string]string{template.ParametersAnnotation: "queue=default,scheduler=volcano"},
+		},
+		Spec: batch.JobSpec{Queue: "${queue}", SchedulerName: "${scheduler}"},
+	}
+	jobFlow := &flow.JobFlow{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "flow",
+			Annotations: map[string]string{template.ParametersAnnotation: "queue=research"},
+		},
+	}
+
+	spec, err := renderJobTemplate(jobTemplate, jobFlow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Queue != "research" || spec.SchedulerName != "volcano" {
+		t.Errorf("expected queue research and scheduler volcano, got %s and %s", spec.Queue, spec.SchedulerName)
+	}
+
+	jobTemplate.Annotations = nil
+	spec, err = renderJobTemplate(jobTemplate, jobFlow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Queue != "${queue}" || spec.SchedulerName != "${scheduler}" {
+		t.Errorf("expected the jobTemplate without parameters to be used as is, got %s and %s", spec.Queue, spec.SchedulerName)
+	}
+}
+
+func TestIsJobFlowFailed(t *testing.T) {
+	// a then b and c, then d
+	buildJobFlow := func(policy string) *flow.JobFlow {
+		return &flow.JobFlow{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "flow",
+				Annotations: map[string]string{FailurePolicyAnnotation: policy},
+			},
+			Spec: flow.JobFlowSpec{
+				Flows: []flow.Flow{
+					{Name: "a"},
+					{Name: "b", DependsOn: &flow.DependsOn{Targets: []string{"a"}}},
+					{Name: "c", DependsOn: &flow.DependsOn{Targets: []string{"a"}}},
+					{Name: "d", DependsOn: &flow.DependsOn{Targets: []string{"b", "c"}}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		phases map[string]batch.JobPhase
+		want   bool
+	}{
+		{
+			name:   "no failed job",
+			policy: FailurePolicyFailFast,
+			phases: map[string]batch.JobPhase{"a": batch.Completed, "b": batch.Running},
+			want:   false,
+		},
+		{
+			name:   "fail fast",
+			policy: FailurePolicyFailFast,
+			phases: map[string]batch.JobPhase{"a": batch.Completed, "b": batch.Failed, "c": batch.Running},
+			want:   true,
+		},
+		{
+			name:   "continue while another branch runs",
+			policy: FailurePolicyContinue,
+			phases: map[string]batch.JobPhase{"a": batch.Completed, "b": batch.Failed, "c": batch.Running},
+			want:   false,
+		},
+		{
+			name:   "continue until the remaining jobs are blocked",
+			policy: FailurePolicyContinue,
+			phases: map[string]batch.JobPhase{"a": batch.Completed, "b": batch.Failed, "c": batch.Completed},
+			want:   true,
+		},
+		{
+			name:   "the default policy is continue",
+			policy: "",
+			phases: map[string]batch.JobPhase{"a": batch.Completed, "b": batch.Terminated, "c": batch.Pending},
+			want:   false,
+		},
+		{
+			name:   "an aborted job does not fail fast",
+			policy: FailurePolicyFailFast,
+			phases: map[string]batch.JobPhase{"a": batch.Completed, "b": batch.Aborted, "c": batch.Running},
+			want:   false,
+		},
+		{
+			name:   "an aborted job does not block its dependents",
+			policy: FailurePolicyContinue,
+			phases: map[string]batch.JobPhase{"a": batch.Completed, "b": batch.Aborted, "c": batch.Failed},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isJobFlowFailed(buildJobFlow(tt.policy), tt.phases); got != tt.want {
+				t.Errorf("isJobFlowFailed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
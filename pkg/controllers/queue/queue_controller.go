@@ -36,6 +36,7 @@ import (
 	"k8s.io/klog/v2"
 
 	busv1alpha1 "volcano.sh/apis/pkg/apis/bus/v1alpha1"
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	vcclientset "volcano.sh/apis/pkg/client/clientset/versioned"
 	versionedscheme "volcano.sh/apis/pkg/client/clientset/versioned/scheme"
 	vcinformer "volcano.sh/apis/pkg/client/informers/externalversions"
@@ -47,6 +48,7 @@ import (
 	"volcano.sh/volcano/pkg/controllers/framework"
 	queuestate "volcano.sh/volcano/pkg/controllers/queue/state"
 	"volcano.sh/volcano/pkg/features"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 )
 
 func init() {
@@ -89,6 +91,10 @@ type queuecontroller struct {
 
 	enqueueQueue func(req *apis.Request)
 
+	quotaMutex sync.Mutex
+	// queue name -> time of the pending resync at the next quota window boundary
+	quotaResyncs map[string]time.Time
+
 	recorder      record.EventRecorder
 	maxRequeueNum int
 }
@@ -120,6 +126,7 @@ func (c *queuecontroller) Initialize(opt *framework.ControllerOption) error {
 	c.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
 	c.commandQueue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
 	c.podGroups = make(map[string]map[string]struct{})
+	c.quotaResyncs = make(map[string]time.Time)
 	c.recorder = eventBroadcaster.NewRecorder(versionedscheme.Scheme, v1.EventSource{Component: "vc-controller-manager"})
 	c.maxRequeueNum = opt.MaxRequeueNum
 	if c.maxRequeueNum < 0 {
@@ -248,9 +255,49 @@ func (c *queuecontroller) handleQueue(req *apis.Request) error {
 			req.QueueName, err, req.Event, req.Action)
 	}
 
+	c.scheduleQuotaResync(queue)
+
 	return nil
 }
 
+// scheduleQuotaResync resyncs the queue when the active window of its quota schedule changes,
+// so that the switch is reflected without waiting for another queue event.
+func (c *queuecontroller) scheduleQuotaResync(queue *schedulingv1beta1.Queue) {
+	value, found := queue.Annotations[schedulingapi.QuotaScheduleAnnotationKey]
+	if !found {
+		return
+	}
+	windows, err := schedulingapi.ParseQuotaSchedule(value)
+	if err != nil {
+		klog.Errorf("Failed to parse quota schedule of queue %s: %v.", queue.Name, err)
+		c.recorder.Event(queue, v1.EventTypeWarning, "InvalidQuotaSchedule", err.Error())
+		return
+	}
+	now := time.Now()
+	next := schedulingapi.NextQuotaWindowChange(windows, now)
+	if next == 0 {
+		return
+	}
+
+	c.quotaMutex.Lock()
+	defer c.quotaMutex.Unlock()
+	at := now.Add(next)
+	if pending, found := c.quotaResyncs[queue.Name]; found && pending.After(now) && !pending.After(at) {
+		return
+	}
+	c.quotaResyncs[queue.Name] = at
+
+	if w := schedulingapi.ActiveQuotaWindow(windows, now); w != nil {
+		klog.V(3).Infof("Quota window %s-%s of queue %s is active, next change in %v.", w.Start, w.End, queue.Name, next)
+	}
+	c.queue.AddAfter(&apis.Request{
+		QueueName: queue.Name,
+
+		Event:  busv1alpha1.OutOfSyncEvent,
+		Action: busv1alpha1.SyncQueueAction,
+	}, next)
+}
+
 func (c *queuecontroller) handleQueueErr(err error, obj interface{}) {
 	if err == nil {
 		c.queue.Forget(obj)
@@ -57,6 +57,10 @@ func (c *queuecontroller) deleteQueue(obj interface{}) {
 		}
 	}
 
+	c.quotaMutex.Lock()
+	delete(c.quotaResyncs, queue.Name)
+	c.quotaMutex.Unlock()
+
 	c.pgMutex.Lock()
 	defer c.pgMutex.Unlock()
 	delete(c.podGroups, queue.Name)
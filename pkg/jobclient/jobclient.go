@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jobclient submits volcano jobs and follows them from Go programs. The typed clientset,
+// listers and informers of all the volcano CRDs are published by volcano.sh/apis/pkg/client.
+package jobclient
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+)
+
+// Client submits the jobs and follows their progress.
+type Client struct {
+	vcClient   versioned.Interface
+	kubeClient kubernetes.Interface
+}
+
+// New returns a Client using the given clientsets.
+func New(vcClient versioned.Interface, kubeClient kubernetes.Interface) *Client {
+	return &Client{vcClient: vcClient, kubeClient: kubeClient}
+}
+
+// NewForConfig returns a Client for the cluster of config.
+func NewForConfig(config *rest.Config) (*Client, error) {
+	vcClient, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(vcClient, kubeClient), nil
+}
+
+// SubmitJob creates the job, in the default namespace if it has none.
+func (c *Client) SubmitJob(ctx context.Context, job *batch.Job) (*batch.Job, error) {
+	namespace := job.Namespace
+	if len(namespace) == 0 {
+		namespace = metav1.NamespaceDefault
+	}
+	return c.vcClient.BatchV1alpha1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+}
+
+// IsFinished returns whether the job reached a phase it does not leave by itself.
+func IsFinished(job *batch.Job) bool {
+	switch job.Status.State.Phase {
+	case batch.Completed, batch.Failed, batch.Terminated, batch.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForCompletion blocks until the job is finished and returns it in its last state, or until
+// ctx is done.
+func (c *Client) WaitForCompletion(ctx context.Context, namespace, name string) (*batch.Job, error) {
+	jobs := c.vcClient.BatchV1alpha1().Jobs(namespace)
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	for {
+		// the watch is opened before the job is read, so no update is missed in between
+		watcher, err := jobs.Watch(ctx, metav1.ListOptions{FieldSelector: selector})
+		if err != nil {
+			return nil, err
+		}
+		job, err := jobs.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			watcher.Stop()
+			return nil, err
+		}
+		if IsFinished(job) {
+			watcher.Stop()
+			return job, nil
+		}
+
+		job, err = waitForFinished(ctx, watcher, name)
+		watcher.Stop()
+		if err != nil || job != nil {
+			return job, err
+		}
+		// the watch expired, it is opened again
+	}
+}
+
+// waitForFinished returns the job once finished, or nil when the watch is closed.
+func waitForFinished(ctx context.Context, watcher watch.Interface, name string) (*batch.Job, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil, nil
+			}
+			switch event.Type {
+			case watch.Deleted:
+				return nil, fmt.Errorf("job <%s> was deleted before completion", name)
+			case watch.Error:
+				return nil, fmt.Errorf("failed to watch job <%s>: %v", name, event.Object)
+			}
+			job, ok := event.Object.(*batch.Job)
+			if !ok || job.Name != name {
+				continue
+			}
+			if IsFinished(job) {
+				return job, nil
+			}
+		}
+	}
+}
+
+// StreamEvents sends the events recorded for the job until ctx is done. The returned channel is
+// closed once ctx is done or the watch ends.
+func (c *Client) StreamEvents(ctx context.Context, namespace, name string) (<-chan v1.Event, error) {
+	selector := fields.Set{
+		"involvedObject.kind": "Job",
+		"involvedObject.name": name,
+	}.AsSelector().String()
+	watcher, err := c.kubeClient.CoreV1().Events(namespace).Watch(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan v1.Event)
+	go func() {
+		defer close(events)
+		defer watcher.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+				event, ok := e.Object.(*v1.Event)
+				if !ok || e.Type == watch.Deleted {
+					continue
+				}
+				if event.InvolvedObject.Kind != "Job" || event.InvolvedObject.Name != name {
+					continue
+				}
+				select {
+				case events <- *event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+func TestSubmitAndWaitForCompletion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	vcClient := vcfake.NewSimpleClientset()
+	client := New(vcClient, kubefake.NewSimpleClientset())
+
+	job, err := client.SubmitJob(ctx, &batch.Job{ObjectMeta: metav1.ObjectMeta{Name: "job1"}})
+	if err != nil {
+		t.Fatalf("failed to submit job: %v", err)
+	}
+	if job.Namespace != metav1.NamespaceDefault {
+		t.Errorf("expected the job in namespace %s, got %s", metav1.NamespaceDefault, job.Namespace)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		job := job.DeepCopy()
+		job.Status.State.Phase = batch.Running
+		job, _ = vcClient.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(ctx, job, metav1.UpdateOptions{})
+		job.Status.State.Phase = batch.Completed
+		vcClient.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(ctx, job, metav1.UpdateOptions{})
+	}()
+
+	finished, err := client.WaitForCompletion(ctx, job.Namespace, job.Name)
+	if err != nil {
+		t.Fatalf("failed to wait for job: %v", err)
+	}
+	if finished.Status.State.Phase != batch.Completed {
+		t.Errorf("expected phase %s, got %s", batch.Completed, finished.Status.State.Phase)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	kubeClient := kubefake.NewSimpleClientset()
+	client := New(vcfake.NewSimpleClientset(), kubeClient)
+
+	events, err := client.StreamEvents(ctx, "ns1", "job1")
+	if err != nil {
+		t.Fatalf("failed to stream events: %v", err)
+	}
+
+	for _, e := range []*v1.Event{
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "e1", Namespace: "ns1"},
+			InvolvedObject: v1.ObjectReference{Kind: "Job", Name: "job2"},
+			Reason:         "Other",
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "e2", Namespace: "ns1"},
+			InvolvedObject: v1.ObjectReference{Kind: "Job", Name: "job1"},
+			Reason:         "PhaseTransition",
+		},
+	} {
+		if _, err := kubeClient.CoreV1().Events("ns1").Create(ctx, e, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+	}
+
+	select {
+	case event := <-events:
+		if event.Reason != "PhaseTransition" {
+			t.Errorf("expected the event of job1, got %s", event.Reason)
+		}
+	case <-ctx.Done():
+		t.Fatalf("no event received")
+	}
+}
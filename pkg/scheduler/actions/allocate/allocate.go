@@ -17,6 +17,7 @@
 package allocate
 
 import (
+	"fmt"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -24,6 +25,7 @@ import (
 	"volcano.sh/apis/pkg/apis/scheduling"
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/explain"
 	"volcano.sh/volcano/pkg/scheduler/framework"
 	"volcano.sh/volcano/pkg/scheduler/metrics"
 	"volcano.sh/volcano/pkg/scheduler/util"
@@ -33,11 +35,22 @@ type Action struct {
 	session *framework.Session
 	// configured flag for error cache
 	enablePredicateErrorCache bool
+	// configured flag for allocating single-pod jobs by the fast path
+	enableFastPath bool
+	// the number of feasible nodes scored by the fast path
+	fastPathNodes int
+	// the index of the node the fast path starts looking up from
+	fastPathOffset int
+	// the pending jobs ordered by queue then job, kept across the sessions of each profile as the
+	// action is shared by the profiles
+	jobsQueues map[string]*util.JobsQueue
 }
 
 func New() *Action {
 	return &Action{
 		enablePredicateErrorCache: true, // default to enable it
+		fastPathNodes:             defaultFastPathNodes,
+		jobsQueues:                map[string]*util.JobsQueue{},
 	}
 }
 
@@ -50,6 +63,11 @@ func (alloc *Action) Initialize() {}
 func (alloc *Action) parseArguments(ssn *framework.Session) {
 	arguments := framework.GetArgOfActionFromConf(ssn.Configurations, alloc.Name())
 	arguments.GetBool(&alloc.enablePredicateErrorCache, conf.EnablePredicateErrCacheKey)
+	arguments.GetBool(&alloc.enableFastPath, conf.EnableFastPathKey)
+	arguments.GetInt(&alloc.fastPathNodes, conf.FastPathNodesKey)
+	if alloc.fastPathNodes <= 0 {
+		alloc.fastPathNodes = defaultFastPathNodes
+	}
 }
 
 func (alloc *Action) Execute(ssn *framework.Session) {
@@ -65,58 +83,56 @@ func (alloc *Action) Execute(ssn *framework.Session) {
 	// 4. use predicateFn to filter out node that T can not be allocated on.
 	// 5. use ssn.NodeOrderFn to judge the best node and assign it to T
 
-	// queues sort queues by QueueOrderFn.
-	queues := util.NewPriorityQueue(ssn.QueueOrderFn)
-	// jobsMap is used to find job with the highest priority in given queue.
-	jobsMap := map[api.QueueID]*util.PriorityQueue{}
+	if recorder := explain.Default(); recorder.Enabled() {
+		recorder.StartCycle(string(ssn.UID))
+		defer recorder.EndCycle()
+	}
 
 	alloc.session = ssn
-	alloc.pickUpQueuesAndJobs(queues, jobsMap)
-	klog.V(3).Infof("Try to allocate resource to %d Queues", len(jobsMap))
-	alloc.allocateResources(queues, jobsMap)
+	if alloc.enableFastPath {
+		alloc.allocateSinglePodJobs()
+	}
+	// jobsQueue sorts queues by QueueOrderFn, then the jobs of each queue by JobOrderFn.
+	jobsQueue := ssn.UpdateJobsQueue(alloc.jobsQueues[ssn.Profile()], alloc.pickUpJob)
+	alloc.jobsQueues[ssn.Profile()] = jobsQueue
+	klog.V(3).Infof("Try to allocate resource to %d Queues", jobsQueue.Len())
+	alloc.allocateResources(jobsQueue)
 }
 
-func (alloc *Action) pickUpQueuesAndJobs(queues *util.PriorityQueue, jobsMap map[api.QueueID]*util.PriorityQueue) {
+// pickUpJob returns whether the job is considered for allocation.
+func (alloc *Action) pickUpJob(job *api.JobInfo) bool {
 	ssn := alloc.session
-	for _, job := range ssn.Jobs {
-		// If not config enqueue action, change Pending pg into Inqueue statue to avoid blocking job scheduling.
-		if job.IsPending() {
-			if conf.EnabledActionMap["enqueue"] {
-				klog.V(4).Infof("Job <%s/%s> Queue <%s> skip allocate, reason: job status is pending.",
-					job.Namespace, job.Name, job.Queue)
-				continue
-			} else {
-				klog.V(4).Infof("Job <%s/%s> Queue <%s> status update from pending to inqueue, reason: no enqueue action is configured.",
-					job.Namespace, job.Name, job.Queue)
-				job.PodGroup.Status.Phase = scheduling.PodGroupInqueue
-			}
-		}
-
-		if vr := ssn.JobValid(job); vr != nil && !vr.Pass {
-			klog.V(4).Infof("Job <%s/%s> Queue <%s> skip allocate, reason: %v, message %v", job.Namespace, job.Name, job.Queue, vr.Reason, vr.Message)
-			continue
-		}
-
-		if _, found := ssn.Queues[job.Queue]; !found {
-			klog.Warningf("Skip adding Job <%s/%s> because its queue %s is not found",
+	// If not config enqueue action, change Pending pg into Inqueue statue to avoid blocking job scheduling.
+	if job.IsPending() {
+		if conf.EnabledActionMap["enqueue"] {
+			klog.V(4).Infof("Job <%s/%s> Queue <%s> skip allocate, reason: job status is pending.",
 				job.Namespace, job.Name, job.Queue)
-			continue
+			return false
 		}
+		klog.V(4).Infof("Job <%s/%s> Queue <%s> status update from pending to inqueue, reason: no enqueue action is configured.",
+			job.Namespace, job.Name, job.Queue)
+		job.PodGroup.Status.Phase = scheduling.PodGroupInqueue
+	}
 
-		if _, found := jobsMap[job.Queue]; !found {
-			jobsMap[job.Queue] = util.NewPriorityQueue(ssn.JobOrderFn)
-			queues.Push(ssn.Queues[job.Queue])
-		}
+	if vr := ssn.JobValid(job); vr != nil && !vr.Pass {
+		klog.V(4).Infof("Job <%s/%s> Queue <%s> skip allocate, reason: %v, message %v", job.Namespace, job.Name, job.Queue, vr.Reason, vr.Message)
+		return false
+	}
 
-		klog.V(4).Infof("Added Job <%s/%s> into Queue <%s>", job.Namespace, job.Name, job.Queue)
-		jobsMap[job.Queue].Push(job)
+	if ssn.BackedOff(job) {
+		klog.V(4).Infof("Job <%s/%s> Queue <%s> skip allocate, reason: backed off after failing to be scheduled",
+			job.Namespace, job.Name, job.Queue)
+		return false
 	}
+
+	klog.V(4).Infof("Added Job <%s/%s> into Queue <%s>", job.Namespace, job.Name, job.Queue)
+	return true
 }
 
 // allocateResources primarily accomplishes two steps:
 // 1. picks up tasks.
 // 2. allocates resources to these tasks. (this step is carried out by the allocateResourcesForTasks method.)
-func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[api.QueueID]*util.PriorityQueue) {
+func (alloc *Action) allocateResources(jobsQueue *util.JobsQueue) {
 	ssn := alloc.session
 	pendingTasks := map[api.JobID]*util.PriorityQueue{}
 
@@ -126,12 +142,11 @@ func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[a
 	// Because we believe that number of queues would less than namespaces in most case.
 	// And, this action would make the resource usage among namespace balanced.
 	for {
-		if queues.Empty() {
+		queue := jobsQueue.PopQueue()
+		if queue == nil {
 			break
 		}
 
-		queue := queues.Pop().(*api.QueueInfo)
-
 		if ssn.Overused(queue) {
 			klog.V(3).Infof("Queue <%s> is overused, ignore it.", queue.Name)
 			continue
@@ -139,14 +154,14 @@ func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[a
 
 		klog.V(3).Infof("Try to allocate resource to Jobs in Queue <%s>", queue.Name)
 
-		jobs, found := jobsMap[queue.UID]
-		if !found || jobs.Empty() {
+		jobs := jobsQueue.Jobs(queue.UID)
+		if jobs == nil || jobs.Empty() {
 			klog.V(4).Infof("Can not find jobs for queue %s.", queue.Name)
 			continue
 		}
 
 		job := jobs.Pop().(*api.JobInfo)
-		if _, found = pendingTasks[job.UID]; !found {
+		if _, found := pendingTasks[job.UID]; !found {
 			tasks := util.NewPriorityQueue(ssn.TaskOrderFn)
 			for _, task := range job.TaskStatusIndex[api.Pending] {
 				// Skip tasks whose pod are scheduling gated
@@ -169,7 +184,7 @@ func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[a
 
 		if tasks.Empty() {
 			// put queue back again and try other jobs in this queue
-			queues.Push(queue)
+			jobsQueue.PushQueue(queue)
 			continue
 		}
 
@@ -180,14 +195,38 @@ func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[a
 
 		// Put back the queue to priority queue after job's resource allocating finished,
 		// To ensure that the priority of the queue is calculated based on the latest resource allocation situation.
-		queues.Push(queue)
+		jobsQueue.PushQueue(queue)
 	}
 }
 
 func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *api.JobInfo, jobs *util.PriorityQueue, queue *api.QueueInfo, allNodes []*api.NodeInfo) {
 	ssn := alloc.session
+	if job.CandidateNodes != nil {
+		allNodes = candidateNodes(job, allNodes)
+		if len(allNodes) == 0 {
+			job.JobFitErrors = fmt.Sprintf("none of the candidate nodes %v of the job is in the cluster", job.CandidateNodes.List())
+			klog.V(3).Infof("Job <%s/%s>: %s", job.Namespace, job.Name, job.JobFitErrors)
+			return
+		}
+	}
 	stmt := framework.NewStatement(ssn)
 	ph := util.NewPredicateHelper()
+	recorder := explain.Default()
+	batchNodeOrderFn, nodeOrderMapFn, nodeOrderReduceFn := ssn.BatchNodeOrderFn, ssn.NodeOrderMapFn, ssn.NodeOrderReduceFn
+	// the plugin scores of the nodes scored for the current task, for its score breakdown
+	var pluginScores *util.PluginScores
+	breakdown := util.ScoreBreakdownVerbosity() > 0
+	if recorder.Enabled() || breakdown {
+		recordScores := func(task *api.TaskInfo, node string, scores map[string]float64) {
+			recorder.RecordScores(job, task, node, scores)
+			if pluginScores != nil {
+				pluginScores.Record(node, scores)
+			}
+		}
+		batchNodeOrderFn = ssn.BatchNodeOrderFnWithScores(recordScores)
+		nodeOrderMapFn = ssn.NodeOrderMapFnWithScores(recordScores)
+		nodeOrderReduceFn = ssn.NodeOrderReduceFnWithScores(recordScores)
+	}
 
 	for !tasks.Empty() {
 		task := tasks.Pop().(*api.TaskInfo)
@@ -212,12 +251,15 @@ func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *a
 				fitErrors.SetNodeError(ni.Name, err)
 			}
 			job.NodesFitErrors[task.UID] = fitErrors
+			recorder.RecordDecision(job, task, "", fmt.Sprintf("prepredicate failed: %v", err))
 			break
 		}
 
 		predicateNodes, fitErrors := ph.PredicateNodes(task, allNodes, alloc.predicate, alloc.enablePredicateErrorCache)
+		recorder.RecordFiltered(job, task, fitErrors)
 		if len(predicateNodes) == 0 {
 			job.NodesFitErrors[task.UID] = fitErrors
+			recorder.RecordDecision(job, task, "", fmt.Sprintf("no node passed the predicates: %v", fitErrors.Error()))
 			// Assume that all left tasks are allocatable, but can not meet gang-scheduling min member,
 			// so we should break from continuously allocating.
 			// otherwise, should continue to find other allocatable task
@@ -261,13 +303,23 @@ func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *a
 				klog.V(5).Infof("Task: %v, no matching node is found in the candidateNodes（index: %d） list.", task.Name, index)
 			case len(nodes) == 1: // If only one node after predicate, just use it.
 				bestNode = nodes[0]
+				if breakdown {
+					task.ScoreBreakdown = fmt.Sprintf("node %s was the only candidate, not scored", bestNode.Name)
+				}
 			case len(nodes) > 1: // If more than one node after predicate, using "the best" one
-				nodeScores := util.PrioritizeNodes(task, nodes, ssn.BatchNodeOrderFn, ssn.NodeOrderMapFn, ssn.NodeOrderReduceFn)
+				if breakdown {
+					pluginScores = util.NewPluginScores()
+				}
+				nodeScores := util.PrioritizeNodes(task, nodes, batchNodeOrderFn, nodeOrderMapFn, nodeOrderReduceFn)
 
 				bestNode = ssn.BestNodeFn(task, nodeScores)
 				if bestNode == nil {
 					bestNode = util.SelectBestNode(nodeScores)
 				}
+				if pluginScores != nil && bestNode != nil {
+					task.ScoreBreakdown = pluginScores.Breakdown(nodeScores, bestNode.Name)
+					pluginScores = nil
+				}
 			}
 
 			// If a proper node is found in idleCandidateNodes, skip futureIdleCandidateNodes and directly return the node information.
@@ -282,7 +334,9 @@ func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *a
 			if err := stmt.Allocate(task, bestNode); err != nil {
 				klog.Errorf("Failed to bind Task %v on %v in Session %v, err: %v",
 					task.UID, bestNode.Name, ssn.UID, err)
+				recorder.RecordDecision(job, task, "", fmt.Sprintf("failed to allocate on node %s: %v", bestNode.Name, err))
 			} else {
+				recorder.RecordDecision(job, task, bestNode.Name, "allocated on the best scored node with idle resources")
 				metrics.UpdateE2eSchedulingDurationByJob(job.Name, string(job.Queue), job.Namespace, metrics.Duration(job.CreationTimestamp.Time))
 				metrics.UpdateE2eSchedulingLastTimeByJob(job.Name, string(job.Queue), job.Namespace, time.Now())
 			}
@@ -297,7 +351,9 @@ func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *a
 				if err := stmt.Pipeline(task, bestNode.Name, false); err != nil {
 					klog.Errorf("Failed to pipeline Task %v on %v in Session %v for %v.",
 						task.UID, bestNode.Name, ssn.UID, err)
+					recorder.RecordDecision(job, task, "", fmt.Sprintf("failed to pipeline on node %s: %v", bestNode.Name, err))
 				} else {
+					recorder.RecordDecision(job, task, bestNode.Name, "pipelined on the best scored node with releasing resources")
 					metrics.UpdateE2eSchedulingDurationByJob(job.Name, string(job.Queue), job.Namespace, metrics.Duration(job.CreationTimestamp.Time))
 					metrics.UpdateE2eSchedulingLastTimeByJob(job.Name, string(job.Queue), job.Namespace, time.Now())
 				}
@@ -312,11 +368,27 @@ func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *a
 
 	if ssn.JobReady(job) {
 		stmt.Commit()
+		recorder.RecordJob(job, "committed, the job is ready")
 	} else {
 		if !ssn.JobPipelined(job) {
 			stmt.Discard()
+			recorder.RecordJob(job, "discarded, the job is neither ready nor pipelined with the tasks placed")
+		} else {
+			recorder.RecordJob(job, "kept, the job is pipelined")
+		}
+	}
+}
+
+// candidateNodes restricts the nodes to the candidate nodes of the job, so that the fit errors of
+// its tasks only tell why the candidate nodes lack capacity.
+func candidateNodes(job *api.JobInfo, nodes []*api.NodeInfo) []*api.NodeInfo {
+	candidates := make([]*api.NodeInfo, 0, job.CandidateNodes.Len())
+	for _, node := range nodes {
+		if job.IsCandidateNode(node.Name) {
+			candidates = append(candidates, node)
 		}
 	}
+	return candidates
 }
 
 func (alloc *Action) predicate(task *api.TaskInfo, node *api.NodeInfo) error {
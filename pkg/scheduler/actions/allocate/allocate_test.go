@@ -32,6 +32,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
+	k8sframework "k8s.io/kubernetes/pkg/scheduler/framework"
 
 	schedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	"volcano.sh/volcano/cmd/scheduler/app/options"
@@ -268,6 +269,268 @@ func TestAllocate(t *testing.T) {
 	}
 }
 
+// preferNodePlugin scores a node by its batch node order function, and the nodes labeled
+// preferred by its node map function.
+type preferNodePlugin struct {
+	node string
+}
+
+func (pp *preferNodePlugin) Name() string { return "prefer-node" }
+
+func (pp *preferNodePlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddBatchNodeOrderFn(pp.Name(), func(task *api.TaskInfo, nodes []*api.NodeInfo) (map[string]float64, error) {
+		return map[string]float64{pp.node: 100}, nil
+	})
+	ssn.AddNodeMapFn(pp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		if node.Node.Labels["preferred"] == "true" {
+			return 200, nil
+		}
+		return 0, nil
+	})
+	ssn.AddNodeReduceFn(pp.Name(), func(task *api.TaskInfo, scores k8sframework.NodeScoreList) error {
+		return nil
+	})
+}
+
+func (pp *preferNodePlugin) OnSessionClose(ssn *framework.Session) {}
+
+func TestAllocateFastPath(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		drf.PluginName:        drf.New,
+		proportion.PluginName: proportion.New,
+		predicates.PluginName: predicates.New,
+		nodeorder.PluginName:  nodeorder.New,
+		gang.PluginName:       gang.New,
+		"prefer-node": func(framework.Arguments) framework.Plugin {
+			return &preferNodePlugin{node: "n2"}
+		},
+	}
+	tests := []struct {
+		uthelper.TestCommonStruct
+		// expected are the nodes the tasks are allocated to by the fast path, "" if not allocated
+		expected map[string]string
+	}{
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name: "single pod jobs are allocated by the fast path",
+				PodGroups: []*schedulingv1.PodGroup{
+					util.BuildPodGroup("pg1", "c1", "c1", 1, nil, schedulingv1.PodGroupInqueue),
+					util.BuildPodGroup("pg2", "c1", "c1", 1, nil, schedulingv1.PodGroupInqueue),
+				},
+				Pods: []*v1.Pod{
+					util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), map[string]string{"nodeRole": "worker"}),
+					util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), map[string]string{"nodeRole": "master"}),
+				},
+				Nodes: []*v1.Node{
+					util.BuildNode("n1", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"nodeRole": "master"}),
+					util.BuildNode("n2", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"nodeRole": "worker"}),
+				},
+				Queues: []*schedulingv1.Queue{
+					util.BuildQueue("c1", 1, nil),
+				},
+			},
+			expected: map[string]string{
+				"c1/p1": "n2",
+				"c1/p2": "n1",
+			},
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name: "the batch node order scores of the plugins pick the node",
+				PodGroups: []*schedulingv1.PodGroup{
+					util.BuildPodGroup("pg1", "c1", "c1", 1, nil, schedulingv1.PodGroupInqueue),
+				},
+				Pods: []*v1.Pod{
+					util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				},
+				Nodes: []*v1.Node{
+					util.BuildNode("n1", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+					util.BuildNode("n2", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+				},
+				Queues: []*schedulingv1.Queue{
+					util.BuildQueue("c1", 1, nil),
+				},
+			},
+			expected: map[string]string{
+				"c1/p1": "n2",
+			},
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name: "the node map scores of the plugins pick the node",
+				PodGroups: []*schedulingv1.PodGroup{
+					util.BuildPodGroup("pg1", "c1", "c1", 1, nil, schedulingv1.PodGroupInqueue),
+				},
+				Pods: []*v1.Pod{
+					util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				},
+				Nodes: []*v1.Node{
+					util.BuildNode("n1", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"preferred": "true"}),
+					util.BuildNode("n2", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+				},
+				Queues: []*schedulingv1.Queue{
+					util.BuildQueue("c1", 1, nil),
+				},
+			},
+			expected: map[string]string{
+				"c1/p1": "n1",
+			},
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name: "the jobs are served in the queue order",
+				PodGroups: []*schedulingv1.PodGroup{
+					util.BuildPodGroup("pg0", "c1", "c3", 1, nil, schedulingv1.PodGroupRunning),
+					util.BuildPodGroup("pg1", "c1", "c1", 1, nil, schedulingv1.PodGroupInqueue),
+					util.BuildPodGroup("pg2", "c1", "c2", 1, nil, schedulingv1.PodGroupInqueue),
+				},
+				// the idle resource is left for one of the jobs
+				Pods: []*v1.Pod{
+					util.BuildPod("c1", "p0", "n1", v1.PodRunning, api.BuildResourceList("3", "1G"), "pg0", make(map[string]string), make(map[string]string)),
+					util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+					util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+				},
+				Nodes: []*v1.Node{
+					util.BuildNode("n1", api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+				},
+				Queues: []*schedulingv1.Queue{
+					util.BuildQueueWithPriorityAndResourcesQuantity("c1", 1, nil, nil),
+					util.BuildQueueWithPriorityAndResourcesQuantity("c2", 10, nil, nil),
+					util.BuildQueueWithPriorityAndResourcesQuantity("c3", 1, nil, nil),
+				},
+			},
+			expected: map[string]string{
+				"c1/p0": "n1",
+				"c1/p1": "",
+				"c1/p2": "n1",
+			},
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name: "gang jobs and tasks not fitting the idle resource are left to the regular allocation",
+				PodGroups: []*schedulingv1.PodGroup{
+					util.BuildPodGroup("pg1", "c1", "c1", 2, nil, schedulingv1.PodGroupInqueue),
+					util.BuildPodGroup("pg2", "c1", "c1", 1, nil, schedulingv1.PodGroupInqueue),
+				},
+				Pods: []*v1.Pod{
+					util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+					util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+					util.BuildPod("c1", "p3", "", v1.PodPending, api.BuildResourceList("2", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+				},
+				Nodes: []*v1.Node{
+					util.BuildNode("n1", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+					util.BuildNode("n2", api.BuildResourceList("1", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+				},
+				Queues: []*schedulingv1.Queue{
+					util.BuildQueue("c1", 1, nil),
+				},
+			},
+			expected: map[string]string{
+				"c1/p1": "",
+				"c1/p2": "",
+				"c1/p3": "",
+			},
+		},
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:                gang.PluginName,
+					EnabledJobOrder:     &trueValue,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+				{
+					Name:               drf.PluginName,
+					EnabledPreemptable: &trueValue,
+					EnabledJobOrder:    &trueValue,
+				},
+				{
+					Name:               proportion.PluginName,
+					EnabledQueueOrder:  &trueValue,
+					EnabledReclaimable: &trueValue,
+					EnabledAllocatable: &trueValue,
+				},
+				{
+					Name:             predicates.PluginName,
+					EnabledPredicate: &trueValue,
+				},
+				{
+					Name:             nodeorder.PluginName,
+					EnabledNodeOrder: &trueValue,
+				},
+				{
+					Name:             "prefer-node",
+					EnabledNodeOrder: &trueValue,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.Plugins = plugins
+			action := New()
+			ssn := test.RegisterSession(tiers, []conf.Configuration{{Name: action.Name(),
+				Arguments: map[string]interface{}{conf.EnableFastPathKey: true}}})
+			defer test.Close()
+			action.parseArguments(ssn)
+			assert.True(t, action.enableFastPath)
+
+			action.session = ssn
+			action.allocateSinglePodJobs()
+			allocated := map[string]string{}
+			for _, job := range ssn.Jobs {
+				for _, task := range job.Tasks {
+					nodeName := ""
+					if api.AllocatedStatus(task.Status) {
+						nodeName = task.NodeName
+					}
+					allocated[fmt.Sprintf("%s/%s", task.Namespace, task.Name)] = nodeName
+				}
+			}
+			assert.Equal(t, test.expected, allocated)
+		})
+	}
+}
+
+func TestAllocateJobsQueuePerProfile(t *testing.T) {
+	framework.RegisterPluginBuilder(gang.PluginName, gang.New)
+	defer framework.CleanupPluginBuilders()
+	schedulerCache := cache.NewCustomMockSchedulerCache("profiles", util.NewFakeBinder(0), util.NewFakeEvictor(0), &util.FakeStatusUpdater{}, nil, nil, nil)
+	for _, queue := range []string{"c1", "c2"} {
+		schedulerCache.AddQueueV1beta1(util.BuildQueue(queue, 1, nil))
+		schedulerCache.AddPodGroupV1beta1(util.BuildPodGroup("pg-"+queue, "c1", queue, 1, nil, schedulingv1.PodGroupInqueue))
+		// no node is left for the jobs, they are kept pending across the sessions
+		schedulerCache.AddPod(util.BuildPod("c1", "p-"+queue, "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg-"+queue, make(map[string]string), make(map[string]string)))
+	}
+	tiers := []conf.Tier{{Plugins: []conf.PluginOption{{Name: gang.PluginName}}}}
+
+	action := New()
+	// the top level configuration serves c1 and the profile p1 serves c2, both run the same action
+	var kept []*util.JobsQueue
+	for _, profile := range []struct {
+		name  string
+		queue api.QueueID
+	}{{"", "c1"}, {"p1", "c2"}, {"", "c1"}} {
+		queue := profile.queue
+		ssn := framework.OpenSessionWithJobFilter(schedulerCache, profile.name, tiers, nil, func(job *api.JobInfo) bool {
+			return job.Queue == queue
+		})
+		action.Execute(ssn)
+		framework.CloseSession(ssn)
+		kept = append(kept, action.jobsQueues[profile.name])
+	}
+
+	if kept[0] == nil || kept[0] == kept[1] || kept[0] != kept[2] {
+		t.Errorf("expected each profile to keep its own jobs queue across its sessions")
+	}
+}
+
 func TestFareShareAllocate(t *testing.T) {
 	plugins := map[string]framework.PluginBuilder{
 		drf.PluginName:        drf.New,
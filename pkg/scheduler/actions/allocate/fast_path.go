@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allocate
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+const defaultFastPathNodes = 10
+
+// singlePodTask returns the pending task of a job made of a single pod without gang constraints,
+// nil if the job is not such a job.
+func singlePodTask(job *api.JobInfo) *api.TaskInfo {
+	if len(job.Tasks) != 1 || job.MinAvailable > 1 || job.IsPending() {
+		return nil
+	}
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		if task.SchGated || task.Resreq.IsEmpty() {
+			return nil
+		}
+		return task
+	}
+	return nil
+}
+
+// allocateSinglePodJobs allocates the jobs made of a single pod before the regular allocation,
+// so that they are not held back behind large jobs. The jobs are served in the queue and job order
+// of the regular allocation. For each task, only the first fastPathNodes nodes with enough idle
+// resource that pass the predicates are scored, and the task is only allocated to idle resource;
+// tasks not placed here are left to the regular allocation.
+func (alloc *Action) allocateSinglePodJobs() {
+	ssn := alloc.session
+	jobsQueue := ssn.UpdateJobsQueue(nil, func(job *api.JobInfo) bool {
+		if singlePodTask(job) == nil || ssn.BackedOff(job) {
+			return false
+		}
+		vr := ssn.JobValid(job)
+		return vr == nil || vr.Pass
+	})
+	if jobsQueue.Empty() {
+		return
+	}
+	klog.V(3).Infof("Try to allocate the single pod jobs of %d Queues by the fast path", jobsQueue.Len())
+
+	for {
+		queue := jobsQueue.PopQueue()
+		if queue == nil {
+			break
+		}
+		if ssn.Overused(queue) {
+			continue
+		}
+		jobs := jobsQueue.Jobs(queue.UID)
+		if jobs == nil || jobs.Empty() {
+			continue
+		}
+		alloc.allocateSinglePodJob(jobs.Pop().(*api.JobInfo), queue)
+		// the queue is ordered again by its share updated by the allocation
+		jobsQueue.PushQueue(queue)
+	}
+}
+
+// allocateSinglePodJob allocates the task of the single pod job to the best fast path node.
+func (alloc *Action) allocateSinglePodJob(job *api.JobInfo, queue *api.QueueInfo) {
+	ssn := alloc.session
+	task := singlePodTask(job)
+	if !ssn.Allocatable(queue, task) {
+		return
+	}
+	if err := ssn.PrePredicateFn(task); err != nil {
+		return
+	}
+
+	node := alloc.fastPathNode(job, task)
+	if node == nil {
+		return
+	}
+
+	stmt := framework.NewStatement(ssn)
+	if err := stmt.Allocate(task, node); err != nil {
+		klog.Errorf("Failed to allocate Task %v on %v by the fast path in Session %v, err: %v",
+			task.UID, node.Name, ssn.UID, err)
+		stmt.Discard()
+		return
+	}
+	if !ssn.JobReady(job) {
+		stmt.Discard()
+		return
+	}
+	stmt.Commit()
+	klog.V(3).Infof("Allocated Task <%v/%v> to node <%v> by the fast path", task.Namespace, task.Name, node.Name)
+	metrics.UpdateE2eSchedulingDurationByJob(job.Name, string(job.Queue), job.Namespace, metrics.Duration(job.CreationTimestamp.Time))
+	metrics.UpdateE2eSchedulingLastTimeByJob(job.Name, string(job.Queue), job.Namespace, time.Now())
+}
+
+// fastPathNode returns the best node among the first fastPathNodes feasible nodes of the job,
+// starting from where the previous lookup stopped. The nodes are scored by the same node order,
+// batch node order and best node functions of the plugins as in the regular allocation.
+func (alloc *Action) fastPathNode(job *api.JobInfo, task *api.TaskInfo) *api.NodeInfo {
+	ssn := alloc.session
+	nodes := ssn.NodeList
+	if job.CandidateNodes != nil {
+		nodes = candidateNodes(job, nodes)
+	}
+	var candidates []*api.NodeInfo
+	for i := 0; i < len(nodes) && len(candidates) < alloc.fastPathNodes; i++ {
+		node := nodes[(alloc.fastPathOffset+i)%len(nodes)]
+		if !task.InitResreq.LessEqual(node.Idle, api.Zero) {
+			continue
+		}
+		if err := ssn.PredicateForAllocateAction(task, node); err != nil {
+			continue
+		}
+		candidates = append(candidates, node)
+	}
+	if len(nodes) != 0 {
+		alloc.fastPathOffset = (alloc.fastPathOffset + 1) % len(nodes)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
+		return candidates[0]
+	}
+	nodeScores := util.PrioritizeNodes(task, candidates, ssn.BatchNodeOrderFn, ssn.NodeOrderMapFn, ssn.NodeOrderReduceFn)
+	if bestNode := ssn.BestNodeFn(task, nodeScores); bestNode != nil {
+		return bestNode
+	}
+	return util.SelectBestNode(nodeScores)
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package backfill
 
 import (
+	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
 	"volcano.sh/volcano/pkg/scheduler/api"
@@ -28,8 +30,25 @@ import (
 	"volcano.sh/volcano/pkg/scheduler/util"
 )
 
+const (
+	// defaultMinTimeLimit is the lowest time limit the auto-tuning may set if not configured
+	defaultMinTimeLimit = 10 * time.Millisecond
+	// defaultMaxTimeLimit is the highest time limit the auto-tuning may set if not configured
+	defaultMaxTimeLimit = time.Second
+)
+
 type Action struct {
 	enablePredicateErrorCache bool
+
+	// timeLimit is how long backfill places tasks in a session, 0 if unlimited
+	timeLimit time.Duration
+	// autoTune tunes the time limit between minTimeLimit and maxTimeLimit across the sessions: it
+	// is halved when the nodes backfilled in the previous session delay head-of-line jobs, raised
+	// by a tenth of maxTimeLimit otherwise
+	autoTune                   bool
+	minTimeLimit, maxTimeLimit time.Duration
+	// backfilled are the nodes tasks were placed on in the previous session
+	backfilled sets.Set[string]
 }
 
 func New() *Action {
@@ -47,6 +66,107 @@ func (backfill *Action) Initialize() {}
 func (backfill *Action) parseArguments(ssn *framework.Session) {
 	arguments := framework.GetArgOfActionFromConf(ssn.Configurations, backfill.Name())
 	arguments.GetBool(&backfill.enablePredicateErrorCache, conf.EnablePredicateErrCacheKey)
+
+	backfill.autoTune = false
+	arguments.GetBool(&backfill.autoTune, conf.BackfillAutoTuneKey)
+	if !backfill.autoTune {
+		backfill.timeLimit = 0
+		getDuration(arguments, &backfill.timeLimit, conf.BackfillTimeLimitKey)
+		return
+	}
+
+	backfill.minTimeLimit, backfill.maxTimeLimit = defaultMinTimeLimit, defaultMaxTimeLimit
+	getDuration(arguments, &backfill.minTimeLimit, conf.BackfillMinTimeLimitKey)
+	getDuration(arguments, &backfill.maxTimeLimit, conf.BackfillMaxTimeLimitKey)
+	if backfill.minTimeLimit <= 0 {
+		backfill.minTimeLimit = defaultMinTimeLimit
+	}
+	if backfill.maxTimeLimit < backfill.minTimeLimit {
+		backfill.maxTimeLimit = backfill.minTimeLimit
+	}
+	// the tuning starts from the highest time limit
+	if backfill.timeLimit == 0 || backfill.timeLimit > backfill.maxTimeLimit {
+		backfill.timeLimit = backfill.maxTimeLimit
+	}
+	if backfill.timeLimit < backfill.minTimeLimit {
+		backfill.timeLimit = backfill.minTimeLimit
+	}
+}
+
+// getDuration sets the duration of the key if it is a valid non negative duration.
+func getDuration(arguments framework.Arguments, ptr *time.Duration, key string) {
+	value, ok := arguments[key].(string)
+	if !ok {
+		return
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		*ptr = d
+	} else {
+		klog.Warningf("Invalid %s <%s>, using <%v>", key, value, *ptr)
+	}
+}
+
+// tune halves the time limit when backfill delayed head-of-line jobs, and raises it by a tenth of
+// the highest time limit otherwise.
+func (backfill *Action) tune(delayed int) {
+	metrics.RegisterBackfillDelayedJobs(delayed)
+	if delayed > 0 {
+		backfill.timeLimit = max(backfill.minTimeLimit, backfill.timeLimit/2)
+	} else {
+		backfill.timeLimit = min(backfill.maxTimeLimit, backfill.timeLimit+backfill.maxTimeLimit/10)
+	}
+}
+
+// delayedJobs returns the number of queues whose head-of-line job, the first in order of the
+// jobs which are not ready yet, failed to fit on the nodes backfilled in the previous session.
+func (backfill *Action) delayedJobs(ssn *framework.Session) int {
+	if len(backfill.backfilled) == 0 {
+		return 0
+	}
+
+	heads := map[api.QueueID]*api.JobInfo{}
+	for _, job := range ssn.Jobs {
+		if job.IsPending() || len(job.TaskStatusIndex[api.Pending]) == 0 || ssn.JobReady(job) {
+			continue
+		}
+		if head, found := heads[job.Queue]; !found || ssn.JobOrderFn(job, head) {
+			heads[job.Queue] = job
+		}
+	}
+
+	delayed := 0
+	for _, job := range heads {
+		if backfill.blocks(job) {
+			klog.V(4).Infof("Job <%s/%s> of Queue <%s> does not fit on the nodes backfilled in the previous session",
+				job.Namespace, job.Name, job.Queue)
+			delayed++
+		}
+	}
+	return delayed
+}
+
+// blocks returns whether some task of the job lacked resources on a backfilled node, the other
+// fit errors, like a mismatching selector, are not caused by the tasks backfill placed there.
+func (backfill *Action) blocks(job *api.JobInfo) bool {
+	for _, fitErrors := range job.NodesFitErrors {
+		for node, fitError := range fitErrors.NodeErrors() {
+			if backfill.backfilled.Has(node) && resourceFitFailed(fitError) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resourceFitFailed returns whether the node lacked the resources or the pod slots of the task.
+func resourceFitFailed(fitError *api.FitError) bool {
+	for _, reason := range fitError.Reasons() {
+		if strings.HasPrefix(reason, "Insufficient ") ||
+			reason == api.NodeResourceFitFailed || reason == api.NodePodNumberExceeded {
+			return true
+		}
+	}
+	return false
 }
 
 func (backfill *Action) Execute(ssn *framework.Session) {
@@ -54,12 +174,34 @@ func (backfill *Action) Execute(ssn *framework.Session) {
 	defer klog.V(5).Infof("Leaving Backfill ...")
 
 	backfill.parseArguments(ssn)
+	if backfill.autoTune {
+		backfill.tune(backfill.delayedJobs(ssn))
+	}
+	metrics.UpdateBackfillTimeLimit(backfill.timeLimit)
 
-	predicateFunc := ssn.PredicateForAllocateAction
+	// the nodes whose tasks were evicted earlier in the session are left to the preemptors
+	evictingNodes := ssn.JournaledNodes("", framework.Evict)
+	predicateFunc := func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if _, found := evictingNodes[node.Name]; found {
+			return api.NewFitError(task, node, "node has tasks evicted in this session")
+		}
+		return ssn.PredicateForAllocateAction(task, node)
+	}
 
 	// TODO (k82cn): When backfill, it's also need to balance between Queues.
 	pendingTasks := backfill.pickUpPendingTasks(ssn)
+	backfilled := sets.New[string]()
+	placed := 0
+	deadline := time.Now().Add(backfill.timeLimit)
+	defer func() {
+		backfill.backfilled = backfilled
+		metrics.RegisterBackfillTasks(placed)
+	}()
 	for _, task := range pendingTasks {
+		if backfill.timeLimit > 0 && time.Now().After(deadline) {
+			klog.V(3).Infof("Backfill placed %d tasks in its time limit <%v>", placed, backfill.timeLimit)
+			break
+		}
 		job := ssn.Jobs[task.Job]
 		ph := util.NewPredicateHelper()
 		allocated := false
@@ -99,6 +241,8 @@ func (backfill *Action) Execute(ssn *framework.Session) {
 		metrics.UpdateE2eSchedulingDurationByJob(job.Name, string(job.Queue), job.Namespace, metrics.Duration(job.CreationTimestamp.Time))
 		metrics.UpdateE2eSchedulingLastTimeByJob(job.Name, string(job.Queue), job.Namespace, time.Now())
 		allocated = true
+		placed++
+		backfilled.Insert(node.Name)
 
 		if !allocated {
 			job.NodesFitErrors[task.UID] = fe
@@ -124,6 +268,12 @@ func (backfill *Action) pickUpPendingTasks(ssn *framework.Session) []*api.TaskIn
 			continue
 		}
 
+		if ssn.BackedOff(job) {
+			klog.V(4).Infof("Job <%s/%s> Queue <%s> skip backfill, reason: backed off after failing to be scheduled",
+				job.Namespace, job.Name, job.Queue)
+			continue
+		}
+
 		queue, found := ssn.Queues[job.Queue]
 		if !found {
 			continue
@@ -149,8 +299,8 @@ func (backfill *Action) pickUpPendingTasks(ssn *framework.Session) []*api.TaskIn
 				continue
 			}
 
-			stmt := framework.NewStatement(ssn)
-			err := stmt.UnPipeline(task)
+			// the pipelines of the previous actions are rolled back in the journal too
+			err := ssn.UnPipeline(task)
 			if err != nil {
 				klog.Errorf("Failed to unpipeline task: %s", err.Error())
 				continue
@@ -1,13 +1,16 @@
 package backfill
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	schedulingapi "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 
@@ -160,3 +163,65 @@ func TestPickUpPendingTasks(t *testing.T) {
 		}
 	}
 }
+
+func TestParseTimeLimit(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		arguments map[string]interface{}
+		expected  time.Duration
+	}{
+		{name: "unlimited", expected: 0},
+		{name: "fixed", arguments: map[string]interface{}{conf.BackfillTimeLimitKey: "200ms"}, expected: 200 * time.Millisecond},
+		{name: "invalid", arguments: map[string]interface{}{conf.BackfillTimeLimitKey: "soon"}, expected: 0},
+		{name: "tuned from the highest", arguments: map[string]interface{}{
+			conf.BackfillAutoTuneKey: true, conf.BackfillMaxTimeLimitKey: "300ms"}, expected: 300 * time.Millisecond},
+		{name: "tuned within the lowest", arguments: map[string]interface{}{
+			conf.BackfillAutoTuneKey: true, conf.BackfillMinTimeLimitKey: "2s"}, expected: 2 * time.Second},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			backfill := New()
+			backfill.parseArguments(&framework.Session{Configurations: []conf.Configuration{
+				{Name: backfill.Name(), Arguments: test.arguments},
+			}})
+			assert.Equal(t, test.expected, backfill.timeLimit)
+		})
+	}
+}
+
+func TestTune(t *testing.T) {
+	backfill := &Action{timeLimit: 950 * time.Millisecond, autoTune: true,
+		minTimeLimit: 200 * time.Millisecond, maxTimeLimit: time.Second}
+	for i, step := range []struct {
+		delayed  int
+		expected time.Duration
+	}{
+		{delayed: 0, expected: time.Second},
+		{delayed: 0, expected: time.Second},
+		{delayed: 1, expected: 500 * time.Millisecond},
+		{delayed: 3, expected: 250 * time.Millisecond},
+		{delayed: 1, expected: 200 * time.Millisecond},
+		{delayed: 0, expected: 300 * time.Millisecond},
+	} {
+		backfill.tune(step.delayed)
+		assert.Equal(t, step.expected, backfill.timeLimit, "step %d", i)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	backfill := &Action{backfilled: sets.New("n1")}
+	job := api.NewJobInfo("job1")
+
+	fitErrors := api.NewFitErrors()
+	fitErrors.SetNodeError("n2", fmt.Errorf("Insufficient cpu"))
+	job.NodesFitErrors["task1"] = fitErrors
+	assert.False(t, backfill.blocks(job))
+
+	fitErrors.SetNodeError("n1", fmt.Errorf("node(s) didn't match Pod's node affinity/selector"))
+	assert.False(t, backfill.blocks(job))
+
+	fitErrors.SetNodeError("n1", fmt.Errorf(api.NodePodNumberExceeded))
+	assert.True(t, backfill.blocks(job))
+
+	fitErrors.SetNodeError("n1", fmt.Errorf("Insufficient memory"))
+	assert.True(t, backfill.blocks(job))
+}
@@ -45,6 +45,8 @@ func (enqueue *Action) Execute(ssn *framework.Session) {
 	klog.V(5).Infof("Enter Enqueue ...")
 	defer klog.V(5).Infof("Leaving Enqueue ...")
 
+	drainMaintenanceWindows(ssn)
+
 	queues := util.NewPriorityQueue(ssn.QueueOrderFn)
 	queueSet := sets.NewString()
 	jobsMap := map[api.QueueID]*util.PriorityQueue{}
@@ -68,6 +70,11 @@ func (enqueue *Action) Execute(ssn *framework.Session) {
 		}
 
 		if job.IsPending() {
+			if window := maintenanceWindow(ssn, ssn.Queues[job.Queue].Name); window != nil {
+				klog.V(3).Infof("Queue <%s> of Job <%s/%s> is in maintenance window <%s>, skip it",
+					job.Queue, job.Namespace, job.Name, window.Name)
+				continue
+			}
 			if _, found := jobsMap[job.Queue]; !found {
 				jobsMap[job.Queue] = util.NewPriorityQueue(ssn.JobOrderFn)
 			}
@@ -104,3 +111,41 @@ func (enqueue *Action) Execute(ssn *framework.Session) {
 }
 
 func (enqueue *Action) UnInitialize() {}
+
+// maintenanceWindow returns the maintenance window in progress covering the queue, nil if none.
+func maintenanceWindow(ssn *framework.Session, queue string) *api.MaintenanceWindow {
+	for _, window := range ssn.MaintenanceWindows {
+		if window.Queues.Has(queue) {
+			return window
+		}
+	}
+	return nil
+}
+
+// drainMaintenanceWindows evicts the running tasks of the queues and on the nodes of the
+// draining maintenance windows in progress.
+func drainMaintenanceWindows(ssn *framework.Session) {
+	for _, window := range ssn.MaintenanceWindows {
+		if !window.Drain {
+			continue
+		}
+		for _, job := range ssn.Jobs {
+			queue, found := ssn.Queues[job.Queue]
+			if !found {
+				continue
+			}
+			for _, task := range job.Tasks {
+				if task.Status != api.Running && task.Status != api.Bound && task.Status != api.Binding {
+					continue
+				}
+				if !window.CoversTask(queue.Name, task) {
+					continue
+				}
+				if err := ssn.Evict(task, "maintenance window "+window.Name); err != nil {
+					klog.Errorf("Failed to drain Task <%s/%s> for maintenance window <%s>: %v",
+						task.Namespace, task.Name, window.Name, err)
+				}
+			}
+		}
+	}
+}
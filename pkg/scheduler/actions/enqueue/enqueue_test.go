@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"volcano.sh/apis/pkg/apis/scheduling"
 	schedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
@@ -105,6 +106,38 @@ func TestEnqueue(t *testing.T) {
 				"c1/pg1": scheduling.PodGroupPending,
 			},
 		},
+		{
+			Name: "podgroup without pods is enqueued by its MinResources",
+			PodGroups: []*schedulingv1.PodGroup{
+				util.BuildPodGroupWithMinResources("pg1", "c1", "c1", 2,
+					nil, api.BuildResourceList("2", "2G"), schedulingv1.PodGroupPending),
+			},
+			Nodes: []*v1.Node{
+				util.BuildNode("n1", api.BuildResourceList("4", "4G"), make(map[string]string)),
+			},
+			Queues: []*schedulingv1.Queue{
+				util.BuildQueue("c1", 1, api.BuildResourceList("4", "4G")),
+			},
+			ExpectStatus: map[api.JobID]scheduling.PodGroupPhase{
+				"c1/pg1": scheduling.PodGroupInqueue,
+			},
+		},
+		{
+			Name: "podgroup without pods stays pending when MinResources exceeds queue capability",
+			PodGroups: []*schedulingv1.PodGroup{
+				util.BuildPodGroupWithMinResources("pg1", "c1", "c1", 2,
+					nil, api.BuildResourceList("8", "8G"), schedulingv1.PodGroupPending),
+			},
+			Nodes: []*v1.Node{
+				util.BuildNode("n1", api.BuildResourceList("4", "4G"), make(map[string]string)),
+			},
+			Queues: []*schedulingv1.Queue{
+				util.BuildQueue("c1", 1, api.BuildResourceList("4", "4G")),
+			},
+			ExpectStatus: map[api.JobID]scheduling.PodGroupPhase{
+				"c1/pg1": scheduling.PodGroupPending,
+			},
+		},
 	}
 
 	trueValue := true
@@ -149,3 +182,79 @@ func TestEnqueue(t *testing.T) {
 		})
 	}
 }
+
+func TestEnqueueMaintenanceWindows(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		drf.PluginName:        drf.New,
+		proportion.PluginName: proportion.New,
+	}
+	if options.ServerOpts == nil {
+		options.Default()
+	}
+	test := uthelper.TestCommonStruct{
+		Name: "queue in a draining maintenance window",
+		PodGroups: []*schedulingv1.PodGroup{
+			util.BuildPodGroup("pg1", "c1", "q1", 0, nil, schedulingv1.PodGroupPending),
+			util.BuildPodGroup("pg2", "c1", "q2", 0, nil, schedulingv1.PodGroupPending),
+			util.BuildPodGroup("pg3", "c1", "q1", 0, nil, schedulingv1.PodGroupRunning),
+			util.BuildPodGroup("pg4", "c1", "q2", 0, nil, schedulingv1.PodGroupRunning),
+		},
+		Pods: []*v1.Pod{
+			util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			// running in the queue of the window
+			util.BuildPod("c1", "p3", "n2", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg3", make(map[string]string), make(map[string]string)),
+			// running on the node of the window
+			util.BuildPod("c1", "p4", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg4", make(map[string]string), make(map[string]string)),
+			// running out of the window
+			util.BuildPod("c1", "p5", "n2", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg4", make(map[string]string), make(map[string]string)),
+		},
+		Nodes: []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("4", "4G"), make(map[string]string)),
+			util.BuildNode("n2", api.BuildResourceList("4", "4G"), make(map[string]string)),
+		},
+		Queues: []*schedulingv1.Queue{
+			util.BuildQueue("q1", 1, api.BuildResourceList("4", "4G")),
+			util.BuildQueue("q2", 1, api.BuildResourceList("4", "4G")),
+		},
+		ExpectStatus: map[api.JobID]scheduling.PodGroupPhase{
+			"c1/pg1": scheduling.PodGroupPending,
+			"c1/pg2": scheduling.PodGroupInqueue,
+		},
+		ExpectEvicted:  []string{"c1/p3", "c1/p4"},
+		ExpectEvictNum: 2,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               drf.PluginName,
+					EnabledJobOrder:    &trueValue,
+					EnabledJobEnqueued: &trueValue,
+				},
+				{
+					Name:               proportion.PluginName,
+					EnabledQueueOrder:  &trueValue,
+					EnabledJobEnqueued: &trueValue,
+				},
+			},
+		},
+	}
+	test.Plugins = plugins
+	ssn := test.RegisterSession(tiers, nil)
+	defer test.Close()
+	ssn.MaintenanceWindows = []*api.MaintenanceWindow{
+		{
+			Name:   "mw1",
+			Queues: sets.New("q1"),
+			Nodes:  sets.New("n1"),
+			Drain:  true,
+		},
+	}
+	test.Run([]framework.Action{New()})
+	if err := test.CheckAll(0); err != nil {
+		t.Fatal(err)
+	}
+}
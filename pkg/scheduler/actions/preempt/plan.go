@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preempt
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// Plan is the set of victims to evict on a node for a preemptor to fit on it.
+type Plan struct {
+	Node    *api.NodeInfo
+	Victims []*api.TaskInfo
+}
+
+// PlanCostFn returns the cost of a plan, the cheaper plans are executed first.
+type PlanCostFn func(plan *Plan) float64
+
+var planCostFns = map[string]PlanCostFn{
+	"nodes":   touchedNodeCost,
+	"victims": victimCountCost,
+}
+
+// RegisterPlanCostFn registers a cost function under a name for it to be selected in the
+// preemptionPlanCost argument of preempt.
+func RegisterPlanCostFn(name string, fn PlanCostFn) {
+	planCostFns[name] = fn
+}
+
+// touchedNodeCost is 0 on the nodes where tasks are already evicted, so that the evictions are
+// concentrated on the fewest nodes rather than spread across the cluster.
+func touchedNodeCost(plan *Plan) float64 {
+	if len(plan.Victims) == 0 || !plan.Node.Releasing.IsEmpty() {
+		return 0
+	}
+	return 1
+}
+
+// victimCountCost is the number of victims of the plan.
+func victimCountCost(plan *Plan) float64 {
+	return float64(len(plan.Victims))
+}
+
+// parsePlanCostFns returns the cost functions named in the comma separated list, the unknown
+// ones are ignored.
+func parsePlanCostFns(names string) []PlanCostFn {
+	var fns []PlanCostFn
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); len(name) == 0 {
+			continue
+		}
+		fn, found := planCostFns[name]
+		if !found {
+			klog.Warningf("Unknown preemption plan cost function %q, ignore it", name)
+			continue
+		}
+		fns = append(fns, fn)
+	}
+	return fns
+}
+
+// buildPlan returns the victims popped in order until the preemptor fits on the node, nil if
+// it does not fit even with all of them evicted.
+func buildPlan(preemptor *api.TaskInfo, node *api.NodeInfo, victims []*api.TaskInfo) *Plan {
+	plan := &Plan{Node: node}
+	idle := node.FutureIdle()
+	for _, victim := range victims {
+		if preemptor.InitResreq.LessEqual(idle, api.Zero) {
+			return plan
+		}
+		plan.Victims = append(plan.Victims, victim)
+		idle.Add(victim.Resreq)
+	}
+	if preemptor.InitResreq.LessEqual(idle, api.Zero) {
+		return plan
+	}
+	return nil
+}
+
+// lessCost compares the plans by each cost function in turn.
+func lessCost(fns []PlanCostFn, l, r *Plan) bool {
+	for _, fn := range fns {
+		if lc, rc := fn(l), fn(r); lc != rc {
+			return lc < rc
+		}
+	}
+	return false
+}
+
+// sortPlans orders the plans by cost, keeping the order of the plans of equal costs.
+func sortPlans(fns []PlanCostFn, plans []*Plan) {
+	sort.SliceStable(plans, func(i, j int) bool {
+		return lessCost(fns, plans[i], plans[j])
+	})
+}
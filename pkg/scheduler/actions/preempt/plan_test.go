@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preempt
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func buildPlanNode(name, cpu string, tasks ...*api.TaskInfo) *api.NodeInfo {
+	node := api.NewNodeInfo(util.BuildNode(name, api.BuildResourceList(cpu, "16Gi", []api.ScalarResource{{Name: "pods", Value: "100"}}...), nil))
+	for _, task := range tasks {
+		node.AddTask(task)
+	}
+	return node
+}
+
+func buildPlanTask(name, node, cpu string) *api.TaskInfo {
+	return api.NewTaskInfo(util.BuildPod("c1", name, node, v1.PodRunning, api.BuildResourceList(cpu, "1Gi"), "pg1", nil, nil))
+}
+
+func TestBuildPlan(t *testing.T) {
+	preemptor := api.NewTaskInfo(util.BuildPod("c1", "preemptor", "", v1.PodPending, api.BuildResourceList("3", "1Gi"), "pg2", nil, nil))
+	small := []*api.TaskInfo{buildPlanTask("s1", "n1", "1"), buildPlanTask("s2", "n1", "1"), buildPlanTask("s3", "n1", "1"), buildPlanTask("s4", "n1", "1")}
+	node := buildPlanNode("n1", "4", small...)
+
+	plan := buildPlan(preemptor, node, small)
+	if plan == nil || len(plan.Victims) != 3 {
+		t.Fatalf("expected 3 victims to fit the preemptor, got %v", plan)
+	}
+	if plan := buildPlan(preemptor, node, small[:2]); plan != nil {
+		t.Errorf("expected no plan without enough victims, got %v", plan.Victims)
+	}
+}
+
+func TestSortPlans(t *testing.T) {
+	many := &Plan{Node: buildPlanNode("many", "4"), Victims: make([]*api.TaskInfo, 3)}
+	one := &Plan{Node: buildPlanNode("one", "4"), Victims: make([]*api.TaskInfo, 1)}
+	touched := &Plan{Node: buildPlanNode("touched", "4"), Victims: make([]*api.TaskInfo, 2)}
+	touched.Node.Releasing = api.NewResource(api.BuildResourceList("1", "1Gi"))
+	none := &Plan{Node: buildPlanNode("none", "4")}
+
+	tests := []struct {
+		name     string
+		costs    string
+		expected []string
+	}{
+		{name: "score order kept without cost functions", costs: "", expected: []string{"many", "one", "touched", "none"}},
+		{name: "fewest victims first", costs: "victims", expected: []string{"none", "one", "touched", "many"}},
+		{name: "evictions concentrated on touched nodes", costs: "nodes,victims", expected: []string{"none", "touched", "one", "many"}},
+		{name: "unknown cost functions ignored", costs: "unknown, victims", expected: []string{"none", "one", "touched", "many"}},
+	}
+	for _, test := range tests {
+		plans := []*Plan{many, one, touched, none}
+		sortPlans(parsePlanCostFns(test.costs), plans)
+		for i, plan := range plans {
+			if plan.Node.Name != test.expected[i] {
+				t.Errorf("%s: expected plans %v, got node %s at %d", test.name, test.expected, plan.Node.Name, i)
+				break
+			}
+		}
+	}
+}
@@ -30,6 +30,18 @@ import (
 
 type Action struct {
 	enablePredicateErrorCache bool
+
+	maxEvictionsPerCycle          int
+	maxEvictionsPerQueuePerMinute int
+	limiter                       *util.EvictionLimiter
+
+	// wholeJobPreemption evicts the jobs of the victims as a whole, for the tightly-coupled jobs
+	// which can't run without any of their tasks
+	wholeJobPreemption bool
+
+	// planCostFns order the candidate nodes of a preemptor by the cost of their preemption plans,
+	// the nodes are taken in score order if empty
+	planCostFns []PlanCostFn
 }
 
 func New() *Action {
@@ -47,6 +59,13 @@ func (pmpt *Action) Initialize() {}
 func (pmpt *Action) parseArguments(ssn *framework.Session) {
 	arguments := framework.GetArgOfActionFromConf(ssn.Configurations, pmpt.Name())
 	arguments.GetBool(&pmpt.enablePredicateErrorCache, conf.EnablePredicateErrCacheKey)
+	arguments.GetInt(&pmpt.maxEvictionsPerCycle, conf.MaxEvictionsPerCycleKey)
+	arguments.GetInt(&pmpt.maxEvictionsPerQueuePerMinute, conf.MaxEvictionsPerQueuePerMinuteKey)
+	arguments.GetBool(&pmpt.wholeJobPreemption, conf.WholeJobPreemptionKey)
+	pmpt.planCostFns = nil
+	if names, ok := arguments[conf.PreemptionPlanCostKey].(string); ok {
+		pmpt.planCostFns = parsePlanCostFns(names)
+	}
 }
 
 func (pmpt *Action) Execute(ssn *framework.Session) {
@@ -54,6 +73,7 @@ func (pmpt *Action) Execute(ssn *framework.Session) {
 	defer klog.V(5).Infof("Leaving Preempt ...")
 
 	pmpt.parseArguments(ssn)
+	pmpt.limiter = util.NewEvictionLimiter(pmpt.maxEvictionsPerCycle, pmpt.maxEvictionsPerQueuePerMinute)
 
 	preemptorsMap := map[api.QueueID]*util.PriorityQueue{}
 	preemptorTasks := map[api.JobID]*util.PriorityQueue{}
@@ -91,6 +111,10 @@ func (pmpt *Action) Execute(ssn *framework.Session) {
 				if task.SchGated {
 					continue
 				}
+				if task.NeverPreempts() {
+					klog.V(4).Infof("Task <%s/%s> has preemptionPolicy Never, skip it as preemptor.", task.Namespace, task.Name)
+					continue
+				}
 				preemptorTasks[job.UID].Push(task)
 			}
 		}
@@ -153,12 +177,20 @@ func (pmpt *Action) Execute(ssn *framework.Session) {
 			}
 
 			// Commit changes only if job is pipelined, otherwise try next job.
-			if ssn.JobPipelined(preemptorJob) {
-				stmt.Commit()
-			} else {
+			if !ssn.JobPipelined(preemptorJob) {
+				if !preemptorJob.PreemptionDryRun {
+					pmpt.limiter.Forget(queue.UID, len(stmt.Evictions()))
+				}
 				stmt.Discard()
 				continue
 			}
+			// The evictions of the dry-run jobs are only reported.
+			if preemptorJob.PreemptionDryRun {
+				ssn.RecordPreemptionDryRun(preemptorJob, "preemption", stmt.Evictions())
+				stmt.Discard()
+				continue
+			}
+			stmt.Commit()
 
 			if assigned {
 				preemptors.Push(preemptorJob)
@@ -170,8 +202,8 @@ func (pmpt *Action) Execute(ssn *framework.Session) {
 			// Fix: preemptor numbers lose when in same job
 			preemptorTasks[job.UID] = util.NewPriorityQueue(ssn.TaskOrderFn)
 			for _, task := range job.TaskStatusIndex[api.Pending] {
-				// Again, skip scheduling gated tasks
-				if task.SchGated {
+				// Again, skip scheduling gated tasks and the ones which never preempt
+				if task.SchGated || task.NeverPreempts() {
 					continue
 				}
 				preemptorTasks[job.UID].Push(task)
@@ -208,6 +240,13 @@ func (pmpt *Action) Execute(ssn *framework.Session) {
 				if err != nil {
 					klog.V(3).Infof("Preemptor <%s/%s> failed to preempt Task , err: %s", preemptor.Namespace, preemptor.Name, err)
 				}
+				if job.PreemptionDryRun {
+					if assigned {
+						ssn.RecordPreemptionDryRun(job, "preemption within the job", stmt.Evictions())
+					}
+					stmt.Discard()
+					break
+				}
 				stmt.Commit()
 
 				// If no preemption, next job.
@@ -245,6 +284,11 @@ func (pmpt *Action) preempt(
 	nodeScores := util.PrioritizeNodes(preemptor, predicateNodes, ssn.BatchNodeOrderFn, ssn.NodeOrderMapFn, ssn.NodeOrderReduceFn)
 
 	selectedNodes := util.SortNodes(nodeScores)
+	// the victims on the nodes found while ordering them are not looked up again
+	var nodeVictims map[string][]*api.TaskInfo
+	if len(pmpt.planCostFns) > 0 {
+		selectedNodes, nodeVictims = pmpt.orderByPlanCost(ssn, preemptor, selectedNodes, filter)
+	}
 
 	job, found := ssn.Jobs[preemptor.Job]
 	if !found {
@@ -257,15 +301,10 @@ func (pmpt *Action) preempt(
 		klog.V(3).Infof("Considering Task <%s/%s> on Node <%s>.",
 			preemptor.Namespace, preemptor.Name, node.Name)
 
-		var preemptees []*api.TaskInfo
-		for _, task := range node.Tasks {
-			if filter == nil {
-				preemptees = append(preemptees, task.Clone())
-			} else if filter(task) {
-				preemptees = append(preemptees, task.Clone())
-			}
+		victims, found := nodeVictims[node.Name]
+		if !found {
+			victims = ssn.Preemptable(preemptor, preemptees(node, filter))
 		}
-		victims := ssn.Preemptable(preemptor, preemptees)
 		metrics.UpdatePreemptionVictimsCount(len(victims))
 
 		if err := util.ValidateVictims(preemptor, node, victims); err != nil {
@@ -275,7 +314,9 @@ func (pmpt *Action) preempt(
 
 		victimsQueue := ssn.BuildVictimsPriorityQueue(victims)
 		// Preempt victims for tasks, pick lowest priority task first.
+		checkpoint := stmt.Checkpoint()
 		preempted := api.EmptyResource()
+		evictedJobs := map[api.JobID]bool{}
 
 		for !victimsQueue.Empty() {
 			// If reclaimed enough resources, break loop to avoid Sub panic.
@@ -291,15 +332,45 @@ func (pmpt *Action) preempt(
 			if ssn.Allocatable(currentQueue, preemptor) && preemptor.InitResreq.LessEqual(node.FutureIdle(), api.Zero) {
 				break
 			}
+			// the evictions of the dry-run jobs are neither recorded nor limited
+			if !job.PreemptionDryRun && !pmpt.limiter.Allow(currentQueue.UID) {
+				klog.V(3).Infof("Evictions caused by Queue <%s> are rate limited, stop preempting for Task <%s/%s>",
+					currentQueue.Name, preemptor.Namespace, preemptor.Name)
+				break
+			}
 			preemptee := victimsQueue.Pop().(*api.TaskInfo)
+			if evictedJobs[preemptee.Job] {
+				continue
+			}
+			var siblings []*api.TaskInfo
+			if pmpt.wholeJobPreemption {
+				evictedJobs[preemptee.Job] = true
+				var evictable bool
+				if siblings, evictable = jobVictims(ssn, preemptor, preemptee, filter); !evictable {
+					klog.V(3).Infof("The other tasks of Task <%s/%s> can not be preempted for Task <%s/%s>, skip its job",
+						preemptee.Namespace, preemptee.Name, preemptor.Namespace, preemptor.Name)
+					continue
+				}
+				if !job.PreemptionDryRun && !pmpt.limiter.AllowN(currentQueue.UID, len(siblings)+1) {
+					klog.V(3).Infof("Evictions caused by Queue <%s> are rate limited, skip the job of Task <%s/%s>",
+						currentQueue.Name, preemptee.Namespace, preemptee.Name)
+					continue
+				}
+			}
 			klog.V(3).Infof("Try to preempt Task <%s/%s> for Task <%s/%s>",
 				preemptee.Namespace, preemptee.Name, preemptor.Namespace, preemptor.Name)
-			if err := stmt.Evict(preemptee, "preempt"); err != nil {
+			released, err := evictJob(stmt, preemptee, siblings, node.Name)
+			if err != nil {
 				klog.Errorf("Failed to preempt Task <%s/%s> for Task <%s/%s>: %v",
 					preemptee.Namespace, preemptee.Name, preemptor.Namespace, preemptor.Name, err)
 				continue
 			}
-			preempted.Add(preemptee.Resreq)
+			if !job.PreemptionDryRun {
+				for i := 0; i <= len(siblings); i++ {
+					pmpt.limiter.Record(currentQueue.UID)
+				}
+			}
+			preempted.Add(released)
 		}
 
 		evictionOccurred := false
@@ -323,11 +394,108 @@ func (pmpt *Action) preempt(
 
 			break
 		}
+
+		// The evictions on the node don't make room for the preemptor, none of them is executed.
+		if evicted := stmt.Rollback(checkpoint); evicted > 0 && !job.PreemptionDryRun {
+			pmpt.limiter.Forget(currentQueue.UID, evicted)
+		}
 	}
 
 	return assigned, nil
 }
 
+// preemptees returns clones of the tasks of the node which pass the filter.
+func preemptees(node *api.NodeInfo, filter func(*api.TaskInfo) bool) []*api.TaskInfo {
+	var tasks []*api.TaskInfo
+	for _, task := range node.Tasks {
+		if filter == nil || filter(task) {
+			tasks = append(tasks, task.Clone())
+		}
+	}
+	return tasks
+}
+
+// orderByPlanCost orders the nodes by the cost of the plans preempting for the preemptor on them,
+// the nodes without a valid plan go last in their original order. It also returns the victims of
+// the preemptor on each node.
+func (pmpt *Action) orderByPlanCost(ssn *framework.Session, preemptor *api.TaskInfo, nodes []*api.NodeInfo,
+	filter func(*api.TaskInfo) bool) ([]*api.NodeInfo, map[string][]*api.TaskInfo) {
+	var plans []*Plan
+	var rest []*api.NodeInfo
+	nodeVictims := make(map[string][]*api.TaskInfo, len(nodes))
+	for _, node := range nodes {
+		victims := ssn.Preemptable(preemptor, preemptees(node, filter))
+		nodeVictims[node.Name] = victims
+		if err := util.ValidateVictims(preemptor, node, victims); err != nil {
+			rest = append(rest, node)
+			continue
+		}
+		victimsQueue := ssn.BuildVictimsPriorityQueue(victims)
+		ordered := make([]*api.TaskInfo, 0, len(victims))
+		for !victimsQueue.Empty() {
+			ordered = append(ordered, victimsQueue.Pop().(*api.TaskInfo))
+		}
+		if plan := buildPlan(preemptor, node, ordered); plan != nil {
+			plans = append(plans, plan)
+		} else {
+			rest = append(rest, node)
+		}
+	}
+
+	sortPlans(pmpt.planCostFns, plans)
+	ordered := make([]*api.NodeInfo, 0, len(nodes))
+	for _, plan := range plans {
+		klog.V(4).Infof("Preemption plan for Task <%s/%s> on Node <%s> evicts %d tasks",
+			preemptor.Namespace, preemptor.Name, plan.Node.Name, len(plan.Victims))
+		ordered = append(ordered, plan.Node)
+	}
+	return append(ordered, rest...), nodeVictims
+}
+
+// jobVictims returns the other allocated tasks of the job of the victim, which are evicted with it
+// so that the job is requeued as a whole. It returns false when any of them can't be preempted
+// for the preemptor, the job is then left running.
+func jobVictims(ssn *framework.Session, preemptor, victim *api.TaskInfo, filter func(*api.TaskInfo) bool) ([]*api.TaskInfo, bool) {
+	job, found := ssn.Jobs[victim.Job]
+	if !found {
+		return nil, true
+	}
+	var tasks []*api.TaskInfo
+	for _, task := range job.Tasks {
+		if task.UID == victim.UID || !api.AllocatedStatus(task.Status) {
+			continue
+		}
+		if filter != nil && !filter(task) {
+			return nil, false
+		}
+		tasks = append(tasks, task.Clone())
+	}
+	if len(tasks) == 0 {
+		return nil, true
+	}
+	if victims := ssn.Preemptable(preemptor, tasks); len(victims) != len(tasks) {
+		return nil, false
+	}
+	return tasks, true
+}
+
+// evictJob evicts the victim and the other tasks of its job, it returns the resource released on
+// the node. None of them is evicted when any eviction fails.
+func evictJob(stmt *framework.Statement, victim *api.TaskInfo, siblings []*api.TaskInfo, nodeName string) (*api.Resource, error) {
+	checkpoint := stmt.Checkpoint()
+	released := api.EmptyResource()
+	for _, task := range append([]*api.TaskInfo{victim}, siblings...) {
+		if err := stmt.Evict(task, "preempt"); err != nil {
+			stmt.Rollback(checkpoint)
+			return nil, fmt.Errorf("failed to evict Task <%s/%s>: %v", task.Namespace, task.Name, err)
+		}
+		if task.NodeName == nodeName {
+			released.Add(task.Resreq)
+		}
+	}
+	return released, nil
+}
+
 func victimTasks(ssn *framework.Session) {
 	stmt := framework.NewStatement(ssn)
 	tasks := make([]*api.TaskInfo, 0)
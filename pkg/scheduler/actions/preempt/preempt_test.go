@@ -31,6 +31,7 @@ import (
 	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
 	"volcano.sh/volcano/pkg/scheduler/plugins/priority"
 	"volcano.sh/volcano/pkg/scheduler/plugins/proportion"
+	pluginutil "volcano.sh/volcano/pkg/scheduler/plugins/util"
 	"volcano.sh/volcano/pkg/scheduler/uthelper"
 	"volcano.sh/volcano/pkg/scheduler/util"
 )
@@ -45,6 +46,15 @@ func TestPreempt(t *testing.T) {
 	highPrio := util.BuildPriorityClass("high-priority", 100000)
 	lowPrio := util.BuildPriorityClass("low-priority", 10)
 	options.Default()
+	neverPreempt := func(pod *v1.Pod) *v1.Pod {
+		policy := v1.PreemptNever
+		pod.Spec.PreemptionPolicy = &policy
+		return pod
+	}
+	dryRun := func(pg *schedulingv1beta1.PodGroup) *schedulingv1beta1.PodGroup {
+		pg.Annotations = map[string]string{api.JobPreemptionPolicy: api.PreemptionPolicyDryRun}
+		return pg
+	}
 
 	tests := []uthelper.TestCommonStruct{
 		{
@@ -109,6 +119,46 @@ func TestPreempt(t *testing.T) {
 			ExpectEvicted:  []string{"c1/preemptee1"},
 			ExpectEvictNum: 1,
 		},
+		{
+			Name: "do not evict the victims of a dry-run preemptor",
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				util.BuildPodGroupWithPrio("pg1", "c1", "q1", 1, map[string]int32{"": 2}, schedulingv1beta1.PodGroupInqueue, "low-priority"),
+				dryRun(util.BuildPodGroupWithPrio("pg2", "c1", "q1", 1, map[string]int32{"": 2}, schedulingv1beta1.PodGroupInqueue, "high-priority")),
+			},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+				util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "false"}, make(map[string]string)),
+				util.BuildPod("c1", "preemptor1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptor2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			Nodes: []*v1.Node{
+				util.BuildNode("n1", api.BuildResourceList("2", "2G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+			},
+			Queues: []*schedulingv1beta1.Queue{
+				util.BuildQueue("q1", 1, nil),
+			},
+			ExpectEvictNum: 0,
+		},
+		{
+			Name: "do not preempt for tasks with preemptionPolicy Never",
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				util.BuildPodGroupWithPrio("pg1", "c1", "q1", 1, map[string]int32{"": 2}, schedulingv1beta1.PodGroupInqueue, "low-priority"),
+				util.BuildPodGroupWithPrio("pg2", "c1", "q1", 1, map[string]int32{"": 2}, schedulingv1beta1.PodGroupInqueue, "high-priority"),
+			},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+				util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+				neverPreempt(util.BuildPod("c1", "preemptor1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string))),
+				neverPreempt(util.BuildPod("c1", "preemptor2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string))),
+			},
+			Nodes: []*v1.Node{
+				util.BuildNode("n1", api.BuildResourceList("2", "2G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+			},
+			Queues: []*schedulingv1beta1.Queue{
+				util.BuildQueue("q1", 1, nil),
+			},
+			ExpectEvictNum: 0,
+		},
 		{
 			Name: "preempt enough tasks to fit large task of different job",
 			PodGroups: []*schedulingv1beta1.PodGroup{
@@ -280,3 +330,309 @@ func TestPreempt(t *testing.T) {
 		})
 	}
 }
+
+func TestWholeJobPreemption(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		conformance.PluginName: conformance.New,
+		gang.PluginName:        gang.New,
+		priority.PluginName:    priority.New,
+	}
+	highPrio := util.BuildPriorityClass("high-priority", 100000)
+	lowPrio := util.BuildPriorityClass("low-priority", 10)
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               conformance.PluginName,
+					EnabledPreemptable: &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledPreemptable:  &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+				{
+					Name:                priority.PluginName,
+					EnabledTaskOrder:    &trueValue,
+					EnabledJobOrder:     &trueValue,
+					EnabledPreemptable:  &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+			},
+		}}
+
+	tests := []struct {
+		uthelper.TestCommonStruct
+		wholeJob bool
+		// siblingNotPreemptable marks preemptee2 as not preemptable
+		siblingNotPreemptable bool
+		maxEvictionsPerCycle  int
+	}{
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:           "gang job at its minAvailable is not preempted task by task",
+				ExpectEvictNum: 0,
+			},
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:           "gang job is preempted as a whole",
+				ExpectEvicted:  []string{"c1/preemptee1", "c1/preemptee2"},
+				ExpectEvictNum: 2,
+			},
+			wholeJob: true,
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:           "gang job with a task which is not preemptable is not preempted",
+				ExpectEvictNum: 0,
+			},
+			wholeJob:              true,
+			siblingNotPreemptable: true,
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:           "gang job is not preempted beyond the evictions per cycle",
+				ExpectEvictNum: 0,
+			},
+			wholeJob:             true,
+			maxEvictionsPerCycle: 1,
+		},
+	}
+
+	for i, test := range tests {
+		test.Plugins = plugins
+		test.PriClass = []*schedulingv1.PriorityClass{highPrio, lowPrio}
+		test.PodGroups = []*schedulingv1beta1.PodGroup{
+			util.BuildPodGroupWithPrio("pg1", "c1", "q1", 2, nil, schedulingv1beta1.PodGroupRunning, "low-priority"),
+			util.BuildPodGroupWithPrio("pg2", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue, "high-priority"),
+		}
+		test.Pods = []*v1.Pod{
+			util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "preemptee2", "n2", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "preemptor1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+		}
+		if test.siblingNotPreemptable {
+			test.Pods[1].Annotations[schedulingv1beta1.PodPreemptable] = "false"
+		}
+		test.Nodes = []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("1", "1G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+			util.BuildNode("n2", api.BuildResourceList("1", "1G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+		}
+		test.Queues = []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)}
+
+		configurations := []conf.Configuration{{
+			Name: "preempt",
+			Arguments: map[string]interface{}{
+				conf.WholeJobPreemptionKey:   test.wholeJob,
+				conf.MaxEvictionsPerCycleKey: test.maxEvictionsPerCycle,
+			},
+		}}
+		t.Run(test.Name, func(t *testing.T) {
+			test.RegisterSession(tiers, configurations)
+			defer test.Close()
+			test.Run([]framework.Action{New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestPreemptionDryRunReport(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		conformance.PluginName: conformance.New,
+		gang.PluginName:        gang.New,
+		priority.PluginName:    priority.New,
+	}
+	highPrio := util.BuildPriorityClass("high-priority", 100000)
+	lowPrio := util.BuildPriorityClass("low-priority", 10)
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               conformance.PluginName,
+					EnabledPreemptable: &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledPreemptable:  &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+				{
+					Name:                priority.PluginName,
+					EnabledTaskOrder:    &trueValue,
+					EnabledJobOrder:     &trueValue,
+					EnabledPreemptable:  &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+			},
+		}}
+
+	tests := []struct {
+		uthelper.TestCommonStruct
+		minAvailable int32
+		// throttled uses up the evictions of the queue in the last minute
+		throttled bool
+		report    string
+	}{
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{Name: "report the victims of a pipelined dry-run job"},
+			minAvailable:     1,
+			report:           "preemption would evict 1 tasks: c1/preemptee1 on n1",
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{Name: "report the victims of a dry-run job whose queue is rate limited"},
+			minAvailable:     1,
+			throttled:        true,
+			report:           "preemption would evict 1 tasks: c1/preemptee1 on n1",
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{Name: "do not report the victims of a dry-run job which is not pipelined"},
+			minAvailable:     2,
+		},
+	}
+
+	for i, test := range tests {
+		test.Plugins = plugins
+		test.PriClass = []*schedulingv1.PriorityClass{highPrio, lowPrio}
+		pg := util.BuildPodGroupWithPrio("pg2", "c1", "q1", test.minAvailable, nil, schedulingv1beta1.PodGroupInqueue, "high-priority")
+		pg.Annotations = map[string]string{api.JobPreemptionPolicy: api.PreemptionPolicyDryRun}
+		test.PodGroups = []*schedulingv1beta1.PodGroup{
+			util.BuildPodGroupWithPrio("pg1", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning, "low-priority"),
+			pg,
+		}
+		test.Pods = []*v1.Pod{
+			util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+			util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "false"}, make(map[string]string)),
+			util.BuildPod("c1", "preemptor1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "preemptor2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+		}
+		test.Nodes = []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("2", "2G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+		}
+		test.Queues = []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)}
+
+		t.Run(test.Name, func(t *testing.T) {
+			var configurations []conf.Configuration
+			if test.throttled {
+				configurations = []conf.Configuration{{
+					Name:      "preempt",
+					Arguments: map[string]interface{}{conf.MaxEvictionsPerQueuePerMinuteKey: 1},
+				}}
+				limiter := util.NewEvictionLimiter(0, 1)
+				limiter.Record("q1")
+				defer limiter.Forget("q1", 1)
+			}
+			ssn := test.RegisterSession(tiers, configurations)
+			defer test.Close()
+			test.Run([]framework.Action{New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+			report := ""
+			for _, c := range ssn.Jobs["c1/pg2"].PodGroup.Status.Conditions {
+				if c.Type == api.PodGroupPreemptionDryRunType {
+					report = c.Message
+				}
+			}
+			if report != test.report {
+				t.Errorf("expected the dry-run report %q, got %q", test.report, report)
+			}
+		})
+	}
+}
+
+// countingPlugin counts the lookups of the victims, all of which it permits.
+type countingPlugin struct {
+	lookups *int
+}
+
+func (cp *countingPlugin) Name() string { return "counting" }
+
+func (cp *countingPlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddPreemptableFn(cp.Name(), func(preemptor *api.TaskInfo, preemptees []*api.TaskInfo) ([]*api.TaskInfo, int) {
+		*cp.lookups++
+		return preemptees, pluginutil.Permit
+	})
+}
+
+func (cp *countingPlugin) OnSessionClose(ssn *framework.Session) {}
+
+func TestPlanCostVictimsLookedUpOnce(t *testing.T) {
+	lookups := 0
+	plugins := map[string]framework.PluginBuilder{
+		priority.PluginName: priority.New,
+		"counting": func(framework.Arguments) framework.Plugin {
+			return &countingPlugin{lookups: &lookups}
+		},
+	}
+	highPrio := util.BuildPriorityClass("high-priority", 100000)
+	lowPrio := util.BuildPriorityClass("low-priority", 10)
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:                priority.PluginName,
+					EnabledTaskOrder:    &trueValue,
+					EnabledJobOrder:     &trueValue,
+					EnabledPreemptable:  &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+				{
+					Name:               "counting",
+					EnabledPreemptable: &trueValue,
+				},
+			},
+		}}
+
+	test := uthelper.TestCommonStruct{
+		Name:           "the victims found while ordering the nodes are reused",
+		Plugins:        plugins,
+		PriClass:       []*schedulingv1.PriorityClass{highPrio, lowPrio},
+		ExpectEvicted:  []string{"c1/preemptee1"},
+		ExpectEvictNum: 1,
+		PodGroups: []*schedulingv1beta1.PodGroup{
+			util.BuildPodGroupWithPrio("pg1", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning, "low-priority"),
+			util.BuildPodGroupWithPrio("pg2", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue, "high-priority"),
+			util.BuildPodGroupWithPrio("pg3", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning, "high-priority"),
+		},
+		Pods: []*v1.Pod{
+			util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "running", "n2", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg3", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "preemptor1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+		},
+		Nodes: []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("1", "1G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+			util.BuildNode("n2", api.BuildResourceList("1", "1G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+		},
+		Queues: []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+	}
+	configurations := []conf.Configuration{{
+		Name:      "preempt",
+		Arguments: map[string]interface{}{conf.PreemptionPlanCostKey: "victims"},
+	}}
+
+	test.RegisterSession(tiers, configurations)
+	defer test.Close()
+	test.Run([]framework.Action{New()})
+	if err := test.CheckAll(0); err != nil {
+		t.Fatal(err)
+	}
+	// once to order the nodes, none again for n1 preempted on; the priority plugin finds no
+	// victim on n2 before the counting plugin is asked
+	if lookups != 1 {
+		t.Errorf("expected the victims on n1 looked up once, got %d lookups", lookups)
+	}
+}
@@ -17,14 +17,20 @@ limitations under the License.
 package reclaim
 
 import (
+	"sort"
+
 	"k8s.io/klog/v2"
 
 	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
 	"volcano.sh/volcano/pkg/scheduler/framework"
 	"volcano.sh/volcano/pkg/scheduler/util"
 )
 
-type Action struct{}
+type Action struct {
+	maxEvictionsPerCycle          int
+	maxEvictionsPerQueuePerMinute int
+}
 
 func New() *Action {
 	return &Action{}
@@ -36,10 +42,19 @@ func (ra *Action) Name() string {
 
 func (ra *Action) Initialize() {}
 
+func (ra *Action) parseArguments(ssn *framework.Session) {
+	arguments := framework.GetArgOfActionFromConf(ssn.Configurations, ra.Name())
+	arguments.GetInt(&ra.maxEvictionsPerCycle, conf.MaxEvictionsPerCycleKey)
+	arguments.GetInt(&ra.maxEvictionsPerQueuePerMinute, conf.MaxEvictionsPerQueuePerMinuteKey)
+}
+
 func (ra *Action) Execute(ssn *framework.Session) {
 	klog.V(5).Infof("Enter Reclaim ...")
 	defer klog.V(5).Infof("Leaving Reclaim ...")
 
+	ra.parseArguments(ssn)
+	limiter := util.NewEvictionLimiter(ra.maxEvictionsPerCycle, ra.maxEvictionsPerQueuePerMinute)
+
 	queues := util.NewPriorityQueue(ssn.QueueOrderFn)
 	queueMap := map[api.QueueID]*api.QueueInfo{}
 
@@ -78,6 +93,10 @@ func (ra *Action) Execute(ssn *framework.Session) {
 				if task.SchGated {
 					continue
 				}
+				if task.NeverPreempts() {
+					klog.V(4).Infof("Task <%s/%s> has preemptionPolicy Never, skip it as reclaimer.", task.Namespace, task.Name)
+					continue
+				}
 				preemptorTasks[job.UID].Push(task)
 			}
 		}
@@ -107,118 +126,182 @@ func (ra *Action) Execute(ssn *framework.Session) {
 		}
 
 		// Found "high" priority task to reclaim others
-		if tasks, found := preemptorTasks[job.UID]; !found || tasks.Empty() {
+		tasks, found := preemptorTasks[job.UID]
+		if !found || tasks.Empty() {
 			continue
-		} else {
-			task = tasks.Pop().(*api.TaskInfo)
 		}
-
-		if !ssn.Allocatable(queue, task) {
-			klog.V(3).Infof("Queue <%s> is overused when considering task <%s>, ignore it.", queue.Name, task.Name)
+		task = tasks.Pop().(*api.TaskInfo)
+
+		if job.PreemptionDryRun {
+			// The evictions of the dry-run job are kept in one statement across its tasks, so that
+			// the next tasks are not reported against the resources the previous ones would have
+			// reclaimed; they are reported and discarded before any other job is considered.
+			stmt := framework.NewStatement(ssn)
+			for ra.reclaimForTask(ssn, limiter, queue, job, task, stmt) && !tasks.Empty() {
+				task = tasks.Pop().(*api.TaskInfo)
+			}
+			ssn.RecordPreemptionDryRun(job, "reclaim", stmt.Evictions())
+			stmt.Discard()
+			queues.Push(queue)
 			continue
 		}
 
-		if !ssn.Preemptive(queue, task) {
-			klog.V(3).Infof("Queue <%s> can not reclaim by preempt others when considering task <%s> , ignore it.", queue.Name, task.Name)
-			continue
+		if ra.reclaimForTask(ssn, limiter, queue, job, task, nil) {
+			jobs.Push(job)
 		}
+		queues.Push(queue)
+	}
+}
 
-		if err := ssn.PrePredicateFn(task); err != nil {
-			klog.V(3).Infof("PrePredicate for task %s/%s failed for: %v", task.Namespace, task.Name, err)
-			continue
-		}
+// reclaimForTask reclaims the resources of the task from the other queues on the first node
+// where enough is reclaimed, and pipelines the task there; it returns whether the task was
+// pipelined. The evictions of the dry-run job are kept in dryRun rather than executed.
+func (ra *Action) reclaimForTask(ssn *framework.Session, limiter *util.EvictionLimiter, queue *api.QueueInfo,
+	job *api.JobInfo, task *api.TaskInfo, dryRun *framework.Statement) bool {
+	if !ssn.Allocatable(queue, task) {
+		klog.V(3).Infof("Queue <%s> is overused when considering task <%s>, ignore it.", queue.Name, task.Name)
+		return false
+	}
 
-		assigned := false
-		// we should filter out those nodes that are UnschedulableAndUnresolvable status got in allocate action
-		totalNodes := ssn.GetUnschedulableAndUnresolvableNodesForTask(task)
-		for _, n := range totalNodes {
-			// When filtering candidate nodes, need to consider the node statusSets instead of the err information.
-			// refer to kube-scheduler preemption code: https://github.com/kubernetes/kubernetes/blob/9d87fa215d9e8020abdc17132d1252536cd752d2/pkg/scheduler/framework/preemption/preemption.go#L422
-			if err := ssn.PredicateForPreemptAction(task, n); err != nil {
-				klog.V(4).Infof("Reclaim predicate for task %s/%s on node %s return error %v ", task.Namespace, task.Name, n.Name, err)
-				continue
-			}
+	if !ssn.Preemptive(queue, task) {
+		klog.V(3).Infof("Queue <%s> can not reclaim by preempt others when considering task <%s> , ignore it.", queue.Name, task.Name)
+		return false
+	}
 
-			klog.V(3).Infof("Considering Task <%s/%s> on Node <%s>.", task.Namespace, task.Name, n.Name)
+	if err := ssn.PrePredicateFn(task); err != nil {
+		klog.V(3).Infof("PrePredicate for task %s/%s failed for: %v", task.Namespace, task.Name, err)
+		return false
+	}
 
-			var reclaimees []*api.TaskInfo
-			for _, task := range n.Tasks {
-				// Ignore non running task.
-				if task.Status != api.Running {
-					continue
-				}
-				if !task.Preemptable {
-					continue
-				}
+	// we should filter out those nodes that are UnschedulableAndUnresolvable status got in allocate action
+	totalNodes := ssn.GetUnschedulableAndUnresolvableNodesForTask(task)
+	for _, n := range totalNodes {
+		// When filtering candidate nodes, need to consider the node statusSets instead of the err information.
+		// refer to kube-scheduler preemption code: https://github.com/kubernetes/kubernetes/blob/9d87fa215d9e8020abdc17132d1252536cd752d2/pkg/scheduler/framework/preemption/preemption.go#L422
+		if err := ssn.PredicateForPreemptAction(task, n); err != nil {
+			klog.V(4).Infof("Reclaim predicate for task %s/%s on node %s return error %v ", task.Namespace, task.Name, n.Name, err)
+			continue
+		}
 
-				if j, found := ssn.Jobs[task.Job]; !found {
-					continue
-				} else if j.Queue != job.Queue {
-					q := ssn.Queues[j.Queue]
-					if !q.Reclaimable() {
-						continue
-					}
-					// Clone task to avoid modify Task's status on node.
-					reclaimees = append(reclaimees, task.Clone())
-				}
-			}
+		klog.V(3).Infof("Considering Task <%s/%s> on Node <%s>.", task.Namespace, task.Name, n.Name)
 
-			if len(reclaimees) == 0 {
-				klog.V(4).Infof("No reclaimees on Node <%s>.", n.Name)
+		var reclaimees []*api.TaskInfo
+		for _, task := range n.Tasks {
+			// Ignore non running task.
+			if task.Status != api.Running {
 				continue
 			}
-
-			victims := ssn.Reclaimable(task, reclaimees)
-
-			if err := util.ValidateVictims(task, n, victims); err != nil {
-				klog.V(3).Infof("No validated victims on Node <%s>: %v", n.Name, err)
+			if !task.Preemptable {
 				continue
 			}
 
-			victimsQueue := ssn.BuildVictimsPriorityQueue(victims)
-
-			resreq := task.InitResreq.Clone()
-			reclaimed := api.EmptyResource()
-
-			// Reclaim victims for tasks.
-			for !victimsQueue.Empty() {
-				reclaimee := victimsQueue.Pop().(*api.TaskInfo)
-				klog.Errorf("Try to reclaim Task <%s/%s> for Tasks <%s/%s>",
-					reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name)
-				if err := ssn.Evict(reclaimee, "reclaim"); err != nil {
-					klog.Errorf("Failed to reclaim Task <%s/%s> for Tasks <%s/%s>: %v",
-						reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name, err)
+			if j, found := ssn.Jobs[task.Job]; !found {
+				continue
+			} else if j.Queue != job.Queue {
+				q := ssn.Queues[j.Queue]
+				if !q.Reclaimable() {
 					continue
 				}
-				reclaimed.Add(reclaimee.Resreq)
-				// If reclaimed enough resources, break loop to avoid Sub panic.
-				if resreq.LessEqual(reclaimed, api.Zero) {
-					break
-				}
+				// Clone task to avoid modify Task's status on node.
+				reclaimees = append(reclaimees, task.Clone())
 			}
+		}
 
-			klog.V(3).Infof("Reclaimed <%v> for task <%s/%s> requested <%v>.",
-				reclaimed, task.Namespace, task.Name, task.InitResreq)
+		// Keep the order of the reclaimees stable for the plugins choosing victims among them.
+		sort.Slice(reclaimees, func(i, j int) bool {
+			if reclaimees[i].Namespace != reclaimees[j].Namespace {
+				return reclaimees[i].Namespace < reclaimees[j].Namespace
+			}
+			return reclaimees[i].Name < reclaimees[j].Name
+		})
 
-			if task.InitResreq.LessEqual(reclaimed, api.Zero) {
-				if err := ssn.Pipeline(task, n.Name); err != nil {
-					klog.Errorf("Failed to pipeline Task <%s/%s> on Node <%s>",
-						task.Namespace, task.Name, n.Name)
-				}
+		if len(reclaimees) == 0 {
+			klog.V(4).Infof("No reclaimees on Node <%s>.", n.Name)
+			continue
+		}
+
+		victims := ssn.Reclaimable(task, reclaimees)
+
+		if err := util.ValidateVictims(task, n, victims); err != nil {
+			klog.V(3).Infof("No validated victims on Node <%s>: %v", n.Name, err)
+			continue
+		}
 
-				// Ignore error of pipeline, will be corrected in next scheduling loop.
-				assigned = true
+		resreq := task.InitResreq.Clone()
+		reclaimed := api.EmptyResource()
 
+		// Reclaim victims for tasks. The victims are ordered again after each eviction, which
+		// lowers the share of the victim's queue, so that the victims are taken from the overused
+		// queues in proportion to their overuse rather than all from the most overused one. The
+		// evictions are only executed if they reclaim enough for the task.
+		stmt := dryRun
+		if stmt == nil {
+			stmt = framework.NewStatement(ssn)
+		}
+		checkpoint := stmt.Checkpoint()
+		for len(victims) > 0 {
+			if !job.PreemptionDryRun && !limiter.Allow(queue.UID) {
+				klog.V(3).Infof("Evictions caused by Queue <%s> are rate limited, stop reclaiming for Task <%s/%s>",
+					queue.Name, task.Namespace, task.Name)
+				break
+			}
+			reclaimee := ssn.BuildVictimsPriorityQueue(victims).Pop().(*api.TaskInfo)
+			victims = removeTask(victims, reclaimee)
+			klog.Errorf("Try to reclaim Task <%s/%s> for Tasks <%s/%s>",
+				reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name)
+			if err := stmt.Evict(reclaimee, "reclaim"); err != nil {
+				klog.Errorf("Failed to reclaim Task <%s/%s> for Tasks <%s/%s>: %v",
+					reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name, err)
+				continue
+			}
+			if !job.PreemptionDryRun {
+				limiter.Record(queue.UID)
+			}
+			reclaimed.Add(reclaimee.Resreq)
+			// If reclaimed enough resources, break loop to avoid Sub panic.
+			if resreq.LessEqual(reclaimed, api.Zero) {
 				break
 			}
 		}
 
-		if assigned {
-			jobs.Push(job)
+		klog.V(3).Infof("Reclaimed <%v> for task <%s/%s> requested <%v>.",
+			reclaimed, task.Namespace, task.Name, task.InitResreq)
+
+		if !task.InitResreq.LessEqual(reclaimed, api.Zero) {
+			if evicted := stmt.Rollback(checkpoint); evicted > 0 && !job.PreemptionDryRun {
+				limiter.Forget(queue.UID, evicted)
+			}
+			continue
 		}
-		queues.Push(queue)
+		if job.PreemptionDryRun {
+			// Keep the task on the node in the dry-run statement, so that the next tasks of
+			// the job are not reported against the resources it would have reclaimed.
+			if err := stmt.Pipeline(task, n.Name, true); err != nil {
+				klog.Errorf("Failed to pipeline Task <%s/%s> on Node <%s> for the dry-run",
+					task.Namespace, task.Name, n.Name)
+			}
+			return true
+		}
+		stmt.Commit()
+		if err := ssn.Pipeline(task, n.Name); err != nil {
+			klog.Errorf("Failed to pipeline Task <%s/%s> on Node <%s>",
+				task.Namespace, task.Name, n.Name)
+		}
+
+		// Ignore error of pipeline, will be corrected in next scheduling loop.
+		return true
 	}
+	return false
 }
 
 func (ra *Action) UnInitialize() {
 }
+
+func removeTask(tasks []*api.TaskInfo, task *api.TaskInfo) []*api.TaskInfo {
+	for i := range tasks {
+		if tasks[i].UID == task.UID {
+			return append(tasks[:i], tasks[i+1:]...)
+		}
+	}
+	return tasks
+}
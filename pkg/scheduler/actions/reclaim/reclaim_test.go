@@ -17,6 +17,7 @@ limitations under the License.
 package reclaim
 
 import (
+	"fmt"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
@@ -35,6 +36,11 @@ import (
 )
 
 func TestReclaim(t *testing.T) {
+	neverPreempt := func(pod *v1.Pod) *v1.Pod {
+		policy := v1.PreemptNever
+		pod.Spec.PreemptionPolicy = &policy
+		return pod
+	}
 	tests := []uthelper.TestCommonStruct{
 		{
 			Name: "Two Queue with one Queue overusing resource, should reclaim",
@@ -63,6 +69,32 @@ func TestReclaim(t *testing.T) {
 			ExpectEvictNum: 1,
 			ExpectEvicted:  []string{"c1/preemptee2"}, // let pod2 in the middle when sort tasks be preemptable and will not disturb
 		},
+		{
+			Name: "Two Queue with one Queue overusing resource, should not reclaim for preemptionPolicy Never",
+			Plugins: map[string]framework.PluginBuilder{
+				conformance.PluginName: conformance.New,
+				gang.PluginName:        gang.New,
+				proportion.PluginName:  proportion.New,
+			},
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				util.BuildPodGroupWithPrio("pg1", "c1", "q1", 0, nil, schedulingv1beta1.PodGroupInqueue, "low-priority"),
+				util.BuildPodGroupWithPrio("pg2", "c1", "q2", 0, nil, schedulingv1beta1.PodGroupInqueue, "high-priority"),
+			},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+				util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+				util.BuildPod("c1", "preemptee3", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+				neverPreempt(util.BuildPod("c1", "preemptor1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string))),
+			},
+			Nodes: []*v1.Node{
+				util.BuildNode("n1", api.BuildResourceList("3", "3Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+			},
+			Queues: []*schedulingv1beta1.Queue{
+				util.BuildQueue("q1", 1, nil),
+				util.BuildQueue("q2", 1, nil),
+			},
+			ExpectEvictNum: 0,
+		},
 		{
 			Name: "sort reclaimees when reclaiming from overusing queue",
 			Plugins: map[string]framework.PluginBuilder{
@@ -129,6 +161,37 @@ func TestReclaim(t *testing.T) {
 			ExpectEvictNum: 1,
 			ExpectEvicted:  []string{"c1/preemptee1-1"}, // low queue priority job's preemptable pod is evicted
 		},
+		{
+			Name: "reclaim from overusing queues in proportion to their overuse",
+			Plugins: map[string]framework.PluginBuilder{
+				conformance.PluginName: conformance.New,
+				gang.PluginName:        gang.New,
+				proportion.PluginName:  proportion.New,
+			},
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				util.BuildPodGroupWithPrio("pg1", "c1", "q1", 0, nil, schedulingv1beta1.PodGroupRunning, ""),
+				util.BuildPodGroupWithPrio("pg2", "c1", "q2", 0, nil, schedulingv1beta1.PodGroupRunning, ""),
+				util.BuildPodGroupWithPrio("pg3", "c1", "q3", 0, nil, schedulingv1beta1.PodGroupInqueue, ""),
+			},
+			Pods: append(append(
+				buildRunningPods("q1-task", 5, "1", "1G", "pg1"),
+				buildRunningPods("q2-task", 9, "500m", "500M", "pg2")...),
+				util.BuildPod("c1", "q3-task-1", "", v1.PodPending, api.BuildResourceList("2", "2G"), "pg3", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "q3-task-2", "", v1.PodPending, api.BuildResourceList("2", "2G"), "pg3", make(map[string]string), make(map[string]string)),
+			),
+			Nodes: []*v1.Node{
+				util.BuildNode("n1", api.BuildResourceList("10", "100G", []api.ScalarResource{{Name: "pods", Value: "100"}}...), make(map[string]string)),
+			},
+			Queues: []*schedulingv1beta1.Queue{
+				util.BuildQueue("q1", 1, nil),
+				util.BuildQueue("q2", 1, nil),
+				util.BuildQueue("q3", 1, nil),
+			},
+			// q1 (5 of 3.33 deserved cpus) is more overused than q2 (4.5 of 3.33), but only until its first
+			// victim is evicted, so the rest of the 2 cpus are taken from q2
+			ExpectEvictNum: 3,
+			ExpectEvicted:  []string{"c1/q1-task-2", "c1/q2-task-3", "c1/q2-task-2"},
+		},
 	}
 
 	reclaim := New()
@@ -168,3 +231,225 @@ func TestReclaim(t *testing.T) {
 		})
 	}
 }
+
+func TestReclaimRateLimited(t *testing.T) {
+	test := uthelper.TestCommonStruct{
+		Name: "evictions which do not reclaim enough for the task are rolled back",
+		Plugins: map[string]framework.PluginBuilder{
+			conformance.PluginName: conformance.New,
+			gang.PluginName:        gang.New,
+			proportion.PluginName:  proportion.New,
+		},
+		PodGroups: []*schedulingv1beta1.PodGroup{
+			util.BuildPodGroupWithPrio("pg1", "c1", "q1", 0, nil, schedulingv1beta1.PodGroupRunning, ""),
+			util.BuildPodGroupWithPrio("pg2", "c1", "q2", 0, nil, schedulingv1beta1.PodGroupRunning, ""),
+			util.BuildPodGroupWithPrio("pg3", "c1", "q3", 0, nil, schedulingv1beta1.PodGroupInqueue, ""),
+		},
+		Pods: append(append(
+			buildRunningPods("q1-task", 5, "1", "1G", "pg1"),
+			buildRunningPods("q2-task", 9, "500m", "500M", "pg2")...),
+			util.BuildPod("c1", "q3-task-1", "", v1.PodPending, api.BuildResourceList("2", "2G"), "pg3", make(map[string]string), make(map[string]string)),
+		),
+		Nodes: []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("10", "100G", []api.ScalarResource{{Name: "pods", Value: "100"}}...), make(map[string]string)),
+		},
+		Queues: []*schedulingv1beta1.Queue{
+			util.BuildQueue("q1", 1, nil),
+			util.BuildQueue("q2", 1, nil),
+			util.BuildQueue("q3", 1, nil),
+		},
+		// the 2 evictions allowed in the cycle only reclaim 1.5 of the 2 cpus
+		ExpectEvictNum: 0,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               conformance.PluginName,
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               gang.PluginName,
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               proportion.PluginName,
+					EnabledReclaimable: &trueValue,
+					EnabledQueueOrder:  &trueValue,
+				},
+			},
+		},
+	}
+	configurations := []conf.Configuration{{
+		Name:      "reclaim",
+		Arguments: map[string]interface{}{conf.MaxEvictionsPerCycleKey: 2},
+	}}
+	test.RegisterSession(tiers, configurations)
+	defer test.Close()
+	test.Run([]framework.Action{New()})
+	if err := test.CheckAll(0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReclaimDryRunReport(t *testing.T) {
+	pg2 := util.BuildPodGroupWithPrio("pg2", "c1", "q2", 0, nil, schedulingv1beta1.PodGroupInqueue, "")
+	pg2.Annotations = map[string]string{api.JobPreemptionPolicy: api.PreemptionPolicyDryRun}
+	running := func(name, node, preemptable string) *v1.Pod {
+		return util.BuildPod("c1", name, node, v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1",
+			map[string]string{schedulingv1beta1.PodPreemptable: preemptable}, make(map[string]string))
+	}
+	test := uthelper.TestCommonStruct{
+		Name: "the victims of a dry-run job are reported across the nodes",
+		Plugins: map[string]framework.PluginBuilder{
+			conformance.PluginName: conformance.New,
+			gang.PluginName:        gang.New,
+			proportion.PluginName:  proportion.New,
+		},
+		PodGroups: []*schedulingv1beta1.PodGroup{
+			util.BuildPodGroupWithPrio("pg1", "c1", "q1", 0, nil, schedulingv1beta1.PodGroupRunning, ""),
+			pg2,
+		},
+		// only one task of q1 can be reclaimed on each node
+		Pods: []*v1.Pod{
+			running("q1-task-1", "n1", "true"),
+			running("q1-task-2", "n1", "false"),
+			running("q1-task-3", "n2", "true"),
+			running("q1-task-4", "n2", "false"),
+			util.BuildPod("c1", "q2-task-1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "q2-task-2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+		},
+		Nodes: []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("2", "2G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+			util.BuildNode("n2", api.BuildResourceList("2", "2G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+		},
+		Queues: []*schedulingv1beta1.Queue{
+			util.BuildQueue("q1", 1, nil),
+			util.BuildQueue("q2", 1, nil),
+		},
+		ExpectEvictNum: 0,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               conformance.PluginName,
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               gang.PluginName,
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               proportion.PluginName,
+					EnabledReclaimable: &trueValue,
+					EnabledQueueOrder:  &trueValue,
+				},
+			},
+		},
+	}
+	ssn := test.RegisterSession(tiers, nil)
+	defer test.Close()
+	test.Run([]framework.Action{New()})
+	if err := test.CheckAll(0); err != nil {
+		t.Fatal(err)
+	}
+	report := ""
+	for _, c := range ssn.Jobs["c1/pg2"].PodGroup.Status.Conditions {
+		if c.Type == api.PodGroupPreemptionDryRunType {
+			report = c.Message
+		}
+	}
+	expected := "reclaim would evict 2 tasks: c1/q1-task-1 on n1, c1/q1-task-3 on n2"
+	if report != expected {
+		t.Errorf("expected the dry-run report %q, got %q", expected, report)
+	}
+}
+
+func TestReclaimAfterDryRun(t *testing.T) {
+	pg2 := util.BuildPodGroupWithPrio("pg2", "c1", "q2", 1, nil, schedulingv1beta1.PodGroupInqueue, "")
+	pg2.Annotations = map[string]string{api.JobPreemptionPolicy: api.PreemptionPolicyDryRun}
+	test := uthelper.TestCommonStruct{
+		Name: "the evictions of a dry-run job are discarded before the next job reclaims",
+		Plugins: map[string]framework.PluginBuilder{
+			conformance.PluginName: conformance.New,
+			gang.PluginName:        gang.New,
+			proportion.PluginName:  proportion.New,
+		},
+		PodGroups: []*schedulingv1beta1.PodGroup{
+			util.BuildPodGroupWithPrio("pg1", "c1", "q1", 0, nil, schedulingv1beta1.PodGroupRunning, ""),
+			pg2,
+			util.BuildPodGroupWithPrio("pg3", "c1", "q3", 1, nil, schedulingv1beta1.PodGroupInqueue, ""),
+		},
+		// only one task of q1 can be reclaimed, the dry-run job of q2 is considered first
+		Pods: []*v1.Pod{
+			util.BuildPod("c1", "q1-task-1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1",
+				map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)),
+			util.BuildPod("c1", "q1-task-2", "n1", v1.PodRunning, api.BuildResourceList("3", "3G"), "pg1",
+				map[string]string{schedulingv1beta1.PodPreemptable: "false"}, make(map[string]string)),
+			util.BuildPod("c1", "q2-task-1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "q3-task-1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg3", make(map[string]string), make(map[string]string)),
+		},
+		Nodes: []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("4", "4G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+		},
+		Queues: []*schedulingv1beta1.Queue{
+			util.BuildQueue("q1", 1, nil),
+			util.BuildQueue("q2", 1, nil),
+			util.BuildQueue("q3", 1, nil),
+		},
+		ExpectEvicted:  []string{"c1/q1-task-1"},
+		ExpectEvictNum: 1,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               conformance.PluginName,
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               gang.PluginName,
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               proportion.PluginName,
+					EnabledReclaimable: &trueValue,
+					EnabledQueueOrder:  &trueValue,
+					EnabledAllocatable: &trueValue,
+				},
+			},
+		},
+	}
+	ssn := test.RegisterSession(tiers, nil)
+	defer test.Close()
+	test.Run([]framework.Action{New()})
+	if err := test.CheckAll(0); err != nil {
+		t.Fatal(err)
+	}
+	report := ""
+	for _, c := range ssn.Jobs["c1/pg2"].PodGroup.Status.Conditions {
+		if c.Type == api.PodGroupPreemptionDryRunType {
+			report = c.Message
+		}
+	}
+	expected := "reclaim would evict 1 tasks: c1/q1-task-1 on n1"
+	if report != expected {
+		t.Errorf("expected the dry-run report %q, got %q", expected, report)
+	}
+}
+
+func buildRunningPods(prefix string, num int, cpu, memory, group string) []*v1.Pod {
+	pods := make([]*v1.Pod, 0, num)
+	for i := 1; i <= num; i++ {
+		pods = append(pods, util.BuildPod("c1", fmt.Sprintf("%s-%d", prefix, i), "n1", v1.PodRunning, api.BuildResourceList(cpu, memory), group,
+			map[string]string{schedulingv1beta1.PodPreemptable: "true"}, make(map[string]string)))
+	}
+	return pods
+}
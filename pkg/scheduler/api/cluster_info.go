@@ -18,6 +18,11 @@ package api
 
 import (
 	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // ClusterInfo is a snapshot of cluster by cache.
@@ -29,6 +34,54 @@ type ClusterInfo struct {
 	RevocableNodes map[string]*NodeInfo
 	NodeList       []string
 	CSINodesStatus map[string]*CSINodeStatusInfo
+	// MaintenanceWindows are the maintenance windows in progress, their nodes are not in Nodes
+	MaintenanceWindows []*MaintenanceWindow
+	// Reservations are the Reservations in progress
+	Reservations []*Reservation
+	// ResourceFlavors are the ResourceFlavors by name
+	ResourceFlavors map[string]*ResourceFlavor
+	// QuarantinedNodes are the ends of the quarantines of the nodes quarantined for their repeated
+	// bind or admission failures, by node name; the nodes are not in Nodes
+	QuarantinedNodes map[string]time.Time
+	// Totals are the totals of Nodes and of the jobs of the cache by queue, nil if not computed
+	Totals *ClusterTotals
+}
+
+// MaintenanceWindow is a maintenance in progress of queues and nodes.
+type MaintenanceWindow struct {
+	Name string
+	// Queues are the names of the queues which admit no new job
+	Queues sets.Set[string]
+	// Nodes are the names of the nodes which take no new task
+	Nodes sets.Set[string]
+	// Drain evicts the running tasks of the queues and on the nodes
+	Drain bool
+}
+
+// CoversTask returns whether the task belongs to a queue or runs on a node of the window.
+func (mw *MaintenanceWindow) CoversTask(queue string, task *TaskInfo) bool {
+	return mw.Queues.Has(queue) || mw.Nodes.Has(task.NodeName)
+}
+
+// Reservation is a block of capacity withheld for a queue while it is in progress.
+type Reservation struct {
+	Name  string
+	Queue QueueID
+	// NodeSelector selects the nodes the capacity is withheld on, the nodes with taints not
+	// tolerated by Tolerations are left out
+	NodeSelector labels.Selector
+	Tolerations  []v1.Toleration
+	Resources    v1.ResourceList
+}
+
+// ResourceFlavor is a kind of nodes, e.g. those of a GPU model, jobs may ask to run on.
+type ResourceFlavor struct {
+	Name string
+	// NodeLabels selects the nodes of the flavor
+	NodeLabels labels.Selector
+	// NodeTaints are the taints the nodes of the flavor carry, the jobs which do not
+	// tolerate them cannot run on the flavor
+	NodeTaints []v1.Taint
 }
 
 func (ci ClusterInfo) String() string {
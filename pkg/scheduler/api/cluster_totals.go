@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ClusterTotals are the sums of the resources of the ready nodes, kept up to date by the cache
+// as its nodes change, so that the sessions and plugins do not sum them up over all the nodes.
+type ClusterTotals struct {
+	Allocatable *Resource
+	Used        *Resource
+	Idle        *Resource
+
+	// nodes are what each node adds to the totals, nil in the copies
+	nodes map[string]*nodeTotals
+}
+
+type nodeTotals struct {
+	allocatable, used, idle *Resource
+}
+
+// NewClusterTotals returns the totals of an empty cluster.
+func NewClusterTotals() *ClusterTotals {
+	return &ClusterTotals{
+		Allocatable: EmptyResource(),
+		Used:        EmptyResource(),
+		Idle:        EmptyResource(),
+		nodes:       map[string]*nodeTotals{},
+	}
+}
+
+// UpdateNode replaces what the node adds to the totals by its current resources; a nil or not
+// ready node adds nothing.
+func (t *ClusterTotals) UpdateNode(name string, node *NodeInfo) {
+	if old, found := t.nodes[name]; found {
+		t.Allocatable.sub(old.allocatable)
+		t.Used.sub(old.used)
+		t.Idle.sub(old.idle)
+		delete(t.nodes, name)
+	}
+	if node == nil || !node.Ready() {
+		return
+	}
+	contribution := &nodeTotals{
+		allocatable: node.Allocatable.Clone(),
+		used:        node.Used.Clone(),
+		idle:        node.Idle.Clone(),
+	}
+	t.Allocatable.Add(contribution.allocatable)
+	t.Used.Add(contribution.used)
+	t.Idle.Add(contribution.idle)
+	t.nodes[name] = contribution
+}
+
+// ExcludeNode removes the node from the totals of a copy, for the nodes left out of a snapshot.
+func (t *ClusterTotals) ExcludeNode(node *NodeInfo) {
+	t.Allocatable.sub(node.Allocatable)
+	t.Used.sub(node.Used)
+	t.Idle.sub(node.Idle)
+}
+
+// Reserve moves the resources from the idle to the used ones of a copy, for the resources
+// reserved on the nodes of a snapshot.
+func (t *ClusterTotals) Reserve(reserved *Resource) {
+	t.Used.Add(reserved)
+	t.Idle.sub(reserved)
+}
+
+// Clone returns a copy of the totals, which is not updated by the nodes.
+func (t *ClusterTotals) Clone() *ClusterTotals {
+	return &ClusterTotals{
+		Allocatable: t.Allocatable.Clone(),
+		Used:        t.Used.Clone(),
+		Idle:        t.Idle.Clone(),
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterTotals(t *testing.T) {
+	totals := NewClusterTotals()
+	n1 := NewNodeInfo(buildNode("n1", BuildResourceList("8", "16G")))
+	n2 := NewNodeInfo(buildNode("n2", BuildResourceList("4", "8G")))
+	totals.UpdateNode("n1", n1)
+	totals.UpdateNode("n2", n2)
+	if totals.Allocatable.MilliCPU != 12000 || totals.Allocatable.Memory != 24e9 {
+		t.Errorf("expected 12 cpus and 24G allocatable, got %v", totals.Allocatable)
+	}
+
+	owner := buildOwnerReference("uid")
+	task := NewTaskInfo(buildPod("c1", "p1", "n1", v1.PodRunning, BuildResourceList("2", "4G"), []metav1.OwnerReference{owner}, nil))
+	if err := n1.AddTask(task); err != nil {
+		t.Fatal(err)
+	}
+	totals.UpdateNode("n1", n1)
+	if cpu := totals.Used.MilliCPU; cpu != 2000 {
+		t.Errorf("expected 2 cpus used, got %v", cpu)
+	}
+	if cpu := totals.Idle.MilliCPU; cpu != 10000 {
+		t.Errorf("expected 10 idle cpus, got %v", cpu)
+	}
+
+	// the copies are not updated
+	copied := totals.Clone()
+	totals.UpdateNode("n2", nil)
+	if totals.Allocatable.MilliCPU != 8000 || copied.Allocatable.MilliCPU != 12000 {
+		t.Errorf("expected 8 cpus allocatable and 12 in the copy, got %v and %v",
+			totals.Allocatable.MilliCPU, copied.Allocatable.MilliCPU)
+	}
+}
@@ -0,0 +1,354 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api/devices"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
+)
+
+// NICDevice is an RDMA HCA or an SR-IOV capable NIC of a node.
+type NICDevice struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// NUMA node the NIC is attached to
+	NUMA int `json:"numa"`
+	// VFs is the number of virtual functions of an SR-IOV NIC
+	VFs int `json:"vfs,omitempty"`
+
+	// Used is the number of allocated virtual functions, 1 for an allocated RDMA HCA
+	Used int `json:"-"`
+	// PodMap is the number of virtual functions or HCAs allocated to each pod
+	PodMap map[string]int `json:"-"`
+}
+
+func (d *NICDevice) free() int {
+	if d.Type == RDMAType {
+		return 1 - d.Used
+	}
+	return d.VFs - d.Used
+}
+
+type NICDevices struct {
+	Name string
+
+	Device map[string]*NICDevice
+	// GPUs are the gpu cards of the node, the NICs are allocated on the NUMA node of the cards
+	// allocated to the same pod
+	GPUs *gpushare.GPUDevices
+}
+
+// nicRequest is the NICs requested by a pod
+type nicRequest struct {
+	rdma int
+	vfs  int
+}
+
+func NewNICDevices(name string, node *v1.Node, gpus *gpushare.GPUDevices) *NICDevices {
+	if node == nil {
+		return nil
+	}
+	value, ok := node.Annotations[NICRegister]
+	if !ok {
+		return nil
+	}
+	var nics []*NICDevice
+	if err := json.Unmarshal([]byte(value), &nics); err != nil {
+		klog.Warningf("Failed to parse %s of node %s: %v", NICRegister, name, err)
+		return nil
+	}
+	nicDevices := &NICDevices{
+		Name:   name,
+		Device: make(map[string]*NICDevice, len(nics)),
+		GPUs:   gpus,
+	}
+	for _, nic := range nics {
+		if nic.Type != RDMAType && nic.Type != SRIOVType {
+			klog.Warningf("Unknown type %s of NIC %s on node %s", nic.Type, nic.ID, name)
+			continue
+		}
+		nic.PodMap = map[string]int{}
+		nicDevices.Device[nic.ID] = nic
+	}
+	if len(nicDevices.Device) == 0 {
+		return nil
+	}
+	if gpus != nil {
+		gpus.AlignedNUMA = nicDevices.alignedNUMA
+	}
+	return nicDevices
+}
+
+// GetIgnoredDevices return device names which wish vc-scheduler to ignore, only the resources of
+// the NIC types registered on the node are ignored
+func (ns *NICDevices) GetIgnoredDevices() []string {
+	if !NICEnable || ns == nil {
+		return nil
+	}
+	var rdma, sriov bool
+	for _, dev := range ns.Device {
+		switch dev.Type {
+		case RDMAType:
+			rdma = true
+		case SRIOVType:
+			sriov = true
+		}
+	}
+	var ignored []string
+	if rdma {
+		ignored = append(ignored, RDMAResource)
+	}
+	if sriov {
+		ignored = append(ignored, SRIOVResource)
+	}
+	return ignored
+}
+
+// AddResource adds the pod to the NIC pool if it is assigned
+func (ns *NICDevices) AddResource(pod *v1.Pod) {
+	if ns == nil {
+		return
+	}
+	for _, id := range getAssignedNICs(pod) {
+		if dev, ok := ns.Device[id]; ok {
+			dev.Used++
+			dev.PodMap[string(pod.UID)]++
+		}
+	}
+}
+
+// SubResource frees the NICs hold by the pod
+func (ns *NICDevices) SubResource(pod *v1.Pod) {
+	if ns == nil {
+		return
+	}
+	for _, id := range getAssignedNICs(pod) {
+		if dev, ok := ns.Device[id]; ok && dev.PodMap[string(pod.UID)] > 0 {
+			dev.Used--
+			dev.PodMap[string(pod.UID)]--
+			if dev.PodMap[string(pod.UID)] == 0 {
+				delete(dev.PodMap, string(pod.UID))
+			}
+		}
+	}
+}
+
+func (ns *NICDevices) HasDeviceRequest(pod *v1.Pod) bool {
+	if !NICEnable {
+		return false
+	}
+	req := getNICRequestOfPod(pod)
+	return req.rdma > 0 || req.vfs > 0
+}
+
+func (ns *NICDevices) FilterNode(pod *v1.Pod, schedulePolicy string) (int, string, error) {
+	if ns == nil {
+		return devices.Unschedulable, "no NIC on the node", errors.New("no NIC on the node")
+	}
+	if _, ids := ns.selectNICs(pod); ids == nil {
+		err := fmt.Errorf("no NUMA node of node %s has enough NICs aligned with the gpus", ns.Name)
+		return devices.Unschedulable, fmt.Sprintf("NIC %s", err.Error()), err
+	}
+	return devices.Success, "", nil
+}
+
+func (ns *NICDevices) ScoreNode(pod *v1.Pod, schedulePolicy string) float64 {
+	return 0
+}
+
+func (ns *NICDevices) Allocate(kubeClient kubernetes.Interface, pod *v1.Pod) error {
+	if ns == nil {
+		return errors.Errorf("no NIC on node %s", pod.Spec.NodeName)
+	}
+	numa, ids := ns.selectNICs(pod)
+	if ids == nil {
+		return errors.Errorf("the node %s can't place the NICs of pod %s in ns %s", ns.Name, pod.Name, pod.Namespace)
+	}
+	patch := fmt.Sprintf(`[{"op": "add", "path": "/metadata/annotations/%s", "value": "%s"}]`,
+		escapeJSONPointer(AssignedNICs), strings.Join(ids, ","))
+	if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return errors.Errorf("patch pod %s failed with patch %s: %v", pod.Name, patch, err)
+	}
+	for _, id := range ids {
+		dev := ns.Device[id]
+		dev.Used++
+		dev.PodMap[string(pod.UID)]++
+	}
+	klog.V(4).Infof("Allocated NICs %v on NUMA node %d of node %s to pod %s/%s", ids, numa, ns.Name, pod.Namespace, pod.Name)
+	return nil
+}
+
+func (ns *NICDevices) Release(kubeClient kubernetes.Interface, pod *v1.Pod) error {
+	if ns == nil {
+		return nil
+	}
+	patch := fmt.Sprintf(`[{"op": "remove", "path": "/metadata/annotations/%s"}]`, escapeJSONPointer(AssignedNICs))
+	if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return errors.Errorf("patch pod %s failed with patch %s: %v", pod.Name, patch, err)
+	}
+	for _, dev := range ns.Device {
+		if used, found := dev.PodMap[string(pod.UID)]; found {
+			dev.Used -= used
+			delete(dev.PodMap, string(pod.UID))
+		}
+	}
+	klog.V(4).Infof("Released NICs of pod %s/%s on node %s", pod.Namespace, pod.Name, ns.Name)
+	return nil
+}
+
+func (ns *NICDevices) GetStatus() string {
+	return ""
+}
+
+// candidateNUMA returns the NUMA nodes the NICs of the pod may be allocated on: the ones of the
+// gpus already allocated to the pod, else the ones with enough idle gpus for the pod, else all.
+func (ns *NICDevices) candidateNUMA(pod *v1.Pod) []int {
+	if numa := ns.GPUs.NUMAOfPod(pod); len(numa) != 0 {
+		return uniqueSorted(numa)
+	}
+
+	var numa []int
+	if gpuRequest := gpushare.GetGPUNumberOfPod(pod); gpuRequest > 0 {
+		for n, idle := range ns.GPUs.IdleGPUsByNUMA() {
+			if n >= 0 && idle >= gpuRequest {
+				numa = append(numa, n)
+			}
+		}
+		if len(numa) != 0 {
+			return uniqueSorted(numa)
+		}
+	}
+	for _, dev := range ns.Device {
+		numa = append(numa, dev.NUMA)
+	}
+	return uniqueSorted(numa)
+}
+
+// selectNICs returns the ids of the NICs to allocate to the pod, with an id repeated for each
+// of its virtual functions, all on the returned NUMA node.
+func (ns *NICDevices) selectNICs(pod *v1.Pod) (int, []string) {
+	req := getNICRequestOfPod(pod)
+	for _, numa := range ns.candidateNUMA(pod) {
+		if ids := ns.nicsOnNUMA(req, numa); ids != nil {
+			return numa, ids
+		}
+	}
+	return -1, nil
+}
+
+// alignedNUMA returns the NUMA nodes with enough free NICs for the pod, on which its gpu cards
+// are to be allocated, nil if the pod requests no NIC.
+func (ns *NICDevices) alignedNUMA(pod *v1.Pod) []int {
+	if !ns.HasDeviceRequest(pod) {
+		return nil
+	}
+	req := getNICRequestOfPod(pod)
+	numa := []int{}
+	for _, dev := range ns.Device {
+		numa = append(numa, dev.NUMA)
+	}
+	aligned := []int{}
+	for _, n := range uniqueSorted(numa) {
+		if ns.nicsOnNUMA(req, n) != nil {
+			aligned = append(aligned, n)
+		}
+	}
+	return aligned
+}
+
+// nicsOnNUMA returns the ids of the free NICs of the NUMA node fulfilling the request, nil if
+// there are not enough of them.
+func (ns *NICDevices) nicsOnNUMA(req nicRequest, numa int) []string {
+	var nics []*NICDevice
+	for _, dev := range ns.Device {
+		if dev.NUMA == numa && dev.free() > 0 {
+			nics = append(nics, dev)
+		}
+	}
+	sort.Slice(nics, func(i, j int) bool { return nics[i].ID < nics[j].ID })
+
+	ids := []string{}
+	rdma, vfs := req.rdma, req.vfs
+	for _, dev := range nics {
+		switch {
+		case dev.Type == RDMAType && rdma > 0:
+			ids = append(ids, dev.ID)
+			rdma--
+		case dev.Type == SRIOVType && vfs > 0:
+			for free := dev.free(); free > 0 && vfs > 0; free-- {
+				ids = append(ids, dev.ID)
+				vfs--
+			}
+		}
+	}
+	if rdma > 0 || vfs > 0 {
+		return nil
+	}
+	return ids
+}
+
+func getNICRequestOfPod(pod *v1.Pod) nicRequest {
+	req := nicRequest{}
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Limits[RDMAResource]; ok {
+			req.rdma += int(q.Value())
+		}
+		if q, ok := c.Resources.Limits[SRIOVResource]; ok {
+			req.vfs += int(q.Value())
+		}
+	}
+	return req
+}
+
+func getAssignedNICs(pod *v1.Pod) []string {
+	value, found := pod.Annotations[AssignedNICs]
+	if !found || len(value) == 0 {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func uniqueSorted(values []int) []int {
+	sort.Ints(values)
+	var res []int
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func escapeJSONPointer(p string) string {
+	// Escaping reference name using https://tools.ietf.org/html/rfc6901
+	p = strings.Replace(p, "~", "~0", -1)
+	p = strings.Replace(p, "/", "~1", -1)
+	return p
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nic
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"volcano.sh/volcano/pkg/scheduler/api/devices"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
+)
+
+func buildNICPod(name string, gpus, rdma, vfs int64) *v1.Pod {
+	limits := v1.ResourceList{}
+	if gpus > 0 {
+		limits[gpushare.VolcanoGPUNumber] = *resource.NewQuantity(gpus, resource.DecimalSI)
+	}
+	if rdma > 0 {
+		limits[RDMAResource] = *resource.NewQuantity(rdma, resource.DecimalSI)
+	}
+	if vfs > 0 {
+		limits[SRIOVResource] = *resource.NewQuantity(vfs, resource.DecimalSI)
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Resources: v1.ResourceRequirements{Limits: limits}}},
+		},
+	}
+}
+
+func TestSelectNICs(t *testing.T) {
+	NICEnable = true
+	defer func() { NICEnable = false }()
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "n1",
+			Annotations: map[string]string{
+				gpushare.GPUNUMA: "0,0,1,1",
+				NICRegister: `[{"id":"mlx5_0","type":"rdma","numa":0},{"id":"mlx5_1","type":"rdma","numa":1},` +
+					`{"id":"ens1","type":"sriov","numa":0,"vfs":4}]`,
+			},
+		},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				gpushare.VolcanoGPUResource: resource.MustParse("4000"),
+				gpushare.VolcanoGPUNumber:   resource.MustParse("4"),
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		pod      *v1.Pod
+		wantCode int
+		wantNUMA int
+		wantIDs  []string
+	}{
+		{
+			name:     "rdma on the NUMA node with enough gpus and a free HCA",
+			pod:      buildNICPod("p1", 2, 1, 0),
+			wantCode: devices.Success,
+			wantNUMA: 1,
+			wantIDs:  []string{"mlx5_1"},
+		},
+		{
+			name:     "virtual functions of the same NIC",
+			pod:      buildNICPod("p2", 0, 0, 3),
+			wantCode: devices.Success,
+			wantNUMA: 0,
+			wantIDs:  []string{"ens1", "ens1", "ens1"},
+		},
+		{
+			name:     "no NUMA node has two free HCAs",
+			pod:      buildNICPod("p3", 0, 2, 0),
+			wantCode: devices.Unschedulable,
+			wantNUMA: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gpus := gpushare.NewGPUDevices("n1", node)
+			nics := NewNICDevices("n1", node, gpus)
+			used := buildNICPod("used", 0, 1, 0)
+			used.Annotations = map[string]string{AssignedNICs: "mlx5_0"}
+			nics.AddResource(used)
+
+			code, _, _ := nics.FilterNode(tc.pod, "")
+			if code != tc.wantCode {
+				t.Errorf("expected code %d, got %d", tc.wantCode, code)
+			}
+			numa, ids := nics.selectNICs(tc.pod)
+			if numa != tc.wantNUMA || !reflect.DeepEqual(ids, tc.wantIDs) {
+				t.Errorf("expected %v on NUMA node %d, got %v on %d", tc.wantIDs, tc.wantNUMA, ids, numa)
+			}
+		})
+	}
+}
+
+func TestAlignedNUMA(t *testing.T) {
+	NICEnable = true
+	defer func() { NICEnable = false }()
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "n1",
+			Annotations: map[string]string{
+				gpushare.GPUNUMA: "0,0,1,1",
+				NICRegister:      `[{"id":"mlx5_0","type":"rdma","numa":0},{"id":"mlx5_1","type":"rdma","numa":1}]`,
+			},
+		},
+	}
+	gpus := &gpushare.GPUDevices{Name: "n1", Device: map[int]*gpushare.GPUDevice{}}
+	nics := NewNICDevices("n1", node, gpus)
+	used := buildNICPod("used", 0, 1, 0)
+	used.Annotations = map[string]string{AssignedNICs: "mlx5_1"}
+	nics.AddResource(used)
+
+	if gpus.AlignedNUMA == nil {
+		t.Fatal("expected the NICs to constrain the NUMA node of the gpus")
+	}
+	if numa := gpus.AlignedNUMA(buildNICPod("p1", 2, 0, 0)); numa != nil {
+		t.Errorf("expected no constraint for a pod without NIC, got %v", numa)
+	}
+	if numa := gpus.AlignedNUMA(buildNICPod("p2", 2, 1, 0)); !reflect.DeepEqual(numa, []int{0}) {
+		t.Errorf("expected NUMA node 0, got %v", numa)
+	}
+}
+
+func TestGetIgnoredDevices(t *testing.T) {
+	NICEnable = true
+	defer func() { NICEnable = false }()
+
+	testCases := []struct {
+		name     string
+		register string
+		want     []string
+	}{
+		{
+			name:     "rdma only",
+			register: `[{"id":"mlx5_0","type":"rdma","numa":0}]`,
+			want:     []string{RDMAResource},
+		},
+		{
+			name:     "rdma and sriov",
+			register: `[{"id":"mlx5_0","type":"rdma","numa":0},{"id":"ens1","type":"sriov","numa":0,"vfs":4}]`,
+			want:     []string{RDMAResource, SRIOVResource},
+		},
+		{
+			name: "no NIC registered",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1", Annotations: map[string]string{}}}
+			if tc.register != "" {
+				node.Annotations[NICRegister] = tc.register
+			}
+			nics := NewNICDevices("n1", node, nil)
+			if got := nics.GetIgnoredDevices(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
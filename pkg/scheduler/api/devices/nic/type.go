@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nic
+
+var NICEnable bool
+
+const (
+	// DeviceName used to indicate this device
+	DeviceName = "nic"
+
+	// NICRegister is the node annotation holding, as a JSON list of NICDevice, the RDMA HCAs and
+	// SR-IOV capable NICs of the node reported by the node agent
+	NICRegister = "volcano.sh/node-nic-register"
+	// AssignedNICs is the pod annotation listing, comma separated, the ids of the NICs allocated
+	// to the pod, read by the device plugin
+	AssignedNICs = "volcano.sh/nic-ids"
+
+	// RDMAResource is the number of whole RDMA HCAs requested by a container
+	RDMAResource = "volcano.sh/rdma"
+	// SRIOVResource is the number of SR-IOV virtual functions requested by a container
+	SRIOVResource = "volcano.sh/sriov-vf"
+
+	// RDMAType is the type of an RDMA HCA, allocated exclusively
+	RDMAType = "rdma"
+	// SRIOVType is the type of an SR-IOV NIC, whose virtual functions are allocated
+	SRIOVType = "sriov"
+)
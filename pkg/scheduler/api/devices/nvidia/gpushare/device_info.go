@@ -39,12 +39,19 @@ type GPUDevice struct {
 	PodMap map[string]*v1.Pod
 	// memory per card
 	Memory uint
+	// NUMA node the card is attached to, -1 if unknown
+	NUMA int
 }
 
 type GPUDevices struct {
 	Name string
 
 	Device map[int]*GPUDevice
+	// Topology is the link distance between each pair of cards, nil if not reported
+	Topology [][]int
+	// AlignedNUMA is set by the devices allocated along with the cards, it returns the NUMA nodes
+	// one of which must hold all the cards of the pod, nil if the cards may be anywhere
+	AlignedNUMA func(pod *v1.Pod) []int
 }
 
 // NewGPUDevice creates a device
@@ -53,6 +60,7 @@ func NewGPUDevice(id int, mem uint) *GPUDevice {
 		ID:     id,
 		Memory: mem,
 		PodMap: map[string]*v1.Pod{},
+		NUMA:   -1,
 	}
 }
 
@@ -88,6 +96,8 @@ func NewGPUDevices(name string, node *v1.Node) *GPUDevices {
 		klog.V(4).Infof("delete unhealthy gpu id %d from GPUDevices", unhealthyGPUs[i])
 		delete(gpudevices.Device, unhealthyGPUs[i])
 	}
+	gpudevices.Topology = getGPUTopology(node, int(gpuNumber))
+	setGPUNUMA(&gpudevices, node)
 	return &gpudevices
 }
 
@@ -17,12 +17,16 @@ limitations under the License.
 package gpushare
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+var restartAlways = v1.ContainerRestartPolicyAlways
+
 func TestGetGPUMemoryOfPod(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -86,6 +90,41 @@ func TestGetGPUMemoryOfPod(t *testing.T) {
 			},
 			want: 3,
 		},
+		{
+			name: "GPUs required by a sidecar running with the Containers",
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{
+							RestartPolicy: &restartAlways,
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{
+									VolcanoGPUResource: resource.MustParse("1"),
+								},
+							},
+						},
+						{
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{
+									VolcanoGPUResource: resource.MustParse("4"),
+								},
+							},
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{
+									VolcanoGPUResource: resource.MustParse("2"),
+								},
+							},
+						},
+					},
+				},
+			},
+			// the second init container runs with the sidecar: max(1+4, 2+1)
+			want: 5,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -172,3 +211,41 @@ func TestGetGPUNumberOfPod(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectGPUsByTopology(t *testing.T) {
+	// gpu 0-1 and 2-3 are linked by NVLink, the pairs are connected across the CPU interconnect
+	topology := [][]int{
+		{0, 1, 3, 3},
+		{1, 0, 3, 3},
+		{3, 3, 0, 1},
+		{3, 3, 1, 0},
+	}
+	testCases := []struct {
+		name       string
+		candidates []int
+		request    int
+		want       []int
+	}{
+		{
+			name:       "pick the NVLink pair",
+			candidates: []int{1, 2, 3},
+			request:    2,
+			want:       []int{2, 3},
+		},
+		{
+			name:       "pick the closest cards when no pair is free",
+			candidates: []int{0, 2, 3},
+			request:    3,
+			want:       []int{0, 2, 3},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := selectGPUsByTopology(tc.candidates, tc.request, topology)
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
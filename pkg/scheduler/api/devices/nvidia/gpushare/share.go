@@ -170,6 +170,18 @@ func predicateGPUbyNumber(pod *v1.Pod, gs *GPUDevices) []int {
 		return nil
 	}
 
+	sort.Ints(allocatableGPUs)
+	if gs.AlignedNUMA != nil {
+		if numa := gs.AlignedNUMA(pod); numa != nil {
+			if allocatableGPUs = selectGPUsOnNUMA(gs, allocatableGPUs, gpuRequest, numa); allocatableGPUs == nil {
+				klog.Errorf("Not enough gpu cards on the NUMA nodes %v", numa)
+				return nil
+			}
+		}
+	}
+	if gpuRequest > 1 && gs.Topology != nil {
+		return selectGPUsByTopology(allocatableGPUs, gpuRequest, gs.Topology)
+	}
 	return allocatableGPUs[:gpuRequest]
 }
 
@@ -214,25 +226,16 @@ func (g *GPUDevice) isIdleGPU() bool {
 	return g.PodMap == nil || len(g.PodMap) == 0
 }
 
+// GetGPUMemoryOfPod returns the GPU memory required by the pod.
+func GetGPUMemoryOfPod(pod *v1.Pod) uint {
+	return getGPUMemoryOfPod(pod)
+}
+
 // getGPUMemoryPod returns the GPU memory required by the pod.
 func getGPUMemoryOfPod(pod *v1.Pod) uint {
-	var initMem uint
-	for _, container := range pod.Spec.InitContainers {
-		res := getGPUMemoryOfContainer(container.Resources)
-		if initMem < res {
-			initMem = res
-		}
-	}
-
-	var mem uint
-	for _, container := range pod.Spec.Containers {
-		mem += getGPUMemoryOfContainer(container.Resources)
-	}
-
-	if mem > initMem {
-		return mem
-	}
-	return initMem
+	return uint(podDeviceRequest(pod, func(resources v1.ResourceRequirements) int {
+		return int(getGPUMemoryOfContainer(resources))
+	}))
 }
 
 // getGPUMemoryOfContainer returns the GPU memory required by the container.
@@ -246,23 +249,38 @@ func getGPUMemoryOfContainer(resources v1.ResourceRequirements) uint {
 
 // getGPUNumberOfPod returns the number of GPUs required by the pod.
 func getGPUNumberOfPod(pod *v1.Pod) int {
-	var gpus int
+	return podDeviceRequest(pod, getGPUNumberOfContainer)
+}
+
+// podDeviceRequest returns the largest amount of a device the pod uses at once, the same way
+// api.GetPodResourceRequest does for the resources: the regular containers run together with
+// the restartable init containers, the sidecars, while each other init container runs alone
+// with the sidecars started before it.
+func podDeviceRequest(pod *v1.Pod, containerRequest func(v1.ResourceRequirements) int) int {
+	var request int
 	for _, container := range pod.Spec.Containers {
-		gpus += getGPUNumberOfContainer(container.Resources)
+		request += containerRequest(container.Resources)
 	}
 
-	var initGPUs int
+	var sidecars, initRequest int
 	for _, container := range pod.Spec.InitContainers {
-		res := getGPUNumberOfContainer(container.Resources)
-		if initGPUs < res {
-			initGPUs = res
+		res := containerRequest(container.Resources)
+		if container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways {
+			request += res
+			sidecars += res
+			res = sidecars
+		} else {
+			res += sidecars
+		}
+		if initRequest < res {
+			initRequest = res
 		}
 	}
 
-	if gpus > initGPUs {
-		return gpus
+	if request > initRequest {
+		return request
 	}
-	return initGPUs
+	return initRequest
 }
 
 // getGPUNumberOfContainer returns the number of GPUs required by the container.
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gpushare
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// getGPUTopology returns the link distance matrix of the gpu cards of the node,
+// nil if it is not reported or does not match the number of cards.
+func getGPUTopology(node *v1.Node, gpuNumber int) [][]int {
+	value, found := node.Annotations[GPUTopology]
+	if !found {
+		return nil
+	}
+	var topology [][]int
+	if err := json.Unmarshal([]byte(value), &topology); err != nil {
+		klog.Warningf("Failed to parse %s of node %s: %v", GPUTopology, node.Name, err)
+		return nil
+	}
+	if len(topology) != gpuNumber {
+		klog.Warningf("Invalid %s of node %s: %d rows for %d gpus", GPUTopology, node.Name, len(topology), gpuNumber)
+		return nil
+	}
+	for _, row := range topology {
+		if len(row) != gpuNumber {
+			klog.Warningf("Invalid %s of node %s: row of %d columns for %d gpus", GPUTopology, node.Name, len(row), gpuNumber)
+			return nil
+		}
+	}
+	return topology
+}
+
+// selectGPUsByTopology picks request cards out of the sorted candidates so that the sum of
+// the link distances between the picked cards is minimal. Starting from each candidate, the
+// closest card to the picked ones is added greedily, and the best of these sets is returned.
+func selectGPUsByTopology(candidates []int, request int, topology [][]int) []int {
+	var best []int
+	bestCost := -1
+	for _, seed := range candidates {
+		picked := []int{seed}
+		cost := 0
+		for len(picked) < request {
+			next, nextCost := -1, 0
+			for _, id := range candidates {
+				if containsGPU(picked, id) {
+					continue
+				}
+				c := 0
+				for _, p := range picked {
+					c += topology[p][id]
+				}
+				if next == -1 || c < nextCost {
+					next, nextCost = id, c
+				}
+			}
+			picked = append(picked, next)
+			cost += nextCost
+		}
+		if bestCost == -1 || cost < bestCost {
+			best, bestCost = picked, cost
+		}
+	}
+	klog.V(4).Infof("Selected gpus %v with link distance %d", best, bestCost)
+	return best
+}
+
+func containsGPU(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// setGPUNUMA sets the NUMA node of the cards reported by the node.
+func setGPUNUMA(gs *GPUDevices, node *v1.Node) {
+	value, found := node.Annotations[GPUNUMA]
+	if !found {
+		return
+	}
+	for id, s := range strings.Split(value, ",") {
+		numa, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			klog.Warningf("Failed to parse %s of node %s: %v", GPUNUMA, node.Name, err)
+			return
+		}
+		if dev, ok := gs.Device[id]; ok {
+			dev.NUMA = numa
+		}
+	}
+}
+
+// IdleGPUsByNUMA returns the number of idle cards of each NUMA node, cards of unknown NUMA node are
+// counted under -1.
+func (gs *GPUDevices) IdleGPUsByNUMA() map[int]int {
+	idle := map[int]int{}
+	if gs == nil {
+		return idle
+	}
+	for _, dev := range gs.Device {
+		if dev.isIdleGPU() {
+			idle[dev.NUMA]++
+		}
+	}
+	return idle
+}
+
+// NUMAOfPod returns the NUMA nodes of the cards allocated to the pod.
+func (gs *GPUDevices) NUMAOfPod(pod *v1.Pod) []int {
+	if gs == nil {
+		return nil
+	}
+	var numa []int
+	for _, id := range sortedGPUIDs(gs) {
+		dev := gs.Device[id]
+		if _, found := dev.PodMap[string(pod.UID)]; found && dev.NUMA >= 0 {
+			numa = append(numa, dev.NUMA)
+		}
+	}
+	return numa
+}
+
+// selectGPUsOnNUMA returns the candidates on the first of the NUMA nodes holding at least request of them.
+func selectGPUsOnNUMA(gs *GPUDevices, candidates []int, request int, numa []int) []int {
+	for _, n := range numa {
+		var ids []int
+		for _, id := range candidates {
+			if gs.Device[id].NUMA == n {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) >= request {
+			return ids
+		}
+	}
+	return nil
+}
+
+func sortedGPUIDs(gs *GPUDevices) []int {
+	ids := make([]int, 0, len(gs.Device))
+	for id := range gs.Device {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// GetGPUNumberOfPod returns the number of whole cards requested by the pod.
+func GetGPUNumberOfPod(pod *v1.Pod) int {
+	return getGPUNumberOfPod(pod)
+}
@@ -33,4 +33,11 @@ const (
 
 	// UnhealthyGPUIDs list of unhealthy gpu ids
 	UnhealthyGPUIDs = "volcano.sh/gpu-unhealthy-ids"
+
+	// GPUTopology is the node annotation reported by the node agent holding, as a JSON matrix indexed
+	// by gpu id, the link distance between each pair of cards: the lower the closer, e.g. 1 for NVLink,
+	// 2 for cards under the same PCIe switch and 3 for cards across the CPU interconnect.
+	GPUTopology = "volcano.sh/gpu-topology"
+	// GPUNUMA is the node annotation listing, comma separated and ordered by gpu id, the NUMA node of each card
+	GPUNUMA = "volcano.sh/gpu-numa"
 )
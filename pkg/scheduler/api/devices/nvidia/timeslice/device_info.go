@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package timeslice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api/devices"
+)
+
+// GPUCard is one gpu card shared by time-slicing.
+type GPUCard struct {
+	Index int
+	// Slices is the number of slices the card is shared by, Used the ones allocated
+	Slices int
+	Used   int
+	// PodMap is the number of slices allocated to each pod
+	PodMap map[string]int
+}
+
+func (c *GPUCard) free() int {
+	return c.Slices - c.Used
+}
+
+// GPUCards are the time-sliced gpu cards of a node, the slices of a pod are all packed on one card.
+type GPUCards struct {
+	Name string
+
+	Cards []*GPUCard
+}
+
+// NewGPUCards returns the time-sliced cards of the node, from the slices it advertises and the
+// number of slices of each card.
+func NewGPUCards(name string, node *v1.Node) *GPUCards {
+	if node == nil {
+		return nil
+	}
+	value, found := node.Labels[ReplicasLabel]
+	if !found {
+		return nil
+	}
+	replicas, err := strconv.Atoi(value)
+	if err != nil || replicas <= 0 {
+		klog.Warningf("Invalid %s <%s> of node %s", ReplicasLabel, value, name)
+		return nil
+	}
+	slices, found := node.Status.Allocatable[SharedGPUResource]
+	if !found || slices.Value() < int64(replicas) {
+		return nil
+	}
+
+	cards := &GPUCards{Name: name}
+	for i := 0; i < int(slices.Value())/replicas; i++ {
+		cards.Cards = append(cards.Cards, &GPUCard{Index: i, Slices: replicas, PodMap: map[string]int{}})
+	}
+	return cards
+}
+
+// GetIgnoredDevices return device names which wish vc-scheduler to ignore
+func (gc *GPUCards) GetIgnoredDevices() []string {
+	return nil
+}
+
+// AddResource adds the slices of the pod to its card if it is assigned
+func (gc *GPUCards) AddResource(pod *v1.Pod) {
+	if card := gc.assignedCard(pod); card != nil {
+		slices := getSlicesOfPod(pod)
+		card.Used += slices
+		card.PodMap[string(pod.UID)] += slices
+	}
+}
+
+// SubResource frees the slices hold by the pod
+func (gc *GPUCards) SubResource(pod *v1.Pod) {
+	if card := gc.assignedCard(pod); card != nil {
+		card.Used -= card.PodMap[string(pod.UID)]
+		delete(card.PodMap, string(pod.UID))
+	}
+}
+
+func (gc *GPUCards) HasDeviceRequest(pod *v1.Pod) bool {
+	return TimeSlicingEnable && getSlicesOfPod(pod) > 0
+}
+
+func (gc *GPUCards) FilterNode(pod *v1.Pod, schedulePolicy string) (int, string, error) {
+	if gc == nil {
+		return devices.Unschedulable, "no time-sliced gpu on the node", errors.New("no time-sliced gpu on the node")
+	}
+	slices := getSlicesOfPod(pod)
+	if slices > gc.Cards[0].Slices {
+		err := fmt.Errorf("%d gpu slices requested, the cards of node %s are shared by %d", slices, gc.Name, gc.Cards[0].Slices)
+		return devices.UnschedulableAndUnresolvable, err.Error(), err
+	}
+	if gc.selectCard(slices, schedulePolicy) == nil {
+		err := fmt.Errorf("no card of node %s has %d free gpu slices", gc.Name, slices)
+		return devices.Unschedulable, err.Error(), err
+	}
+	return devices.Success, "", nil
+}
+
+// ScoreNode favors, with the binpack policy, the nodes whose card the slices are packed on is the
+// busiest and, with the spread policy, the idlest.
+func (gc *GPUCards) ScoreNode(pod *v1.Pod, schedulePolicy string) float64 {
+	if gc == nil {
+		return 0
+	}
+	slices := getSlicesOfPod(pod)
+	card := gc.selectCard(slices, schedulePolicy)
+	if card == nil {
+		return 0
+	}
+	used := float64(card.Used+slices) / float64(card.Slices)
+	switch schedulePolicy {
+	case binpackPolicy:
+		return used * scoreMultiplier
+	case spreadPolicy:
+		return (1 - used) * scoreMultiplier
+	}
+	return 0
+}
+
+func (gc *GPUCards) Allocate(kubeClient kubernetes.Interface, pod *v1.Pod) error {
+	if gc == nil {
+		return errors.Errorf("no time-sliced gpu on node %s", pod.Spec.NodeName)
+	}
+	slices := getSlicesOfPod(pod)
+	card := gc.selectCard(slices, SchedulePolicy)
+	if card == nil {
+		return errors.Errorf("the node %s can't pack the gpu slices of pod %s in ns %s", gc.Name, pod.Name, pod.Namespace)
+	}
+	patch := fmt.Sprintf(`[{"op": "add", "path": "/metadata/annotations/%s", "value": "%d"}]`,
+		escapeJSONPointer(AssignedCard), card.Index)
+	if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return errors.Errorf("patch pod %s failed with patch %s: %v", pod.Name, patch, err)
+	}
+	card.Used += slices
+	card.PodMap[string(pod.UID)] += slices
+	klog.V(4).Infof("Packed %d gpu slices of pod %s/%s on card %d of node %s", slices, pod.Namespace, pod.Name, card.Index, gc.Name)
+	return nil
+}
+
+func (gc *GPUCards) Release(kubeClient kubernetes.Interface, pod *v1.Pod) error {
+	if gc == nil {
+		return nil
+	}
+	patch := fmt.Sprintf(`[{"op": "remove", "path": "/metadata/annotations/%s"}]`, escapeJSONPointer(AssignedCard))
+	if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return errors.Errorf("patch pod %s failed with patch %s: %v", pod.Name, patch, err)
+	}
+	for _, card := range gc.Cards {
+		if used, found := card.PodMap[string(pod.UID)]; found {
+			card.Used -= used
+			delete(card.PodMap, string(pod.UID))
+		}
+	}
+	klog.V(4).Infof("Released the gpu slices of pod %s/%s on node %s", pod.Namespace, pod.Name, gc.Name)
+	return nil
+}
+
+func (gc *GPUCards) GetStatus() string {
+	return ""
+}
+
+// selectCard returns the card to pack the slices on: the one with the fewest free slices which
+// fits them with the binpack policy, the default, or with the most free slices with the spread
+// policy, nil if none fits them.
+func (gc *GPUCards) selectCard(slices int, schedulePolicy string) *GPUCard {
+	var selected *GPUCard
+	for _, card := range gc.Cards {
+		if card.free() < slices {
+			continue
+		}
+		if selected == nil ||
+			(schedulePolicy == spreadPolicy && card.free() > selected.free()) ||
+			(schedulePolicy != spreadPolicy && card.free() < selected.free()) {
+			selected = card
+		}
+	}
+	return selected
+}
+
+func (gc *GPUCards) assignedCard(pod *v1.Pod) *GPUCard {
+	if gc == nil {
+		return nil
+	}
+	value, found := pod.Annotations[AssignedCard]
+	if !found {
+		return nil
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil || index < 0 || index >= len(gc.Cards) {
+		return nil
+	}
+	return gc.Cards[index]
+}
+
+// getSlicesOfPod returns the number of gpu slices requested by the containers of the pod.
+func getSlicesOfPod(pod *v1.Pod) int {
+	slices := 0
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Limits[SharedGPUResource]; ok {
+			slices += int(q.Value())
+		}
+	}
+	return slices
+}
+
+func escapeJSONPointer(p string) string {
+	// Escaping reference name using https://tools.ietf.org/html/rfc6901
+	p = strings.Replace(p, "~", "~0", -1)
+	p = strings.Replace(p, "/", "~1", -1)
+	return p
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package timeslice
+
+import (
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"volcano.sh/volcano/pkg/scheduler/api/devices"
+)
+
+func buildSlicePod(name string, slices int64, card int) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), Annotations: map[string]string{}},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+				SharedGPUResource: *resource.NewQuantity(slices, resource.DecimalSI),
+			}}}},
+		},
+	}
+	if card >= 0 {
+		pod.Annotations[AssignedCard] = strconv.Itoa(card)
+	}
+	return pod
+}
+
+func TestGPUCards(t *testing.T) {
+	TimeSlicingEnable = true
+	defer func() { TimeSlicingEnable = false }()
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{ReplicasLabel: "4"}},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			SharedGPUResource: *resource.NewQuantity(8, resource.DecimalSI),
+		}},
+	}
+	cards := NewGPUCards("n1", node)
+	if cards == nil || len(cards.Cards) != 2 {
+		t.Fatalf("expected 2 cards of 4 slices, got %v", cards)
+	}
+
+	// card 0 holds 3 slices, card 1 one
+	cards.AddResource(buildSlicePod("p0", 3, 0))
+	cards.AddResource(buildSlicePod("p1", 1, 1))
+
+	pod := buildSlicePod("p2", 1, -1)
+	if !cards.HasDeviceRequest(pod) {
+		t.Errorf("expected a request of gpu slices")
+	}
+	if card := cards.selectCard(1, binpackPolicy); card == nil || card.Index != 0 {
+		t.Errorf("expected binpack to pack the slice on card 0, got %v", card)
+	}
+	if card := cards.selectCard(1, spreadPolicy); card == nil || card.Index != 1 {
+		t.Errorf("expected spread to place the slice on card 1, got %v", card)
+	}
+	if score := cards.ScoreNode(pod, binpackPolicy); score != scoreMultiplier {
+		t.Errorf("expected binpack to score a full card %d, got %v", scoreMultiplier, score)
+	}
+
+	if code, _, _ := cards.FilterNode(buildSlicePod("p3", 4, -1), binpackPolicy); code != devices.Unschedulable {
+		t.Errorf("expected no card with 4 free slices, got code %d", code)
+	}
+	if code, _, _ := cards.FilterNode(buildSlicePod("p4", 5, -1), binpackPolicy); code != devices.UnschedulableAndUnresolvable {
+		t.Errorf("expected more slices than a card has to be unresolvable, got code %d", code)
+	}
+
+	cards.SubResource(buildSlicePod("p0", 3, 0))
+	if code, _, _ := cards.FilterNode(buildSlicePod("p3", 4, -1), binpackPolicy); code != devices.Success {
+		t.Errorf("expected card 0 to fit 4 slices once freed, got code %d", code)
+	}
+}
+
+func TestAllocateSchedulePolicy(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{ReplicasLabel: "4"}},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			SharedGPUResource: *resource.NewQuantity(8, resource.DecimalSI),
+		}},
+	}
+	for policy, expected := range map[string]int{"": 0, binpackPolicy: 0, spreadPolicy: 1} {
+		t.Run(policy, func(t *testing.T) {
+			SchedulePolicy = policy
+			defer func() { SchedulePolicy = "" }()
+
+			cards := NewGPUCards("n1", node)
+			cards.AddResource(buildSlicePod("p0", 2, 0))
+			pod := buildSlicePod("p1", 1, -1)
+			pod.Annotations["scheduling.k8s.io/group-name"] = "pg1"
+			client := fake.NewSimpleClientset(pod)
+			if err := cards.Allocate(client, pod); err != nil {
+				t.Fatalf("failed to allocate the slice: %v", err)
+			}
+			if used := cards.Cards[expected].PodMap[string(pod.UID)]; used != 1 {
+				t.Errorf("expected the %q policy to place the slice on card %d", policy, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package timeslice
+
+var TimeSlicingEnable bool
+
+// SchedulePolicy is the schedule policy of the deviceshare plugin the slices are allocated by,
+// the same one the nodes are filtered and scored by
+var SchedulePolicy string
+
+const (
+	// DeviceName used to indicate this device
+	DeviceName = "timeslice"
+
+	// SharedGPUResource is the resource of the gpu slices advertised by the NVIDIA device plugin
+	// with time-slicing and renamed resources, each card being shared by ReplicasLabel slices
+	SharedGPUResource = "nvidia.com/gpu.shared"
+	// ReplicasLabel is the node label, set by the gpu feature discovery, of the number of slices
+	// each card is shared by, the replicas of the time-slicing config of the device plugin
+	ReplicasLabel = "nvidia.com/gpu.replicas"
+
+	// AssignedCard is the pod annotation of the index of the card the slices of the pod are packed on
+	AssignedCard = "volcano.sh/gpu-slice-card"
+
+	// binpackPolicy packs the slices on the busiest cards, spreadPolicy on the idlest ones
+	binpackPolicy = "binpack"
+	spreadPolicy  = "spread"
+
+	scoreMultiplier = 100
+)
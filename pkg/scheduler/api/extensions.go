@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sync"
+)
+
+// ExtensionKey is the key of the values of type T plugins attach to the nodes and jobs.
+type ExtensionKey[T any] struct {
+	name string
+}
+
+// NewExtensionKey returns the key of the extension of the name, which should be prefixed by the
+// name of the plugin defining it, e.g. "deviceshare.topology".
+func NewExtensionKey[T any](name string) ExtensionKey[T] {
+	return ExtensionKey[T]{name: name}
+}
+
+// Extensions are the values plugins derive from a node or a job once per session and share with
+// the other plugins, e.g. a parsed topology or usage metrics. They start empty in each session:
+// the clones of the nodes and jobs the snapshot is made of get new empty ones. They are held
+// through a pointer as they are guarded by a mutex, and are nil on the nodes and jobs of the cache:
+// nil extensions hold no value, the values set on them are dropped.
+type Extensions struct {
+	mutex  sync.RWMutex
+	values map[string]interface{}
+}
+
+// NewExtensions returns empty extensions.
+func NewExtensions() *Extensions {
+	return &Extensions{values: map[string]interface{}{}}
+}
+
+// GetExtension returns the value of the extension, false if it is not set.
+func GetExtension[T any](e *Extensions, key ExtensionKey[T]) (T, bool) {
+	if e == nil {
+		var value T
+		return value, false
+	}
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	value, found := e.values[key.name].(T)
+	return value, found
+}
+
+// SetExtension sets the value of the extension.
+func SetExtension[T any](e *Extensions, key ExtensionKey[T], value T) {
+	if e == nil {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.values == nil {
+		e.values = map[string]interface{}{}
+	}
+	e.values[key.name] = value
+}
+
+// GetOrComputeExtension returns the value of the extension, computed and set first if it is not
+// set; the value is computed once even if several goroutines, e.g. the predicates of the nodes of
+// a task, get it at the same time. On nil extensions, it is computed on each call.
+func GetOrComputeExtension[T any](e *Extensions, key ExtensionKey[T], compute func() T) T {
+	if e == nil {
+		return compute()
+	}
+	if value, found := GetExtension(e, key); found {
+		return value
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if value, found := e.values[key.name].(T); found {
+		return value
+	}
+	value := compute()
+	if e.values == nil {
+		e.values = map[string]interface{}{}
+	}
+	e.values[key.name] = value
+	return value
+}
+
+// DeleteExtension removes the extension, e.g. when the value it was derived from changed.
+func DeleteExtension[T any](e *Extensions, key ExtensionKey[T]) {
+	if e == nil {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	delete(e.values, key.name)
+}
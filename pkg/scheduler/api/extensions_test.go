@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExtensions(t *testing.T) {
+	counts := NewExtensionKey[int]("test.count")
+	names := NewExtensionKey[[]string]("test.names")
+
+	node := NewNodeInfo(nil).Clone()
+	if _, found := GetExtension(node.Extensions, counts); found {
+		t.Errorf("expected no extension on a new node")
+	}
+
+	SetExtension(node.Extensions, counts, 3)
+	if count, found := GetExtension(node.Extensions, counts); !found || count != 3 {
+		t.Errorf("expected count 3, got %v (found %v)", count, found)
+	}
+	if _, found := GetExtension(node.Extensions, names); found {
+		t.Errorf("expected no names extension")
+	}
+
+	var computed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			GetOrComputeExtension(node.Extensions, names, func() []string {
+				atomic.AddInt32(&computed, 1)
+				return []string{"a", "b"}
+			})
+		}()
+	}
+	wg.Wait()
+	if computed != 1 {
+		t.Errorf("expected the extension computed once, got %d", computed)
+	}
+
+	DeleteExtension(node.Extensions, counts)
+	if _, found := GetExtension(node.Extensions, counts); found {
+		t.Errorf("expected the count extension deleted")
+	}
+
+	if _, found := GetExtension(node.Clone().Extensions, names); found {
+		t.Errorf("expected the extensions not carried by the clone")
+	}
+}
+
+func TestNilExtensions(t *testing.T) {
+	counts := NewExtensionKey[int]("test.count")
+
+	job := NewJobInfo("job")
+	SetExtension(job.Extensions, counts, 3)
+	if _, found := GetExtension(job.Extensions, counts); found {
+		t.Errorf("expected nil extensions to hold no value")
+	}
+	if count := GetOrComputeExtension(job.Extensions, counts, func() int { return 5 }); count != 5 {
+		t.Errorf("expected the computed count 5, got %d", count)
+	}
+	DeleteExtension(job.Extensions, counts)
+
+	extensions := &Extensions{}
+	if count := GetOrComputeExtension(extensions, counts, func() int { return 5 }); count != 5 {
+		t.Errorf("expected the computed count 5, got %d", count)
+	}
+	SetExtension(extensions, counts, 3)
+	if count, found := GetExtension(extensions, counts); !found || count != 3 {
+		t.Errorf("expected count 3, got %v (found %v)", count, found)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// maxInternedStrings bounds the strings kept by the interner, it is reset once full so that the
+// values seen once, e.g. the hash of a retired pod template, are not kept forever
+const maxInternedStrings = 1 << 20
+
+var interned = struct {
+	sync.Mutex
+	strings map[string]string
+}{strings: map[string]string{}}
+
+// InternString returns the string equal to s shared by all the callers, so that the values
+// repeated across many objects, e.g. label keys and values, node names or images, are kept once.
+func InternString(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	interned.Lock()
+	defer interned.Unlock()
+	if is, found := interned.strings[s]; found {
+		return is
+	}
+	if len(interned.strings) >= maxInternedStrings {
+		interned.strings = map[string]string{}
+	}
+	interned.strings[s] = s
+	return s
+}
+
+// InternLabels replaces the keys and values of labels by their interned strings.
+func InternLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return labels
+	}
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[InternString(k)] = InternString(v)
+	}
+	return result
+}
+
+// InternPod interns the strings of the pod repeated across the pods of a cluster. It is meant for
+// the pods received from the informer before they are stored, the pod is modified in place.
+func InternPod(pod *v1.Pod) {
+	pod.Namespace = InternString(pod.Namespace)
+	pod.Labels = InternLabels(pod.Labels)
+	pod.Spec.NodeName = InternString(pod.Spec.NodeName)
+	pod.Spec.SchedulerName = InternString(pod.Spec.SchedulerName)
+	pod.Spec.ServiceAccountName = InternString(pod.Spec.ServiceAccountName)
+	for i := range pod.Spec.InitContainers {
+		pod.Spec.InitContainers[i].Image = InternString(pod.Spec.InitContainers[i].Image)
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].Image = InternString(pod.Spec.Containers[i].Image)
+	}
+	for i := range pod.Status.ContainerStatuses {
+		pod.Status.ContainerStatuses[i].Image = InternString(pod.Status.ContainerStatuses[i].Image)
+		pod.Status.ContainerStatuses[i].ImageID = InternString(pod.Status.ContainerStatuses[i].ImageID)
+	}
+}
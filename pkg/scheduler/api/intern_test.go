@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// decodedPod builds a pod the way the informer does, with strings not shared with other pods.
+func decodedPod(i int) *v1.Pod {
+	clone := func(s string) string {
+		return strings.Clone(s)
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("job-%d-worker", i),
+			Namespace: clone("training"),
+			Labels: map[string]string{
+				clone("app.kubernetes.io/name"):       clone("distributed-training"),
+				clone("app.kubernetes.io/managed-by"): clone("volcano"),
+				clone("volcano.sh/job-name"):          clone(fmt.Sprintf("job-%d", i%100)),
+				clone("volcano.sh/task-spec"):         clone("worker"),
+			},
+		},
+		Spec: v1.PodSpec{
+			NodeName:      clone(fmt.Sprintf("node-%d", i%1000)),
+			SchedulerName: clone("volcano"),
+			Containers: []v1.Container{
+				{Name: "worker", Image: clone("registry.example.com/training/worker:v1.2.3")},
+			},
+		},
+	}
+}
+
+func TestInternPod(t *testing.T) {
+	p1, p2 := decodedPod(1), decodedPod(101)
+	InternPod(p1)
+	InternPod(p2)
+
+	same := func(l, r string) bool {
+		return unsafe.StringData(l) == unsafe.StringData(r)
+	}
+	if !same(p1.Namespace, p2.Namespace) {
+		t.Errorf("expected the namespaces to be shared")
+	}
+	if !same(p1.Spec.Containers[0].Image, p2.Spec.Containers[0].Image) {
+		t.Errorf("expected the images to be shared")
+	}
+	for k, v := range p1.Labels {
+		if p2.Labels[k] != v {
+			t.Errorf("expected label %s=%s, got %s", k, v, p2.Labels[k])
+			continue
+		}
+		if !same(v, p2.Labels[k]) {
+			t.Errorf("expected the value of label %s to be shared", k)
+		}
+	}
+}
+
+// BenchmarkPodsHeap reports the heap kept by the pods of a large cluster with and without interning.
+func BenchmarkPodsHeap(b *testing.B) {
+	const numPods = 100000
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("intern=%v", intern), func(b *testing.B) {
+			var heap uint64
+			for n := 0; n < b.N; n++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				pods := make([]*v1.Pod, numPods)
+				for i := range pods {
+					pods[i] = decodedPod(i)
+					if intern {
+						InternPod(pods[i])
+					}
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				heap += after.HeapAlloc - before.HeapAlloc
+				runtime.KeepAlive(pods)
+			}
+			b.ReportMetric(float64(heap)/float64(b.N*numPods), "heap-bytes/pod")
+		})
+	}
+}
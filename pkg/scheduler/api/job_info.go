@@ -28,6 +28,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/klog/v2"
 
@@ -66,6 +67,67 @@ func (db *DisruptionBudget) Clone() *DisruptionBudget {
 // when job waits longer than waiting time, it should enqueue at once, and cluster should reserve resources for it
 const JobWaitingTime = "sla-waiting-time"
 
+const (
+	// JobGangPolicy is the podgroup annotation selecting how strictly gang semantics are enforced,
+	// one of GangPolicyStrict (the default) or GangPolicyBestEffort
+	JobGangPolicy = "volcano.sh/gang-policy"
+	// JobGangBudget is the podgroup annotation holding how long a best-effort gang job waits for
+	// all of its members to be placed together before accepting a partial placement
+	JobGangBudget = "volcano.sh/gang-budget"
+
+	// GangPolicyStrict never places fewer tasks than minMember
+	GangPolicyStrict = "strict"
+	// GangPolicyBestEffort degrades to partial placement once the gang budget is exhausted
+	GangPolicyBestEffort = "best-effort"
+
+	// DefaultGangBudget is used for best-effort gang jobs without a valid gang budget
+	DefaultGangBudget = 5 * time.Minute
+)
+
+// JobMaxTasksPerNode is the podgroup annotation limiting how many tasks of the job may run on
+// the same node, to bound the impact of a node failure on the job
+const JobMaxTasksPerNode = "volcano.sh/max-tasks-per-node"
+
+// JobCandidateNodes is the podgroup annotation listing, comma separated, the only nodes the tasks
+// of the job may run on, e.g. the nodes holding its dataset or reserved for it
+const JobCandidateNodes = "volcano.sh/candidate-nodes"
+
+const (
+	// JobPriorityBoost is the podgroup annotation which, set to "true" by an administrator, puts
+	// the job ahead of the other jobs of its queue whatever their priorities, until the time of
+	// JobPriorityBoostExpiry
+	JobPriorityBoost = "volcano.sh/priority-boost"
+	// JobPriorityBoostExpiry is the podgroup annotation holding, in RFC 3339, when the boost of the
+	// job expires; a boost without expiry is ignored
+	JobPriorityBoostExpiry = "volcano.sh/priority-boost-expiry"
+	// JobPriorityBoostAudit is the podgroup annotation recording who boosted the job, when and why
+	JobPriorityBoostAudit = "volcano.sh/priority-boost-audit"
+	// JobHibernated is the podgroup annotation the job controller sets on the podgroups of the
+	// hibernated jobs, which keep their podgroup but are not scheduled until woken
+	JobHibernated = "volcano.sh/hibernate"
+
+	// JobPreemptionPolicy is the podgroup annotation setting how the job preempts other tasks
+	JobPreemptionPolicy = "volcano.sh/preemption-policy"
+	// PreemptionPolicyDryRun records the tasks the job would preempt in its events and conditions,
+	// without evicting them
+	PreemptionPolicyDryRun = "dry-run"
+	// PreemptionDryRunReason is the reason of the events and conditions reporting the tasks a
+	// dry-run job would preempt
+	PreemptionDryRunReason = "PreemptionDryRun"
+)
+
+// PodGroupGangDegradedType is the podgroup condition recorded when a best-effort gang job
+// is placed partially after its gang budget is exhausted
+const PodGroupGangDegradedType scheduling.PodGroupConditionType = "GangDegraded"
+
+// PodGroupPreemptionDryRunType is the podgroup condition reporting the tasks a dry-run job
+// would preempt
+const PodGroupPreemptionDryRunType scheduling.PodGroupConditionType = "PreemptionDryRun"
+
+// PodGroupDuplicateOwnerType is the podgroup condition recorded when the podgroup shares its
+// owner with an older podgroup, the pods of the owner may then be split between their gangs
+const PodGroupDuplicateOwnerType scheduling.PodGroupConditionType = "DuplicateOwner"
+
 // TaskID is UID type for Task
 type TaskID types.UID
 
@@ -110,12 +172,20 @@ type TaskInfo struct {
 
 	Name      string
 	Namespace string
-	TaskRole  string // value of "volcano.sh/task-spec"
+	TaskRole  string // value of "volcano.sh/task-spec", or of the first of RoleLabels set on the pod
+	// Identity is the gang slot of the task, its role and index, which the pods recreated by the
+	// job controller for the slot keep; empty if the pod has no role or index
+	Identity string
+	// PreviousNode is the node of the deleted pod the task was recreated for in its gang slot, if any
+	PreviousNode string
 
 	// Resreq is the resource that used when task running.
 	Resreq *Resource
 	// InitResreq is the resource that used to launch a task.
 	InitResreq *Resource
+	// Recommended is the resource recommended for the task by a VerticalPodAutoscaler, below
+	// Resreq; nil if there is no recommendation
+	Recommended *Resource
 
 	TransactionContext
 	// LastTransaction holds the context of last scheduling transaction
@@ -127,6 +197,9 @@ type TaskInfo struct {
 	BestEffort                  bool
 	HasRestartableInitContainer bool
 	SchGated                    bool
+	// EvictionProtected is whether the priority band of the task is never evicted, see the
+	// eviction-grace-periods flag of the scheduler
+	EvictionProtected bool
 
 	// RevocableZone supports setting volcano.sh/revocable-zone annotation or label for pod/podgroup
 	// we only support empty value or * value for this version and we will support specify revocable zone name for future releases
@@ -137,6 +210,9 @@ type TaskInfo struct {
 	NumaInfo   *TopologyInfo
 	PodVolumes *volumescheduling.PodVolumes
 	Pod        *v1.Pod
+	// ScoreBreakdown gives the scores of the node the task was allocated to and of the runner-up,
+	// reported when the task is bound; empty unless the score-breakdown-verbosity flag is set
+	ScoreBreakdown string
 
 	// CustomBindErrHandler is a custom callback func called when task bind err.
 	CustomBindErrHandler func() error `json:"-"`
@@ -154,6 +230,11 @@ func getJobID(pod *v1.Pod) JobID {
 	return ""
 }
 
+// RoleLabels are the labels, e.g. "training.kubeflow.org/replica-type", giving the roles of the
+// pods without "volcano.sh/task-spec", set by the task-role-labels flag of the scheduler; the
+// first label set on a pod gives its role.
+var RoleLabels []string
+
 func getTaskRole(pod *v1.Pod) string {
 	if pod == nil {
 		return ""
@@ -165,10 +246,38 @@ func getTaskRole(pod *v1.Pod) string {
 	if ts, found := pod.Labels[batch.TaskSpecKey]; found && len(ts) != 0 {
 		return ts
 	}
+	for _, label := range RoleLabels {
+		if role, found := pod.Labels[label]; found && len(role) != 0 {
+			return role
+		}
+	}
 
 	return ""
 }
 
+// PlacementKey returns the key of the task in the previous placements of its podgroup, its
+// identity, kept by the pods recreated by the job controller, or its name.
+func (ti *TaskInfo) PlacementKey() string {
+	if len(ti.Identity) != 0 {
+		return ti.Identity
+	}
+	return ti.Name
+}
+
+func getTaskIdentity(pod *v1.Pod, role string) string {
+	if pod == nil || len(role) == 0 {
+		return ""
+	}
+	index, found := pod.Annotations[batch.TaskIndex]
+	if !found {
+		index = pod.Labels[batch.TaskIndex]
+	}
+	if len(index) == 0 {
+		return ""
+	}
+	return role + "-" + index
+}
+
 const TaskPriorityAnnotation = "volcano.sh/task-priority"
 
 // NewTaskInfo creates new taskInfo object for a Pod
@@ -191,6 +300,7 @@ func NewTaskInfo(pod *v1.Pod) *TaskInfo {
 		Name:                        pod.Name,
 		Namespace:                   pod.Namespace,
 		TaskRole:                    role,
+		Identity:                    getTaskIdentity(pod, role),
 		Priority:                    1,
 		Pod:                         pod,
 		Resreq:                      resReq,
@@ -269,6 +379,15 @@ func (ti *TaskInfo) UnsetPodResourceDecision() {
 	delete(ti.Pod.Annotations, topologyDecisionAnnotation)
 }
 
+// NeverPreempts returns whether the task must not evict other tasks to be placed, as the
+// preemptionPolicy of its priority class is Never. It is still ordered by its priority.
+func (ti *TaskInfo) NeverPreempts() bool {
+	if ti.Pod == nil || ti.Pod.Spec.PreemptionPolicy == nil {
+		return false
+	}
+	return *ti.Pod.Spec.PreemptionPolicy == v1.PreemptNever
+}
+
 // Clone is used for cloning a task
 func (ti *TaskInfo) Clone() *TaskInfo {
 	return &TaskInfo{
@@ -277,11 +396,15 @@ func (ti *TaskInfo) Clone() *TaskInfo {
 		Name:                        ti.Name,
 		Namespace:                   ti.Namespace,
 		TaskRole:                    ti.TaskRole,
+		Identity:                    ti.Identity,
+		PreviousNode:                ti.PreviousNode,
 		Priority:                    ti.Priority,
 		PodVolumes:                  ti.PodVolumes,
 		Pod:                         ti.Pod,
+		ScoreBreakdown:              ti.ScoreBreakdown,
 		Resreq:                      ti.Resreq.Clone(),
 		InitResreq:                  ti.InitResreq.Clone(),
+		Recommended:                 ti.Recommended,
 		VolumeReady:                 ti.VolumeReady,
 		Preemptable:                 ti.Preemptable,
 		BestEffort:                  ti.BestEffort,
@@ -289,6 +412,7 @@ func (ti *TaskInfo) Clone() *TaskInfo {
 		RevocableZone:               ti.RevocableZone,
 		NumaInfo:                    ti.NumaInfo.Clone(),
 		SchGated:                    ti.SchGated,
+		EvictionProtected:           ti.EvictionProtected,
 		TransactionContext: TransactionContext{
 			NodeName: ti.NodeName,
 			Status:   ti.Status,
@@ -297,6 +421,15 @@ func (ti *TaskInfo) Clone() *TaskInfo {
 	}
 }
 
+// ShareResreq returns the resource the task counts for in the fair-share and binpack math: its
+// recommended resource if any, its request otherwise.
+func (ti *TaskInfo) ShareResreq() *Resource {
+	if ti.Recommended != nil {
+		return ti.Recommended
+	}
+	return ti.Resreq
+}
+
 // hasRestartableInitContainer returns whether pod has restartable container.
 func hasRestartableInitContainer(pod *v1.Pod) bool {
 	for _, c := range pod.Spec.InitContainers {
@@ -345,6 +478,22 @@ type JobInfo struct {
 
 	WaitingTime *time.Duration
 
+	// GangPolicy and GangBudget are read from the podgroup annotations, see JobGangPolicy
+	GangPolicy string
+	GangBudget time.Duration
+
+	// MaxTasksPerNode is the maximum number of tasks of the job on a node, 0 if unlimited
+	MaxTasksPerNode int32
+	// CandidateNodes are the only nodes the tasks of the job may run on, nil if any node
+	CandidateNodes sets.String
+	// BoostedUntil is when the boost of the job bumping it to the front of its queue expires, see
+	// JobPriorityBoost
+	BoostedUntil metav1.Time
+	// Hibernated is whether the job was hibernated, see JobHibernated
+	Hibernated bool
+	// PreemptionDryRun is whether the job only records what it would preempt, see JobPreemptionPolicy
+	PreemptionDryRun bool
+
 	JobFitErrors   string
 	NodesFitErrors map[TaskID]*FitErrors
 
@@ -353,6 +502,11 @@ type JobInfo struct {
 	Tasks                 tasksMap
 	TaskMinAvailable      map[string]int32 // key is value of "volcano.sh/task-spec", value is number
 	TaskMinAvailableTotal int32
+	// Roles groups the tasks by role, the key is the value of "volcano.sh/task-spec"
+	Roles map[string]*RoleInfo
+	// slotNodes are the nodes the tasks were last placed on by identity, kept once the tasks are
+	// deleted to give the pods recreated for the slots their previous node
+	slotNodes map[string]slotNode
 
 	Allocated    *Resource
 	TotalRequest *Resource
@@ -370,6 +524,9 @@ type JobInfo struct {
 	// * value means workload can use all the revocable node for during node active revocable time.
 	RevocableZone string
 	Budget        *DisruptionBudget
+
+	// Extensions are the per-session data plugins derive from the job, see extensions.go
+	Extensions *Extensions
 }
 
 // NewJobInfo creates a new jobInfo for set of tasks
@@ -383,6 +540,7 @@ func NewJobInfo(uid JobID, tasks ...*TaskInfo) *JobInfo {
 		TaskStatusIndex:  map[TaskStatus]tasksMap{},
 		Tasks:            tasksMap{},
 		TaskMinAvailable: map[string]int32{},
+		Roles:            map[string]*RoleInfo{},
 	}
 
 	for _, task := range tasks {
@@ -421,6 +579,12 @@ func (ji *JobInfo) SetPodGroup(pg *PodGroup) {
 		}
 	}
 
+	ji.GangPolicy, ji.GangBudget = ji.extractGangPolicy(pg)
+	ji.MaxTasksPerNode = ji.extractMaxTasksPerNode(pg)
+	ji.CandidateNodes = ji.extractCandidateNodes(pg)
+	ji.BoostedUntil = ji.extractBoostedUntil(pg)
+	ji.Hibernated = pg.Annotations[JobHibernated] == "true"
+	ji.PreemptionDryRun = pg.Annotations[JobPreemptionPolicy] == PreemptionPolicyDryRun
 	ji.Preemptable = ji.extractPreemptable(pg)
 	ji.RevocableZone = ji.extractRevocableZone(pg)
 	ji.Budget = ji.extractBudget(pg)
@@ -450,6 +614,82 @@ func (ji *JobInfo) extractWaitingTime(pg *PodGroup, waitingTimeKey string) (*tim
 	return &jobWaitingTime, nil
 }
 
+// extractGangPolicy reads the gang policy and gang budget for job from podgroup annotations
+func (ji *JobInfo) extractGangPolicy(pg *PodGroup) (string, time.Duration) {
+	if pg.Annotations[JobGangPolicy] != GangPolicyBestEffort {
+		return GangPolicyStrict, 0
+	}
+
+	value, found := pg.Annotations[JobGangBudget]
+	if !found {
+		return GangPolicyBestEffort, DefaultGangBudget
+	}
+	budget, err := time.ParseDuration(value)
+	if err != nil || budget < 0 {
+		klog.Warningf("Invalid %s=%s for job <%s/%s>, use default %v",
+			JobGangBudget, value, pg.Namespace, pg.Name, DefaultGangBudget)
+		return GangPolicyBestEffort, DefaultGangBudget
+	}
+
+	return GangPolicyBestEffort, budget
+}
+
+// extractMaxTasksPerNode reads the maximum number of tasks per node for job from podgroup annotations
+func (ji *JobInfo) extractMaxTasksPerNode(pg *PodGroup) int32 {
+	value, found := pg.Annotations[JobMaxTasksPerNode]
+	if !found {
+		return 0
+	}
+	max, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || max < 0 {
+		klog.Warningf("Invalid %s=%s for job <%s/%s>, ignore it", JobMaxTasksPerNode, value, pg.Namespace, pg.Name)
+		return 0
+	}
+	return int32(max)
+}
+
+// extractCandidateNodes reads the candidate nodes for job from podgroup annotations
+func (ji *JobInfo) extractCandidateNodes(pg *PodGroup) sets.String {
+	value, found := pg.Annotations[JobCandidateNodes]
+	if !found {
+		return nil
+	}
+	nodes := sets.NewString()
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); len(name) != 0 {
+			nodes.Insert(name)
+		}
+	}
+	if nodes.Len() == 0 {
+		klog.Warningf("Empty %s for job <%s/%s>, ignore it", JobCandidateNodes, pg.Namespace, pg.Name)
+		return nil
+	}
+	return nodes
+}
+
+// IsCandidateNode returns whether the tasks of the job may run on the node.
+func (ji *JobInfo) IsCandidateNode(name string) bool {
+	return ji.CandidateNodes == nil || ji.CandidateNodes.Has(name)
+}
+
+// extractBoostedUntil returns when the boost of the job expires, the zero time if it is not boosted.
+func (ji *JobInfo) extractBoostedUntil(pg *PodGroup) metav1.Time {
+	if pg.Annotations[JobPriorityBoost] != "true" {
+		return metav1.Time{}
+	}
+	expiry, err := time.Parse(time.RFC3339, pg.Annotations[JobPriorityBoostExpiry])
+	if err != nil {
+		klog.Warningf("Ignored the boost of job <%s/%s> without a valid expiry: %v", pg.Namespace, pg.Name, err)
+		return metav1.Time{}
+	}
+	return metav1.NewTime(expiry)
+}
+
+// Boosted returns whether the job is bumped to the front of its queue, see JobPriorityBoost.
+func (ji *JobInfo) Boosted() bool {
+	return time.Now().Before(ji.BoostedUntil.Time)
+}
+
 // extractPreemptable return volcano.sh/preemptable value for job
 func (ji *JobInfo) extractPreemptable(pg *PodGroup) bool {
 	// check annotaion first
@@ -534,6 +774,18 @@ func (ji *JobInfo) GetMinResources() *Resource {
 	return NewResource(*ji.PodGroup.Spec.MinResources)
 }
 
+// GetSchedulerName returns the scheduler name of the job's pods, the first one in name order if
+// they have several; it is empty if the job has no pod yet.
+func (ji *JobInfo) GetSchedulerName() string {
+	name, found := "", false
+	for _, task := range ji.Tasks {
+		if task.Pod != nil && (!found || task.Pod.Spec.SchedulerName < name) {
+			name, found = task.Pod.Spec.SchedulerName, true
+		}
+	}
+	return name
+}
+
 // Get the total resources of tasks whose pod is scheduling gated
 // By definition, if a pod is scheduling gated, it's status is Pending
 func (ji *JobInfo) GetSchGatedPodResources() *Resource {
@@ -587,10 +839,34 @@ func (ji *JobInfo) addTaskIndex(ti *TaskInfo) {
 	ji.TaskStatusIndex[ti.Status][ti.UID] = ti
 }
 
+// slotNode is the node a task of a gang slot was placed on.
+type slotNode struct {
+	task TaskID
+	node string
+}
+
+// recordSlotNode gives the task the node the previous task of its slot was placed on, and keeps
+// the node of the task for the task recreated after it.
+func (ji *JobInfo) recordSlotNode(ti *TaskInfo) {
+	if ji.slotNodes == nil {
+		ji.slotNodes = map[string]slotNode{}
+	}
+	if last, found := ji.slotNodes[ti.Identity]; found && last.task != ti.UID && len(ti.PreviousNode) == 0 {
+		ti.PreviousNode = last.node
+	}
+	if len(ti.NodeName) != 0 {
+		ji.slotNodes[ti.Identity] = slotNode{task: ti.UID, node: ti.NodeName}
+	}
+}
+
 // AddTaskInfo is used to add a task to a job
 func (ji *JobInfo) AddTaskInfo(ti *TaskInfo) {
 	ji.Tasks[ti.UID] = ti
+	if len(ti.Identity) != 0 {
+		ji.recordSlotNode(ti)
+	}
 	ji.addTaskIndex(ti)
+	ji.addTaskRole(ti)
 	ji.TotalRequest.Add(ti.Resreq)
 	if AllocatedStatus(ti.Status) {
 		ji.Allocated.Add(ti.Resreq)
@@ -600,7 +876,7 @@ func (ji *JobInfo) AddTaskInfo(ti *TaskInfo) {
 // UpdateTaskStatus is used to update task's status in a job.
 // If error occurs both task and job are guaranteed to be in the original state.
 func (ji *JobInfo) UpdateTaskStatus(task *TaskInfo, status TaskStatus) error {
-	if err := validateStatusUpdate(task.Status, status); err != nil {
+	if err := ValidateStatusUpdate(task.Status, status); err != nil {
 		return err
 	}
 
@@ -637,6 +913,7 @@ func (ji *JobInfo) DeleteTaskInfo(ti *TaskInfo) error {
 		}
 		delete(ji.Tasks, task.UID)
 		ji.deleteTaskIndex(task)
+		ji.deleteTaskRole(task)
 		return nil
 	}
 
@@ -654,12 +931,19 @@ func (ji *JobInfo) Clone() *JobInfo {
 		Queue:     ji.Queue,
 		Priority:  ji.Priority,
 
-		MinAvailable:   ji.MinAvailable,
-		WaitingTime:    ji.WaitingTime,
-		JobFitErrors:   ji.JobFitErrors,
-		NodesFitErrors: make(map[TaskID]*FitErrors),
-		Allocated:      EmptyResource(),
-		TotalRequest:   EmptyResource(),
+		MinAvailable:     ji.MinAvailable,
+		WaitingTime:      ji.WaitingTime,
+		GangPolicy:       ji.GangPolicy,
+		GangBudget:       ji.GangBudget,
+		MaxTasksPerNode:  ji.MaxTasksPerNode,
+		CandidateNodes:   ji.CandidateNodes,
+		BoostedUntil:     ji.BoostedUntil,
+		Hibernated:       ji.Hibernated,
+		PreemptionDryRun: ji.PreemptionDryRun,
+		JobFitErrors:     ji.JobFitErrors,
+		NodesFitErrors:   make(map[TaskID]*FitErrors),
+		Allocated:        EmptyResource(),
+		TotalRequest:     EmptyResource(),
 
 		PodGroup: ji.PodGroup.Clone(),
 
@@ -667,9 +951,11 @@ func (ji *JobInfo) Clone() *JobInfo {
 		TaskMinAvailable:      make(map[string]int32, len(ji.TaskMinAvailable)),
 		TaskMinAvailableTotal: ji.TaskMinAvailableTotal,
 		Tasks:                 tasksMap{},
+		Roles:                 map[string]*RoleInfo{},
 		Preemptable:           ji.Preemptable,
 		RevocableZone:         ji.RevocableZone,
 		Budget:                ji.Budget.Clone(),
+		Extensions:            NewExtensions(),
 	}
 
 	ji.CreationTimestamp.DeepCopyInto(&info.CreationTimestamp)
@@ -677,6 +963,12 @@ func (ji *JobInfo) Clone() *JobInfo {
 	for task, minAvailable := range ji.TaskMinAvailable {
 		info.TaskMinAvailable[task] = minAvailable
 	}
+	if ji.slotNodes != nil {
+		info.slotNodes = make(map[string]slotNode, len(ji.slotNodes))
+		for identity, node := range ji.slotNodes {
+			info.slotNodes[identity] = node
+		}
+	}
 	for _, task := range ji.Tasks {
 		info.AddTaskInfo(task.Clone())
 	}
@@ -845,6 +1137,10 @@ func (ji *JobInfo) TaskHasFitErrors(task *TaskInfo) bool {
 //	As the failed predicating role has been pre-checked when it was popped from queue,
 //	this function will only be called at most as the number of roles in this job.
 func (ji *JobInfo) NeedContinueAllocating() bool {
+	// A degraded best-effort gang job accepts whatever can be placed
+	if ji.IsGangDegraded() {
+		return true
+	}
 	// Ensures all tasks must be running; if any pod allocation fails, further execution stops
 	if int(ji.MinAvailable) == len(ji.Tasks) {
 		return false
@@ -913,24 +1209,12 @@ func (ji *JobInfo) CheckTaskValid() bool {
 		return true
 	}
 
-	actual := map[string]int32{}
-	for status, tasks := range ji.TaskStatusIndex {
-		if AllocatedStatus(status) ||
-			status == Succeeded ||
-			status == Pipelined ||
-			status == Pending {
-			for _, task := range tasks {
-				actual[task.TaskRole]++
-			}
-		}
-	}
-
-	klog.V(4).Infof("job %s/%s actual: %+v, ji.TaskMinAvailable: %+v", ji.Name, ji.Namespace, actual, ji.TaskMinAvailable)
+	klog.V(4).Infof("job %s/%s roles: %d, ji.TaskMinAvailable: %+v", ji.Name, ji.Namespace, len(ji.Roles), ji.TaskMinAvailable)
 	for task, minAvailable := range ji.TaskMinAvailable {
 		if minAvailable == 0 {
 			continue
 		}
-		if act, ok := actual[task]; !ok || act < minAvailable {
+		if ji.Roles[task].ReadyTaskNum()+ji.Roles[task].TaskNum(Pipelined, Pending) < minAvailable {
 			return false
 		}
 	}
@@ -991,20 +1275,10 @@ func (ji *JobInfo) CheckTaskStarving() bool {
 	if ji.MinAvailable < ji.TaskMinAvailableTotal {
 		return true
 	}
-	occupiedMap := map[string]int32{}
-	for status, tasks := range ji.TaskStatusIndex {
-		if AllocatedStatus(status) ||
-			status == Succeeded ||
-			status == Pipelined {
-			for _, task := range tasks {
-				occupiedMap[task.TaskRole]++
-			}
-			continue
-		}
-	}
 	for taskSpec, minNum := range ji.TaskMinAvailable {
-		if occupiedMap[taskSpec] < minNum {
-			klog.V(4).Infof("Job %s/%s Task %s occupied %v less than task min available", ji.Namespace, ji.Name, taskSpec, occupiedMap[taskSpec])
+		occupied := ji.Roles[taskSpec].ReadyTaskNum() + ji.Roles[taskSpec].TaskNum(Pipelined)
+		if occupied < minNum {
+			klog.V(4).Infof("Job %s/%s Task %s occupied %v less than task min available", ji.Namespace, ji.Name, taskSpec, occupied)
 			return true
 		}
 	}
@@ -1034,6 +1308,15 @@ func (ji *JobInfo) IsPipelined() bool {
 	return ji.WaitingTaskNum()+ji.ReadyTaskNum()+ji.PendingBestEffortTaskNum() >= ji.MinAvailable
 }
 
+// IsGangDegraded returns whether the job uses the best-effort gang policy and has waited
+// longer than its gang budget, so that it may be placed with fewer tasks than minMember.
+func (ji *JobInfo) IsGangDegraded() bool {
+	if ji.GangPolicy != GangPolicyBestEffort || ji.CreationTimestamp.IsZero() {
+		return false
+	}
+	return time.Since(ji.CreationTimestamp.Time) >= ji.GangBudget
+}
+
 func (ji *JobInfo) IsStarving() bool {
 	return ji.WaitingTaskNum()+ji.ReadyTaskNum() < ji.MinAvailable
 }
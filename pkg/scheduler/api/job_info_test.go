@@ -17,7 +17,9 @@ limitations under the License.
 package api
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
@@ -83,7 +85,20 @@ func TestAddTaskInfo(t *testing.T) {
 				},
 				NodesFitErrors:   make(map[TaskID]*FitErrors),
 				TaskMinAvailable: make(map[string]int32),
-				Budget:           &DisruptionBudget{},
+				Roles: map[string]*RoleInfo{
+					"": {
+						Tasks: tasksMap{
+							case01Task1.UID: case01Task1,
+							case01Task2.UID: case01Task2,
+							case01Task3.UID: case01Task3,
+							case01Task4.UID: case01Task4,
+						},
+						Allocated:       buildResource("4000m", "4G", map[string]string{"pods": "3"}, 0),
+						TotalRequest:    buildResource("5000m", "5G", map[string]string{"pods": "4"}, 0),
+						TaskStatusCount: map[TaskStatus]int32{Running: 1, Pending: 1, Bound: 2},
+					},
+				},
+				Budget: &DisruptionBudget{},
 			},
 		},
 	}
@@ -150,7 +165,18 @@ func TestDeleteTaskInfo(t *testing.T) {
 				},
 				NodesFitErrors:   make(map[TaskID]*FitErrors),
 				TaskMinAvailable: make(map[string]int32),
-				Budget:           &DisruptionBudget{},
+				Roles: map[string]*RoleInfo{
+					"": {
+						Tasks: tasksMap{
+							case01Task1.UID: case01Task1,
+							case01Task3.UID: case01Task3,
+						},
+						Allocated:       buildResource("3000m", "3G", map[string]string{"pods": "1"}, 0),
+						TotalRequest:    buildResource("4000m", "4G", map[string]string{"pods": "2"}, 0),
+						TaskStatusCount: map[TaskStatus]int32{Pending: 1, Running: 1},
+					},
+				},
+				Budget: &DisruptionBudget{},
 			},
 		},
 		{
@@ -176,7 +202,18 @@ func TestDeleteTaskInfo(t *testing.T) {
 				},
 				NodesFitErrors:   make(map[TaskID]*FitErrors),
 				TaskMinAvailable: make(map[string]int32),
-				Budget:           &DisruptionBudget{},
+				Roles: map[string]*RoleInfo{
+					"": {
+						Tasks: tasksMap{
+							case02Task1.UID: case02Task1,
+							case02Task3.UID: case02Task3,
+						},
+						Allocated:       buildResource("3000m", "3G", map[string]string{"pods": "1"}, 0),
+						TotalRequest:    buildResource("4000m", "4G", map[string]string{"pods": "2"}, 0),
+						TaskStatusCount: map[TaskStatus]int32{Pending: 1, Running: 1},
+					},
+				},
+				Budget: &DisruptionBudget{},
 			},
 		},
 	}
@@ -385,3 +422,285 @@ func TestJobInfo(t *testing.T) {
 		}
 	}
 }
+
+func TestJobInfoRoles(t *testing.T) {
+	owner := buildOwnerReference("uid")
+	buildRolePod := func(name, nodeName string, phase v1.PodPhase, role string) *TaskInfo {
+		pod := buildPod("c1", name, nodeName, phase, BuildResourceList("1", "1G"), []metav1.OwnerReference{owner}, map[string]string{
+			"volcano.sh/task-spec": role,
+		})
+		return NewTaskInfo(pod)
+	}
+	ps1 := buildRolePod("ps-0", "n1", v1.PodRunning, "ps")
+	worker1 := buildRolePod("worker-0", "n1", v1.PodRunning, "worker")
+	worker2 := buildRolePod("worker-1", "", v1.PodPending, "worker")
+
+	job := NewJobInfo("uid", ps1, worker1, worker2)
+	job.TaskMinAvailable = map[string]int32{"ps": 1, "worker": 2}
+	job.TaskMinAvailableTotal = 3
+	job.MinAvailable = 3
+
+	assert.Equal(t, 2, len(job.Roles))
+	assert.Equal(t, int32(1), job.Roles["worker"].ReadyTaskNum())
+	assert.Equal(t, int32(1), job.Roles["worker"].TaskNum(Pending))
+	assert.True(t, job.Roles["worker"].TotalRequest.Equal(NewResource(BuildResourceList("2", "2G", ScalarResource{Name: "pods", Value: "2"})), Zero))
+	assert.True(t, job.CheckTaskValid())
+	assert.True(t, job.CheckTaskStarving())
+
+	assert.NoError(t, job.UpdateTaskStatus(worker2, Pipelined))
+	assert.Equal(t, int32(0), job.Roles["worker"].TaskNum(Pending))
+	assert.False(t, job.CheckTaskStarving())
+
+	assert.NoError(t, job.DeleteTaskInfo(ps1))
+	_, found := job.Roles["ps"]
+	assert.False(t, found)
+	assert.False(t, job.CheckTaskValid())
+	job.PodGroup = &PodGroup{}
+	job.Budget = &DisruptionBudget{}
+	assert.True(t, job.Clone().Roles["worker"].TotalRequest.Equal(job.Roles["worker"].TotalRequest, Zero))
+}
+
+func TestJobInfoSlots(t *testing.T) {
+	owner := buildOwnerReference("uid")
+	buildSlotPod := func(name, uid, nodeName string, phase v1.PodPhase, index string) *TaskInfo {
+		pod := buildPod("c1", name, nodeName, phase, BuildResourceList("1", "1G"), []metav1.OwnerReference{owner}, map[string]string{
+			"volcano.sh/task-spec":  "worker",
+			"volcano.sh/task-index": index,
+		})
+		pod.UID = types.UID(uid)
+		return NewTaskInfo(pod)
+	}
+	worker0 := buildSlotPod("worker-0", "w0", "n1", v1.PodRunning, "0")
+	failed := buildSlotPod("worker-1", "w1", "n2", v1.PodFailed, "1")
+
+	job := NewJobInfo("uid", worker0, failed)
+	job.MinAvailable = 2
+	job.PodGroup = &PodGroup{}
+	job.Budget = &DisruptionBudget{}
+	assert.Equal(t, "worker-1", failed.Identity)
+	assert.Equal(t, "", failed.PreviousNode)
+
+	// the job controller deletes the failed pod and creates it again under the same name
+	assert.NoError(t, job.DeleteTaskInfo(failed))
+	worker1 := buildSlotPod("worker-1", "w1-recreated", "", v1.PodPending, "1")
+	job.AddTaskInfo(worker1)
+	assert.Equal(t, 2, len(job.Tasks))
+	assert.Equal(t, "n2", worker1.PreviousNode)
+	assert.Equal(t, "", worker0.PreviousNode)
+
+	assert.NoError(t, job.UpdateTaskStatus(worker1, Allocated))
+	assert.True(t, job.IsReady())
+	assert.Equal(t, "n2", worker1.PreviousNode)
+
+	clone := job.Clone()
+	assert.Equal(t, int32(2), clone.ReadyTaskNum())
+	assert.Equal(t, "n2", clone.Tasks[worker1.UID].PreviousNode)
+
+	// the pod recreated once more comes after the one placed on n3
+	worker1.NodeName = "n3"
+	assert.NoError(t, job.UpdateTaskStatus(worker1, Binding))
+	assert.NoError(t, job.DeleteTaskInfo(worker1))
+	recreated := buildSlotPod("worker-1", "w1-recreated-again", "", v1.PodPending, "1")
+	job.AddTaskInfo(recreated)
+	assert.Equal(t, "n3", recreated.PreviousNode)
+}
+
+func TestJobInfoGetSchedulerName(t *testing.T) {
+	owner := buildOwnerReference("uid")
+	job := NewJobInfo("uid")
+	assert.Equal(t, "", job.GetSchedulerName())
+	for i, schedulerName := range []string{"volcano-b", "volcano-a", "volcano-c"} {
+		pod := buildPod("c1", fmt.Sprintf("p%d", i), "", v1.PodPending, BuildResourceList("1", "1G"), []metav1.OwnerReference{owner}, nil)
+		pod.Spec.SchedulerName = schedulerName
+		job.AddTaskInfo(NewTaskInfo(pod))
+	}
+	assert.Equal(t, "volcano-a", job.GetSchedulerName())
+}
+
+func TestGetTaskRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		roleLabels  []string
+		labels      map[string]string
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			name:        "volcano job task",
+			roleLabels:  []string{"training.kubeflow.org/replica-type"},
+			labels:      map[string]string{"training.kubeflow.org/replica-type": "worker"},
+			annotations: map[string]string{"volcano.sh/task-spec": "driver"},
+			expected:    "driver",
+		},
+		{
+			name:     "role labels not configured",
+			labels:   map[string]string{"training.kubeflow.org/replica-type": "worker"},
+			expected: "",
+		},
+		{
+			name:       "kubeflow replica type",
+			roleLabels: []string{"training.kubeflow.org/replica-type", "spark-role"},
+			labels:     map[string]string{"training.kubeflow.org/replica-type": "worker"},
+			expected:   "worker",
+		},
+		{
+			name:       "spark role",
+			roleLabels: []string{"training.kubeflow.org/replica-type", "spark-role"},
+			labels:     map[string]string{"spark-role": "executor"},
+			expected:   "executor",
+		},
+		{
+			name:       "no role",
+			roleLabels: []string{"spark-role"},
+			expected:   "",
+		},
+	}
+
+	defer func() { RoleLabels = nil }()
+	for _, test := range tests {
+		RoleLabels = test.roleLabels
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: test.labels, Annotations: test.annotations}}
+		if role := getTaskRole(pod); role != test.expected {
+			t.Errorf("%s: expected role %q, got %q", test.name, test.expected, role)
+		}
+	}
+}
+
+func TestJobInfoGangDegraded(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		created     time.Duration
+		policy      string
+		budget      time.Duration
+		degraded    bool
+	}{
+		{
+			name:     "strict by default",
+			created:  time.Hour,
+			policy:   GangPolicyStrict,
+			degraded: false,
+		},
+		{
+			name:        "best effort within budget",
+			annotations: map[string]string{JobGangPolicy: GangPolicyBestEffort, JobGangBudget: "10m"},
+			created:     time.Minute,
+			policy:      GangPolicyBestEffort,
+			budget:      10 * time.Minute,
+			degraded:    false,
+		},
+		{
+			name:        "best effort after budget",
+			annotations: map[string]string{JobGangPolicy: GangPolicyBestEffort, JobGangBudget: "10m"},
+			created:     time.Hour,
+			policy:      GangPolicyBestEffort,
+			budget:      10 * time.Minute,
+			degraded:    true,
+		},
+		{
+			name:        "best effort with invalid budget",
+			annotations: map[string]string{JobGangPolicy: GangPolicyBestEffort, JobGangBudget: "soon"},
+			created:     time.Minute,
+			policy:      GangPolicyBestEffort,
+			budget:      DefaultGangBudget,
+			degraded:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pg := scheduling.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pg1",
+					Namespace:         "ns1",
+					Annotations:       test.annotations,
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-test.created)),
+				},
+				Spec: scheduling.PodGroupSpec{MinMember: 2},
+			}
+			job := NewJobInfo("ns1/pg1")
+			job.SetPodGroup(&PodGroup{PodGroup: pg})
+
+			assert.Equal(t, test.policy, job.GangPolicy)
+			assert.Equal(t, test.budget, job.GangBudget)
+			assert.Equal(t, test.degraded, job.IsGangDegraded())
+			assert.Equal(t, test.degraded, job.Clone().IsGangDegraded())
+		})
+	}
+}
+
+func TestJobInfoBoosted(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		boosted     bool
+	}{
+		{
+			name:        "boosted until its expiry",
+			annotations: map[string]string{JobPriorityBoost: "true", JobPriorityBoostExpiry: time.Now().Add(time.Hour).Format(time.RFC3339)},
+			boosted:     true,
+		},
+		{
+			name:        "boost expired",
+			annotations: map[string]string{JobPriorityBoost: "true", JobPriorityBoostExpiry: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+			boosted:     false,
+		},
+		{
+			name:        "boost without expiry",
+			annotations: map[string]string{JobPriorityBoost: "true"},
+			boosted:     false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pg := scheduling.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "ns1", Annotations: test.annotations},
+			}
+			job := NewJobInfo("ns1/pg1")
+			job.SetPodGroup(&PodGroup{PodGroup: pg})
+
+			assert.Equal(t, test.boosted, job.Boosted())
+		})
+	}
+}
+
+func TestValidateStatusUpdate(t *testing.T) {
+	tests := []struct {
+		from, to TaskStatus
+		valid    bool
+	}{
+		{from: Pending, to: Pending, valid: true},
+		{from: Pending, to: Allocated, valid: true},
+		{from: Allocated, to: Pending, valid: true},
+		{from: Pipelined, to: Pending, valid: true},
+		{from: Allocated, to: Binding, valid: true},
+		{from: Binding, to: Pending, valid: true},
+		{from: Running, to: Releasing, valid: true},
+		{from: Releasing, to: Running, valid: true},
+		{from: Running, to: Succeeded, valid: true},
+		{from: Failed, to: Releasing, valid: true},
+		{from: Unknown, to: Running, valid: true},
+		{from: Running, to: Pending, valid: false},
+		{from: Running, to: Allocated, valid: false},
+		{from: Bound, to: Pipelined, valid: false},
+		{from: Succeeded, to: Running, valid: false},
+		{from: Failed, to: Pending, valid: false},
+	}
+	for _, test := range tests {
+		err := ValidateStatusUpdate(test.from, test.to)
+		if (err == nil) != test.valid {
+			t.Errorf("transition from %s to %s: expected valid %v, got error %v", test.from, test.to, test.valid, err)
+		}
+	}
+
+	job := NewJobInfo("uid")
+	task := NewTaskInfo(buildPod("ns1", "p1", "n1", v1.PodRunning, BuildResourceList("1", "1G"), nil, make(map[string]string)))
+	job.AddTaskInfo(task)
+	if err := job.UpdateTaskStatus(task, Pending); err == nil {
+		t.Errorf("expected an error moving a running task back to pending")
+	}
+	if task.Status != Running || len(job.TaskStatusIndex[Running]) != 1 {
+		t.Errorf("expected the task left running, got %s", task.Status)
+	}
+}
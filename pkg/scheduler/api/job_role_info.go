@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// RoleInfo summarizes the tasks of a job sharing the same role, i.e. the same "volcano.sh/task-spec".
+type RoleInfo struct {
+	Name string
+
+	Tasks tasksMap
+	// TotalRequest is the sum of the requests of the tasks of the role
+	TotalRequest *Resource
+	// Allocated is the sum of the requests of the tasks of the role in allocated status
+	Allocated *Resource
+	// TaskStatusCount is the number of tasks of the role in each status
+	TaskStatusCount map[TaskStatus]int32
+}
+
+func newRoleInfo(name string) *RoleInfo {
+	return &RoleInfo{
+		Name:            name,
+		Tasks:           tasksMap{},
+		TotalRequest:    EmptyResource(),
+		Allocated:       EmptyResource(),
+		TaskStatusCount: map[TaskStatus]int32{},
+	}
+}
+
+// TaskNum returns the number of tasks of the role in one of the given status.
+func (ri *RoleInfo) TaskNum(statuses ...TaskStatus) int32 {
+	if ri == nil {
+		return 0
+	}
+	num := int32(0)
+	for _, status := range statuses {
+		num += ri.TaskStatusCount[status]
+	}
+	return num
+}
+
+// ReadyTaskNum returns the number of tasks of the role which are allocated or succeeded.
+func (ri *RoleInfo) ReadyTaskNum() int32 {
+	return ri.TaskNum(Allocated, Binding, Bound, Running, Succeeded)
+}
+
+func (ji *JobInfo) addTaskRole(ti *TaskInfo) {
+	if ji.Roles == nil {
+		ji.Roles = map[string]*RoleInfo{}
+	}
+	role, found := ji.Roles[ti.TaskRole]
+	if !found {
+		role = newRoleInfo(ti.TaskRole)
+		ji.Roles[ti.TaskRole] = role
+	}
+	role.Tasks[ti.UID] = ti
+	role.TotalRequest.Add(ti.Resreq)
+	if AllocatedStatus(ti.Status) {
+		role.Allocated.Add(ti.Resreq)
+	}
+	role.TaskStatusCount[ti.Status]++
+}
+
+func (ji *JobInfo) deleteTaskRole(ti *TaskInfo) {
+	role, found := ji.Roles[ti.TaskRole]
+	if !found {
+		return
+	}
+	if _, found := role.Tasks[ti.UID]; !found {
+		return
+	}
+	delete(role.Tasks, ti.UID)
+	if len(role.Tasks) == 0 {
+		delete(ji.Roles, ti.TaskRole)
+		return
+	}
+	role.TotalRequest.Sub(ti.Resreq)
+	if AllocatedStatus(ti.Status) {
+		role.Allocated.Sub(ti.Resreq)
+	}
+	if role.TaskStatusCount[ti.Status]--; role.TaskStatusCount[ti.Status] == 0 {
+		delete(role.TaskStatusCount, ti.Status)
+	}
+}
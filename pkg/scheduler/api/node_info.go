@@ -27,7 +27,9 @@ import (
 
 	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nic"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/timeslice"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/vgpu"
 )
 
@@ -71,9 +73,14 @@ type NodeInfo struct {
 	NumaChgFlag       NumaChgFlag
 	NumaSchedulerInfo *NumatopoInfo
 	RevocableZone     string
+	// OS and Arch are the operating system and the architecture of the node
+	OS   string
+	Arch string
 
 	// Used to store custom information
 	Others map[string]interface{}
+	// Extensions are the per-session data plugins derive from the node, see extensions.go
+	Extensions *Extensions
 	//SharedDevices map[string]SharedDevicePool
 
 	// enable node resource oversubscription
@@ -161,6 +168,7 @@ func NewNodeInfo(node *v1.Node) *NodeInfo {
 	nodeInfo.setNodeOthersResource(node)
 	nodeInfo.setNodeState(node)
 	nodeInfo.setRevocableZone(node)
+	nodeInfo.setPlatform(node)
 
 	return nodeInfo
 }
@@ -193,6 +201,7 @@ func (ni *NodeInfo) RefreshNumaSchedulerInfoByCrd() {
 // Clone used to clone nodeInfo Object
 func (ni *NodeInfo) Clone() *NodeInfo {
 	res := NewNodeInfo(ni.Node)
+	res.Extensions = NewExtensions()
 
 	for _, p := range ni.Tasks {
 		res.AddTask(p)
@@ -241,6 +250,22 @@ func (ni *NodeInfo) setRevocableZone(node *v1.Node) {
 	ni.RevocableZone = revocableZone
 }
 
+// setPlatform sets the OS and architecture of the node from its well-known labels, or from the
+// information reported by the kubelet when they are not set.
+func (ni *NodeInfo) setPlatform(node *v1.Node) {
+	if node == nil {
+		return
+	}
+	ni.OS = node.Labels[v1.LabelOSStable]
+	if len(ni.OS) == 0 {
+		ni.OS = node.Status.NodeInfo.OperatingSystem
+	}
+	ni.Arch = node.Labels[v1.LabelArchStable]
+	if len(ni.Arch) == 0 {
+		ni.Arch = node.Status.NodeInfo.Architecture
+	}
+}
+
 // Check node if enable Oversubscription and set Oversubscription resources
 // Only support oversubscription cpu and memory resource for this version
 func (ni *NodeInfo) setOversubscription(node *v1.Node) {
@@ -347,9 +372,13 @@ func (ni *NodeInfo) SetNode(node *v1.Node) {
 func (ni *NodeInfo) setNodeOthersResource(node *v1.Node) {
 	ni.Others[GPUSharingDevice] = gpushare.NewGPUDevices(ni.Name, node)
 	ni.Others[vgpu.DeviceName] = vgpu.NewGPUDevices(ni.Name, node)
+	ni.Others[nic.DeviceName] = nic.NewNICDevices(ni.Name, node, ni.Others[GPUSharingDevice].(*gpushare.GPUDevices))
+	ni.Others[timeslice.DeviceName] = timeslice.NewGPUCards(ni.Name, node)
 	IgnoredDevicesList.Set(
 		ni.Others[GPUSharingDevice].(Devices).GetIgnoredDevices(),
 		ni.Others[vgpu.DeviceName].(Devices).GetIgnoredDevices(),
+		ni.Others[nic.DeviceName].(Devices).GetIgnoredDevices(),
+		ni.Others[timeslice.DeviceName].(Devices).GetIgnoredDevices(),
 	)
 }
 
@@ -360,6 +389,7 @@ func (ni *NodeInfo) setNode(node *v1.Node) {
 
 	ni.setOversubscription(node)
 	ni.setRevocableZone(node)
+	ni.setPlatform(node)
 	ni.setNodeOthersResource(node)
 
 	ni.Allocatable = NewResource(node.Status.Allocatable).Add(ni.OversubscriptionResource)
@@ -495,12 +525,16 @@ func (ni *NodeInfo) RemoveTask(ti *TaskInfo) error {
 func (ni *NodeInfo) addResource(pod *v1.Pod) {
 	ni.Others[GPUSharingDevice].(Devices).AddResource(pod)
 	ni.Others[vgpu.DeviceName].(Devices).AddResource(pod)
+	ni.Others[nic.DeviceName].(Devices).AddResource(pod)
+	ni.Others[timeslice.DeviceName].(Devices).AddResource(pod)
 }
 
 // subResource is used to subtract sharable devices
 func (ni *NodeInfo) subResource(pod *v1.Pod) {
 	ni.Others[GPUSharingDevice].(Devices).SubResource(pod)
 	ni.Others[vgpu.DeviceName].(Devices).SubResource(pod)
+	ni.Others[nic.DeviceName].(Devices).SubResource(pod)
+	ni.Others[timeslice.DeviceName].(Devices).SubResource(pod)
 }
 
 // UpdateTask is used to update a task in nodeInfo object.
@@ -24,7 +24,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sframework "k8s.io/kubernetes/pkg/scheduler/framework"
 
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nic"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/timeslice"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/vgpu"
 )
 
@@ -69,8 +71,10 @@ func TestNodeInfo_AddPod(t *testing.T) {
 					"c1/p2": NewTaskInfo(case01Pod2),
 				},
 				Others: map[string]interface{}{
-					GPUSharingDevice: gpushare.NewGPUDevices("n1", case01Node),
-					vgpu.DeviceName:  vgpu.NewGPUDevices("n1", case01Node),
+					GPUSharingDevice:     gpushare.NewGPUDevices("n1", case01Node),
+					vgpu.DeviceName:      vgpu.NewGPUDevices("n1", case01Node),
+					nic.DeviceName:       nic.NewNICDevices("n1", case01Node, nil),
+					timeslice.DeviceName: timeslice.NewGPUCards("n1", case01Node),
 				},
 				ImageStates: make(map[string]*k8sframework.ImageStateSummary),
 			},
@@ -95,8 +99,10 @@ func TestNodeInfo_AddPod(t *testing.T) {
 					"c2/p1": NewTaskInfo(case02Pod1),
 				},
 				Others: map[string]interface{}{
-					GPUSharingDevice: gpushare.NewGPUDevices("n2", case01Node),
-					vgpu.DeviceName:  vgpu.NewGPUDevices("n2", case01Node),
+					GPUSharingDevice:     gpushare.NewGPUDevices("n2", case01Node),
+					vgpu.DeviceName:      vgpu.NewGPUDevices("n2", case01Node),
+					nic.DeviceName:       nic.NewNICDevices("n2", case01Node, nil),
+					timeslice.DeviceName: timeslice.NewGPUCards("n2", case01Node),
 				},
 				ImageStates: make(map[string]*k8sframework.ImageStateSummary),
 			},
@@ -160,8 +166,10 @@ func TestNodeInfo_RemovePod(t *testing.T) {
 					"c1/p3": NewTaskInfo(case01Pod3),
 				},
 				Others: map[string]interface{}{
-					GPUSharingDevice: gpushare.NewGPUDevices("n1", case01Node),
-					vgpu.DeviceName:  vgpu.NewGPUDevices("n1", case01Node),
+					GPUSharingDevice:     gpushare.NewGPUDevices("n1", case01Node),
+					vgpu.DeviceName:      vgpu.NewGPUDevices("n1", case01Node),
+					nic.DeviceName:       nic.NewNICDevices("n1", case01Node, nil),
+					timeslice.DeviceName: timeslice.NewGPUCards("n1", case01Node),
 				},
 				ImageStates: make(map[string]*k8sframework.ImageStateSummary),
 			},
@@ -227,8 +235,10 @@ func TestNodeInfo_SetNode(t *testing.T) {
 					"c1/p3": NewTaskInfo(case01Pod3),
 				},
 				Others: map[string]interface{}{
-					GPUSharingDevice: gpushare.NewGPUDevices("n1", case01Node1),
-					vgpu.DeviceName:  vgpu.NewGPUDevices("n1", case01Node1),
+					GPUSharingDevice:     gpushare.NewGPUDevices("n1", case01Node1),
+					vgpu.DeviceName:      vgpu.NewGPUDevices("n1", case01Node1),
+					nic.DeviceName:       nic.NewNICDevices("n1", case01Node1, nil),
+					timeslice.DeviceName: timeslice.NewGPUCards("n1", case01Node1),
 				},
 				ImageStates: make(map[string]*k8sframework.ImageStateSummary),
 			},
@@ -250,8 +260,10 @@ func TestNodeInfo_SetNode(t *testing.T) {
 					"c1/p3": NewTaskInfo(case01Pod3),
 				},
 				Others: map[string]interface{}{
-					GPUSharingDevice: gpushare.NewGPUDevices("n1", case01Node1),
-					vgpu.DeviceName:  vgpu.NewGPUDevices("n1", case01Node1),
+					GPUSharingDevice:     gpushare.NewGPUDevices("n1", case01Node1),
+					vgpu.DeviceName:      vgpu.NewGPUDevices("n1", case01Node1),
+					nic.DeviceName:       nic.NewNICDevices("n1", case01Node1, nil),
+					timeslice.DeviceName: timeslice.NewGPUCards("n1", case01Node1),
 				},
 				ImageStates: make(map[string]*k8sframework.ImageStateSummary),
 			},
@@ -17,6 +17,13 @@ limitations under the License.
 package api
 
 import (
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
 	"volcano.sh/apis/pkg/apis/scheduling"
 )
 
@@ -29,6 +36,42 @@ const (
 	PodGroupVersionV1Beta1 string = "v1beta1"
 )
 
+// MaxPhaseHistory is the number of phase transitions kept in the status of the podgroups. They
+// are kept as conditions whose type is the phase, oldest first, to reconstruct the queueing
+// timeline of the podgroup.
+const MaxPhaseHistory = 16
+
+// phaseConditionTypes are the types of the conditions of the phase transitions.
+var phaseConditionTypes = sets.New[scheduling.PodGroupConditionType](
+	scheduling.PodGroupConditionType(scheduling.PodGroupPending),
+	scheduling.PodGroupConditionType(scheduling.PodGroupRunning),
+	scheduling.PodGroupConditionType(scheduling.PodGroupUnknown),
+	scheduling.PodGroupConditionType(scheduling.PodGroupInqueue),
+	scheduling.PodGroupConditionType(scheduling.PodGroupCompleted),
+)
+
+// PhaseTransition is a transition of a podgroup to a phase.
+type PhaseTransition struct {
+	Phase   scheduling.PodGroupPhase
+	Time    metav1.Time
+	Reason  string
+	Message string
+}
+
+// PodGroupPreviousPlacement is the podgroup annotation recording, as a JSON map keyed by the
+// placement key of the tasks, where the evicted tasks of the podgroup ran
+const PodGroupPreviousPlacement = "volcano.sh/previous-placement"
+
+// MaxPreviousPlacements is the number of tasks whose placement is kept in PodGroupPreviousPlacement
+const MaxPreviousPlacements = 256
+
+// Placement is the node and zone an evicted task ran on.
+type Placement struct {
+	Node string      `json:"node"`
+	Zone string      `json:"zone,omitempty"`
+	Time metav1.Time `json:"time"`
+}
+
 // PodGroup is a collection of Pod; used for batch workload.
 type PodGroup struct {
 	scheduling.PodGroup
@@ -43,3 +86,83 @@ func (pg *PodGroup) Clone() *PodGroup {
 		Version:  pg.Version,
 	}
 }
+
+// PhaseHistory returns the phase transitions recorded in the status of the podgroup, oldest first.
+func (pg *PodGroup) PhaseHistory() []PhaseTransition {
+	var history []PhaseTransition
+	for _, c := range pg.Status.Conditions {
+		if !phaseConditionTypes.Has(c.Type) {
+			continue
+		}
+		history = append(history, PhaseTransition{
+			Phase:   scheduling.PodGroupPhase(c.Type),
+			Time:    c.LastTransitionTime,
+			Reason:  c.Reason,
+			Message: c.Message,
+		})
+	}
+	return history
+}
+
+// RecordPhaseTransition appends the transition to the status of the podgroup, dropping the
+// oldest transitions beyond MaxPhaseHistory.
+func (pg *PodGroup) RecordPhaseTransition(transition PhaseTransition) {
+	drop := len(pg.PhaseHistory()) + 1 - MaxPhaseHistory
+	conditions := make([]scheduling.PodGroupCondition, 0, len(pg.Status.Conditions)+1)
+	for _, c := range pg.Status.Conditions {
+		if drop > 0 && phaseConditionTypes.Has(c.Type) {
+			drop--
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+	pg.Status.Conditions = append(conditions, scheduling.PodGroupCondition{
+		Type:               scheduling.PodGroupConditionType(transition.Phase),
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: transition.Time,
+		Reason:             transition.Reason,
+		Message:            transition.Message,
+	})
+}
+
+// PreviousPlacements returns the placements of the evicted tasks of the podgroup by placement key.
+func (pg *PodGroup) PreviousPlacements() map[string]Placement {
+	value, found := pg.Annotations[PodGroupPreviousPlacement]
+	if !found {
+		return nil
+	}
+	var placements map[string]Placement
+	if err := json.Unmarshal([]byte(value), &placements); err != nil {
+		klog.Warningf("Invalid %s of podgroup <%s/%s>, reset it: %v", PodGroupPreviousPlacement, pg.Namespace, pg.Name, err)
+		return nil
+	}
+	return placements
+}
+
+// RecordPreviousPlacement records the placement of the evicted task with the key, dropping the
+// oldest placements beyond MaxPreviousPlacements, and returns the new value of the annotation.
+func (pg *PodGroup) RecordPreviousPlacement(key string, placement Placement) (string, error) {
+	placements := pg.PreviousPlacements()
+	if placements == nil {
+		placements = map[string]Placement{}
+	}
+	placements[key] = placement
+	for len(placements) > MaxPreviousPlacements {
+		oldest, oldestTime := "", metav1.Time{}
+		for k, p := range placements {
+			if oldest == "" || p.Time.Before(&oldestTime) {
+				oldest, oldestTime = k, p.Time
+			}
+		}
+		delete(placements, oldest)
+	}
+	value, err := json.Marshal(placements)
+	if err != nil {
+		return "", err
+	}
+	if pg.Annotations == nil {
+		pg.Annotations = map[string]string{}
+	}
+	pg.Annotations[PodGroupPreviousPlacement] = string(value)
+	return string(value), nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+)
+
+func TestRecordPhaseTransition(t *testing.T) {
+	pg := &PodGroup{}
+	if history := pg.PhaseHistory(); len(history) != 0 {
+		t.Fatalf("expected no history, got %v", history)
+	}
+	unschedulable := scheduling.PodGroupCondition{Type: scheduling.PodGroupUnschedulableType, Reason: "NotEnoughResources"}
+	pg.Status.Conditions = append(pg.Status.Conditions, unschedulable)
+
+	pg.RecordPhaseTransition(PhaseTransition{Phase: scheduling.PodGroupPending, Reason: "NotEnoughResources"})
+	pg.RecordPhaseTransition(PhaseTransition{Phase: scheduling.PodGroupInqueue})
+	history := pg.PhaseHistory()
+	if len(history) != 2 || history[0].Phase != scheduling.PodGroupPending || history[0].Reason != "NotEnoughResources" ||
+		history[1].Phase != scheduling.PodGroupInqueue {
+		t.Errorf("unexpected history %v", history)
+	}
+
+	for i := 0; i < MaxPhaseHistory; i++ {
+		pg.RecordPhaseTransition(PhaseTransition{Phase: scheduling.PodGroupRunning, Reason: fmt.Sprint(i)})
+	}
+	history = pg.PhaseHistory()
+	if len(history) != MaxPhaseHistory || history[0].Reason != "0" {
+		t.Errorf("expected the %d last transitions kept, got %v", MaxPhaseHistory, history)
+	}
+
+	// the other conditions are kept as they are
+	if conditions := pg.Status.Conditions; len(conditions) != MaxPhaseHistory+1 || conditions[0] != unschedulable {
+		t.Errorf("expected the %s condition kept with the history, got %v", unschedulable.Type, conditions)
+	}
+}
+
+func TestRecordPreviousPlacement(t *testing.T) {
+	pg := &PodGroup{}
+	start := time.Now()
+	for i := 0; i <= MaxPreviousPlacements; i++ {
+		placement := Placement{Node: fmt.Sprintf("n%d", i), Time: metav1.NewTime(start.Add(time.Duration(i) * time.Second))}
+		if _, err := pg.RecordPreviousPlacement(fmt.Sprintf("worker-%d", i), placement); err != nil {
+			t.Fatalf("failed to record placement %d: %v", i, err)
+		}
+	}
+
+	placements := pg.PreviousPlacements()
+	if len(placements) != MaxPreviousPlacements {
+		t.Errorf("expected %d placements kept, got %d", MaxPreviousPlacements, len(placements))
+	}
+	if _, found := placements["worker-0"]; found {
+		t.Errorf("expected the oldest placement dropped")
+	}
+	if last := placements[fmt.Sprintf("worker-%d", MaxPreviousPlacements)]; last.Node != fmt.Sprintf("n%d", MaxPreviousPlacements) {
+		t.Errorf("unexpected placement of the last task %v", last)
+	}
+}
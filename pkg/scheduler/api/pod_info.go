@@ -62,24 +62,35 @@ func GetPodResourceRequest(pod *v1.Pod) *Resource {
 	restartableInitContainerReqs := EmptyResource()
 	initContainerReqs := EmptyResource()
 	for _, container := range pod.Spec.InitContainers {
-		containerReq := NewResource(container.Resources.Requests)
+		restartable := container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways
+		requests := container.Resources.Requests
+		if restartable {
+			// the sidecars keep running, they may be resized in place like the regular containers
+			requests = containerRequests(pod, &container, pod.Status.InitContainerStatuses)
+		}
+		containerReq := newPooledResource(requests)
 
-		if container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways {
+		if restartable {
 			// Add the restartable container's req to the resulting cumulative container requests.
 			result.Add(containerReq)
 
 			// Track our cumulative restartable init container resources
 			restartableInitContainerReqs.Add(containerReq)
-			containerReq = restartableInitContainerReqs
+			initContainerReqs.SetMaxResource(restartableInitContainerReqs)
 		} else {
-			tmp := EmptyResource()
-			tmp.Add(containerReq)
-			tmp.Add(restartableInitContainerReqs)
-			containerReq = tmp
+			// the request of the init container adds up with the restartable ones started before
+			containerReq.Add(restartableInitContainerReqs)
+			initContainerReqs.SetMaxResource(containerReq)
 		}
-		initContainerReqs.SetMaxResource(containerReq)
+		releaseResource(containerReq)
 	}
 
+	// the overhead is counted in the result already, it applies to the init containers too
+	if pod.Spec.Overhead != nil {
+		overhead := newPooledResource(pod.Spec.Overhead)
+		initContainerReqs.Add(overhead)
+		releaseResource(overhead)
+	}
 	result.SetMaxResource(initContainerReqs)
 	result.AddScalar(v1.ResourcePods, 1)
 
@@ -162,13 +173,49 @@ func GetPodTopologyInfo(pod *v1.Pod) *TopologyInfo {
 func GetPodResourceWithoutInitContainers(pod *v1.Pod) *Resource {
 	result := EmptyResource()
 	for _, container := range pod.Spec.Containers {
-		result.Add(NewResource(container.Resources.Requests))
+		containerReq := newPooledResource(containerRequests(pod, &container, pod.Status.ContainerStatuses))
+		result.Add(containerReq)
+		releaseResource(containerReq)
 	}
 
 	// if PodOverhead feature is supported, add overhead for running a pod
 	if pod.Spec.Overhead != nil {
-		result.Add(NewResource(pod.Spec.Overhead))
+		overhead := newPooledResource(pod.Spec.Overhead)
+		result.Add(overhead)
+		releaseResource(overhead)
 	}
 
 	return result
 }
+
+// containerRequests returns the requests to account for a regular container or a sidecar,
+// whose status is among the statuses. When the container has been resized in place, the
+// kubelet reports the resources it actually admitted in the container status; follow
+// k8s.io/kubernetes/pkg/api/v1/resource#PodRequests and take the larger of the desired and
+// the allocated value while the resize is in progress, or the allocated value if the resize
+// is infeasible on the node.
+func containerRequests(pod *v1.Pod, container *v1.Container, statuses []v1.ContainerStatus) v1.ResourceList {
+	for i := range statuses {
+		cs := &statuses[i]
+		if cs.Name != container.Name || cs.AllocatedResources == nil {
+			continue
+		}
+
+		if pod.Status.Resize == v1.PodResizeStatusInfeasible {
+			return cs.AllocatedResources
+		}
+
+		requests := v1.ResourceList{}
+		for name, quantity := range container.Resources.Requests {
+			requests[name] = quantity
+		}
+		for name, allocated := range cs.AllocatedResources {
+			if desired, found := requests[name]; !found || allocated.Cmp(desired) > 0 {
+				requests[name] = allocated
+			}
+		}
+		return requests
+	}
+
+	return container.Resources.Requests
+}
@@ -278,6 +278,81 @@ func TestGetPodResourceRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "init container with pod overhead",
+			// max(init container, regular container) + overhead
+			expectedResource: buildResource("4", "0", map[string]string{"pods": "1"}, 0),
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{
+							Name: "init-1",
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU: resource.MustParse("3"),
+								},
+							},
+						},
+					},
+
+					Containers: []v1.Container{
+						{
+							Name: "container-1",
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU: resource.MustParse("1"),
+								},
+							},
+						},
+					},
+					Overhead: v1.ResourceList{
+						v1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+			},
+		},
+		{
+			name: "restartable init container resized in place",
+			// the sidecar accounts for its allocated resources while its resize is in progress
+			expectedResource: buildResource("3", "0", map[string]string{"pods": "1"}, 0),
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{
+							Name:          "restartable-init-1",
+							RestartPolicy: &restartAlways,
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU: resource.MustParse("1"),
+								},
+							},
+						},
+					},
+
+					Containers: []v1.Container{
+						{
+							Name: "container-1",
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU: resource.MustParse("1"),
+								},
+							},
+						},
+					},
+				},
+				Status: v1.PodStatus{
+					Resize: v1.PodResizeStatusInProgress,
+					InitContainerStatuses: []v1.ContainerStatus{
+						{
+							Name: "restartable-init-1",
+							AllocatedResources: v1.ResourceList{
+								v1.ResourceCPU: resource.MustParse("2"),
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -371,6 +446,56 @@ func TestGetPodResourceWithoutInitContainers(t *testing.T) {
 			},
 			expectedResource: NewResource(BuildResourceList("3500m", "3G")),
 		},
+		{
+			name: "get resource for pod being resized in place",
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: "c1",
+							Resources: v1.ResourceRequirements{
+								Requests: BuildResourceList("2000m", "1G"),
+							},
+						},
+					},
+				},
+				Status: v1.PodStatus{
+					Resize: v1.PodResizeStatusInProgress,
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:               "c1",
+							AllocatedResources: BuildResourceList("1000m", "2G"),
+						},
+					},
+				},
+			},
+			expectedResource: NewResource(BuildResourceList("2000m", "2G")),
+		},
+		{
+			name: "get resource for pod with infeasible in-place resize",
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: "c1",
+							Resources: v1.ResourceRequirements{
+								Requests: BuildResourceList("4000m", "4G"),
+							},
+						},
+					},
+				},
+				Status: v1.PodStatus{
+					Resize: v1.PodResizeStatusInfeasible,
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:               "c1",
+							AllocatedResources: BuildResourceList("1000m", "1G"),
+						},
+					},
+				},
+			},
+			expectedResource: NewResource(BuildResourceList("1000m", "1G")),
+		},
 	}
 
 	for i, test := range tests {
@@ -435,3 +560,29 @@ func TestGetGPUIndex(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkGetPodResourceRequest(b *testing.B) {
+	restartAlways := v1.ContainerRestartPolicyAlways
+	requests := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("1"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+		GPUResourceName:   resource.MustParse("1"),
+	}
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: requests}},
+				{Resources: v1.ResourceRequirements{Requests: requests}, RestartPolicy: &restartAlways},
+			},
+			Containers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: requests}},
+				{Resources: v1.ResourceRequirements{Requests: requests}},
+			},
+		},
+	}
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		GetPodResourceRequest(pod)
+	}
+}
@@ -17,12 +17,34 @@ limitations under the License.
 package api
 
 import (
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"volcano.sh/apis/pkg/apis/scheduling"
 	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 )
 
+const (
+	// QueueIsolation is the queue annotation selecting how the queue shares the cluster, one of
+	// QueueIsolationElastic (the default) or QueueIsolationStrict
+	QueueIsolation = "volcano.sh/isolation"
+	// QueueIsolationElastic lets the queue borrow the resources the other queues leave idle
+	QueueIsolationElastic = "elastic"
+	// QueueIsolationStrict bounds the queue to its deserved resource, even when the cluster is idle
+	QueueIsolationStrict = "strict"
+)
+
+// SchedulingLatency is the rolling median and 95th percentile of the time the last jobs of a
+// queue took from their enqueue to their running, reported in the schedulingLatency field of the
+// queue status.
+type SchedulingLatency struct {
+	P50 metav1.Duration `json:"p50"`
+	P95 metav1.Duration `json:"p95"`
+}
+
 // QueueID is UID type, serves as unique ID for each queue
 type QueueID types.UID
 
@@ -40,6 +62,10 @@ type QueueInfo struct {
 	// Hierarchy is a list of node name along the
 	// path from the root to the node itself.
 	Hierarchy string
+	// Strict is whether the queue may never borrow beyond its deserved resource, see QueueIsolation
+	Strict bool
+	// SchedulingLatency is the scheduling latency last reported in the queue status, nil if none
+	SchedulingLatency *SchedulingLatency
 
 	Queue *scheduling.Queue
 }
@@ -53,6 +79,7 @@ func NewQueueInfo(queue *scheduling.Queue) *QueueInfo {
 		Weight:    queue.Spec.Weight,
 		Hierarchy: queue.Annotations[v1beta1.KubeHierarchyAnnotationKey],
 		Weights:   queue.Annotations[v1beta1.KubeHierarchyWeightAnnotationKey],
+		Strict:    queue.Annotations[QueueIsolation] == QueueIsolationStrict,
 
 		Queue: queue,
 	}
@@ -66,8 +93,28 @@ func (q *QueueInfo) Clone() *QueueInfo {
 		Weight:    q.Weight,
 		Hierarchy: q.Hierarchy,
 		Weights:   q.Weights,
+		Strict:    q.Strict,
 		Queue:     q.Queue,
+
+		SchedulingLatency: q.SchedulingLatency,
+	}
+}
+
+// IsolationBound returns the resource a strict queue may never be allocated beyond, its deserved
+// resource with the dimensions it does not set unbounded; nil if the queue is not strict or has no
+// deserved resource.
+func (q *QueueInfo) IsolationBound() *Resource {
+	if !q.Strict || q.Queue == nil || len(q.Queue.Spec.Deserved) == 0 {
+		return nil
+	}
+	bound := NewResource(q.Queue.Spec.Deserved)
+	if _, found := q.Queue.Spec.Deserved[v1.ResourceCPU]; !found {
+		bound.MilliCPU = math.MaxFloat64
+	}
+	if _, found := q.Queue.Spec.Deserved[v1.ResourceMemory]; !found {
+		bound.Memory = math.MaxFloat64
 	}
+	return bound
 }
 
 // Reclaimable return whether queue is reclaimable
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// QuotaScheduleAnnotationKey is the queue annotation holding, as a JSON list of QuotaWindow,
+// the time windows in which the weight and capability of the queue are overridden, e.g.
+//
+//	[{"start":"20:00","end":"08:00","weight":8},{"start":"08:00","end":"20:00","weight":2}]
+const QuotaScheduleAnnotationKey = "volcano.sh/quota-schedule"
+
+const quotaWindowLayout = "15:04"
+
+// QuotaWindow overrides the weight and/or capability of a queue every day from Start to End,
+// both in `HH:MM` UTC. A window whose End is not after its Start spans midnight.
+type QuotaWindow struct {
+	Start      string          `json:"start"`
+	End        string          `json:"end"`
+	Weight     int32           `json:"weight,omitempty"`
+	Capability v1.ResourceList `json:"capability,omitempty"`
+
+	start, end int
+}
+
+// ParseQuotaSchedule parses the value of the QuotaScheduleAnnotationKey annotation.
+func ParseQuotaSchedule(value string) ([]QuotaWindow, error) {
+	var windows []QuotaWindow
+	if err := json.Unmarshal([]byte(value), &windows); err != nil {
+		return nil, err
+	}
+	for i := range windows {
+		w := &windows[i]
+		start, err := time.Parse(quotaWindowLayout, w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("window %d has invalid start <%s>", i, w.Start)
+		}
+		end, err := time.Parse(quotaWindowLayout, w.End)
+		if err != nil {
+			return nil, fmt.Errorf("window %d has invalid end <%s>", i, w.End)
+		}
+		if w.Weight < 0 {
+			return nil, fmt.Errorf("window %d has negative weight %d", i, w.Weight)
+		}
+		w.start = start.Hour()*60 + start.Minute()
+		w.end = end.Hour()*60 + end.Minute()
+	}
+	return windows, nil
+}
+
+// Contains returns whether now is in the window.
+func (w *QuotaWindow) Contains(now time.Time) bool {
+	now = now.UTC()
+	minute := now.Hour()*60 + now.Minute()
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+// ActiveQuotaWindow returns the first window of the schedule containing now, nil if none.
+func ActiveQuotaWindow(windows []QuotaWindow, now time.Time) *QuotaWindow {
+	for i := range windows {
+		if windows[i].Contains(now) {
+			return &windows[i]
+		}
+	}
+	return nil
+}
+
+// NextQuotaWindowChange returns the duration from now to the next start or end of any window
+// of the schedule, zero if the schedule is empty.
+func NextQuotaWindowChange(windows []QuotaWindow, now time.Time) time.Duration {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var next time.Duration
+	for _, w := range windows {
+		for _, minute := range []int{w.start, w.end} {
+			boundary := midnight.Add(time.Duration(minute) * time.Minute)
+			if !boundary.After(now) {
+				boundary = boundary.Add(24 * time.Hour)
+			}
+			if d := boundary.Sub(now); next == 0 || d < next {
+				next = d
+			}
+		}
+	}
+	return next
+}
+
+// QuotaAt returns the weight and capability of the queue at now, taking the active window of
+// its quota schedule into account.
+func (q *QueueInfo) QuotaAt(now time.Time) (int32, v1.ResourceList) {
+	weight := q.Weight
+	var capability v1.ResourceList
+	if q.Queue == nil {
+		return weight, capability
+	}
+	capability = q.Queue.Spec.Capability
+
+	value, found := q.Queue.Annotations[QuotaScheduleAnnotationKey]
+	if !found {
+		return weight, capability
+	}
+	windows, err := ParseQuotaSchedule(value)
+	if err != nil {
+		return weight, capability
+	}
+	if w := ActiveQuotaWindow(windows, now); w != nil {
+		if w.Weight > 0 {
+			weight = w.Weight
+		}
+		if len(w.Capability) != 0 {
+			capability = w.Capability
+		}
+	}
+	return weight, capability
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+)
+
+func TestQuotaAt(t *testing.T) {
+	schedule := `[{"start":"20:00","end":"08:00","weight":8,"capability":{"cpu":"80"}},` +
+		`{"start":"08:00","end":"20:00","weight":2}]`
+	queue := NewQueueInfo(&scheduling.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "batch",
+			Annotations: map[string]string{QuotaScheduleAnnotationKey: schedule},
+		},
+		Spec: scheduling.QueueSpec{
+			Weight:     1,
+			Capability: BuildResourceList("20", "20Gi"),
+		},
+	})
+
+	tests := []struct {
+		name               string
+		now                time.Time
+		expectedWeight     int32
+		expectedCapability string
+		expectedNext       time.Duration
+	}{
+		{
+			name:               "night window spanning midnight",
+			now:                time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC),
+			expectedWeight:     8,
+			expectedCapability: "80",
+			expectedNext:       6 * time.Hour,
+		},
+		{
+			name:               "business hours window keeps the capability of the spec",
+			now:                time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC),
+			expectedWeight:     2,
+			expectedCapability: "20",
+			expectedNext:       7*time.Hour + 30*time.Minute,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			weight, capability := queue.QuotaAt(test.now)
+			if weight != test.expectedWeight {
+				t.Errorf("expected weight %d, got %d", test.expectedWeight, weight)
+			}
+			cpu := capability["cpu"]
+			if cpu.String() != test.expectedCapability {
+				t.Errorf("expected cpu capability %s, got %s", test.expectedCapability, cpu.String())
+			}
+			windows, err := ParseQuotaSchedule(schedule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if next := NextQuotaWindowChange(windows, test.now); next != test.expectedNext {
+				t.Errorf("expected next change in %v, got %v", test.expectedNext, next)
+			}
+		})
+	}
+
+	if _, err := ParseQuotaSchedule(`[{"start":"25:00","end":"08:00"}]`); err == nil {
+		t.Errorf("expected error for invalid start")
+	}
+	queue.Queue.Annotations[QuotaScheduleAnnotationKey] = "invalid"
+	weight, capability := queue.QuotaAt(time.Now())
+	if weight != 1 || !equality.Semantic.DeepEqual(capability, queue.Queue.Spec.Capability) {
+		t.Errorf("expected spec quota for invalid schedule, got weight %d capability %v", weight, capability)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -67,9 +68,38 @@ func EmptyResource() *Resource {
 	return &Resource{}
 }
 
+// resourcePool keeps the Resources computed temporarily, e.g. the requests of each container of a
+// pod summed into the request of the pod
+var resourcePool = sync.Pool{
+	New: func() interface{} {
+		return EmptyResource()
+	},
+}
+
 // NewResource creates a new resource object from resource list
 func NewResource(rl v1.ResourceList) *Resource {
 	r := EmptyResource()
+	r.addResourceList(rl)
+	return r
+}
+
+// newPooledResource is NewResource taking the object from resourcePool, it must be released with
+// releaseResource once no longer referenced.
+func newPooledResource(rl v1.ResourceList) *Resource {
+	r := resourcePool.Get().(*Resource)
+	r.addResourceList(rl)
+	return r
+}
+
+func releaseResource(r *Resource) {
+	r.MilliCPU = 0
+	r.Memory = 0
+	r.MaxTaskNum = 0
+	clear(r.ScalarResources)
+	resourcePool.Put(r)
+}
+
+func (r *Resource) addResourceList(rl v1.ResourceList) {
 	for rName, rQuant := range rl {
 		switch rName {
 		case v1.ResourceCPU:
@@ -103,7 +133,6 @@ func NewResource(rl v1.ResourceList) *Resource {
 			}
 		}
 	}
-	return r
 }
 
 // ResFloat642Quantity transform resource quantity
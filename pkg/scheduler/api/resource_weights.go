@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// ResourceWeights is the relative value of the resources, shared by the plugins
+// which compare quantities of different resources. A resource not in the map
+// weighs 1, a resource of weight 0 is ignored.
+type ResourceWeights map[v1.ResourceName]float64
+
+// Get returns the weight of the resource.
+func (w ResourceWeights) Get(name v1.ResourceName) float64 {
+	if weight, found := w[name]; found {
+		return weight
+	}
+	return 1
+}
+
+// Highest returns the largest weight, a weighted share divided by it stays within [0, 1].
+func (w ResourceWeights) Highest() float64 {
+	highest := 1.0
+	for _, weight := range w {
+		if weight > highest {
+			highest = weight
+		}
+	}
+	return highest
+}
@@ -22,7 +22,9 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nic"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/timeslice"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/vgpu"
 )
 
@@ -79,8 +81,14 @@ type Devices interface {
 // make sure GPUDevices implements Devices interface
 var _ Devices = new(gpushare.GPUDevices)
 
+// make sure NICDevices implements Devices interface
+var _ Devices = new(nic.NICDevices)
+
+// make sure GPUCards implements Devices interface
+var _ Devices = new(timeslice.GPUCards)
+
 var RegisteredDevices = []string{
-	GPUSharingDevice, vgpu.DeviceName,
+	GPUSharingDevice, vgpu.DeviceName, nic.DeviceName, timeslice.DeviceName,
 }
 
 var IgnoredDevicesList = ignoredDevicesList{}
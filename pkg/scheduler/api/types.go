@@ -17,6 +17,7 @@ limitations under the License.
 package api
 
 import (
+	"fmt"
 	"strings"
 
 	k8sframework "k8s.io/kubernetes/pkg/scheduler/framework"
@@ -105,8 +106,36 @@ func (np NodePhase) String() string {
 	return "Unknown"
 }
 
-// validateStatusUpdate validates whether the status transfer is valid.
-func validateStatusUpdate(oldStatus, newStatus TaskStatus) error {
+// taskStatusTransitions holds the statuses the scheduler may move a task to from each status. The
+// reverse transitions are there for the statements discarded and the binds and evictions reverted.
+var taskStatusTransitions = map[TaskStatus]TaskStatus{
+	Pending:   Allocated | Pipelined | Binding | Releasing,
+	Allocated: Pending | Pipelined | Binding | Releasing,
+	Pipelined: Pending | Allocated | Binding | Releasing,
+	Binding:   Pending | Allocated | Pipelined | Bound | Running | Releasing | Succeeded | Failed,
+	Bound:     Running | Releasing | Succeeded | Failed,
+	Running:   Releasing | Succeeded | Failed,
+	Releasing: Pending | Allocated | Pipelined | Binding | Bound | Running | Succeeded | Failed,
+	Succeeded: Releasing,
+	Failed:    Releasing,
+}
+
+// ValidateStatusUpdate validates whether the status transfer is valid. The changes the scheduler
+// makes go through JobInfo.UpdateTaskStatus, which rejects the invalid ones; the pods are also
+// bound, started and lost outside the scheduler, so the changes observed from them are applied
+// anyway and only reported by the cache.
+func ValidateStatusUpdate(oldStatus, newStatus TaskStatus) error {
+	if oldStatus == newStatus {
+		return nil
+	}
+	allowed, found := taskStatusTransitions[oldStatus]
+	if !found {
+		// the status of the tasks unknown to the scheduler is trusted
+		return nil
+	}
+	if allowed&newStatus == 0 {
+		return fmt.Errorf("invalid task status transition from %s to %s", oldStatus, newStatus)
+	}
 	return nil
 }
 
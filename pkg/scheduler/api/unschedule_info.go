@@ -11,6 +11,12 @@ import (
 const (
 	// NodePodNumberExceeded means pods in node exceed the allocatable pod number
 	NodePodNumberExceeded = "node(s) pod number exceeded"
+	// NodeJobTaskNumberExceeded means tasks of the job in node exceed the maximum of the job per node
+	NodeJobTaskNumberExceeded = "node(s) job task number exceeded"
+	// NodePlatformMismatch means the OS or architecture of node is not the one required by pod
+	NodePlatformMismatch = "node(s) didn't match pod OS or architecture"
+	// NodeNotCandidate means node is not one of the candidate nodes of the job
+	NodeNotCandidate = "node(s) not in the candidate nodes of the job"
 	// NodeResourceFitFailed means node could not fit the request of pod
 	NodeResourceFitFailed = "node(s) resource fit failed"
 
@@ -69,6 +75,11 @@ func (f *FitErrors) SetNodeError(nodeName string, err error) {
 	f.nodes[nodeName] = fe
 }
 
+// NodeErrors returns the errors of the nodes by node name
+func (f *FitErrors) NodeErrors() map[string]*FitError {
+	return f.nodes
+}
+
 // GetUnschedulableAndUnresolvableNodes returns the set of nodes that has no help from preempting pods from it
 func (f *FitErrors) GetUnschedulableAndUnresolvableNodes() map[string]sets.Empty {
 	ret := make(map[string]sets.Empty)
@@ -39,6 +39,19 @@ const (
 	OversubscriptionMemory = "volcano.sh/oversubscription-memory"
 	// OfflineJobEvicting node will not schedule pod due to offline job evicting
 	OfflineJobEvicting = "volcano.sh/offline-job-evicting"
+	// ExternalBinder is the pod annotation which, set to "true", hands the binding of the pod to an
+	// external binder: the scheduler only annotates the pod with its node decision
+	ExternalBinder = "volcano.sh/external-binder"
+	// AssignedNode is the pod annotation holding the node decision of the scheduler for the pods
+	// bound by an external binder
+	AssignedNode = "volcano.sh/assigned-node"
+	// TaskRank is the pod annotation holding the rank of the pod in its job, counted across the
+	// tasks of the job in their order
+	TaskRank = "volcano.sh/task-rank"
+	// ExclusiveNode is the podgroup annotation which, set to "true", makes the tasks of the job the
+	// only batch workloads of their nodes. It is also the key of the label and of the NoSchedule taint
+	// the scheduler fences the nodes of such a job with, their value is the UID of its podgroup.
+	ExclusiveNode = "volcano.sh/exclusive-node"
 
 	// topologyDecisionAnnotation is the key of topology decision about pod request resource
 	topologyDecisionAnnotation = "volcano.sh/topology-decision"
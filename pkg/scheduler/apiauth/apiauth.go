@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiauth authenticates and authorizes the requests of the HTTP APIs served by the
+// scheduler. The bearer tokens of the requests are reviewed by the API server and the users
+// are authorized against the RBAC of the cluster, the same way as the requests to the API server.
+// Every API is only served to the users allowed to access its path as a non-resource URL, e.g.
+//
+//	rules:
+//	- nonResourceURLs: ["/api/v1/offers", "/api/v1/offers/*"]
+//	  verbs: ["get", "post"]
+//
+// and the APIs may further check the access of the users to the resources they serve.
+package apiauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// userKey is the key of the authenticated user in the context of the requests.
+type userKey struct{}
+
+// Authorizer reviews the tokens and the access of the users of the requests.
+type Authorizer struct {
+	client kubernetes.Interface
+}
+
+// New returns the authorizer reviewing the requests with the API server of the client.
+func New(client kubernetes.Interface) *Authorizer {
+	return &Authorizer{client: client}
+}
+
+// Error is a failed authentication or authorization, with the HTTP status of the response.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Authenticate returns the user of the bearer token of the request, or the user already
+// authenticated by Handler.
+func (a *Authorizer) Authenticate(r *http.Request) (*authenticationv1.UserInfo, error) {
+	if user, ok := r.Context().Value(userKey{}).(*authenticationv1.UserInfo); ok {
+		return user, nil
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || len(token) == 0 {
+		return nil, &Error{Status: http.StatusUnauthorized, Message: "a bearer token is required"}
+	}
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(r.Context(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, &Error{Status: http.StatusInternalServerError, Message: fmt.Sprintf("failed to review the token: %v", err)}
+	}
+	if !review.Status.Authenticated {
+		return nil, &Error{Status: http.StatusUnauthorized, Message: "the token is not valid"}
+	}
+	return &review.Status.User, nil
+}
+
+// Allowed returns whether the user may access the resource.
+func (a *Authorizer) Allowed(ctx context.Context, user *authenticationv1.UserInfo, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	return a.review(ctx, user, &attributes, nil)
+}
+
+// Authorize checks that the user may access the resource.
+func (a *Authorizer) Authorize(ctx context.Context, user *authenticationv1.UserInfo, attributes authorizationv1.ResourceAttributes) error {
+	allowed, err := a.Allowed(ctx, user, attributes)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return &Error{Status: http.StatusForbidden, Message: fmt.Sprintf("user <%s> may not %s %s <%s> in namespace <%s>",
+			user.Username, attributes.Verb, attributes.Resource, attributes.Name, attributes.Namespace)}
+	}
+	return nil
+}
+
+// Handler authenticates the requests and serves them by the handler only if their user may
+// access their path with the verb of their method.
+func (a *Authorizer) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := a.Authenticate(r)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		attributes := &authorizationv1.NonResourceAttributes{Path: r.URL.Path, Verb: strings.ToLower(r.Method)}
+		allowed, err := a.review(r.Context(), user, nil, attributes)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		if !allowed {
+			WriteError(w, &Error{Status: http.StatusForbidden, Message: fmt.Sprintf("user <%s> may not %s <%s>",
+				user.Username, attributes.Verb, attributes.Path)})
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// Mux is where the APIs register their handlers.
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// ServeMux registers the handlers on a mux behind the authorizer.
+type ServeMux struct {
+	mux        Mux
+	authorizer *Authorizer
+}
+
+// ServeMux returns the mux registering the handlers on the mux behind the authorizer.
+func (a *Authorizer) ServeMux(mux Mux) *ServeMux {
+	return &ServeMux{mux: mux, authorizer: a}
+}
+
+// Handle registers the handler for the pattern behind the authorizer.
+func (m *ServeMux) Handle(pattern string, handler http.Handler) {
+	m.mux.Handle(pattern, m.authorizer.Handler(handler))
+}
+
+func (a *Authorizer) review(ctx context.Context, user *authenticationv1.UserInfo,
+	resource *authorizationv1.ResourceAttributes, nonResource *authorizationv1.NonResourceAttributes) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes:    resource,
+			NonResourceAttributes: nonResource,
+			User:                  user.Username,
+			Groups:                user.Groups,
+			UID:                   user.UID,
+			Extra:                 extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, &Error{Status: http.StatusInternalServerError, Message: fmt.Sprintf("failed to review the access: %v", err)}
+	}
+	return review.Status.Allowed, nil
+}
+
+// WriteError writes the error of the authentication or authorization as the response.
+func WriteError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if e, ok := err.(*Error); ok {
+		status = e.Status
+	}
+	http.Error(w, err.Error(), status)
+}
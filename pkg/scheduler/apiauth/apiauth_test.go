@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newFakeClient returns a client authenticating the token "valid" as user "alice", who may
+// only get the queue "q1" and the path "/api".
+func newFakeClient() *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "valid" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: "alice"}
+		}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		if path := review.Spec.NonResourceAttributes; path != nil {
+			review.Status.Allowed = review.Spec.User == "alice" && path.Path == "/api" && path.Verb == "get"
+			return true, review, nil
+		}
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "alice" && attributes.Resource == "queues" &&
+			attributes.Verb == "get" && attributes.Name == "q1"
+		return true, review, nil
+	})
+	return client
+}
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := New(newFakeClient())
+
+	tests := []struct {
+		name   string
+		token  string
+		queue  string
+		status int
+	}{
+		{name: "no token", status: http.StatusUnauthorized},
+		{name: "invalid token", token: "invalid", queue: "q1", status: http.StatusUnauthorized},
+		{name: "not allowed", token: "valid", queue: "q2", status: http.StatusForbidden},
+		{name: "allowed", token: "valid", queue: "q1", status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.token != "" {
+				r.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			w := httptest.NewRecorder()
+			user, err := authorizer.Authenticate(r)
+			if err == nil {
+				err = authorizer.Authorize(context.TODO(), user, authorizationv1.ResourceAttributes{
+					Group: "scheduling.volcano.sh", Verb: "get", Resource: "queues", Name: test.queue,
+				})
+			}
+			if err != nil {
+				WriteError(w, err)
+			}
+			if w.Code != test.status {
+				t.Errorf("expected status %d, got %d: %v", test.status, w.Code, err)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	authorizer := New(newFakeClient())
+	handler := authorizer.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the user authenticated by the handler is not reviewed again
+		if user, err := authorizer.Authenticate(r); err != nil || user.Username != "alice" {
+			t.Errorf("expected the authenticated user alice, got %v: %v", user, err)
+		}
+	}))
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		status int
+	}{
+		{name: "no token", method: http.MethodGet, path: "/api", status: http.StatusUnauthorized},
+		{name: "other path", token: "valid", method: http.MethodGet, path: "/other", status: http.StatusForbidden},
+		{name: "other verb", token: "valid", method: http.MethodPost, path: "/api", status: http.StatusForbidden},
+		{name: "allowed", token: "valid", method: http.MethodGet, path: "/api", status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, nil)
+			if test.token != "" {
+				r.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.status {
+				t.Errorf("expected status %d, got %d", test.status, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// jobBackoff keeps the jobs which failed to be scheduled in consecutive sessions out of the
+// next ones for an exponentially growing period, so that the jobs which can never fit do not
+// consume the time of every cycle. A job is retried at once when capacity may have been freed.
+type jobBackoff struct {
+	base time.Duration
+	max  time.Duration
+
+	mutex   sync.Mutex
+	entries map[schedulingapi.JobID]*backoffEntry
+}
+
+type backoffEntry struct {
+	// failures is the number of consecutive sessions the job failed to be scheduled in
+	failures int
+	// until is when the job is to be scheduled again
+	until time.Time
+	// capacityEvents is the capacity event count when the job last failed
+	capacityEvents uint64
+}
+
+func newJobBackoff(base, max time.Duration) *jobBackoff {
+	if max < base {
+		max = base
+	}
+	return &jobBackoff{
+		base:    base,
+		max:     max,
+		entries: map[schedulingapi.JobID]*backoffEntry{},
+	}
+}
+
+// delay returns how long a job is backed off after its nth consecutive failure.
+func (b *jobBackoff) delay(failures int) time.Duration {
+	d := b.base
+	for i := 1; i < failures && d < b.max; i++ {
+		d *= 2
+	}
+	if d > b.max {
+		d = b.max
+	}
+	return d
+}
+
+func (b *jobBackoff) backedOff(job schedulingapi.JobID, now time.Time, capacityEvents uint64) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	entry, found := b.entries[job]
+	if !found {
+		return false
+	}
+	return now.Before(entry.until) && entry.capacityEvents == capacityEvents
+}
+
+func (b *jobBackoff) record(job schedulingapi.JobID, scheduled bool, now time.Time, capacityEvents uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if scheduled {
+		delete(b.entries, job)
+		return
+	}
+	entry, found := b.entries[job]
+	if !found {
+		entry = &backoffEntry{}
+		b.entries[job] = entry
+	}
+	entry.failures++
+	entry.until = now.Add(b.delay(entry.failures))
+	entry.capacityEvents = capacityEvents
+	klog.V(4).Infof("Job <%s> failed to be scheduled %d times in a row, back off until %v",
+		job, entry.failures, entry.until)
+}
+
+func (b *jobBackoff) forget(job schedulingapi.JobID) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.entries, job)
+}
+
+// BackedOff returns whether the job is not to be allocated in the session opened at now, because
+// it failed to be scheduled lately and no capacity was freed since.
+func (sc *SchedulerCache) BackedOff(job schedulingapi.JobID, now time.Time) bool {
+	if sc.backoff == nil {
+		return false
+	}
+	return sc.backoff.backedOff(job, now, sc.capacityEventCount.Load())
+}
+
+// RecordScheduleAttempt records whether the job was scheduled in the session closed at now,
+// a failure backs it off exponentially while a success resets its backoff.
+func (sc *SchedulerCache) RecordScheduleAttempt(job schedulingapi.JobID, scheduled bool, now time.Time) {
+	if sc.backoff == nil {
+		return
+	}
+	sc.backoff.record(job, scheduled, now, sc.capacityEventCount.Load())
+}
+
+// capacityFreed records an event which may have made room for the backed off jobs.
+func (sc *SchedulerCache) capacityFreed() {
+	sc.capacityEventCount.Add(1)
+}
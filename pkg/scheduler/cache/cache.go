@@ -18,19 +18,25 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -42,6 +48,9 @@ import (
 	storagev1beta1 "k8s.io/client-go/informers/storage/v1beta1"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appslisterv1 "k8s.io/client-go/listers/apps/v1"
+	coordinationlisterv1 "k8s.io/client-go/listers/coordination/v1"
+	nodelisterv1 "k8s.io/client-go/listers/node/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -100,8 +109,9 @@ type SchedulerCache struct {
 	restConfig   *rest.Config
 	vcClient     vcclient.Interface
 	defaultQueue string
-	// schedulerName is the name for volcano scheduler
+	// schedulerName is the name for volcano scheduler, the names of the profiles are added to it
 	schedulerNames     []string
+	schedulerNamesLock sync.RWMutex
 	nodeSelectorLabels map[string]sets.Empty
 	metricsConf        map[string]string
 
@@ -118,6 +128,10 @@ type SchedulerCache struct {
 	csiDriverInformer          storagev1.CSIDriverInformer
 	csiStorageCapacityInformer storagev1beta1.CSIStorageCapacityInformer
 	cpuInformer                cpuinformerv1.NumatopologyInformer
+	leaseLister                coordinationlisterv1.LeaseLister
+	leaseInformerFactory       informers.SharedInformerFactory
+	runtimeClassLister         nodelisterv1.RuntimeClassLister
+	daemonSetLister            appslisterv1.DaemonSetLister
 
 	Binder         Binder
 	Evictor        Evictor
@@ -148,12 +162,87 @@ type SchedulerCache struct {
 	BindFlowChannel chan *schedulingapi.TaskInfo
 	bindCache       []*schedulingapi.TaskInfo
 	batchNum        int
+	// bindMutex serializes the flushes of bindCache
+	bindMutex sync.Mutex
+	// inFlight counts the binds and evictions not completed yet, see Drain
+	inFlight atomic.Int64
+	// draining refuses the new binds and evictions once Drain started, guarded by Mutex
+	draining bool
+
+	// placementMutex guards the previous placements waiting to be patched on their podgroups and
+	// the podgroups being patched, see recordPreviousPlacement
+	placementMutex     sync.Mutex
+	pendingPlacements  map[string]string
+	patchingPlacements sets.Set[string]
 
 	// A map from image name to its imageState.
 	imageStates map[string]*imageState
 
 	nodeWorkers uint32
 
+	// nodeLeaseStaleDuration is how long a node may miss renewing its lease before it is
+	// excluded from the snapshot, 0 disables the check
+	nodeLeaseStaleDuration time.Duration
+
+	// daemonSetReservationPeriod is how long after their creation the nodes keep room for the pods
+	// of the DaemonSets not started on them yet, 0 disables the reservation
+	daemonSetReservationPeriod time.Duration
+
+	// gangBindFailurePolicy and gangBindRetryPeriod control what happens to the bound tasks
+	// of a gang some of whose binds failed
+	gangBindFailurePolicy string
+	gangBindRetryPeriod   time.Duration
+	// gangBindRetries are the timers evicting the partially bound gangs at the end of their retry
+	// period, stopped when the job is deleted or the cache is stopped
+	gangBindRetries map[schedulingapi.JobID]*time.Timer
+
+	// scoreBreakdownVerbosity is 1 to log the score breakdowns of the bound tasks, 2 to also
+	// record them as events of the pods
+	scoreBreakdownVerbosity int
+
+	// eventCount is the number of pod and node events received
+	eventCount atomic.Uint64
+	// capacityEventCount is the number of events which may have freed capacity: pods deleted or
+	// completed, nodes added or updated
+	capacityEventCount atomic.Uint64
+
+	// backoff delays the jobs which keep failing to be scheduled, nil if disabled
+	backoff *jobBackoff
+
+	// vpa holds the recommendations of the VerticalPodAutoscalers, nil if they are ignored or
+	// their CRD is not served
+	vpa *vpaRecommendations
+
+	// maintenance holds the MaintenanceWindows, nil if they are ignored
+	maintenance *maintenanceWindows
+
+	// reservations holds the Reservations, nil if the CRD is not installed
+	reservations *reservations
+
+	// resourceFlavors holds the ResourceFlavors, nil if the CRD is not installed
+	resourceFlavors *resourceFlavors
+
+	// quarantine leaves the nodes with repeated bind or admission failures out of scheduling, nil if disabled
+	quarantine *nodeQuarantine
+
+	// totals are the totals of the ready nodes
+	totals *schedulingapi.ClusterTotals
+
+	// owners reports the podgroups sharing an owner with an older one, created on the first podgroup with an owner
+	owners *podGroupOwners
+
+	// gracePeriods are the eviction grace periods by priority band, the pods of the protected
+	// bands are never offered as victims
+	gracePeriods options.GracePeriodBands
+
+	// faults are injected by tests, nil otherwise
+	faults *FaultInjector
+
+	// eventRecorder records the received events for them to be replayed, nil if disabled
+	eventRecorder *eventRecorder
+	// statusQueue rate limits the writes of the podgroup statuses, nil if they are written at once
+	statusQueue *statusQueue
+
 	// IgnoredCSIProvisioners contains a list of provisioners, and pod request pvc with these provisioners will
 	// not be counted in pod pvc resource request and node.Allocatable, because the spec.drivers of csinode resource
 	// is always null, these provisioners usually are host path csi controllers like rancher.io/local-path and hostpath.csi.k8s.io.
@@ -217,17 +306,27 @@ func NewDefaultBinder(kbclient kubernetes.Interface, record record.EventRecorder
 type defaultEvictor struct {
 	kubeclient kubernetes.Interface
 	recorder   record.EventRecorder
+	// evictByDelete deletes the pods instead of evicting them through the Eviction API
+	evictByDelete bool
+	// gracePeriods are the grace periods of the pods by priority band
+	gracePeriods options.GracePeriodBands
 }
 
-// Evict will send delete pod request to api server
+// Evict will send eviction request to api server, or delete pod request if evictByDelete is set
 func (de *defaultEvictor) Evict(p *v1.Pod, reason string) error {
 	klog.V(3).Infof("Evicting pod %v/%v, because of %v", p.Namespace, p.Name, reason)
 
-	evictMsg := fmt.Sprintf("Pod is evicted, because of %v", reason)
-	annotations := map[string]string{}
-	// record that we are evicting the pod
-	de.recorder.AnnotatedEventf(p, annotations, v1.EventTypeWarning, "Evict", evictMsg)
+	priority := podPriority(p)
+	deleteOptions := &metav1.DeleteOptions{}
+	if band := de.gracePeriods.Band(priority); band != nil {
+		if band.GracePeriod == nil {
+			return fmt.Errorf("pod <%v/%v> of priority %d is protected from eviction", p.Namespace, p.Name, priority)
+		}
+		seconds := gracePeriodSeconds(*band.GracePeriod)
+		deleteOptions.GracePeriodSeconds = &seconds
+	}
 
+	evictMsg := fmt.Sprintf("Pod is evicted, because of %v", reason)
 	pod := p.DeepCopy()
 	condition := &v1.PodCondition{
 		Type:    v1.PodReady,
@@ -240,14 +339,37 @@ func (de *defaultEvictor) Evict(p *v1.Pod, reason string) error {
 		klog.V(1).Infof("%+v", pod.Status.Conditions)
 		return nil
 	}
-	if _, err := de.kubeclient.CoreV1().Pods(p.Namespace).UpdateStatus(context.TODO(), pod, metav1.UpdateOptions{}); err != nil {
-		klog.Errorf("Failed to update pod <%v/%v> status: %v", pod.Namespace, pod.Name, err)
-		return err
+
+	if de.evictByDelete {
+		// record that we are evicting the pod
+		de.recorder.AnnotatedEventf(p, map[string]string{}, v1.EventTypeWarning, "Evict", evictMsg)
+		if _, err := de.kubeclient.CoreV1().Pods(p.Namespace).UpdateStatus(context.TODO(), pod, metav1.UpdateOptions{}); err != nil {
+			klog.Errorf("Failed to update pod <%v/%v> status: %v", pod.Namespace, pod.Name, err)
+			return err
+		}
+		if err := de.kubeclient.CoreV1().Pods(p.Namespace).Delete(context.TODO(), p.Name, *deleteOptions); err != nil {
+			klog.Errorf("Failed to evict pod <%v/%v>: %#v", p.Namespace, p.Name, err)
+			return err
+		}
+		return nil
 	}
-	if err := de.kubeclient.CoreV1().Pods(p.Namespace).Delete(context.TODO(), p.Name, metav1.DeleteOptions{}); err != nil {
+
+	// the eviction is refused with TooManyRequests if it would violate a PodDisruptionBudget, so
+	// the pod is only marked as evicted once the eviction is accepted
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: p.Name, Namespace: p.Namespace},
+		DeleteOptions: deleteOptions,
+	}
+	if err := de.kubeclient.PolicyV1().Evictions(p.Namespace).Evict(context.TODO(), eviction); err != nil {
 		klog.Errorf("Failed to evict pod <%v/%v>: %#v", p.Namespace, p.Name, err)
 		return err
 	}
+	// record that we are evicting the pod
+	de.recorder.AnnotatedEventf(p, map[string]string{}, v1.EventTypeWarning, "Evict", evictMsg)
+	// the pod is terminating already, failing to mark it does not fail the eviction
+	if _, err := de.kubeclient.CoreV1().Pods(p.Namespace).UpdateStatus(context.TODO(), pod, metav1.UpdateOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("Failed to update pod <%v/%v> status: %v", pod.Namespace, pod.Name, err)
+	}
 
 	return nil
 }
@@ -323,8 +445,28 @@ func (su *defaultStatusUpdater) UpdateQueueStatus(queue *schedulingapi.QueueInfo
 		return err
 	}
 
-	_, err := su.vcclient.SchedulingV1beta1().Queues().UpdateStatus(context.TODO(), newQueue, metav1.UpdateOptions{})
+	// the typed status has no scheduling latency, the status is written by a patch replacing it
+	// whole, which fails as the update would if the queue changed since it was cached
+	status := map[string]interface{}{}
+	value, err := json.Marshal(newQueue.Status)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(value, &status); err != nil {
+		return err
+	}
+	if queue.SchedulingLatency != nil {
+		status["schedulingLatency"] = queue.SchedulingLatency
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": newQueue.ResourceVersion},
+		{"op": "add", "path": "/status", "value": status},
+	})
 	if err != nil {
+		return err
+	}
+
+	if _, err := su.vcclient.SchedulingV1beta1().Queues().Patch(context.TODO(), newQueue.Name, types.JSONPatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
 		klog.Errorf("error occurred in updating Queue <%s>: %s", newQueue.Name, err.Error())
 		return err
 	}
@@ -540,6 +682,7 @@ func newSchedulerCache(config *rest.Config, schedulerNames []string, defaultQueu
 	sc := &SchedulerCache{
 		Jobs:                make(map[schedulingapi.JobID]*schedulingapi.JobInfo),
 		Nodes:               make(map[string]*schedulingapi.NodeInfo),
+		totals:              schedulingapi.NewClusterTotals(),
 		Queues:              make(map[schedulingapi.QueueID]*schedulingapi.QueueInfo),
 		PriorityClasses:     make(map[string]*schedulingv1.PriorityClass),
 		errTasks:            workqueue.NewRateLimitingQueue(errTaskRateLimiter),
@@ -569,6 +712,41 @@ func newSchedulerCache(config *rest.Config, schedulerNames []string, defaultQueu
 	if len(nodeSelectors) > 0 {
 		sc.updateNodeSelectors(nodeSelectors)
 	}
+	if options.ServerOpts != nil {
+		sc.nodeLeaseStaleDuration = options.ServerOpts.NodeLeaseStaleDuration
+		sc.daemonSetReservationPeriod = options.ServerOpts.DaemonSetReservationPeriod
+		sc.gangBindFailurePolicy = options.ServerOpts.GangBindFailurePolicy
+		sc.gangBindRetryPeriod = options.ServerOpts.GangBindRetryPeriod
+		sc.scoreBreakdownVerbosity = options.ServerOpts.ScoreBreakdownVerbosity
+		schedulingapi.RoleLabels = options.ServerOpts.TaskRoleLabels
+		// validated with the options
+		sc.gracePeriods, _ = options.ParseGracePeriodBands(options.ServerOpts.EvictionGracePeriods)
+		if options.ServerOpts.EnableVPARecommendations && resourceServed(sc.kubeClient.Discovery(), vpaResource) {
+			sc.vpa = newVPARecommendations()
+		}
+		if options.ServerOpts.EnableMaintenanceWindows && resourceServed(sc.kubeClient.Discovery(), maintenanceWindowResource) {
+			sc.maintenance = newMaintenanceWindows()
+		}
+		if resourceServed(sc.kubeClient.Discovery(), reservationResource) {
+			sc.reservations = newReservations()
+		}
+		if resourceServed(sc.kubeClient.Discovery(), resourceFlavorResource) {
+			sc.resourceFlavors = newResourceFlavors()
+		}
+		if options.ServerOpts.NodeQuarantineFailures > 0 {
+			sc.quarantine = newNodeQuarantine(options.ServerOpts.NodeQuarantineFailures,
+				options.ServerOpts.NodeQuarantineWindow, options.ServerOpts.NodeQuarantineDuration)
+		}
+		if options.ServerOpts.UnschedulableBackoffBase > 0 {
+			sc.backoff = newJobBackoff(options.ServerOpts.UnschedulableBackoffBase, options.ServerOpts.UnschedulableBackoffMax)
+		}
+		if options.ServerOpts.RecordEventsFile != "" {
+			if sc.eventRecorder, err = newEventRecorder(options.ServerOpts.RecordEventsFile,
+				options.ServerOpts.RecordEventsMaxBytes); err != nil {
+				klog.Errorf("Failed to record the cache events: %v", err)
+			}
+		}
+	}
 	// Prepare event clients.
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: eventClient.CoreV1().Events("")})
@@ -581,16 +759,34 @@ func newSchedulerCache(config *rest.Config, schedulerNames []string, defaultQueu
 		bindMethodMap = NewDefaultBinder(sc.kubeClient, sc.Recorder)
 	}
 	sc.Binder = GetBindMethod()
+	if options.ServerOpts != nil && options.ServerOpts.ExternalBindTimeout > 0 {
+		sc.Binder = newExternalBinder(sc, sc.Binder, options.ServerOpts.ExternalBindTimeout)
+	}
 
 	sc.Evictor = &defaultEvictor{
-		kubeclient: sc.kubeClient,
-		recorder:   sc.Recorder,
+		kubeclient:    sc.kubeClient,
+		recorder:      sc.Recorder,
+		evictByDelete: options.ServerOpts != nil && options.ServerOpts.EvictByDelete,
+		gracePeriods:  sc.gracePeriods,
 	}
 
 	sc.StatusUpdater = &defaultStatusUpdater{
 		kubeclient: sc.kubeClient,
 		vcclient:   sc.vcClient,
 	}
+	if options.ServerOpts != nil && options.ServerOpts.PodGroupStatusQPS > 0 {
+		sc.statusQueue = newStatusQueue(options.ServerOpts.PodGroupStatusQPS, func(pg *schedulingapi.PodGroup) error {
+			_, err := sc.StatusUpdater.UpdatePodGroup(pg)
+			return err
+		}, func(pg *schedulingapi.PodGroup) *schedulingapi.PodGroup {
+			sc.Mutex.Lock()
+			defer sc.Mutex.Unlock()
+			if job, found := sc.Jobs[getJobID(pg)]; found && job.PodGroup != nil {
+				return job.PodGroup.Clone()
+			}
+			return nil
+		})
+	}
 
 	sc.PodGroupBinder = &podgroupBinder{
 		kubeclient: sc.kubeClient,
@@ -599,6 +795,18 @@ func newSchedulerCache(config *rest.Config, schedulerNames []string, defaultQueu
 
 	// add all events handlers
 	sc.addEventHandler()
+	if sc.vpa != nil {
+		sc.addVPAEventHandler()
+	}
+	if sc.maintenance != nil {
+		sc.addMaintenanceWindowEventHandler()
+	}
+	if sc.reservations != nil {
+		sc.addReservationEventHandler()
+	}
+	if sc.resourceFlavors != nil {
+		sc.addResourceFlavorEventHandler()
+	}
 	// finally, init default volume binder which has dependencies on other informers
 	sc.setDefaultVolumeBinder()
 	return sc
@@ -654,6 +862,23 @@ func (sc *SchedulerCache) addEventHandler() {
 		0,
 	)
 
+	// node leases are only watched when the staleness check is enabled, and only in the namespace
+	// of the node leases
+	if sc.nodeLeaseStaleDuration > 0 {
+		sc.leaseInformerFactory = informers.NewSharedInformerFactoryWithOptions(sc.kubeClient, 0,
+			informers.WithNamespace(v1.NamespaceNodeLease))
+		sc.leaseLister = sc.leaseInformerFactory.Coordination().V1().Leases().Lister()
+	}
+
+	// DaemonSets are only watched when the reservation for their pods is enabled
+	if sc.daemonSetReservationPeriod > 0 {
+		sc.daemonSetLister = informerFactory.Apps().V1().DaemonSets().Lister()
+	}
+
+	// the overhead of the pods admitted without the RuntimeClass admission controller is read
+	// from their runtime class
+	sc.runtimeClassLister = informerFactory.Node().V1().RuntimeClasses().Lister()
+
 	sc.podInformer = informerFactory.Core().V1().Pods()
 	sc.pvcInformer = informerFactory.Core().V1().PersistentVolumeClaims()
 	sc.pvInformer = informerFactory.Core().V1().PersistentVolumes()
@@ -672,21 +897,24 @@ func (sc *SchedulerCache) addEventHandler() {
 		sc.csiStorageCapacityInformer = informerFactory.Storage().V1beta1().CSIStorageCapacities()
 	}
 
+	// the labels, node names and images repeat across the pods, they are interned before the pods
+	// are stored
+	if err := sc.podInformer.Informer().SetTransform(func(obj interface{}) (interface{}, error) {
+		if pod, ok := obj.(*v1.Pod); ok {
+			schedulingapi.InternPod(pod)
+		}
+		return obj, nil
+	}); err != nil {
+		klog.Errorf("Failed to set the transform of the pod informer: %v", err)
+	}
+
 	// create informer for pod information
 	sc.podInformer.Informer().AddEventHandler(
 		cache.FilteringResourceEventHandler{
 			FilterFunc: func(obj interface{}) bool {
 				switch v := obj.(type) {
 				case *v1.Pod:
-					if !responsibleForPod(v, sc.schedulerNames, sc.schedulerPodName, sc.c) {
-						if len(v.Spec.NodeName) == 0 {
-							return false
-						}
-						if !responsibleForNode(v.Spec.NodeName, sc.schedulerPodName, sc.c) {
-							return false
-						}
-					}
-					return true
+					return sc.cachesPod(v)
 				case cache.DeletedFinalStateUnknown:
 					if _, ok := v.Obj.(*v1.Pod); ok {
 						// The carried object may be stale, always pass to clean up stale obj in event handlers.
@@ -775,7 +1003,37 @@ func (sc *SchedulerCache) addEventHandler() {
 func (sc *SchedulerCache) Run(stopCh <-chan struct{}) {
 	sc.informerFactory.Start(stopCh)
 	sc.vcInformerFactory.Start(stopCh)
+	if sc.leaseInformerFactory != nil {
+		sc.leaseInformerFactory.Start(stopCh)
+	}
+	if sc.vpa != nil {
+		sc.vpa.informerFactory.Start(stopCh)
+	}
+	if sc.maintenance != nil {
+		sc.maintenance.informerFactory.Start(stopCh)
+	}
+	if sc.reservations != nil {
+		sc.reservations.informerFactory.Start(stopCh)
+	}
+	if sc.resourceFlavors != nil {
+		sc.resourceFlavors.informerFactory.Start(stopCh)
+	}
 	sc.WaitForCacheSync(stopCh)
+	go func() {
+		<-stopCh
+		sc.Mutex.Lock()
+		sc.stopGangBindRetries()
+		sc.Mutex.Unlock()
+	}()
+	if sc.eventRecorder != nil {
+		go func() {
+			<-stopCh
+			sc.eventRecorder.close()
+		}()
+	}
+	if sc.statusQueue != nil {
+		go sc.statusQueue.run(stopCh)
+	}
 	for i := 0; i < int(sc.nodeWorkers); i++ {
 		go wait.Until(sc.runNodeWorker, 0, stopCh)
 	}
@@ -802,6 +1060,21 @@ func (sc *SchedulerCache) Run(stopCh <-chan struct{}) {
 func (sc *SchedulerCache) WaitForCacheSync(stopCh <-chan struct{}) {
 	sc.informerFactory.WaitForCacheSync(stopCh)
 	sc.vcInformerFactory.WaitForCacheSync(stopCh)
+	if sc.leaseInformerFactory != nil {
+		sc.leaseInformerFactory.WaitForCacheSync(stopCh)
+	}
+	if sc.vpa != nil {
+		sc.vpa.informerFactory.WaitForCacheSync(stopCh)
+	}
+	if sc.maintenance != nil {
+		sc.maintenance.informerFactory.WaitForCacheSync(stopCh)
+	}
+	if sc.reservations != nil {
+		sc.reservations.informerFactory.WaitForCacheSync(stopCh)
+	}
+	if sc.resourceFlavors != nil {
+		sc.resourceFlavors.informerFactory.WaitForCacheSync(stopCh)
+	}
 }
 
 // findJobAndTask returns job and the task info
@@ -828,6 +1101,10 @@ func (sc *SchedulerCache) Evict(taskInfo *schedulingapi.TaskInfo, reason string)
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
+	if sc.draining {
+		return fmt.Errorf("failed to evict Task %v/%v, scheduler cache is draining", taskInfo.Namespace, taskInfo.Name)
+	}
+
 	job, task, err := sc.findJobAndTask(taskInfo)
 
 	if err != nil {
@@ -839,6 +1116,7 @@ func (sc *SchedulerCache) Evict(taskInfo *schedulingapi.TaskInfo, reason string)
 		return fmt.Errorf("failed to bind Task %v to host %v, host does not exist",
 			task.UID, task.NodeName)
 	}
+	defer sc.updateNodeTotals(node.Name)
 
 	originalStatus := task.Status
 	if err := job.UpdateTaskStatus(task, schedulingapi.Releasing); err != nil {
@@ -860,12 +1138,15 @@ func (sc *SchedulerCache) Evict(taskInfo *schedulingapi.TaskInfo, reason string)
 
 	p := task.Pod
 
+	sc.inFlight.Add(1)
 	go func() {
+		defer sc.inFlight.Add(-1)
 		err := sc.Evictor.Evict(p, reason)
 		if err != nil {
 			sc.resyncTask(task)
 		}
 	}()
+	sc.recordPreviousPlacement(job, task, node)
 
 	podgroup := &vcv1beta1.PodGroup{}
 	if job.PodGroup != nil {
@@ -882,20 +1163,50 @@ func (sc *SchedulerCache) Evict(taskInfo *schedulingapi.TaskInfo, reason string)
 	return nil
 }
 
+const (
+	// gangBindFailureEvict evicts the bound tasks of a gang as soon as some of its binds fail
+	gangBindFailureEvict = "evict"
+	// gangBindFailureRetry evicts the bound tasks of a gang whose failed tasks are not bound again in time
+	gangBindFailureRetry = "retry"
+)
+
 // Bind binds task to the target host.
 func (sc *SchedulerCache) Bind(tasks []*schedulingapi.TaskInfo) {
+	stale := sc.staleBindings(tasks)
+	feasible := tasks
+	if len(stale) != 0 {
+		feasible = make([]*schedulingapi.TaskInfo, 0, len(tasks))
+		for _, task := range tasks {
+			if _, found := stale[task.UID]; !found {
+				feasible = append(feasible, task)
+			}
+		}
+	}
+
 	tmp := time.Now()
-	errMsg := sc.Binder.Bind(sc.kubeClient, tasks)
+	errMsg := sc.Binder.Bind(sc.kubeClient, feasible)
+	if errMsg == nil && len(stale) != 0 {
+		errMsg = make(map[schedulingapi.TaskID]string, len(stale))
+	}
+	for uid, reason := range stale {
+		errMsg[uid] = reason
+	}
 	if len(errMsg) == 0 {
 		klog.V(3).Infof("bind ok, latency %v", time.Since(tmp))
 	} else {
 		klog.V(3).Infof("There are %d tasks in total and %d binds failed, latency %v", len(tasks), len(errMsg), time.Since(tmp))
 	}
 
+	// the nodes some tasks failed to bind to, counted once however many tasks failed
+	failedNodes := sets.New[string]()
 	for _, task := range tasks {
 		if reason, ok := errMsg[task.UID]; !ok {
 			sc.Recorder.Eventf(task.Pod, v1.EventTypeNormal, "Scheduled", "Successfully assigned %v/%v to %v", task.Namespace, task.Name, task.NodeName)
+			sc.reportScoreBreakdown(task)
 		} else {
+			if _, found := stale[task.UID]; !found && nodeBindFailure(reason) {
+				failedNodes.Insert(task.NodeName)
+			}
 			unschedulableMsg := fmt.Sprintf("failed to bind to node %s: %s", task.NodeName, reason)
 			if err := sc.taskUnschedulable(task, schedulingapi.PodReasonSchedulerError, unschedulableMsg, ""); err != nil {
 				klog.ErrorS(err, "Failed to update pod status when bind task error", "task", task.Name)
@@ -905,6 +1216,216 @@ func (sc *SchedulerCache) Bind(tasks []*schedulingapi.TaskInfo) {
 			sc.resyncTask(task)
 		}
 	}
+
+	if sc.quarantine != nil {
+		now := time.Now()
+		for node := range failedNodes {
+			sc.quarantine.recordFailure(node, failureBind, now)
+		}
+	}
+
+	if len(errMsg) != 0 {
+		sc.handleGangBindFailure(tasks, errMsg)
+	}
+}
+
+// staleBindings returns why the tasks, placed on the session snapshot, no longer fit on their nodes
+// by the live idle resources of the cache. The tasks are counted in the idle resources already, so
+// the nodes are overcommitted if these turned negative since, e.g. when pods of other schedulers or
+// static pods were started on them. Only as many tasks as needed to cover the overcommitment are
+// rejected, the last placed first, and the tasks of a job are bound or rejected all together so
+// that no part of a gang is bound without the rest; the rejected tasks are placed again.
+func (sc *SchedulerCache) staleBindings(tasks []*schedulingapi.TaskInfo) map[schedulingapi.TaskID]string {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	jobTasks := map[schedulingapi.JobID][]*schedulingapi.TaskInfo{}
+	deficits := map[string]map[v1.ResourceName]float64{}
+	for _, task := range tasks {
+		jobTasks[task.Job] = append(jobTasks[task.Job], task)
+		node, found := sc.Nodes[task.NodeName]
+		if !found || node.Node == nil {
+			continue
+		}
+		for _, name := range task.Resreq.ResourceNames() {
+			if schedulingapi.IsIgnoredScalarResource(name) || node.Idle.Get(name) >= 0 {
+				continue
+			}
+			if deficits[node.Name] == nil {
+				deficits[node.Name] = map[v1.ResourceName]float64{}
+			}
+			deficits[node.Name][name] = -node.Idle.Get(name)
+		}
+	}
+
+	stale := map[schedulingapi.TaskID]string{}
+	// rejectJob rejects the tasks of the job of the given task, whose resources are no longer
+	// missing on their nodes then
+	rejectJob := func(task *schedulingapi.TaskInfo, reason string) {
+		for _, jobTask := range jobTasks[task.Job] {
+			if _, found := stale[jobTask.UID]; found {
+				continue
+			}
+			if jobTask.UID == task.UID {
+				stale[jobTask.UID] = reason
+			} else {
+				stale[jobTask.UID] = fmt.Sprintf("task %s/%s of the same job can not be bound: %s", task.Namespace, task.Name, reason)
+			}
+			deficit := deficits[jobTask.NodeName]
+			for name, missing := range deficit {
+				if missing -= jobTask.Resreq.Get(name); missing > 0 {
+					deficit[name] = missing
+				} else {
+					delete(deficit, name)
+				}
+			}
+			if len(deficit) == 0 {
+				delete(deficits, jobTask.NodeName)
+			}
+		}
+	}
+
+	for _, task := range tasks {
+		if node, found := sc.Nodes[task.NodeName]; !found || node.Node == nil {
+			rejectJob(task, fmt.Sprintf("node %s does not exist", task.NodeName))
+		}
+	}
+	for i := len(tasks) - 1; i >= 0 && len(deficits) != 0; i-- {
+		task := tasks[i]
+		if _, found := stale[task.UID]; found {
+			continue
+		}
+		var overcommitted []string
+		for name := range deficits[task.NodeName] {
+			if task.Resreq.Get(name) > 0 {
+				overcommitted = append(overcommitted, string(name))
+			}
+		}
+		if len(overcommitted) == 0 {
+			continue
+		}
+		slices.Sort(overcommitted)
+		node := sc.Nodes[task.NodeName]
+		klog.V(3).Infof("Node <%s> is overcommitted in %v since task <%s/%s> was placed, idle: %s",
+			node.Name, overcommitted, task.Namespace, task.Name, node.Idle.String())
+		rejectJob(task, fmt.Sprintf("node %s no longer has enough %v", node.Name, overcommitted))
+	}
+	return stale
+}
+
+// handleGangBindFailure evicts the bound tasks of the gangs some of whose binds failed, at once with
+// the evict policy, or with the retry policy if the gang is still not ready after the retry period.
+func (sc *SchedulerCache) handleGangBindFailure(tasks []*schedulingapi.TaskInfo, errMsg map[schedulingapi.TaskID]string) {
+	if sc.gangBindFailurePolicy != gangBindFailureEvict && sc.gangBindFailurePolicy != gangBindFailureRetry {
+		return
+	}
+
+	failed := map[schedulingapi.JobID]map[schedulingapi.TaskID]bool{}
+	for _, task := range tasks {
+		if _, found := errMsg[task.UID]; !found {
+			continue
+		}
+		if failed[task.Job] == nil {
+			failed[task.Job] = map[schedulingapi.TaskID]bool{}
+		}
+		failed[task.Job][task.UID] = true
+	}
+
+	for jobID, failedTasks := range failed {
+		if sc.gangBindFailurePolicy == gangBindFailureRetry {
+			sc.retryGangBind(jobID)
+			continue
+		}
+		sc.evictPartialGang(jobID, failedTasks, "some of its tasks failed to bind")
+	}
+}
+
+// retryGangBind evicts the bound tasks of the job at the end of the retry period if it is still
+// not ready, the retry period of a job runs from the first failure.
+func (sc *SchedulerCache) retryGangBind(jobID schedulingapi.JobID) {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	if sc.draining || sc.gangBindRetries[jobID] != nil {
+		return
+	}
+	if sc.gangBindRetries == nil {
+		sc.gangBindRetries = map[schedulingapi.JobID]*time.Timer{}
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(sc.gangBindRetryPeriod, func() {
+		sc.Mutex.Lock()
+		current := sc.gangBindRetries[jobID] == timer
+		if current {
+			delete(sc.gangBindRetries, jobID)
+		}
+		sc.Mutex.Unlock()
+		if current {
+			sc.evictPartialGang(jobID, nil, fmt.Sprintf("its failed tasks were not bound within %v", sc.gangBindRetryPeriod))
+		}
+	})
+	sc.gangBindRetries[jobID] = timer
+}
+
+// stopGangBindRetries stops the retry periods of the jobs, of all the jobs if none is given.
+// The caller must hold the lock.
+func (sc *SchedulerCache) stopGangBindRetries(jobIDs ...schedulingapi.JobID) {
+	if len(jobIDs) == 0 {
+		for jobID := range sc.gangBindRetries {
+			jobIDs = append(jobIDs, jobID)
+		}
+	}
+	for _, jobID := range jobIDs {
+		if timer, found := sc.gangBindRetries[jobID]; found {
+			timer.Stop()
+			delete(sc.gangBindRetries, jobID)
+		}
+	}
+}
+
+// evictPartialGang evicts the bound tasks of the job if it does not have enough ready tasks to run.
+func (sc *SchedulerCache) evictPartialGang(jobID schedulingapi.JobID, failed map[schedulingapi.TaskID]bool, reason string) {
+	sc.Mutex.Lock()
+	job, found := sc.Jobs[jobID]
+	var tasks []*schedulingapi.TaskInfo
+	if found {
+		tasks = partialGangBoundTasks(job, failed)
+	}
+	sc.Mutex.Unlock()
+
+	for _, task := range tasks {
+		klog.V(3).Infof("Evicting task <%s/%s> of partially bound gang <%s>: %s", task.Namespace, task.Name, jobID, reason)
+		if err := sc.Evict(task, fmt.Sprintf("gang %s is partially bound: %s", jobID, reason)); err != nil {
+			klog.Errorf("Failed to evict task <%s/%s> of partially bound gang <%s>: %v", task.Namespace, task.Name, jobID, err)
+		}
+	}
+}
+
+// partialGangBoundTasks returns the bound tasks of the gang if, leaving out the failed ones, it has fewer
+// ready tasks than its min available, nil otherwise.
+func partialGangBoundTasks(job *schedulingapi.JobInfo, failed map[schedulingapi.TaskID]bool) []*schedulingapi.TaskInfo {
+	if job.MinAvailable <= 1 {
+		return nil
+	}
+
+	var ready int32
+	var bound []*schedulingapi.TaskInfo
+	for _, task := range job.Tasks {
+		if failed[task.UID] {
+			continue
+		}
+		switch task.Status {
+		case schedulingapi.Binding, schedulingapi.Bound, schedulingapi.Running:
+			bound = append(bound, task)
+			ready++
+		case schedulingapi.Succeeded:
+			ready++
+		}
+	}
+	if ready >= job.MinAvailable {
+		return nil
+	}
+	return bound
 }
 
 // BindPodGroup binds job to silo cluster
@@ -1032,6 +1553,9 @@ func (sc *SchedulerCache) taskUnschedulable(task *schedulingapi.TaskInfo, reason
 func (sc *SchedulerCache) deleteJob(job *schedulingapi.JobInfo) {
 	klog.V(3).Infof("Try to delete Job <%v:%v/%v>", job.UID, job.Namespace, job.Name)
 
+	if sc.backoff != nil {
+		sc.backoff.forget(job.UID)
+	}
 	sc.DeletedJobs.Add(job)
 }
 
@@ -1070,6 +1594,7 @@ func (sc *SchedulerCache) processCleanupJob() {
 		klog.V(5).Infof("Just add pguid:%v, try to delete pguid:%v", newPgVersion, oldPgVersion)
 		if oldPgVersion == newPgVersion {
 			delete(sc.Jobs, job.UID)
+			sc.stopGangBindRetries(job.UID)
 			metrics.DeleteJobMetrics(job.Name, string(job.Queue), job.Namespace)
 			klog.V(3).Infof("Job <%v:%v/%v> was deleted.", job.UID, job.Namespace, job.Name)
 		}
@@ -1200,6 +1725,9 @@ func (sc *SchedulerCache) AddBindTask(taskInfo *schedulingapi.TaskInfo) error {
 	klog.V(5).Infof("add bind task %v/%v", taskInfo.Namespace, taskInfo.Name)
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
+	if sc.draining {
+		return fmt.Errorf("failed to bind Task %v/%v, scheduler cache is draining", taskInfo.Namespace, taskInfo.Name)
+	}
 	job, task, err := sc.findJobAndTask(taskInfo)
 	if err != nil {
 		return err
@@ -1210,6 +1738,7 @@ func (sc *SchedulerCache) AddBindTask(taskInfo *schedulingapi.TaskInfo) error {
 		return fmt.Errorf("failed to bind Task %v to host %v, host does not exist",
 			task.UID, taskInfo.NodeName)
 	}
+	defer sc.updateNodeTotals(node.Name)
 
 	originalStatus := task.Status
 	if err := job.UpdateTaskStatus(task, schedulingapi.Binding); err != nil {
@@ -1235,12 +1764,79 @@ func (sc *SchedulerCache) AddBindTask(taskInfo *schedulingapi.TaskInfo) error {
 		return err
 	}
 
+	sc.inFlight.Add(1)
 	sc.BindFlowChannel <- taskInfo
 
 	return nil
 }
 
+// Drain refuses the new binds and evictions, flushes the binds queued after the stop of the
+// cache and waits for the in-flight binds and evictions to complete, at most timeout. It
+// returns the tasks still assumed on their node when it gives up, after nominating their node
+// on their pods for the next leader.
+func (sc *SchedulerCache) Drain(timeout time.Duration) []*schedulingapi.TaskInfo {
+	// the binds and evictions hold the lock until they are counted in flight
+	sc.Mutex.Lock()
+	sc.draining = true
+	sc.stopGangBindRetries()
+	sc.Mutex.Unlock()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		sc.processBindTask()
+		if sc.inFlight.Load() <= 0 {
+			return nil
+		}
+		select {
+		case <-deadline.C:
+			tasks := sc.assumedTasks()
+			sc.nominateAssumedTasks(tasks)
+			return tasks
+		case <-ticker.C:
+		}
+	}
+}
+
+// nominateAssumedTasks records the node of the tasks assumed but not bound as the nominated node
+// of their pods, which the sticky plugin of the next leader places them back on.
+func (sc *SchedulerCache) nominateAssumedTasks(tasks []*schedulingapi.TaskInfo) {
+	if sc.StatusUpdater == nil {
+		return
+	}
+	for _, task := range tasks {
+		if task.Pod == nil || task.Pod.Status.NominatedNodeName == task.NodeName {
+			continue
+		}
+		pod := task.Pod.DeepCopy()
+		pod.Status.NominatedNodeName = task.NodeName
+		if _, err := sc.StatusUpdater.UpdatePodStatus(pod); err != nil {
+			klog.Errorf("Failed to nominate node <%s> for the assumed task <%s/%s>: %v", task.NodeName, task.Namespace, task.Name, err)
+		}
+	}
+}
+
+// assumedTasks returns the tasks allocated to a node by the scheduler but not bound yet.
+func (sc *SchedulerCache) assumedTasks() []*schedulingapi.TaskInfo {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	var tasks []*schedulingapi.TaskInfo
+	for _, job := range sc.Jobs {
+		for _, task := range job.TaskStatusIndex[schedulingapi.Binding] {
+			tasks = append(tasks, task.Clone())
+		}
+	}
+	return tasks
+}
+
 func (sc *SchedulerCache) processBindTask() {
+	sc.bindMutex.Lock()
+	defer sc.bindMutex.Unlock()
+
 	for {
 		select {
 		case taskInfo, ok := <-sc.BindFlowChannel:
@@ -1272,6 +1868,7 @@ func (sc *SchedulerCache) BindTask() {
 	var tmpBindCache []*schedulingapi.TaskInfo = make([]*schedulingapi.TaskInfo, len(sc.bindCache))
 	copy(tmpBindCache, sc.bindCache)
 	go func(tasks []*schedulingapi.TaskInfo) {
+		defer sc.inFlight.Add(-int64(len(tasks)))
 		successfulTasks := make([]*schedulingapi.TaskInfo, 0)
 		for _, task := range tasks {
 			if err := sc.VolumeBinder.BindVolumes(task, task.PodVolumes); err != nil {
@@ -1291,6 +1888,35 @@ func (sc *SchedulerCache) BindTask() {
 	sc.bindCache = sc.bindCache[0:0]
 }
 
+// reportScoreBreakdown logs the score breakdown of the bound task and, at verbosity 2, records it
+// as an event of the pod.
+func (sc *SchedulerCache) reportScoreBreakdown(task *schedulingapi.TaskInfo) {
+	if sc.scoreBreakdownVerbosity == 0 || len(task.ScoreBreakdown) == 0 {
+		return
+	}
+	klog.Infof("Task <%s/%s> bound to <%s>: %s", task.Namespace, task.Name, task.NodeName, task.ScoreBreakdown)
+	if sc.scoreBreakdownVerbosity > 1 {
+		sc.Recorder.Eventf(task.Pod, v1.EventTypeNormal, "ScoreBreakdown", "Scores: %s", task.ScoreBreakdown)
+	}
+}
+
+// EventCount returns the number of pod and node events received by the cache.
+func (sc *SchedulerCache) EventCount() uint64 {
+	return sc.eventCount.Load()
+}
+
+// nodeLeaseStale returns whether the heartbeat of the node is stale according to its lease.
+func (sc *SchedulerCache) nodeLeaseStale(nodeName string, now time.Time) bool {
+	if sc.leaseLister == nil {
+		return false
+	}
+	lease, err := sc.leaseLister.Leases(v1.NamespaceNodeLease).Get(nodeName)
+	if err != nil {
+		return false
+	}
+	return isNodeLeaseStale(lease, sc.nodeLeaseStaleDuration, now)
+}
+
 // Snapshot returns the complete snapshot of the cluster from cache
 func (sc *SchedulerCache) Snapshot() *schedulingapi.ClusterInfo {
 	sc.Mutex.Lock()
@@ -1315,12 +1941,68 @@ func (sc *SchedulerCache) Snapshot() *schedulingapi.ClusterInfo {
 		snapshot.CSINodesStatus[value.CSINodeName] = value.Clone()
 	}
 
+	if sc.totals != nil {
+		snapshot.Totals = sc.totals.Clone()
+	}
+	// excluded leaves a ready node out of the snapshot and its totals
+	excluded := func(node *schedulingapi.NodeInfo) {
+		if snapshot.Totals != nil {
+			snapshot.Totals.ExcludeNode(node)
+		}
+	}
+
+	now := time.Now()
+	maintained := sets.New[string]()
+	if sc.quarantine != nil {
+		snapshot.QuarantinedNodes = sc.quarantine.quarantined(now)
+	}
+	if sc.maintenance != nil {
+		snapshot.MaintenanceWindows = sc.maintenance.active(now, sc.Nodes)
+		for _, window := range snapshot.MaintenanceWindows {
+			maintained = maintained.Union(window.Nodes)
+		}
+	}
+	if sc.reservations != nil {
+		snapshot.Reservations = sc.reservations.active(now)
+	}
+	if sc.resourceFlavors != nil {
+		snapshot.ResourceFlavors = sc.resourceFlavors.snapshot()
+	}
+	var reserveDaemonSets func(*schedulingapi.NodeInfo) *schedulingapi.Resource
+	if sc.daemonSetLister != nil {
+		reserveDaemonSets = sc.daemonSetReserver(now)
+	}
 	for _, value := range sc.Nodes {
 		if !value.Ready() {
 			continue
 		}
 
+		if maintained.Has(value.Name) {
+			klog.V(3).Infof("Node <%s> is in a maintenance window, skip it in snapshot.", value.Name)
+			excluded(value)
+			continue
+		}
+
+		if until, found := snapshot.QuarantinedNodes[value.Name]; found {
+			klog.V(3).Infof("Node <%s> is quarantined until %v, skip it in snapshot.", value.Name, until)
+			excluded(value)
+			continue
+		}
+
+		if sc.nodeLeaseStale(value.Name, now) {
+			klog.Warningf("The lease of node <%s> has not been renewed for %v, skip it in snapshot.",
+				value.Name, sc.nodeLeaseStaleDuration)
+			excluded(value)
+			continue
+		}
+
 		snapshot.Nodes[value.Name] = value.Clone()
+		if reserveDaemonSets != nil {
+			reserved := reserveDaemonSets(snapshot.Nodes[value.Name])
+			if snapshot.Totals != nil {
+				snapshot.Totals.Reserve(reserved)
+			}
+		}
 
 		if value.RevocableZone != "" {
 			snapshot.RevocableNodes[value.Name] = snapshot.Nodes[value.Name]
@@ -1349,6 +2031,16 @@ func (sc *SchedulerCache) Snapshot() *schedulingapi.ClusterInfo {
 		}
 
 		clonedJob := value.Clone()
+		if sc.vpa != nil {
+			for _, task := range clonedJob.Tasks {
+				sc.vpa.recommend(task)
+			}
+		}
+		if len(sc.gracePeriods) > 0 {
+			for _, task := range clonedJob.Tasks {
+				task.EvictionProtected = sc.gracePeriods.Protects(podPriority(task.Pod))
+			}
+		}
 
 		cloneJobLock.Lock()
 		snapshot.Jobs[value.UID] = clonedJob
@@ -1369,6 +2061,11 @@ func (sc *SchedulerCache) Snapshot() *schedulingapi.ClusterInfo {
 			continue
 		}
 
+		if value.Hibernated {
+			klog.V(4).Infof("Job <%v/%v> is hibernated, ignore it.", value.Namespace, value.Name)
+			continue
+		}
+
 		if _, found := snapshot.Queues[value.Queue]; !found {
 			klog.V(3).Infof("The Queue <%v> of Job <%v/%v> does not exist, ignore it.",
 				value.Queue, value.Namespace, value.Name)
@@ -1476,7 +2173,9 @@ func (sc *SchedulerCache) RecordJobStatusEvent(job *schedulingapi.JobInfo, updat
 
 // UpdateJobStatus update the status of job and its tasks.
 func (sc *SchedulerCache) UpdateJobStatus(job *schedulingapi.JobInfo, updatePG bool) (*schedulingapi.JobInfo, error) {
-	if updatePG {
+	if updatePG && sc.statusQueue != nil {
+		sc.statusQueue.add(job.PodGroup.Clone(), sc.isPhaseTransition(job))
+	} else if updatePG {
 		pg, err := sc.StatusUpdater.UpdatePodGroup(job.PodGroup)
 		if err != nil {
 			return nil, err
@@ -1489,9 +2188,27 @@ func (sc *SchedulerCache) UpdateJobStatus(job *schedulingapi.JobInfo, updatePG b
 	return job, nil
 }
 
+// isPhaseTransition returns whether the phase of the podgroup of the job differs from the one of
+// the cache.
+func (sc *SchedulerCache) isPhaseTransition(job *schedulingapi.JobInfo) bool {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+	cached, found := sc.Jobs[job.UID]
+	return !found || cached.PodGroup == nil || cached.PodGroup.Status.Phase != job.PodGroup.Status.Phase
+}
+
 // UpdateQueueStatus update the status of queue.
 func (sc *SchedulerCache) UpdateQueueStatus(queue *schedulingapi.QueueInfo) error {
-	return sc.StatusUpdater.UpdateQueueStatus(queue)
+	if err := sc.StatusUpdater.UpdateQueueStatus(queue); err != nil {
+		return err
+	}
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+	if cached, found := sc.Queues[queue.UID]; found {
+		cached.SchedulingLatency = queue.SchedulingLatency
+	}
+	return nil
 }
 
 func (sc *SchedulerCache) recordPodGroupEvent(podGroup *schedulingapi.PodGroup, eventType, reason, msg string) {
@@ -1507,6 +2224,66 @@ func (sc *SchedulerCache) recordPodGroupEvent(podGroup *schedulingapi.PodGroup,
 	sc.Recorder.Eventf(pg, eventType, reason, msg)
 }
 
+// getSchedulerNames returns the scheduler names the pods of which are scheduled by the cache.
+func (sc *SchedulerCache) getSchedulerNames() []string {
+	sc.schedulerNamesLock.RLock()
+	defer sc.schedulerNamesLock.RUnlock()
+	return sc.schedulerNames
+}
+
+// AddSchedulerNames adds the scheduler names of the profiles. The pods of these names
+// already listed were only accounted on their node as pods of other schedulers, they
+// are cached again as tasks of their jobs.
+func (sc *SchedulerCache) AddSchedulerNames(names ...string) {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	current := sc.getSchedulerNames()
+	var added []string
+	for _, name := range names {
+		if !slices.Contains(current, name) && !slices.Contains(added, name) {
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	klog.V(2).Infof("Add scheduler names %v of the profiles to %v", added, current)
+
+	var pods []*v1.Pod
+	if sc.podInformer != nil {
+		listed, err := sc.podInformer.Lister().List(labels.Everything())
+		if err != nil {
+			klog.Errorf("Failed to list the pods of scheduler names %v: %v", added, err)
+		}
+		for _, pod := range listed {
+			if !slices.Contains(added, pod.Spec.SchedulerName) {
+				continue
+			}
+			if sc.cachesPod(pod) {
+				if err := sc.deletePod(pod); err != nil {
+					klog.Errorf("Failed to delete pod <%s/%s> from cache: %v", pod.Namespace, pod.Name, err)
+				}
+			}
+			pods = append(pods, pod)
+		}
+	}
+
+	sc.schedulerNamesLock.Lock()
+	// the slice is copied as readers may still hold the previous one
+	sc.schedulerNames = append(slices.Clone(current), added...)
+	sc.schedulerNamesLock.Unlock()
+
+	for _, pod := range pods {
+		if !sc.cachesPod(pod) {
+			continue
+		}
+		if err := sc.addPod(pod); err != nil {
+			klog.Errorf("Failed to add pod <%s/%s> into cache: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+}
+
 func (sc *SchedulerCache) SetMetricsConf(conf map[string]string) {
 	sc.metricsConf = conf
 }
@@ -19,15 +19,41 @@ package cache
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	appslisterv1 "k8s.io/client-go/listers/apps/v1"
+	nodelisterv1 "k8s.io/client-go/listers/node/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+
+	"volcano.sh/volcano/cmd/scheduler/app/options"
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/util"
 )
@@ -99,6 +125,12 @@ func TestGetOrCreateJob(t *testing.T) {
 		[]metav1.OwnerReference{owner2}, make(map[string]string))
 	pi3 := api.NewTaskInfo(pod3)
 
+	pod4 := buildPod("c1", "p4", "n1", v1.PodRunning, api.BuildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner1}, make(map[string]string))
+	pod4.Spec.SchedulerName = "default-scheduler"
+	pi4 := api.NewTaskInfo(pod4)
+	pi4.Job = "j1"
+
 	cache := &SchedulerCache{
 		Nodes:          make(map[string]*api.NodeInfo),
 		Jobs:           make(map[api.JobID]*api.JobInfo),
@@ -121,6 +153,10 @@ func TestGetOrCreateJob(t *testing.T) {
 			task:   pi3,
 			gotJob: false,
 		},
+		{
+			task:   pi4,
+			gotJob: false,
+		},
 	}
 	for i, test := range tests {
 		result := cache.getOrCreateJob(test.task) != nil
@@ -339,3 +375,1156 @@ func TestBindTasks(t *testing.T) {
 		t.Fatalf("succesfully binding task should have 1 event")
 	}
 }
+
+func TestStaleBindings(t *testing.T) {
+	owner := buildOwnerReference("j1")
+	pods := []api.ScalarResource{{Name: "pods", Value: "10"}}
+	n1 := api.NewNodeInfo(buildNode("n1", api.BuildResourceList("4000m", "10G", pods...)))
+	n2 := api.NewNodeInfo(buildNode("n2", api.BuildResourceList("4000m", "10G", pods...)))
+	sc := &SchedulerCache{Nodes: map[string]*api.NodeInfo{"n1": n1, "n2": n2}}
+
+	placed := func(job, name, node string) *api.TaskInfo {
+		task := api.NewTaskInfo(buildPod("c1", name, node, v1.PodPending, api.BuildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, make(map[string]string)))
+		task.Job = api.JobID(job)
+		task.Status = api.Binding
+		if node, found := sc.Nodes[node]; found {
+			if err := node.AddTask(task); err != nil {
+				t.Fatalf("failed to add task %s to node %s: %v", name, node.Name, err)
+			}
+		}
+		return task
+	}
+	// j1 and j2 are placed on both nodes, j2 last, and j3 on a node that was deleted since
+	j1p1, j1p2 := placed("j1", "j1-p1", "n1"), placed("j1", "j1-p2", "n2")
+	j2p1, j2p2 := placed("j2", "j2-p1", "n1"), placed("j2", "j2-p2", "n2")
+	j3p1 := placed("j3", "j3-p1", "n3")
+	// a pod of another scheduler started on n1 after the tasks were placed, overcommitting it by 500m
+	other := api.NewTaskInfo(buildPod("c1", "other", "n1", v1.PodRunning, api.BuildResourceList("2500m", "1G"), nil, make(map[string]string)))
+	if err := n1.AddTask(other); err != nil {
+		t.Fatalf("failed to add the pod of another scheduler: %v", err)
+	}
+
+	stale := sc.staleBindings([]*api.TaskInfo{j1p1, j1p2, j2p1, j2p2, j3p1})
+	// rejecting the last placed task on n1 is enough, and the rest of its gang goes with it
+	for _, task := range []*api.TaskInfo{j2p1, j2p2, j3p1} {
+		if _, found := stale[task.UID]; !found {
+			t.Errorf("expected the binding of %s to be stale", task.Name)
+		}
+	}
+	for _, task := range []*api.TaskInfo{j1p1, j1p2} {
+		if reason, found := stale[task.UID]; found {
+			t.Errorf("expected the binding of %s to be feasible, got %s", task.Name, reason)
+		}
+	}
+}
+
+func TestIsNodeLeaseStale(t *testing.T) {
+	now := time.Now()
+	buildLease := func(renewTime *time.Time) *coordinationv1.Lease {
+		lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "n1", Namespace: v1.NamespaceNodeLease}}
+		if renewTime != nil {
+			lease.Spec.RenewTime = &metav1.MicroTime{Time: *renewTime}
+		}
+		return lease
+	}
+	fresh := now.Add(-10 * time.Second)
+	stale := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		lease         *coordinationv1.Lease
+		staleDuration time.Duration
+		expected      bool
+	}{
+		{name: "recently renewed lease", lease: buildLease(&fresh), staleDuration: 30 * time.Second, expected: false},
+		{name: "lease not renewed in time", lease: buildLease(&stale), staleDuration: 30 * time.Second, expected: true},
+		{name: "lease never renewed", lease: buildLease(nil), staleDuration: 30 * time.Second, expected: false},
+		{name: "check disabled", lease: buildLease(&stale), staleDuration: 0, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isNodeLeaseStale(test.lease, test.staleDuration, now); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestPartialGangBoundTasks(t *testing.T) {
+	buildTask := func(name string, status api.TaskStatus) *api.TaskInfo {
+		pod := buildPod("c1", name, "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), nil, nil)
+		task := api.NewTaskInfo(pod)
+		task.Status = status
+		return task
+	}
+	running, binding, failed := buildTask("p1", api.Running), buildTask("p2", api.Binding), buildTask("p3", api.Binding)
+
+	tests := []struct {
+		name         string
+		minAvailable int32
+		failed       map[api.TaskID]bool
+		expected     int
+	}{
+		{name: "gang ready without the failed task", minAvailable: 2, failed: map[api.TaskID]bool{failed.UID: true}, expected: 0},
+		{name: "gang not ready without the failed task", minAvailable: 3, failed: map[api.TaskID]bool{failed.UID: true}, expected: 2},
+		{name: "failed task bound again", minAvailable: 3, failed: nil, expected: 0},
+		{name: "not a gang", minAvailable: 1, failed: map[api.TaskID]bool{running.UID: true, binding.UID: true}, expected: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			job := api.NewJobInfo("c1/j1", running, binding, failed)
+			job.MinAvailable = test.minAvailable
+			if got := partialGangBoundTasks(job, test.failed); len(got) != test.expected {
+				t.Errorf("expected %d tasks to evict, got %d", test.expected, len(got))
+			}
+		})
+	}
+}
+
+func TestGangBindRetries(t *testing.T) {
+	sc := &SchedulerCache{gangBindRetryPeriod: time.Hour}
+	sc.retryGangBind("c1/j1")
+	timer := sc.gangBindRetries["c1/j1"]
+	sc.retryGangBind("c1/j1")
+	if timer == nil || sc.gangBindRetries["c1/j1"] != timer {
+		t.Fatalf("expected the retry period to run from the first failure")
+	}
+	sc.retryGangBind("c1/j2")
+
+	sc.stopGangBindRetries("c1/j1")
+	if _, found := sc.gangBindRetries["c1/j1"]; found || timer.Stop() {
+		t.Errorf("expected the retry of the deleted job stopped")
+	}
+	sc.stopGangBindRetries()
+	if len(sc.gangBindRetries) != 0 {
+		t.Errorf("expected all the retries stopped, got %v", sc.gangBindRetries)
+	}
+
+	sc.draining = true
+	sc.retryGangBind("c1/j1")
+	if len(sc.gangBindRetries) != 0 {
+		t.Errorf("expected no retry while draining, got %v", sc.gangBindRetries)
+	}
+}
+
+func TestFaultInjector(t *testing.T) {
+	binder := util.NewFakeBinder(10)
+	sc := NewCustomMockSchedulerCache("fault-scheduler", binder, nil, nil, nil, nil, nil)
+	faults := NewFaultInjector(1)
+	faults.DropEventRate = 1
+	faults.BindFailureRate = 1
+	faults.StaleNodes.Insert("n1")
+	sc.InjectFaults(faults)
+
+	sc.AddOrUpdateNode(buildNode("n1", api.BuildResourceList("2", "4Gi")))
+	sc.AddOrUpdateNode(buildNode("n1", api.BuildResourceList("4", "8Gi")))
+	if cpu := sc.Nodes["n1"].Allocatable.MilliCPU; cpu != 2000 {
+		t.Errorf("expected the stale allocatable cpu 2000 of n1, got %v", cpu)
+	}
+
+	pod := util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", nil, nil)
+	sc.AddPod(pod)
+	if len(sc.Jobs) != 0 {
+		t.Errorf("expected the pod event to be dropped, got jobs %v", sc.Jobs)
+	}
+
+	task := api.NewTaskInfo(pod)
+	if errMsg := sc.Binder.Bind(nil, []*api.TaskInfo{task}); errMsg[task.UID] == "" {
+		t.Errorf("expected the bind of %s to fail", task.Name)
+	}
+	if binder.Length() != 0 {
+		t.Errorf("expected no bind to reach the binder, got %v", binder.Binds())
+	}
+}
+
+func TestDrain(t *testing.T) {
+	owner := buildOwnerReference("j1")
+	task := api.NewTaskInfo(buildPod("c1", "p1", "n1", v1.PodPending, api.BuildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, make(map[string]string)))
+	task.Status = api.Binding
+	task.NodeName = "n1"
+	statusUpdater := &recordingStatusUpdater{}
+	sc := &SchedulerCache{
+		Jobs:            map[api.JobID]*api.JobInfo{"c1/j1": api.NewJobInfo("c1/j1", task)},
+		BindFlowChannel: make(chan *api.TaskInfo, 1),
+		StatusUpdater:   statusUpdater,
+	}
+
+	sc.inFlight.Add(1)
+	assumed := sc.Drain(50 * time.Millisecond)
+	if len(assumed) != 1 || assumed[0].UID != task.UID {
+		t.Errorf("expected task %s to be reported as assumed, got %v", task.Name, assumed)
+	}
+	if len(statusUpdater.pods) != 1 || statusUpdater.pods[0].Status.NominatedNodeName != "n1" {
+		t.Errorf("expected the node of the assumed task to be nominated on its pod, got %v", statusUpdater.pods)
+	}
+	if err := sc.AddBindTask(task); err == nil {
+		t.Errorf("expected the binds to be refused while draining")
+	}
+
+	sc.inFlight.Add(-1)
+	if assumed = sc.Drain(time.Second); len(assumed) != 0 {
+		t.Errorf("expected no assumed task once the in-flight bind completed, got %v", assumed)
+	}
+}
+
+// recordingStatusUpdater records the pods whose status is updated.
+type recordingStatusUpdater struct {
+	pods []*v1.Pod
+}
+
+func (u *recordingStatusUpdater) UpdatePodStatus(pod *v1.Pod) (*v1.Pod, error) {
+	u.pods = append(u.pods, pod)
+	return pod, nil
+}
+
+func (u *recordingStatusUpdater) UpdatePodGroup(pg *api.PodGroup) (*api.PodGroup, error) {
+	return pg, nil
+}
+
+func (u *recordingStatusUpdater) UpdateQueueStatus(queue *api.QueueInfo) error {
+	return nil
+}
+
+func TestDuplicatePodGroupOwners(t *testing.T) {
+	owner := buildOwnerReference("w1")
+	podGroup := func(name string, created time.Time) *api.PodGroup {
+		return &api.PodGroup{
+			PodGroup: scheduling.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         "c1",
+					Name:              name,
+					CreationTimestamp: metav1.NewTime(created),
+					OwnerReferences:   []metav1.OwnerReference{owner},
+				},
+				Spec: scheduling.PodGroupSpec{Queue: "default"},
+			},
+			Version: api.PodGroupVersionV1Beta1,
+		}
+	}
+	task := func(name, group string) *api.TaskInfo {
+		pod := buildPod("c1", name, "", v1.PodPending, api.BuildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, nil)
+		pod.Annotations = map[string]string{schedulingv1beta1.KubeGroupNameAnnotationKey: group}
+		return api.NewTaskInfo(pod)
+	}
+	sc := &SchedulerCache{
+		Nodes: map[string]*api.NodeInfo{},
+		Jobs:  map[api.JobID]*api.JobInfo{},
+	}
+	now := time.Now()
+
+	// the operator's podgroup, which the pods reference, is seen first, the one created before by
+	// the controller later
+	sc.setPodGroup(podGroup("pg-b", now))
+	sc.addTask(task("p1", "pg-b"))
+	sc.setPodGroup(podGroup("pg-a", now.Add(-time.Minute)))
+	sc.addTask(task("p2", "pg-b"))
+
+	if original, found := sc.owners.duplicateOf["c1/pg-b"]; !found || original != "c1/pg-a" {
+		t.Fatalf("expected c1/pg-b to be a duplicate of c1/pg-a, got %v", sc.owners.duplicateOf)
+	}
+	if _, found := sc.owners.duplicateOf["c1/pg-a"]; found {
+		t.Errorf("expected the oldest podgroup c1/pg-a not to be a duplicate")
+	}
+	// the pods are still scheduled with the podgroup they reference
+	if tasks := len(sc.Jobs["c1/pg-b"].Tasks); tasks != 2 {
+		t.Errorf("expected the 2 tasks in c1/pg-b, got %d", tasks)
+	}
+	if tasks := len(sc.Jobs["c1/pg-a"].Tasks); tasks != 0 {
+		t.Errorf("expected no task in c1/pg-a, got %d", tasks)
+	}
+
+	sc.Jobs["c1/pg-a"].UnsetPodGroup()
+	sc.trackPodGroupOwner("c1/pg-a")
+	if len(sc.owners.duplicateOf) != 0 {
+		t.Errorf("expected no duplicate once the oldest podgroup is deleted, got %v", sc.owners.duplicateOf)
+	}
+}
+
+func TestDefaultEvictor(t *testing.T) {
+	for _, evictByDelete := range []bool{false, true} {
+		pod := buildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), nil, nil)
+		client := fake.NewSimpleClientset(pod)
+		evicted := false
+		client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			evicted = action.GetSubresource() == "eviction"
+			return evicted, nil, nil
+		})
+		evictor := &defaultEvictor{kubeclient: client, recorder: record.NewFakeRecorder(10), evictByDelete: evictByDelete}
+
+		if err := evictor.Evict(pod, "preempted"); err != nil {
+			t.Fatalf("evictByDelete %v: unexpected error: %v", evictByDelete, err)
+		}
+		_, err := client.CoreV1().Pods("c1").Get(context.TODO(), "p1", metav1.GetOptions{})
+		if deleted := apierrors.IsNotFound(err); evicted == evictByDelete || deleted != evictByDelete {
+			t.Errorf("evictByDelete %v: expected the pod to be deleted %v, got evicted %v and deleted %v",
+				evictByDelete, evictByDelete, evicted, deleted)
+		}
+	}
+}
+
+func TestDefaultEvictorRefused(t *testing.T) {
+	pod := buildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), nil, nil)
+	client := fake.NewSimpleClientset(pod)
+	client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewTooManyRequests("disruption budget exhausted", 10)
+	})
+	evictor := &defaultEvictor{kubeclient: client, recorder: record.NewFakeRecorder(10)}
+
+	if err := evictor.Evict(pod, "preempted"); err == nil {
+		t.Fatalf("expected the eviction to be refused")
+	}
+	got, err := client.CoreV1().Pods("c1").Get(context.TODO(), "p1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the pod: %v", err)
+	}
+	if _, condition := podutil.GetPodCondition(&got.Status, v1.PodReady); condition != nil && condition.Reason == "Evict" {
+		t.Errorf("expected the pod of a refused eviction not to be marked evicted, got %v", condition)
+	}
+}
+
+func TestEvictionGracePeriods(t *testing.T) {
+	bands, err := options.ParseGracePeriodBands(map[string]string{"0": "5s", "100": "500ms", "1000": "30s", "1000000": options.NeverEvict})
+	if err != nil {
+		t.Fatalf("failed to parse the grace periods: %v", err)
+	}
+
+	for _, test := range []struct {
+		priority int32
+		grace    *int64
+		evicted  bool
+	}{
+		{priority: -1, evicted: true},
+		{priority: 10, grace: int64Ptr(5), evicted: true},
+		{priority: 500, grace: int64Ptr(1), evicted: true},
+		{priority: 5000, grace: int64Ptr(30), evicted: true},
+		{priority: 1000000, evicted: false},
+	} {
+		pod := buildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), nil, nil)
+		pod.Spec.Priority = &test.priority
+		client := fake.NewSimpleClientset(pod)
+		var eviction *policyv1.Eviction
+		client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			eviction = action.(clienttesting.CreateAction).GetObject().(*policyv1.Eviction)
+			return true, nil, nil
+		})
+		evictor := &defaultEvictor{kubeclient: client, recorder: record.NewFakeRecorder(10), gracePeriods: bands}
+
+		err := evictor.Evict(pod, "preempted")
+		if (err == nil) != test.evicted || (eviction != nil) != test.evicted {
+			t.Fatalf("priority %d: expected evicted %v, got eviction %v and error %v", test.priority, test.evicted, eviction, err)
+		}
+		if eviction != nil && !reflect.DeepEqual(eviction.DeleteOptions.GracePeriodSeconds, test.grace) {
+			t.Errorf("priority %d: expected grace period %v, got %v", test.priority, test.grace, eviction.DeleteOptions.GracePeriodSeconds)
+		}
+		if bands.Protects(test.priority) == test.evicted {
+			t.Errorf("priority %d: expected protected %v", test.priority, !test.evicted)
+		}
+	}
+}
+
+func TestSnapshotEvictionProtected(t *testing.T) {
+	bands, err := options.ParseGracePeriodBands(map[string]string{"0": "5s", "1000000": options.NeverEvict})
+	if err != nil {
+		t.Fatalf("failed to parse the grace periods: %v", err)
+	}
+	sc := newMockSchedulerCache("volcano")
+	sc.gracePeriods = bands
+	sc.Queues["q1"] = api.NewQueueInfo(&scheduling.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}})
+
+	low, high := int32(10), int32(1000000)
+	annotated := buildPod("c1", "annotated", "", v1.PodPending, api.BuildResourceList("1", "1G"), nil, nil)
+	annotated.Spec.Priority = &low
+	annotated.Annotations = map[string]string{api.TaskPriorityAnnotation: "2000000"}
+	protected := buildPod("c1", "protected", "", v1.PodPending, api.BuildResourceList("1", "1G"), nil, nil)
+	protected.Spec.Priority = &high
+	job := api.NewJobInfo("c1/pg1", api.NewTaskInfo(annotated), api.NewTaskInfo(protected))
+	job.SetPodGroup(&api.PodGroup{PodGroup: scheduling.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: "pg1"},
+		Spec:       scheduling.PodGroupSpec{Queue: "q1"},
+	}})
+	sc.Jobs[job.UID] = job
+
+	snapshot := sc.Snapshot()
+	for _, task := range snapshot.Jobs[job.UID].Tasks {
+		if expected := task.Name == "protected"; task.EvictionProtected != expected {
+			t.Errorf("task %s: expected protected %v, got %v", task.Name, expected, task.EvictionProtected)
+		}
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestNodeQuarantine(t *testing.T) {
+	now := time.Now()
+	nq := newNodeQuarantine(3, time.Minute, 5*time.Minute)
+
+	nq.recordFailure("n1", failureBind, now)
+	nq.recordFailure("n1", failureAdmission, now.Add(30*time.Second))
+	// the first failures are out of the window
+	nq.recordFailure("n1", failureBind, now.Add(2*time.Minute))
+	if quarantined := nq.quarantined(now.Add(2 * time.Minute)); len(quarantined) != 0 {
+		t.Fatalf("expected no node quarantined for failures out of the window, got %v", quarantined)
+	}
+
+	nq.recordFailure("n1", failureBind, now.Add(2*time.Minute+10*time.Second))
+	nq.recordFailure("n1", failureBind, now.Add(2*time.Minute+20*time.Second))
+	quarantined := nq.quarantined(now.Add(3 * time.Minute))
+	if until, found := quarantined["n1"]; !found || !until.Equal(now.Add(7*time.Minute+20*time.Second)) {
+		t.Fatalf("expected n1 quarantined for 5m after its third failure, got %v", quarantined)
+	}
+
+	if quarantined := nq.quarantined(now.Add(8 * time.Minute)); len(quarantined) != 0 {
+		t.Errorf("expected n1 released after its quarantine, got %v", quarantined)
+	}
+}
+
+// failingBinder fails the binds to the nodes with the message of the node.
+type failingBinder map[string]string
+
+func (fb failingBinder) Bind(_ kubernetes.Interface, tasks []*api.TaskInfo) map[api.TaskID]string {
+	errMsg := map[api.TaskID]string{}
+	for _, task := range tasks {
+		if msg, found := fb[task.NodeName]; found {
+			errMsg[task.UID] = msg
+		}
+	}
+	return errMsg
+}
+
+func TestBindFailuresQuarantine(t *testing.T) {
+	binder := failingBinder{
+		"n1": "failed to allocate the devices of the node",
+		"n2": apierrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "create", 1).Error(),
+	}
+	sc := NewCustomMockSchedulerCache("volcano", binder, nil, nil, nil, nil, record.NewFakeRecorder(100))
+	sc.quarantine = newNodeQuarantine(2, time.Minute, time.Minute)
+	sc.AddOrUpdateNode(buildNode("n1", api.BuildResourceList("8", "8G")))
+	sc.AddOrUpdateNode(buildNode("n2", api.BuildResourceList("8", "8G")))
+
+	var tasks []*api.TaskInfo
+	for i, node := range []string{"n1", "n1", "n1", "n2", "n2", "n2"} {
+		pod := buildPod("c1", fmt.Sprintf("p%d", i), node, v1.PodPending, api.BuildResourceList("1", "1G"), nil, nil)
+		tasks = append(tasks, api.NewTaskInfo(pod))
+	}
+	sc.Bind(tasks)
+	if quarantined := sc.quarantine.quarantined(time.Now()); len(quarantined) != 0 {
+		t.Fatalf("expected the failures of a bind counted once per node, got %v quarantined", quarantined)
+	}
+
+	sc.Bind(tasks)
+	quarantined := sc.quarantine.quarantined(time.Now())
+	if _, found := quarantined["n1"]; !found || len(quarantined) != 1 {
+		t.Errorf("expected only n1 quarantined after its second failed bind, got %v", quarantined)
+	}
+}
+
+func TestNodeBindFailure(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	for reason, expected := range map[string]bool{
+		"failed to allocate the devices of the node":                                          true,
+		"injected bind failure":                                                               true,
+		apierrors.NewServerTimeout(pods, "create", 1).Error():                                 false,
+		apierrors.NewConflict(pods, "p1", fmt.Errorf("conflict")).Error():                     false,
+		apierrors.NewNotFound(pods, "p1").Error():                                             false,
+		apierrors.NewTooManyRequests("Too many requests, please try again later.", 1).Error(): false,
+		apierrors.NewInternalError(fmt.Errorf("etcdserver: request timed out")).Error():       false,
+		apierrors.NewTimeoutError("timeout", 1).Error():                                       false,
+		"Post \"https://10.0.0.1/api\": dial tcp 10.0.0.1:443: connect: connection refused":   false,
+		context.DeadlineExceeded.Error():                                                      false,
+		"etcdserver: leader changed":                                                          false,
+	} {
+		if got := nodeBindFailure(reason); got != expected {
+			t.Errorf("reason %q: expected node failure %v, got %v", reason, expected, got)
+		}
+	}
+}
+
+func TestAdmissionRejected(t *testing.T) {
+	running := buildPod("c1", "p1", "n1", v1.PodPending, api.BuildResourceList("1000m", "1G"), nil, make(map[string]string))
+	rejected := func(reason string) *v1.Pod {
+		pod := running.DeepCopy()
+		pod.Status.Phase = v1.PodFailed
+		pod.Status.Reason = reason
+		return pod
+	}
+
+	for reason, expected := range map[string]bool{
+		"OutOfcpu":                 true,
+		"UnexpectedAdmissionError": true,
+		"NodeAffinity":             true,
+		"Evicted":                  false,
+		"":                         false,
+	} {
+		if got := admissionRejected(running, rejected(reason)); got != expected {
+			t.Errorf("reason %q: expected rejected %v, got %v", reason, expected, got)
+		}
+	}
+}
+
+func TestJobBackoff(t *testing.T) {
+	sc := NewDefaultMockSchedulerCache("volcano")
+	sc.backoff = newJobBackoff(time.Second, 3*time.Second)
+	job := api.JobID("ns1/pg1")
+	now := time.Now()
+
+	for i, expected := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		sc.RecordScheduleAttempt(job, false, now)
+		if !sc.BackedOff(job, now.Add(expected-time.Millisecond)) {
+			t.Errorf("failure %d: expected the job backed off for %v", i+1, expected)
+		}
+		if sc.BackedOff(job, now.Add(expected)) {
+			t.Errorf("failure %d: expected the job retried after %v", i+1, expected)
+		}
+	}
+
+	node := util.BuildNode("n1", api.BuildResourceList("2", "4Gi"), map[string]string{})
+	heartbeat := node.DeepCopy()
+	heartbeat.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	sc.UpdateNode(node, heartbeat)
+	if !sc.BackedOff(job, now) {
+		t.Errorf("expected the job still backed off after a node heartbeat")
+	}
+	grown := node.DeepCopy()
+	grown.Status.Allocatable = api.BuildResourceList("4", "8Gi")
+	sc.UpdateNode(node, grown)
+	if sc.BackedOff(job, now) {
+		t.Errorf("expected the job retried at once after the node grew")
+	}
+
+	sc.RecordScheduleAttempt(job, true, now)
+	sc.RecordScheduleAttempt(job, false, now)
+	if sc.BackedOff(job, now.Add(time.Second)) {
+		t.Errorf("expected the backoff reset after the job was scheduled")
+	}
+}
+
+func TestVPARecommendations(t *testing.T) {
+	vpa := newVPARecommendations()
+	vpa.update(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]interface{}{"namespace": "c1", "name": "vpa1"},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "batch.volcano.sh/v1alpha1", "kind": "Job", "name": "job1"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{"containerName": "c", "target": map[string]interface{}{"cpu": "4", "memory": "1Gi"}},
+				},
+			},
+		},
+	}})
+
+	isController := true
+	pod := buildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("2", "4Gi"), []metav1.OwnerReference{
+		{APIVersion: "batch.volcano.sh/v1alpha1", Kind: "Job", Name: "job1", Controller: &isController},
+	}, make(map[string]string))
+	pod.Spec.Containers[0].Name = "c"
+	task := api.NewTaskInfo(pod)
+	vpa.recommend(task)
+	if task.Recommended == nil || task.Recommended.MilliCPU != 2000 || task.Recommended.Memory != 1024*1024*1024 {
+		t.Fatalf("expected the memory lowered to the recommendation and the cpu kept, got %v", task.Recommended)
+	}
+	if task.ShareResreq() != task.Recommended || task.Resreq.Memory != 4*1024*1024*1024 {
+		t.Errorf("expected the request kept and the recommendation shared, got %v and %v", task.Resreq, task.ShareResreq())
+	}
+
+	vpa.delete(&unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "c1", "name": "vpa1"},
+	}})
+	task = api.NewTaskInfo(pod)
+	vpa.recommend(task)
+	if task.Recommended != nil {
+		t.Errorf("expected no recommendation after the autoscaler was deleted, got %v", task.Recommended)
+	}
+}
+
+func TestResourceServed(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	if resourceServed(client.Discovery(), vpaResource) {
+		t.Fatalf("expected the VerticalPodAutoscalers not served without their CRD")
+	}
+
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: vpaResource.GroupVersion().String(),
+		APIResources: []metav1.APIResource{{Name: "verticalpodautoscalercheckpoints"}},
+	}}
+	if resourceServed(client.Discovery(), vpaResource) {
+		t.Fatalf("expected the VerticalPodAutoscalers not served with only the other resources of their group")
+	}
+
+	client.Resources[0].APIResources = append(client.Resources[0].APIResources, metav1.APIResource{Name: vpaResource.Resource})
+	if !resourceServed(client.Discovery(), vpaResource) {
+		t.Errorf("expected the VerticalPodAutoscalers served with their CRD")
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	start := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	mw := newMaintenanceWindows()
+	mw.update(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "scheduling.volcano.sh/v1alpha1",
+		"kind":       "MaintenanceWindow",
+		"metadata":   map[string]interface{}{"name": "mw1"},
+		"spec": map[string]interface{}{
+			"start":        start.Format(time.RFC3339),
+			"end":          start.Add(4 * time.Hour).Format(time.RFC3339),
+			"queues":       []interface{}{"q1"},
+			"nodeSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"pool": "a"}},
+			"drain":        true,
+		},
+	}})
+
+	n1 := buildNode("n1", api.BuildResourceList("2", "4Gi"))
+	n1.Labels = map[string]string{"pool": "a"}
+	n2 := buildNode("n2", api.BuildResourceList("2", "4Gi"))
+	nodes := map[string]*api.NodeInfo{"n1": api.NewNodeInfo(n1), "n2": api.NewNodeInfo(n2)}
+
+	if active := mw.active(start.Add(-time.Minute), nodes); len(active) != 0 {
+		t.Errorf("expected no window before its start, got %v", active)
+	}
+	if active := mw.active(start.Add(4*time.Hour), nodes); len(active) != 0 {
+		t.Errorf("expected no window at its end, got %v", active)
+	}
+	active := mw.active(start.Add(time.Hour), nodes)
+	if len(active) != 1 {
+		t.Fatalf("expected the window in progress, got %v", active)
+	}
+	if window := active[0]; !window.Drain || !window.Queues.Has("q1") || !window.Nodes.Has("n1") || window.Nodes.Has("n2") {
+		t.Errorf("expected queue q1 and node n1 drained, got %+v", window)
+	}
+
+	mw.delete(&unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "mw1"},
+	}})
+	if active := mw.active(start.Add(time.Hour), nodes); len(active) != 0 {
+		t.Errorf("expected no window after it was deleted, got %v", active)
+	}
+}
+
+func TestReservations(t *testing.T) {
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	rs := newReservations()
+	rs.update(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "scheduling.volcano.sh/v1alpha1",
+		"kind":       "Reservation",
+		"metadata":   map[string]interface{}{"name": "r1"},
+		"spec": map[string]interface{}{
+			"queue":        "q1",
+			"nodeSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"node-type": "gpu"}},
+			"tolerations": []interface{}{
+				map[string]interface{}{"key": "dedicated", "operator": "Exists", "effect": "NoSchedule"},
+			},
+			"resources": map[string]interface{}{"nvidia.com/gpu": "8"},
+			"start":     start.Format(time.RFC3339),
+			"end":       start.Add(24 * time.Hour).Format(time.RFC3339),
+		},
+	}})
+
+	if active := rs.active(start.Add(-time.Minute)); len(active) != 0 {
+		t.Errorf("expected no reservation before its start, got %v", active)
+	}
+	if active := rs.active(start.Add(24 * time.Hour)); len(active) != 0 {
+		t.Errorf("expected no reservation at its end, got %v", active)
+	}
+	active := rs.active(start.Add(time.Hour))
+	if len(active) != 1 {
+		t.Fatalf("expected the reservation in progress, got %v", active)
+	}
+	r := active[0]
+	gpus := r.Resources["nvidia.com/gpu"]
+	if r.Queue != "q1" || gpus.Value() != 8 || len(r.Tolerations) != 1 ||
+		!r.NodeSelector.Matches(labels.Set{"node-type": "gpu"}) || r.NodeSelector.Matches(labels.Set{}) {
+		t.Errorf("expected 8 GPUs of the gpu nodes reserved for queue q1, got %+v", r)
+	}
+
+	rs.delete(&unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "r1"},
+	}})
+	if active := rs.active(start.Add(time.Hour)); len(active) != 0 {
+		t.Errorf("expected no reservation after it was deleted, got %v", active)
+	}
+}
+
+func TestResourceFlavors(t *testing.T) {
+	rf := newResourceFlavors()
+	rf.update(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "scheduling.volcano.sh/v1alpha1",
+		"kind":       "ResourceFlavor",
+		"metadata":   map[string]interface{}{"name": "a100"},
+		"spec": map[string]interface{}{
+			"nodeLabels": map[string]interface{}{"gpu-model": "a100"},
+			"nodeTaints": []interface{}{
+				map[string]interface{}{"key": "dedicated", "value": "training", "effect": "NoSchedule"},
+			},
+		},
+	}})
+
+	f := rf.snapshot()["a100"]
+	if f == nil || len(f.NodeTaints) != 1 ||
+		!f.NodeLabels.Matches(labels.Set{"gpu-model": "a100"}) || f.NodeLabels.Matches(labels.Set{"gpu-model": "v100"}) {
+		t.Errorf("expected the a100 nodes tainted for training, got %+v", f)
+	}
+
+	rf.delete(&unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "a100"},
+	}})
+	if flavors := rf.snapshot(); len(flavors) != 0 {
+		t.Errorf("expected no flavor after it was deleted, got %v", flavors)
+	}
+}
+
+func TestEventRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events")
+	recorder, err := newEventRecorder(path, 200)
+	if err != nil {
+		t.Fatalf("failed to create the event recorder: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		recorder.record(EventKindCycle, "", nil)
+	}
+	recorder.close()
+	// recording after the close is a no-op
+	recorder.record(EventKindCycle, "", nil)
+	recorder.close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the recorded events: %v", err)
+	}
+	if len(data) == 0 || len(data) > 200 {
+		t.Errorf("expected the recorded events capped to 200 bytes, got %d", len(data))
+	}
+	if lines := strings.Count(string(data), "\n"); lines == 10 {
+		t.Errorf("expected the recording stopped at the cap, got all the %d events", lines)
+	}
+}
+
+func TestRuntimeClassOverhead(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&nodev1.RuntimeClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kata"},
+		Handler:    "kata",
+		Overhead:   &nodev1.Overhead{PodFixed: api.BuildResourceList("250m", "160Mi")},
+	})
+	sc := NewDefaultMockSchedulerCache("volcano")
+	sc.runtimeClassLister = nodelisterv1.NewRuntimeClassLister(indexer)
+
+	pod := buildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), nil, make(map[string]string))
+	runtimeClass := "kata"
+	pod.Spec.RuntimeClassName = &runtimeClass
+	task, err := sc.NewTaskInfo(pod)
+	if err != nil {
+		t.Fatalf("failed to create the task: %v", err)
+	}
+	if task.Resreq.MilliCPU != 1250 || task.Resreq.Memory != float64(1024+160)*1024*1024 {
+		t.Errorf("expected the overhead of the runtime class in the request, got %v", task.Resreq)
+	}
+	if pod.Spec.Overhead != nil {
+		t.Errorf("expected the pod of the informer left unchanged")
+	}
+
+	// the overhead set by the admission controller is kept
+	pod.Spec.Overhead = api.BuildResourceList("500m", "0")
+	task, _ = sc.NewTaskInfo(pod)
+	if task.Resreq.MilliCPU != 1500 || task.Resreq.Memory != 1024*1024*1024 {
+		t.Errorf("expected the overhead of the pod in the request, got %v", task.Resreq)
+	}
+}
+
+func TestPodsOfOtherSchedulers(t *testing.T) {
+	sc := NewDefaultMockSchedulerCache("volcano")
+	sc.AddOrUpdateNode(buildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...)))
+
+	annotations := map[string]string{schedulingv1beta1.KubeGroupNameAnnotationKey: "pg1"}
+	ours := buildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), nil, make(map[string]string))
+	ours.Spec.SchedulerName = "volcano"
+	ours.Annotations = annotations
+	theirs := buildPod("c1", "p2", "n1", v1.PodRunning, api.BuildResourceList("2", "2Gi"), nil, make(map[string]string))
+	theirs.Spec.SchedulerName = "default-scheduler"
+	theirs.Annotations = annotations
+	pending := buildPod("c1", "p3", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), nil, make(map[string]string))
+	pending.Spec.SchedulerName = "default-scheduler"
+
+	for _, test := range []struct {
+		pod    *v1.Pod
+		cached bool
+	}{
+		{pod: ours, cached: true},
+		{pod: theirs, cached: true},
+		{pod: pending, cached: false},
+	} {
+		if cached := sc.cachesPod(test.pod); cached != test.cached {
+			t.Errorf("pod %s: expected cached %t, got %t", test.pod.Name, test.cached, cached)
+		}
+	}
+
+	sc.AddPod(ours)
+	sc.AddPod(theirs)
+	node := sc.Nodes["n1"]
+	if node.Used.MilliCPU != 3000 || node.Idle.MilliCPU != 1000 {
+		t.Errorf("expected both pods accounted on the node, got used %v, idle %v", node.Used, node.Idle)
+	}
+	job := sc.Jobs["c1/pg1"]
+	if job == nil || len(job.Tasks) != 1 {
+		t.Fatalf("expected only the pod of volcano in the job, got %v", job)
+	}
+	if _, found := job.Tasks[api.TaskID(ours.UID)]; !found {
+		t.Errorf("expected the pod of volcano in the job")
+	}
+
+	sc.DeletePod(theirs)
+	if node.Used.MilliCPU != 1000 || node.Idle.MilliCPU != 3000 {
+		t.Errorf("expected the pod of the other scheduler released from the node, got used %v, idle %v", node.Used, node.Idle)
+	}
+	if len(job.Tasks) != 1 {
+		t.Errorf("expected the job left unchanged, got %d tasks", len(job.Tasks))
+	}
+}
+
+func TestAddSchedulerNames(t *testing.T) {
+	sc := NewDefaultMockSchedulerCache("volcano")
+	sc.AddOrUpdateNode(buildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...)))
+
+	annotations := map[string]string{schedulingv1beta1.KubeGroupNameAnnotationKey: "pg1"}
+	running := buildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), nil, make(map[string]string))
+	running.Spec.SchedulerName = "interactive"
+	running.Annotations = annotations
+	pending := buildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), nil, make(map[string]string))
+	pending.Spec.SchedulerName = "interactive"
+	pending.Annotations = annotations
+	for _, pod := range []*v1.Pod{running, pending} {
+		sc.podInformer.Informer().GetStore().Add(pod)
+	}
+	// the running pod is only accounted on its node until the name of its profile is added
+	sc.AddPod(running)
+	if _, found := sc.Jobs["c1/pg1"]; found {
+		t.Fatalf("expected no job for the pods of an unknown scheduler name")
+	}
+
+	sc.AddSchedulerNames("interactive", "volcano")
+	job := sc.Jobs["c1/pg1"]
+	if job == nil || len(job.Tasks) != 2 {
+		t.Fatalf("expected both pods of the profile in the job, got %v", job)
+	}
+	if node := sc.Nodes["n1"]; node.Used.MilliCPU != 1000 {
+		t.Errorf("expected the running pod accounted once on the node, got used %v", node.Used)
+	}
+	if names := sc.getSchedulerNames(); !reflect.DeepEqual(names, []string{"volcano", "interactive"}) {
+		t.Errorf("expected the name of the profile added once, got %v", names)
+	}
+}
+
+func TestDaemonSetReservation(t *testing.T) {
+	now := time.Now()
+	daemonSet := func(name string, cpu string, nodeSelector map[string]string) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+			Spec: appsv1.DaemonSetSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						NodeSelector: nodeSelector,
+						Containers: []v1.Container{
+							{Resources: v1.ResourceRequirements{Requests: api.BuildResourceList(cpu, "100Mi")}},
+						},
+					},
+				},
+			},
+		}
+	}
+	logs := daemonSet("logs", "100m", nil)
+	gpu := daemonSet("gpu", "200m", map[string]string{"gpu": "true"})
+	started := daemonSet("started", "400m", nil)
+	tainted := daemonSet("tainted", "800m", nil)
+	tainted.Spec.Template.Spec.Tolerations = []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}}
+	daemonSets := []*appsv1.DaemonSet{logs, gpu, started, tainted}
+
+	n1 := buildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...))
+	n1.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+	n1.Spec.Taints = []v1.Taint{
+		{Key: "node.kubernetes.io/not-ready", Effect: v1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "batch", Effect: v1.TaintEffectNoSchedule},
+	}
+	node := api.NewNodeInfo(n1)
+	controller := true
+	pod := buildPod("kube-system", "started-1", "n1", v1.PodRunning, api.BuildResourceList("400m", "100Mi"),
+		[]metav1.OwnerReference{{Kind: "DaemonSet", Name: "started", UID: started.UID, Controller: &controller}}, nil)
+	if err := node.AddTask(api.NewTaskInfo(pod)); err != nil {
+		t.Fatalf("failed to add the pod of the DaemonSet: %v", err)
+	}
+
+	// logs does not tolerate the dedicated taint, only tainted is reserved
+	reserved := daemonSetReservation(node, daemonSets, 10*time.Minute, now)
+	if reserved.MilliCPU != 800 {
+		t.Errorf("expected 800m reserved, got %v", reserved)
+	}
+
+	n1.Spec.Taints = n1.Spec.Taints[:1]
+	if reserved := daemonSetReservation(node, daemonSets, 10*time.Minute, now); reserved.MilliCPU != 900 {
+		t.Errorf("expected 900m reserved, got %v", reserved)
+	}
+
+	if reserved := daemonSetReservation(node, daemonSets, 10*time.Minute, now.Add(time.Hour)); !reserved.IsEmpty() {
+		t.Errorf("expected nothing reserved on an old node, got %v", reserved)
+	}
+}
+
+// countingDaemonSetLister counts the lists of the DaemonSets.
+type countingDaemonSetLister struct {
+	appslisterv1.DaemonSetLister
+	lists int
+}
+
+func (l *countingDaemonSetLister) List(selector labels.Selector) ([]*appsv1.DaemonSet, error) {
+	l.lists++
+	return l.DaemonSetLister.List(selector)
+}
+
+func TestDaemonSetReserver(t *testing.T) {
+	now := time.Now()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "logs", UID: "logs"},
+		Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+			Containers: []v1.Container{{Resources: v1.ResourceRequirements{Requests: api.BuildResourceList("100m", "100Mi")}}},
+		}}},
+	}
+	if err := indexer.Add(ds); err != nil {
+		t.Fatalf("failed to add the DaemonSet: %v", err)
+	}
+	lister := &countingDaemonSetLister{DaemonSetLister: appslisterv1.NewDaemonSetLister(indexer)}
+	sc := &SchedulerCache{daemonSetLister: lister, daemonSetReservationPeriod: 10 * time.Minute}
+
+	buildNodeInfo := func(name string, age time.Duration) *api.NodeInfo {
+		node := buildNode(name, api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...))
+		node.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		return api.NewNodeInfo(node)
+	}
+
+	// the old nodes are skipped without listing the DaemonSets
+	reserve := sc.daemonSetReserver(now)
+	if reserved := reserve(buildNodeInfo("old", time.Hour)); !reserved.IsEmpty() || lister.lists != 0 {
+		t.Errorf("expected nothing reserved on an old node without a list, got %v after %d lists", reserved, lister.lists)
+	}
+
+	// the new nodes share a single list of the DaemonSets
+	for _, name := range []string{"n1", "n2", "n3"} {
+		node := buildNodeInfo(name, time.Minute)
+		if reserved := reserve(node); reserved.MilliCPU != 100 || node.Idle.MilliCPU != 3900 {
+			t.Errorf("expected 100m reserved on node %s, got %v and idle %v", name, reserved, node.Idle)
+		}
+	}
+	if lister.lists != 1 {
+		t.Errorf("expected the DaemonSets listed once, got %d lists", lister.lists)
+	}
+}
+
+func TestStatusQueue(t *testing.T) {
+	var written []string
+	q := newStatusQueue(1000, func(pg *api.PodGroup) error {
+		written = append(written, pg.Name+":"+pg.Annotations["version"])
+		return nil
+	}, nil)
+	newPodGroup := func(name, version string) *api.PodGroup {
+		pg := &api.PodGroup{}
+		pg.Namespace, pg.Name = "ns", name
+		pg.Annotations = map[string]string{"version": version}
+		return pg
+	}
+
+	q.add(newPodGroup("refresh", "1"), false)
+	q.add(newPodGroup("promoted", "1"), false)
+	q.add(newPodGroup("transition", "1"), true)
+	q.add(newPodGroup("refresh", "2"), false)
+	q.add(newPodGroup("promoted", "2"), true)
+
+	for w := q.pop(); w != nil; w = q.pop() {
+		q.write(w.pg)
+	}
+	expected := []string{"transition:1", "promoted:2", "refresh:2"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("expected the writes %v, got %v", expected, written)
+	}
+}
+
+func TestStatusQueueConflict(t *testing.T) {
+	newPodGroup := func(version string, phase scheduling.PodGroupPhase) *api.PodGroup {
+		pg := &api.PodGroup{}
+		pg.Namespace, pg.Name, pg.ResourceVersion = "ns", "pg", version
+		pg.Status.Phase = phase
+		return pg
+	}
+
+	written := make(chan *api.PodGroup, 2)
+	q := newStatusQueue(1000, func(pg *api.PodGroup) error {
+		written <- pg
+		if pg.ResourceVersion == "1" {
+			return apierrors.NewConflict(schema.GroupResource{Resource: "podgroups"}, pg.Name, fmt.Errorf("outdated"))
+		}
+		return nil
+	}, func(pg *api.PodGroup) *api.PodGroup {
+		return newPodGroup("2", scheduling.PodGroupPending)
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go q.run(stopCh)
+
+	q.add(newPodGroup("1", scheduling.PodGroupRunning), true)
+	for _, version := range []string{"1", "2"} {
+		select {
+		case pg := <-written:
+			if pg.ResourceVersion != version || pg.Status.Phase != scheduling.PodGroupRunning {
+				t.Errorf("expected the running status written on version %s, got %s on version %s",
+					version, pg.Status.Phase, pg.ResourceVersion)
+			}
+		case <-time.After(wait.ForeverTestTimeout):
+			t.Fatalf("expected the status written on version %s", version)
+		}
+	}
+}
+
+func TestUpdateQueueStatus(t *testing.T) {
+	stored := &schedulingv1beta1.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1", ResourceVersion: "2"}}
+	stored.Status.State = schedulingv1beta1.QueueStateOpen
+	vcclient := vcfake.NewSimpleClientset(stored)
+	su := &defaultStatusUpdater{vcclient: vcclient}
+
+	newQueueInfo := func(version string) *api.QueueInfo {
+		queue := &scheduling.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1", ResourceVersion: version}}
+		queue.Status.State = scheduling.QueueStateOpen
+		queue.Status.Allocated = api.BuildResourceList("2", "4Gi")
+		qi := api.NewQueueInfo(queue)
+		qi.SchedulingLatency = &api.SchedulingLatency{
+			P50: metav1.Duration{Duration: time.Minute},
+			P95: metav1.Duration{Duration: time.Hour},
+		}
+		return qi
+	}
+
+	// the queue changed since it was cached
+	if err := su.UpdateQueueStatus(newQueueInfo("1")); err == nil {
+		t.Errorf("expected the status of an outdated queue to be rejected")
+	}
+
+	if err := su.UpdateQueueStatus(newQueueInfo("2")); err != nil {
+		t.Fatalf("expected the status written, got %v", err)
+	}
+	actions := vcclient.Actions()
+	patch := actions[len(actions)-1].(clienttesting.PatchAction)
+	if patch.GetSubresource() != "status" || patch.GetPatchType() != types.JSONPatchType ||
+		!strings.Contains(string(patch.GetPatch()), `"schedulingLatency":{"p50":"1m0s","p95":"1h0m0s"}`) {
+		t.Errorf("expected a single status patch with the scheduling latency, got %s on %q", patch.GetPatch(), patch.GetSubresource())
+	}
+	queue, _ := vcclient.SchedulingV1beta1().Queues().Get(context.TODO(), "q1", metav1.GetOptions{})
+	if !equality.Semantic.DeepEqual(queue.Status.Allocated, api.BuildResourceList("2", "4Gi")) ||
+		queue.Status.State != schedulingv1beta1.QueueStateOpen {
+		t.Errorf("expected the allocated resource written along the status, got %v", queue.Status)
+	}
+}
+
+func TestExternalBinder(t *testing.T) {
+	external := util.BuildPod("ns", "external", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg", nil, nil)
+	external.Annotations[api.ExternalBinder] = "true"
+	external.ResourceVersion = "1"
+	internal := util.BuildPod("ns", "internal", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg", nil, nil)
+	client := fake.NewSimpleClientset(external, internal)
+
+	sc := newMockSchedulerCache("volcano")
+	sc.Recorder = record.NewFakeRecorder(10)
+	binder := util.NewFakeBinder(10)
+	eb := newExternalBinder(sc, binder, time.Hour)
+	var scheduled []time.Duration
+	eb.after = func(d time.Duration, fn func()) { scheduled = append(scheduled, d) }
+
+	tasks := []*api.TaskInfo{api.NewTaskInfo(external), api.NewTaskInfo(internal)}
+	for _, task := range tasks {
+		task.NodeName = "n1"
+	}
+	if errMsg := eb.Bind(client, tasks); len(errMsg) != 0 {
+		t.Fatalf("expected no bind error, got %v", errMsg)
+	}
+	if binds := binder.Binds(); len(binds) != 1 || binds["ns/internal"] != "n1" {
+		t.Errorf("expected only the internal pod to be bound, got %v", binds)
+	}
+	pod, _ := client.CoreV1().Pods("ns").Get(context.TODO(), "external", metav1.GetOptions{})
+	if pod.Annotations[api.AssignedNode] != "n1" {
+		t.Fatalf("expected the node decision in the annotations, got %v", pod.Annotations)
+	}
+	if !reflect.DeepEqual(scheduled, []time.Duration{time.Hour}) {
+		t.Errorf("expected the revocation of the decision scheduled after the timeout, got %v", scheduled)
+	}
+
+	// the decision is kept once the external binder bound the pod
+	bound := pod.DeepCopy()
+	bound.Spec.NodeName = "n1"
+	client.CoreV1().Pods("ns").Update(context.TODO(), bound, metav1.UpdateOptions{})
+	eb.revoke(client, tasks[0])
+	pod, _ = client.CoreV1().Pods("ns").Get(context.TODO(), "external", metav1.GetOptions{})
+	key := sc.generateErrTaskKey(tasks[0])
+	if pod.Annotations[api.AssignedNode] != "n1" || sc.errTasks.NumRequeues(key) != 0 {
+		t.Errorf("expected the decision of the bound pod to be kept")
+	}
+
+	// and revoked otherwise
+	unbound := pod.DeepCopy()
+	unbound.Spec.NodeName = ""
+	client.CoreV1().Pods("ns").Update(context.TODO(), unbound, metav1.UpdateOptions{})
+	eb.revoke(client, tasks[0])
+	pod, _ = client.CoreV1().Pods("ns").Get(context.TODO(), "external", metav1.GetOptions{})
+	if _, found := pod.Annotations[api.AssignedNode]; found || sc.errTasks.NumRequeues(key) != 1 {
+		t.Errorf("expected the decision of the pod not bound in time to be revoked and the pod placed again, got %v", pod.Annotations)
+	}
+}
+
+func TestPreviousPlacementPatches(t *testing.T) {
+	pg := &schedulingv1beta1.PodGroup{ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: "pg1"}}
+	vcClient := vcfake.NewSimpleClientset(pg)
+	sending, release := make(chan struct{}), make(chan struct{})
+	patches := 0
+	vcClient.PrependReactor("patch", "podgroups", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if patches++; patches == 1 {
+			close(sending)
+			<-release
+		}
+		return false, nil, nil
+	})
+	sc := &SchedulerCache{vcClient: vcClient}
+	job := api.NewJobInfo("c1/pg1")
+	job.SetPodGroup(&api.PodGroup{
+		PodGroup: scheduling.PodGroup{ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: "pg1"}},
+		Version:  api.PodGroupVersionV1Beta1,
+	})
+	node := api.NewNodeInfo(buildNode("n1", api.BuildResourceList("4", "4G")))
+
+	// the evictions made while the first patch is sent are merged into the next one
+	for i, name := range []string{"p1", "p2", "p3"} {
+		task := api.NewTaskInfo(buildPod("c1", name, "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), nil, nil))
+		sc.recordPreviousPlacement(job, task, node)
+		if i == 0 {
+			<-sending
+		}
+	}
+	close(release)
+
+	err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, time.Second, true, func(ctx context.Context) (bool, error) {
+		sc.placementMutex.Lock()
+		defer sc.placementMutex.Unlock()
+		return sc.patchingPlacements.Len() == 0, nil
+	})
+	if err != nil {
+		t.Fatalf("the previous placements were not patched: %v", err)
+	}
+	if patches != 2 {
+		t.Errorf("expected 2 patches, got %d", patches)
+	}
+	patched, err := vcClient.SchedulingV1beta1().PodGroups("c1").Get(context.TODO(), "pg1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := patched.Annotations[api.PodGroupPreviousPlacement], job.PodGroup.Annotations[api.PodGroupPreviousPlacement]; got != want {
+		t.Errorf("expected the previous placements %s, got %s", want, got)
+	}
+}
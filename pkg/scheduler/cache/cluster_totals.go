@@ -0,0 +1,26 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// updateNodeTotals refreshes what the node adds to the cluster totals, to be called once the
+// node or its tasks changed. Assumes that lock is already acquired.
+func (sc *SchedulerCache) updateNodeTotals(name string) {
+	if sc.totals == nil || len(name) == 0 {
+		return
+	}
+	sc.totals.UpdateNode(name, sc.Nodes[name])
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// daemonSetRuns returns whether the pods of the DaemonSet run on the node. The taints the DaemonSet
+// controller tolerates for all its pods, the node.kubernetes.io ones, are ignored.
+func daemonSetRuns(ds *appsv1.DaemonSet, node *v1.Node) bool {
+	pod := &v1.Pod{Spec: ds.Spec.Template.Spec}
+	if matches, _ := nodeaffinity.GetRequiredNodeAffinity(pod).Match(node); !matches {
+		return false
+	}
+	_, untolerated := v1helper.FindMatchingUntoleratedTaint(node.Spec.Taints, pod.Spec.Tolerations, func(taint *v1.Taint) bool {
+		return (taint.Effect == v1.TaintEffectNoSchedule || taint.Effect == v1.TaintEffectNoExecute) &&
+			!strings.HasPrefix(taint.Key, "node.kubernetes.io/")
+	})
+	return !untolerated
+}
+
+// createdWithin returns whether the node was created within the period.
+func createdWithin(node *schedulingapi.NodeInfo, period time.Duration, now time.Time) bool {
+	return node.Node != nil && now.Sub(node.Node.CreationTimestamp.Time) <= period
+}
+
+// daemonSetReservation returns the requests of the pods the DaemonSets are about to start on the node:
+// the node was created within the period, the pods of the DaemonSets run on it and are not bound to it yet.
+func daemonSetReservation(node *schedulingapi.NodeInfo, daemonSets []*appsv1.DaemonSet, period time.Duration, now time.Time) *schedulingapi.Resource {
+	reserved := schedulingapi.EmptyResource()
+	if !createdWithin(node, period, now) {
+		return reserved
+	}
+
+	bound := map[string]bool{}
+	for _, task := range node.Tasks {
+		if task.Pod == nil {
+			continue
+		}
+		if owner := metav1.GetControllerOf(task.Pod); owner != nil && owner.Kind == "DaemonSet" {
+			bound[string(owner.UID)] = true
+		}
+	}
+	for _, ds := range daemonSets {
+		if bound[string(ds.UID)] || !daemonSetRuns(ds, node.Node) {
+			continue
+		}
+		reserved.Add(schedulingapi.GetPodResourceRequest(&v1.Pod{Spec: ds.Spec.Template.Spec}))
+	}
+	return reserved
+}
+
+// daemonSetReserver returns the function that takes the requests of the DaemonSet pods about to start
+// on a node of the snapshot out of its idle resources, so the gangs placed on new nodes leave room for
+// them, and returns the reserved resources. The DaemonSets are listed once, when the first new node
+// is met, and shared by all the nodes of the snapshot.
+func (sc *SchedulerCache) daemonSetReserver(now time.Time) func(node *schedulingapi.NodeInfo) *schedulingapi.Resource {
+	var daemonSets []*appsv1.DaemonSet
+	listed := false
+	return func(node *schedulingapi.NodeInfo) *schedulingapi.Resource {
+		if !createdWithin(node, sc.daemonSetReservationPeriod, now) {
+			return schedulingapi.EmptyResource()
+		}
+		if !listed {
+			var err error
+			if daemonSets, err = sc.daemonSetLister.List(labels.Everything()); err != nil {
+				klog.Errorf("Failed to list the DaemonSets: %v", err)
+			}
+			listed = true
+		}
+		reserved := daemonSetReservation(node, daemonSets, sc.daemonSetReservationPeriod, now)
+		if reserved.IsEmpty() {
+			return reserved
+		}
+		reserved.MinDimensionResource(node.Idle, schedulingapi.Zero)
+		node.Idle.Sub(reserved)
+		node.Used.Add(reserved)
+		klog.V(4).Infof("Reserve <%v> on node <%s> for the pods of the DaemonSets about to start", reserved, node.Name)
+		return reserved
+	}
+}
@@ -17,7 +17,6 @@
 package cache
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -30,6 +29,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"volcano.sh/volcano/pkg/scheduler/api"
+	clustersnapshot "volcano.sh/volcano/pkg/scheduler/snapshot"
 )
 
 // Dumper writes some information from the scheduler cache to the scheduler logs
@@ -40,7 +40,7 @@ type Dumper struct {
 	RootDir string // target directory for the dumped json file
 }
 
-// dumpToJSONFile marsh scheduler cache snapshot to json file
+// dumpToJSONFile writes the scheduler cache snapshot to a json file, in the format of the snapshot package
 func (d *Dumper) dumpToJSONFile() {
 	snapshot := d.Cache.Snapshot()
 	name := fmt.Sprintf("snapshot-%d.json", time.Now().Unix())
@@ -52,7 +52,12 @@ func (d *Dumper) dumpToJSONFile() {
 	}
 	defer file.Close()
 	klog.Infoln("Starting to dump info in scheduler cache to file", fName)
-	if err = json.NewEncoder(file).Encode(snapshot.Nodes); err != nil {
+	exported, err := clustersnapshot.Export(snapshot, time.Now())
+	if err != nil {
+		klog.Errorf("Failed to dump info in scheduler cache, export error: %v", err)
+		return
+	}
+	if err = clustersnapshot.Write(file, exported); err != nil {
 		klog.Errorf("Failed to dump info in scheduler cache, json encode error: %v", err)
 		return
 	}
@@ -68,6 +73,13 @@ func (d *Dumper) dumpAll() {
 		klog.Info(d.printNodeInfo(nodeInfo))
 	}
 
+	if len(snapshot.QuarantinedNodes) != 0 {
+		klog.Info("Dump of quarantined nodes in scheduler cache")
+		for name, until := range snapshot.QuarantinedNodes {
+			klog.Infof("Node %s is quarantined until %v", name, until)
+		}
+	}
+
 	klog.Info("Dump of jobs info in scheduler cache")
 	for _, jobInfo := range snapshot.Jobs {
 		klog.Info(d.printJobInfo(jobInfo))
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+)
+
+// podGroupOwners tracks the controllers owning the podgroups. An operator creating a podgroup
+// for a workload which has one already may split its pods between two gangs: the pods are still
+// scheduled with the podgroup they reference, and the podgroups created after the first one of
+// their owner are reported with a warning condition and event.
+type podGroupOwners struct {
+	// owner is the controller of each podgroup having one
+	owner map[schedulingapi.JobID]types.UID
+	// owned are the podgroups of each controller
+	owned map[types.UID]map[schedulingapi.JobID]bool
+	// duplicateOf is the oldest podgroup of the owner of each duplicate podgroup
+	duplicateOf map[schedulingapi.JobID]schedulingapi.JobID
+}
+
+func newPodGroupOwners() *podGroupOwners {
+	return &podGroupOwners{
+		owner:       map[schedulingapi.JobID]types.UID{},
+		owned:       map[types.UID]map[schedulingapi.JobID]bool{},
+		duplicateOf: map[schedulingapi.JobID]schedulingapi.JobID{},
+	}
+}
+
+// podGroupOwner returns the controller of the podgroup, empty if it has none.
+func podGroupOwner(pg *schedulingapi.PodGroup) types.UID {
+	if pg == nil {
+		return ""
+	}
+	if ref := metav1.GetControllerOf(&pg.PodGroup); ref != nil {
+		return ref.UID
+	}
+	return ""
+}
+
+// trackPodGroupOwner records the owner of the podgroup of the job, nil when the podgroup is
+// deleted, and resolves the podgroups of its previous and new owners.
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) trackPodGroupOwner(id schedulingapi.JobID) {
+	var owner types.UID
+	if job, found := sc.Jobs[id]; found {
+		owner = podGroupOwner(job.PodGroup)
+	}
+	if sc.owners == nil {
+		if owner == "" {
+			return
+		}
+		sc.owners = newPodGroupOwners()
+	}
+
+	previous, tracked := sc.owners.owner[id]
+	if tracked && previous == owner {
+		return
+	}
+	if tracked {
+		delete(sc.owners.owner, id)
+		delete(sc.owners.owned[previous], id)
+		if len(sc.owners.owned[previous]) == 0 {
+			delete(sc.owners.owned, previous)
+		}
+		if _, duplicate := sc.owners.duplicateOf[id]; duplicate {
+			delete(sc.owners.duplicateOf, id)
+			sc.markDuplicatePodGroup(sc.Jobs[id], nil)
+		}
+		sc.resolvePodGroupOwner(previous)
+	}
+	if owner == "" {
+		return
+	}
+
+	sc.owners.owner[id] = owner
+	if sc.owners.owned[owner] == nil {
+		sc.owners.owned[owner] = map[schedulingapi.JobID]bool{}
+	}
+	sc.owners.owned[owner][id] = true
+	sc.resolvePodGroupOwner(owner)
+}
+
+// resolvePodGroupOwner reports the podgroups of the owner created after the oldest one, the name
+// breaking the ties, as its duplicates.
+func (sc *SchedulerCache) resolvePodGroupOwner(owner types.UID) {
+	ids := make([]schedulingapi.JobID, 0, len(sc.owners.owned[owner]))
+	for id := range sc.owners.owned[owner] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		li, lj := sc.Jobs[ids[i]].PodGroup, sc.Jobs[ids[j]].PodGroup
+		if !li.CreationTimestamp.Equal(&lj.CreationTimestamp) {
+			return li.CreationTimestamp.Before(&lj.CreationTimestamp)
+		}
+		return ids[i] < ids[j]
+	})
+
+	for i, id := range ids {
+		if i == 0 {
+			if _, duplicate := sc.owners.duplicateOf[id]; duplicate {
+				delete(sc.owners.duplicateOf, id)
+				sc.markDuplicatePodGroup(sc.Jobs[id], nil)
+			}
+			continue
+		}
+		if sc.owners.duplicateOf[id] == ids[0] {
+			continue
+		}
+		sc.owners.duplicateOf[id] = ids[0]
+		metrics.RegisterPodGroupOwnerConflict()
+		sc.markDuplicatePodGroup(sc.Jobs[id], sc.Jobs[ids[0]])
+	}
+	metrics.UpdateDuplicatePodGroups(len(sc.owners.duplicateOf))
+}
+
+// markDuplicatePodGroup gives the duplicate podgroup a warning condition naming the older
+// podgroup of its owner, original is nil when the podgroup is no longer a duplicate.
+func (sc *SchedulerCache) markDuplicatePodGroup(duplicate, original *schedulingapi.JobInfo) {
+	if duplicate == nil || duplicate.PodGroup == nil {
+		return
+	}
+	condition := scheduling.PodGroupCondition{
+		Type:               schedulingapi.PodGroupDuplicateOwnerType,
+		Status:             v1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(schedulingapi.PodGroupDuplicateOwnerType),
+		Message:            "no older podgroup has the same owner",
+	}
+	if original != nil {
+		condition.Status = v1.ConditionTrue
+		condition.Message = fmt.Sprintf("podgroup %s has the same owner and was created first, the pods of the owner may be split between the two gangs",
+			original.PodGroup.Name)
+		klog.Warningf("PodGroup <%s/%s> is a duplicate: %s", duplicate.Namespace, duplicate.Name, condition.Message)
+		if sc.Recorder != nil {
+			sc.recordPodGroupEvent(duplicate.PodGroup, v1.EventTypeWarning, condition.Reason, condition.Message)
+		}
+	}
+
+	pg := duplicate.PodGroup.Clone()
+	replaced := false
+	for i := range pg.Status.Conditions {
+		if pg.Status.Conditions[i].Type == condition.Type {
+			if pg.Status.Conditions[i].Status == condition.Status && pg.Status.Conditions[i].Message == condition.Message {
+				return
+			}
+			pg.Status.Conditions[i] = condition
+			replaced = true
+		}
+	}
+	if !replaced {
+		if original == nil {
+			return
+		}
+		pg.Status.Conditions = append(pg.Status.Conditions, condition)
+	}
+
+	switch {
+	case sc.statusQueue != nil:
+		sc.statusQueue.add(pg, false)
+	case sc.StatusUpdater != nil:
+		go func() {
+			if _, err := sc.StatusUpdater.UpdatePodGroup(pg); err != nil {
+				klog.Errorf("Failed to update the condition of duplicate podgroup <%s/%s>: %v", pg.Namespace, pg.Name, err)
+			}
+		}()
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math"
 	"slices"
 	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
@@ -59,10 +60,15 @@ func isTerminated(status schedulingapi.TaskStatus) bool {
 // getOrCreateJob will return corresponding Job for pi if it exists, or it will create a Job and return it if
 // pi.Pod.Spec.SchedulerName is same as volcano scheduler's name, otherwise it will return nil.
 func (sc *SchedulerCache) getOrCreateJob(pi *schedulingapi.TaskInfo) *schedulingapi.JobInfo {
+	if pi.Pod != nil && ofOtherScheduler(pi.Pod, sc.getSchedulerNames()) {
+		klog.V(4).Infof("Pod %s/%s is scheduled by %s, only account it on its node",
+			pi.Namespace, pi.Name, pi.Pod.Spec.SchedulerName)
+		return nil
+	}
 	if len(pi.Job) == 0 {
-		if !slices.Contains(sc.schedulerNames, pi.Pod.Spec.SchedulerName) {
+		if !slices.Contains(sc.getSchedulerNames(), pi.Pod.Spec.SchedulerName) {
 			klog.V(4).Infof("Pod %s/%s will not scheduled by %#v, skip creating PodGroup and Job for it",
-				pi.Pod.Namespace, pi.Pod.Name, sc.schedulerNames)
+				pi.Pod.Namespace, pi.Pod.Name, sc.getSchedulerNames())
 		}
 		return nil
 	}
@@ -217,7 +223,9 @@ func (sc *SchedulerCache) addTask(pi *schedulingapi.TaskInfo) error {
 
 		node := sc.Nodes[pi.NodeName]
 		if !isTerminated(pi.Status) {
-			if err := node.AddTask(pi); err != nil {
+			err := node.AddTask(pi)
+			sc.updateNodeTotals(pi.NodeName)
+			if err != nil {
 				return err
 			}
 		} else {
@@ -234,7 +242,7 @@ func (sc *SchedulerCache) addTask(pi *schedulingapi.TaskInfo) error {
 }
 
 func (sc *SchedulerCache) NewTaskInfo(pod *v1.Pod) (*schedulingapi.TaskInfo, error) {
-	taskInfo := schedulingapi.NewTaskInfo(pod)
+	taskInfo := schedulingapi.NewTaskInfo(sc.withRuntimeClassOverhead(pod))
 	if err := sc.addPodCSIVolumesToTask(taskInfo); err != nil {
 		return taskInfo, err
 	}
@@ -243,6 +251,27 @@ func (sc *SchedulerCache) NewTaskInfo(pod *v1.Pod) (*schedulingapi.TaskInfo, err
 	return taskInfo, nil
 }
 
+// withRuntimeClassOverhead returns the pod with the overhead of its runtime class, the RuntimeClass
+// admission controller sets it on the pods it admits, the pod itself if the overhead is set or
+// the runtime class has none.
+func (sc *SchedulerCache) withRuntimeClassOverhead(pod *v1.Pod) *v1.Pod {
+	if pod.Spec.Overhead != nil || pod.Spec.RuntimeClassName == nil || sc.runtimeClassLister == nil {
+		return pod
+	}
+	runtimeClass, err := sc.runtimeClassLister.Get(*pod.Spec.RuntimeClassName)
+	if err != nil {
+		klog.V(4).Infof("Failed to get the runtime class <%s> of pod <%s/%s>: %v",
+			*pod.Spec.RuntimeClassName, pod.Namespace, pod.Name, err)
+		return pod
+	}
+	if runtimeClass.Overhead == nil || len(runtimeClass.Overhead.PodFixed) == 0 {
+		return pod
+	}
+	pod = pod.DeepCopy()
+	pod.Spec.Overhead = runtimeClass.Overhead.PodFixed.DeepCopy()
+	return pod
+}
+
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) addPod(pod *v1.Pod) error {
 	pi, err := sc.NewTaskInfo(pod)
@@ -283,6 +312,7 @@ func (sc *SchedulerCache) syncTask(oldTask *schedulingapi.TaskInfo) error {
 }
 
 func (sc *SchedulerCache) updateTask(oldTask, newTask *schedulingapi.TaskInfo) error {
+	reportStatusUpdate(newTask.Namespace, newTask.Name, oldTask.Status, newTask.Status)
 	if err := sc.deleteTask(oldTask); err != nil {
 		klog.Warningf("Failed to delete task: %v", err)
 	}
@@ -303,6 +333,30 @@ func (sc *SchedulerCache) allocatedPodInCache(pod *v1.Pod) bool {
 	return false
 }
 
+// checkPodStatusUpdate reports the status of the task of the new pod which the scheduler would
+// not move the task in the cache, or of the old pod for the tasks of no job, to. Assumes that lock
+// is already acquired.
+func (sc *SchedulerCache) checkPodStatusUpdate(oldPod, newPod *v1.Pod) {
+	pi := schedulingapi.NewTaskInfo(newPod)
+	oldStatus := schedulingapi.NewTaskInfo(oldPod).Status
+	if job, found := sc.Jobs[pi.Job]; found {
+		if task, found := job.Tasks[pi.UID]; found {
+			oldStatus = task.Status
+		}
+	}
+	reportStatusUpdate(newPod.Namespace, newPod.Name, oldStatus, pi.Status)
+}
+
+// reportStatusUpdate logs and counts the status change observed from a pod which the scheduler
+// would not make itself; the change is applied anyway, the pods are also bound, started and lost
+// outside the scheduler.
+func reportStatusUpdate(namespace, name string, oldStatus, newStatus schedulingapi.TaskStatus) {
+	if err := schedulingapi.ValidateStatusUpdate(oldStatus, newStatus); err != nil {
+		klog.V(3).Infof("Observed an unexpected status change of task <%s/%s>: %v", namespace, name, err)
+		metrics.RegisterUnexpectedTaskStatusTransition(oldStatus.String(), newStatus.String())
+	}
+}
+
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) updatePod(oldPod, newPod *v1.Pod) error {
 	//ignore the update event if pod is allocated in cache but not present in NodeName
@@ -311,9 +365,16 @@ func (sc *SchedulerCache) updatePod(oldPod, newPod *v1.Pod) error {
 		return nil
 	}
 
-	if err := sc.deletePod(oldPod); err != nil {
-		return err
+	sc.checkPodStatusUpdate(oldPod, newPod)
+
+	if oldPod.Status.Resize != newPod.Status.Resize {
+		klog.V(3).Infof("Pod <%s/%s> in-place resize status changed from <%s> to <%s>, refreshing its resource accounting",
+			newPod.Namespace, newPod.Name, oldPod.Status.Resize, newPod.Status.Resize)
 	}
+
+	// the job of the pod is not deleted even if it is left without tasks, the new pod is added back
+	// to it right away
+	sc.removePod(oldPod)
 	//when delete pod, the ownerreference of pod will be set nil,just as orphan pod
 	if len(utils.GetController(newPod)) == 0 {
 		newPod.OwnerReferences = oldPod.OwnerReferences
@@ -324,13 +385,16 @@ func (sc *SchedulerCache) updatePod(oldPod, newPod *v1.Pod) error {
 func (sc *SchedulerCache) deleteTask(ti *schedulingapi.TaskInfo) error {
 	var jobErr, nodeErr, numaErr error
 
-	if len(ti.Job) != 0 {
+	switch {
+	case ti.Pod != nil && ofOtherScheduler(ti.Pod, sc.getSchedulerNames()):
+		// the Pods of other schedulers are only on the Nodes, see getOrCreateJob
+	case len(ti.Job) != 0:
 		if job, found := sc.Jobs[ti.Job]; found {
 			jobErr = job.DeleteTaskInfo(ti)
 		} else {
 			klog.Warningf("Failed to find Job <%v> for Task <%v/%v>", ti.Job, ti.Namespace, ti.Name)
 		}
-	} else { // should not run into here; record error so that easy to debug
+	default: // should not run into here; record error so that easy to debug
 		jobErr = fmt.Errorf("task %s/%s has null jobID", ti.Namespace, ti.Name)
 	}
 
@@ -338,6 +402,7 @@ func (sc *SchedulerCache) deleteTask(ti *schedulingapi.TaskInfo) error {
 		node := sc.Nodes[ti.NodeName]
 		if node != nil {
 			nodeErr = node.RemoveTask(ti)
+			sc.updateNodeTotals(ti.NodeName)
 		}
 	}
 
@@ -348,8 +413,9 @@ func (sc *SchedulerCache) deleteTask(ti *schedulingapi.TaskInfo) error {
 	return nil
 }
 
+// removePod removes the task of the pod from its job and node, it returns the job of the task.
 // Assumes that lock is already acquired.
-func (sc *SchedulerCache) deletePod(pod *v1.Pod) error {
+func (sc *SchedulerCache) removePod(pod *v1.Pod) schedulingapi.JobID {
 	pi := schedulingapi.NewTaskInfo(pod)
 
 	// Delete the Task in cache to handle Binding status.
@@ -362,9 +428,15 @@ func (sc *SchedulerCache) deletePod(pod *v1.Pod) error {
 	if err := sc.deleteTask(task); err != nil {
 		klog.Warningf("Failed to delete task: %v", err)
 	}
+	return pi.Job
+}
+
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) deletePod(pod *v1.Pod) error {
+	jobID := sc.removePod(pod)
 
 	// If job was terminated, delete it.
-	if job, found := sc.Jobs[pi.Job]; found && schedulingapi.JobTerminated(job) {
+	if job, found := sc.Jobs[jobID]; found && schedulingapi.JobTerminated(job) {
 		sc.deleteJob(job)
 	}
 
@@ -373,6 +445,11 @@ func (sc *SchedulerCache) deletePod(pod *v1.Pod) error {
 
 // AddPod add pod to scheduler cache
 func (sc *SchedulerCache) AddPod(obj interface{}) {
+	sc.eventCount.Add(1)
+	sc.eventRecorder.record(EventKindPod, EventOpAdd, obj)
+	if sc.dropEvent() {
+		return
+	}
 	pod, ok := obj.(*v1.Pod)
 	if !ok {
 		klog.Errorf("Cannot convert to *v1.Pod: %v", obj)
@@ -393,6 +470,11 @@ func (sc *SchedulerCache) AddPod(obj interface{}) {
 
 // UpdatePod update pod to scheduler cache
 func (sc *SchedulerCache) UpdatePod(oldObj, newObj interface{}) {
+	sc.eventCount.Add(1)
+	sc.eventRecorder.record(EventKindPod, EventOpUpdate, newObj)
+	if sc.dropEvent() {
+		return
+	}
 	oldPod, ok := oldObj.(*v1.Pod)
 	if !ok {
 		klog.Errorf("Cannot convert oldObj to *v1.Pod: %v", oldObj)
@@ -403,6 +485,14 @@ func (sc *SchedulerCache) UpdatePod(oldObj, newObj interface{}) {
 		klog.Errorf("Cannot convert newObj to *v1.Pod: %v", newObj)
 		return
 	}
+	if newPod.Status.Phase == v1.PodSucceeded || newPod.Status.Phase == v1.PodFailed {
+		sc.capacityFreed()
+	}
+	if sc.quarantine != nil && admissionRejected(oldPod, newPod) && slices.Contains(sc.getSchedulerNames(), newPod.Spec.SchedulerName) {
+		klog.V(3).Infof("Pod <%s/%s> was rejected by the kubelet of node <%s>: %s",
+			newPod.Namespace, newPod.Name, newPod.Spec.NodeName, newPod.Status.Reason)
+		sc.quarantine.recordFailure(newPod.Spec.NodeName, failureAdmission, time.Now())
+	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
@@ -418,6 +508,12 @@ func (sc *SchedulerCache) UpdatePod(oldObj, newObj interface{}) {
 
 // DeletePod delete pod from scheduler cache
 func (sc *SchedulerCache) DeletePod(obj interface{}) {
+	sc.eventCount.Add(1)
+	sc.capacityFreed()
+	sc.eventRecorder.record(EventKindPod, EventOpDelete, obj)
+	if sc.dropEvent() {
+		return
+	}
 	var pod *v1.Pod
 	switch t := obj.(type) {
 	case *v1.Pod:
@@ -493,12 +589,16 @@ func (sc *SchedulerCache) AddOrUpdateNode(node *v1.Node) error {
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
+	if sc.staleNode(node.Name) {
+		return nil
+	}
 	if sc.Nodes[node.Name] != nil {
 		sc.Nodes[node.Name].SetNode(node)
 		sc.removeNodeImageStates(node.Name)
 	} else {
 		sc.Nodes[node.Name] = schedulingapi.NewNodeInfo(node)
 	}
+	sc.updateNodeTotals(node.Name)
 	sc.addNodeImageStates(node, sc.Nodes[node.Name])
 
 	var nodeExisted bool
@@ -540,11 +640,15 @@ func (sc *SchedulerCache) RemoveNode(nodeName string) error {
 		}
 	}
 	delete(sc.Nodes, nodeName)
+	sc.updateNodeTotals(nodeName)
 	return nil
 }
 
 // AddNode add node to scheduler cache
 func (sc *SchedulerCache) AddNode(obj interface{}) {
+	sc.eventCount.Add(1)
+	sc.capacityFreed()
+	sc.eventRecorder.record(EventKindNode, EventOpAdd, obj)
 	node, ok := obj.(*v1.Node)
 	if !ok {
 		klog.Errorf("Cannot convert to *v1.Node: %v", obj)
@@ -555,7 +659,9 @@ func (sc *SchedulerCache) AddNode(obj interface{}) {
 
 // UpdateNode update node to scheduler cache
 func (sc *SchedulerCache) UpdateNode(oldObj, newObj interface{}) {
-	_, ok := oldObj.(*v1.Node)
+	sc.eventCount.Add(1)
+	sc.eventRecorder.record(EventKindNode, EventOpUpdate, newObj)
+	oldNode, ok := oldObj.(*v1.Node)
 	if !ok {
 		klog.Errorf("Cannot convert oldObj to *v1.Node: %v", oldObj)
 		return
@@ -565,11 +671,20 @@ func (sc *SchedulerCache) UpdateNode(oldObj, newObj interface{}) {
 		klog.Errorf("Cannot convert newObj to *v1.Node: %v", newObj)
 		return
 	}
+	// the heartbeats leave the capacity unchanged, unlike the changes of the schedulable resources
+	if oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+		!equality.Semantic.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+		!equality.Semantic.DeepEqual(oldNode.Labels, newNode.Labels) ||
+		!equality.Semantic.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable) {
+		sc.capacityFreed()
+	}
 	sc.nodeQueue.Add(newNode.Name)
 }
 
 // DeleteNode delete node from scheduler cache
 func (sc *SchedulerCache) DeleteNode(obj interface{}) {
+	sc.eventCount.Add(1)
+	sc.eventRecorder.record(EventKindNode, EventOpDelete, obj)
 	var node *v1.Node
 	switch t := obj.(type) {
 	case *v1.Node:
@@ -706,6 +821,7 @@ func (sc *SchedulerCache) setPodGroup(ss *schedulingapi.PodGroup) error {
 	}
 
 	sc.Jobs[job].SetPodGroup(ss)
+	sc.trackPodGroupOwner(job)
 
 	// TODO(k82cn): set default queue in admission.
 	if len(ss.Spec.Queue) == 0 {
@@ -731,6 +847,7 @@ func (sc *SchedulerCache) deletePodGroup(id schedulingapi.JobID) error {
 
 	// Unset SchedulingSpec
 	job.UnsetPodGroup()
+	sc.trackPodGroupOwner(id)
 
 	sc.deleteJob(job)
 
@@ -739,6 +856,7 @@ func (sc *SchedulerCache) deletePodGroup(id schedulingapi.JobID) error {
 
 // AddPodGroupV1beta1 add podgroup to scheduler cache
 func (sc *SchedulerCache) AddPodGroupV1beta1(obj interface{}) {
+	sc.eventRecorder.record(EventKindPodGroup, EventOpAdd, obj)
 	ss, ok := obj.(*schedulingv1beta1.PodGroup)
 	if !ok {
 		klog.Errorf("Cannot convert to *schedulingv1beta1.PodGroup: %v", obj)
@@ -765,6 +883,7 @@ func (sc *SchedulerCache) AddPodGroupV1beta1(obj interface{}) {
 
 // UpdatePodGroupV1beta1 add podgroup to scheduler cache
 func (sc *SchedulerCache) UpdatePodGroupV1beta1(oldObj, newObj interface{}) {
+	sc.eventRecorder.record(EventKindPodGroup, EventOpUpdate, newObj)
 	oldSS, ok := oldObj.(*schedulingv1beta1.PodGroup)
 	if !ok {
 		klog.Errorf("Cannot convert oldObj to *schedulingv1beta1.SchedulingSpec: %v", oldObj)
@@ -799,6 +918,7 @@ func (sc *SchedulerCache) UpdatePodGroupV1beta1(oldObj, newObj interface{}) {
 
 // DeletePodGroupV1beta1 delete podgroup from scheduler cache
 func (sc *SchedulerCache) DeletePodGroupV1beta1(obj interface{}) {
+	sc.eventRecorder.record(EventKindPodGroup, EventOpDelete, obj)
 	var ss *schedulingv1beta1.PodGroup
 	switch t := obj.(type) {
 	case *schedulingv1beta1.PodGroup:
@@ -828,6 +948,7 @@ func (sc *SchedulerCache) DeletePodGroupV1beta1(obj interface{}) {
 
 // AddQueueV1beta1 add queue to scheduler cache
 func (sc *SchedulerCache) AddQueueV1beta1(obj interface{}) {
+	sc.eventRecorder.record(EventKindQueue, EventOpAdd, obj)
 	ss, ok := obj.(*schedulingv1beta1.Queue)
 	if !ok {
 		klog.Errorf("Cannot convert to *schedulingv1beta1.Queue: %v", obj)
@@ -849,6 +970,7 @@ func (sc *SchedulerCache) AddQueueV1beta1(obj interface{}) {
 
 // UpdateQueueV1beta1 update queue to scheduler cache
 func (sc *SchedulerCache) UpdateQueueV1beta1(oldObj, newObj interface{}) {
+	sc.eventRecorder.record(EventKindQueue, EventOpUpdate, newObj)
 	oldSS, ok := oldObj.(*schedulingv1beta1.Queue)
 	if !ok {
 		klog.Errorf("Cannot convert oldObj to *schedulingv1beta1.Queue: %v", oldObj)
@@ -877,6 +999,7 @@ func (sc *SchedulerCache) UpdateQueueV1beta1(oldObj, newObj interface{}) {
 
 // DeleteQueueV1beta1 delete queue from the scheduler cache
 func (sc *SchedulerCache) DeleteQueueV1beta1(obj interface{}) {
+	sc.eventRecorder.record(EventKindQueue, EventOpDelete, obj)
 	var ss *schedulingv1beta1.Queue
 	switch t := obj.(type) {
 	case *schedulingv1beta1.Queue:
@@ -904,7 +1027,13 @@ func (sc *SchedulerCache) addQueue(queue *scheduling.Queue) {
 }
 
 func (sc *SchedulerCache) updateQueue(queue *scheduling.Queue) {
+	// the scheduling latency is not in the typed status, the last reported one is kept
+	var latency *schedulingapi.SchedulingLatency
+	if old, found := sc.Queues[schedulingapi.QueueID(queue.Name)]; found {
+		latency = old.SchedulingLatency
+	}
 	sc.addQueue(queue)
+	sc.Queues[schedulingapi.QueueID(queue.Name)].SchedulingLatency = latency
 }
 
 func (sc *SchedulerCache) deleteQueue(id schedulingapi.QueueID) {
@@ -145,6 +145,47 @@ func TestSchedulerCache_UpdatePod(t *testing.T) {
 	}
 }
 
+func TestSchedulerCache_UpdatePodResize(t *testing.T) {
+	cache := &SchedulerCache{
+		Jobs:        make(map[api.JobID]*api.JobInfo),
+		Nodes:       make(map[string]*api.NodeInfo),
+		DeletedJobs: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	cache.AddOrUpdateNode(buildNode("n1", api.BuildResourceList("4000m", "10G", []api.ScalarResource{{Name: "pods", Value: "10"}}...)))
+
+	oldPod := util.BuildPod("test", "p1", "n1", v1.PodRunning, api.BuildResourceList("1000m", "1G"), "pg1", make(map[string]string), make(map[string]string))
+	cache.AddPod(oldPod)
+
+	// The kubelet has admitted a resize from 1 to 3 CPUs.
+	newPod := oldPod.DeepCopy()
+	newPod.Spec.Containers[0].Resources.Requests = api.BuildResourceList("3000m", "1G")
+	newPod.Status.Resize = v1.PodResizeStatusInProgress
+	newPod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:               newPod.Spec.Containers[0].Name,
+		AllocatedResources: api.BuildResourceList("3000m", "1G"),
+	}}
+	if err := cache.updatePod(oldPod, newPod); err != nil {
+		t.Fatalf("failed to update pod: %v", err)
+	}
+
+	if got := cache.Nodes["n1"].Used.MilliCPU; got != 3000 {
+		t.Errorf("expected node used cpu 3000, got %v", got)
+	}
+	if got := cache.Nodes["n1"].Idle.MilliCPU; got != 1000 {
+		t.Errorf("expected node idle cpu 1000, got %v", got)
+	}
+	job := cache.Jobs[api.JobID("test/pg1")]
+	if job == nil {
+		t.Fatalf("expected job test/pg1 in cache")
+	}
+	if got := job.Allocated.MilliCPU; got != 3000 {
+		t.Errorf("expected job allocated cpu 3000, got %v", got)
+	}
+	if got := cache.DeletedJobs.Len(); got != 0 {
+		t.Errorf("expected the job of the resized pod not queued for deletion, got %d jobs", got)
+	}
+}
+
 func TestSchedulerCache_AddPodGroupV1beta1(t *testing.T) {
 	namespace := "test"
 	owner := buildOwnerReference("j1")
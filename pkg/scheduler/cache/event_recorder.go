@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// Kinds of the recorded events.
+const (
+	EventKindPod      = "Pod"
+	EventKindNode     = "Node"
+	EventKindPodGroup = "PodGroup"
+	EventKindQueue    = "Queue"
+	// EventKindCycle marks the start of a scheduling cycle
+	EventKindCycle = "Cycle"
+)
+
+// Operations of the recorded events.
+const (
+	EventOpAdd    = "Add"
+	EventOpUpdate = "Update"
+	EventOpDelete = "Delete"
+)
+
+// EventRecord is an event received by the cache, recorded as a line of JSON to be replayed
+// offline through the scheduler.
+type EventRecord struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	Op   string    `json:"op,omitempty"`
+	// Object is the object of the event, the new one of an update
+	Object json.RawMessage `json:"object,omitempty"`
+}
+
+// eventRecorder writes the events received by the cache.
+type eventRecorder struct {
+	sync.Mutex
+	w io.WriteCloser
+	// maxBytes caps the size of the record, 0 if unbounded
+	maxBytes int64
+	written  int64
+	// stopped is set once the record is full or closed, nothing is written after it
+	stopped bool
+}
+
+func newEventRecorder(path string, maxBytes int64) (*eventRecorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event record file %s: %v", path, err)
+	}
+	// the record appended to counts against the cap
+	var written int64
+	if info, err := f.Stat(); err == nil {
+		written = info.Size()
+	}
+	return &eventRecorder{w: f, maxBytes: maxBytes, written: written}, nil
+}
+
+func (er *eventRecorder) record(kind, op string, obj interface{}) {
+	if er == nil {
+		return
+	}
+	if deleted, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = deleted.Obj
+	}
+	rec := EventRecord{Time: time.Now(), Kind: kind, Op: op}
+	if obj != nil {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			klog.Errorf("Failed to record %s event of %s: %v", op, kind, err)
+			return
+		}
+		rec.Object = data
+	}
+	line, err := json.Marshal(&rec)
+	if err != nil {
+		klog.Errorf("Failed to record %s event of %s: %v", op, kind, err)
+		return
+	}
+	line = append(line, '\n')
+
+	er.Lock()
+	defer er.Unlock()
+	if er.stopped {
+		return
+	}
+	if er.maxBytes > 0 && er.written+int64(len(line)) > er.maxBytes {
+		klog.Warningf("The recorded events reached the maximum of %d bytes, stop recording them", er.maxBytes)
+		er.stopped = true
+		return
+	}
+	n, err := er.w.Write(line)
+	er.written += int64(n)
+	if err != nil {
+		klog.Errorf("Failed to record %s event of %s: %v", op, kind, err)
+	}
+}
+
+func (er *eventRecorder) close() {
+	if er == nil {
+		return
+	}
+	er.Lock()
+	defer er.Unlock()
+	if er.w == nil {
+		return
+	}
+	er.stopped = true
+	er.w.Close()
+	er.w = nil
+}
+
+// RecordCycle records the start of a scheduling cycle, at which the replay runs a session.
+func (sc *SchedulerCache) RecordCycle() {
+	sc.eventRecorder.record(EventKindCycle, "", nil)
+}
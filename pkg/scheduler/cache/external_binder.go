@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// revokeRetryPeriod is how long a failed revocation of a node decision waits before being retried
+const revokeRetryPeriod = 10 * time.Second
+
+// externalBinder hands the binding of the pods annotated with schedulingapi.ExternalBinder to an
+// external binder, e.g. one which sets up the network of the pod on the node first: their node
+// decision is written in their schedulingapi.AssignedNode annotation, and revoked if they are not
+// bound after the timeout. The other pods are bound by the wrapped binder.
+type externalBinder struct {
+	Binder
+	cache   *SchedulerCache
+	timeout time.Duration
+	// after runs fn once the duration elapsed, time.AfterFunc but in tests
+	after func(d time.Duration, fn func())
+}
+
+func newExternalBinder(sc *SchedulerCache, binder Binder, timeout time.Duration) *externalBinder {
+	return &externalBinder{
+		Binder:  binder,
+		cache:   sc,
+		timeout: timeout,
+		after:   func(d time.Duration, fn func()) { time.AfterFunc(d, fn) },
+	}
+}
+
+func (eb *externalBinder) Bind(kubeClient kubernetes.Interface, tasks []*schedulingapi.TaskInfo) map[schedulingapi.TaskID]string {
+	errMsg := map[schedulingapi.TaskID]string{}
+	var internal []*schedulingapi.TaskInfo
+	for _, task := range tasks {
+		if task.Pod.Annotations[schedulingapi.ExternalBinder] != "true" {
+			internal = append(internal, task)
+			continue
+		}
+		if err := eb.handOff(kubeClient, task); err != nil {
+			klog.Errorf("Failed to hand pod <%s/%s> over to its external binder: %v", task.Namespace, task.Name, err)
+			errMsg[task.UID] = err.Error()
+			continue
+		}
+		klog.V(3).Infof("Handed pod <%s/%s> over to its external binder for node <%s>", task.Namespace, task.Name, task.NodeName)
+		eb.after(eb.timeout, func() {
+			eb.revoke(kubeClient, task)
+		})
+	}
+	if len(internal) != 0 {
+		for uid, msg := range eb.Binder.Bind(kubeClient, internal) {
+			errMsg[uid] = msg
+		}
+	}
+	return errMsg
+}
+
+func (eb *externalBinder) handOff(kubeClient kubernetes.Interface, task *schedulingapi.TaskInfo) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{schedulingapi.AssignedNode: task.NodeName},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = kubeClient.CoreV1().Pods(task.Namespace).Patch(context.TODO(), task.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// revoke removes the node decision of the task if its pod is still not bound, and places the task
+// again. The decision is removed by a JSON patch which tests the version of the pod and its
+// decision first, so that it fails if the external binder bound the pod in the meantime.
+func (eb *externalBinder) revoke(kubeClient kubernetes.Interface, task *schedulingapi.TaskInfo) {
+	pod, err := kubeClient.CoreV1().Pods(task.Namespace).Get(context.TODO(), task.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("Failed to get pod <%s/%s> to revoke its node decision: %v", task.Namespace, task.Name, err)
+			eb.after(revokeRetryPeriod, func() { eb.revoke(kubeClient, task) })
+		}
+		return
+	}
+	if pod.UID != task.Pod.UID || len(pod.Spec.NodeName) != 0 || pod.Annotations[schedulingapi.AssignedNode] != task.NodeName {
+		return
+	}
+
+	annotationPath := "/metadata/annotations/" + strings.ReplaceAll(schedulingapi.AssignedNode, "/", "~1")
+	patch, err := json.Marshal([]map[string]string{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": pod.ResourceVersion},
+		{"op": "test", "path": annotationPath, "value": task.NodeName},
+		{"op": "remove", "path": annotationPath},
+	})
+	if err != nil {
+		klog.Errorf("Failed to build the revocation of the node decision of pod <%s/%s>: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		// a failed test means the pod changed, it is checked again
+		klog.Errorf("Failed to revoke the node decision of pod <%s/%s>: %v", pod.Namespace, pod.Name, err)
+		eb.after(revokeRetryPeriod, func() { eb.revoke(kubeClient, task) })
+		return
+	}
+
+	klog.V(3).Infof("Revoked the node decision <%s> of pod <%s/%s> not bound within %v", task.NodeName, pod.Namespace, pod.Name, eb.timeout)
+	eb.cache.Recorder.Eventf(pod, v1.EventTypeWarning, "ExternalBindTimeout",
+		"Not bound to %s by its external binder within %v, placing it again", task.NodeName, eb.timeout)
+	eb.cache.resyncTask(task)
+}
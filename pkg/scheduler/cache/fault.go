@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// FaultInjector makes the cache misbehave the way it does under informer lag and api server
+// flakiness, for tests to check that the scheduler converges anyway. It is for tests only.
+type FaultInjector struct {
+	// DropEventRate is the probability for a pod event to be lost
+	DropEventRate float64
+	// BindDelay is how long each bind request waits before being sent
+	BindDelay time.Duration
+	// BindFailureRate is the probability for the bind of a task to fail
+	BindFailureRate float64
+	// StaleNodes are the nodes whose updates are ignored, so that their status in the cache
+	// stays the one they had when they were added
+	StaleNodes sets.Set[string]
+
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// NewFaultInjector returns a FaultInjector whose random faults are reproducible for the seed.
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{
+		StaleNodes: sets.New[string](),
+		rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (fi *FaultInjector) happens(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+	return fi.rand.Float64() < rate
+}
+
+// InjectFaults makes the cache inject the faults, it must be called before the cache is run.
+func (sc *SchedulerCache) InjectFaults(fi *FaultInjector) {
+	sc.faults = fi
+	sc.Binder = &faultyBinder{Binder: sc.Binder, faults: fi}
+}
+
+// dropEvent tells whether the pod event is to be lost.
+func (sc *SchedulerCache) dropEvent() bool {
+	if sc.faults == nil || !sc.faults.happens(sc.faults.DropEventRate) {
+		return false
+	}
+	klog.V(3).Infof("Fault injected: pod event dropped")
+	return true
+}
+
+// staleNode tells whether the update of the node already in cache is to be ignored.
+func (sc *SchedulerCache) staleNode(name string) bool {
+	if sc.faults == nil || sc.Nodes[name] == nil || !sc.faults.StaleNodes.Has(name) {
+		return false
+	}
+	klog.V(3).Infof("Fault injected: update of node %s ignored", name)
+	return true
+}
+
+// faultyBinder delays the bind requests and fails some of the binds.
+type faultyBinder struct {
+	Binder
+	faults *FaultInjector
+}
+
+func (fb *faultyBinder) Bind(kubeClient kubernetes.Interface, tasks []*schedulingapi.TaskInfo) map[schedulingapi.TaskID]string {
+	time.Sleep(fb.faults.BindDelay)
+
+	errMsg := map[schedulingapi.TaskID]string{}
+	var bound []*schedulingapi.TaskInfo
+	for _, task := range tasks {
+		if fb.faults.happens(fb.faults.BindFailureRate) {
+			klog.V(3).Infof("Fault injected: bind of task %s/%s failed", task.Namespace, task.Name)
+			errMsg[task.UID] = "injected bind failure"
+			continue
+		}
+		bound = append(bound, task)
+	}
+	if len(bound) != 0 {
+		for uid, msg := range fb.Binder.Bind(kubeClient, bound) {
+			errMsg[uid] = msg
+		}
+	}
+	if len(errMsg) == 0 {
+		return nil
+	}
+	return errMsg
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package cache
 
 import (
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -49,6 +51,10 @@ type Cache interface {
 	// Evict evicts the task to release resources.
 	Evict(task *api.TaskInfo, reason string) error
 
+	// Drain waits, at most timeout, for the in-flight binds and evictions to
+	// complete and returns the tasks still assumed on their node.
+	Drain(timeout time.Duration) []*api.TaskInfo
+
 	// RecordJobStatusEvent records related events according to job status.
 	// Deprecated: remove it after removed PDB support.
 	RecordJobStatusEvent(job *api.JobInfo, updatePG bool)
@@ -82,11 +88,27 @@ type Cache interface {
 	// SharedInformerFactory return scheduler SharedInformerFactory
 	SharedInformerFactory() informers.SharedInformerFactory
 
+	// AddSchedulerNames adds the scheduler names of the profiles, whose pods are scheduled too
+	AddSchedulerNames(names ...string)
+
 	// SetMetricsConf set the metrics server related configuration
 	SetMetricsConf(conf map[string]string)
 
 	// EventRecorder returns the event recorder
 	EventRecorder() record.EventRecorder
+
+	// EventCount returns the number of pod and node events received so far
+	EventCount() uint64
+
+	// BackedOff returns whether the job is not allocated in the session opened at now, as it
+	// kept failing to be scheduled
+	BackedOff(job api.JobID, now time.Time) bool
+
+	// RecordScheduleAttempt records whether the job was scheduled in the session closed at now
+	RecordScheduleAttempt(job api.JobID, scheduled bool, now time.Time)
+
+	// RecordCycle records the start of a scheduling cycle if the events are recorded
+	RecordCycle()
 }
 
 // VolumeBinder interface for allocate and bind volumes
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// maintenanceWindowResource is the MaintenanceWindow resource, see
+// config/crd/volcano/bases/scheduling.volcano.sh_maintenancewindows.yaml
+var maintenanceWindowResource = schema.GroupVersionResource{Group: "scheduling.volcano.sh", Version: "v1alpha1", Resource: "maintenancewindows"}
+
+// maintenanceWindowObject holds the fields of a MaintenanceWindow.
+type maintenanceWindowObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Start        metav1.Time           `json:"start"`
+		End          metav1.Time           `json:"end"`
+		Queues       []string              `json:"queues"`
+		NodeSelector *metav1.LabelSelector `json:"nodeSelector"`
+		Drain        bool                  `json:"drain"`
+	} `json:"spec"`
+}
+
+// maintenanceWindow is a declared maintenance of queues and nodes.
+type maintenanceWindow struct {
+	name       string
+	start, end time.Time
+	queues     sets.Set[string]
+	nodes      labels.Selector
+	drain      bool
+}
+
+// maintenanceWindows are the MaintenanceWindows by name.
+type maintenanceWindows struct {
+	mutex   sync.RWMutex
+	windows map[string]*maintenanceWindow
+
+	informerFactory dynamicinformer.DynamicSharedInformerFactory
+}
+
+func newMaintenanceWindows() *maintenanceWindows {
+	return &maintenanceWindows{windows: map[string]*maintenanceWindow{}}
+}
+
+func (mw *maintenanceWindows) update(obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+	window := &maintenanceWindowObject{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, window); err != nil {
+		klog.Errorf("Failed to convert MaintenanceWindow <%s>: %v", u.GetName(), err)
+		return
+	}
+	// a nil selector selects no node, an empty one all of them
+	nodes, err := metav1.LabelSelectorAsSelector(window.Spec.NodeSelector)
+	if err != nil {
+		klog.Errorf("Invalid node selector of MaintenanceWindow <%s>, ignore it: %v", window.Name, err)
+		return
+	}
+
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	mw.windows[window.Name] = &maintenanceWindow{
+		name:   window.Name,
+		start:  window.Spec.Start.Time,
+		end:    window.Spec.End.Time,
+		queues: sets.New(window.Spec.Queues...),
+		nodes:  nodes,
+		drain:  window.Spec.Drain,
+	}
+}
+
+func (mw *maintenanceWindows) delete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	delete(mw.windows, u.GetName())
+}
+
+// active returns the windows in progress at the time, with the nodes they cover.
+func (mw *maintenanceWindows) active(now time.Time, nodes map[string]*schedulingapi.NodeInfo) []*schedulingapi.MaintenanceWindow {
+	mw.mutex.RLock()
+	defer mw.mutex.RUnlock()
+
+	var active []*schedulingapi.MaintenanceWindow
+	for _, window := range mw.windows {
+		if now.Before(window.start) || !now.Before(window.end) {
+			continue
+		}
+		covered := &schedulingapi.MaintenanceWindow{
+			Name:   window.name,
+			Queues: window.queues,
+			Nodes:  sets.New[string](),
+			Drain:  window.drain,
+		}
+		for name, node := range nodes {
+			if node.Node != nil && window.nodes.Matches(labels.Set(node.Node.Labels)) {
+				covered.Nodes.Insert(name)
+			}
+		}
+		active = append(active, covered)
+	}
+	return active
+}
+
+// addMaintenanceWindowEventHandler watches the MaintenanceWindows.
+func (sc *SchedulerCache) addMaintenanceWindowEventHandler() {
+	client, err := dynamic.NewForConfig(sc.restConfig)
+	if err != nil {
+		klog.Errorf("Failed to create the client of the MaintenanceWindows, ignore them: %v", err)
+		sc.maintenance = nil
+		return
+	}
+	sc.maintenance.informerFactory = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	sc.maintenance.informerFactory.ForResource(maintenanceWindowResource).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: sc.maintenance.update,
+		UpdateFunc: func(_, newObj interface{}) {
+			sc.maintenance.update(newObj)
+		},
+		DeleteFunc: sc.maintenance.delete,
+	})
+}
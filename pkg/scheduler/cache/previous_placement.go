@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// recordPreviousPlacement records the node and zone of the evicted task on its podgroup, for the
+// sticky plugin to place the task back there. The podgroup of the cache is updated at once and
+// the annotation patched in the background; the patches of one podgroup are sent one at a time,
+// the latest value replacing the ones not sent yet.
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) recordPreviousPlacement(job *schedulingapi.JobInfo, task *schedulingapi.TaskInfo, node *schedulingapi.NodeInfo) {
+	if job.PodGroup == nil {
+		return
+	}
+	placement := schedulingapi.Placement{Node: node.Name, Time: metav1.Now()}
+	if node.Node != nil {
+		placement.Zone = node.Node.Labels[v1.LabelTopologyZone]
+	}
+	value, err := job.PodGroup.RecordPreviousPlacement(task.PlacementKey(), placement)
+	if err != nil {
+		klog.Errorf("Failed to record the placement of evicted task <%s/%s>: %v", task.Namespace, task.Name, err)
+		return
+	}
+	if sc.vcClient == nil {
+		return
+	}
+
+	key := job.PodGroup.Namespace + "/" + job.PodGroup.Name
+	sc.placementMutex.Lock()
+	defer sc.placementMutex.Unlock()
+	if sc.pendingPlacements == nil {
+		sc.pendingPlacements = map[string]string{}
+		sc.patchingPlacements = sets.New[string]()
+	}
+	sc.pendingPlacements[key] = value
+	if sc.patchingPlacements.Has(key) {
+		return
+	}
+	sc.patchingPlacements.Insert(key)
+	go sc.patchPreviousPlacements(job.PodGroup.Namespace, job.PodGroup.Name)
+}
+
+// patchPreviousPlacements patches the pending previous placements of the podgroup until none is
+// left.
+func (sc *SchedulerCache) patchPreviousPlacements(namespace, name string) {
+	key := namespace + "/" + name
+	for {
+		sc.placementMutex.Lock()
+		value, found := sc.pendingPlacements[key]
+		if !found {
+			sc.patchingPlacements.Delete(key)
+			sc.placementMutex.Unlock()
+			return
+		}
+		delete(sc.pendingPlacements, key)
+		sc.placementMutex.Unlock()
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{schedulingapi.PodGroupPreviousPlacement: value},
+			},
+		})
+		if err != nil {
+			klog.Errorf("Failed to encode the previous placements of podgroup <%s/%s>: %v", namespace, name, err)
+			continue
+		}
+		if _, err := sc.vcClient.SchedulingV1beta1().PodGroups(namespace).Patch(context.TODO(), name,
+			types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			klog.Errorf("Failed to patch the previous placements of podgroup <%s/%s>: %v", namespace, name, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+)
+
+const (
+	// failureBind is a bind of a task to the node which failed
+	failureBind = "bind"
+	// failureAdmission is a pod bound to the node which its kubelet rejected
+	failureAdmission = "admission"
+)
+
+// nodeQuarantine leaves the nodes with repeated bind or admission failures out of scheduling for
+// a while, e.g. the nodes with a broken kubelet or a disk pressure which is not reported yet.
+type nodeQuarantine struct {
+	// threshold is the number of failures within window which quarantine a node for duration
+	threshold int
+	window    time.Duration
+	duration  time.Duration
+
+	mutex sync.Mutex
+	// failures are the times of the recent failures of the nodes
+	failures map[string][]time.Time
+	// until is when the quarantine of the quarantined nodes ends
+	until map[string]time.Time
+}
+
+func newNodeQuarantine(threshold int, window, duration time.Duration) *nodeQuarantine {
+	return &nodeQuarantine{
+		threshold: threshold,
+		window:    window,
+		duration:  duration,
+		failures:  map[string][]time.Time{},
+		until:     map[string]time.Time{},
+	}
+}
+
+// recordFailure records a failure of the kind on the node, and quarantines it on too many.
+func (nq *nodeQuarantine) recordFailure(node, kind string, now time.Time) {
+	metrics.RegisterNodeFailure(node, kind)
+
+	nq.mutex.Lock()
+	defer nq.mutex.Unlock()
+
+	if until, found := nq.until[node]; found && now.Before(until) {
+		return
+	}
+	recent := nq.failures[node][:0]
+	for _, failure := range nq.failures[node] {
+		if now.Sub(failure) < nq.window {
+			recent = append(recent, failure)
+		}
+	}
+	recent = append(recent, now)
+	if len(recent) < nq.threshold {
+		nq.failures[node] = recent
+		return
+	}
+
+	delete(nq.failures, node)
+	nq.until[node] = now.Add(nq.duration)
+	metrics.UpdateNodeQuarantined(node, true)
+	klog.Warningf("Node <%s> had %d bind or admission failures within %v, quarantine it for %v",
+		node, len(recent), nq.window, nq.duration)
+}
+
+// quarantined returns the nodes in quarantine at the time with the end of their quarantine,
+// releasing the others.
+func (nq *nodeQuarantine) quarantined(now time.Time) map[string]time.Time {
+	nq.mutex.Lock()
+	defer nq.mutex.Unlock()
+
+	quarantined := make(map[string]time.Time, len(nq.until))
+	for node, until := range nq.until {
+		if now.Before(until) {
+			quarantined[node] = until
+			continue
+		}
+		delete(nq.until, node)
+		metrics.UpdateNodeQuarantined(node, false)
+		klog.V(3).Infof("Node <%s> is released from quarantine", node)
+	}
+	return quarantined
+}
+
+// apiServerErrors are parts of the messages of the bind failures caused by the API server or the
+// connection to it rather than by the node; the binders report their failures as messages.
+var apiServerErrors = []string{
+	"the server ",
+	"Internal error occurred",
+	"etcdserver: ",
+	"Operation cannot be fulfilled",
+	"not found",
+	"already exists",
+	"is forbidden",
+	"Too many requests",
+	"please try again",
+	"Timeout: ",
+	"rate limiter",
+	"context deadline exceeded",
+	"context canceled",
+	"connection refused",
+	"connection reset",
+	"i/o timeout",
+	"EOF",
+}
+
+// nodeBindFailure returns whether the reason of a bind failure may come from the node, so that a
+// flaky API server does not quarantine the nodes it failed to bind to.
+func nodeBindFailure(reason string) bool {
+	for _, apiServerError := range apiServerErrors {
+		if strings.Contains(reason, apiServerError) {
+			return false
+		}
+	}
+	return true
+}
+
+// admissionRejected returns whether the kubelet of the node of the pod refused to run it.
+func admissionRejected(oldPod, newPod *v1.Pod) bool {
+	if oldPod.Status.Phase == v1.PodFailed || newPod.Status.Phase != v1.PodFailed || newPod.Spec.NodeName == "" {
+		return false
+	}
+	reason := newPod.Status.Reason
+	return strings.HasPrefix(reason, "OutOf") || reason == "UnexpectedAdmissionError" || reason == "NodeAffinity"
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// reservationResource is the Reservation resource, see
+// config/crd/volcano/bases/scheduling.volcano.sh_reservations.yaml
+var reservationResource = schema.GroupVersionResource{Group: "scheduling.volcano.sh", Version: "v1alpha1", Resource: "reservations"}
+
+// reservationObject holds the fields of a Reservation.
+type reservationObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Queue        string                `json:"queue"`
+		NodeSelector *metav1.LabelSelector `json:"nodeSelector"`
+		Tolerations  []v1.Toleration       `json:"tolerations"`
+		Resources    v1.ResourceList       `json:"resources"`
+		Start        metav1.Time           `json:"start"`
+		End          metav1.Time           `json:"end"`
+	} `json:"spec"`
+}
+
+// reservation is a block of capacity declared for a queue during a time window.
+type reservation struct {
+	schedulingapi.Reservation
+	start, end time.Time
+}
+
+// reservations are the Reservations by name.
+type reservations struct {
+	mutex        sync.RWMutex
+	reservations map[string]*reservation
+
+	informerFactory dynamicinformer.DynamicSharedInformerFactory
+}
+
+func newReservations() *reservations {
+	return &reservations{reservations: map[string]*reservation{}}
+}
+
+func (rs *reservations) update(obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+	r := &reservationObject{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, r); err != nil {
+		klog.Errorf("Failed to convert Reservation <%s>: %v", u.GetName(), err)
+		return
+	}
+	// a nil selector selects no node, an empty one all of them
+	nodes, err := metav1.LabelSelectorAsSelector(r.Spec.NodeSelector)
+	if err != nil {
+		klog.Errorf("Invalid node selector of Reservation <%s>, ignore it: %v", r.Name, err)
+		return
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.reservations[r.Name] = &reservation{
+		Reservation: schedulingapi.Reservation{
+			Name:         r.Name,
+			Queue:        schedulingapi.QueueID(r.Spec.Queue),
+			NodeSelector: nodes,
+			Tolerations:  r.Spec.Tolerations,
+			Resources:    r.Spec.Resources,
+		},
+		start: r.Spec.Start.Time,
+		end:   r.Spec.End.Time,
+	}
+}
+
+func (rs *reservations) delete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	delete(rs.reservations, u.GetName())
+}
+
+// active returns the reservations in progress at the time.
+func (rs *reservations) active(now time.Time) []*schedulingapi.Reservation {
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+
+	var active []*schedulingapi.Reservation
+	for _, r := range rs.reservations {
+		if now.Before(r.start) || !now.Before(r.end) {
+			continue
+		}
+		reservation := r.Reservation
+		active = append(active, &reservation)
+	}
+	return active
+}
+
+// addReservationEventHandler watches the Reservations.
+func (sc *SchedulerCache) addReservationEventHandler() {
+	client, err := dynamic.NewForConfig(sc.restConfig)
+	if err != nil {
+		klog.Errorf("Failed to create the client of the Reservations, ignore them: %v", err)
+		sc.reservations = nil
+		return
+	}
+	sc.reservations.informerFactory = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	sc.reservations.informerFactory.ForResource(reservationResource).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: sc.reservations.update,
+		UpdateFunc: func(_, newObj interface{}) {
+			sc.reservations.update(newObj)
+		},
+		DeleteFunc: sc.reservations.delete,
+	})
+}
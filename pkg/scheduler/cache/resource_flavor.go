@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// resourceFlavorResource is the ResourceFlavor resource, see
+// config/crd/volcano/bases/scheduling.volcano.sh_resourceflavors.yaml
+var resourceFlavorResource = schema.GroupVersionResource{Group: "scheduling.volcano.sh", Version: "v1alpha1", Resource: "resourceflavors"}
+
+// resourceFlavorObject holds the fields of a ResourceFlavor.
+type resourceFlavorObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		NodeLabels map[string]string `json:"nodeLabels"`
+		NodeTaints []v1.Taint        `json:"nodeTaints"`
+	} `json:"spec"`
+}
+
+// resourceFlavors are the ResourceFlavors by name.
+type resourceFlavors struct {
+	mutex   sync.RWMutex
+	flavors map[string]*schedulingapi.ResourceFlavor
+
+	informerFactory dynamicinformer.DynamicSharedInformerFactory
+}
+
+func newResourceFlavors() *resourceFlavors {
+	return &resourceFlavors{flavors: map[string]*schedulingapi.ResourceFlavor{}}
+}
+
+func (rf *resourceFlavors) update(obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+	f := &resourceFlavorObject{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, f); err != nil {
+		klog.Errorf("Failed to convert ResourceFlavor <%s>: %v", u.GetName(), err)
+		return
+	}
+
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+	rf.flavors[f.Name] = &schedulingapi.ResourceFlavor{
+		Name:       f.Name,
+		NodeLabels: labels.SelectorFromSet(f.Spec.NodeLabels),
+		NodeTaints: f.Spec.NodeTaints,
+	}
+}
+
+func (rf *resourceFlavors) delete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+	delete(rf.flavors, u.GetName())
+}
+
+// snapshot returns the ResourceFlavors by name.
+func (rf *resourceFlavors) snapshot() map[string]*schedulingapi.ResourceFlavor {
+	rf.mutex.RLock()
+	defer rf.mutex.RUnlock()
+
+	flavors := make(map[string]*schedulingapi.ResourceFlavor, len(rf.flavors))
+	for name, f := range rf.flavors {
+		flavor := *f
+		flavors[name] = &flavor
+	}
+	return flavors
+}
+
+// addResourceFlavorEventHandler watches the ResourceFlavors.
+func (sc *SchedulerCache) addResourceFlavorEventHandler() {
+	client, err := dynamic.NewForConfig(sc.restConfig)
+	if err != nil {
+		klog.Errorf("Failed to create the client of the ResourceFlavors, ignore them: %v", err)
+		sc.resourceFlavors = nil
+		return
+	}
+	sc.resourceFlavors.informerFactory = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	sc.resourceFlavors.informerFactory.ForResource(resourceFlavorResource).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: sc.resourceFlavors.update,
+		UpdateFunc: func(_, newObj interface{}) {
+			sc.resourceFlavors.update(newObj)
+		},
+		DeleteFunc: sc.resourceFlavors.delete,
+	})
+}
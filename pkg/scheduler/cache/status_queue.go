@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+)
+
+// statusQueue rate limits the writes of the podgroup statuses to the API server. The writes
+// of one podgroup are coalesced, only its last status is written, and the phase transitions are
+// written before the refreshes of the conditions, which are only written when no transition waits.
+// A status rejected for a conflict is written again on the podgroup as cached by then.
+type statusQueue struct {
+	write func(pg *schedulingapi.PodGroup) error
+	// latest returns the podgroup as cached now, nil if it is gone
+	latest  func(pg *schedulingapi.PodGroup) *schedulingapi.PodGroup
+	limiter flowcontrol.RateLimiter
+
+	mutex sync.Mutex
+	// pending are the last statuses to write by podgroup, transitions and refreshes the keys of
+	// the podgroups to write in order; a key is left in refreshes when its write turns into a
+	// transition, and skipped there
+	pending     map[string]*statusWrite
+	transitions []string
+	refreshes   []string
+	notify      chan struct{}
+}
+
+type statusWrite struct {
+	pg         *schedulingapi.PodGroup
+	transition bool
+}
+
+func newStatusQueue(qps float32, write func(pg *schedulingapi.PodGroup) error,
+	latest func(pg *schedulingapi.PodGroup) *schedulingapi.PodGroup) *statusQueue {
+	return &statusQueue{
+		write:   write,
+		latest:  latest,
+		limiter: flowcontrol.NewTokenBucketRateLimiter(qps, int(math.Ceil(float64(qps)))),
+		pending: map[string]*statusWrite{},
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// add queues the status of the podgroup, replacing the one still waiting if any.
+func (q *statusQueue) add(pg *schedulingapi.PodGroup, transition bool) {
+	q.mutex.Lock()
+	q.push(pg, transition)
+	q.mutex.Unlock()
+	q.wakeUp()
+}
+
+// retry queues again the status which failed to be written for a conflict, on the podgroup as
+// cached now; it is dropped if the podgroup is gone or a newer status of it waits.
+func (q *statusQueue) retry(w *statusWrite) {
+	latest := q.latest(w.pg)
+	if latest == nil {
+		return
+	}
+	latest.Status = w.pg.Status
+
+	q.mutex.Lock()
+	if _, found := q.pending[latest.Namespace+"/"+latest.Name]; !found {
+		q.push(latest, w.transition)
+	}
+	q.mutex.Unlock()
+	q.wakeUp()
+}
+
+// push queues the status of the podgroup. Assumes that lock is already acquired.
+func (q *statusQueue) push(pg *schedulingapi.PodGroup, transition bool) {
+	key := pg.Namespace + "/" + pg.Name
+	if w, found := q.pending[key]; found {
+		w.pg = pg
+		if transition && !w.transition {
+			w.transition = true
+			q.transitions = append(q.transitions, key)
+		}
+	} else {
+		q.pending[key] = &statusWrite{pg: pg, transition: transition}
+		if transition {
+			q.transitions = append(q.transitions, key)
+		} else {
+			q.refreshes = append(q.refreshes, key)
+		}
+	}
+	metrics.UpdatePodGroupStatusWritesPending(len(q.pending))
+}
+
+func (q *statusQueue) wakeUp() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the next status to write, transitions first, or nil if none is waiting.
+func (q *statusQueue) pop() *statusWrite {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for len(q.transitions) > 0 {
+		key := q.transitions[0]
+		q.transitions = q.transitions[1:]
+		if w, found := q.pending[key]; found {
+			delete(q.pending, key)
+			metrics.UpdatePodGroupStatusWritesPending(len(q.pending))
+			return w
+		}
+	}
+	for len(q.refreshes) > 0 {
+		key := q.refreshes[0]
+		q.refreshes = q.refreshes[1:]
+		if w, found := q.pending[key]; found && !w.transition {
+			delete(q.pending, key)
+			metrics.UpdatePodGroupStatusWritesPending(len(q.pending))
+			return w
+		}
+	}
+	return nil
+}
+
+func (q *statusQueue) run(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	for {
+		w := q.pop()
+		if w == nil {
+			select {
+			case <-stopCh:
+				return
+			case <-q.notify:
+			}
+			continue
+		}
+
+		if err := q.limiter.Wait(ctx); err != nil {
+			return
+		}
+		if err := q.write(w.pg); err != nil {
+			switch {
+			case apierrors.IsConflict(err):
+				klog.V(4).Infof("Retry the status of podgroup <%s/%s> on its latest version: %v", w.pg.Namespace, w.pg.Name, err)
+				q.retry(w)
+			case apierrors.IsNotFound(err):
+				klog.V(4).Infof("Dropped the status of podgroup <%s/%s>: %v", w.pg.Namespace, w.pg.Name, err)
+			default:
+				// the status is computed again in the next session if still outdated
+				klog.Errorf("Failed to update the status of podgroup <%s/%s>: %v", w.pg.Namespace, w.pg.Name, err)
+			}
+		}
+	}
+}
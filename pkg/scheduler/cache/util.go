@@ -22,14 +22,58 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/klog/v2"
 	"stathat.com/c/consistent"
 
 	scheduling "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 )
 
+// resourceServed returns whether the API server serves the resource, so that the informers of
+// the optional CRDs are not started when the CRD is not installed and would never sync.
+func resourceServed(client discovery.DiscoveryInterface, gvr schema.GroupVersionResource) bool {
+	resources, err := client.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		klog.V(3).Infof("Failed to discover the resources of <%s>: %v", gvr.GroupVersion(), err)
+		return false
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == gvr.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// podPriority returns the priority of the pod the eviction grace periods are banded by, the
+// priority of its spec rather than the task priority its annotations may raise.
+func podPriority(pod *v1.Pod) int32 {
+	if pod == nil || pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// gracePeriodSeconds returns the grace period rounded up to whole seconds, so that a grace
+// period under a second is not turned into an immediate deletion.
+func gracePeriodSeconds(grace time.Duration) int64 {
+	return int64((grace + time.Second - 1) / time.Second)
+}
+
+// isNodeLeaseStale returns whether the lease of the node has not been renewed within staleDuration,
+// a lease never renewed is not considered as stale.
+func isNodeLeaseStale(lease *coordinationv1.Lease, staleDuration time.Duration, now time.Time) bool {
+	if lease == nil || lease.Spec.RenewTime == nil || staleDuration <= 0 {
+		return false
+	}
+	return now.Sub(lease.Spec.RenewTime.Time) > staleDuration
+}
+
 // responsibleForPod returns false at following conditions:
 // 1. The current scheduler is not specified scheduler in Pod's spec.
 // 2. The Job which the Pod belongs is not assigned to current scheduler based on the hash algorithm in multi-schedulers scenario
@@ -57,6 +101,13 @@ func responsibleForPod(pod *v1.Pod, schedulerNames []string, mySchedulerPodName
 	return true
 }
 
+// ofOtherScheduler returns true if the Pod is scheduled by a scheduler other than the current one, e.g. the
+// kube-scheduler, such Pod only counts in the resources used on its Node and is never a Task of a Job; the API
+// server defaults the scheduler name, so a Pod without one is not taken as of another scheduler.
+func ofOtherScheduler(pod *v1.Pod, schedulerNames []string) bool {
+	return len(pod.Spec.SchedulerName) != 0 && !slices.Contains(schedulerNames, pod.Spec.SchedulerName)
+}
+
 // responsibleForNode returns true if the Node is assigned to current scheduler in multi-scheduler scenario
 func responsibleForNode(nodeName string, mySchedulerPodName string, c *consistent.Consistent) bool {
 	if c != nil {
@@ -73,6 +124,15 @@ func responsibleForNode(nodeName string, mySchedulerPodName string, c *consisten
 	return true
 }
 
+// cachesPod returns true if the Pod is cached: the Pods the current scheduler is responsible for, and the
+// Pods bound by any scheduler to the Nodes it is responsible for, so their resources are accounted on the Nodes.
+func (sc *SchedulerCache) cachesPod(pod *v1.Pod) bool {
+	if responsibleForPod(pod, sc.getSchedulerNames(), sc.schedulerPodName, sc.c) {
+		return true
+	}
+	return len(pod.Spec.NodeName) != 0 && responsibleForNode(pod.Spec.NodeName, sc.schedulerPodName, sc.c)
+}
+
 // responsibleForPodGroup returns true if Job which PodGroup belongs is assigned to current scheduler in multi-schedulers scenario
 func responsibleForPodGroup(pg *scheduling.PodGroup, mySchedulerPodName string, c *consistent.Consistent) bool {
 	if c != nil {
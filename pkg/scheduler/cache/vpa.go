@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// vpaResource is the VerticalPodAutoscaler resource, read through the dynamic client not to
+// depend on the autoscaler module.
+var vpaResource = schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}
+
+// verticalPodAutoscaler holds the fields of a VerticalPodAutoscaler the scheduler reads.
+type verticalPodAutoscaler struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		TargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"targetRef"`
+	} `json:"spec"`
+	Status struct {
+		Recommendation *struct {
+			ContainerRecommendations []struct {
+				ContainerName string          `json:"containerName"`
+				Target        v1.ResourceList `json:"target"`
+			} `json:"containerRecommendations"`
+		} `json:"recommendation"`
+	} `json:"status"`
+}
+
+// vpaRecommendations are the container recommendations of the VerticalPodAutoscalers, used
+// instead of the requests of the tasks in the fair-share and binpack math, as the users tend to
+// over-request.
+type vpaRecommendations struct {
+	mutex sync.RWMutex
+	// targets are the recommendations by container name, by namespace/kind/name of the
+	// controller the autoscaler targets
+	targets map[string]map[string]v1.ResourceList
+	// keys are the targets by namespace/name of the autoscaler
+	keys map[string]string
+
+	informerFactory dynamicinformer.DynamicSharedInformerFactory
+}
+
+func newVPARecommendations() *vpaRecommendations {
+	return &vpaRecommendations{
+		targets: map[string]map[string]v1.ResourceList{},
+		keys:    map[string]string{},
+	}
+}
+
+func targetKey(namespace, kind, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
+
+func (r *vpaRecommendations) update(obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+	vpa := &verticalPodAutoscaler{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, vpa); err != nil {
+		klog.Errorf("Failed to convert VerticalPodAutoscaler <%s/%s>: %v", u.GetNamespace(), u.GetName(), err)
+		return
+	}
+
+	recommendations := map[string]v1.ResourceList{}
+	if vpa.Status.Recommendation != nil {
+		for _, c := range vpa.Status.Recommendation.ContainerRecommendations {
+			recommendations[c.ContainerName] = c.Target
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.forget(vpa.Namespace + "/" + vpa.Name)
+	if len(recommendations) == 0 {
+		return
+	}
+	key := targetKey(vpa.Namespace, vpa.Spec.TargetRef.Kind, vpa.Spec.TargetRef.Name)
+	r.keys[vpa.Namespace+"/"+vpa.Name] = key
+	r.targets[key] = recommendations
+}
+
+func (r *vpaRecommendations) delete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Errorf("Cannot convert to *unstructured.Unstructured: %v", obj)
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.forget(u.GetNamespace() + "/" + u.GetName())
+}
+
+// forget removes the recommendations of the autoscaler, the caller holds the lock.
+func (r *vpaRecommendations) forget(name string) {
+	if key, found := r.keys[name]; found {
+		delete(r.targets, key)
+		delete(r.keys, name)
+	}
+}
+
+// recommend sets the recommended resources of the task from the autoscaler of its controller.
+func (r *vpaRecommendations) recommend(task *schedulingapi.TaskInfo) {
+	if task.Pod == nil {
+		return
+	}
+	owner := metav1.GetControllerOf(task.Pod)
+	if owner == nil {
+		return
+	}
+
+	r.mutex.RLock()
+	recommendations, found := r.targets[targetKey(task.Namespace, owner.Kind, owner.Name)]
+	r.mutex.RUnlock()
+	if !found {
+		return
+	}
+	task.Recommended = recommendedResreq(task, recommendations)
+}
+
+// recommendedResreq returns the resource request of the task with the request of each container
+// lowered to its recommendation, the recommendations above the requests are ignored as the
+// task could not get more than it requested.
+func recommendedResreq(task *schedulingapi.TaskInfo, recommendations map[string]v1.ResourceList) *schedulingapi.Resource {
+	saved := schedulingapi.EmptyResource()
+	for _, c := range task.Pod.Spec.Containers {
+		target, found := recommendations[c.Name]
+		if !found {
+			continue
+		}
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			request, requested := c.Resources.Requests[name]
+			recommended, recommendedFound := target[name]
+			if !requested || !recommendedFound || recommended.Cmp(request) >= 0 {
+				continue
+			}
+			delta := request.DeepCopy()
+			delta.Sub(recommended)
+			saved.Add(schedulingapi.NewResource(v1.ResourceList{name: delta}))
+		}
+	}
+	return task.Resreq.Clone().SubWithoutAssert(saved)
+}
+
+// addVPAEventHandler watches the VerticalPodAutoscalers for their recommendations.
+func (sc *SchedulerCache) addVPAEventHandler() {
+	client, err := dynamic.NewForConfig(sc.restConfig)
+	if err != nil {
+		klog.Errorf("Failed to create the client of the VerticalPodAutoscalers, ignore their recommendations: %v", err)
+		sc.vpa = nil
+		return
+	}
+	sc.vpa.informerFactory = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	sc.vpa.informerFactory.ForResource(vpaResource).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: sc.vpa.update,
+		UpdateFunc: func(_, newObj interface{}) {
+			sc.vpa.update(newObj)
+		},
+		DeleteFunc: sc.vpa.delete,
+	})
+}
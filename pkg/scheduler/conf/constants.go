@@ -3,4 +3,26 @@ package conf
 const (
 	// EnablePredicateErrCacheKey is the key whether predicate error cache is enabled
 	EnablePredicateErrCacheKey = "predicateErrorCacheEnable"
+	// EnableFastPathKey is the key whether single-pod jobs are allocated by the fast path
+	EnableFastPathKey = "fastPathEnable"
+	// FastPathNodesKey is the key of the number of feasible nodes the fast path scores for a task
+	FastPathNodesKey = "fastPathNodes"
+	// MaxEvictionsPerCycleKey is the key of the maximum number of evictions an action may execute in a session
+	MaxEvictionsPerCycleKey = "maxEvictionsPerCycle"
+	// MaxEvictionsPerQueuePerMinuteKey is the key of the maximum number of evictions the jobs of a queue may cause per minute
+	MaxEvictionsPerQueuePerMinuteKey = "maxEvictionsPerQueuePerMinute"
+	// WholeJobPreemptionKey is the key whether preempt evicts the victim jobs as a whole rather than task by task
+	WholeJobPreemptionKey = "wholeJobPreemptionEnable"
+	// PreemptionPlanCostKey is the key of the comma separated cost functions preempt compares the
+	// preemption plans of the candidate nodes with, in order of precedence
+	PreemptionPlanCostKey = "preemptionPlanCost"
+	// BackfillTimeLimitKey is the key of the duration backfill places tasks for in a session
+	BackfillTimeLimitKey = "backfillTimeLimit"
+	// BackfillAutoTuneKey is the key whether backfill tunes its time limit between
+	// BackfillMinTimeLimitKey and BackfillMaxTimeLimitKey by how often it delays the head-of-line jobs
+	BackfillAutoTuneKey = "backfillAutoTune"
+	// BackfillMinTimeLimitKey is the key of the lowest time limit the backfill auto-tuning may set
+	BackfillMinTimeLimitKey = "backfillMinTimeLimit"
+	// BackfillMaxTimeLimitKey is the key of the highest time limit the backfill auto-tuning may set
+	BackfillMaxTimeLimitKey = "backfillMaxTimeLimit"
 )
@@ -28,6 +28,26 @@ type SchedulerConfiguration struct {
 	// Configurations is configuration for actions
 	Configurations       []Configuration   `yaml:"configurations"`
 	MetricsConfiguration map[string]string `yaml:"metrics"`
+	// ResourceWeights defines the relative value of the resources, used by
+	// the drf shares and the binpack scores
+	ResourceWeights map[string]float64 `yaml:"resourceWeights"`
+	// Profiles defines the additional scheduler profiles, jobs whose scheduler name
+	// matches no profile are scheduled with the top level actions and tiers
+	Profiles []Profile `yaml:"profiles"`
+}
+
+// Profile defines the actions and plugins used for the jobs of one scheduler name
+type Profile struct {
+	// SchedulerName is the scheduler name of the pods handled by the profile
+	SchedulerName string `yaml:"schedulerName"`
+	// Actions defines the actions list of the profile in order
+	Actions string `yaml:"actions"`
+	// Tiers defines plugins in different tiers
+	Tiers []Tier `yaml:"tiers"`
+	// Configurations is configuration for actions
+	Configurations []Configuration `yaml:"configurations"`
+	// ResourceWeights overrides the resource weights of the configuration for the profile
+	ResourceWeights map[string]float64 `yaml:"resourceWeights"`
 }
 
 // Tier defines plugin tier
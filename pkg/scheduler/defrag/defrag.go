@@ -0,0 +1,243 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defrag analyzes the fragmentation of the cluster: how scattered the idle gpus are,
+// which capacity of the nodes can't be used because another resource of the node is exhausted,
+// and which migrations would consolidate the gpu workloads to free whole nodes.
+package defrag
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// UnknownGPUType is the gpu type of the nodes without the gpu type label.
+const UnknownGPUType = "unknown"
+
+// exhaustedRatio is the share of the allocatable of a resource under which its idle amount is
+// considered exhausted
+const exhaustedRatio = 0.05
+
+// Report is the fragmentation of the cluster at a point in time.
+type Report struct {
+	Time       time.Time      `json:"time"`
+	GPUTypes   []GPUTypeStats `json:"gpuTypes"`
+	Nodes      []NodeStranded `json:"nodes"`
+	Migrations []Migration    `json:"migrations"`
+}
+
+// GPUTypeStats is the fragmentation of the gpus of a type.
+type GPUTypeStats struct {
+	Type     string `json:"type"`
+	Nodes    int    `json:"nodes"`
+	IdleGPUs int    `json:"idleGPUs"`
+	// LargestPodGPUs is the largest number of gpus a single pod can still get
+	LargestPodGPUs int `json:"largestPodGPUs"`
+	// IdleNodes is the number of nodes with all their gpus idle, the largest gang of whole-node
+	// pods that can still be placed
+	IdleNodes int `json:"idleNodes"`
+	// Fragmentation is the share of the idle gpus which are not on idle nodes
+	Fragmentation float64 `json:"fragmentation"`
+}
+
+// NodeStranded is the idle capacity of a node which can't be used because another resource of
+// the node is exhausted.
+type NodeStranded struct {
+	Name     string  `json:"name"`
+	MilliCPU float64 `json:"milliCPU"`
+	Memory   float64 `json:"memory"`
+	GPUs     int     `json:"gpus"`
+}
+
+// Migration moves a gpu task to another node so that its current node frees its gpus.
+type Migration struct {
+	Task string `json:"task"`
+	// UID is the uid of the task, to select it as victim
+	UID  api.TaskID `json:"-"`
+	GPUs int        `json:"gpus"`
+	From string     `json:"from"`
+	To   string     `json:"to"`
+}
+
+// Analyze computes the fragmentation of the nodes, suggesting at most maxMigrations migrations.
+// The gpu type of a node is the value of its gpuTypeLabel label.
+func Analyze(nodes map[string]*api.NodeInfo, gpuTypeLabel string, maxMigrations int) *Report {
+	report := &Report{Time: time.Now()}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byType := map[string][]*api.NodeInfo{}
+	for _, name := range names {
+		node := nodes[name]
+		if stranded := strandedOf(node); stranded != nil {
+			report.Nodes = append(report.Nodes, *stranded)
+		}
+		if gpus(node.Allocatable) == 0 {
+			continue
+		}
+		gpuType := UnknownGPUType
+		if node.Node != nil && node.Node.Labels[gpuTypeLabel] != "" {
+			gpuType = node.Node.Labels[gpuTypeLabel]
+		}
+		byType[gpuType] = append(byType[gpuType], node)
+	}
+
+	types := make([]string, 0, len(byType))
+	for gpuType := range byType {
+		types = append(types, gpuType)
+	}
+	sort.Strings(types)
+	for _, gpuType := range types {
+		report.GPUTypes = append(report.GPUTypes, statsOf(gpuType, byType[gpuType]))
+		if left := maxMigrations - len(report.Migrations); left > 0 {
+			report.Migrations = append(report.Migrations, consolidate(byType[gpuType], left)...)
+		}
+	}
+	return report
+}
+
+func gpus(r *api.Resource) int {
+	return int(r.Get(api.GPUResourceName) / 1000)
+}
+
+func exhausted(idle, allocatable float64) bool {
+	return allocatable > 0 && idle < allocatable*exhaustedRatio
+}
+
+func strandedOf(node *api.NodeInfo) *NodeStranded {
+	idle, alloc := node.Idle, node.Allocatable
+	cpuExhausted := exhausted(idle.MilliCPU, alloc.MilliCPU)
+	memExhausted := exhausted(idle.Memory, alloc.Memory)
+
+	stranded := &NodeStranded{Name: node.Name}
+	if memExhausted && !cpuExhausted {
+		stranded.MilliCPU = idle.MilliCPU
+	}
+	if cpuExhausted && !memExhausted {
+		stranded.Memory = idle.Memory
+	}
+	if cpuExhausted || memExhausted {
+		stranded.GPUs = gpus(idle)
+	}
+	if stranded.MilliCPU == 0 && stranded.Memory == 0 && stranded.GPUs == 0 {
+		return nil
+	}
+	return stranded
+}
+
+func statsOf(gpuType string, nodes []*api.NodeInfo) GPUTypeStats {
+	stats := GPUTypeStats{Type: gpuType, Nodes: len(nodes)}
+	onIdleNodes := 0
+	for _, node := range nodes {
+		idle := gpus(node.Idle)
+		stats.IdleGPUs += idle
+		if idle > stats.LargestPodGPUs {
+			stats.LargestPodGPUs = idle
+		}
+		if idle == gpus(node.Allocatable) {
+			stats.IdleNodes++
+			onIdleNodes += idle
+		}
+	}
+	if stats.IdleGPUs > 0 {
+		stats.Fragmentation = 1 - float64(onIdleNodes)/float64(stats.IdleGPUs)
+	}
+	return stats
+}
+
+// consolidate empties the partially used nodes with the fewest used gpus by moving their gpu tasks
+// to the other partially used nodes, the fullest first, as long as all the gpu tasks of a node
+// can be moved.
+func consolidate(nodes []*api.NodeInfo, maxMigrations int) []Migration {
+	idle := map[string]*api.Resource{}
+	var partial []*api.NodeInfo
+	for _, node := range nodes {
+		idle[node.Name] = node.Idle.Clone()
+		if used := gpus(node.Used); used > 0 && gpus(node.Idle) > 0 {
+			partial = append(partial, node)
+		}
+	}
+	sort.SliceStable(partial, func(i, j int) bool { return gpus(partial[i].Used) < gpus(partial[j].Used) })
+
+	var migrations []Migration
+	emptied := map[string]bool{}
+	for _, source := range partial {
+		tasks := movableTasks(source)
+		if tasks == nil || len(migrations)+len(tasks) > maxMigrations {
+			continue
+		}
+		targets := make([]*api.NodeInfo, 0, len(partial))
+		for _, node := range partial {
+			if node != source && !emptied[node.Name] {
+				targets = append(targets, node)
+			}
+		}
+		sort.SliceStable(targets, func(i, j int) bool { return gpus(idle[targets[i].Name]) < gpus(idle[targets[j].Name]) })
+
+		planned := map[string]*api.Resource{}
+		for _, target := range targets {
+			planned[target.Name] = idle[target.Name].Clone()
+		}
+		var moves []Migration
+		for _, task := range tasks {
+			for _, target := range targets {
+				if task.Resreq.LessEqual(planned[target.Name], api.Zero) {
+					planned[target.Name].Sub(task.Resreq)
+					moves = append(moves, Migration{
+						Task: fmt.Sprintf("%s/%s", task.Namespace, task.Name),
+						UID:  task.UID,
+						GPUs: gpus(task.Resreq),
+						From: source.Name,
+						To:   target.Name,
+					})
+					break
+				}
+			}
+		}
+		if len(moves) != len(tasks) {
+			continue
+		}
+		for name, resource := range planned {
+			idle[name] = resource
+		}
+		emptied[source.Name] = true
+		migrations = append(migrations, moves...)
+	}
+	return migrations
+}
+
+// movableTasks returns the gpu tasks of the node, nil if one of them can't be evicted.
+func movableTasks(node *api.NodeInfo) []*api.TaskInfo {
+	var tasks []*api.TaskInfo
+	for _, task := range node.Tasks {
+		if gpus(task.Resreq) == 0 {
+			continue
+		}
+		if task.Status != api.Running || !task.Preemptable {
+			return nil
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].UID < tasks[j].UID })
+	return tasks
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defrag
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func buildNode(name, cpu, memory, gpus string, tasks ...*api.TaskInfo) *api.NodeInfo {
+	labels := map[string]string{}
+	scalars := []api.ScalarResource{{Name: "pods", Value: "110"}}
+	if gpus != "" {
+		labels["gpu.product"] = "A100"
+		scalars = append(scalars, api.ScalarResource{Name: api.GPUResourceName, Value: gpus})
+	}
+	node := api.NewNodeInfo(util.BuildNode(name, api.BuildResourceList(cpu, memory, scalars...), labels))
+	for _, task := range tasks {
+		node.AddTask(task)
+	}
+	return node
+}
+
+func buildTask(name, nodeName, cpu, memory, gpus string) *api.TaskInfo {
+	var scalars []api.ScalarResource
+	if gpus != "" {
+		scalars = append(scalars, api.ScalarResource{Name: api.GPUResourceName, Value: gpus})
+	}
+	pod := util.BuildPod("c1", name, nodeName, v1.PodRunning, api.BuildResourceList(cpu, memory, scalars...), "pg1", nil, nil)
+	pod.Annotations[v1beta1.PodPreemptable] = "true"
+	return api.NewTaskInfo(pod)
+}
+
+func TestAnalyze(t *testing.T) {
+	nodes := map[string]*api.NodeInfo{
+		"n1": buildNode("n1", "32", "128Gi", "4", buildTask("p1", "n1", "4", "8Gi", "1")),
+		"n2": buildNode("n2", "32", "128Gi", "4", buildTask("p2", "n2", "8", "16Gi", "2")),
+		"n3": buildNode("n3", "32", "128Gi", "4"),
+		"n4": buildNode("n4", "4", "8Gi", "", buildTask("p4", "n4", "4", "1Gi", "")),
+	}
+
+	report := Analyze(nodes, "gpu.product", 1)
+
+	expectedTypes := []GPUTypeStats{{Type: "A100", Nodes: 3, IdleGPUs: 9, LargestPodGPUs: 4, IdleNodes: 1, Fragmentation: 1 - 4.0/9}}
+	if !reflect.DeepEqual(report.GPUTypes, expectedTypes) {
+		t.Errorf("expected gpu types %+v, got %+v", expectedTypes, report.GPUTypes)
+	}
+	expectedNodes := []NodeStranded{{Name: "n4", Memory: 7 * 1024 * 1024 * 1024}}
+	if !reflect.DeepEqual(report.Nodes, expectedNodes) {
+		t.Errorf("expected stranded nodes %+v, got %+v", expectedNodes, report.Nodes)
+	}
+	expectedMigrations := []Migration{{Task: "c1/p1", UID: "c1-p1", GPUs: 1, From: "n1", To: "n2"}}
+	if !reflect.DeepEqual(report.Migrations, expectedMigrations) {
+		t.Errorf("expected migrations %+v, got %+v", expectedMigrations, report.Migrations)
+	}
+}
+
+func TestAnalyzeUnmovableTasks(t *testing.T) {
+	p1 := buildTask("p1", "n1", "4", "8Gi", "1")
+	p1.Preemptable = false
+	nodes := map[string]*api.NodeInfo{
+		"n1": buildNode("n1", "32", "128Gi", "4", p1),
+		"n2": buildNode("n2", "32", "128Gi", "4", buildTask("p2", "n2", "8", "16Gi", "2")),
+	}
+
+	// p1 can't be evicted, p2 is moved to n1 instead
+	report := Analyze(nodes, "gpu.product", 2)
+	expectedMigrations := []Migration{{Task: "c1/p2", UID: "c1-p2", GPUs: 2, From: "n2", To: "n1"}}
+	if !reflect.DeepEqual(report.Migrations, expectedMigrations) {
+		t.Errorf("expected migrations %+v, got %+v", expectedMigrations, report.Migrations)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defrag
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+)
+
+// Path is the HTTP path the latest report is served on.
+const Path = "/api/v1/defrag"
+
+var latest atomic.Pointer[Report]
+
+// SetLatest stores the report as the latest one.
+func SetLatest(report *Report) {
+	latest.Store(report)
+}
+
+// Latest returns the latest report, nil if there is none yet.
+func Latest() *Report {
+	return latest.Load()
+}
+
+// NewHandler returns a read-only HTTP handler serving the latest report. It covers all the nodes,
+// so the user of the request must be allowed to list the nodes.
+func NewHandler(authorizer *apiauth.Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		user, err := authorizer.Authenticate(r)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		if err := authorizer.Authorize(r.Context(), user, authorizationv1.ResourceAttributes{
+			Verb: "list", Resource: "nodes",
+		}); err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		report := Latest()
+		if report == nil {
+			http.Error(w, "no fragmentation report yet, is the defrag plugin enabled?", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			klog.Errorf("Failed to encode fragmentation report: %v", err)
+		}
+	})
+}
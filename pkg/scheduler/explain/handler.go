@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+)
+
+// Path is the HTTP path the explanations are served on, as Path<namespace>/<podgroup>.
+const Path = "/explain/"
+
+// NewHandler returns the HTTP handler serving the explanations of the last cycle. The user of the
+// request must be allowed to get the podgroup.
+func NewHandler(r *Recorder, authorizer *apiauth.Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, Path), "/")
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			http.Error(w, fmt.Sprintf("expected %s<namespace>/<podgroup>", Path), http.StatusBadRequest)
+			return
+		}
+
+		user, err := authorizer.Authenticate(req)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		if err := authorizer.Authorize(req.Context(), user, authorizationv1.ResourceAttributes{
+			Namespace: parts[0], Group: "scheduling.volcano.sh", Verb: "get", Resource: "podgroups", Name: parts[1],
+		}); err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+
+		e, found := r.Get(parts[0], parts[1])
+		if !found {
+			http.Error(w, fmt.Sprintf("podgroup <%s/%s> had no pending task considered in the last cycle, "+
+				"check its conditions for why it was not allocated", parts[0], parts[1]), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(e); err != nil {
+			klog.Errorf("Failed to encode explanation: %v", err)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package explain records why the pending tasks of each PodGroup were placed or not in the last
+// scheduling cycle.
+package explain
+
+import (
+	"sync"
+	"time"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// Explanation is the record of a PodGroup in a scheduling cycle.
+type Explanation struct {
+	Namespace string             `json:"namespace"`
+	PodGroup  string             `json:"podGroup"`
+	Cycle     string             `json:"cycle"`
+	Time      time.Time          `json:"time"`
+	Tasks     []*TaskExplanation `json:"tasks"`
+	// Decision is what became of the placements of the tasks
+	Decision string `json:"decision,omitempty"`
+}
+
+// TaskExplanation is the record of a pending task: the nodes filtered out by the predicates, the
+// scores of the candidate nodes and the final decision.
+type TaskExplanation struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Filtered holds the verdict of the plugins on the nodes filtered out, by node name
+	Filtered map[string][]Verdict `json:"filtered,omitempty"`
+	// Scores holds the score given by each plugin to the candidate nodes, by node name, the sum of
+	// its node order, reduced map and batch scores
+	Scores map[string]map[string]float64 `json:"scores,omitempty"`
+	// Node is the node the task was allocated or pipelined to, if any
+	Node     string `json:"node,omitempty"`
+	Decision string `json:"decision"`
+}
+
+// Verdict is the reason a plugin filtered out a node.
+type Verdict struct {
+	Plugin string `json:"plugin,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Recorder keeps the explanations of the last cycle while recording the ones of the current cycle.
+type Recorder struct {
+	mutex   sync.RWMutex
+	enabled bool
+	cycle   string
+	current map[string]*Explanation
+	last    map[string]*Explanation
+}
+
+var defaultRecorder = &Recorder{}
+
+// Default returns the recorder of the scheduler process.
+func Default() *Recorder {
+	return defaultRecorder
+}
+
+// Enable starts the recording, the explanations are only recorded once enabled.
+func (r *Recorder) Enable() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.enabled = true
+}
+
+// Enabled returns whether the explanations are recorded.
+func (r *Recorder) Enabled() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.enabled
+}
+
+// StartCycle starts recording the explanations of a cycle.
+func (r *Recorder) StartCycle(cycle string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cycle = cycle
+	r.current = map[string]*Explanation{}
+}
+
+// EndCycle makes the explanations of the cycle the ones served.
+func (r *Recorder) EndCycle() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.current == nil {
+		return
+	}
+	r.last = r.current
+	r.current = nil
+}
+
+// Get returns the explanation of the PodGroup in the last cycle.
+func (r *Recorder) Get(namespace, podGroup string) (*Explanation, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	e, found := r.last[namespace+"/"+podGroup]
+	return e, found
+}
+
+// explanation returns the record of the job, to be updated while the mutex is held, or nil when
+// the recording is disabled.
+func (r *Recorder) explanation(job *api.JobInfo) *Explanation {
+	if !r.enabled || r.current == nil {
+		return nil
+	}
+	key := job.Namespace + "/" + job.Name
+	e, found := r.current[key]
+	if !found {
+		e = &Explanation{Namespace: job.Namespace, PodGroup: job.Name, Cycle: r.cycle, Time: time.Now()}
+		r.current[key] = e
+	}
+	return e
+}
+
+// task returns the record of the task, to be updated while the mutex is held, or nil when the
+// recording is disabled.
+func (r *Recorder) task(job *api.JobInfo, task *api.TaskInfo) *TaskExplanation {
+	e := r.explanation(job)
+	if e == nil {
+		return nil
+	}
+	for _, t := range e.Tasks {
+		if t.Namespace == task.Namespace && t.Name == task.Name {
+			return t
+		}
+	}
+	t := &TaskExplanation{Namespace: task.Namespace, Name: task.Name}
+	e.Tasks = append(e.Tasks, t)
+	return t
+}
+
+// RecordFiltered records the nodes the predicates filtered out for the task.
+func (r *Recorder) RecordFiltered(job *api.JobInfo, task *api.TaskInfo, fitErrors *api.FitErrors) {
+	if fitErrors == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	t := r.task(job, task)
+	if t == nil {
+		return
+	}
+	for node, fe := range fitErrors.NodeErrors() {
+		if t.Filtered == nil {
+			t.Filtered = map[string][]Verdict{}
+		}
+		var verdicts []Verdict
+		for _, status := range fe.Status {
+			if status == nil {
+				continue
+			}
+			verdicts = append(verdicts, Verdict{Plugin: status.Plugin, Reason: status.Reason})
+		}
+		t.Filtered[node] = verdicts
+	}
+}
+
+// RecordScores adds the scores given by the plugins to a candidate node for the task.
+func (r *Recorder) RecordScores(job *api.JobInfo, task *api.TaskInfo, node string, scores map[string]float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	t := r.task(job, task)
+	if t == nil {
+		return
+	}
+	if t.Scores == nil {
+		t.Scores = map[string]map[string]float64{}
+	}
+	if t.Scores[node] == nil {
+		t.Scores[node] = map[string]float64{}
+	}
+	for plugin, score := range scores {
+		t.Scores[node][plugin] += score
+	}
+}
+
+// RecordDecision records the node the task was placed on, empty if none, and why.
+func (r *Recorder) RecordDecision(job *api.JobInfo, task *api.TaskInfo, node, decision string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	t := r.task(job, task)
+	if t == nil {
+		return
+	}
+	t.Node = node
+	t.Decision = decision
+}
+
+// RecordJob records what became of the placements of the tasks of the job.
+func (r *Recorder) RecordJob(job *api.JobInfo, decision string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if e := r.explanation(job); e != nil {
+		e.Decision = decision
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+)
+
+// newAuthorizer returns the authorizer of the user of the token "alice", who may only get the
+// podgroups of the namespace ns1.
+func newAuthorizer() *apiauth.Authorizer {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = true
+		review.Status.User = authenticationv1.UserInfo{Username: review.Spec.Token}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "alice" &&
+			attributes.Resource == "podgroups" && attributes.Verb == "get" && attributes.Namespace == "ns1"
+		return true, review, nil
+	})
+	return apiauth.New(client)
+}
+
+// get requests the explanation of the podgroup with the bearer token, if any.
+func get(t *testing.T, server *httptest.Server, podGroup, token string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, server.URL+Path+podGroup, nil)
+	if err != nil {
+		t.Fatalf("failed to build the request: %v", err)
+	}
+	if len(token) != 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to get explanation: %v", err)
+	}
+	return resp
+}
+
+func TestExplain(t *testing.T) {
+	job := &api.JobInfo{Namespace: "ns1", Name: "pg1"}
+	task := &api.TaskInfo{Namespace: "ns1", Name: "p1"}
+	node1, node2 := &api.NodeInfo{Name: "n1"}, &api.NodeInfo{Name: "n2"}
+
+	r := &Recorder{}
+	r.StartCycle("cycle1")
+	r.RecordDecision(job, task, "n2", "ignored while disabled")
+	r.EndCycle()
+	if _, found := r.Get("ns1", "pg1"); found {
+		t.Fatalf("expected no explanation recorded while disabled")
+	}
+
+	r.Enable()
+	r.StartCycle("cycle2")
+	fitErrors := api.NewFitErrors()
+	fitErrors.SetNodeError(node1.Name, api.NewFitErrWithStatus(task, node1, &api.Status{Code: api.UnschedulableAndUnresolvable, Reason: "node(s) didn't match", Plugin: "predicates"}))
+	r.RecordFiltered(job, task, fitErrors)
+	r.RecordScores(job, task, node2.Name, map[string]float64{"binpack": 10})
+	r.RecordScores(job, task, node2.Name, map[string]float64{"binpack": 5, "nodeorder": 2})
+	r.RecordDecision(job, task, node2.Name, "allocated")
+	r.RecordJob(job, "committed")
+	if _, found := r.Get("ns1", "pg1"); found {
+		t.Fatalf("expected the explanations of the cycle served once it ended")
+	}
+	r.EndCycle()
+
+	server := httptest.NewServer(NewHandler(r, newAuthorizer()))
+	defer server.Close()
+	resp := get(t, server, "ns1/pg1", "alice")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	e := &Explanation{}
+	if err := json.NewDecoder(resp.Body).Decode(e); err != nil {
+		t.Fatalf("failed to decode explanation: %v", err)
+	}
+	if e.Cycle != "cycle2" || e.Decision != "committed" || len(e.Tasks) != 1 {
+		t.Fatalf("unexpected explanation %+v", e)
+	}
+	got := e.Tasks[0]
+	if got.Node != "n2" || got.Scores["n2"]["binpack"] != 15 || got.Scores["n2"]["nodeorder"] != 2 || len(got.Filtered["n1"]) != 1 || got.Filtered["n1"][0].Plugin != "predicates" {
+		t.Errorf("unexpected task explanation %+v", got)
+	}
+
+	for _, test := range []struct {
+		podGroup string
+		token    string
+		status   int
+	}{
+		{podGroup: "ns1/pg2", token: "alice", status: http.StatusNotFound},
+		{podGroup: "ns1/pg1", status: http.StatusUnauthorized},
+		{podGroup: "ns1/pg1", token: "bob", status: http.StatusForbidden},
+		{podGroup: "ns2/pg1", token: "alice", status: http.StatusForbidden},
+	} {
+		resp := get(t, server, test.podGroup, test.token)
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("%s by %q: expected status %d, got %d", test.podGroup, test.token, test.status, resp.StatusCode)
+		}
+	}
+}
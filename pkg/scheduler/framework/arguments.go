@@ -17,11 +17,17 @@ limitations under the License.
 package framework
 
 import (
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
+	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/conf"
 )
 
+// ResourceWeightsKey is the argument holding the resource weights of the scheduler
+// configuration, it is given to the plugins comparing different resources.
+const ResourceWeightsKey = "resourceWeights"
+
 // Arguments map
 type Arguments map[string]interface{}
 
@@ -89,6 +95,56 @@ func (a Arguments) GetBool(ptr *bool, key string) {
 	*ptr = value
 }
 
+// GetResourceWeights get the resource weights from a map of resource name to weight
+func (a Arguments) GetResourceWeights(key string) api.ResourceWeights {
+	argv, ok := a[key]
+	if !ok {
+		return nil
+	}
+
+	weights := api.ResourceWeights{}
+	add := func(name, value interface{}) {
+		resource, ok := name.(string)
+		if !ok {
+			klog.Warningf("Could not parse resource name %v of argument %s", name, key)
+			return
+		}
+		switch weight := value.(type) {
+		case float64:
+			weights[v1.ResourceName(resource)] = weight
+		case int:
+			weights[v1.ResourceName(resource)] = float64(weight)
+		default:
+			klog.Warningf("Could not parse weight %v of resource %s in argument %s to float64", value, resource, key)
+		}
+	}
+	switch value := argv.(type) {
+	case map[string]float64:
+		for name, weight := range value {
+			add(name, weight)
+		}
+	case map[string]interface{}:
+		for name, weight := range value {
+			add(name, weight)
+		}
+	case map[interface{}]interface{}:
+		for name, weight := range value {
+			add(name, weight)
+		}
+	default:
+		klog.Warningf("Could not parse argument: %v for key %s to resource weights", argv, key)
+		return nil
+	}
+
+	for name, weight := range weights {
+		if weight < 0 {
+			klog.Warningf("Negative weight %v of resource %s in argument %s, using 1", weight, name, key)
+			weights[name] = 1
+		}
+	}
+	return weights
+}
+
 // GetArgOfActionFromConf return argument of action reading from configuration of schedule
 func GetArgOfActionFromConf(configurations []conf.Configuration, actionName string) Arguments {
 	for _, c := range configurations {
@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/equality"
 
+	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/conf"
 )
 
@@ -137,6 +138,42 @@ func TestArgumentsGetFloat64(t *testing.T) {
 	}
 }
 
+func TestArgumentsGetResourceWeights(t *testing.T) {
+	cases := []struct {
+		name   string
+		arg    Arguments
+		expect api.ResourceWeights
+	}{
+		{
+			name:   "key not exist",
+			arg:    Arguments{},
+			expect: nil,
+		},
+		{
+			name:   "parsed from yaml",
+			arg:    Arguments{ResourceWeightsKey: map[interface{}]interface{}{"nvidia.com/gpu": 4, "memory": 0.5}},
+			expect: api.ResourceWeights{"nvidia.com/gpu": 4, "memory": 0.5},
+		},
+		{
+			name:   "scheduler configuration",
+			arg:    Arguments{ResourceWeightsKey: map[string]float64{"cpu": 2, "pods": -1}},
+			expect: api.ResourceWeights{"cpu": 2, "pods": 1},
+		},
+		{
+			name:   "invalid value",
+			arg:    Arguments{ResourceWeightsKey: "nvidia.com/gpu=4"},
+			expect: nil,
+		},
+	}
+
+	for _, c := range cases {
+		weights := c.arg.GetResourceWeights(ResourceWeightsKey)
+		if !equality.Semantic.DeepEqual(weights, c.expect) {
+			t.Errorf("case %s, weights should be %v, but not %v", c.name, c.expect, weights)
+		}
+	}
+}
+
 func TestGetArgOfActionFromConf(t *testing.T) {
 	cases := []struct {
 		name              string
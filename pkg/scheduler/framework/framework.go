@@ -19,27 +19,39 @@ package framework
 import (
 	"time"
 
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
+	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/cache"
 	"volcano.sh/volcano/pkg/scheduler/conf"
 	"volcano.sh/volcano/pkg/scheduler/metrics"
+	"volcano.sh/volcano/pkg/scheduler/util"
 )
 
 // OpenSession start the session
 func OpenSession(cache cache.Cache, tiers []conf.Tier, configurations []conf.Configuration) *Session {
-	ssn := openSession(cache)
+	return OpenSessionWithJobFilter(cache, "", tiers, configurations, nil)
+}
+
+// OpenSessionWithJobFilter start the session of the scheduler profile which only schedules the jobs
+// accepted by jobFilter, all jobs are scheduled if jobFilter is nil. The profile of the top level
+// configuration is "".
+func OpenSessionWithJobFilter(cache cache.Cache, profile string, tiers []conf.Tier, configurations []conf.Configuration, jobFilter func(*api.JobInfo) bool) *Session {
+	ssn := openSession(cache, jobFilter)
+	ssn.profile = profile
+	util.ResetTieBreaker()
 	ssn.Tiers = tiers
 	ssn.Configurations = configurations
 	ssn.NodeMap = GenerateNodeMapAndSlice(ssn.Nodes)
 	ssn.PodLister = NewPodLister(ssn)
 
-	for _, tier := range tiers {
+	for i, tier := range tiers {
 		for _, plugin := range tier.Plugins {
 			if pb, found := GetPluginBuilder(plugin.Name); !found {
 				klog.Errorf("Failed to get plugin %s.", plugin.Name)
 			} else {
-				plugin := pb(plugin.Arguments)
+				plugin := buildPlugin(pluginKey{profile: profile, tier: i, name: plugin.Name}, pb, plugin.Arguments)
 				ssn.plugins[plugin.Name()] = plugin
 				onSessionOpenStart := time.Now()
 				plugin.OnSessionOpen(ssn)
@@ -50,6 +62,37 @@ func OpenSession(cache cache.Cache, tiers []conf.Tier, configurations []conf.Con
 	return ssn
 }
 
+// OpenPreviewSession starts a session to answer what-if questions about the jobs, which are
+// added to the jobs of the snapshot; it must be closed by ClosePreviewSession. Its plugins are
+// built afresh, so that the state the stateful plugins keep across the scheduling sessions is
+// left alone, and the metrics of the sessions are not updated until it is closed.
+func OpenPreviewSession(cache cache.Cache, tiers []conf.Tier, configurations []conf.Configuration, jobs []*api.JobInfo) *Session {
+	metrics.SuspendSessionMetrics()
+	ssn := openSession(cache, nil)
+	for _, job := range jobs {
+		ssn.Jobs[job.UID] = job
+	}
+	// the events the plugins record about the jobs are dropped, the jobs are not really scheduled
+	ssn.recorder = &record.FakeRecorder{}
+	ssn.Tiers = tiers
+	ssn.Configurations = configurations
+	ssn.NodeMap = GenerateNodeMapAndSlice(ssn.Nodes)
+	ssn.PodLister = NewPodLister(ssn)
+
+	for _, tier := range tiers {
+		for _, plugin := range tier.Plugins {
+			if pb, found := GetPluginBuilder(plugin.Name); !found {
+				klog.Errorf("Failed to get plugin %s.", plugin.Name)
+			} else {
+				plugin := pb(plugin.Arguments)
+				ssn.plugins[plugin.Name()] = plugin
+				plugin.OnSessionOpen(ssn)
+			}
+		}
+	}
+	return ssn
+}
+
 // CloseSession close the session
 func CloseSession(ssn *Session) {
 	for _, plugin := range ssn.plugins {
@@ -60,3 +103,10 @@ func CloseSession(ssn *Session) {
 
 	closeSession(ssn)
 }
+
+// ClosePreviewSession closes a session opened by OpenPreviewSession, unlike CloseSession the
+// plugins are not closed and the statuses of the jobs and queues of the session are not written back.
+func ClosePreviewSession(ssn *Session) {
+	releaseSession(ssn)
+	metrics.ResumeSessionMetrics()
+}
@@ -39,3 +39,12 @@ type Plugin interface {
 	OnSessionOpen(ssn *Session)
 	OnSessionClose(ssn *Session)
 }
+
+// StatefulPlugin is a plugin keeping state across the sessions, unlike the other plugins it is
+// not built again for each session but reused as long as its arguments are unchanged.
+type StatefulPlugin interface {
+	Plugin
+
+	// Stateful marks the plugin as reused across the sessions.
+	Stateful()
+}
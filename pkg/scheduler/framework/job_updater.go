@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	"volcano.sh/apis/pkg/apis/scheduling"
 	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
 )
 
 const (
@@ -94,9 +96,52 @@ func (ju *jobUpdater) updateJob(index int) {
 
 	job.PodGroup.Status = jobStatus(ssn, job)
 	oldStatus, found := ssn.podGroupStatus[job.UID]
+	if found && job.PodGroup.Status.Phase != oldStatus.Phase {
+		recordPhaseTransition(ssn, job)
+	}
 	updatePG := !found || isPodGroupStatusUpdated(job.PodGroup.Status, oldStatus)
 	if _, err := ssn.cache.UpdateJobStatus(job, updatePG); err != nil {
 		klog.Errorf("Failed to update job <%s/%s>: %v",
 			job.Namespace, job.Name, err)
 	}
 }
+
+// recordPhaseTransition records the new phase of the job in its history, with the reason of the
+// condition set in the session if any.
+func recordPhaseTransition(ssn *Session, job *api.JobInfo) {
+	transition := api.PhaseTransition{
+		Phase: job.PodGroup.Status.Phase,
+		Time:  metav1.Now(),
+	}
+	for _, c := range job.PodGroup.Status.Conditions {
+		if c.TransitionID == string(ssn.UID) {
+			transition.Reason = c.Reason
+			transition.Message = c.Message
+		}
+	}
+	if transition.Phase == scheduling.PodGroupRunning {
+		recordSchedulingLatency(ssn, job, transition.Time.Time)
+	}
+	job.PodGroup.RecordPhaseTransition(transition)
+}
+
+// recordSchedulingLatency records the time the job took from its last enqueue, or its creation
+// if it was never seen inqueue, to its running.
+func recordSchedulingLatency(ssn *Session, job *api.JobInfo, now time.Time) {
+	enqueued := job.PodGroup.CreationTimestamp.Time
+	history := job.PodGroup.PhaseHistory()
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Phase == scheduling.PodGroupInqueue {
+			enqueued = history[i].Time.Time
+			break
+		}
+	}
+	if enqueued.IsZero() {
+		return
+	}
+	queueName := string(job.Queue)
+	if queue, found := ssn.Queues[job.Queue]; found {
+		queueName = queue.Name
+	}
+	metrics.RecordQueueSchedulingLatency(queueName, job.MinAvailable, now.Sub(enqueued))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// JournalEntry is an operation applied to the snapshot of the session.
+type JournalEntry struct {
+	// Action is the name of the action which applied the operation
+	Action    string
+	Operation Operation
+	Task      *api.TaskInfo
+	NodeName  string
+	Reason    string
+
+	// statement is the statement which applied the operation until it is committed, the operation
+	// is only applied to the snapshot until then and is rolled back if the statement is discarded
+	statement *Statement
+}
+
+// RunAction executes the action on the session, the operations it applies to the
+// snapshot are journaled under its name.
+func (ssn *Session) RunAction(action Action) {
+	ssn.currentAction = action.Name()
+	defer func() {
+		ssn.currentAction = ""
+	}()
+	action.Execute(ssn)
+}
+
+// Journal returns the operations committed so far, in order.
+func (ssn *Session) Journal() []JournalEntry {
+	var journal []JournalEntry
+	for _, entry := range ssn.journal {
+		if entry.statement == nil {
+			journal = append(journal, *entry)
+		}
+	}
+	return journal
+}
+
+// JournaledNodes returns the names of the nodes on which the operation was committed
+// by the given action, or by any action if action is empty.
+func (ssn *Session) JournaledNodes(action string, operation Operation) map[string]struct{} {
+	nodes := map[string]struct{}{}
+	for _, entry := range ssn.journal {
+		if entry.statement != nil || entry.Operation != operation || (len(action) != 0 && entry.Action != action) {
+			continue
+		}
+		nodes[entry.NodeName] = struct{}{}
+	}
+	return nodes
+}
+
+// record journals the operation, applied to the snapshot by the statement or committed if nil.
+func (ssn *Session) record(stmt *Statement, operation Operation, task *api.TaskInfo, nodeName, reason string) *JournalEntry {
+	entry := &JournalEntry{
+		Action:    ssn.currentAction,
+		Operation: operation,
+		Task:      task,
+		NodeName:  nodeName,
+		Reason:    reason,
+		statement: stmt,
+	}
+	ssn.journal = append(ssn.journal, entry)
+	klog.V(5).Infof("Journaled operation %d of action <%s> on task <%s/%s>, node <%s>",
+		operation, ssn.currentAction, task.Namespace, task.Name, nodeName)
+	return entry
+}
+
+// forget drops the rolled back operations from the journal.
+func (ssn *Session) forget(entries []*JournalEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	forgotten := make(map[*JournalEntry]struct{}, len(entries))
+	for _, entry := range entries {
+		forgotten[entry] = struct{}{}
+	}
+	journal := ssn.journal[:0]
+	for _, entry := range ssn.journal {
+		if _, found := forgotten[entry]; !found {
+			journal = append(journal, entry)
+		}
+	}
+	for i := len(journal); i < len(ssn.journal); i++ {
+		ssn.journal[i] = nil
+	}
+	ssn.journal = journal
+}
+
+// UnPipeline rolls back the pipeline of the task committed earlier in the session, which is
+// dropped from the journal.
+func (ssn *Session) UnPipeline(task *api.TaskInfo) error {
+	if err := ssn.Statement().UnPipeline(task); err != nil {
+		return err
+	}
+	for i := len(ssn.journal) - 1; i >= 0; i-- {
+		if entry := ssn.journal[i]; entry.statement == nil && entry.Operation == Pipeline && entry.Task == task {
+			ssn.forget([]*JournalEntry{entry})
+			break
+		}
+	}
+	return nil
+}
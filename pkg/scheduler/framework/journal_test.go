@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+type fakeAction struct {
+	name    string
+	execute func(ssn *Session)
+}
+
+func (fa *fakeAction) Name() string         { return fa.name }
+func (fa *fakeAction) Initialize()          {}
+func (fa *fakeAction) Execute(ssn *Session) { fa.execute(ssn) }
+func (fa *fakeAction) UnInitialize()        {}
+
+func TestJournal(t *testing.T) {
+	scherCache := cache.NewDefaultMockSchedulerCache("test-scheduler")
+	scherCache.AddOrUpdateNode(util.BuildNode("n1", api.BuildResourceList("2", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), nil))
+	scherCache.AddOrUpdateNode(util.BuildNode("n2", api.BuildResourceList("2", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), nil))
+	scherCache.AddPod(util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", nil, nil))
+	scherCache.AddPod(util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", nil, nil))
+	scherCache.AddPodGroupV1beta1(util.BuildPodGroup("pg1", "c1", "c1", 1, nil, schedulingv1.PodGroupInqueue))
+	scherCache.AddQueueV1beta1(util.BuildQueue("c1", 1, nil))
+
+	ssn := OpenSession(scherCache, nil, nil)
+	defer CloseSession(ssn)
+
+	job := ssn.Jobs["c1/pg1"]
+	p1, p2 := job.Tasks["c1-p1"], job.Tasks["c1-p2"]
+
+	ssn.RunAction(&fakeAction{name: "first", execute: func(ssn *Session) {
+		assert.NoError(t, ssn.Pipeline(p1, "n1"))
+	}})
+	ssn.RunAction(&fakeAction{name: "second", execute: func(ssn *Session) {
+		// discarded operations are not journaled
+		stmt := NewStatement(ssn)
+		assert.NoError(t, stmt.Pipeline(p2, "n2", false))
+		stmt.Discard()
+	}})
+
+	journal := ssn.Journal()
+	assert.Equal(t, 1, len(journal))
+	assert.Equal(t, "first", journal[0].Action)
+	assert.Equal(t, Operation(Pipeline), journal[0].Operation)
+	assert.Equal(t, "n1", journal[0].NodeName)
+	assert.Equal(t, map[string]struct{}{"n1": {}}, ssn.JournaledNodes("", Pipeline))
+	assert.Equal(t, map[string]struct{}{}, ssn.JournaledNodes("second", Pipeline))
+
+	ssn.RunAction(&fakeAction{name: "third", execute: func(ssn *Session) {
+		// the operations of a statement are journaled once committed, the rolled back ones never
+		stmt := NewStatement(ssn)
+		checkpoint := stmt.Checkpoint()
+		assert.NoError(t, stmt.Pipeline(p2, "n2", false))
+		assert.Equal(t, 2, len(ssn.journal))
+		assert.Equal(t, 1, len(ssn.Journal()))
+		assert.Equal(t, 0, stmt.Rollback(checkpoint))
+		assert.Equal(t, 1, len(ssn.journal))
+		assert.NoError(t, stmt.Pipeline(p2, "n1", false))
+		stmt.Commit()
+	}})
+	journal = ssn.Journal()
+	assert.Equal(t, 2, len(journal))
+	assert.Equal(t, "third", journal[1].Action)
+	assert.Equal(t, "n1", journal[1].NodeName)
+	assert.Equal(t, map[string]struct{}{"n1": {}}, ssn.JournaledNodes("third", Pipeline))
+	assert.Equal(t, api.Pipelined, p1.Status)
+
+	// the pipelines rolled back by the next actions leave the journal and the snapshot
+	assert.NoError(t, ssn.UnPipeline(p1))
+	journal = ssn.Journal()
+	assert.Equal(t, 1, len(journal))
+	assert.Equal(t, p2, journal[0].Task)
+	assert.Equal(t, api.Pending, p1.Status)
+	assert.Equal(t, 1, len(ssn.Nodes["n1"].Tasks))
+}
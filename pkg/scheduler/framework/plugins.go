@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"plugin"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -28,18 +29,43 @@ import (
 
 var pluginMutex sync.RWMutex
 
+// statefulPlugin is a StatefulPlugin with the arguments it was built for.
+type statefulPlugin struct {
+	arguments Arguments
+	plugin    Plugin
+}
+
+// pluginKey identifies a plugin of a tier of a scheduler profile, the profile of the top level
+// configuration is "".
+type pluginKey struct {
+	profile string
+	tier    int
+	name    string
+}
+
+// statefulPlugins are the StatefulPlugins reused across the sessions, by profile, tier and name
+var statefulPlugins = map[pluginKey]*statefulPlugin{}
+
 // PluginBuilder plugin management
 type PluginBuilder = func(Arguments) Plugin
 
 // Plugin management
 var pluginBuilders = map[string]PluginBuilder{}
 
+// resourceWeightedPlugins are the plugins comparing different resources by the resource weights
+var resourceWeightedPlugins = map[string]bool{}
+
 // RegisterPluginBuilder register the plugin
 func RegisterPluginBuilder(name string, pc PluginBuilder) {
 	pluginMutex.Lock()
 	defer pluginMutex.Unlock()
 
 	pluginBuilders[name] = pc
+	for key := range statefulPlugins {
+		if key.name == name {
+			delete(statefulPlugins, key)
+		}
+	}
 }
 
 // CleanupPluginBuilders cleans up all the plugin
@@ -48,6 +74,43 @@ func CleanupPluginBuilders() {
 	defer pluginMutex.Unlock()
 
 	pluginBuilders = map[string]PluginBuilder{}
+	statefulPlugins = map[pluginKey]*statefulPlugin{}
+}
+
+// buildPlugin builds the plugin for a session, the StatefulPlugin built by a previous session
+// for the same profile, tier and arguments is reused instead.
+func buildPlugin(key pluginKey, pb PluginBuilder, arguments Arguments) Plugin {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	if sp, found := statefulPlugins[key]; found && reflect.DeepEqual(sp.arguments, arguments) {
+		return sp.plugin
+	}
+	plugin := pb(arguments)
+	if _, ok := plugin.(StatefulPlugin); ok {
+		statefulPlugins[key] = &statefulPlugin{arguments: arguments, plugin: plugin}
+	} else {
+		delete(statefulPlugins, key)
+	}
+	return plugin
+}
+
+// RegisterResourceWeighted declares that the plugin compares different resources by the resource
+// weights of its ResourceWeightsKey argument, which the resource weights of the scheduler
+// configuration are given to unless the plugin sets its own.
+func RegisterResourceWeighted(name string) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	resourceWeightedPlugins[name] = true
+}
+
+// IsResourceWeighted returns whether the plugin was declared to use the resource weights.
+func IsResourceWeighted(name string) bool {
+	pluginMutex.RLock()
+	defer pluginMutex.RUnlock()
+
+	return resourceWeightedPlugins[name]
 }
 
 // GetPluginBuilder get the pluginbuilder by name
@@ -16,7 +16,13 @@ limitations under the License.
 
 package framework
 
-import "testing"
+import (
+	"testing"
+
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
 
 func TestGetPluginName(t *testing.T) {
 	cases := []struct {
@@ -52,3 +58,87 @@ func TestGetPluginName(t *testing.T) {
 		}
 	}
 }
+
+type fakePlugin struct{ name string }
+
+func (fp *fakePlugin) Name() string                { return fp.name }
+func (fp *fakePlugin) OnSessionOpen(ssn *Session)  {}
+func (fp *fakePlugin) OnSessionClose(ssn *Session) {}
+
+type fakeStatefulPlugin struct{ fakePlugin }
+
+func (fsp *fakeStatefulPlugin) Stateful() {}
+
+func TestBuildPlugin(t *testing.T) {
+	defer CleanupPluginBuilders()
+	stateless := func(Arguments) Plugin { return &fakePlugin{name: "stateless"} }
+	stateful := func(Arguments) Plugin { return &fakeStatefulPlugin{fakePlugin{name: "stateful"}} }
+	arguments := Arguments{"weight": 1}
+	statelessKey := pluginKey{name: "stateless"}
+	key := pluginKey{name: "stateful"}
+
+	if buildPlugin(statelessKey, stateless, arguments) == buildPlugin(statelessKey, stateless, arguments) {
+		t.Errorf("expected the plugin built again for each session")
+	}
+	plugin := buildPlugin(key, stateful, arguments)
+	if buildPlugin(key, stateful, Arguments{"weight": 1}) != plugin {
+		t.Errorf("expected the stateful plugin reused for the same arguments")
+	}
+	other := buildPlugin(pluginKey{profile: "other-scheduler", name: "stateful"}, stateful, arguments)
+	if other == plugin {
+		t.Errorf("expected the stateful plugin built again for another profile")
+	}
+	if buildPlugin(pluginKey{tier: 1, name: "stateful"}, stateful, arguments) == plugin {
+		t.Errorf("expected the stateful plugin built again for another tier")
+	}
+	if buildPlugin(key, stateful, arguments) != plugin {
+		t.Errorf("expected the stateful plugin of the profile kept")
+	}
+	rebuilt := buildPlugin(key, stateful, Arguments{"weight": 2})
+	if rebuilt == plugin {
+		t.Errorf("expected the stateful plugin built again for other arguments")
+	}
+	RegisterPluginBuilder("stateful", stateful)
+	if buildPlugin(pluginKey{profile: "other-scheduler", name: "stateful"}, stateful, arguments) == other {
+		t.Errorf("expected the stateful plugins of every profile built again once the plugin is registered again")
+	}
+	rebuilt = buildPlugin(key, stateful, Arguments{"weight": 2})
+	CleanupPluginBuilders()
+	if buildPlugin(key, stateful, Arguments{"weight": 2}) == rebuilt {
+		t.Errorf("expected the stateful plugin built again after the cleanup")
+	}
+}
+
+// closingPlugin is a stateful plugin counting the sessions it is closed for.
+type closingPlugin struct {
+	fakeStatefulPlugin
+	closed int
+}
+
+func (cp *closingPlugin) OnSessionClose(ssn *Session) { cp.closed++ }
+
+func TestPreviewSessionPlugins(t *testing.T) {
+	defer CleanupPluginBuilders()
+	RegisterPluginBuilder("closing", func(Arguments) Plugin {
+		return &closingPlugin{fakeStatefulPlugin: fakeStatefulPlugin{fakePlugin{name: "closing"}}}
+	})
+	schedulerCache := cache.NewCustomMockSchedulerCache("mock-test", util.NewFakeBinder(0), nil, &util.FakeStatusUpdater{}, nil, &util.FakeVolumeBinder{}, nil)
+	tiers := []conf.Tier{{Plugins: []conf.PluginOption{{Name: "closing"}}}}
+
+	ssn := OpenSession(schedulerCache, tiers, nil)
+	stateful := ssn.plugins["closing"].(*closingPlugin)
+	CloseSession(ssn)
+
+	preview := OpenPreviewSession(schedulerCache, tiers, nil, nil)
+	own := preview.plugins["closing"].(*closingPlugin)
+	if own == stateful {
+		t.Errorf("expected the preview session to build its own plugins")
+	}
+	ClosePreviewSession(preview)
+	if stateful.closed != 1 || own.closed != 0 {
+		t.Errorf("expected the plugins not closed by the preview session, got %d and %d closes", stateful.closed, own.closed)
+	}
+	if ssn := OpenSession(schedulerCache, tiers, nil); ssn.plugins["closing"] != stateful {
+		t.Errorf("expected the stateful plugin kept for the scheduling sessions")
+	}
+}
@@ -18,6 +18,9 @@ package framework
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -44,6 +47,8 @@ import (
 // Session information for the current session
 type Session struct {
 	UID types.UID
+	// profile is the scheduler profile of the session, "" for the top level configuration
+	profile string
 
 	kubeClient      kubernetes.Interface
 	recorder        record.EventRecorder
@@ -52,9 +57,21 @@ type Session struct {
 	informerFactory informers.SharedInformerFactory
 
 	TotalResource *api.Resource
+	// Totals are the totals of the nodes of the session at the opening of the session
+	Totals *api.ClusterTotals
 	// podGroupStatus cache podgroup status during schedule
 	// This should not be mutated after initiated
 	podGroupStatus map[api.JobID]scheduling.PodGroupStatus
+	// snapshotJobs are all jobs of the snapshot, including the ones filtered out of Jobs
+	snapshotJobs map[api.JobID]*api.JobInfo
+	// backedOff are the jobs backed off after failing to be scheduled, kept in the session but
+	// not allocated
+	backedOff map[api.JobID]struct{}
+	// journal records the operations applied to the snapshot by the actions of the session,
+	// including the ones of the statements not committed yet
+	journal []*JournalEntry
+	// currentAction is the name of the action being executed
+	currentAction string
 
 	Jobs           map[api.JobID]*api.JobInfo
 	Nodes          map[string]*api.NodeInfo
@@ -62,6 +79,12 @@ type Session struct {
 	RevocableNodes map[string]*api.NodeInfo
 	Queues         map[api.QueueID]*api.QueueInfo
 	NamespaceInfo  map[api.NamespaceName]*api.NamespaceInfo
+	// MaintenanceWindows are the maintenance windows in progress
+	MaintenanceWindows []*api.MaintenanceWindow
+	// Reservations are the Reservations in progress
+	Reservations []*api.Reservation
+	// ResourceFlavors are the ResourceFlavors by name
+	ResourceFlavors map[string]*api.ResourceFlavor
 
 	// NodeMap is like Nodes except that it uses k8s NodeInfo api and should only
 	// be used in k8s compatable api scenarios such as in predicates and nodeorder plugins.
@@ -103,7 +126,7 @@ type Session struct {
 	jobStarvingFns    map[string]api.ValidateFn
 }
 
-func openSession(cache cache.Cache) *Session {
+func openSession(cache cache.Cache, jobFilter func(*api.JobInfo) bool) *Session {
 	ssn := &Session{
 		UID:             uuid.NewUUID(),
 		kubeClient:      cache.Client(),
@@ -151,7 +174,24 @@ func openSession(cache cache.Cache) *Session {
 
 	snapshot := cache.Snapshot()
 
+	now := time.Now()
 	ssn.Jobs = snapshot.Jobs
+	if jobFilter != nil {
+		ssn.Jobs = make(map[api.JobID]*api.JobInfo, len(snapshot.Jobs))
+		for uid, job := range snapshot.Jobs {
+			if jobFilter(job) {
+				ssn.Jobs[uid] = job
+			}
+		}
+	}
+	ssn.backedOff = map[api.JobID]struct{}{}
+	for uid := range ssn.Jobs {
+		if cache.BackedOff(uid, now) {
+			klog.V(4).Infof("Job <%s> is backed off after failing to be scheduled, skip its allocation", uid)
+			ssn.backedOff[uid] = struct{}{}
+		}
+	}
+	ssn.snapshotJobs = snapshot.Jobs
 	for _, job := range ssn.Jobs {
 		if job.PodGroup != nil {
 			ssn.podGroupStatus[job.UID] = *job.PodGroup.Status.DeepCopy()
@@ -177,15 +217,26 @@ func openSession(cache cache.Cache) *Session {
 		}
 	}
 	ssn.NodeList = util.GetNodeList(snapshot.Nodes, snapshot.NodeList)
+	util.SortNodesByName(ssn.NodeList)
 	ssn.Nodes = snapshot.Nodes
 	ssn.CSINodesStatus = snapshot.CSINodesStatus
 	ssn.RevocableNodes = snapshot.RevocableNodes
 	ssn.Queues = snapshot.Queues
 	ssn.NamespaceInfo = snapshot.NamespaceInfo
-	// calculate all nodes' resource only once in each schedule cycle, other plugins can clone it when need
-	for _, n := range ssn.Nodes {
-		ssn.TotalResource.Add(n.Allocatable)
+	ssn.MaintenanceWindows = snapshot.MaintenanceWindows
+	ssn.Reservations = snapshot.Reservations
+	ssn.ResourceFlavors = snapshot.ResourceFlavors
+	// the totals are kept up to date by the cache, they are only summed up here for the
+	// snapshots built without them
+	ssn.Totals = snapshot.Totals
+	if ssn.Totals == nil {
+		ssn.Totals = api.NewClusterTotals()
+		for name, n := range ssn.Nodes {
+			ssn.Totals.UpdateNode(name, n)
+		}
 	}
+	// other plugins can clone it when need
+	ssn.TotalResource.Add(ssn.Totals.Allocatable)
 
 	klog.V(3).Infof("Open Session %v with <%d> Job and <%d> Queues",
 		ssn.UID, len(ssn.Jobs), len(ssn.Queues))
@@ -193,46 +244,124 @@ func openSession(cache cache.Cache) *Session {
 	return ssn
 }
 
-// updateQueueStatus updates allocated field in queue status on session close.
-func updateQueueStatus(ssn *Session) {
-	// calculate allocated resources on each queue
-	var allocatedResources = make(map[api.QueueID]*api.Resource, len(ssn.Queues))
-	for queueID := range ssn.Queues {
-		allocatedResources[queueID] = &api.Resource{}
-	}
+// BackedOff returns whether the job is backed off in the session, as it kept failing to be
+// scheduled: its tasks are not allocated, the rest of the session still accounts for it.
+func (ssn *Session) BackedOff(job *api.JobInfo) bool {
+	_, found := ssn.backedOff[job.UID]
+	return found
+}
+
+// recordScheduleAttempts backs off the jobs found unschedulable in the session and resets the
+// backoff of the others.
+func recordScheduleAttempts(ssn *Session) {
+	now := time.Now()
 	for _, job := range ssn.Jobs {
-		for _, runningTask := range job.TaskStatusIndex[api.Running] {
-			allocatedResources[job.Queue].Add(runningTask.Resreq)
+		// the backed off jobs were not attempted
+		if job.PodGroup == nil || ssn.BackedOff(job) {
+			continue
+		}
+		ssn.cache.RecordScheduleAttempt(job.UID, !unschedulableInSession(ssn, job.PodGroup.Status), now)
+	}
+}
+
+// queueAllocated returns the resources of the running tasks of the jobs per queue, and apart the
+// resources of their in-flight tasks, bound or being bound but not running yet.
+func queueAllocated(queues map[api.QueueID]*api.QueueInfo, jobs map[api.JobID]*api.JobInfo) (running, inflight map[api.QueueID]*api.Resource) {
+	running = make(map[api.QueueID]*api.Resource, len(queues))
+	inflight = make(map[api.QueueID]*api.Resource, len(queues))
+	for queueID := range queues {
+		running[queueID] = &api.Resource{}
+		inflight[queueID] = &api.Resource{}
+	}
+	for _, job := range jobs {
+		if _, found := queues[job.Queue]; !found {
+			continue
+		}
+		for _, task := range job.TaskStatusIndex[api.Running] {
+			running[job.Queue].Add(task.Resreq)
+		}
+		for _, status := range []api.TaskStatus{api.Binding, api.Bound} {
+			for _, task := range job.TaskStatusIndex[status] {
+				inflight[job.Queue].Add(task.Resreq)
+			}
 		}
 	}
+	return running, inflight
+}
+
+// updateQueueStatus updates allocated field in queue status on session close.
+func updateQueueStatus(ssn *Session) {
+	// jobs filtered out of the session still count for their queues
+	allocatedResources, inflightResources := queueAllocated(ssn.Queues, ssn.snapshotJobs)
+	for queueID, inflight := range inflightResources {
+		metrics.UpdateQueueInflight(ssn.Queues[queueID].Name, inflight.MilliCPU, inflight.Memory)
+		// the in-flight tasks count as allocated, or a queue could exceed its share in the window
+		// between the bind of its tasks and the start of their pods
+		allocatedResources[queueID].Add(inflight)
+	}
 
 	// update queue status
-	for queueID := range ssn.Queues {
+	for queueID, queue := range ssn.Queues {
 		// convert api.Resource to v1.ResourceList
 		var queueStatus = util.ConvertRes2ResList(allocatedResources[queueID]).DeepCopy()
-		if equality.Semantic.DeepEqual(ssn.Queues[queueID].Queue.Status.Allocated, queueStatus) {
+		latency := schedulingLatency(queue)
+		if equality.Semantic.DeepEqual(queue.Queue.Status.Allocated, queueStatus) && latency == queue.SchedulingLatency {
 			klog.V(5).Infof("Queue <%s> allocated resource keeps equal, no need to update queue status <%v>.",
-				queueID, ssn.Queues[queueID].Queue.Status.Allocated)
+				queueID, queue.Queue.Status.Allocated)
 			continue
 		}
 
-		ssn.Queues[queueID].Queue.Status.Allocated = queueStatus
+		// the queue object is shared with the cache, the status is written on a copy
+		updated := queue.Clone()
+		updated.Queue = queue.Queue.DeepCopy()
+		updated.Queue.Status.Allocated = queueStatus
+		updated.SchedulingLatency = latency
 
-		if err := ssn.cache.UpdateQueueStatus(ssn.Queues[queueID]); err != nil {
-			klog.Errorf("failed to update queue <%s> status: %s", ssn.Queues[queueID].Name, err.Error())
+		if err := ssn.cache.UpdateQueueStatus(updated); err != nil {
+			klog.Errorf("failed to update queue <%s> status: %s", queue.Name, err.Error())
 		}
 	}
 }
 
+// schedulingLatency returns the rolling scheduling latency of the queue to report in its status,
+// the reported one if it did not change.
+func schedulingLatency(queue *api.QueueInfo) *api.SchedulingLatency {
+	p50, p95, found := metrics.QueueSchedulingLatency(queue.Name)
+	if !found {
+		return queue.SchedulingLatency
+	}
+	latency := &api.SchedulingLatency{
+		P50: metav1.Duration{Duration: p50.Round(time.Second)},
+		P95: metav1.Duration{Duration: p95.Round(time.Second)},
+	}
+	if queue.SchedulingLatency != nil && *queue.SchedulingLatency == *latency {
+		return queue.SchedulingLatency
+	}
+	return latency
+}
+
 func closeSession(ssn *Session) {
+	recordScheduleAttempts(ssn)
+
 	ju := newJobUpdater(ssn)
 	ju.UpdateAll()
 
 	updateQueueStatus(ssn)
 
+	releaseSession(ssn)
+}
+
+// releaseSession drops the references of the session to the snapshot.
+func releaseSession(ssn *Session) {
 	ssn.Jobs = nil
+	ssn.snapshotJobs = nil
+	ssn.backedOff = nil
+	ssn.journal = nil
 	ssn.Nodes = nil
 	ssn.RevocableNodes = nil
+	ssn.MaintenanceWindows = nil
+	ssn.Reservations = nil
+	ssn.ResourceFlavors = nil
 	ssn.plugins = nil
 	ssn.eventHandlers = nil
 	ssn.jobOrderFns = nil
@@ -240,22 +369,26 @@ func closeSession(ssn *Session) {
 	ssn.clusterOrderFns = nil
 	ssn.NodeList = nil
 	ssn.TotalResource = nil
+	ssn.Totals = nil
 
 	klog.V(3).Infof("Close Session %v", ssn.UID)
 }
 
-func jobStatus(ssn *Session, jobInfo *api.JobInfo) scheduling.PodGroupStatus {
-	status := jobInfo.PodGroup.Status
-
-	unschedulable := false
+// unschedulableInSession returns whether the job was found unschedulable in the session.
+func unschedulableInSession(ssn *Session, status scheduling.PodGroupStatus) bool {
 	for _, c := range status.Conditions {
 		if c.Type == scheduling.PodGroupUnschedulableType &&
 			c.Status == v1.ConditionTrue &&
 			c.TransitionID == string(ssn.UID) {
-			unschedulable = true
-			break
+			return true
 		}
 	}
+	return false
+}
+
+func jobStatus(ssn *Session, jobInfo *api.JobInfo) scheduling.PodGroupStatus {
+	status := jobInfo.PodGroup.Status
+	unschedulable := unschedulableInSession(ssn, status)
 
 	// If running tasks && unschedulable, unknown phase
 	if len(jobInfo.TaskStatusIndex[api.Running]) != 0 && unschedulable {
@@ -268,8 +401,8 @@ func jobStatus(ssn *Session, jobInfo *api.JobInfo) scheduling.PodGroupStatus {
 			}
 		}
 
-		// If there're enough allocated resource, it's running
-		if int32(allocated) >= jobInfo.PodGroup.Spec.MinMember {
+		// If there're enough allocated resource, or a degraded best-effort gang job got any, it's running
+		if int32(allocated) >= jobInfo.PodGroup.Spec.MinMember || (allocated > 0 && jobInfo.IsGangDegraded()) {
 			status.Phase = scheduling.PodGroupRunning
 			// If all allocated tasks is succeeded, it's completed
 			if len(jobInfo.TaskStatusIndex[api.Succeeded]) == allocated {
@@ -359,6 +492,16 @@ func (ssn *Session) PredicateForPreemptAction(task *api.TaskInfo, node *api.Node
 	return nil
 }
 
+// Profile returns the scheduler profile of the session, "" for the top level configuration.
+func (ssn *Session) Profile() string {
+	return ssn.profile
+}
+
+// SnapshotJobs returns all jobs of the snapshot, including the ones filtered out of Jobs.
+func (ssn *Session) SnapshotJobs() map[api.JobID]*api.JobInfo {
+	return ssn.snapshotJobs
+}
+
 // Statement returns new statement object
 func (ssn *Session) Statement() *Statement {
 	return &Statement{
@@ -405,6 +548,7 @@ func (ssn *Session) Pipeline(task *api.TaskInfo, hostname string) error {
 			})
 		}
 	}
+	ssn.record(nil, Pipeline, task, hostname, "")
 
 	return nil
 }
@@ -479,6 +623,7 @@ func (ssn *Session) Allocate(task *api.TaskInfo, nodeInfo *api.NodeInfo) (err er
 	} else {
 		ssn.cache.RevertVolumes(task, podVolumes)
 	}
+	ssn.record(nil, Allocate, task, hostname, "")
 
 	return nil
 }
@@ -541,6 +686,7 @@ func (ssn *Session) Evict(reclaimee *api.TaskInfo, reason string) error {
 			})
 		}
 	}
+	ssn.record(nil, Evict, reclaimee, reclaimee.NodeName, reason)
 
 	return nil
 }
@@ -614,6 +760,69 @@ func (ssn Session) RecordPodGroupEvent(podGroup *api.PodGroup, eventType, reason
 	ssn.recorder.Eventf(pg, eventType, reason, msg)
 }
 
+// RecordQueueEvent records queue events
+func (ssn Session) RecordQueueEvent(queue *api.QueueInfo, eventType, reason, msg string) {
+	if queue == nil || queue.Queue == nil {
+		return
+	}
+
+	q := &vcv1beta1.Queue{}
+	if err := schedulingscheme.Scheme.Convert(queue.Queue, q, nil); err != nil {
+		klog.Errorf("Error while converting Queue to v1beta1.Queue with error: %v", err)
+		return
+	}
+	ssn.recorder.Eventf(q, eventType, reason, msg)
+}
+
+// maxDryRunVictims is the number of victims listed in the preemption dry-run reports of a job
+const maxDryRunVictims = 20
+
+// RecordPreemptionDryRun reports, by event and podgroup condition, the tasks the job would have
+// preempted had its preemption policy not been dry-run.
+func (ssn *Session) RecordPreemptionDryRun(job *api.JobInfo, action string, victims []*api.TaskInfo) {
+	if len(victims) == 0 {
+		return
+	}
+	// The victims are listed in a stable order, which the job's report is compared in.
+	victims = append([]*api.TaskInfo(nil), victims...)
+	sort.Slice(victims, func(i, j int) bool {
+		if victims[i].Namespace != victims[j].Namespace {
+			return victims[i].Namespace < victims[j].Namespace
+		}
+		return victims[i].Name < victims[j].Name
+	})
+	names := make([]string, 0, len(victims))
+	for i, victim := range victims {
+		if i == maxDryRunVictims {
+			names = append(names, fmt.Sprintf("and %d more", len(victims)-maxDryRunVictims))
+			break
+		}
+		names = append(names, fmt.Sprintf("%s/%s on %s", victim.Namespace, victim.Name, victim.NodeName))
+	}
+	msg := fmt.Sprintf("%s would evict %d tasks: %s", action, len(victims), strings.Join(names, ", "))
+	klog.V(3).Infof("Dry-run preemption of job <%s/%s>: %s", job.Namespace, job.Name, msg)
+
+	// The job is reported again only when the tasks it would evict change.
+	for _, c := range job.PodGroup.Status.Conditions {
+		if c.Type == api.PodGroupPreemptionDryRunType && c.Message == msg {
+			return
+		}
+	}
+
+	jc := &scheduling.PodGroupCondition{
+		Type:               api.PodGroupPreemptionDryRunType,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		TransitionID:       string(ssn.UID),
+		Reason:             api.PreemptionDryRunReason,
+		Message:            msg,
+	}
+	if err := ssn.UpdatePodGroupCondition(job, jc); err != nil {
+		klog.Errorf("Failed to update condition of job <%s/%s>: %v", job.Namespace, job.Name, err)
+	}
+	ssn.RecordPodGroupEvent(job.PodGroup, v1.EventTypeNormal, api.PreemptionDryRunReason, msg)
+}
+
 // String return nodes and jobs information in the session
 func (ssn Session) String() string {
 	msg := fmt.Sprintf("Session %v: \n", ssn.UID)
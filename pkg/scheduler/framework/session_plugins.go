@@ -17,6 +17,9 @@ limitations under the License.
 package framework
 
 import (
+	"sort"
+
+	"k8s.io/klog/v2"
 	k8sframework "k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"volcano.sh/apis/pkg/apis/scheduling"
@@ -155,6 +158,11 @@ func (ssn *Session) Reclaimable(reclaimer *api.TaskInfo, reclaimees []*api.TaskI
 	var victims []*api.TaskInfo
 	var init bool
 
+	reclaimees = evictableTasks(reclaimees)
+	if len(reclaimees) == 0 {
+		return nil
+	}
+
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if !isEnabled(plugin.EnabledReclaimable) {
@@ -205,6 +213,11 @@ func (ssn *Session) Preemptable(preemptor *api.TaskInfo, preemptees []*api.TaskI
 	var victims []*api.TaskInfo
 	var init bool
 
+	preemptees = evictableTasks(preemptees)
+	if len(preemptees) == 0 {
+		return nil
+	}
+
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if !isEnabled(plugin.EnabledPreemptable) {
@@ -252,6 +265,18 @@ func (ssn *Session) Preemptable(preemptor *api.TaskInfo, preemptees []*api.TaskI
 	return victims
 }
 
+// evictableTasks returns the tasks whose priority band may be evicted, the protected ones are
+// never offered to the plugins as victims.
+func evictableTasks(tasks []*api.TaskInfo) []*api.TaskInfo {
+	evictable := make([]*api.TaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		if !task.EvictionProtected {
+			evictable = append(evictable, task)
+		}
+	}
+	return evictable
+}
+
 // Overused invoke overused function of the plugins
 func (ssn *Session) Overused(queue *api.QueueInfo) bool {
 	for _, tier := range ssn.Tiers {
@@ -480,6 +505,9 @@ func (ssn *Session) TargetJob(jobs []*api.JobInfo) *api.JobInfo {
 func (ssn *Session) VictimTasks(tasks []*api.TaskInfo) map[*api.TaskInfo]bool {
 	// different filters may add the same task to victims, so use a map to remove duplicate tasks.
 	victimSet := make(map[*api.TaskInfo]bool)
+	// the plugins may pick their victims among all the tasks of the session, not only among the
+	// given ones, so the protected tasks are dropped from what they return
+	tasks = evictableTasks(tasks)
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if !isEnabled(plugin.EnabledVictim) {
@@ -491,7 +519,7 @@ func (ssn *Session) VictimTasks(tasks []*api.TaskInfo) map[*api.TaskInfo]bool {
 			}
 			for _, fn := range fns {
 				victimTasks := fn(tasks)
-				for _, victim := range victimTasks {
+				for _, victim := range evictableTasks(victimTasks) {
 					victimSet[victim] = true
 				}
 			}
@@ -521,6 +549,11 @@ func (ssn *Session) ReservedNodes() {
 
 // JobOrderFn invoke joborder function of the plugins
 func (ssn *Session) JobOrderFn(l, r interface{}) bool {
+	// the jobs boosted by an administrator go first until their boost expires, whatever the plugins say
+	if lb, rb := l.(*api.JobInfo).Boosted(), r.(*api.JobInfo).Boosted(); lb != rb {
+		return lb
+	}
+
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if !isEnabled(plugin.EnabledJobOrder) {
@@ -711,6 +744,25 @@ func (ssn *Session) NodeOrderFn(task *api.TaskInfo, node *api.NodeInfo) (float64
 
 // BatchNodeOrderFn invoke node order function of the plugins
 func (ssn *Session) BatchNodeOrderFn(task *api.TaskInfo, nodes []*api.NodeInfo) (map[string]float64, error) {
+	return ssn.batchNodeOrder(task, nodes, nil)
+}
+
+// BatchNodeOrderFnWithScores returns BatchNodeOrderFn passing to record the batch score given by
+// each plugin to the nodes.
+func (ssn *Session) BatchNodeOrderFnWithScores(record NodeScoresRecorder) api.BatchNodeOrderFn {
+	return func(task *api.TaskInfo, nodes []*api.NodeInfo) (map[string]float64, error) {
+		scores := map[string]map[string]float64{}
+		priorityScore, err := ssn.batchNodeOrder(task, nodes, scores)
+		if err == nil {
+			for node, nodeScores := range scores {
+				record(task, node, nodeScores)
+			}
+		}
+		return priorityScore, err
+	}
+}
+
+func (ssn *Session) batchNodeOrder(task *api.TaskInfo, nodes []*api.NodeInfo, scores map[string]map[string]float64) (map[string]float64, error) {
 	priorityScore := make(map[string]float64, len(nodes))
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
@@ -727,6 +779,7 @@ func (ssn *Session) BatchNodeOrderFn(task *api.TaskInfo, nodes []*api.NodeInfo)
 			}
 			for nodeName, score := range score {
 				priorityScore[nodeName] += score
+				addScore(scores, nodeName, plugin.Name, score)
 			}
 		}
 	}
@@ -739,6 +792,27 @@ func isEnabled(enabled *bool) bool {
 
 // NodeOrderMapFn invoke node order function of the plugins
 func (ssn *Session) NodeOrderMapFn(task *api.TaskInfo, node *api.NodeInfo) (map[string]float64, float64, error) {
+	return ssn.nodeOrderMap(task, node, nil)
+}
+
+// NodeScoresRecorder is passed the scores given by each plugin to a node for a task.
+type NodeScoresRecorder func(task *api.TaskInfo, node string, scores map[string]float64)
+
+// NodeOrderMapFnWithScores returns NodeOrderMapFn passing to record the score given by the node
+// order function of each plugin to the node. The scores of the map functions are only final once
+// reduced, they are recorded by NodeOrderReduceFnWithScores.
+func (ssn *Session) NodeOrderMapFnWithScores(record NodeScoresRecorder) api.NodeOrderMapFn {
+	return func(task *api.TaskInfo, node *api.NodeInfo) (map[string]float64, float64, error) {
+		scores := map[string]map[string]float64{}
+		nodeScoreMap, priorityScore, err := ssn.nodeOrderMap(task, node, scores)
+		if err == nil && len(scores) > 0 {
+			record(task, node.Name, scores[node.Name])
+		}
+		return nodeScoreMap, priorityScore, err
+	}
+}
+
+func (ssn *Session) nodeOrderMap(task *api.TaskInfo, node *api.NodeInfo, scores map[string]map[string]float64) (map[string]float64, float64, error) {
 	nodeScoreMap := map[string]float64{}
 	var priorityScore float64
 	for _, tier := range ssn.Tiers {
@@ -752,6 +826,7 @@ func (ssn *Session) NodeOrderMapFn(task *api.TaskInfo, node *api.NodeInfo) (map[
 					return nodeScoreMap, priorityScore, err
 				}
 				priorityScore += score
+				addScore(scores, node.Name, plugin.Name, score)
 			}
 			if pfn, found := ssn.nodeMapFns[plugin.Name]; found {
 				score, err := pfn(task, node)
@@ -767,6 +842,25 @@ func (ssn *Session) NodeOrderMapFn(task *api.TaskInfo, node *api.NodeInfo) (map[
 
 // NodeOrderReduceFn invoke node order function of the plugins
 func (ssn *Session) NodeOrderReduceFn(task *api.TaskInfo, pluginNodeScoreMap map[string]k8sframework.NodeScoreList) (map[string]float64, error) {
+	return ssn.nodeOrderReduce(task, pluginNodeScoreMap, nil)
+}
+
+// NodeOrderReduceFnWithScores returns NodeOrderReduceFn passing to record the score given by the
+// map function of each plugin to the nodes, once reduced.
+func (ssn *Session) NodeOrderReduceFnWithScores(record NodeScoresRecorder) api.NodeOrderReduceFn {
+	return func(task *api.TaskInfo, pluginNodeScoreMap map[string]k8sframework.NodeScoreList) (map[string]float64, error) {
+		scores := map[string]map[string]float64{}
+		nodeScoreMap, err := ssn.nodeOrderReduce(task, pluginNodeScoreMap, scores)
+		if err == nil {
+			for node, nodeScores := range scores {
+				record(task, node, nodeScores)
+			}
+		}
+		return nodeScoreMap, err
+	}
+}
+
+func (ssn *Session) nodeOrderReduce(task *api.TaskInfo, pluginNodeScoreMap map[string]k8sframework.NodeScoreList, scores map[string]map[string]float64) (map[string]float64, error) {
 	nodeScoreMap := map[string]float64{}
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
@@ -782,12 +876,24 @@ func (ssn *Session) NodeOrderReduceFn(task *api.TaskInfo, pluginNodeScoreMap map
 			}
 			for _, hp := range pluginNodeScoreMap[plugin.Name] {
 				nodeScoreMap[hp.Name] += float64(hp.Score)
+				addScore(scores, hp.Name, plugin.Name, float64(hp.Score))
 			}
 		}
 	}
 	return nodeScoreMap, nil
 }
 
+// addScore adds the score of the plugin to the scores of the node, if the scores are recorded.
+func addScore(scores map[string]map[string]float64, node, plugin string, score float64) {
+	if scores == nil {
+		return
+	}
+	if scores[node] == nil {
+		scores[node] = map[string]float64{}
+	}
+	scores[node][plugin] += score
+}
+
 // BuildVictimsPriorityQueue returns a priority queue with victims sorted by:
 // if victims has same job id, sorted by !ssn.TaskOrderFn
 // if victims has different job id, sorted by !ssn.JobOrderFn
@@ -812,3 +918,34 @@ func (ssn *Session) BuildVictimsPriorityQueue(victims []*api.TaskInfo) *util.Pri
 	}
 	return victimsQueue
 }
+
+// UpdateJobsQueue updates the jobs queue of the last session, or returns a new one if nil, with the
+// jobs of the session accepted by the filter, layered by QueueOrderFn then JobOrderFn. The jobs are
+// added by UID so that the order of the ties does not depend on the iteration of the jobs map.
+func (ssn *Session) UpdateJobsQueue(jobsQueue *util.JobsQueue, filter func(job *api.JobInfo) bool) *util.JobsQueue {
+	jobIDs := make([]string, 0, len(ssn.Jobs))
+	for jobID := range ssn.Jobs {
+		jobIDs = append(jobIDs, string(jobID))
+	}
+	sort.Strings(jobIDs)
+
+	jobs := make([]*api.JobInfo, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		job := ssn.Jobs[api.JobID(jobID)]
+		if _, found := ssn.Queues[job.Queue]; !found {
+			klog.Warningf("Skip adding Job <%s/%s> because its queue %s is not found",
+				job.Namespace, job.Name, job.Queue)
+			continue
+		}
+		if filter != nil && !filter(job) {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	if jobsQueue == nil {
+		jobsQueue = util.NewJobsQueue(ssn.QueueOrderFn, ssn.JobOrderFn)
+	}
+	jobsQueue.Update(ssn.QueueOrderFn, ssn.JobOrderFn, ssn.Queues, jobs)
+	return jobsQueue
+}
@@ -18,14 +18,17 @@ package framework
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	k8sframework "k8s.io/kubernetes/pkg/scheduler/framework"
 
 	schedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
 	"volcano.sh/volcano/pkg/scheduler/util"
 )
 
@@ -136,3 +139,61 @@ func TestFilterOutPreemptMayNotHelpNodes(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeOrderFnsWithScores(t *testing.T) {
+	enabled := true
+	ssn := &Session{
+		Tiers: []conf.Tier{{Plugins: []conf.PluginOption{
+			{Name: "order", EnabledNodeOrder: &enabled},
+			{Name: "mapreduce", EnabledNodeOrder: &enabled},
+			{Name: "batch", EnabledNodeOrder: &enabled},
+		}}},
+		nodeOrderFns: map[string]api.NodeOrderFn{
+			"order": func(*api.TaskInfo, *api.NodeInfo) (float64, error) { return 1, nil },
+		},
+		nodeMapFns: map[string]api.NodeMapFn{
+			"mapreduce": func(_ *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+				if node.Name == "n1" {
+					return 10, nil
+				}
+				return 5, nil
+			},
+		},
+		nodeReduceFns: map[string]api.NodeReduceFn{
+			"mapreduce": func(_ *api.TaskInfo, scores k8sframework.NodeScoreList) error {
+				for i := range scores {
+					scores[i].Score *= 2
+				}
+				return nil
+			},
+		},
+		batchNodeOrderFns: map[string]api.BatchNodeOrderFn{
+			"batch": func(*api.TaskInfo, []*api.NodeInfo) (map[string]float64, error) {
+				return map[string]float64{"n1": 3}, nil
+			},
+		},
+	}
+
+	var mutex sync.Mutex
+	recorded := map[string]map[string]float64{}
+	record := func(_ *api.TaskInfo, node string, scores map[string]float64) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if recorded[node] == nil {
+			recorded[node] = map[string]float64{}
+		}
+		for plugin, score := range scores {
+			recorded[node][plugin] += score
+		}
+	}
+	nodes := []*api.NodeInfo{{Name: "n1"}, {Name: "n2"}}
+	nodeScores := util.PrioritizeNodes(&api.TaskInfo{Name: "p1"}, nodes,
+		ssn.BatchNodeOrderFnWithScores(record), ssn.NodeOrderMapFnWithScores(record), ssn.NodeOrderReduceFnWithScores(record))
+
+	// the recorded scores of each node add up to its total score
+	assert.Equal(t, map[string]map[string]float64{
+		"n1": {"order": 1, "mapreduce": 20, "batch": 3},
+		"n2": {"order": 1, "mapreduce": 10},
+	}, recorded)
+	assert.Equal(t, map[float64][]*api.NodeInfo{24: {nodes[0]}, 11: {nodes[1]}}, nodeScores)
+}
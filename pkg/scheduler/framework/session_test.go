@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestQueueAllocated(t *testing.T) {
+	buildQueue := func(name string) *api.QueueInfo {
+		return api.NewQueueInfo(&scheduling.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       scheduling.QueueSpec{Weight: 1},
+		})
+	}
+	queues := map[api.QueueID]*api.QueueInfo{
+		"q1": buildQueue("q1"),
+		"q2": buildQueue("q2"),
+	}
+
+	job := api.NewJobInfo("c1/pg1")
+	job.Queue = "q1"
+	running := api.NewTaskInfo(util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", nil, nil))
+	bound := api.NewTaskInfo(util.BuildPod("c1", "p2", "n1", v1.PodPending, api.BuildResourceList("2", "1Gi"), "pg1", nil, nil))
+	binding := api.NewTaskInfo(util.BuildPod("c1", "p3", "", v1.PodPending, api.BuildResourceList("4", "1Gi"), "pg1", nil, nil))
+	binding.Status = api.Binding
+	pending := api.NewTaskInfo(util.BuildPod("c1", "p4", "", v1.PodPending, api.BuildResourceList("8", "1Gi"), "pg1", nil, nil))
+	for _, task := range []*api.TaskInfo{running, bound, binding, pending} {
+		job.AddTaskInfo(task)
+	}
+	orphan := api.NewJobInfo("c1/pg2")
+	orphan.Queue = "unknown"
+	orphan.AddTaskInfo(api.NewTaskInfo(util.BuildPod("c1", "p5", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg2", nil, nil)))
+
+	allocated, inflight := queueAllocated(queues, map[api.JobID]*api.JobInfo{job.UID: job, orphan.UID: orphan})
+	if allocated["q1"].MilliCPU != 1000 || inflight["q1"].MilliCPU != 6000 {
+		t.Errorf("expected 1 running and 6 in-flight cpus in q1, got %v and %v", allocated["q1"], inflight["q1"])
+	}
+	if !allocated["q2"].IsEmpty() || !inflight["q2"].IsEmpty() {
+		t.Errorf("expected nothing allocated in q2, got %v and %v", allocated["q2"], inflight["q2"])
+	}
+	if len(allocated) != 2 {
+		t.Errorf("expected the jobs of unknown queues ignored, got %v", allocated)
+	}
+}
+
+func TestRecordPreemptionDryRun(t *testing.T) {
+	job := api.NewJobInfo("c1/pg1")
+	job.SetPodGroup(&api.PodGroup{PodGroup: scheduling.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: "pg1"},
+	}})
+	recorder := record.NewFakeRecorder(10)
+	ssn := &Session{Jobs: map[api.JobID]*api.JobInfo{job.UID: job}, recorder: recorder}
+	victim := func(name, node string) *api.TaskInfo {
+		return api.NewTaskInfo(util.BuildPod("c1", name, node, v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg2", nil, nil))
+	}
+
+	ssn.RecordPreemptionDryRun(job, "reclaim", []*api.TaskInfo{victim("p1", "n1"), victim("p2", "n2")})
+	ssn.RecordPreemptionDryRun(job, "reclaim", []*api.TaskInfo{victim("p1", "n1"), victim("p2", "n2")})
+	ssn.RecordPreemptionDryRun(job, "reclaim", []*api.TaskInfo{victim("p1", "n1")})
+
+	expected := []string{
+		"Normal PreemptionDryRun reclaim would evict 2 tasks: c1/p1 on n1, c1/p2 on n2",
+		"Normal PreemptionDryRun reclaim would evict 1 tasks: c1/p1 on n1",
+	}
+	for _, e := range expected {
+		select {
+		case event := <-recorder.Events:
+			if event != e {
+				t.Errorf("expected event %q, got %q", e, event)
+			}
+		default:
+			t.Fatalf("expected event %q, got none", e)
+		}
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("expected the unchanged victims not reported again, got %q", event)
+	default:
+	}
+
+	conditions := job.PodGroup.Status.Conditions
+	if len(conditions) != 1 || conditions[0].Type != api.PodGroupPreemptionDryRunType ||
+		conditions[0].Message != "reclaim would evict 1 tasks: c1/p1 on n1" {
+		t.Errorf("expected the last victims in the %s condition, got %v", api.PodGroupPreemptionDryRunType, conditions)
+	}
+}
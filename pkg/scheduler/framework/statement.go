@@ -37,15 +37,11 @@ const (
 	Allocate
 )
 
-type operation struct {
-	name   Operation
-	task   *api.TaskInfo
-	reason string
-}
-
-// Statement structure
+// Statement applies operations to the snapshot of the session, which are journaled with it until
+// they are committed to the cluster or rolled back.
 type Statement struct {
-	operations []operation
+	// operations are the entries of the session journal the statement applied, in order
+	operations []*JournalEntry
 	ssn        *Session
 }
 
@@ -87,11 +83,7 @@ func (s *Statement) Evict(reclaimee *api.TaskInfo, reason string) error {
 		}
 	}
 
-	s.operations = append(s.operations, operation{
-		name:   Evict,
-		task:   reclaimee,
-		reason: reason,
-	})
+	s.operations = append(s.operations, s.ssn.record(s, Evict, reclaimee, reclaimee.NodeName, reason))
 
 	return nil
 }
@@ -141,6 +133,17 @@ func (s *Statement) unevict(reclaimee *api.TaskInfo) error {
 	return nil
 }
 
+// Evictions returns the tasks evicted by the statement, which are not evicted until it is committed.
+func (s *Statement) Evictions() []*api.TaskInfo {
+	var tasks []*api.TaskInfo
+	for _, op := range s.operations {
+		if op.Operation == Evict {
+			tasks = append(tasks, op.Task)
+		}
+	}
+	return tasks
+}
+
 // Pipeline the task for the node
 func (s *Statement) Pipeline(task *api.TaskInfo, hostname string, evictionOccurred bool) error {
 	job, found := s.ssn.Jobs[task.Job]
@@ -177,10 +180,7 @@ func (s *Statement) Pipeline(task *api.TaskInfo, hostname string, evictionOccurr
 		}
 	}
 
-	s.operations = append(s.operations, operation{
-		name: Pipeline,
-		task: task,
-	})
+	s.operations = append(s.operations, s.ssn.record(s, Pipeline, task, hostname, ""))
 
 	return nil
 }
@@ -283,10 +283,7 @@ func (s *Statement) Allocate(task *api.TaskInfo, nodeInfo *api.NodeInfo) (err er
 
 	// Update status in session
 	klog.V(3).Info("Allocating operations ...")
-	s.operations = append(s.operations, operation{
-		name: Allocate,
-		task: task,
-	})
+	s.operations = append(s.operations, s.ssn.record(s, Allocate, task, hostname, ""))
 
 	return nil
 }
@@ -348,53 +345,90 @@ func (s *Statement) unallocate(task *api.TaskInfo) error {
 	return nil
 }
 
+// Checkpoint returns the point of the statement its operations can be rolled back to.
+func (s *Statement) Checkpoint() int {
+	return len(s.operations)
+}
+
+// Rollback discards the operations after the checkpoint and returns the number of evictions
+// discarded, the operations before it are kept.
+func (s *Statement) Rollback(checkpoint int) int {
+	if checkpoint < 0 || checkpoint >= len(s.operations) {
+		return 0
+	}
+	klog.V(3).Infof("Rolling back %d operations ...", len(s.operations)-checkpoint)
+	evictions := 0
+	for _, op := range s.operations[checkpoint:] {
+		if op.Operation == Evict {
+			evictions++
+		}
+	}
+	s.discard(checkpoint)
+	return evictions
+}
+
 // Discard operation for evict, pipeline and allocate
 func (s *Statement) Discard() {
 	klog.V(3).Info("Discarding operations ...")
-	for i := len(s.operations) - 1; i >= 0; i-- {
+	s.discard(0)
+}
+
+// discard rolls the operations after the checkpoint back in the snapshot and drops them from the
+// journal of the session.
+func (s *Statement) discard(checkpoint int) {
+	for i := len(s.operations) - 1; i >= checkpoint; i-- {
 		op := s.operations[i]
-		op.task.GenerateLastTxContext()
-		switch op.name {
+		op.Task.GenerateLastTxContext()
+		switch op.Operation {
 		case Evict:
-			err := s.unevict(op.task)
+			err := s.unevict(op.Task)
 			if err != nil {
 				klog.Errorf("Failed to unevict task: %s", err.Error())
 			}
 		case Pipeline:
-			err := s.UnPipeline(op.task)
+			err := s.UnPipeline(op.Task)
 			if err != nil {
 				klog.Errorf("Failed to unpipeline task: %s", err.Error())
 			}
 		case Allocate:
-			err := s.unallocate(op.task)
+			err := s.unallocate(op.Task)
 			if err != nil {
 				klog.Errorf("Failed to unallocate task: %s", err.Error())
 			}
 		}
 	}
+	s.ssn.forget(s.operations[checkpoint:])
+	s.operations = s.operations[:checkpoint]
 }
 
 // Commit operation for evict and pipeline
 func (s *Statement) Commit() {
 	klog.V(3).Info("Committing operations ...")
+	var failed []*JournalEntry
 	for _, op := range s.operations {
-		op.task.ClearLastTxContext()
-		switch op.name {
+		op.Task.ClearLastTxContext()
+		op.statement = nil
+		switch op.Operation {
 		case Evict:
-			err := s.evict(op.task, op.reason)
+			err := s.evict(op.Task, op.Reason)
 			if err != nil {
 				klog.Errorf("Failed to evict task: %s", err.Error())
+				failed = append(failed, op)
 			}
 		case Pipeline:
-			s.pipeline(op.task)
+			s.pipeline(op.Task)
 		case Allocate:
-			err := s.allocate(op.task)
+			err := s.allocate(op.Task)
 			if err != nil {
-				if e := s.unallocate(op.task); e != nil {
-					klog.Errorf("Failed to unallocate task <%v/%v>: %v.", op.task.Namespace, op.task.Name, e)
+				if e := s.unallocate(op.Task); e != nil {
+					klog.Errorf("Failed to unallocate task <%v/%v>: %v.", op.Task.Namespace, op.Task.Name, e)
 				}
-				klog.Errorf("Failed to allocate task <%v/%v>: %v.", op.task.Namespace, op.task.Name, err)
+				klog.Errorf("Failed to allocate task <%v/%v>: %v.", op.Task.Namespace, op.Task.Name, err)
+				failed = append(failed, op)
 			}
 		}
 	}
+	// the operations which failed were rolled back
+	s.ssn.forget(failed)
+	s.operations = nil
 }
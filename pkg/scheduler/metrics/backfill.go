@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto" // auto-registry collectors in default registry
+)
+
+var (
+	backfillTimeLimit = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "backfill_time_limit_seconds",
+			Help:      "Time backfill places tasks for in a session, 0 if unlimited",
+		},
+	)
+
+	backfillTasks = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "backfill_tasks_total",
+			Help:      "Number of tasks placed by backfill",
+		},
+	)
+
+	backfillDelayedJobs = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "backfill_delayed_jobs_total",
+			Help:      "Number of head-of-line jobs which failed to fit on nodes backfilled in the previous session",
+		},
+	)
+)
+
+// UpdateBackfillTimeLimit records the current backfill time limit
+func UpdateBackfillTimeLimit(limit time.Duration) {
+	backfillTimeLimit.Set(limit.Seconds())
+}
+
+// RegisterBackfillTasks records the tasks placed by backfill in a session
+func RegisterBackfillTasks(count int) {
+	backfillTasks.Add(float64(count))
+}
+
+// RegisterBackfillDelayedJobs records the head-of-line jobs delayed by backfill
+func RegisterBackfillDelayedJobs(count int) {
+	backfillDelayedJobs.Add(float64(count))
+}
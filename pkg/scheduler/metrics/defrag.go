@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto" // auto-registry collectors in default registry
+)
+
+var (
+	gpuIdle = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "defrag_idle_gpus",
+			Help:      "Idle gpus of one gpu type",
+		}, []string{"gpu_type"},
+	)
+
+	gpuLargestPod = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "defrag_largest_pod_gpus",
+			Help:      "Largest number of gpus of one gpu type a single pod can still get",
+		}, []string{"gpu_type"},
+	)
+
+	gpuIdleNodes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "defrag_idle_gpu_nodes",
+			Help:      "Nodes of one gpu type with all their gpus idle",
+		}, []string{"gpu_type"},
+	)
+
+	gpuFragmentation = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "defrag_gpu_fragmentation",
+			Help:      "Share of the idle gpus of one gpu type which are not on idle nodes",
+		}, []string{"gpu_type"},
+	)
+
+	nodeStrandedCapacity = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "defrag_node_stranded_capacity",
+			Help:      "Idle capacity of one node which can't be used because another resource of the node is exhausted",
+		}, []string{"node_name", "resource"},
+	)
+)
+
+// UpdateGPUFragmentation records the fragmentation of the gpus of one type
+func UpdateGPUFragmentation(gpuType string, idle, largestPod, idleNodes int, fragmentation float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
+	gpuIdle.WithLabelValues(gpuType).Set(float64(idle))
+	gpuLargestPod.WithLabelValues(gpuType).Set(float64(largestPod))
+	gpuIdleNodes.WithLabelValues(gpuType).Set(float64(idleNodes))
+	gpuFragmentation.WithLabelValues(gpuType).Set(fragmentation)
+}
+
+// ResetGPUFragmentation forgets the fragmentation of all the gpu types
+func ResetGPUFragmentation() {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
+	gpuIdle.Reset()
+	gpuLargestPod.Reset()
+	gpuIdleNodes.Reset()
+	gpuFragmentation.Reset()
+}
+
+// UpdateNodeStrandedCapacity records the stranded capacity of one resource of one node
+func UpdateNodeStrandedCapacity(nodeName, resource string, value float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
+	nodeStrandedCapacity.WithLabelValues(nodeName, resource).Set(value)
+}
+
+// ResetNodeStrandedCapacity forgets the stranded capacity of all the nodes
+func ResetNodeStrandedCapacity() {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
+	nodeStrandedCapacity.Reset()
+}
@@ -37,10 +37,37 @@ var (
 			Help:      "Number of retry counts for one job",
 		}, []string{"job_id"},
 	)
+
+	podGroupOwnerConflicts = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "podgroup_owner_conflicts_total",
+			Help:      "Number of podgroups found sharing their owner with an older podgroup",
+		},
+	)
+
+	duplicatePodGroups = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "duplicate_podgroups",
+			Help:      "Number of podgroups sharing their owner with an older podgroup",
+		},
+	)
+
+	podGroupStatusWritesPending = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "podgroup_status_writes_pending",
+			Help:      "Number of podgroup statuses waiting to be written to the API server",
+		},
+	)
 )
 
 // UpdateJobShare records share for one job
 func UpdateJobShare(jobNs, jobID string, share float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	jobShare.WithLabelValues(jobNs, jobID).Set(share)
 }
 
@@ -49,6 +76,21 @@ func RegisterJobRetries(jobID string) {
 	jobRetryCount.WithLabelValues(jobID).Inc()
 }
 
+// RegisterPodGroupOwnerConflict records a podgroup sharing its owner with an older podgroup
+func RegisterPodGroupOwnerConflict() {
+	podGroupOwnerConflicts.Inc()
+}
+
+// UpdateDuplicatePodGroups records the number of podgroups sharing their owner with an older podgroup
+func UpdateDuplicatePodGroups(count int) {
+	duplicatePodGroups.Set(float64(count))
+}
+
+// UpdatePodGroupStatusWritesPending records the number of podgroup statuses waiting to be written
+func UpdatePodGroupStatusWritesPending(count int) {
+	podGroupStatusWritesPending.Set(float64(count))
+}
+
 // DeleteJobMetrics delete all metrics related to the job
 func DeleteJobMetrics(jobName, queue, namespace string) {
 	e2eJobSchedulingDuration.DeleteLabelValues(jobName, queue, namespace)
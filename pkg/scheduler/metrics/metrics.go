@@ -17,6 +17,7 @@ limitations under the License.
 package metrics
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -146,8 +147,30 @@ var (
 			Help:      "Number of jobs could not be scheduled",
 		},
 	)
+
+	unexpectedTaskStatusTransitions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "unexpected_task_status_transitions_total",
+			Help:      "Number of the task status changes observed from the pods which the scheduler would not make itself",
+		}, []string{"from", "to"},
+	)
 )
 
+// sessionMetricsSuspended is set while a preview session is open, the queue, share and
+// fragmentation metrics are then left to the scheduling sessions, which are held off meanwhile.
+var sessionMetricsSuspended atomic.Bool
+
+// SuspendSessionMetrics stops the updates of the metrics of the sessions.
+func SuspendSessionMetrics() {
+	sessionMetricsSuspended.Store(true)
+}
+
+// ResumeSessionMetrics resumes the updates of the metrics of the sessions.
+func ResumeSessionMetrics() {
+	sessionMetricsSuspended.Store(false)
+}
+
 // UpdatePluginDuration updates latency for every plugin
 func UpdatePluginDuration(pluginName, onSessionStatus string, duration time.Duration) {
 	pluginSchedulingLatency.WithLabelValues(pluginName, onSessionStatus).Observe(DurationInMilliseconds(duration))
@@ -209,6 +232,11 @@ func UpdateUnscheduleJobCount(jobCount int) {
 	unscheduleJobCount.Set(float64(jobCount))
 }
 
+// RegisterUnexpectedTaskStatusTransition records a task status change the scheduler would not make itself
+func RegisterUnexpectedTaskStatusTransition(from, to string) {
+	unexpectedTaskStatusTransitions.WithLabelValues(from, to).Inc()
+}
+
 // DurationInMicroseconds gets the time in microseconds.
 func DurationInMicroseconds(duration time.Duration) float64 {
 	return float64(duration.Nanoseconds()) / float64(time.Microsecond.Nanoseconds())
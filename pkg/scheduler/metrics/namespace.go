@@ -49,15 +49,24 @@ var (
 
 // UpdateNamespaceShare records share for one namespace
 func UpdateNamespaceShare(namespaceName string, share float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	namespaceShare.WithLabelValues(namespaceName).Set(share)
 }
 
 // UpdateNamespaceWeight records weight for one namespace
 func UpdateNamespaceWeight(namespaceName string, weight int64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	namespaceWeight.WithLabelValues(namespaceName).Set(float64(weight))
 }
 
 // UpdateNamespaceWeightedShare records weighted share for one namespace
 func UpdateNamespaceWeightedShare(namespaceName string, weightedShare float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	namespaceWeightedShare.WithLabelValues(namespaceName).Set(weightedShare)
 }
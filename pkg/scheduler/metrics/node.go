@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto" // auto-registry collectors in default registry
+)
+
+var (
+	nodeFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "node_failures_total",
+			Help:      "Number of bind failures and kubelet admission rejections of the pods placed on one node",
+		}, []string{"node_name", "kind"},
+	)
+
+	nodeQuarantined = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "node_quarantined",
+			Help:      "Whether one node is quarantined for its repeated bind or admission failures",
+		}, []string{"node_name"},
+	)
+)
+
+// RegisterNodeFailure records a bind or admission failure of one node
+func RegisterNodeFailure(nodeName, kind string) {
+	nodeFailures.WithLabelValues(nodeName, kind).Inc()
+}
+
+// UpdateNodeQuarantined records whether one node is quarantined
+func UpdateNodeQuarantined(nodeName string, quarantined bool) {
+	if quarantined {
+		nodeQuarantined.WithLabelValues(nodeName).Set(1)
+		return
+	}
+	nodeQuarantined.DeleteLabelValues(nodeName)
+}
@@ -38,6 +38,22 @@ var (
 		}, []string{"queue_name"},
 	)
 
+	queueInflightMilliCPU = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_inflight_milli_cpu",
+			Help:      "CPU count of the tasks of one queue bound but not running yet",
+		}, []string{"queue_name"},
+	)
+
+	queueInflightMemory = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_inflight_memory_bytes",
+			Help:      "Memory of the tasks of one queue bound but not running yet",
+		}, []string{"queue_name"},
+	)
+
 	queueRequestMilliCPU = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: VolcanoNamespace,
@@ -78,6 +94,22 @@ var (
 		}, []string{"queue_name"},
 	)
 
+	queueFairnessDeviation = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_fairness_deviation",
+			Help:      "How far the allocated resources are from being shared by the queues with demand in proportion of their weights, between 0 and 1",
+		},
+	)
+
+	queueQuotaExceeded = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_quota_exceeded",
+			Help:      "If one queue whose quota is only monitored is allocated beyond its capability",
+		}, []string{"queue_name"},
+	)
+
 	queueWeight = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: VolcanoNamespace,
@@ -129,34 +161,69 @@ var (
 
 // UpdateQueueAllocated records allocated resources for one queue
 func UpdateQueueAllocated(queueName string, milliCPU, memory float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queueAllocatedMilliCPU.WithLabelValues(queueName).Set(milliCPU)
 	queueAllocatedMemory.WithLabelValues(queueName).Set(memory)
 }
 
+// UpdateQueueInflight records the resources of the tasks of one queue bound but not running yet
+func UpdateQueueInflight(queueName string, milliCPU, memory float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
+	queueInflightMilliCPU.WithLabelValues(queueName).Set(milliCPU)
+	queueInflightMemory.WithLabelValues(queueName).Set(memory)
+}
+
 // UpdateQueueRequest records request resources for one queue
 func UpdateQueueRequest(queueName string, milliCPU, memory float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queueRequestMilliCPU.WithLabelValues(queueName).Set(milliCPU)
 	queueRequestMemory.WithLabelValues(queueName).Set(memory)
 }
 
 // UpdateQueueDeserved records deserved resources for one queue
 func UpdateQueueDeserved(queueName string, milliCPU, memory float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queueDeservedMilliCPU.WithLabelValues(queueName).Set(milliCPU)
 	queueDeservedMemory.WithLabelValues(queueName).Set(memory)
 }
 
 // UpdateQueueShare records share for one queue
 func UpdateQueueShare(queueName string, share float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queueShare.WithLabelValues(queueName).Set(share)
 }
 
+// UpdateQueueFairnessDeviation records the fairness deviation of the queues
+func UpdateQueueFairnessDeviation(deviation float64) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
+	queueFairnessDeviation.Set(deviation)
+}
+
 // UpdateQueueWeight records weight for one queue
 func UpdateQueueWeight(queueName string, weight int32) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queueWeight.WithLabelValues(queueName).Set(float64(weight))
 }
 
 // UpdateQueueOverused records if one queue is overused
 func UpdateQueueOverused(queueName string, overused bool) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	var value float64
 	if overused {
 		value = 1
@@ -166,23 +233,47 @@ func UpdateQueueOverused(queueName string, overused bool) {
 	queueOverused.WithLabelValues(queueName).Set(value)
 }
 
+// UpdateQueueQuotaExceeded records if one monitored queue is allocated beyond its capability
+func UpdateQueueQuotaExceeded(queueName string, exceeded bool) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
+	var value float64
+	if exceeded {
+		value = 1
+	}
+	queueQuotaExceeded.WithLabelValues(queueName).Set(value)
+}
+
 // UpdateQueuePodGroupInqueueCount records the number of Inqueue PodGroup in this queue
 func UpdateQueuePodGroupInqueueCount(queueName string, count int32) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queuePodGroupInqueue.WithLabelValues(queueName).Set(float64(count))
 }
 
 // UpdateQueuePodGroupPendingCount records the number of Pending PodGroup in this queue
 func UpdateQueuePodGroupPendingCount(queueName string, count int32) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queuePodGroupPending.WithLabelValues(queueName).Set(float64(count))
 }
 
 // UpdateQueuePodGroupRunningCount records the number of Running PodGroup in this queue
 func UpdateQueuePodGroupRunningCount(queueName string, count int32) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queuePodGroupRunning.WithLabelValues(queueName).Set(float64(count))
 }
 
 // UpdateQueuePodGroupUnknownCount records the number of Unknown PodGroup in this queue
 func UpdateQueuePodGroupUnknownCount(queueName string, count int32) {
+	if sessionMetricsSuspended.Load() {
+		return
+	}
 	queuePodGroupUnknown.WithLabelValues(queueName).Set(float64(count))
 }
 
@@ -190,6 +281,8 @@ func UpdateQueuePodGroupUnknownCount(queueName string, count int32) {
 func DeleteQueueMetrics(queueName string) {
 	queueAllocatedMilliCPU.DeleteLabelValues(queueName)
 	queueAllocatedMemory.DeleteLabelValues(queueName)
+	queueInflightMilliCPU.DeleteLabelValues(queueName)
+	queueInflightMemory.DeleteLabelValues(queueName)
 	queueRequestMilliCPU.DeleteLabelValues(queueName)
 	queueRequestMemory.DeleteLabelValues(queueName)
 	queueDeservedMilliCPU.DeleteLabelValues(queueName)
@@ -197,8 +290,10 @@ func DeleteQueueMetrics(queueName string) {
 	queueShare.DeleteLabelValues(queueName)
 	queueWeight.DeleteLabelValues(queueName)
 	queueOverused.DeleteLabelValues(queueName)
+	queueQuotaExceeded.DeleteLabelValues(queueName)
 	queuePodGroupInqueue.DeleteLabelValues(queueName)
 	queuePodGroupPending.DeleteLabelValues(queueName)
 	queuePodGroupRunning.DeleteLabelValues(queueName)
 	queuePodGroupUnknown.DeleteLabelValues(queueName)
+	deleteQueueLatencyMetrics(queueName)
 }
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// latencyWindow is the number of the last jobs of a queue the rolling percentiles are computed on
+const latencyWindow = 200
+
+var (
+	queueSchedulingLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_scheduling_latency_seconds",
+			Help:      "Time from the enqueue of the jobs of one queue to their running, by job size",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{"queue_name", "job_size"},
+	)
+
+	queueSchedulingLatencyP50 = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_scheduling_latency_p50_seconds",
+			Help:      "Rolling median of the time from enqueue to running of the last jobs of one queue",
+		}, []string{"queue_name"},
+	)
+
+	queueSchedulingLatencyP95 = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_scheduling_latency_p95_seconds",
+			Help:      "Rolling 95th percentile of the time from enqueue to running of the last jobs of one queue",
+		}, []string{"queue_name"},
+	)
+
+	latencies = &latencySamples{samples: map[string][]time.Duration{}}
+)
+
+// latencySamples are the last scheduling latencies of the jobs of each queue.
+type latencySamples struct {
+	sync.Mutex
+	samples map[string][]time.Duration
+}
+
+// JobSizeBucket returns the bucket of the size of a job, in tasks, its latency is recorded in.
+func JobSizeBucket(size int32) string {
+	switch {
+	case size <= 1:
+		return "1"
+	case size <= 8:
+		return "2-8"
+	case size <= 64:
+		return "9-64"
+	default:
+		return "65+"
+	}
+}
+
+// RecordQueueSchedulingLatency records the time a job of the queue took from its enqueue to its running.
+func RecordQueueSchedulingLatency(queueName string, size int32, latency time.Duration) {
+	queueSchedulingLatency.WithLabelValues(queueName, JobSizeBucket(size)).Observe(latency.Seconds())
+
+	latencies.Lock()
+	samples := append(latencies.samples[queueName], latency)
+	if len(samples) > latencyWindow {
+		samples = samples[len(samples)-latencyWindow:]
+	}
+	latencies.samples[queueName] = samples
+	latencies.Unlock()
+
+	p50, p95, _ := QueueSchedulingLatency(queueName)
+	queueSchedulingLatencyP50.WithLabelValues(queueName).Set(p50.Seconds())
+	queueSchedulingLatencyP95.WithLabelValues(queueName).Set(p95.Seconds())
+}
+
+// QueueSchedulingLatency returns the rolling median and 95th percentile of the scheduling latency
+// of the last jobs of the queue, and whether any job of the queue was recorded.
+func QueueSchedulingLatency(queueName string) (p50, p95 time.Duration, found bool) {
+	latencies.Lock()
+	samples := append([]time.Duration(nil), latencies.samples[queueName]...)
+	latencies.Unlock()
+	if len(samples) == 0 {
+		return 0, 0, false
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return percentile(samples, 50), percentile(samples, 95), true
+}
+
+// percentile returns the nearest-rank percentile of the sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// deleteQueueLatencyMetrics deletes the scheduling latencies of the queue.
+func deleteQueueLatencyMetrics(queueName string) {
+	latencies.Lock()
+	delete(latencies.samples, queueName)
+	latencies.Unlock()
+	queueSchedulingLatency.DeletePartialMatch(prometheus.Labels{"queue_name": queueName})
+	queueSchedulingLatencyP50.DeleteLabelValues(queueName)
+	queueSchedulingLatencyP95.DeleteLabelValues(queueName)
+}
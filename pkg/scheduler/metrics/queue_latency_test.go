@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueSchedulingLatency(t *testing.T) {
+	defer DeleteQueueMetrics("q1")
+
+	if _, _, found := QueueSchedulingLatency("q1"); found {
+		t.Fatalf("expected no latency before any job ran")
+	}
+
+	// the oldest samples fall out of the window
+	for i := 0; i < latencyWindow; i++ {
+		RecordQueueSchedulingLatency("q1", 1, time.Hour)
+	}
+	for i := 1; i <= latencyWindow; i++ {
+		RecordQueueSchedulingLatency("q1", 4, time.Duration(i)*time.Second)
+	}
+
+	p50, p95, found := QueueSchedulingLatency("q1")
+	if !found {
+		t.Fatalf("expected the latency of q1")
+	}
+	if p50 != 100*time.Second || p95 != 190*time.Second {
+		t.Errorf("expected p50 100s and p95 190s, got %v and %v", p50, p95)
+	}
+
+	DeleteQueueMetrics("q1")
+	if _, _, found := QueueSchedulingLatency("q1"); found {
+		t.Errorf("expected the latency of q1 to be deleted with the queue")
+	}
+}
+
+func TestJobSizeBucket(t *testing.T) {
+	for size, expected := range map[int32]string{0: "1", 1: "1", 2: "2-8", 8: "2-8", 9: "9-64", 64: "9-64", 65: "65+"} {
+		if got := JobSizeBucket(size); got != expected {
+			t.Errorf("size %d: expected bucket %s, got %s", size, expected, got)
+		}
+	}
+}
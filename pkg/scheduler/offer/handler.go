@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+)
+
+const (
+	// Path is the HTTP path the offers of a queue are served on, the framework and queue
+	// are given as query parameters.
+	Path = "/api/v1/offers"
+	// ClaimPath is the HTTP path offers are claimed on.
+	ClaimPath = "/api/v1/offers/claim"
+)
+
+// NewHandler returns the HTTP handler serving offers from the manager. The user of the request
+// must be allowed to get the queue.
+func NewHandler(m *Manager, authorizer *apiauth.Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		framework, queue := r.URL.Query().Get("framework"), r.URL.Query().Get("queue")
+		if len(framework) == 0 || len(queue) == 0 {
+			http.Error(w, "framework and queue are required", http.StatusBadRequest)
+			return
+		}
+		user, err := authorizer.Authenticate(r)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		if err := authorizer.Authorize(r.Context(), user, queueAttributes(queue)); err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(m.Offers(framework, queue)); err != nil {
+			klog.Errorf("Failed to encode offers: %v", err)
+		}
+	})
+}
+
+// NewClaimHandler returns the HTTP handler accepting claims into the manager. The user of the
+// request must be allowed to get the queue of the offer and to update the pods of the tasks.
+func NewClaimHandler(m *Manager, authorizer *apiauth.Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		user, err := authorizer.Authenticate(r)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		claim := &Claim{}
+		if err := json.NewDecoder(r.Body).Decode(claim); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode claim: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(claim.Tasks) == 0 {
+			http.Error(w, "at least one task is required", http.StatusBadRequest)
+			return
+		}
+		o, found := m.Offer(claim.OfferID)
+		if !found {
+			http.Error(w, fmt.Sprintf("offer <%s> does not exist or has expired", claim.OfferID), http.StatusConflict)
+			return
+		}
+		if err := authorizer.Authorize(r.Context(), user, queueAttributes(o.Queue)); err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		namespaces := map[string]bool{}
+		for _, task := range claim.Tasks {
+			if namespaces[task.Namespace] {
+				continue
+			}
+			namespaces[task.Namespace] = true
+			if err := authorizer.Authorize(r.Context(), user, authorizationv1.ResourceAttributes{
+				Namespace: task.Namespace,
+				Verb:      "update",
+				Resource:  "pods",
+			}); err != nil {
+				apiauth.WriteError(w, err)
+				return
+			}
+		}
+		if err := m.Claim(claim); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+func queueAttributes(queue string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Group:    "scheduling.volcano.sh",
+		Verb:     "get",
+		Resource: "queues",
+		Name:     queue,
+	}
+}
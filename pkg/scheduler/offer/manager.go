@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultTTL is how long offers and placements stay valid by default.
+const DefaultTTL = 30 * time.Second
+
+// Manager keeps the offers published by the scheduler and the placements claimed by
+// the framework schedulers. It is shared by the HTTP handler and the offer plugin.
+type Manager struct {
+	mutex sync.Mutex
+	now   func() time.Time
+	ttl   time.Duration
+
+	subscribers map[Subscriber]time.Time
+	offers      map[string]*Offer
+	// placements is keyed by namespace/name of the claimed task
+	placements map[string]*Placement
+}
+
+// NewManager returns an empty manager.
+func NewManager() *Manager {
+	return &Manager{
+		now:         time.Now,
+		ttl:         DefaultTTL,
+		subscribers: map[Subscriber]time.Time{},
+		offers:      map[string]*Offer{},
+		placements:  map[string]*Placement{},
+	}
+}
+
+var defaultManager = NewManager()
+
+// Default returns the manager of the scheduler process.
+func Default() *Manager {
+	return defaultManager
+}
+
+// SetTTL sets how long offers and placements stay valid.
+func (m *Manager) SetTTL(ttl time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.ttl = ttl
+}
+
+// Offers subscribes the framework to the offers of the queue and returns the ones
+// currently offered to it.
+func (m *Manager) Offers(framework, queue string) []Offer {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.subscribers[Subscriber{Framework: framework, Queue: queue}] = now
+
+	var offers []Offer
+	for _, o := range m.offers {
+		if o.Framework == framework && o.Queue == queue && now.Before(o.Expires) {
+			offers = append(offers, *o)
+		}
+	}
+	sort.Slice(offers, func(i, j int) bool {
+		return offers[i].Node < offers[j].Node
+	})
+	return offers
+}
+
+// Subscribers returns the frameworks which asked for offers within the ttl, sorted.
+func (m *Manager) Subscribers() []Subscriber {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	var subscribers []Subscriber
+	for s, seen := range m.subscribers {
+		if now.Sub(seen) > m.ttl {
+			delete(m.subscribers, s)
+			continue
+		}
+		subscribers = append(subscribers, s)
+	}
+	sort.Slice(subscribers, func(i, j int) bool {
+		if subscribers[i].Queue != subscribers[j].Queue {
+			return subscribers[i].Queue < subscribers[j].Queue
+		}
+		return subscribers[i].Framework < subscribers[j].Framework
+	})
+	return subscribers
+}
+
+// Publish replaces the outstanding offers and renews their expiry. An offer published again
+// with the same framework, queue, node and resources keeps its ID, so that it can still be
+// claimed from an earlier listing.
+func (m *Manager) Publish(offers []Offer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	expires := m.now().Add(m.ttl)
+	m.offers = make(map[string]*Offer, len(offers))
+	for i := range offers {
+		o := offers[i]
+		o.ID = offerID(&o)
+		o.Expires = expires
+		m.offers[o.ID] = &o
+	}
+}
+
+// Offer returns the outstanding offer of the ID, if any.
+func (m *Manager) Offer(id string) (Offer, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	o, found := m.offers[id]
+	if !found || !m.now().Before(o.Expires) {
+		return Offer{}, false
+	}
+	return *o, true
+}
+
+// Claim accepts the placement of the tasks onto the node of the offer. An offer can be
+// claimed only once, by the framework it was offered to.
+func (m *Manager) Claim(claim *Claim) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	o, found := m.offers[claim.OfferID]
+	if !found || !m.now().Before(o.Expires) {
+		return fmt.Errorf("offer <%s> does not exist or has expired", claim.OfferID)
+	}
+	if o.Framework != claim.Framework {
+		return fmt.Errorf("offer <%s> was not offered to framework <%s>", claim.OfferID, claim.Framework)
+	}
+	delete(m.offers, o.ID)
+
+	expires := m.now().Add(m.ttl)
+	for _, task := range claim.Tasks {
+		m.placements[taskKey(task.Namespace, task.Name)] = &Placement{
+			Framework: o.Framework,
+			Queue:     o.Queue,
+			Node:      o.Node,
+			Expires:   expires,
+		}
+	}
+	return nil
+}
+
+// Placement returns the claimed placement of the task, if any.
+func (m *Manager) Placement(namespace, name string) (*Placement, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	p, found := m.placements[taskKey(namespace, name)]
+	if !found {
+		return nil, false
+	}
+	if !m.now().Before(p.Expires) {
+		delete(m.placements, taskKey(namespace, name))
+		return nil, false
+	}
+	return p, true
+}
+
+// Release forgets the placement of the task, once it is allocated or gone.
+func (m *Manager) Release(namespace, name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.placements, taskKey(namespace, name))
+}
+
+// Placements returns the keys of all claimed tasks.
+func (m *Manager) Placements() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := make([]string, 0, len(m.placements))
+	for key := range m.placements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// offerID derives the ID of the offer from its node and a hash of its framework, queue, node
+// and resources.
+func offerID(o *Offer) string {
+	names := make([]string, 0, len(o.Resources))
+	for name := range o.Resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s/%s/%s", o.Framework, o.Queue, o.Node)
+	for _, name := range names {
+		quantity := o.Resources[v1.ResourceName(name)]
+		fmt.Fprintf(h, "/%s=%s", name, quantity.String())
+	}
+	return fmt.Sprintf("%s-%x", o.Node, h.Sum64())
+}
+
+func taskKey(namespace, name string) string {
+	return namespace + "/" + name
+}
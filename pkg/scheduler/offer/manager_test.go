@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offer
+
+import (
+	"testing"
+	"time"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+func TestManager(t *testing.T) {
+	now := time.Now()
+	m := NewManager()
+	m.now = func() time.Time { return now }
+
+	if offers := m.Offers("spark", "q1"); len(offers) != 0 {
+		t.Fatalf("expected no offers before publishing, got %v", offers)
+	}
+	subscribers := m.Subscribers()
+	if len(subscribers) != 1 || subscribers[0] != (Subscriber{Framework: "spark", Queue: "q1"}) {
+		t.Fatalf("expected spark to subscribe to q1, got %v", subscribers)
+	}
+
+	published := []Offer{
+		{Framework: "spark", Queue: "q1", Node: "n2", Resources: api.BuildResourceList("2", "4Gi")},
+		{Framework: "spark", Queue: "q1", Node: "n1", Resources: api.BuildResourceList("2", "4Gi")},
+		{Framework: "ray", Queue: "q2", Node: "n3", Resources: api.BuildResourceList("2", "4Gi")},
+	}
+	m.Publish(published)
+	offers := m.Offers("spark", "q1")
+	if len(offers) != 2 || offers[0].Node != "n1" || offers[1].Node != "n2" {
+		t.Fatalf("expected offers of n1 and n2, got %v", offers)
+	}
+	// the offers published again by the next session keep their IDs
+	m.Publish(published)
+	if republished := m.Offers("spark", "q1"); republished[0].ID != offers[0].ID || republished[1].ID != offers[1].ID {
+		t.Errorf("expected the IDs of the offers kept, got %v and %v", offers, republished)
+	}
+	published[1].Resources = api.BuildResourceList("1", "4Gi")
+	m.Publish(published)
+	if changed := m.Offers("spark", "q1"); changed[0].ID == offers[0].ID {
+		t.Errorf("expected a new ID for the offer of other resources, got %v", changed[0].ID)
+	}
+	published[1].Resources = api.BuildResourceList("2", "4Gi")
+	m.Publish(published)
+
+	if err := m.Claim(&Claim{OfferID: offers[0].ID, Framework: "ray", Tasks: []Task{{Namespace: "ns", Name: "p1"}}}); err == nil {
+		t.Errorf("expected claim of another framework to fail")
+	}
+	if err := m.Claim(&Claim{OfferID: offers[0].ID, Framework: "spark", Tasks: []Task{{Namespace: "ns", Name: "p1"}}}); err != nil {
+		t.Fatalf("failed to claim offer: %v", err)
+	}
+	if err := m.Claim(&Claim{OfferID: offers[0].ID, Framework: "spark", Tasks: []Task{{Namespace: "ns", Name: "p2"}}}); err == nil {
+		t.Errorf("expected an offer to be claimed only once")
+	}
+	if p, found := m.Placement("ns", "p1"); !found || p.Node != "n1" || p.Queue != "q1" {
+		t.Errorf("expected p1 to be placed on n1, got %v", p)
+	}
+
+	now = now.Add(2 * DefaultTTL)
+	if _, found := m.Placement("ns", "p1"); found {
+		t.Errorf("expected placement to expire")
+	}
+	if err := m.Claim(&Claim{OfferID: offers[1].ID, Framework: "spark", Tasks: []Task{{Namespace: "ns", Name: "p3"}}}); err == nil {
+		t.Errorf("expected expired offer to be rejected")
+	}
+	if subscribers := m.Subscribers(); len(subscribers) != 0 {
+		t.Errorf("expected subscription to expire, got %v", subscribers)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package offer publishes the idle resources of the queues as offers to the external framework
+// schedulers, e.g. of Spark or Ray, and accepts the placements they claim. The offers and the
+// claims are served as JSON over the HTTP endpoints of the scheduler, see Path and ClaimPath,
+// in place of a gRPC API: the scheduler serves no gRPC.
+package offer
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// FrameworkAnnotation is the pod annotation naming the framework scheduler which places the pod,
+// only pods of the claiming framework may be placed through its claims.
+const FrameworkAnnotation = "volcano.sh/framework"
+
+// Offer is a slice of the idle resources of one node, offered exclusively to the framework
+// scheduler of a queue until it expires.
+type Offer struct {
+	ID        string          `json:"id"`
+	Framework string          `json:"framework"`
+	Queue     string          `json:"queue"`
+	Node      string          `json:"node"`
+	Resources v1.ResourceList `json:"resources"`
+	Expires   time.Time       `json:"expires"`
+}
+
+// Task identifies a pod created by a framework scheduler.
+type Task struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Claim commits the placement of tasks onto the node of an offer, the tasks must have been
+// created already and be annotated with FrameworkAnnotation. The tasks are still
+// allocated by the scheduler, which keeps enforcing the queue fairness, so a claim may be
+// only partially honoured.
+type Claim struct {
+	OfferID   string `json:"offerID"`
+	Framework string `json:"framework"`
+	Tasks     []Task `json:"tasks"`
+}
+
+// Placement is an accepted claim of a task.
+type Placement struct {
+	Framework string
+	Queue     string
+	Node      string
+	Expires   time.Time
+}
+
+// Subscriber is a framework scheduler asking offers for a queue.
+type Subscriber struct {
+	Framework string
+	Queue     string
+}
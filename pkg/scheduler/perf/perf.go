@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package perf measures the scheduling throughput of a synthesized cluster, to track
+// performance regressions across releases.
+package perf
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"volcano.sh/volcano/pkg/scheduler"
+	"volcano.sh/volcano/pkg/scheduler/uthelper/harness"
+)
+
+// Config describes the synthesized cluster and the scheduler run against it.
+type Config struct {
+	Nodes       int
+	NodeCPU     string
+	NodeMemory  string
+	Jobs        int
+	TasksPerJob int
+	TaskCPU     string
+	TaskMemory  string
+	// Cycles is the number of scheduling cycles to run
+	Cycles int
+	// SchedulerConf is the scheduler configuration, the default one if empty
+	SchedulerConf string
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Cycles        int
+	ScheduledPods int
+	TotalPods     int
+	// PodsPerSecond is the number of scheduled pods per second spent in scheduling sessions
+	PodsPerSecond float64
+	P50, P90, P99 time.Duration
+	MaxLatency    time.Duration
+
+	// CompleteJobs is the number of jobs with all of their pods scheduled
+	CompleteJobs int
+	// BrokenGangs is the number of jobs with less pods than their minMember scheduled, but some
+	BrokenGangs int
+	// NodesUsed is the number of nodes running at least one pod
+	NodesUsed int
+	// CPUUtilization is the share of the cpu of the used nodes requested by their pods
+	CPUUtilization float64
+}
+
+// Validate checks the config.
+func (c *Config) Validate() error {
+	if c.Nodes <= 0 || c.Jobs <= 0 || c.TasksPerJob <= 0 || c.Cycles <= 0 {
+		return fmt.Errorf("nodes, jobs, tasks per job and cycles must be positive")
+	}
+	for _, q := range []string{c.NodeCPU, c.NodeMemory, c.TaskCPU, c.TaskMemory} {
+		if _, err := resource.ParseQuantity(q); err != nil {
+			return fmt.Errorf("invalid quantity %q: %v", q, err)
+		}
+	}
+	return nil
+}
+
+// Run synthesizes the cluster, runs the scheduling cycles against it and reports on them.
+func Run(c *Config) (*Report, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	schedulerConf := c.SchedulerConf
+	if schedulerConf == "" {
+		schedulerConf = scheduler.DefaultSchedulerConf
+	}
+	actions, tiers, configs, _, err := scheduler.UnmarshalSchedulerConf(schedulerConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scheduler conf: %v", err)
+	}
+
+	fc := harness.NewFakeCluster(c.clusterSpec(), nil, tiers, configs)
+	defer fc.Close()
+
+	latencies := make([]time.Duration, 0, c.Cycles)
+	for i := 0; i < c.Cycles; i++ {
+		latencies = append(latencies, fc.RunCycle(actions))
+	}
+	return c.report(latencies, fc.Placements()), nil
+}
+
+func (c *Config) clusterSpec() *harness.ClusterSpec {
+	spec := &harness.ClusterSpec{
+		Queues: []harness.QueueSpec{{Name: "default"}},
+	}
+	for i := 0; i < c.Nodes; i++ {
+		spec.Nodes = append(spec.Nodes, harness.NodeSpec{
+			Name:   fmt.Sprintf("node-%d", i),
+			CPU:    c.NodeCPU,
+			Memory: c.NodeMemory,
+		})
+	}
+	for i := 0; i < c.Jobs; i++ {
+		spec.Jobs = append(spec.Jobs, harness.JobSpec{
+			Name:      fmt.Sprintf("job-%d", i),
+			MinMember: int32(c.TasksPerJob),
+			Tasks: []harness.TaskSpec{{
+				Name:     "worker",
+				Replicas: c.TasksPerJob,
+				CPU:      c.TaskCPU,
+				Memory:   c.TaskMemory,
+			}},
+		})
+	}
+	return spec
+}
+
+func (c *Config) report(latencies []time.Duration, placements map[string]string) *Report {
+	r := &Report{
+		Cycles:        len(latencies),
+		ScheduledPods: len(placements),
+		TotalPods:     c.Jobs * c.TasksPerJob,
+	}
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	if total > 0 {
+		r.PodsPerSecond = float64(r.ScheduledPods) / total.Seconds()
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	r.P50, r.P90, r.P99 = percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99)
+	r.MaxLatency = latencies[len(latencies)-1]
+
+	podsOfJob := map[string]int{}
+	podsOfNode := map[string]int{}
+	for pod, node := range placements {
+		// pods are named <job>-worker-<index>
+		podsOfJob[pod[:strings.LastIndex(pod, "-worker-")]]++
+		podsOfNode[node]++
+	}
+	for _, n := range podsOfJob {
+		if n == c.TasksPerJob {
+			r.CompleteJobs++
+		} else {
+			r.BrokenGangs++
+		}
+	}
+	r.NodesUsed = len(podsOfNode)
+	if r.NodesUsed > 0 {
+		nodeCPU, taskCPU := resource.MustParse(c.NodeCPU), resource.MustParse(c.TaskCPU)
+		r.CPUUtilization = float64(r.ScheduledPods) * float64(taskCPU.MilliValue()) /
+			(float64(r.NodesUsed) * float64(nodeCPU.MilliValue()))
+	}
+	return r
+}
+
+// percentile returns the p-th percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// Print writes the report in a human readable form.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "cycles:            %d\n", r.Cycles)
+	fmt.Fprintf(w, "scheduled pods:    %d/%d\n", r.ScheduledPods, r.TotalPods)
+	fmt.Fprintf(w, "pods/sec:          %.1f\n", r.PodsPerSecond)
+	fmt.Fprintf(w, "cycle latency:     p50 %v, p90 %v, p99 %v, max %v\n", r.P50, r.P90, r.P99, r.MaxLatency)
+	fmt.Fprintf(w, "complete jobs:     %d\n", r.CompleteJobs)
+	fmt.Fprintf(w, "broken gangs:      %d\n", r.BrokenGangs)
+	fmt.Fprintf(w, "nodes used:        %d\n", r.NodesUsed)
+	fmt.Fprintf(w, "cpu utilization:   %.1f%%\n", r.CPUUtilization*100)
+}
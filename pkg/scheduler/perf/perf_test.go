@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perf
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 10; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	for p, want := range map[int]time.Duration{50: 5 * time.Millisecond, 90: 9 * time.Millisecond, 99: 10 * time.Millisecond} {
+		if got := percentile(latencies, p); got != want {
+			t.Errorf("p%d: expected %v, got %v", p, want, got)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	c := &Config{Nodes: 3, NodeCPU: "4", NodeMemory: "8Gi", Jobs: 3, TasksPerJob: 2, TaskCPU: "1", TaskMemory: "1Gi", Cycles: 2}
+	placements := map[string]string{
+		"default/job-0-worker-0": "node-0",
+		"default/job-0-worker-1": "node-0",
+		"default/job-1-worker-0": "node-1",
+	}
+	r := c.report([]time.Duration{2 * time.Second, time.Second}, placements)
+
+	if r.ScheduledPods != 3 || r.TotalPods != 6 {
+		t.Errorf("expected 3/6 scheduled pods, got %d/%d", r.ScheduledPods, r.TotalPods)
+	}
+	if r.PodsPerSecond != 1 {
+		t.Errorf("expected 1 pod/sec, got %v", r.PodsPerSecond)
+	}
+	if r.CompleteJobs != 1 || r.BrokenGangs != 1 {
+		t.Errorf("expected 1 complete job and 1 broken gang, got %d and %d", r.CompleteJobs, r.BrokenGangs)
+	}
+	if r.NodesUsed != 2 || r.CPUUtilization != 3.0/8 {
+		t.Errorf("expected 2 nodes used at 37.5%%, got %d at %v", r.NodesUsed, r.CPUUtilization)
+	}
+	if r.MaxLatency != 2*time.Second {
+		t.Errorf("expected max latency of 2s, got %v", r.MaxLatency)
+	}
+}
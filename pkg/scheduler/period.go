@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// deepPendingTasks is the number of tasks left pending from which the period is shortened
+	deepPendingTasks = 100
+	// frequentEvents is the number of events between two cycles from which the period is shortened
+	frequentEvents = 50
+)
+
+// adaptivePeriod is the period between scheduling cycles: it is halved while many tasks are pending
+// or events are frequent, and doubled while the cluster is idle, within [minPeriod, maxPeriod].
+type adaptivePeriod struct {
+	minPeriod  time.Duration
+	maxPeriod  time.Duration
+	current    time.Duration
+	lastEvents uint64
+}
+
+func newAdaptivePeriod(minPeriod, maxPeriod time.Duration) *adaptivePeriod {
+	return &adaptivePeriod{minPeriod: minPeriod, maxPeriod: maxPeriod, current: minPeriod}
+}
+
+// next returns the period to wait after a cycle which left pendingTasks pending, events being
+// the number of events received by the cache so far.
+func (p *adaptivePeriod) next(pendingTasks int, events uint64) time.Duration {
+	newEvents := events - p.lastEvents
+	p.lastEvents = events
+
+	switch {
+	case pendingTasks >= deepPendingTasks || newEvents >= frequentEvents:
+		p.current /= 2
+	case pendingTasks == 0 && newEvents == 0:
+		p.current *= 2
+	}
+	if p.current < p.minPeriod {
+		p.current = p.minPeriod
+	}
+	if p.current > p.maxPeriod {
+		p.current = p.maxPeriod
+	}
+	return p.current
+}
+
+// burst returns whether the cache received frequent events since the last cycle, events being
+// the number of events received so far.
+func (p *adaptivePeriod) burst(events uint64) bool {
+	return events-p.lastEvents >= frequentEvents
+}
+
+// wait waits the period before the next cycle, checking the events every minPeriod to cut it
+// short on a burst of events. It returns false if stopCh is closed meanwhile.
+func (p *adaptivePeriod) wait(period time.Duration, eventCount func() uint64, stopCh <-chan struct{}) bool {
+	timer := time.NewTimer(period)
+	defer timer.Stop()
+	ticker := time.NewTicker(p.minPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return false
+		case <-timer.C:
+			return true
+		case <-ticker.C:
+			if p.burst(eventCount()) {
+				klog.V(4).Infof("Frequent events, start the next scheduling cycle early")
+				return true
+			}
+		}
+	}
+}
+
+// runAdaptively runs the scheduling cycles until stopCh is closed, waiting an adaptive period between them.
+func (pc *Scheduler) runAdaptively(stopCh <-chan struct{}) {
+	period := newAdaptivePeriod(pc.minSchedulePeriod, pc.maxSchedulePeriod)
+	for {
+		pending := pc.runOnce()
+		wait := period.next(pending, pc.cache.EventCount())
+		klog.V(4).Infof("%d tasks pending, next scheduling cycle in %v", pending, wait)
+
+		if !period.wait(wait, pc.cache.EventCount, stopCh) {
+			return
+		}
+	}
+}
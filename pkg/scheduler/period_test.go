@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptivePeriod(t *testing.T) {
+	period := newAdaptivePeriod(time.Second, 8*time.Second)
+
+	steps := []struct {
+		name     string
+		pending  int
+		events   uint64
+		expected time.Duration
+	}{
+		{name: "idle cluster", pending: 0, events: 0, expected: 2 * time.Second},
+		{name: "still idle", pending: 0, events: 0, expected: 4 * time.Second},
+		{name: "few pending tasks", pending: 3, events: 0, expected: 4 * time.Second},
+		{name: "idle up to the max", pending: 0, events: 0, expected: 8 * time.Second},
+		{name: "bounded by the max", pending: 0, events: 0, expected: 8 * time.Second},
+		{name: "frequent events", pending: 0, events: frequentEvents, expected: 4 * time.Second},
+		{name: "deep pending queue", pending: deepPendingTasks, events: frequentEvents, expected: 2 * time.Second},
+		{name: "bounded by the min", pending: deepPendingTasks, events: 3 * frequentEvents, expected: time.Second},
+		{name: "bounded by the min again", pending: deepPendingTasks, events: 3 * frequentEvents, expected: time.Second},
+	}
+	for _, step := range steps {
+		if got := period.next(step.pending, step.events); got != step.expected {
+			t.Errorf("%s: expected period %v, got %v", step.name, step.expected, got)
+		}
+	}
+}
+
+func TestAdaptivePeriodWait(t *testing.T) {
+	period := newAdaptivePeriod(10*time.Millisecond, time.Hour)
+	period.next(0, 100)
+
+	// a burst of events cuts the max period short
+	var events atomic.Uint64
+	events.Store(100)
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		events.Add(frequentEvents)
+	}()
+	done := make(chan bool)
+	go func() {
+		done <- period.wait(time.Hour, events.Load, make(chan struct{}))
+	}()
+	select {
+	case waited := <-done:
+		if !waited {
+			t.Errorf("expected the wait to end on the burst of events, not to be stopped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the wait to be cut short by the burst of events")
+	}
+
+	// a few events do not
+	stopCh := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(stopCh)
+	}()
+	if period.wait(time.Hour, func() uint64 { return 101 }, stopCh) {
+		t.Errorf("expected the wait to last until stopped")
+	}
+}
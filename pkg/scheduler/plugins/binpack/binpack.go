@@ -54,6 +54,9 @@ type priorityWeight struct {
 	BinPackingCPU       int
 	BinPackingMemory    int
 	BinPackingResources map[v1.ResourceName]int
+	// ResourceWeights is the resource weights of the scheduler configuration,
+	// they scale the weights above
+	ResourceWeights api.ResourceWeights
 }
 
 func (w *priorityWeight) String() string {
@@ -154,6 +157,14 @@ func calculateWeight(args framework.Arguments) priorityWeight {
 	weight.BinPackingResources[v1.ResourceCPU] = weight.BinPackingCPU
 	weight.BinPackingResources[v1.ResourceMemory] = weight.BinPackingMemory
 
+	// the resources valued by the scheduler configuration are packed too
+	weight.ResourceWeights = args.GetResourceWeights(framework.ResourceWeightsKey)
+	for resource := range weight.ResourceWeights {
+		if _, found := weight.BinPackingResources[resource]; !found {
+			weight.BinPackingResources[resource] = 1
+		}
+	}
+
 	return weight
 }
 
@@ -205,8 +216,8 @@ func (bp *binpackPlugin) OnSessionClose(ssn *framework.Session) {
 // - Reduce Fragmentation of scarce resources on the Cluster
 func BinPackingScore(task *api.TaskInfo, node *api.NodeInfo, weight priorityWeight) float64 {
 	score := 0.0
-	weightSum := 0
-	requested := task.Resreq
+	weightSum := 0.0
+	requested := task.ShareResreq()
 	allocatable := node.Allocatable
 	used := node.Used
 
@@ -222,6 +233,7 @@ func BinPackingScore(task *api.TaskInfo, node *api.NodeInfo, weight priorityWeig
 		if !found {
 			continue
 		}
+		factor := weight.ResourceWeights.Get(resource)
 
 		resourceScore, err := ResourceBinPackingScore(request, allocate, nodeUsed, resourceWeight)
 		if err != nil {
@@ -229,16 +241,16 @@ func BinPackingScore(task *api.TaskInfo, node *api.NodeInfo, weight priorityWeig
 				task.Namespace, task.Name, node.Name, resource, err.Error(), request, nodeUsed, allocate)
 			return 0
 		}
-		klog.V(5).Infof("task %s/%s on node %s resource %s, need %f, used %f, allocatable %f, weight %d, score %f",
-			task.Namespace, task.Name, node.Name, resource, request, nodeUsed, allocate, resourceWeight, resourceScore)
+		klog.V(5).Infof("task %s/%s on node %s resource %s, need %f, used %f, allocatable %f, weight %d x %f, score %f",
+			task.Namespace, task.Name, node.Name, resource, request, nodeUsed, allocate, resourceWeight, factor, resourceScore)
 
-		score += resourceScore
-		weightSum += resourceWeight
+		score += resourceScore * factor
+		weightSum += float64(resourceWeight) * factor
 	}
 
 	// mapping the result from [0, weightSum] to [0, 10(MaxPriority)]
 	if weightSum > 0 {
-		score /= float64(weightSum)
+		score /= weightSum
 	}
 	score *= float64(k8sFramework.MaxNodeScore * int64(weight.BinPackingWeight))
 
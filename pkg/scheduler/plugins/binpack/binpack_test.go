@@ -203,6 +203,41 @@ func TestNode(t *testing.T) {
 				},
 			},
 		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:      "resource weights of the scheduler configuration",
+				Plugins:   map[string]framework.PluginBuilder{PluginName: New},
+				PodGroups: []*schedulingv1.PodGroup{pg1},
+				Queues:    []*schedulingv1.Queue{queue1},
+				Pods:      []*v1.Pod{p1, p2, p3, p4},
+				Nodes:     []*v1.Node{n1, n2, n3},
+			},
+			arguments: framework.Arguments{
+				framework.ResourceWeightsKey: map[string]float64{"nvidia.com/gpu": 4, "memory": 0.5},
+			},
+			expected: map[string]map[string]float64{
+				"c1/p1": {
+					"n1": 83.333333333,
+					"n2": 18.75,
+					"n3": 0,
+				},
+				"c1/p2": {
+					"n1": 0,
+					"n2": 37.5,
+					"n3": 0,
+				},
+				"c1/p3": {
+					"n1": 0,
+					"n2": 51.136363636,
+					"n3": 0,
+				},
+				"c1/p4": {
+					"n1": 0,
+					"n2": 58.333333333,
+					"n3": 0, // required 3c, but node only has 2c
+				},
+			},
+		},
 	}
 
 	trueValue := true
@@ -18,6 +18,7 @@ package capacity
 
 import (
 	"math"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
@@ -88,11 +89,14 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 		cp.totalGuarantee.Add(guarantee)
 	}
 	klog.V(4).Infof("The total guarantee resource is <%v>", cp.totalGuarantee)
+	now := time.Now()
 	// Build attributes for Queues.
 	for _, job := range ssn.Jobs {
 		klog.V(4).Infof("Considering Job <%s/%s>.", job.Namespace, job.Name)
 		if _, found := cp.queueOpts[job.Queue]; !found {
 			queue := ssn.Queues[job.Queue]
+			// the active window of the quota schedule overrides the capability of the queue
+			_, capability := queue.QuotaAt(now)
 			attr := &queueAttr{
 				queueID: queue.UID,
 				name:    queue.Name,
@@ -104,8 +108,8 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 				inqueue:   api.EmptyResource(),
 				guarantee: api.EmptyResource(),
 			}
-			if len(queue.Queue.Spec.Capability) != 0 {
-				attr.capability = api.NewResource(queue.Queue.Spec.Capability)
+			if len(capability) != 0 {
+				attr.capability = api.NewResource(capability)
 				if attr.capability.MilliCPU <= 0 {
 					attr.capability.MilliCPU = math.MaxFloat64
 				}
@@ -123,6 +127,10 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 				realCapability.MinDimensionResource(attr.capability, api.Infinity)
 				attr.realCapability = realCapability
 			}
+			if bound := queue.IsolationBound(); bound != nil {
+				attr.realCapability.MinDimensionResource(bound, api.Infinity)
+				klog.V(4).Infof("Queue <%s> is strictly isolated within <%v>", queue.Name, attr.realCapability)
+			}
 			cp.queueOpts[job.Queue] = attr
 			klog.V(4).Infof("Added Queue <%s> attributes.", job.Queue)
 		}
@@ -131,12 +139,12 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 		for status, tasks := range job.TaskStatusIndex {
 			if api.AllocatedStatus(status) {
 				for _, t := range tasks {
-					attr.allocated.Add(t.Resreq)
-					attr.request.Add(t.Resreq)
+					attr.allocated.Add(t.ShareResreq())
+					attr.request.Add(t.ShareResreq())
 				}
 			} else if status == api.Pending {
 				for _, t := range tasks {
-					attr.request.Add(t.Resreq)
+					attr.request.Add(t.ShareResreq())
 				}
 			}
 		}
@@ -233,19 +241,19 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 				allocations[job.Queue] = attr.allocated.Clone()
 			}
 			allocated := allocations[job.Queue]
-			if allocated.LessPartly(reclaimer.Resreq, api.Zero) {
+			if allocated.LessPartly(reclaimer.ShareResreq(), api.Zero) {
 				klog.V(3).Infof("Failed to allocate resource for Task <%s/%s> in Queue <%s>, not enough resource.",
 					reclaimee.Namespace, reclaimee.Name, job.Queue)
 				continue
 			}
 
-			exceptReclaimee := allocated.Clone().Sub(reclaimee.Resreq)
+			exceptReclaimee := allocated.Clone().Sub(reclaimee.ShareResreq())
 			// When scalar resource not specified in deserved such as "pods", we should skip it and consider it as infinity,
 			// so the following first condition will be true and the current queue will not be reclaimed.
 			if allocated.LessEqual(attr.deserved, api.Infinity) || !attr.guarantee.LessEqual(exceptReclaimee, api.Zero) {
 				continue
 			}
-			allocated.Sub(reclaimee.Resreq)
+			allocated.Sub(reclaimee.ShareResreq())
 			victims = append(victims, reclaimee)
 		}
 		klog.V(4).InfoS("Victims from capacity plugin", "victims", victims, "reclaimer", reclaimer)
@@ -257,8 +265,8 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 		task := candidate.(*api.TaskInfo)
 		attr := cp.queueOpts[queue.UID]
 
-		futureUsed := attr.allocated.Clone().Add(task.Resreq)
-		overused := !futureUsed.LessEqualWithDimension(attr.deserved, task.Resreq)
+		futureUsed := attr.allocated.Clone().Add(task.ShareResreq())
+		overused := !futureUsed.LessEqualWithDimension(attr.deserved, task.ShareResreq())
 		metrics.UpdateQueueOverused(attr.name, overused)
 		if overused {
 			klog.V(3).Infof("Queue <%v> can not reclaim, deserved <%v>, allocated <%v>, share <%v>",
@@ -273,11 +281,11 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 	ssn.AddAllocatableFn(cp.Name(), func(queue *api.QueueInfo, candidate *api.TaskInfo) bool {
 		attr := cp.queueOpts[queue.UID]
 
-		futureUsed := attr.allocated.Clone().Add(candidate.Resreq)
-		allocatable := futureUsed.LessEqualWithDimension(attr.realCapability, candidate.Resreq)
+		futureUsed := attr.allocated.Clone().Add(candidate.ShareResreq())
+		allocatable := futureUsed.LessEqualWithDimension(attr.realCapability, candidate.ShareResreq())
 		if !allocatable {
 			klog.V(3).Infof("Queue <%v>: realCapability <%v>, allocated <%v>; Candidate <%v>: resource request <%v>",
-				queue.Name, attr.realCapability, attr.allocated, candidate.Name, candidate.Resreq)
+				queue.Name, attr.realCapability, attr.allocated, candidate.Name, candidate.ShareResreq())
 		}
 
 		return allocatable
@@ -321,24 +329,24 @@ func (cp *capacityPlugin) OnSessionOpen(ssn *framework.Session) {
 		AllocateFunc: func(event *framework.Event) {
 			job := ssn.Jobs[event.Task.Job]
 			attr := cp.queueOpts[job.Queue]
-			attr.allocated.Add(event.Task.Resreq)
+			attr.allocated.Add(event.Task.ShareResreq())
 			metrics.UpdateQueueAllocated(attr.name, attr.allocated.MilliCPU, attr.allocated.Memory)
 
 			cp.updateShare(attr)
 
 			klog.V(4).Infof("Capacity AllocateFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
+				event.Task.Namespace, event.Task.Name, event.Task.ShareResreq(), attr.share)
 		},
 		DeallocateFunc: func(event *framework.Event) {
 			job := ssn.Jobs[event.Task.Job]
 			attr := cp.queueOpts[job.Queue]
-			attr.allocated.Sub(event.Task.Resreq)
+			attr.allocated.Sub(event.Task.ShareResreq())
 			metrics.UpdateQueueAllocated(attr.name, attr.allocated.MilliCPU, attr.allocated.Memory)
 
 			cp.updateShare(attr)
 
 			klog.V(4).Infof("Capacity EvictFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
+				event.Task.Namespace, event.Task.Name, event.Task.ShareResreq(), attr.share)
 		},
 	})
 }
@@ -136,6 +136,29 @@ func Test_capacityPlugin_OnSessionOpen(t *testing.T) {
 	queue10 := util.BuildQueueWithResourcesQuantity("q10", api.BuildResourceList("2", "2Gi"), api.BuildResourceList("4", "4Gi"))
 	queue11 := util.BuildQueueWithResourcesQuantity("q11", api.BuildResourceList("0", "0Gi"), api.BuildResourceList("2", "2Gi"))
 
+	// case6: the capability of queue12 is lowered all day by its quota schedule
+	p19 := util.BuildPod("ns1", "p19", "n1", corev1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg19", make(map[string]string), make(map[string]string))
+	p20 := util.BuildPod("ns1", "p20", "", corev1.PodPending, api.BuildResourceList("1", "1Gi"), "pg20", make(map[string]string), make(map[string]string))
+	// podgroup
+	pg19 := util.BuildPodGroup("pg19", "ns1", "q12", 1, nil, schedulingv1beta1.PodGroupRunning)
+	pg20 := util.BuildPodGroup("pg20", "ns1", "q12", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	// queue
+	queue12 := util.BuildQueueWithResourcesQuantity("q12", nil, api.BuildResourceList("2", "2Gi"))
+	queue12.Annotations = map[string]string{
+		api.QuotaScheduleAnnotationKey: `[{"start":"00:00","end":"00:00","capability":{"cpu":"1","memory":"1Gi"}}]`,
+	}
+
+	// case7: the strict queue13 is not allocated beyond its deserved resource though the cluster is idle
+	n7 := util.BuildNode("n7", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string))
+	p21 := util.BuildPod("ns1", "p21", "", corev1.PodPending, api.BuildResourceList("1", "1Gi"), "pg21", make(map[string]string), make(map[string]string))
+	p22 := util.BuildPod("ns1", "p22", "", corev1.PodPending, api.BuildResourceList("2", "1Gi"), "pg22", make(map[string]string), make(map[string]string))
+	// podgroup
+	pg21 := util.BuildPodGroup("pg21", "ns1", "q13", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	pg22 := util.BuildPodGroup("pg22", "ns1", "q13", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	// queue
+	queue13 := util.BuildQueueWithResourcesQuantity("q13", api.BuildResourceList("1", "2Gi"), nil)
+	queue13.Annotations = map[string]string{api.QueueIsolation: api.QueueIsolationStrict}
+
 	tests := []uthelper.TestCommonStruct{
 		{
 			Name:      "case0: Pod allocatable when queue has not exceed capability",
@@ -208,6 +231,27 @@ func Test_capacityPlugin_OnSessionOpen(t *testing.T) {
 			ExpectEvicted:   []string{},
 			ExpectEvictNum:  0,
 		},
+		{
+			Name:           "case6: Pod not allocatable when queue exceed the capability of its active quota window",
+			Plugins:        plugins,
+			Pods:           []*corev1.Pod{p19, p20},
+			Nodes:          []*corev1.Node{n1, n2},
+			PodGroups:      []*schedulingv1beta1.PodGroup{pg19, pg20},
+			Queues:         []*schedulingv1beta1.Queue{queue12},
+			ExpectBindsNum: 0,
+		},
+		{
+			Name:      "case7: Pod not allocatable beyond the deserved resource of a strict queue",
+			Plugins:   plugins,
+			Pods:      []*corev1.Pod{p21, p22},
+			Nodes:     []*corev1.Node{n7},
+			PodGroups: []*schedulingv1beta1.PodGroup{pg21, pg22},
+			Queues:    []*schedulingv1beta1.Queue{queue13},
+			ExpectBindMap: map[string]string{
+				"ns1/p21": "n7",
+			},
+			ExpectBindsNum: 1,
+		},
 	}
 
 	tiers := []conf.Tier{
@@ -40,12 +40,27 @@ const (
 	PluginName = "cdp"
 )
 
+// protectionPeriodKey is the argument of the cooldown time of the pods which have none of their own
+const protectionPeriodKey = "cdp.protectionPeriod"
+
 type CooldownProtectionPlugin struct {
+	// protectionPeriod is the cooldown time of the pods without the cooldown time label or annotation,
+	// 0 if they are not protected
+	protectionPeriod time.Duration
 }
 
 // New return CooldownProtectionPlugin
 func New(arguments framework.Arguments) framework.Plugin {
-	return &CooldownProtectionPlugin{}
+	sp := &CooldownProtectionPlugin{}
+	if value, ok := arguments[protectionPeriodKey].(string); ok {
+		period, err := time.ParseDuration(value)
+		if err != nil || period < 0 {
+			klog.Warningf("invalid time duration %s=%s", protectionPeriodKey, value)
+		} else {
+			sp.protectionPeriod = period
+		}
+	}
+	return sp
 }
 
 // Name implements framework.Plugin
@@ -70,34 +85,55 @@ func (sp *CooldownProtectionPlugin) podCooldownTime(pod *v1.Pod) (value time.Dur
 	return vi, true
 }
 
+// runningSince returns when the pod started running: the earliest start of its running
+// containers, or the start of the pod if one of them restarted, so that a crash looping
+// container is not protected again on each restart. It falls back to the scheduling of the pod
+// if the states of its containers are not reported yet.
+func runningSince(pod *v1.Pod) (time.Time, bool) {
+	if pod.Status.Phase != v1.PodRunning {
+		return time.Time{}, false
+	}
+	var since time.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.RestartCount > 0 && pod.Status.StartTime != nil {
+			return pod.Status.StartTime.Time, true
+		}
+		if running := status.State.Running; running != nil && (since.IsZero() || running.StartedAt.Time.Before(since)) {
+			since = running.StartedAt.Time
+		}
+	}
+	if !since.IsZero() {
+		return since, true
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodScheduled && c.Status == v1.ConditionTrue {
+			return c.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // OnSessionOpen implements framework.Plugin
 func (sp *CooldownProtectionPlugin) OnSessionOpen(ssn *framework.Session) {
+	now := time.Now()
 	preemptableFn := func(preemptor *api.TaskInfo, preemptees []*api.TaskInfo) ([]*api.TaskInfo, int) {
 		var victims []*api.TaskInfo
 		for _, preemptee := range preemptees {
 			cooldownTime, enabled := sp.podCooldownTime(preemptee.Pod)
+			if !enabled {
+				cooldownTime, enabled = sp.protectionPeriod, sp.protectionPeriod > 0
+			}
 			if !enabled {
 				victims = append(victims, preemptee)
 				continue
 			}
-			pod := preemptee.Pod
-			// find the time of pod really transform to running
-			// only running pod check stable time, others all put into victims
-			stableFiltered := false
-			if pod.Status.Phase == v1.PodRunning {
-				// ensure pod is running and have ready state
-				for _, c := range pod.Status.Conditions {
-					if c.Type == v1.PodScheduled && c.Status == v1.ConditionTrue {
-						if c.LastTransitionTime.Add(cooldownTime).After(time.Now()) {
-							stableFiltered = true
-						}
-						break
-					}
-				}
-			}
-			if !stableFiltered {
-				victims = append(victims, preemptee)
+			// only the running pods are protected, others all put into victims
+			if since, running := runningSince(preemptee.Pod); running && since.Add(cooldownTime).After(now) {
+				klog.V(4).Infof("Task <%s/%s> started running at %v, protected for %v",
+					preemptee.Namespace, preemptee.Name, since, cooldownTime)
+				continue
 			}
+			victims = append(victims, preemptee)
 		}
 
 		klog.V(4).Infof("Victims from cdp plugins are %+v", victims)
@@ -106,6 +142,7 @@ func (sp *CooldownProtectionPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	klog.V(4).Info("plugin cdp session open")
 	ssn.AddPreemptableFn(sp.Name(), preemptableFn)
+	ssn.AddReclaimableFn(sp.Name(), preemptableFn)
 }
 
 // OnSessionClose implements framework.Plugin
@@ -161,3 +161,47 @@ func TestPreemptableFn(t *testing.T) {
 		t.Errorf("stable preempt test not equal! expect victims %v, actual %v", expectVictims, victims)
 	}
 }
+
+func TestReclaimableFnWithProtectionPeriod(t *testing.T) {
+	plugin := New(framework.Arguments{protectionPeriodKey: "5m"})
+	enabledReclaimable := true
+	ssn := framework.OpenSession(&cache.SchedulerCache{}, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{{Name: PluginName, EnabledReclaimable: &enabledReclaimable}},
+		},
+	}, []conf.Configuration{{Name: "reclaim"}})
+	plugin.OnSessionOpen(ssn)
+
+	// task1: scheduled long ago, its container started a minute ago, protected by default
+	pod1 := makePod(map[string]string{}, map[string]string{}, time.Now().Add(-time.Hour))
+	pod1.Status.ContainerStatuses = []v1.ContainerStatus{
+		{State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now().Add(-time.Minute))}}},
+	}
+	task1 := api.NewTaskInfo(pod1)
+	// task2: running for longer than the protection period
+	pod2 := makePod(map[string]string{}, map[string]string{}, time.Now().Add(-time.Hour))
+	pod2.Status.ContainerStatuses = []v1.ContainerStatus{
+		{State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now().Add(-10 * time.Minute))}}},
+	}
+	task2 := api.NewTaskInfo(pod2)
+	// task3: its own cooldown time overrides the protection period
+	task3 := api.NewTaskInfo(makePod(map[string]string{},
+		map[string]string{v1beta1.CooldownTime: "1h"},
+		time.Now().Add(-10*time.Minute)))
+	// task4: not running yet
+	task4 := api.NewTaskInfo(makePod(map[string]string{}, map[string]string{}, time.Time{}))
+	// task5: started long ago, its crash looping container restarted a minute ago
+	pod5 := makePod(map[string]string{}, map[string]string{}, time.Now().Add(-time.Hour))
+	pod5.Status.StartTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	pod5.Status.ContainerStatuses = []v1.ContainerStatus{
+		{RestartCount: 3, State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now().Add(-time.Minute))}}},
+	}
+	task5 := api.NewTaskInfo(pod5)
+
+	victims := ssn.Reclaimable(&api.TaskInfo{}, []*api.TaskInfo{task1, task2, task3, task4, task5})
+
+	expectVictims := []*api.TaskInfo{task2, task4, task5}
+	if !equality.Semantic.DeepEqual(victims, expectVictims) {
+		t.Errorf("protection period reclaim test not equal! expect victims %v, actual %v", expectVictims, victims)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datalocality
+
+import (
+	"encoding/json"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	k8sFramework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "datalocality"
+
+	// DatasetsAnnotation lists the datasets a pod reads and where they are cached, on the pod or on
+	// its PodGroup, e.g.
+	//   [{"name": "imagenet", "size": "100Gi", "nodes": ["node-1"], "zones": ["zone-a"]}]
+	DatasetsAnnotation = "volcano.sh/dataset-locations"
+
+	weightArgument     = "datalocality.weight"
+	zoneWeightArgument = "datalocality.zoneWeight"
+)
+
+// dataset is where the data read by a pod is cached, e.g. by Fluid or Alluxio.
+type dataset struct {
+	Name string `json:"name"`
+	// Size weights the dataset against the others the pod reads, 1 if not given
+	Size  string   `json:"size,omitempty"`
+	Nodes []string `json:"nodes,omitempty"`
+	Zones []string `json:"zones,omitempty"`
+
+	size float64
+}
+
+type dataLocalityPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	weight int
+	// zoneWeight is the percentage of the score of a node given by a dataset cached in its zone
+	// rather than on the node itself
+	zoneWeight int
+
+	// datasets caches the parsed annotations, nodes are scored in parallel
+	sync.Mutex
+	datasets map[string][]*dataset
+}
+
+/*
+User should give the weights in this format:
+
+	actions: "enqueue, allocate, backfill"
+	tiers:
+	- plugins:
+	  - name: datalocality
+	    arguments:
+	      datalocality.weight: 1
+	      datalocality.zoneWeight: 50
+*/
+
+// New return data locality plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	dp := &dataLocalityPlugin{
+		pluginArguments: arguments,
+		weight:          1,
+		zoneWeight:      50,
+	}
+	arguments.GetInt(&dp.weight, weightArgument)
+	arguments.GetInt(&dp.zoneWeight, zoneWeightArgument)
+	return dp
+}
+
+func (dp *dataLocalityPlugin) Name() string {
+	return PluginName
+}
+
+func parseDatasets(value string) ([]*dataset, error) {
+	var datasets []*dataset
+	if err := json.Unmarshal([]byte(value), &datasets); err != nil {
+		return nil, err
+	}
+	for _, d := range datasets {
+		d.size = 1
+		if len(d.Size) == 0 {
+			continue
+		}
+		size, err := resource.ParseQuantity(d.Size)
+		if err != nil {
+			return nil, err
+		}
+		d.size = size.AsApproximateFloat64()
+	}
+	return datasets, nil
+}
+
+// taskDatasets returns the datasets of the task, given on the pod or on its PodGroup.
+func (dp *dataLocalityPlugin) taskDatasets(ssn *framework.Session, task *api.TaskInfo) []*dataset {
+	value := task.Pod.Annotations[DatasetsAnnotation]
+	if len(value) == 0 {
+		if job, found := ssn.Jobs[task.Job]; found && job.PodGroup != nil {
+			value = job.PodGroup.Annotations[DatasetsAnnotation]
+		}
+	}
+	if len(value) == 0 {
+		return nil
+	}
+
+	dp.Lock()
+	defer dp.Unlock()
+	if datasets, found := dp.datasets[value]; found {
+		return datasets
+	}
+	datasets, err := parseDatasets(value)
+	if err != nil {
+		klog.Errorf("Invalid %s <%s> of task <%s/%s>: %v", DatasetsAnnotation, value, task.Namespace, task.Name, err)
+	}
+	dp.datasets[value] = datasets
+	return datasets
+}
+
+// score is the share of the datasets, weighted by size, cached on the node or, at zoneWeight
+// percent, in its zone.
+func (dp *dataLocalityPlugin) score(datasets []*dataset, node *api.NodeInfo) float64 {
+	zone := ""
+	if node.Node != nil {
+		zone = node.Node.Labels[v1.LabelTopologyZone]
+	}
+
+	total, local := 0.0, 0.0
+	for _, d := range datasets {
+		total += d.size
+		if contains(d.Nodes, node.Name) {
+			local += d.size
+		} else if len(zone) != 0 && contains(d.Zones, zone) {
+			local += d.size * float64(dp.zoneWeight) / 100
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return local / total * float64(k8sFramework.MaxNodeScore) * float64(dp.weight)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (dp *dataLocalityPlugin) OnSessionOpen(ssn *framework.Session) {
+	dp.datasets = map[string][]*dataset{}
+
+	ssn.AddNodeOrderFn(dp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		datasets := dp.taskDatasets(ssn, task)
+		if len(datasets) == 0 {
+			return 0, nil
+		}
+		score := dp.score(datasets, node)
+		klog.V(4).Infof("Data locality score of task <%s/%s> on node <%s> is %f", task.Namespace, task.Name, node.Name, score)
+		return score, nil
+	})
+}
+
+func (dp *dataLocalityPlugin) OnSessionClose(ssn *framework.Session) {
+	dp.datasets = nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datalocality
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestScore(t *testing.T) {
+	dp := New(framework.Arguments{}).(*dataLocalityPlugin)
+	datasets, err := parseDatasets(`[
+		{"name": "imagenet", "size": "300Gi", "nodes": ["n1"], "zones": ["zone-a"]},
+		{"name": "labels", "size": "100Gi", "nodes": ["n2"]}
+	]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buildNode := func(name, zone string) *api.NodeInfo {
+		return api.NewNodeInfo(util.BuildNode(name, api.BuildResourceList("4", "8Gi"), map[string]string{v1.LabelTopologyZone: zone}))
+	}
+	tests := []struct {
+		node     *api.NodeInfo
+		expected float64
+	}{
+		{node: buildNode("n1", "zone-a"), expected: 75},
+		{node: buildNode("n2", "zone-b"), expected: 25},
+		{node: buildNode("n3", "zone-a"), expected: 37.5},
+		{node: buildNode("n4", "zone-b"), expected: 0},
+	}
+	for _, test := range tests {
+		if got := dp.score(datasets, test.node); got != test.expected {
+			t.Errorf("expected score %v on node %s, got %v", test.expected, test.node.Name, got)
+		}
+	}
+
+	if _, err := parseDatasets(`[{"name": "imagenet", "size": "a lot"}]`); err == nil {
+		t.Errorf("expected an error for an invalid size")
+	}
+}
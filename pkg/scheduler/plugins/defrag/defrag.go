@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defrag
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/defrag"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "defrag"
+
+	// intervalArgument is how often the fragmentation is analyzed, 5m by default.
+	intervalArgument = "defrag.interval"
+	// gpuTypeLabelArgument is the node label holding the gpu type.
+	gpuTypeLabelArgument = "defrag.gpuTypeLabel"
+	// migrateArgument makes the suggested migrations executed by evicting the tasks through the
+	// shuffle action, they are only reported otherwise.
+	migrateArgument = "defrag.migrate"
+	// maxMigrationsArgument is the maximum number of tasks migrated at each analysis.
+	maxMigrationsArgument = "defrag.maxMigrations"
+
+	defaultInterval      = 5 * time.Minute
+	defaultGPUTypeLabel  = "nvidia.com/gpu.product"
+	defaultMaxMigrations = 1
+)
+
+type defragPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	interval      time.Duration
+	gpuTypeLabel  string
+	migrate       bool
+	maxMigrations int
+
+	// lastAnalysis is when the fragmentation was last analyzed
+	lastAnalysis time.Time
+	// pins are the nodes the migrated tasks are placed on again, by the namespace/name of the
+	// tasks, which the pods recreated by their jobs keep
+	pins map[string]pin
+}
+
+// pin restricts a migrated task to the node it is migrated to until it expires.
+type pin struct {
+	node    string
+	expires time.Time
+}
+
+// New return defrag plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	dp := &defragPlugin{
+		pluginArguments: arguments,
+		interval:        defaultInterval,
+		gpuTypeLabel:    defaultGPUTypeLabel,
+		maxMigrations:   defaultMaxMigrations,
+		pins:            map[string]pin{},
+	}
+	if value, ok := arguments[intervalArgument].(string); ok {
+		if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+			dp.interval = interval
+		} else {
+			klog.Warningf("Invalid %s <%s> of plugin %s, use %v", intervalArgument, value, PluginName, defaultInterval)
+		}
+	}
+	if value, ok := arguments[gpuTypeLabelArgument].(string); ok && value != "" {
+		dp.gpuTypeLabel = value
+	}
+	arguments.GetBool(&dp.migrate, migrateArgument)
+	arguments.GetInt(&dp.maxMigrations, maxMigrationsArgument)
+	return dp
+}
+
+func (dp *defragPlugin) Name() string {
+	return PluginName
+}
+
+// Stateful keeps the time of the last analysis and the pins of the migrated tasks across the sessions.
+func (dp *defragPlugin) Stateful() {}
+
+func taskKey(task *api.TaskInfo) string {
+	return fmt.Sprintf("%s/%s", task.Namespace, task.Name)
+}
+
+func (dp *defragPlugin) OnSessionOpen(ssn *framework.Session) {
+	now := time.Now()
+	dp.releasePins(ssn, now)
+	if !now.Before(dp.lastAnalysis.Add(dp.interval)) {
+		dp.lastAnalysis = now
+		dp.analyze(ssn, now)
+	}
+	if len(dp.pins) == 0 {
+		return
+	}
+
+	// the migrated tasks are only placed again on the node they are migrated to
+	ssn.AddPredicateFn(dp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if pin, found := dp.pins[taskKey(task)]; found && pin.node != node.Name {
+			return api.NewFitErrWithStatus(task, node, &api.Status{
+				Code:   api.UnschedulableAndUnresolvable,
+				Reason: fmt.Sprintf("task is migrated to node %s by defragmentation", pin.node),
+				Plugin: PluginName,
+			})
+		}
+		return nil
+	})
+}
+
+// releasePins drops the pins expired, of the tasks placed on their node, or of the nodes gone.
+func (dp *defragPlugin) releasePins(ssn *framework.Session, now time.Time) {
+	for key, pin := range dp.pins {
+		if _, found := ssn.Nodes[pin.node]; !found || !now.Before(pin.expires) {
+			delete(dp.pins, key)
+		}
+	}
+	if len(dp.pins) == 0 {
+		return
+	}
+	for _, job := range ssn.Jobs {
+		for _, task := range job.Tasks {
+			key := taskKey(task)
+			if pin, found := dp.pins[key]; found && task.NodeName == pin.node && api.AllocatedStatus(task.Status) {
+				delete(dp.pins, key)
+			}
+		}
+	}
+}
+
+func (dp *defragPlugin) analyze(ssn *framework.Session, now time.Time) {
+	report := defrag.Analyze(ssn.Nodes, dp.gpuTypeLabel, dp.maxMigrations)
+	defrag.SetLatest(report)
+	// the gpu types and nodes gone since the last analysis are not reported any more
+	metrics.ResetGPUFragmentation()
+	for _, stats := range report.GPUTypes {
+		metrics.UpdateGPUFragmentation(stats.Type, stats.IdleGPUs, stats.LargestPodGPUs, stats.IdleNodes, stats.Fragmentation)
+	}
+	metrics.ResetNodeStrandedCapacity()
+	for _, node := range report.Nodes {
+		metrics.UpdateNodeStrandedCapacity(node.Name, "cpu", node.MilliCPU)
+		metrics.UpdateNodeStrandedCapacity(node.Name, "memory", node.Memory)
+		metrics.UpdateNodeStrandedCapacity(node.Name, api.GPUResourceName, float64(node.GPUs))
+	}
+	for _, migration := range report.Migrations {
+		klog.V(3).Infof("Defragmentation suggests to move task <%s> with %d gpus from node <%s> to <%s>",
+			migration.Task, migration.GPUs, migration.From, migration.To)
+	}
+
+	if !dp.migrate || len(report.Migrations) == 0 {
+		return
+	}
+	victims := map[api.TaskID]bool{}
+	for _, migration := range report.Migrations {
+		victims[migration.UID] = true
+		dp.pins[migration.Task] = pin{node: migration.To, expires: now.Add(dp.interval)}
+	}
+	// the evicted tasks are placed again by the scheduler, on the node they are pinned to
+	ssn.AddVictimTasksFns(dp.Name(), []api.VictimTasksFn{func(tasks []*api.TaskInfo) []*api.TaskInfo {
+		var selected []*api.TaskInfo
+		for _, task := range tasks {
+			if victims[task.UID] {
+				selected = append(selected, task)
+			}
+		}
+		return selected
+	}})
+}
+
+func (dp *defragPlugin) OnSessionClose(ssn *framework.Session) {}
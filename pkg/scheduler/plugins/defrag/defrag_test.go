@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defrag
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestPins(t *testing.T) {
+	now := time.Now()
+	dp := New(framework.Arguments{}).(*defragPlugin)
+	// the fragmentation was just analyzed
+	dp.lastAnalysis = now
+	dp.pins = map[string]pin{
+		"c1/p1": {node: "n2", expires: now.Add(time.Hour)},
+		"c1/p2": {node: "n2", expires: now.Add(time.Hour)},
+		"c1/p3": {node: "n2", expires: now.Add(-time.Minute)},
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{{Plugins: []conf.PluginOption{{Name: PluginName, EnabledPredicate: &trueValue}}}}
+	tc := uthelper.TestCommonStruct{
+		Name: "pins",
+		PodGroups: []*schedulingv1.PodGroup{
+			util.BuildPodGroup("pg1", "c1", "q1", 1, nil, schedulingv1.PodGroupInqueue),
+		},
+		Pods: []*v1.Pod{
+			util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", nil, nil),
+			util.BuildPod("c1", "p2", "n2", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", nil, nil),
+			util.BuildPod("c1", "p3", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", nil, nil),
+		},
+		Nodes: []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("4", "8Gi"), nil),
+			util.BuildNode("n2", api.BuildResourceList("4", "8Gi"), nil),
+		},
+		Queues:  []*schedulingv1.Queue{util.BuildQueue("q1", 1, nil)},
+		Plugins: map[string]framework.PluginBuilder{PluginName: func(framework.Arguments) framework.Plugin { return dp }},
+	}
+	ssn := tc.RegisterSession(tiers, nil)
+	defer tc.Close()
+
+	if _, found := dp.pins["c1/p1"]; !found || len(dp.pins) != 1 {
+		t.Errorf("expected only the pin of the pending task left, got %v", dp.pins)
+	}
+	for _, task := range ssn.Jobs["c1/pg1"].Tasks {
+		for _, node := range []string{"n1", "n2"} {
+			err := ssn.PredicateFn(task, ssn.Nodes[node])
+			if expected := task.Name != "p1" || node == "n2"; (err == nil) != expected {
+				t.Errorf("task %s on node %s expect fit %v, but get err %v", task.Name, node, expected, err)
+			}
+		}
+	}
+}
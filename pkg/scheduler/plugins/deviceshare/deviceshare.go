@@ -27,7 +27,9 @@ import (
 
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/api/devices"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nic"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/timeslice"
 	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/vgpu"
 	"volcano.sh/volcano/pkg/scheduler/framework"
 )
@@ -41,6 +43,10 @@ const (
 	GPUNumberPredicate  = "deviceshare.GPUNumberEnable"
 
 	VGPUEnable = "deviceshare.VGPUEnable"
+	// NICEnable is the key for enabling the RDMA and SR-IOV NIC allocation aligned with the gpus
+	NICEnable = "deviceshare.NICEnable"
+	// TimeSlicingEnable is the key for enabling the packing of the time-sliced gpu slices on cards
+	TimeSlicingEnable = "deviceshare.TimeSlicingEnable"
 
 	SchedulePolicyArgument = "deviceshare.SchedulePolicy"
 	ScheduleWeight         = "deviceshare.ScheduleWeight"
@@ -72,6 +78,8 @@ func enablePredicate(dsp *deviceSharePlugin) {
 	args.GetBool(&gpushare.GpuNumberEnable, GPUNumberPredicate)
 	args.GetBool(&nodeLockEnable, NodeLockEnable)
 	args.GetBool(&vgpu.VGPUEnable, VGPUEnable)
+	args.GetBool(&nic.NICEnable, NICEnable)
+	args.GetBool(&timeslice.TimeSlicingEnable, TimeSlicingEnable)
 
 	gpushare.NodeLockEnable = nodeLockEnable
 	vgpu.NodeLockEnable = nodeLockEnable
@@ -80,6 +88,7 @@ func enablePredicate(dsp *deviceSharePlugin) {
 	if ok {
 		dsp.schedulePolicy = args[SchedulePolicyArgument].(string)
 	}
+	timeslice.SchedulePolicy = dsp.schedulePolicy
 	args.GetInt(&dsp.scheduleWeight, ScheduleWeight)
 
 	if gpushare.GpuSharingEnable && gpushare.GpuNumberEnable {
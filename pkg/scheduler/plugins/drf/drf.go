@@ -113,6 +113,9 @@ type drfPlugin struct {
 	// hierarchical tree root
 	hierarchicalRoot *hierarchicalNode
 
+	// resourceWeights scales the share of each resource before the dominant one is chosen
+	resourceWeights api.ResourceWeights
+
 	// Arguments given for the plugin
 	pluginArguments framework.Arguments
 }
@@ -132,6 +135,7 @@ func New(arguments framework.Arguments) framework.Plugin {
 			children:  map[string]*hierarchicalNode{},
 		},
 		pluginArguments: arguments,
+		resourceWeights: arguments.GetResourceWeights(framework.ResourceWeightsKey),
 	}
 }
 
@@ -192,7 +196,8 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	hierarchyEnabled := drf.HierarchyEnabled(ssn)
 
-	for _, job := range ssn.Jobs {
+	// the shares account for the jobs of all the profiles as they share the queues
+	for _, job := range ssn.SnapshotJobs() {
 		attr := &drfAttr{
 			allocated: api.EmptyResource(),
 		}
@@ -200,7 +205,7 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 		for status, tasks := range job.TaskStatusIndex {
 			if api.AllocatedStatus(status) {
 				for _, t := range tasks {
-					attr.allocated.Add(t.Resreq)
+					attr.allocated.Add(t.ShareResreq())
 				}
 			}
 		}
@@ -225,7 +230,7 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 		}
 
 		latt := drf.jobAttrs[preemptor.Job]
-		lalloc := latt.allocated.Clone().Add(preemptor.Resreq)
+		lalloc := latt.allocated.Clone().Add(preemptor.ShareResreq())
 		_, ls := drf.calculateShare(lalloc, drf.totalResource)
 
 		allocations := map[api.JobID]*api.Resource{}
@@ -235,7 +240,7 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 				ratt := drf.jobAttrs[preemptee.Job]
 				allocations[preemptee.Job] = ratt.allocated.Clone()
 			}
-			ralloc := allocations[preemptee.Job].Sub(preemptee.Resreq)
+			ralloc := allocations[preemptee.Job].Sub(preemptee.ShareResreq())
 			_, rs := drf.calculateShare(ralloc, drf.totalResource)
 
 			if ls < rs || math.Abs(ls-rs) <= shareDelta {
@@ -279,8 +284,8 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 			lattr := &drfAttr{
 				allocated: attr.allocated.Clone(),
 			}
-			lattr.allocated.Add(reclaimer.Resreq)
-			totalAllocated.Add(reclaimer.Resreq)
+			lattr.allocated.Add(reclaimer.ShareResreq())
+			totalAllocated.Add(reclaimer.ShareResreq())
 			drf.updateShare(lattr)
 			drf.UpdateHierarchicalShare(root, totalAllocated, ljob, lattr, lqueue.Hierarchy, lqueue.Weights)
 
@@ -289,13 +294,13 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 				rqueue := ssn.Queues[rjob.Queue]
 
 				// update hdrf of reclaimee job
-				totalAllocated.Sub(preemptee.Resreq)
+				totalAllocated.Sub(preemptee.ShareResreq())
 				rjob = rjob.Clone()
 				attr := drf.jobAttrs[rjob.UID]
 				rattr := &drfAttr{
 					allocated: attr.allocated.Clone(),
 				}
-				rattr.allocated.Sub(preemptee.Resreq)
+				rattr.allocated.Sub(preemptee.ShareResreq())
 				drf.updateShare(rattr)
 				drf.UpdateHierarchicalShare(root, totalAllocated, rjob, rattr, rqueue.Hierarchy, rqueue.Weights)
 
@@ -303,8 +308,8 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 				ret := drf.compareQueues(root, lqueue, rqueue)
 
 				// resume hdrf of reclaimee job
-				totalAllocated.Add(preemptee.Resreq)
-				rattr.allocated.Add(preemptee.Resreq)
+				totalAllocated.Add(preemptee.ShareResreq())
+				rattr.allocated.Add(preemptee.ShareResreq())
 				drf.updateShare(rattr)
 				drf.UpdateHierarchicalShare(root, totalAllocated, rjob, rattr, rqueue.Hierarchy, rqueue.Weights)
 
@@ -348,7 +353,7 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 	ssn.AddEventHandler(&framework.EventHandler{
 		AllocateFunc: func(event *framework.Event) {
 			attr := drf.jobAttrs[event.Task.Job]
-			attr.allocated.Add(event.Task.Resreq)
+			attr.allocated.Add(event.Task.ShareResreq())
 
 			job := ssn.Jobs[event.Task.Job]
 			drf.updateJobShare(job.Namespace, job.Name, attr)
@@ -357,16 +362,16 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 			if hierarchyEnabled {
 				queue := ssn.Queues[job.Queue]
 
-				drf.totalAllocated.Add(event.Task.Resreq)
+				drf.totalAllocated.Add(event.Task.ShareResreq())
 				drf.UpdateHierarchicalShare(drf.hierarchicalRoot, drf.totalAllocated, job, attr, queue.Hierarchy, queue.Weights)
 			}
 
 			klog.V(4).Infof("DRF AllocateFunc: task <%v/%v>, resreq <%v>,  share <%v>, namespace share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share, nsShare)
+				event.Task.Namespace, event.Task.Name, event.Task.ShareResreq(), attr.share, nsShare)
 		},
 		DeallocateFunc: func(event *framework.Event) {
 			attr := drf.jobAttrs[event.Task.Job]
-			attr.allocated.Sub(event.Task.Resreq)
+			attr.allocated.Sub(event.Task.ShareResreq())
 
 			job := ssn.Jobs[event.Task.Job]
 			drf.updateJobShare(job.Namespace, job.Name, attr)
@@ -375,12 +380,12 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 
 			if hierarchyEnabled {
 				queue := ssn.Queues[job.Queue]
-				drf.totalAllocated.Sub(event.Task.Resreq)
+				drf.totalAllocated.Sub(event.Task.ShareResreq())
 				drf.UpdateHierarchicalShare(drf.hierarchicalRoot, drf.totalAllocated, job, attr, queue.Hierarchy, queue.Weights)
 			}
 
 			klog.V(4).Infof("DRF EvictFunc: task <%v/%v>, resreq <%v>,  share <%v>, namespace share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share, nsShare)
+				event.Task.Namespace, event.Task.Name, event.Task.ShareResreq(), attr.share, nsShare)
 		},
 	})
 }
@@ -502,8 +507,9 @@ func (drf *drfPlugin) updateShare(attr *drfAttr) {
 func (drf *drfPlugin) calculateShare(allocated, totalResource *api.Resource) (string, float64) {
 	res := float64(0)
 	dominantResource := ""
+	highest := drf.resourceWeights.Highest()
 	for _, rn := range totalResource.ResourceNames() {
-		share := helpers.Share(allocated.Get(rn), totalResource.Get(rn))
+		share := helpers.Share(allocated.Get(rn), totalResource.Get(rn)) * drf.resourceWeights.Get(rn) / highest
 		if share > res {
 			res = share
 			dominantResource = string(rn)
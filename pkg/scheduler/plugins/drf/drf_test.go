@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drf
+
+import (
+	"math"
+	"testing"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+func TestCalculateShareWithResourceWeights(t *testing.T) {
+	total := api.NewResource(api.BuildResourceList("100", "1000Gi", []api.ScalarResource{{Name: "nvidia.com/gpu", Value: "8"}}...))
+	// half of the memory and a quarter of the gpus
+	allocated := api.NewResource(api.BuildResourceList("10", "500Gi", []api.ScalarResource{{Name: "nvidia.com/gpu", Value: "2"}}...))
+
+	tests := []struct {
+		name      string
+		arguments framework.Arguments
+		dominant  string
+		share     float64
+	}{
+		{
+			name:      "no weights",
+			arguments: framework.Arguments{},
+			dominant:  "memory",
+			share:     0.5,
+		},
+		{
+			name:      "gpu dominates and memory counts half",
+			arguments: framework.Arguments{framework.ResourceWeightsKey: map[string]float64{"nvidia.com/gpu": 4, "memory": 0.5}},
+			dominant:  "nvidia.com/gpu",
+			share:     0.25,
+		},
+	}
+
+	for _, test := range tests {
+		drf := New(test.arguments).(*drfPlugin)
+		dominant, share := drf.calculateShare(allocated, total)
+		if dominant != test.dominant || math.Abs(share-test.share) > shareDelta {
+			t.Errorf("%s: expected dominant resource %s with share %v, got %s with share %v",
+				test.name, test.dominant, test.share, dominant, share)
+		}
+	}
+}
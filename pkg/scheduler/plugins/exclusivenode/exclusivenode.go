@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exclusivenode
+
+import (
+	"context"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "exclusivenode"
+
+	// ExclusiveNodeAnnotation is the podgroup annotation which, set to "true", makes the tasks of the
+	// job the only batch workloads of their nodes.
+	ExclusiveNodeAnnotation = api.ExclusiveNode
+
+	// QueuesArgument is the comma separated list of the queues whose jobs may require exclusive
+	// nodes, set by the administrator; the annotation of the jobs of the other queues is ignored.
+	QueuesArgument = "exclusivenode.queues"
+)
+
+type exclusiveNodePlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+	// queues are the queues whose jobs may require exclusive nodes
+	queues sets.Set[string]
+
+	exclusive map[api.JobID]struct{}
+	// owners are the exclusive jobs by the UID of their podgroup, the value of their fences
+	owners map[string]api.JobID
+}
+
+// New return exclusive node plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	queues := sets.New[string]()
+	if value, ok := arguments[QueuesArgument].(string); ok {
+		for _, queue := range strings.Split(value, ",") {
+			if queue = strings.TrimSpace(queue); len(queue) != 0 {
+				queues.Insert(queue)
+			}
+		}
+	}
+	return &exclusiveNodePlugin{pluginArguments: arguments, queues: queues}
+}
+
+func (ep *exclusiveNodePlugin) Name() string {
+	return PluginName
+}
+
+func (ep *exclusiveNodePlugin) isExclusive(job *api.JobInfo) bool {
+	if job.PodGroup == nil || job.PodGroup.Annotations[ExclusiveNodeAnnotation] != "true" {
+		return false
+	}
+	if !ep.queues.Has(string(job.Queue)) {
+		klog.V(4).Infof("Job <%s/%s> requires exclusive nodes, but its queue <%s> is not allowed to, ignore it",
+			job.Namespace, job.Name, job.Queue)
+		return false
+	}
+	return true
+}
+
+// conflict returns the reason why the task can not go to the node, either because one of the two
+// jobs requires exclusive nodes or because the node is fenced for another job.
+func (ep *exclusiveNodePlugin) conflict(task *api.TaskInfo, node *api.NodeInfo) string {
+	_, exclusive := ep.exclusive[task.Job]
+	if node.Node != nil {
+		if owner, found := ep.owners[node.Node.Labels[api.ExclusiveNode]]; found && owner != task.Job {
+			return "node is fenced for job " + string(owner)
+		}
+	}
+	for _, other := range node.Tasks {
+		// the pods of no podgroup are not batch workloads, e.g. daemonsets
+		if len(other.Job) == 0 || other.Job == task.Job || other.Status == api.Succeeded || other.Status == api.Failed {
+			continue
+		}
+		if exclusive {
+			return "node runs tasks of other jobs, but the job requires exclusive nodes"
+		}
+		if _, found := ep.exclusive[other.Job]; found {
+			return "node is used exclusively by job " + string(other.Job)
+		}
+	}
+	return ""
+}
+
+func (ep *exclusiveNodePlugin) OnSessionOpen(ssn *framework.Session) {
+	ep.exclusive = map[api.JobID]struct{}{}
+	ep.owners = map[string]api.JobID{}
+	for _, job := range ssn.Jobs {
+		if ep.isExclusive(job) {
+			ep.exclusive[job.UID] = struct{}{}
+			ep.owners[string(job.PodGroup.UID)] = job.UID
+		}
+	}
+
+	ssn.AddPredicateFn(ep.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if reason := ep.conflict(task, node); len(reason) != 0 {
+			return api.NewFitError(task, node, reason)
+		}
+		return nil
+	})
+}
+
+// fences returns the podgroup UID of the exclusive job each node should be fenced for.
+func (ep *exclusiveNodePlugin) fences(ssn *framework.Session) map[string]string {
+	fences := map[string]string{}
+	for uid := range ep.exclusive {
+		job, found := ssn.Jobs[uid]
+		if !found {
+			continue
+		}
+		for _, task := range job.Tasks {
+			if len(task.NodeName) != 0 && (api.AllocatedStatus(task.Status) || task.Status == api.Pipelined) {
+				fences[task.NodeName] = string(job.PodGroup.UID)
+			}
+		}
+	}
+	return fences
+}
+
+// OnSessionClose fences the nodes of the exclusive jobs, so that they are left to the job by the
+// other schedulers too, and lifts the fences of the nodes the jobs left.
+func (ep *exclusiveNodePlugin) OnSessionClose(ssn *framework.Session) {
+	fences := ep.fences(ssn)
+	for name, node := range ssn.Nodes {
+		if node.Node == nil || node.Node.Labels[api.ExclusiveNode] == fences[name] {
+			continue
+		}
+		if err := fence(ssn.KubeClient(), name, fences[name]); err != nil {
+			klog.Errorf("Failed to set the exclusive fence of node <%s> to <%s>: %v", name, fences[name], err)
+		}
+	}
+
+	ep.exclusive = nil
+	ep.owners = nil
+}
+
+// fence labels and taints the node for the owner, the podgroup UID of an exclusive job, or removes
+// the label and the taint if the owner is empty.
+func fence(client kubernetes.Interface, name, owner string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := client.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		node = node.DeepCopy()
+
+		var taints []v1.Taint
+		for _, taint := range node.Spec.Taints {
+			if taint.Key != api.ExclusiveNode {
+				taints = append(taints, taint)
+			}
+		}
+		if len(owner) == 0 {
+			delete(node.Labels, api.ExclusiveNode)
+		} else {
+			if node.Labels == nil {
+				node.Labels = map[string]string{}
+			}
+			node.Labels[api.ExclusiveNode] = owner
+			taints = append(taints, v1.Taint{Key: api.ExclusiveNode, Value: owner, Effect: v1.TaintEffectNoSchedule})
+		}
+		node.Spec.Taints = taints
+
+		_, err = client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+		return err
+	})
+}
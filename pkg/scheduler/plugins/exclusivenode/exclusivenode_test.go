@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exclusivenode
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/actions/allocate"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestExclusiveNode(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+	buildNode := func(name string) *v1.Node {
+		return util.BuildNode(name, api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string))
+	}
+	buildPodGroup := func(name, queue string, phase schedulingv1beta1.PodGroupPhase, exclusive bool) *schedulingv1beta1.PodGroup {
+		annotations := map[string]string{}
+		if exclusive {
+			annotations[ExclusiveNodeAnnotation] = "true"
+		}
+		pg := util.BuildPodGroupWithAnno(name, "c1", queue, 1, nil, phase, annotations)
+		pg.UID = types.UID(name + "-uid")
+		return pg
+	}
+	fencedNode := buildNode("n1")
+	fencedNode.Labels[api.ExclusiveNode] = "pg1-uid"
+
+	tests := []uthelper.TestCommonStruct{
+		{
+			Name:    "exclusive job avoids the nodes of other jobs",
+			Plugins: plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				buildPodGroup("pg1", "q1", schedulingv1beta1.PodGroupRunning, false),
+				buildPodGroup("pg2", "q1", schedulingv1beta1.PodGroupInqueue, true),
+			},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{buildNode("n1"), buildNode("n2")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p2": "n2"},
+			ExpectBindsNum: 1,
+		},
+		{
+			Name:    "other jobs avoid the nodes of an exclusive job",
+			Plugins: plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				buildPodGroup("pg1", "q1", schedulingv1beta1.PodGroupRunning, true),
+				buildPodGroup("pg2", "q1", schedulingv1beta1.PodGroupInqueue, false),
+			},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{buildNode("n1")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{},
+			ExpectBindsNum: 0,
+		},
+		{
+			Name:    "other jobs avoid the nodes fenced for an exclusive job",
+			Plugins: plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				buildPodGroup("pg1", "q1", schedulingv1beta1.PodGroupRunning, true),
+				buildPodGroup("pg2", "q1", schedulingv1beta1.PodGroupInqueue, false),
+			},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{fencedNode},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{},
+			ExpectBindsNum: 0,
+		},
+		{
+			Name:    "jobs of the queues not allowed to are not exclusive",
+			Plugins: plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{
+				buildPodGroup("pg1", "q2", schedulingv1beta1.PodGroupRunning, true),
+				buildPodGroup("pg2", "q1", schedulingv1beta1.PodGroupInqueue, false),
+			},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{buildNode("n1")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil), util.BuildQueue("q2", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p2": "n1"},
+			ExpectBindsNum: 1,
+		},
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledPredicate: &trueValue,
+					Arguments:        framework.Arguments{QueuesArgument: "q1"},
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.RegisterSession(tiers, nil)
+			defer test.Close()
+			test.Run([]framework.Action{allocate.New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFence(t *testing.T) {
+	node := util.BuildNode("n1", api.BuildResourceList("4", "4Gi"), map[string]string{"zone": "a"})
+	node.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+	client := fake.NewSimpleClientset(node)
+
+	if err := fence(client, "n1", "pg1-uid"); err != nil {
+		t.Fatalf("failed to fence node: %v", err)
+	}
+	fenced, _ := client.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	if fenced.Labels[api.ExclusiveNode] != "pg1-uid" || fenced.Labels["zone"] != "a" {
+		t.Errorf("expected the node labelled for pg1-uid, got %v", fenced.Labels)
+	}
+	expected := []v1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+		{Key: api.ExclusiveNode, Value: "pg1-uid", Effect: v1.TaintEffectNoSchedule},
+	}
+	if !reflect.DeepEqual(fenced.Spec.Taints, expected) {
+		t.Errorf("expected taints %v, got %v", expected, fenced.Spec.Taints)
+	}
+
+	if err := fence(client, "n1", ""); err != nil {
+		t.Fatalf("failed to lift the fence of node: %v", err)
+	}
+	lifted, _ := client.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	if _, found := lifted.Labels[api.ExclusiveNode]; found {
+		t.Errorf("expected the label of the fence removed, got %v", lifted.Labels)
+	}
+	if !reflect.DeepEqual(lifted.Spec.Taints, expected[:1]) {
+		t.Errorf("expected taints %v, got %v", expected[:1], lifted.Spec.Taints)
+	}
+}
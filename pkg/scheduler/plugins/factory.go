@@ -22,21 +22,32 @@ import (
 	"volcano.sh/volcano/pkg/scheduler/plugins/capacity"
 	"volcano.sh/volcano/pkg/scheduler/plugins/cdp"
 	"volcano.sh/volcano/pkg/scheduler/plugins/conformance"
+	"volcano.sh/volcano/pkg/scheduler/plugins/datalocality"
+	"volcano.sh/volcano/pkg/scheduler/plugins/defrag"
 	"volcano.sh/volcano/pkg/scheduler/plugins/deviceshare"
 	"volcano.sh/volcano/pkg/scheduler/plugins/drf"
+	"volcano.sh/volcano/pkg/scheduler/plugins/exclusivenode"
 	"volcano.sh/volcano/pkg/scheduler/plugins/extender"
+	"volcano.sh/volcano/pkg/scheduler/plugins/failuredomain"
+	"volcano.sh/volcano/pkg/scheduler/plugins/flavor"
 	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/plugins/jobgroup"
 	"volcano.sh/volcano/pkg/scheduler/plugins/nodegroup"
+	"volcano.sh/volcano/pkg/scheduler/plugins/nodehealth"
 	"volcano.sh/volcano/pkg/scheduler/plugins/nodeorder"
 	"volcano.sh/volcano/pkg/scheduler/plugins/numaaware"
+	"volcano.sh/volcano/pkg/scheduler/plugins/offer"
 	"volcano.sh/volcano/pkg/scheduler/plugins/overcommit"
 	"volcano.sh/volcano/pkg/scheduler/plugins/pdb"
 	"volcano.sh/volcano/pkg/scheduler/plugins/predicates"
 	"volcano.sh/volcano/pkg/scheduler/plugins/priority"
 	"volcano.sh/volcano/pkg/scheduler/plugins/proportion"
+	"volcano.sh/volcano/pkg/scheduler/plugins/rankaware"
 	"volcano.sh/volcano/pkg/scheduler/plugins/rescheduling"
+	"volcano.sh/volcano/pkg/scheduler/plugins/reservation"
 	"volcano.sh/volcano/pkg/scheduler/plugins/resourcequota"
 	"volcano.sh/volcano/pkg/scheduler/plugins/sla"
+	"volcano.sh/volcano/pkg/scheduler/plugins/sticky"
 	tasktopology "volcano.sh/volcano/pkg/scheduler/plugins/task-topology"
 	"volcano.sh/volcano/pkg/scheduler/plugins/tdm"
 	"volcano.sh/volcano/pkg/scheduler/plugins/usage"
@@ -62,6 +73,17 @@ func init() {
 	framework.RegisterPluginBuilder(usage.PluginName, usage.New)
 	framework.RegisterPluginBuilder(pdb.PluginName, pdb.New)
 	framework.RegisterPluginBuilder(nodegroup.PluginName, nodegroup.New)
+	framework.RegisterPluginBuilder(jobgroup.PluginName, jobgroup.New)
+	framework.RegisterPluginBuilder(reservation.PluginName, reservation.New)
+	framework.RegisterPluginBuilder(defrag.PluginName, defrag.New)
+	framework.RegisterPluginBuilder(offer.PluginName, offer.New)
+	framework.RegisterPluginBuilder(flavor.PluginName, flavor.New)
+	framework.RegisterPluginBuilder(datalocality.PluginName, datalocality.New)
+	framework.RegisterPluginBuilder(failuredomain.PluginName, failuredomain.New)
+	framework.RegisterPluginBuilder(exclusivenode.PluginName, exclusivenode.New)
+	framework.RegisterPluginBuilder(nodehealth.PluginName, nodehealth.New)
+	framework.RegisterPluginBuilder(rankaware.PluginName, rankaware.New)
+	framework.RegisterPluginBuilder(sticky.PluginName, sticky.New)
 
 	// Plugins for Queues
 	framework.RegisterPluginBuilder(proportion.PluginName, proportion.New)
@@ -72,4 +94,8 @@ func init() {
 
 	// Plugins for ResourceQuota
 	framework.RegisterPluginBuilder(resourcequota.PluginName, resourcequota.New)
+
+	// Plugins comparing different resources by the resource weights
+	framework.RegisterResourceWeighted(drf.PluginName)
+	framework.RegisterResourceWeighted(binpack.PluginName)
 }
@@ -0,0 +1,265 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failuredomain
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	k8sFramework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/util"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "failuredomain"
+
+	// MinFailureDomainsAnnotation is the podgroup annotation holding the minimum number of failure
+	// domains the tasks of the job must be spread across.
+	MinFailureDomainsAnnotation = "volcano.sh/min-failure-domains"
+	// FailureDomainKeyAnnotation is the podgroup annotation holding the node label which gives the
+	// failure domain of a node, the zone by default.
+	FailureDomainKeyAnnotation = "volcano.sh/failure-domain-key"
+
+	// NotEnoughFailureDomainsReason is the reason of the podgroup condition of the jobs whose spread
+	// can not be met.
+	NotEnoughFailureDomainsReason = "NotEnoughFailureDomains"
+)
+
+type spread struct {
+	minDomains int
+	key        string
+
+	// domains counts the allocated tasks of the job by failure domain in the session, kept up to
+	// date by the event handlers
+	domains map[string]int
+	// taskDomains holds the failure domain of the tasks counted in domains
+	taskDomains map[api.TaskID]string
+}
+
+// allocate counts the task in its failure domain if it is allocated and not counted yet.
+func (s *spread) allocate(task *api.TaskInfo, node *api.NodeInfo) {
+	if !api.AllocatedStatus(task.Status) {
+		return
+	}
+	if _, found := s.taskDomains[task.UID]; found {
+		return
+	}
+	domain := domainOf(node, s.key)
+	if len(domain) == 0 {
+		return
+	}
+	s.taskDomains[task.UID] = domain
+	s.domains[domain]++
+}
+
+// deallocate removes the task from the count of its failure domain.
+func (s *spread) deallocate(task *api.TaskInfo) {
+	domain, found := s.taskDomains[task.UID]
+	if !found {
+		return
+	}
+	delete(s.taskDomains, task.UID)
+	if s.domains[domain]--; s.domains[domain] == 0 {
+		delete(s.domains, domain)
+	}
+}
+
+// spreadKey is the job extension holding the spread of the jobs which require one.
+var spreadKey = api.NewExtensionKey[*spread]("failuredomain.spread")
+
+type failureDomainPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	// invalid holds the reason why the spread of a job can not be met
+	invalid map[api.JobID]string
+}
+
+// New return failure domain plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	return &failureDomainPlugin{pluginArguments: arguments}
+}
+
+func (fp *failureDomainPlugin) Name() string {
+	return PluginName
+}
+
+func jobSpread(job *api.JobInfo) *spread {
+	if job.PodGroup == nil {
+		return nil
+	}
+	value, found := job.PodGroup.Annotations[MinFailureDomainsAnnotation]
+	if !found {
+		return nil
+	}
+	minDomains, err := strconv.Atoi(value)
+	if err != nil || minDomains <= 1 {
+		if err != nil {
+			klog.Warningf("Invalid %s=%s for job <%s/%s>, ignore it", MinFailureDomainsAnnotation, value, job.Namespace, job.Name)
+		}
+		return nil
+	}
+	key := job.PodGroup.Annotations[FailureDomainKeyAnnotation]
+	if len(key) == 0 {
+		key = v1.LabelTopologyZone
+	}
+	return &spread{minDomains: minDomains, key: key, domains: map[string]int{}, taskDomains: map[api.TaskID]string{}}
+}
+
+// sessionSpread returns the spread of the job set on session open, false if the job has none.
+func sessionSpread(job *api.JobInfo) (*spread, bool) {
+	if job == nil {
+		return nil, false
+	}
+	return api.GetExtension(job.Extensions, spreadKey)
+}
+
+func domainOf(node *api.NodeInfo, key string) string {
+	if node == nil || node.Node == nil {
+		return ""
+	}
+	return node.Node.Labels[key]
+}
+
+func (fp *failureDomainPlugin) OnSessionOpen(ssn *framework.Session) {
+	fp.invalid = map[api.JobID]string{}
+
+	clusterDomains := map[string]map[string]struct{}{}
+	for _, job := range ssn.Jobs {
+		s := jobSpread(job)
+		if s == nil {
+			continue
+		}
+		for _, task := range job.Tasks {
+			if len(task.NodeName) != 0 {
+				s.allocate(task, ssn.Nodes[task.NodeName])
+			}
+		}
+		api.SetExtension(job.Extensions, spreadKey, s)
+
+		if _, found := clusterDomains[s.key]; !found {
+			clusterDomains[s.key] = map[string]struct{}{}
+			for _, node := range ssn.Nodes {
+				if domain := domainOf(node, s.key); len(domain) != 0 {
+					clusterDomains[s.key][domain] = struct{}{}
+				}
+			}
+		}
+
+		var reason string
+		if domains := len(clusterDomains[s.key]); domains < s.minDomains {
+			reason = fmt.Sprintf("job requires %d failure domains of %s, but the cluster has %d", s.minDomains, s.key, domains)
+		} else if job.MinAvailable < int32(s.minDomains) {
+			reason = fmt.Sprintf("job requires %d failure domains, but its minAvailable is %d", s.minDomains, job.MinAvailable)
+		}
+		if len(reason) == 0 {
+			continue
+		}
+		fp.invalid[job.UID] = reason
+		jc := &scheduling.PodGroupCondition{
+			Type:               scheduling.PodGroupUnschedulableType,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			TransitionID:       string(ssn.UID),
+			Reason:             NotEnoughFailureDomainsReason,
+			Message:            reason,
+		}
+		if err := ssn.UpdatePodGroupCondition(job, jc); err != nil {
+			klog.Errorf("Failed to update condition of job <%s/%s>: %v", job.Namespace, job.Name, err)
+		}
+	}
+
+	ssn.AddJobValidFn(fp.Name(), func(obj interface{}) *api.ValidateResult {
+		job := obj.(*api.JobInfo)
+		if reason, found := fp.invalid[job.UID]; found {
+			return &api.ValidateResult{Pass: false, Reason: NotEnoughFailureDomainsReason, Message: reason}
+		}
+		return nil
+	})
+
+	ssn.AddJobEnqueueableFn(fp.Name(), func(obj interface{}) int {
+		job := obj.(*api.JobInfo)
+		if _, found := fp.invalid[job.UID]; found {
+			return util.Reject
+		}
+		return util.Abstain
+	})
+
+	// A task must go to a new failure domain when the pending tasks are just enough to reach the
+	// minimum number of domains.
+	ssn.AddPredicateFn(fp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		job := ssn.Jobs[task.Job]
+		s, found := sessionSpread(job)
+		if !found {
+			return nil
+		}
+		domain := domainOf(node, s.key)
+		if len(domain) == 0 {
+			return api.NewFitError(task, node, fmt.Sprintf("node has no failure domain label %s", s.key))
+		}
+		if _, found := s.domains[domain]; found && s.minDomains-len(s.domains) >= len(job.TaskStatusIndex[api.Pending]) {
+			return api.NewFitError(task, node, fmt.Sprintf("failure domain %s is already used by the job", domain))
+		}
+		return nil
+	})
+
+	ssn.AddNodeOrderFn(fp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		job := ssn.Jobs[task.Job]
+		s, found := sessionSpread(job)
+		if !found {
+			return 0, nil
+		}
+		if _, found := s.domains[domainOf(node, s.key)]; found {
+			return 0, nil
+		}
+		return float64(k8sFramework.MaxNodeScore), nil
+	})
+
+	ssn.AddJobReadyFn(fp.Name(), func(obj interface{}) bool {
+		job := obj.(*api.JobInfo)
+		s, found := sessionSpread(job)
+		if !found {
+			return true
+		}
+		return len(s.domains) >= s.minDomains
+	})
+
+	ssn.AddEventHandler(&framework.EventHandler{
+		AllocateFunc: func(event *framework.Event) {
+			if s, found := sessionSpread(ssn.Jobs[event.Task.Job]); found {
+				s.allocate(event.Task, ssn.Nodes[event.Task.NodeName])
+			}
+		},
+		DeallocateFunc: func(event *framework.Event) {
+			if s, found := sessionSpread(ssn.Jobs[event.Task.Job]); found {
+				s.deallocate(event.Task)
+			}
+		},
+	})
+}
+
+func (fp *failureDomainPlugin) OnSessionClose(ssn *framework.Session) {
+	fp.invalid = nil
+}
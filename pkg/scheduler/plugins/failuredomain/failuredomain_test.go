@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failuredomain
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/actions/allocate"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestFailureDomainSpread(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+	buildNode := func(name, zone string) *v1.Node {
+		return util.BuildNode(name, api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{v1.LabelTopologyZone: zone})
+	}
+	buildPodGroup := func(minDomains string) *schedulingv1beta1.PodGroup {
+		return util.BuildPodGroupWithAnno("pg1", "c1", "q1", 2, nil, schedulingv1beta1.PodGroupInqueue, map[string]string{MinFailureDomainsAnnotation: minDomains})
+	}
+
+	tests := []uthelper.TestCommonStruct{
+		{
+			Name:      "place the last task in another failure domain",
+			Plugins:   plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{buildPodGroup("2")},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{buildNode("n1", "zone-a"), buildNode("n2", "zone-a"), buildNode("n3", "zone-b")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p2": "n3"},
+			ExpectBindsNum: 1,
+		},
+		{
+			Name:      "do not place the job when the cluster has not enough failure domains",
+			Plugins:   plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{buildPodGroup("3")},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p3", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{buildNode("n1", "zone-a"), buildNode("n3", "zone-b")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{},
+			ExpectBindsNum: 0,
+		},
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledPredicate: &trueValue,
+					EnabledNodeOrder: &trueValue,
+					EnabledJobReady:  &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.RegisterSession(tiers, nil)
+			defer test.Close()
+			test.Run([]framework.Action{allocate.New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFailureDomainEventHandlers(t *testing.T) {
+	test := uthelper.TestCommonStruct{
+		Name:    "count the failure domains of the allocations",
+		Plugins: map[string]framework.PluginBuilder{PluginName: New},
+		PodGroups: []*schedulingv1beta1.PodGroup{
+			util.BuildPodGroupWithAnno("pg1", "c1", "q1", 2, nil, schedulingv1beta1.PodGroupInqueue, map[string]string{MinFailureDomainsAnnotation: "2"}),
+		},
+		Pods: []*v1.Pod{
+			util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+			util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+		},
+		Nodes: []*v1.Node{
+			util.BuildNode("n1", api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{v1.LabelTopologyZone: "zone-a"}),
+			util.BuildNode("n2", api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{v1.LabelTopologyZone: "zone-a"}),
+			util.BuildNode("n3", api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{v1.LabelTopologyZone: "zone-b"}),
+		},
+		Queues: []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+	}
+	trueValue := true
+	tiers := []conf.Tier{{Plugins: []conf.PluginOption{{
+		Name:             PluginName,
+		EnabledPredicate: &trueValue,
+		EnabledJobReady:  &trueValue,
+	}}}}
+	ssn := test.RegisterSession(tiers, nil)
+	defer test.Close()
+
+	var job *api.JobInfo
+	for _, j := range ssn.Jobs {
+		job = j
+	}
+	var task *api.TaskInfo
+	for _, t := range job.TaskStatusIndex[api.Pending] {
+		task = t
+	}
+	if ssn.JobReady(job) {
+		t.Fatalf("expected the job spread across a single failure domain not to be ready")
+	}
+
+	stmt := framework.NewStatement(ssn)
+	if err := stmt.Allocate(task, ssn.Nodes["n3"]); err != nil {
+		t.Fatalf("failed to allocate the task: %v", err)
+	}
+	if !ssn.JobReady(job) {
+		t.Errorf("expected the job spread across two failure domains to be ready")
+	}
+
+	stmt.Discard()
+	if ssn.JobReady(job) {
+		t.Errorf("expected the job not to be ready once the allocation is discarded")
+	}
+	if err := ssn.PredicateFn(task, ssn.Nodes["n2"]); err == nil {
+		t.Errorf("expected the task not to fit the failure domain used already once the allocation is discarded")
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flavor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "flavor"
+
+	// flavorsArgument maps the name of each resource flavor to the label selector of its nodes,
+	// the ResourceFlavors of the same name take precedence, e.g.
+	//
+	//	- plugins:
+	//	  - name: flavor
+	//	    arguments:
+	//	      flavor.flavors:
+	//	        a100: gpu-model=a100
+	//	        v100: gpu-model=v100
+	//	      flavor.fallback: v100
+	flavorsArgument = "flavor.flavors"
+
+	// fallbackArgument is the flavor tried after those a job accepts.
+	fallbackArgument = "flavor.fallback"
+
+	// JobFlavorsAnnotation is the comma separated list of the flavors a job accepts, in preference
+	// order, e.g. "a100,v100".
+	JobFlavorsAnnotation = "volcano.sh/resource-flavors"
+)
+
+type flavor struct {
+	name     string
+	selector labels.Selector
+	// taints are the taints the nodes of the flavor carry
+	taints []v1.Taint
+}
+
+type flavorPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	flavors  map[string]*flavor
+	fallback string
+	// chosen is the flavor each job is placed on in the session
+	chosen map[api.JobID]*flavor
+	// tried is the flavors each job accepts, for the unschedulable reason
+	tried map[api.JobID][]string
+}
+
+// New return flavor plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	fp := &flavorPlugin{
+		pluginArguments: arguments,
+		flavors:         parseFlavors(arguments),
+	}
+	if value, found := arguments[fallbackArgument]; found {
+		fp.fallback = fmt.Sprint(value)
+	}
+	return fp
+}
+
+func (fp *flavorPlugin) Name() string {
+	return PluginName
+}
+
+func parseFlavors(arguments framework.Arguments) map[string]*flavor {
+	flavors := map[string]*flavor{}
+	value, found := arguments[flavorsArgument]
+	if !found {
+		return flavors
+	}
+	selectors, ok := value.(map[interface{}]interface{})
+	if !ok {
+		klog.Errorf("Invalid %s <%v> of plugin %s, expect a map of flavor name to label selector", flavorsArgument, value, PluginName)
+		return flavors
+	}
+	for k, v := range selectors {
+		name, selector := fmt.Sprint(k), fmt.Sprint(v)
+		parsed, err := labels.Parse(selector)
+		if err != nil || parsed.Empty() {
+			klog.Errorf("Invalid selector <%s> of flavor <%s>: %v", selector, name, err)
+			continue
+		}
+		flavors[name] = &flavor{name: name, selector: parsed}
+	}
+	return flavors
+}
+
+// sessionFlavors returns the flavors of the arguments and of the ResourceFlavors by name.
+func (fp *flavorPlugin) sessionFlavors(ssn *framework.Session) map[string]*flavor {
+	flavors := make(map[string]*flavor, len(fp.flavors)+len(ssn.ResourceFlavors))
+	for name, f := range fp.flavors {
+		flavors[name] = f
+	}
+	for name, rf := range ssn.ResourceFlavors {
+		if rf.NodeLabels.Empty() && len(rf.NodeTaints) == 0 {
+			klog.Errorf("ResourceFlavor <%s> selects every node, ignore it", name)
+			continue
+		}
+		flavors[name] = &flavor{name: name, selector: rf.NodeLabels, taints: rf.NodeTaints}
+	}
+	return flavors
+}
+
+// jobFlavors returns the known flavors the job accepts in preference order, followed by the
+// fallback flavor.
+func (fp *flavorPlugin) jobFlavors(job *api.JobInfo, known map[string]*flavor) []*flavor {
+	if job.PodGroup == nil {
+		return nil
+	}
+	value := job.PodGroup.Annotations[JobFlavorsAnnotation]
+	if len(value) == 0 {
+		return nil
+	}
+	var flavors []*flavor
+	accepted := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		f, found := known[name]
+		if !found {
+			klog.Warningf("Job <%s/%s> requests unknown flavor <%s>", job.Namespace, job.Name, name)
+			continue
+		}
+		flavors = append(flavors, f)
+		accepted[name] = true
+	}
+	if f, found := known[fp.fallback]; found && len(flavors) != 0 && !accepted[fp.fallback] {
+		flavors = append(flavors, f)
+	}
+	return flavors
+}
+
+// matches returns whether the node is of the flavor: it has the labels and the taints of the flavor.
+func (f *flavor) matches(node *api.NodeInfo) bool {
+	if node.Node == nil || !f.selector.Matches(labels.Set(node.Node.Labels)) {
+		return false
+	}
+	for i := range f.taints {
+		if !hasTaint(node.Node.Spec.Taints, &f.taints[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTaint(taints []v1.Taint, taint *v1.Taint) bool {
+	for i := range taints {
+		if taints[i].MatchTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// tolerates returns whether the task tolerates the scheduling taints.
+func tolerates(task *api.TaskInfo, taints []v1.Taint) bool {
+	var tolerations []v1.Toleration
+	if task.Pod != nil {
+		tolerations = task.Pod.Spec.Tolerations
+	}
+	_, untolerated := v1helper.FindMatchingUntoleratedTaint(taints, tolerations, func(taint *v1.Taint) bool {
+		return taint.Effect == v1.TaintEffectNoSchedule || taint.Effect == v1.TaintEffectNoExecute
+	})
+	return !untolerated
+}
+
+// fits returns whether the pending tasks fit on the nodes of the flavor, placing them one by
+// one on the first node they tolerate with enough idle resource left.
+func (f *flavor) fits(tasks []*api.TaskInfo, nodes map[string]*api.NodeInfo) bool {
+	idle := map[string]*api.Resource{}
+	for name, node := range nodes {
+		if f.matches(node) && !node.Node.Spec.Unschedulable {
+			idle[name] = node.Idle.Clone()
+		}
+	}
+	for _, task := range tasks {
+		if !tolerates(task, f.taints) {
+			return false
+		}
+		placed := false
+		for name, left := range idle {
+			if task.InitResreq.LessEqual(left, api.Zero) && tolerates(task, nodes[name].Node.Spec.Taints) {
+				left.Sub(task.InitResreq)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			return false
+		}
+	}
+	return true
+}
+
+// chooseFlavor returns the flavor the job is placed on: the flavor of the nodes its tasks
+// already run on, otherwise the first flavor in preference order whose nodes fit the pending
+// tasks, otherwise the most preferred one to wait for.
+func chooseFlavor(job *api.JobInfo, flavors []*flavor, nodes map[string]*api.NodeInfo) *flavor {
+	for _, task := range job.Tasks {
+		if !api.AllocatedStatus(task.Status) || len(task.NodeName) == 0 {
+			continue
+		}
+		node, found := nodes[task.NodeName]
+		if !found {
+			continue
+		}
+		for _, f := range flavors {
+			if f.matches(node) {
+				return f
+			}
+		}
+	}
+
+	pending := make([]*api.TaskInfo, 0, len(job.TaskStatusIndex[api.Pending]))
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		pending = append(pending, task)
+	}
+	// place the largest tasks first
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].InitResreq.MilliCPU != pending[j].InitResreq.MilliCPU {
+			return pending[i].InitResreq.MilliCPU > pending[j].InitResreq.MilliCPU
+		}
+		return pending[i].InitResreq.Memory > pending[j].InitResreq.Memory
+	})
+	for _, f := range flavors {
+		if f.fits(pending, nodes) {
+			return f
+		}
+		klog.V(4).Infof("The pending tasks of job <%s/%s> do not fit on the nodes of flavor <%s>",
+			job.Namespace, job.Name, f.name)
+	}
+	return flavors[0]
+}
+
+func (fp *flavorPlugin) OnSessionOpen(ssn *framework.Session) {
+	fp.chosen = map[api.JobID]*flavor{}
+	fp.tried = map[api.JobID][]string{}
+	known := fp.sessionFlavors(ssn)
+	if len(known) == 0 {
+		return
+	}
+
+	for _, job := range ssn.Jobs {
+		flavors := fp.jobFlavors(job, known)
+		if len(flavors) == 0 {
+			continue
+		}
+		fp.chosen[job.UID] = chooseFlavor(job, flavors, ssn.Nodes)
+		for _, f := range flavors {
+			fp.tried[job.UID] = append(fp.tried[job.UID], f.name)
+		}
+		klog.V(4).Infof("Job <%s/%s> is placed on flavor <%s>", job.Namespace, job.Name, fp.chosen[job.UID].name)
+	}
+
+	ssn.AddPredicateFn(fp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		f, found := fp.chosen[task.Job]
+		if !found || f.matches(node) {
+			return nil
+		}
+		return api.NewFitErrWithStatus(task, node, &api.Status{
+			Code:   api.UnschedulableAndUnresolvable,
+			Reason: fmt.Sprintf("node is not of flavor <%s> chosen among <%s>", f.name, strings.Join(fp.tried[task.Job], ",")),
+			Plugin: PluginName,
+		})
+	})
+}
+
+func (fp *flavorPlugin) OnSessionClose(ssn *framework.Session) {
+	fp.chosen = nil
+	fp.tried = nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flavor
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestChooseFlavor(t *testing.T) {
+	fp := New(framework.Arguments{
+		flavorsArgument: map[interface{}]interface{}{
+			"a100": "gpu-model=a100",
+			"v100": "gpu-model=v100",
+			"t4":   "gpu-model=t4",
+		},
+		fallbackArgument: "t4",
+	}).(*flavorPlugin)
+
+	dedicated := v1.Taint{Key: "dedicated", Value: "training", Effect: v1.TaintEffectNoSchedule}
+	tainted := util.BuildNode("a100-2", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"gpu-model": "a100"})
+	tainted.Spec.Taints = []v1.Taint{dedicated}
+	nodes := map[string]*api.NodeInfo{
+		"a100-1": api.NewNodeInfo(util.BuildNode("a100-1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"gpu-model": "a100"})),
+		"a100-2": api.NewNodeInfo(tainted),
+		"v100-1": api.NewNodeInfo(util.BuildNode("v100-1", api.BuildResourceList("8", "16Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"gpu-model": "v100"})),
+		"t4-1":   api.NewNodeInfo(util.BuildNode("t4-1", api.BuildResourceList("16", "32Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"gpu-model": "t4"})),
+	}
+
+	buildJob := func(nodeName string, tolerate bool, cpus ...string) *api.JobInfo {
+		phase := v1.PodPending
+		if len(nodeName) != 0 {
+			phase = v1.PodRunning
+		}
+		var tasks []*api.TaskInfo
+		for i, cpu := range cpus {
+			pod := util.BuildPod("c1", fmt.Sprintf("p%d", i), nodeName, phase, api.BuildResourceList(cpu, "1Gi"), "pg1", nil, nil)
+			if tolerate {
+				pod.Spec.Tolerations = []v1.Toleration{{Key: dedicated.Key, Operator: v1.TolerationOpExists}}
+			}
+			tasks = append(tasks, api.NewTaskInfo(pod))
+		}
+		job := api.NewJobInfo("c1/pg1", tasks...)
+		job.SetPodGroup(&api.PodGroup{PodGroup: scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pg1",
+				Namespace:   "c1",
+				Annotations: map[string]string{JobFlavorsAnnotation: "a100, v100"},
+			},
+			Spec: scheduling.PodGroupSpec{MinMember: 1},
+		}})
+		return job
+	}
+
+	tests := []struct {
+		name     string
+		job      *api.JobInfo
+		expected string
+	}{
+		{
+			name:     "the preferred flavor fits",
+			job:      buildJob("", false, "2"),
+			expected: "a100",
+		},
+		{
+			name:     "the tasks fit on the preferred flavor node by node",
+			job:      buildJob("", true, "3", "3"),
+			expected: "a100",
+		},
+		{
+			name:     "fall back to the next flavor when the tasks only fit on the sum of the nodes",
+			job:      buildJob("", true, "3", "3", "2"),
+			expected: "v100",
+		},
+		{
+			name:     "fall back to the next flavor when the nodes of the preferred one are tainted",
+			job:      buildJob("", false, "3", "3"),
+			expected: "v100",
+		},
+		{
+			name:     "fall back to the fallback flavor when no accepted flavor fits",
+			job:      buildJob("", false, "12"),
+			expected: "t4",
+		},
+		{
+			name:     "wait for the preferred flavor when no flavor fits",
+			job:      buildJob("", false, "32"),
+			expected: "a100",
+		},
+		{
+			name:     "stick to the flavor of the running tasks",
+			job:      buildJob("v100-1", false, "2"),
+			expected: "v100",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flavors := fp.jobFlavors(test.job, fp.flavors)
+			if len(flavors) != 3 || flavors[2].name != "t4" {
+				t.Fatalf("expected the 2 flavors of the job and the fallback flavor, got %d", len(flavors))
+			}
+			if got := chooseFlavor(test.job, flavors, nodes); got.name != test.expected {
+				t.Errorf("expected flavor %s, got %s", test.expected, got.name)
+			}
+		})
+	}
+}
+
+func TestResourceFlavors(t *testing.T) {
+	fp := New(framework.Arguments{
+		flavorsArgument: map[interface{}]interface{}{"a100": "gpu-model=a100"},
+	}).(*flavorPlugin)
+	spot := v1.Taint{Key: "spot", Effect: v1.TaintEffectNoSchedule}
+	flavors := fp.sessionFlavors(&framework.Session{ResourceFlavors: map[string]*api.ResourceFlavor{
+		"a100": {Name: "a100", NodeLabels: labels.SelectorFromSet(labels.Set{"gpu-model": "a100"}), NodeTaints: []v1.Taint{spot}},
+		"all":  {Name: "all", NodeLabels: labels.Everything()},
+	}})
+	if _, found := flavors["all"]; found {
+		t.Errorf("expected the ResourceFlavor selecting every node to be ignored")
+	}
+
+	node := util.BuildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{"gpu-model": "a100"})
+	if flavors["a100"].matches(api.NewNodeInfo(node)) {
+		t.Errorf("expected the node without the taints of the ResourceFlavor not to be of the flavor")
+	}
+	node.Spec.Taints = []v1.Taint{spot}
+	if !flavors["a100"].matches(api.NewNodeInfo(node)) {
+		t.Errorf("expected the node with the labels and the taints of the ResourceFlavor to be of the flavor")
+	}
+}
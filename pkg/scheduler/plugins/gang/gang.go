@@ -26,6 +26,7 @@ import (
 	"volcano.sh/apis/pkg/apis/scheduling"
 	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
 	"volcano.sh/volcano/pkg/scheduler/framework"
 	"volcano.sh/volcano/pkg/scheduler/metrics"
 	"volcano.sh/volcano/pkg/scheduler/plugins/util"
@@ -58,6 +59,18 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 			}
 		}
 
+		// A degraded best-effort gang job is valid as long as any of its tasks can run.
+		if job.IsGangDegraded() {
+			if job.ValidTaskNum() == 0 {
+				return &api.ValidateResult{
+					Pass:    false,
+					Reason:  v1beta1.NotEnoughPodsReason,
+					Message: "No valid tasks for best-effort gang-scheduling",
+				}
+			}
+			return nil
+		}
+
 		if valid := job.CheckTaskValid(); !valid {
 			return &api.ValidateResult{
 				Pass:    false,
@@ -106,7 +119,16 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	// TODO(k82cn): Support preempt/reclaim batch job.
 	ssn.AddReclaimableFn(gp.Name(), preemptableFn)
-	ssn.AddPreemptableFn(gp.Name(), preemptableFn)
+	wholeJobPreemption := false
+	framework.GetArgOfActionFromConf(ssn.Configurations, "preempt").GetBool(&wholeJobPreemption, conf.WholeJobPreemptionKey)
+	if wholeJobPreemption {
+		// preempt evicts the victim jobs as a whole, so they are not left below their minAvailable
+		ssn.AddPreemptableFn(gp.Name(), func(_ *api.TaskInfo, preemptees []*api.TaskInfo) ([]*api.TaskInfo, int) {
+			return preemptees, util.Permit
+		})
+	} else {
+		ssn.AddPreemptableFn(gp.Name(), preemptableFn)
+	}
 
 	jobOrderFn := func(l, r interface{}) int {
 		lv := l.(*api.JobInfo)
@@ -139,7 +161,7 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 		if ji.CheckTaskReady() && ji.IsReady() {
 			return true
 		}
-		return false
+		return ji.IsGangDegraded() && ji.ReadyTaskNum() > 0
 	})
 
 	pipelinedFn := func(obj interface{}) int {
@@ -147,6 +169,9 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 		if ji.CheckTaskPipelined() && ji.IsPipelined() {
 			return util.Permit
 		}
+		if ji.IsGangDegraded() && ji.WaitingTaskNum()+ji.ReadyTaskNum() > 0 {
+			return util.Permit
+		}
 		return util.Reject
 	}
 	ssn.AddJobPipelinedFn(gp.Name(), pipelinedFn)
@@ -163,7 +188,22 @@ func (gp *gangPlugin) OnSessionClose(ssn *framework.Session) {
 	var unreadyTaskCount int32
 	var unScheduleJobCount int
 	for _, job := range ssn.Jobs {
-		if !job.IsReady() {
+		if !job.IsReady() && job.IsGangDegraded() && job.ReadyTaskNum() > 0 {
+			jc := &scheduling.PodGroupCondition{
+				Type:               api.PodGroupGangDegradedType,
+				Status:             v1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				TransitionID:       string(ssn.UID),
+				Reason:             "GangBudgetExceeded",
+				Message: fmt.Sprintf("%v/%v tasks placed partially after gang budget %v",
+					job.ReadyTaskNum(), job.MinAvailable, job.GangBudget),
+			}
+
+			if err := ssn.UpdatePodGroupCondition(job, jc); err != nil {
+				klog.Errorf("Failed to update job <%s/%s> condition: %v",
+					job.Namespace, job.Name, err)
+			}
+		} else if !job.IsReady() {
 			schedulableTaskNum := func() (num int32) {
 				for _, task := range job.TaskStatusIndex[api.Pending] {
 					ctx := task.GetTransactionContext()
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobgroup
+
+import (
+	"strconv"
+
+	"k8s.io/klog/v2"
+	k8sFramework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/util"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "jobgroup"
+
+	// JobGroupKey is the label or annotation of a PodGroup which names the job group it belongs to.
+	JobGroupKey = "volcano.sh/job-group"
+	// JobGroupMinMemberKey is the annotation which holds back the members of the job group until it
+	// has the given number of members: no member is enqueued before then. The members are still
+	// enqueued one by one afterwards, each as its own resources allow, so a member may stay pending
+	// while the others are admitted.
+	JobGroupMinMemberKey = "volcano.sh/job-group-min-member"
+
+	// weightArgument is the weight of the co-location score.
+	weightArgument = "jobgroup.weight"
+)
+
+type jobGroupPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+	weight          int
+}
+
+// New return jobgroup plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	weight := 1
+	arguments.GetInt(&weight, weightArgument)
+	return &jobGroupPlugin{pluginArguments: arguments, weight: weight}
+}
+
+func (jp *jobGroupPlugin) Name() string {
+	return PluginName
+}
+
+// GetJobGroup returns the job group of the job, the label takes precedence over the annotation.
+func GetJobGroup(job *api.JobInfo) string {
+	if job == nil || job.PodGroup == nil {
+		return ""
+	}
+	if group, found := job.PodGroup.Labels[JobGroupKey]; found {
+		return group
+	}
+	return job.PodGroup.Annotations[JobGroupKey]
+}
+
+// groupKey returns the key of the job group of the job: the groups are scoped to the namespace
+// of their members, empty if the job is in no group.
+func groupKey(job *api.JobInfo) string {
+	group := GetJobGroup(job)
+	if len(group) == 0 {
+		return ""
+	}
+	return job.Namespace + "/" + group
+}
+
+func getJobGroupMinMember(job *api.JobInfo) int {
+	if job.PodGroup == nil {
+		return 0
+	}
+	value, found := job.PodGroup.Annotations[JobGroupMinMemberKey]
+	if !found {
+		return 0
+	}
+	minMember, err := strconv.Atoi(value)
+	if err != nil {
+		klog.Warningf("Invalid %s <%s> of job <%s/%s>: %v", JobGroupMinMemberKey, value, job.Namespace, job.Name, err)
+		return 0
+	}
+	return minMember
+}
+
+// isActiveMember returns whether the job counts as a member of its job group, the jobs whose
+// podgroup completed or whose tasks all succeeded or failed are no longer members.
+func isActiveMember(job *api.JobInfo) bool {
+	if job.PodGroup == nil || job.PodGroup.Status.Phase == scheduling.PodGroupCompleted {
+		return false
+	}
+	if len(job.Tasks) == 0 {
+		return true
+	}
+	finished := len(job.TaskStatusIndex[api.Succeeded]) + len(job.TaskStatusIndex[api.Failed])
+	return finished < len(job.Tasks)
+}
+
+func (jp *jobGroupPlugin) OnSessionOpen(ssn *framework.Session) {
+	// the members are counted from the podgroups of the snapshot, not only the jobs of the session,
+	// and grouped by namespace and job group
+	groups := map[string][]*api.JobInfo{}
+	for _, job := range ssn.SnapshotJobs() {
+		if group := groupKey(job); len(group) != 0 && isActiveMember(job) {
+			groups[group] = append(groups[group], job)
+		}
+	}
+	if len(groups) == 0 {
+		return
+	}
+
+	// allocatedMateTasks counts the allocated tasks of the other members of the task's job group,
+	// in total and on the given node.
+	allocatedMateTasks := func(task *api.TaskInfo, group string, node *api.NodeInfo) (onNode, total int) {
+		for _, mate := range groups[group] {
+			if mate.UID == task.Job {
+				continue
+			}
+			for status, tasks := range mate.TaskStatusIndex {
+				if !api.AllocatedStatus(status) {
+					continue
+				}
+				total += len(tasks)
+				for _, t := range tasks {
+					if t.NodeName == node.Name {
+						onNode++
+					}
+				}
+			}
+		}
+		return onNode, total
+	}
+
+	nodeOrderFn := func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		group := groupKey(ssn.Jobs[task.Job])
+		if len(group) == 0 {
+			return 0, nil
+		}
+
+		onNode, total := allocatedMateTasks(task, group, node)
+		if total == 0 {
+			return 0, nil
+		}
+		score := float64(jp.weight) * float64(k8sFramework.MaxNodeScore) * float64(onNode) / float64(total)
+		klog.V(4).Infof("JobGroup score of task <%s/%s> on node <%s>: %v, <%d/%d> tasks of group <%s> on it",
+			task.Namespace, task.Name, node.Name, score, onNode, total, group)
+		return score, nil
+	}
+	ssn.AddNodeOrderFn(jp.Name(), nodeOrderFn)
+
+	jobEnqueueableFn := func(obj interface{}) int {
+		job := obj.(*api.JobInfo)
+		group := groupKey(job)
+		if len(group) == 0 {
+			return util.Abstain
+		}
+
+		minMember := getJobGroupMinMember(job)
+		if minMember <= 0 {
+			return util.Abstain
+		}
+		if members := len(groups[group]); members < minMember {
+			klog.V(3).Infof("Job <%s/%s> can not be enqueued, job group <%s> has %d members, requires %d",
+				job.Namespace, job.Name, group, members, minMember)
+			return util.Reject
+		}
+		return util.Abstain
+	}
+	ssn.AddJobEnqueueableFn(jp.Name(), jobEnqueueableFn)
+}
+
+func (jp *jobGroupPlugin) OnSessionClose(ssn *framework.Session) {}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobgroup
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestJobGroup(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{PluginName: New}
+
+	n1 := util.BuildNode("n1", api.BuildResourceList("4", "8Gi"), make(map[string]string))
+	n2 := util.BuildNode("n2", api.BuildResourceList("4", "8Gi"), make(map[string]string))
+
+	p1 := util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string))
+	p2 := util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg2", make(map[string]string), make(map[string]string))
+	p3 := util.BuildPod("c1", "p3", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg3", make(map[string]string), make(map[string]string))
+	p4 := util.BuildPod("c1", "p4", "n2", v1.PodSucceeded, api.BuildResourceList("1", "1Gi"), "pg4", make(map[string]string), make(map[string]string))
+	p5 := util.BuildPod("c2", "p5", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg5", make(map[string]string), make(map[string]string))
+
+	pg1 := util.BuildPodGroupWithAnno("pg1", "c1", "q1", 1, nil, schedulingv1.PodGroupRunning, map[string]string{JobGroupKey: "g1"})
+	pg2 := util.BuildPodGroupWithAnno("pg2", "c1", "q1", 1, nil, schedulingv1.PodGroupPending, map[string]string{
+		JobGroupKey:          "g1",
+		JobGroupMinMemberKey: "2",
+	})
+	pg3 := util.BuildPodGroupWithAnno("pg3", "c1", "q1", 1, nil, schedulingv1.PodGroupPending, map[string]string{
+		JobGroupKey:          "g2",
+		JobGroupMinMemberKey: "2",
+	})
+	// pg4 has finished, it is no longer a member of g2
+	pg4 := util.BuildPodGroupWithAnno("pg4", "c1", "q1", 1, nil, schedulingv1.PodGroupRunning, map[string]string{JobGroupKey: "g2"})
+	// pg5 is in another namespace, it is not a member of the g2 of c1
+	pg5 := util.BuildPodGroupWithAnno("pg5", "c2", "q1", 1, nil, schedulingv1.PodGroupRunning, map[string]string{JobGroupKey: "g2"})
+
+	queue := util.BuildQueue("q1", 1, nil)
+
+	test := uthelper.TestCommonStruct{
+		Name:      "job group co-location and admission",
+		PodGroups: []*schedulingv1.PodGroup{pg1, pg2, pg3, pg4, pg5},
+		Queues:    []*schedulingv1.Queue{queue},
+		Pods:      []*v1.Pod{p1, p2, p3, p4, p5},
+		Nodes:     []*v1.Node{n1, n2},
+		Plugins:   plugins,
+	}
+	expectedScores := map[string]map[string]float64{
+		"c1/p2": {"n1": 100, "n2": 0},
+		"c1/p3": {"n1": 0, "n2": 0},
+	}
+	expectedEnqueueable := map[api.JobID]bool{
+		"c1/pg2": true,
+		"c1/pg3": false,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               PluginName,
+					EnabledNodeOrder:   &trueValue,
+					EnabledJobEnqueued: &trueValue,
+				},
+			},
+		},
+	}
+	ssn := test.RegisterSession(tiers, nil)
+	defer test.Close()
+
+	for taskID, scores := range expectedScores {
+		for _, job := range ssn.Jobs {
+			for _, task := range job.Tasks {
+				if fmt.Sprintf("%s/%s", task.Namespace, task.Name) != taskID {
+					continue
+				}
+				for nodeName, expected := range scores {
+					score, err := ssn.NodeOrderFn(task, ssn.Nodes[nodeName])
+					if err != nil {
+						t.Errorf("task %s on node %s has err %v", taskID, nodeName, err)
+						continue
+					}
+					if score != expected {
+						t.Errorf("task %s on node %s expect score %v, but get %v", taskID, nodeName, expected, score)
+					}
+				}
+			}
+		}
+	}
+
+	for jobID, expected := range expectedEnqueueable {
+		if got := ssn.JobEnqueueable(ssn.Jobs[jobID]); got != expected {
+			t.Errorf("job %s expect enqueueable %v, but get %v", jobID, expected, got)
+		}
+	}
+}
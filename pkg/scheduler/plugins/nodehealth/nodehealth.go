@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodehealth
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	k8sFramework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "nodehealth"
+
+	// ConditionsArgument is the argument holding the node condition types which mark a node as
+	// unhealthy when true, a list or a comma separated string.
+	ConditionsArgument = "nodehealth.conditions"
+	// WeightArgument is the argument holding the weight of the score of the healthy nodes.
+	WeightArgument = "nodehealth.weight"
+)
+
+// DefaultConditions are the problems reported as node conditions by the default configuration of
+// the node problem detector.
+var DefaultConditions = []string{
+	"KernelDeadlock",
+	"ReadonlyFilesystem",
+	"FrequentKubeletRestart",
+	"FrequentContainerdRestart",
+	"FrequentDockerRestart",
+	"FrequentUnregisterNetDevice",
+}
+
+type nodeHealthPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	conditions sets.Set[string]
+	weight     int
+}
+
+// New return node health plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	conditions := ParseConditions(arguments[ConditionsArgument])
+	if len(conditions) == 0 {
+		conditions = sets.New(DefaultConditions...)
+	}
+	weight := 1
+	arguments.GetInt(&weight, WeightArgument)
+	return &nodeHealthPlugin{pluginArguments: arguments, conditions: conditions, weight: weight}
+}
+
+func (hp *nodeHealthPlugin) Name() string {
+	return PluginName
+}
+
+// ParseConditions returns the condition types of a list or of a comma separated string.
+func ParseConditions(value interface{}) sets.Set[string] {
+	conditions := sets.New[string]()
+	switch v := value.(type) {
+	case string:
+		for _, condition := range strings.Split(v, ",") {
+			if condition = strings.TrimSpace(condition); len(condition) != 0 {
+				conditions.Insert(condition)
+			}
+		}
+	case []interface{}:
+		for _, condition := range v {
+			if s, ok := condition.(string); ok && len(s) != 0 {
+				conditions.Insert(s)
+			}
+		}
+	case []string:
+		conditions.Insert(v...)
+	case nil:
+	default:
+		klog.Warningf("Invalid node conditions %v, ignore them", value)
+	}
+	return conditions
+}
+
+// UnhealthyConditions returns the condition types of the node which are true among the given ones.
+func UnhealthyConditions(node *v1.Node, conditions sets.Set[string]) []string {
+	if node == nil {
+		return nil
+	}
+	var unhealthy []string
+	for _, cond := range node.Status.Conditions {
+		if cond.Status == v1.ConditionTrue && conditions.Has(string(cond.Type)) {
+			unhealthy = append(unhealthy, string(cond.Type))
+		}
+	}
+	return unhealthy
+}
+
+func (hp *nodeHealthPlugin) OnSessionOpen(ssn *framework.Session) {
+	// The replacements of the tasks of running gangs are kept off the unhealthy nodes too, the
+	// unhealthyNode strategy of the rescheduling plugin would evict them again.
+	ssn.AddPredicateFn(hp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if unhealthy := UnhealthyConditions(node.Node, hp.conditions); len(unhealthy) != 0 {
+			return api.NewFitError(task, node, fmt.Sprintf("node is unhealthy: %s", strings.Join(unhealthy, ", ")))
+		}
+		return nil
+	})
+
+	ssn.AddNodeOrderFn(hp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		if len(UnhealthyConditions(node.Node, hp.conditions)) != 0 {
+			return 0, nil
+		}
+		return float64(k8sFramework.MaxNodeScore * int64(hp.weight)), nil
+	})
+}
+
+func (hp *nodeHealthPlugin) OnSessionClose(ssn *framework.Session) {}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodehealth
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/actions/allocate"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestNodeHealth(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+	buildNode := func(name string, conditions ...v1.NodeConditionType) *v1.Node {
+		node := util.BuildNode(name, api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string))
+		for _, condition := range conditions {
+			node.Status.Conditions = append(node.Status.Conditions, v1.NodeCondition{Type: condition, Status: v1.ConditionTrue})
+		}
+		return node
+	}
+
+	tests := []uthelper.TestCommonStruct{
+		{
+			Name:      "new gang avoids the unhealthy nodes",
+			Plugins:   plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{util.BuildPodGroup("pg1", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue)},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{buildNode("n1", "GPUECCError"), buildNode("n2", "NetworkUnavailable")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p1": "n2"},
+			ExpectBindsNum: 1,
+		},
+		{
+			Name:      "replacement of a running gang avoids the unhealthy nodes",
+			Plugins:   plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{util.BuildPodGroup("pg1", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning)},
+			Pods: []*v1.Pod{
+				util.BuildPod("c1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)),
+			},
+			Nodes:          []*v1.Node{buildNode("n1", "GPUECCError")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{},
+			ExpectBindsNum: 0,
+		},
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledPredicate: &trueValue,
+					EnabledNodeOrder: &trueValue,
+					Arguments:        framework.Arguments{ConditionsArgument: "GPUECCError, NICFlapping"},
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.RegisterSession(tiers, nil)
+			defer test.Close()
+			test.Run([]framework.Action{allocate.New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/offer"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "offer"
+
+	// ttlArgument is how long offers and claims stay valid, 30s by default.
+	ttlArgument = "offer.ttl"
+)
+
+// offerPlugin implements two-level scheduling: at the end of each session the idle
+// resources of the cluster are offered to the framework schedulers of the queues which
+// are not overused, and the tasks they claim onto the offered nodes are allocated by
+// the allocate action like any other task, so the queue fairness keeps being enforced.
+type offerPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	manager *offer.Manager
+}
+
+// New return offer plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	op := &offerPlugin{
+		pluginArguments: arguments,
+		manager:         offer.Default(),
+	}
+	ttl := offer.DefaultTTL
+	if value, ok := arguments[ttlArgument].(string); ok {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			ttl = d
+		} else {
+			klog.Warningf("Invalid %s <%s> of plugin %s, use %v", ttlArgument, value, PluginName, offer.DefaultTTL)
+		}
+	}
+	op.manager.SetTTL(ttl)
+	return op
+}
+
+func (op *offerPlugin) Name() string {
+	return PluginName
+}
+
+// placement returns the claimed node of a task created by a framework scheduler. The second
+// result is false for the tasks which are not created by a framework scheduler.
+func (op *offerPlugin) placement(ssn *framework.Session, task *api.TaskInfo) (string, bool) {
+	fw := task.Pod.Annotations[offer.FrameworkAnnotation]
+	if len(fw) == 0 {
+		return "", false
+	}
+	p, found := op.manager.Placement(task.Namespace, task.Name)
+	if !found || p.Framework != fw {
+		return "", true
+	}
+	if job, found := ssn.Jobs[task.Job]; !found || string(job.Queue) != p.Queue {
+		return "", true
+	}
+	return p.Node, true
+}
+
+func (op *offerPlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddPredicateFn(op.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		claimed, managed := op.placement(ssn, task)
+		if !managed {
+			return nil
+		}
+		if len(claimed) == 0 {
+			return api.NewFitError(task, node, fmt.Sprintf("task is not claimed by framework <%s> yet",
+				task.Pod.Annotations[offer.FrameworkAnnotation]))
+		}
+		if claimed != node.Name {
+			return api.NewFitError(task, node, fmt.Sprintf("task is claimed onto node <%s>", claimed))
+		}
+		return nil
+	})
+
+	ssn.AddBestNodeFn(op.Name(), func(task *api.TaskInfo, nodeScores map[float64][]*api.NodeInfo) *api.NodeInfo {
+		claimed, _ := op.placement(ssn, task)
+		if len(claimed) == 0 {
+			return nil
+		}
+		for _, nodes := range nodeScores {
+			for _, node := range nodes {
+				if node.Name == claimed {
+					return node
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (op *offerPlugin) OnSessionClose(ssn *framework.Session) {
+	// the claims of the tasks which are allocated or gone are done with, the resources of
+	// the ones still pending are kept out of the offers
+	pending := map[string]*api.TaskInfo{}
+	for _, job := range ssn.Jobs {
+		for _, task := range job.TaskStatusIndex[api.Pending] {
+			pending[task.Namespace+"/"+task.Name] = task
+		}
+	}
+	claimed := map[string]*api.Resource{}
+	for _, key := range op.manager.Placements() {
+		task, found := pending[key]
+		if !found {
+			namespace, name, _ := strings.Cut(key, "/")
+			op.manager.Release(namespace, name)
+			continue
+		}
+		if node, ok := op.placement(ssn, task); ok && len(node) != 0 {
+			if claimed[node] == nil {
+				claimed[node] = api.EmptyResource()
+			}
+			claimed[node].Add(task.InitResreq)
+		}
+	}
+
+	var subscribers []offer.Subscriber
+	for _, s := range op.manager.Subscribers() {
+		queue, found := ssn.Queues[api.QueueID(s.Queue)]
+		if !found || ssn.Overused(queue) {
+			klog.V(4).Infof("Queue <%s> of framework <%s> is missing or overused, no offers", s.Queue, s.Framework)
+			continue
+		}
+		subscribers = append(subscribers, s)
+	}
+	if len(subscribers) == 0 {
+		op.manager.Publish(nil)
+		return
+	}
+
+	nodes := make([]*api.NodeInfo, 0, len(ssn.Nodes))
+	for _, node := range ssn.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	// each node is offered to one framework only, round robin
+	var offers []offer.Offer
+	for _, node := range nodes {
+		if !node.Ready() {
+			continue
+		}
+		idle := node.Idle.Clone()
+		if c := claimed[node.Name]; c != nil {
+			if !c.LessEqual(idle, api.Zero) {
+				continue
+			}
+			idle.Sub(c)
+		}
+		if idle.IsEmpty() {
+			continue
+		}
+		s := subscribers[len(offers)%len(subscribers)]
+		offers = append(offers, offer.Offer{
+			Framework: s.Framework,
+			Queue:     s.Queue,
+			Node:      node.Name,
+			Resources: util.ConvertRes2ResList(idle),
+		})
+	}
+	op.manager.Publish(offers)
+	klog.V(4).Infof("Published %d offers to %d frameworks", len(offers), len(subscribers))
+}
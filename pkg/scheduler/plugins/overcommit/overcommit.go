@@ -84,11 +84,7 @@ func (op *overcommitPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	op.totalResource.Add(ssn.TotalResource)
 	// calculate idle resources of total cluster, overcommit resources included
-	used := api.EmptyResource()
-	for _, node := range ssn.Nodes {
-		used.Add(node.Used)
-	}
-	op.idleResource = op.totalResource.Clone().Multi(op.overCommitFactor).SubWithoutAssert(used)
+	op.idleResource = op.totalResource.Clone().Multi(op.overCommitFactor).SubWithoutAssert(ssn.Totals.Used)
 
 	for _, job := range ssn.Jobs {
 		// calculate inqueue job resources
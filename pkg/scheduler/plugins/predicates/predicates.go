@@ -402,6 +402,37 @@ func (pp *predicatesPlugin) OnSessionOpen(ssn *framework.Session) {
 			predicateStatus = append(predicateStatus, podsNumStatus)
 		}
 
+		if job, found := ssn.Jobs[task.Job]; found && !job.IsCandidateNode(node.Name) {
+			klog.V(4).Infof("CandidateNodes predicates Task <%s/%s> on Node <%s> failed: not a candidate node of the job",
+				task.Namespace, task.Name, node.Name)
+			return api.NewFitErrWithStatus(task, node, &api.Status{
+				Code:   api.UnschedulableAndUnresolvable,
+				Reason: api.NodeNotCandidate,
+			})
+		}
+
+		if job, found := ssn.Jobs[task.Job]; found && job.MaxTasksPerNode > 0 {
+			if jobTasks := jobTaskNumOnNode(task, node); jobTasks >= job.MaxTasksPerNode {
+				klog.V(4).Infof("JobTaskNumber predicates Task <%s/%s> on Node <%s> failed, maximum <%d>, existed <%d>",
+					task.Namespace, task.Name, node.Name, job.MaxTasksPerNode, jobTasks)
+				predicateStatus = append(predicateStatus, &api.Status{
+					Code:   api.Unschedulable,
+					Reason: api.NodeJobTaskNumberExceeded,
+				})
+				return api.NewFitErrWithStatus(task, node, predicateStatus...)
+			}
+		}
+
+		if reason, fits := platformFits(task.Pod, node); !fits {
+			klog.V(4).Infof("Platform predicates Task <%s/%s> on Node <%s> failed: %s",
+				task.Namespace, task.Name, node.Name, reason)
+			predicateStatus = append(predicateStatus, &api.Status{
+				Code:   api.UnschedulableAndUnresolvable,
+				Reason: api.NodePlatformMismatch,
+			})
+			return api.NewFitErrWithStatus(task, node, predicateStatus...)
+		}
+
 		predicateByStablefilter := func(pod *v1.Pod, nodeInfo *k8sframework.NodeInfo) ([]*api.Status, bool, error) {
 			// CheckNodeUnschedulable
 			predicateStatus := make([]*api.Status, 0)
@@ -564,3 +595,30 @@ func handleSkipPrePredicatePlugin(status *k8sframework.Status, task *api.TaskInf
 }
 
 func (pp *predicatesPlugin) OnSessionClose(ssn *framework.Session) {}
+
+// jobTaskNumOnNode returns the number of the other tasks of the job of task on the node.
+func jobTaskNumOnNode(task *api.TaskInfo, node *api.NodeInfo) int32 {
+	num := int32(0)
+	for _, t := range node.Tasks {
+		if t.Job == task.Job && t.UID != task.UID {
+			num++
+		}
+	}
+	return num
+}
+
+// platformFits checks the OS and the architecture of the node against the OS of the pod spec and
+// its node selector, so that the nodes missing the well-known labels are checked too.
+func platformFits(pod *v1.Pod, node *api.NodeInfo) (string, bool) {
+	os := pod.Spec.NodeSelector[v1.LabelOSStable]
+	if pod.Spec.OS != nil {
+		os = string(pod.Spec.OS.Name)
+	}
+	if len(os) != 0 && len(node.OS) != 0 && os != node.OS {
+		return fmt.Sprintf("pod requires OS %s, node runs %s", os, node.OS), false
+	}
+	if arch := pod.Spec.NodeSelector[v1.LabelArchStable]; len(arch) != 0 && len(node.Arch) != 0 && arch != node.Arch {
+		return fmt.Sprintf("pod requires architecture %s, node is %s", arch, node.Arch), false
+	}
+	return "", true
+}
@@ -120,3 +120,198 @@ func TestEventHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxTasksPerNode(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+
+	w1 := util.BuildPod("ns1", "worker-1", "node1", apiv1.PodRunning, api.BuildResourceList("1", "1k"), "pg1", map[string]string{}, map[string]string{})
+	w2 := util.BuildPod("ns1", "worker-2", "", apiv1.PodPending, api.BuildResourceList("1", "1k"), "pg1", map[string]string{}, map[string]string{})
+
+	n1 := util.BuildNode("node1", api.BuildResourceList("4", "4k", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})
+	n2 := util.BuildNode("node2", api.BuildResourceList("4", "4k", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})
+
+	pg1 := util.BuildPodGroupWithAnno("pg1", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning, map[string]string{api.JobMaxTasksPerNode: "1"})
+	queue1 := util.BuildQueue("q1", 0, nil)
+
+	test := uthelper.TestCommonStruct{
+		Name:      "at most one task of the job per node",
+		Plugins:   plugins,
+		Pods:      []*apiv1.Pod{w1, w2},
+		Nodes:     []*apiv1.Node{n1, n2},
+		PodGroups: []*schedulingv1beta1.PodGroup{pg1},
+		Queues:    []*schedulingv1beta1.Queue{queue1},
+		ExpectBindMap: map[string]string{
+			"ns1/worker-2": "node2",
+		},
+		ExpectBindsNum: 1,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledPredicate: &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	t.Run(test.Name, func(t *testing.T) {
+		test.RegisterSession(tiers, nil)
+		defer test.Close()
+		test.Run([]framework.Action{allocate.New()})
+		if err := test.CheckAll(0); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestCandidateNodes(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+
+	w1 := util.BuildPod("ns1", "worker-1", "", apiv1.PodPending, api.BuildResourceList("1", "1k"), "pg1", map[string]string{}, map[string]string{})
+
+	n1 := util.BuildNode("node1", api.BuildResourceList("4", "4k", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})
+	n2 := util.BuildNode("node2", api.BuildResourceList("1", "4k", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})
+
+	pg1 := util.BuildPodGroupWithAnno("pg1", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue, map[string]string{api.JobCandidateNodes: "node2, node3"})
+	queue1 := util.BuildQueue("q1", 0, nil)
+
+	test := uthelper.TestCommonStruct{
+		Name:      "tasks only placed on the candidate nodes",
+		Plugins:   plugins,
+		Pods:      []*apiv1.Pod{w1},
+		Nodes:     []*apiv1.Node{n1, n2},
+		PodGroups: []*schedulingv1beta1.PodGroup{pg1},
+		Queues:    []*schedulingv1beta1.Queue{queue1},
+		ExpectBindMap: map[string]string{
+			"ns1/worker-1": "node2",
+		},
+		ExpectBindsNum: 1,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledPredicate: &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	t.Run(test.Name, func(t *testing.T) {
+		test.RegisterSession(tiers, nil)
+		defer test.Close()
+		test.Run([]framework.Action{allocate.New()})
+		if err := test.CheckAll(0); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPlatformPredicate(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+
+	w1 := util.BuildPod("ns1", "worker-1", "", apiv1.PodPending, api.BuildResourceList("1", "1k"), "pg1", map[string]string{}, map[string]string{})
+	w1.Spec.OS = &apiv1.PodOS{Name: apiv1.Windows}
+
+	// the OS of the nodes is only reported by their kubelet, the node selectors cannot tell them apart
+	n1 := util.BuildNode("node1", api.BuildResourceList("4", "4k", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})
+	n1.Status.NodeInfo.OperatingSystem = "linux"
+	n2 := util.BuildNode("node2", api.BuildResourceList("4", "4k", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})
+	n2.Status.NodeInfo.OperatingSystem = "windows"
+
+	pg1 := util.BuildPodGroup("pg1", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	queue1 := util.BuildQueue("q1", 0, nil)
+
+	test := uthelper.TestCommonStruct{
+		Name:      "tasks only placed on the nodes of their OS",
+		Plugins:   plugins,
+		Pods:      []*apiv1.Pod{w1},
+		Nodes:     []*apiv1.Node{n1, n2},
+		PodGroups: []*schedulingv1beta1.PodGroup{pg1},
+		Queues:    []*schedulingv1beta1.Queue{queue1},
+		ExpectBindMap: map[string]string{
+			"ns1/worker-1": "node2",
+		},
+		ExpectBindsNum: 1,
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledPredicate: &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	t.Run(test.Name, func(t *testing.T) {
+		test.RegisterSession(tiers, nil)
+		defer test.Close()
+		test.Run([]framework.Action{allocate.New()})
+		if err := test.CheckAll(0); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPlatformFits(t *testing.T) {
+	linuxArm := util.BuildNode("n1", api.BuildResourceList("2", "4Gi"), map[string]string{apiv1.LabelOSStable: "linux"})
+	linuxArm.Status.NodeInfo.Architecture = "arm64"
+	windows := util.BuildNode("n2", api.BuildResourceList("2", "4Gi"), map[string]string{})
+	windows.Status.NodeInfo.OperatingSystem = "windows"
+
+	tests := []struct {
+		name   string
+		os     apiv1.OSName
+		labels map[string]string
+		node   *apiv1.Node
+		fits   bool
+	}{
+		{name: "no requirement", node: linuxArm, fits: true},
+		{name: "pod OS matches the label", os: apiv1.Linux, node: linuxArm, fits: true},
+		{name: "pod OS differs from the kubelet report", os: apiv1.Linux, node: windows, fits: false},
+		{name: "selected OS differs", labels: map[string]string{apiv1.LabelOSStable: "windows"}, node: linuxArm, fits: false},
+		{name: "selected architecture differs", labels: map[string]string{apiv1.LabelArchStable: "amd64"}, node: linuxArm, fits: false},
+		{name: "selected architecture matches", labels: map[string]string{apiv1.LabelArchStable: "arm64"}, node: linuxArm, fits: true},
+	}
+	for _, test := range tests {
+		pod := util.BuildPod("ns1", "p1", "", apiv1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), test.labels)
+		if len(test.os) != 0 {
+			pod.Spec.OS = &apiv1.PodOS{Name: test.os}
+		}
+		if _, fits := platformFits(pod, api.NewNodeInfo(test.node)); fits != test.fits {
+			t.Errorf("%s: expected fits %v, got %v", test.name, test.fits, fits)
+		}
+	}
+}
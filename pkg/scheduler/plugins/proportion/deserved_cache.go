@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// cachedDeserved returns the deserved resources of the queues computed for the same key in the
+// last session of the plugin.
+func (pp *proportionPlugin) cachedDeserved(key uint64) (map[api.QueueID]*api.Resource, bool) {
+	if pp.deserved == nil || pp.deservedKey != key {
+		return nil, false
+	}
+	return pp.deserved, true
+}
+
+// cacheDeserved keeps the deserved resources of the queues computed for the key.
+func (pp *proportionPlugin) cacheDeserved(key uint64) {
+	pp.deservedKey = key
+	pp.deserved = make(map[api.QueueID]*api.Resource, len(pp.queueOpts))
+	for queueID, attr := range pp.queueOpts {
+		pp.deserved[queueID] = attr.deserved.Clone()
+	}
+}
+
+// deservedKey hashes the inputs of the deserved resources: the total resource of each node pool and
+// the node pool, weight, capability, guarantee and request of each queue.
+func deservedKey(poolTotals map[string]*api.Resource, queueOpts map[api.QueueID]*queueAttr) uint64 {
+	h := fnv.New64a()
+	pools := make([]string, 0, len(poolTotals))
+	for pool := range poolTotals {
+		pools = append(pools, pool)
+	}
+	sort.Strings(pools)
+	for _, pool := range pools {
+		h.Write([]byte(pool))
+		hashResource(h, poolTotals[pool])
+	}
+
+	queueIDs := make([]string, 0, len(queueOpts))
+	for queueID := range queueOpts {
+		queueIDs = append(queueIDs, string(queueID))
+	}
+	sort.Strings(queueIDs)
+	for _, queueID := range queueIDs {
+		attr := queueOpts[api.QueueID(queueID)]
+		h.Write([]byte(queueID))
+		h.Write([]byte(attr.pool))
+		binary.Write(h, binary.LittleEndian, attr.weight)
+		hashResource(h, attr.realCapability)
+		hashResource(h, attr.guarantee)
+		hashResource(h, attr.request)
+	}
+	return h.Sum64()
+}
+
+func hashResource(h hash.Hash64, r *api.Resource) {
+	if r == nil {
+		h.Write([]byte{0})
+		return
+	}
+	h.Write([]byte{1})
+	binary.Write(h, binary.LittleEndian, math.Float64bits(r.MilliCPU))
+	binary.Write(h, binary.LittleEndian, math.Float64bits(r.Memory))
+
+	names := make([]string, 0, len(r.ScalarResources))
+	for name := range r.ScalarResources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name))
+		binary.Write(h, binary.LittleEndian, math.Float64bits(r.ScalarResources[v1.ResourceName(name)]))
+	}
+}
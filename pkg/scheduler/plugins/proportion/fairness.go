@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+	"volcano.sh/volcano/pkg/scheduler/stats"
+)
+
+const (
+	// fairnessThresholdArgument is the fairness deviation, between 0 and 1, above which the queues
+	// are alerted of; 0 disables the alert
+	fairnessThresholdArgument = "proportion.fairnessThreshold"
+	// fairnessDurationArgument is how long the deviation must stay above the threshold before alerting
+	fairnessDurationArgument = "proportion.fairnessDuration"
+	// fairnessWebhookArgument is the URL the alerts are posted to, besides the events on the queues
+	fairnessWebhookArgument = "proportion.fairnessWebhook"
+
+	defaultFairnessDuration = 5 * time.Minute
+	fairnessWebhookTimeout  = 10 * time.Second
+
+	fairnessFiring   = "firing"
+	fairnessResolved = "resolved"
+)
+
+type fairnessConfig struct {
+	threshold float64
+	duration  time.Duration
+	webhook   string
+}
+
+func parseFairnessConfig(arguments framework.Arguments) fairnessConfig {
+	cfg := fairnessConfig{duration: defaultFairnessDuration}
+	arguments.GetFloat64(&cfg.threshold, fairnessThresholdArgument)
+	if value, ok := arguments[fairnessDurationArgument].(string); ok {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			cfg.duration = d
+		} else {
+			klog.Warningf("Invalid %s <%s>, using <%v>", fairnessDurationArgument, value, defaultFairnessDuration)
+		}
+	}
+	if value, ok := arguments[fairnessWebhookArgument].(string); ok {
+		cfg.webhook = value
+	}
+	return cfg
+}
+
+// fairnessAlert remembers since when the fairness deviation is above the threshold, and whether
+// it was alerted of.
+type fairnessAlert struct {
+	since  time.Time
+	firing bool
+}
+
+// observe records the deviation of this session, and returns the state the alert moved to:
+// firing once the deviation stayed above the threshold for the duration, resolved once it
+// dropped back below it, or empty if the alert did not change.
+func (a *fairnessAlert) observe(deviation float64, cfg fairnessConfig, now time.Time) (string, time.Time) {
+	if deviation <= cfg.threshold {
+		since, firing := a.since, a.firing
+		a.since, a.firing = time.Time{}, false
+		if firing {
+			return fairnessResolved, since
+		}
+		return "", since
+	}
+	if a.since.IsZero() {
+		a.since = now
+	}
+	if !a.firing && now.Sub(a.since) >= cfg.duration {
+		a.firing = true
+		return fairnessFiring, a.since
+	}
+	return "", a.since
+}
+
+// queueStats returns the weights, dominant shares and pending tasks of the queues of the session,
+// as the scheduling stats compute the fairness deviation from.
+func (pp *proportionPlugin) queueStats(ssn *framework.Session) map[string]*stats.QueueStats {
+	queues := map[string]*stats.QueueStats{}
+	for _, attr := range pp.queueOpts {
+		queues[attr.name] = &stats.QueueStats{
+			Weight:         attr.weight,
+			AllocatedShare: stats.DominantShare(attr.allocated, pp.totalResource),
+		}
+	}
+	for _, job := range ssn.Jobs {
+		if attr, found := pp.queueOpts[job.Queue]; found {
+			queues[attr.name].PendingTasks += len(job.TaskStatusIndex[api.Pending])
+		}
+	}
+	return queues
+}
+
+type fairnessQueue struct {
+	Name           string  `json:"name"`
+	WeightShare    float64 `json:"weightShare"`
+	AllocatedShare float64 `json:"allocatedShare"`
+}
+
+type fairnessNotification struct {
+	Status    string          `json:"status"`
+	Deviation float64         `json:"deviation"`
+	Threshold float64         `json:"threshold"`
+	Since     time.Time       `json:"since"`
+	Queues    []fairnessQueue `json:"queues,omitempty"`
+}
+
+// checkFairness records the fairness deviation of the session and alerts, with an event on each
+// queue with pending tasks served below its weight share and on the webhook, when it stayed above
+// the threshold long enough, as reclaim is failing or the queues are misconfigured.
+func (pp *proportionPlugin) checkFairness(ssn *framework.Session) {
+	if len(pp.queueOpts) == 0 || pp.totalResource == nil {
+		return
+	}
+	queues := pp.queueStats(ssn)
+	deviation := stats.FairnessDeviation(queues)
+	metrics.UpdateQueueFairnessDeviation(deviation)
+	if pp.fairness.threshold <= 0 {
+		return
+	}
+
+	status, since := pp.fairnessAlert.observe(deviation, pp.fairness, time.Now())
+	if len(status) == 0 {
+		return
+	}
+	notification := fairnessNotification{
+		Status:    status,
+		Deviation: deviation,
+		Threshold: pp.fairness.threshold,
+		Since:     since,
+	}
+	if status == fairnessResolved {
+		klog.V(3).Infof("The fairness deviation of the queues <%0.2f> is back under <%0.2f>", deviation, pp.fairness.threshold)
+	} else {
+		klog.Warningf("The fairness deviation of the queues <%0.2f> is above <%0.2f> since %v",
+			deviation, pp.fairness.threshold, since)
+		totalShare := float64(0)
+		for _, queue := range queues {
+			if queue.WeightShare > 0 {
+				totalShare += queue.AllocatedShare
+			}
+		}
+		for _, attr := range pp.queueOpts {
+			queue := queues[attr.name]
+			notification.Queues = append(notification.Queues, fairnessQueue{
+				Name:           attr.name,
+				WeightShare:    queue.WeightShare,
+				AllocatedShare: queue.AllocatedShare,
+			})
+			if queue.PendingTasks > 0 && queue.AllocatedShare < queue.WeightShare*totalShare {
+				ssn.RecordQueueEvent(ssn.Queues[attr.queueID], v1.EventTypeWarning, "FairnessDeviation",
+					fmt.Sprintf("Served below weight share <%0.2f> with pending tasks while the fairness deviation of the queues is %0.2f since %v",
+						queue.WeightShare, deviation, since.Format(time.RFC3339)))
+			}
+		}
+		sort.Slice(notification.Queues, func(i, j int) bool {
+			return notification.Queues[i].Name < notification.Queues[j].Name
+		})
+	}
+	if len(pp.fairness.webhook) != 0 {
+		go postFairness(pp.fairness.webhook, notification)
+	}
+}
+
+func postFairness(webhook string, notification fairnessNotification) {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		klog.Errorf("Failed to marshal the fairness alert: %v", err)
+		return
+	}
+	client := &http.Client{Timeout: fairnessWebhookTimeout}
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(data))
+	if err != nil {
+		klog.Warningf("Failed to post the fairness alert to <%s>: %v", webhook, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		klog.Warningf("Failed to post the fairness alert to <%s>: unexpected status %s", webhook, resp.Status)
+	}
+}
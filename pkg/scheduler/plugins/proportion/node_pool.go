@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// nodePoolsArgument maps the name of each node pool to the label selector of its nodes, e.g.
+	//   proportion.nodePools:
+	//     gpu: "nvidia.com/gpu.present=true"
+	nodePoolsArgument = "proportion.nodePools"
+
+	// QueueNodePoolAnnotation binds a queue to a node pool: the jobs of the queue only run on the
+	// nodes of the pool, and the queue gets its deserved share of the pool rather than of the cluster.
+	// The queues which are not bound share the nodes which are in no pool.
+	QueueNodePoolAnnotation = "volcano.sh/node-pool"
+
+	// defaultNodePool is the pool of the nodes selected by no pool
+	defaultNodePool = ""
+)
+
+type nodePool struct {
+	name     string
+	selector labels.Selector
+}
+
+// parseNodePools reads the node pools from the plugin arguments, sorted by name. A node selected
+// by several pools belongs to the first one.
+func parseNodePools(arguments framework.Arguments) []*nodePool {
+	value, found := arguments[nodePoolsArgument]
+	if !found {
+		return nil
+	}
+	selectors, ok := value.(map[interface{}]interface{})
+	if !ok {
+		klog.Errorf("Invalid %s <%v> of plugin %s, expect a map of pool name to label selector", nodePoolsArgument, value, PluginName)
+		return nil
+	}
+
+	var pools []*nodePool
+	for k, v := range selectors {
+		name, selector := fmt.Sprint(k), fmt.Sprint(v)
+		parsed, err := labels.Parse(selector)
+		if err != nil || parsed.Empty() {
+			klog.Errorf("Invalid selector <%s> of node pool <%s>: %v", selector, name, err)
+			continue
+		}
+		pools = append(pools, &nodePool{name: name, selector: parsed})
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].name < pools[j].name
+	})
+	return pools
+}
+
+// buildNodePools assigns each node to its pool and sums the allocatable resource of each pool.
+// Without node pools all nodes are in the default pool, whose total is the one of the session.
+func (pp *proportionPlugin) buildNodePools(ssn *framework.Session) {
+	pp.poolTotals = map[string]*api.Resource{defaultNodePool: api.EmptyResource()}
+	pp.poolGuarantees = map[string]*api.Resource{defaultNodePool: api.EmptyResource()}
+	pp.nodePoolOf = map[string]string{}
+	if len(pp.nodePools) == 0 {
+		pp.poolTotals[defaultNodePool].Add(pp.totalResource)
+		return
+	}
+
+	for _, pool := range pp.nodePools {
+		pp.poolTotals[pool.name] = api.EmptyResource()
+		pp.poolGuarantees[pool.name] = api.EmptyResource()
+	}
+	for name, node := range ssn.Nodes {
+		pool := defaultNodePool
+		if node.Node != nil {
+			for _, np := range pp.nodePools {
+				if np.selector.Matches(labels.Set(node.Node.Labels)) {
+					pool = np.name
+					break
+				}
+			}
+		}
+		pp.nodePoolOf[name] = pool
+		pp.poolTotals[pool].Add(node.Allocatable)
+	}
+	for _, pool := range pp.nodePools {
+		klog.V(4).Infof("The total resource of node pool <%s> is <%v>", pool.name, pp.poolTotals[pool.name])
+	}
+}
+
+// queueNodePool returns the pool the queue is bound to.
+func (pp *proportionPlugin) queueNodePool(queue *api.QueueInfo) string {
+	if len(pp.nodePools) == 0 || queue.Queue == nil {
+		return defaultNodePool
+	}
+	pool := queue.Queue.Annotations[QueueNodePoolAnnotation]
+	if _, found := pp.poolTotals[pool]; !found {
+		klog.Warningf("Queue <%s> is bound to unknown node pool <%s>, use the nodes in no pool", queue.Name, pool)
+		return defaultNodePool
+	}
+	return pool
+}
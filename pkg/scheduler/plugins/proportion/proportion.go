@@ -17,7 +17,9 @@ limitations under the License.
 package proportion
 
 import (
+	"fmt"
 	"math"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -29,6 +31,7 @@ import (
 	"volcano.sh/volcano/pkg/scheduler/framework"
 	"volcano.sh/volcano/pkg/scheduler/metrics"
 	"volcano.sh/volcano/pkg/scheduler/plugins/util"
+	"volcano.sh/volcano/pkg/scheduler/quota"
 )
 
 // PluginName indicates name of volcano scheduler plugin.
@@ -38,6 +41,41 @@ type proportionPlugin struct {
 	totalResource  *api.Resource
 	totalGuarantee *api.Resource
 	queueOpts      map[api.QueueID]*queueAttr
+
+	// nodePools partition the nodes, the deserved resources are computed in each pool for the
+	// queues bound to it
+	nodePools      []*nodePool
+	poolTotals     map[string]*api.Resource
+	poolGuarantees map[string]*api.Resource
+	nodePoolOf     map[string]string
+	// reservations are the quota reserved on each queue for the jobs of the submission portals
+	// not created yet, by reservation ID, see the quota package
+	reservations map[api.QueueID]map[string]*api.Resource
+
+	// rebalance evicts, rebalanceEvictions tasks at a time, the tasks of the queues allocated
+	// beyond their capability
+	rebalance          bool
+	rebalanceEvictions int
+	// rebalancing is set once the cluster shrank or the weight or capability of a queue changed,
+	// until the queues are back within their deserved resource
+	rebalancing bool
+	// lastTotal and lastQuotas are the total resource of the cluster and the weight and
+	// capability of each queue in the last session
+	lastTotal  *api.Resource
+	lastQuotas map[api.QueueID]queueQuota
+	// fairness configures the alert on the deviation of the allocated resources of the queues from
+	// their weight shares, see stats.FairnessDeviation
+	fairness fairnessConfig
+	// fairnessAlert is the state of the alert, kept across the sessions
+	fairnessAlert fairnessAlert
+	// deservedKey and deserved are the hash of the inputs and the deserved resources of the queues
+	// computed last, they are reused while the inputs don't change, see deservedKey
+	deservedKey uint64
+	deserved    map[api.QueueID]*api.Resource
+	// overQuota remembers the monitored queues which went beyond their capability, so that they
+	// are reported once when they go beyond it and once when they are back within it
+	overQuota map[api.QueueID]bool
+
 	// Arguments given for the plugin
 	pluginArguments framework.Arguments
 }
@@ -47,6 +85,8 @@ type queueAttr struct {
 	name    string
 	weight  int32
 	share   float64
+	// pool is the node pool the queue is bound to
+	pool string
 
 	deserved  *api.Resource
 	allocated *api.Resource
@@ -59,25 +99,43 @@ type queueAttr struct {
 	// realCapability represents the resource limit of the queue, LessEqual capability
 	realCapability *api.Resource
 	guarantee      *api.Resource
+	// quota is the capability of a queue whose quota is only monitored, which is not enforced
+	quota *api.Resource
 }
 
 // New return proportion action
 func New(arguments framework.Arguments) framework.Plugin {
-	return &proportionPlugin{
-		totalResource:   api.EmptyResource(),
-		totalGuarantee:  api.EmptyResource(),
-		queueOpts:       map[api.QueueID]*queueAttr{},
-		pluginArguments: arguments,
+	pp := &proportionPlugin{
+		totalResource:      api.EmptyResource(),
+		totalGuarantee:     api.EmptyResource(),
+		queueOpts:          map[api.QueueID]*queueAttr{},
+		nodePools:          parseNodePools(arguments),
+		fairness:           parseFairnessConfig(arguments),
+		rebalanceEvictions: defaultRebalanceEvictions,
+		overQuota:          map[api.QueueID]bool{},
+		pluginArguments:    arguments,
 	}
+	arguments.GetBool(&pp.rebalance, rebalanceArgument)
+	arguments.GetInt(&pp.rebalanceEvictions, rebalanceEvictionsArgument)
+	return pp
 }
 
 func (pp *proportionPlugin) Name() string {
 	return PluginName
 }
 
+// Stateful marks the plugin as reused across the sessions, it remembers the total resource of
+// the cluster and the quotas of the queues to rebalance them when they change, and the monitored
+// queues beyond their capability to report them once.
+func (pp *proportionPlugin) Stateful() {}
+
 func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 	// Prepare scheduling data for this session.
+	pp.totalResource = api.EmptyResource()
+	pp.totalGuarantee = api.EmptyResource()
+	pp.queueOpts = map[api.QueueID]*queueAttr{}
 	pp.totalResource.Add(ssn.TotalResource)
+	pp.buildNodePools(ssn)
 
 	klog.V(4).Infof("The total resource is <%v>", pp.totalResource)
 	for _, queue := range ssn.Queues {
@@ -86,17 +144,28 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		}
 		guarantee := api.NewResource(queue.Queue.Spec.Guarantee.Resource)
 		pp.totalGuarantee.Add(guarantee)
+		pp.poolGuarantees[pp.queueNodePool(queue)].Add(guarantee)
 	}
 	klog.V(4).Infof("The total guarantee resource is <%v>", pp.totalGuarantee)
-	// Build attributes for Queues.
-	for _, job := range ssn.Jobs {
+	now := time.Now()
+	pp.reservations = map[api.QueueID]map[string]*api.Resource{}
+	for _, queue := range ssn.Queues {
+		if outstanding := quota.Outstanding(queue, ssn.SnapshotJobs(), now); len(outstanding) != 0 {
+			pp.reservations[queue.UID] = outstanding
+		}
+	}
+	// Build attributes for Queues, from the jobs of all the profiles as they share the queues.
+	for _, job := range ssn.SnapshotJobs() {
 		klog.V(4).Infof("Considering Job <%s/%s>.", job.Namespace, job.Name)
 		if _, found := pp.queueOpts[job.Queue]; !found {
 			queue := ssn.Queues[job.Queue]
+			// the active window of the quota schedule overrides weight and capability of the queue
+			weight, capability := queue.QuotaAt(now)
 			attr := &queueAttr{
 				queueID: queue.UID,
 				name:    queue.Name,
-				weight:  queue.Weight,
+				weight:  weight,
+				pool:    pp.queueNodePool(queue),
 
 				deserved:  api.EmptyResource(),
 				allocated: api.EmptyResource(),
@@ -105,25 +174,32 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 				inqueue:   api.EmptyResource(),
 				guarantee: api.EmptyResource(),
 			}
-			if len(queue.Queue.Spec.Capability) != 0 {
-				attr.capability = api.NewResource(queue.Queue.Spec.Capability)
+			if len(capability) != 0 {
+				attr.capability = api.NewResource(capability)
 				if attr.capability.MilliCPU <= 0 {
 					attr.capability.MilliCPU = math.MaxFloat64
 				}
 				if attr.capability.Memory <= 0 {
 					attr.capability.Memory = math.MaxFloat64
 				}
+				if quotaMonitored(queue) {
+					attr.quota, attr.capability = attr.capability, nil
+				}
 			}
 			if len(queue.Queue.Spec.Guarantee.Resource) != 0 {
 				attr.guarantee = api.NewResource(queue.Queue.Spec.Guarantee.Resource)
 			}
-			realCapability := pp.totalResource.Clone().Sub(pp.totalGuarantee).Add(attr.guarantee)
+			realCapability := pp.poolTotals[attr.pool].Clone().Sub(pp.poolGuarantees[attr.pool]).Add(attr.guarantee)
 			if attr.capability == nil {
 				attr.realCapability = realCapability
 			} else {
 				realCapability.MinDimensionResource(attr.capability, api.Infinity)
 				attr.realCapability = realCapability
 			}
+			if bound := queue.IsolationBound(); bound != nil {
+				attr.realCapability.MinDimensionResource(bound, api.Infinity)
+				klog.V(4).Infof("Queue <%s> is strictly isolated within <%v>", queue.Name, attr.realCapability)
+			}
 			pp.queueOpts[job.Queue] = attr
 			klog.V(4).Infof("Added Queue <%s> attributes.", job.Queue)
 		}
@@ -132,12 +208,12 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		for status, tasks := range job.TaskStatusIndex {
 			if api.AllocatedStatus(status) {
 				for _, t := range tasks {
-					attr.allocated.Add(t.Resreq)
-					attr.request.Add(t.Resreq)
+					attr.allocated.Add(t.ShareResreq())
+					attr.request.Add(t.ShareResreq())
 				}
 			} else if status == api.Pending {
 				for _, t := range tasks {
-					attr.request.Add(t.Resreq)
+					attr.request.Add(t.ShareResreq())
 				}
 			}
 		}
@@ -182,73 +258,50 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		metrics.UpdateQueuePodGroupUnknownCount(queueInfo.Name, 0)
 	}
 
-	remaining := pp.totalResource.Clone()
-	meet := map[api.QueueID]struct{}{}
-	for {
-		totalWeight := int32(0)
-		for _, attr := range pp.queueOpts {
-			if _, found := meet[attr.queueID]; found {
-				continue
-			}
-			totalWeight += attr.weight
-		}
+	quotasChanged := pp.recordQuotaChanges()
 
-		// If no queues, break
-		if totalWeight == 0 {
-			klog.V(4).Infof("Exiting when total weight is 0")
-			break
+	// deserved resources only change with the cluster resource and the queues' requests, so they
+	// are reused across sessions while neither changes
+	key := deservedKey(pp.poolTotals, pp.queueOpts)
+	if deserved, found := pp.cachedDeserved(key); found {
+		for queueID, attr := range pp.queueOpts {
+			attr.deserved = deserved[queueID].Clone()
+			pp.updateShare(attr)
+			metrics.UpdateQueueDeserved(attr.name, attr.deserved.MilliCPU, attr.deserved.Memory)
+		}
+		klog.V(4).Infof("Reused the deserved resource of %d queues", len(deserved))
+	} else {
+		for pool, total := range pp.poolTotals {
+			pp.calculateDeserved(pool, total)
 		}
+		pp.cacheDeserved(key)
+	}
 
-		oldRemaining := remaining.Clone()
-		// Calculates the deserved of each Queue.
-		// increasedDeserved is the increased value for attr.deserved of processed queues
-		// decreasedDeserved is the decreased value for attr.deserved of processed queues
-		increasedDeserved := api.EmptyResource()
-		decreasedDeserved := api.EmptyResource()
-		for _, attr := range pp.queueOpts {
-			klog.V(4).Infof("Considering Queue <%s>: weight <%d>, total weight <%d>.",
-				attr.name, attr.weight, totalWeight)
-			if _, found := meet[attr.queueID]; found {
-				continue
+	if len(pp.nodePools) != 0 {
+		ssn.AddPredicateFn(pp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+			job, found := ssn.Jobs[task.Job]
+			if !found {
+				return nil
 			}
-
-			oldDeserved := attr.deserved.Clone()
-			attr.deserved.Add(remaining.Clone().Multi(float64(attr.weight) / float64(totalWeight)))
-
-			if attr.realCapability != nil {
-				attr.deserved.MinDimensionResource(attr.realCapability, api.Infinity)
+			attr, found := pp.queueOpts[job.Queue]
+			if !found {
+				return nil
 			}
-			attr.deserved.MinDimensionResource(attr.request, api.Zero)
-
-			attr.deserved = helpers.Max(attr.deserved, attr.guarantee)
-			pp.updateShare(attr)
-			klog.V(4).Infof("Format queue <%s> deserved resource to <%v>", attr.name, attr.deserved)
-
-			if attr.request.LessEqual(attr.deserved, api.Zero) {
-				meet[attr.queueID] = struct{}{}
-				klog.V(4).Infof("queue <%s> is meet", attr.name)
-			} else if equality.Semantic.DeepEqual(attr.deserved, oldDeserved) {
-				meet[attr.queueID] = struct{}{}
-				klog.V(4).Infof("queue <%s> is meet cause of the capability", attr.name)
+			if pool := pp.nodePoolOf[node.Name]; pool != attr.pool {
+				return api.NewFitError(task, node, fmt.Sprintf("node is in node pool <%s> while queue <%s> is bound to <%s>",
+					pool, attr.name, attr.pool))
 			}
+			return nil
+		})
+	}
 
-			klog.V(4).Infof("The attributes of queue <%s> in proportion: deserved <%v>, realCapability <%v>, allocate <%v>, request <%v>, elastic <%v>, share <%0.2f>",
-				attr.name, attr.deserved, attr.realCapability, attr.allocated, attr.request, attr.elastic, attr.share)
-
-			increased, decreased := attr.deserved.Diff(oldDeserved, api.Zero)
-			increasedDeserved.Add(increased)
-			decreasedDeserved.Add(decreased)
-
-			// Record metrics
-			metrics.UpdateQueueDeserved(attr.name, attr.deserved.MilliCPU, attr.deserved.Memory)
-		}
-
-		remaining.Sub(increasedDeserved).Add(decreasedDeserved)
-		klog.V(4).Infof("Remaining resource is  <%s>", remaining)
-		if remaining.IsEmpty() || equality.Semantic.DeepEqual(remaining, oldRemaining) {
-			klog.V(4).Infof("Exiting when remaining is empty or no queue has more resource request:  <%v>", remaining)
-			break
+	if pp.rebalance {
+		if pp.recordClusterTotal(pp.totalResource) || quotasChanged {
+			pp.rebalancing = true
 		}
+		ssn.AddVictimTasksFns(pp.Name(), []api.VictimTasksFn{func(_ []*api.TaskInfo) []*api.TaskInfo {
+			return pp.rebalanceVictims(ssn)
+		}})
 	}
 
 	ssn.AddQueueOrderFn(pp.Name(), func(l, r interface{}) int {
@@ -283,14 +336,14 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 				allocations[job.Queue] = attr.allocated.Clone()
 			}
 			allocated := allocations[job.Queue]
-			if allocated.LessPartly(reclaimer.Resreq, api.Zero) {
+			if allocated.LessPartly(reclaimer.ShareResreq(), api.Zero) {
 				klog.V(3).Infof("Failed to allocate resource for Task <%s/%s> in Queue <%s>, not enough resource.",
 					reclaimee.Namespace, reclaimee.Name, job.Queue)
 				continue
 			}
 
 			if !allocated.LessEqual(attr.deserved, api.Zero) {
-				allocated.Sub(reclaimee.Resreq)
+				allocated.Sub(reclaimee.ShareResreq())
 				victims = append(victims, reclaimee)
 			}
 		}
@@ -315,11 +368,11 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 	ssn.AddAllocatableFn(pp.Name(), func(queue *api.QueueInfo, candidate *api.TaskInfo) bool {
 		attr := pp.queueOpts[queue.UID]
 
-		futureUsed := attr.allocated.Clone().Add(candidate.Resreq)
-		allocatable := futureUsed.LessEqualWithDimension(attr.deserved, candidate.Resreq)
+		futureUsed := attr.allocated.Clone().Add(candidate.ShareResreq())
+		allocatable := futureUsed.LessEqualWithDimension(attr.deserved, candidate.ShareResreq())
 		if !allocatable {
 			klog.V(3).Infof("Queue <%v>: deserved <%v>, allocated <%v>; Candidate <%v>: resource request <%v>",
-				queue.Name, attr.deserved, attr.allocated, candidate.Name, candidate.Resreq)
+				queue.Name, attr.deserved, attr.allocated, candidate.Name, candidate.ShareResreq())
 		}
 
 		return allocatable
@@ -345,12 +398,18 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 
 		klog.V(5).Infof("job %s min resource <%s>, queue %s capability <%s> allocated <%s> inqueue <%s> elastic <%s>",
 			job.Name, minReq.String(), queue.Name, attr.realCapability.String(), attr.allocated.String(), attr.inqueue.String(), attr.elastic.String())
-		// The queue resource quota limit has not reached
-		r := minReq.Clone().Add(attr.allocated).Add(attr.inqueue).Sub(attr.elastic)
+		// The queue resource quota limit has not reached, the quota reserved for the other jobs
+		// is kept from the job
+		r := minReq.Clone().Add(attr.allocated).Add(attr.inqueue).Sub(attr.elastic).Add(pp.reservedFor(job))
 
 		inqueue := r.LessEqualWithDimension(attr.realCapability, minReq)
 		klog.V(5).Infof("job %s inqueue %v", job.Name, inqueue)
 		if inqueue {
+			if attr.quota != nil {
+				if exceeded := exceededResources(r, attr.quota); len(exceeded) != 0 {
+					recordQuotaExceeded(ssn, job, attr, exceeded)
+				}
+			}
 			// deduct the resources of scheduling gated tasks in a job when calculating inqueued resources
 			// so that it will not block other jobs from being inqueued.
 			attr.inqueue.Add(job.DeductSchGatedResources(minReq))
@@ -365,32 +424,128 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		AllocateFunc: func(event *framework.Event) {
 			job := ssn.Jobs[event.Task.Job]
 			attr := pp.queueOpts[job.Queue]
-			attr.allocated.Add(event.Task.Resreq)
+			attr.allocated.Add(event.Task.ShareResreq())
 			metrics.UpdateQueueAllocated(attr.name, attr.allocated.MilliCPU, attr.allocated.Memory)
 
 			pp.updateShare(attr)
 
 			klog.V(4).Infof("Proportion AllocateFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
+				event.Task.Namespace, event.Task.Name, event.Task.ShareResreq(), attr.share)
 		},
 		DeallocateFunc: func(event *framework.Event) {
 			job := ssn.Jobs[event.Task.Job]
 			attr := pp.queueOpts[job.Queue]
-			attr.allocated.Sub(event.Task.Resreq)
+			attr.allocated.Sub(event.Task.ShareResreq())
 			metrics.UpdateQueueAllocated(attr.name, attr.allocated.MilliCPU, attr.allocated.Memory)
 
 			pp.updateShare(attr)
 
 			klog.V(4).Infof("Proportion EvictFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
+				event.Task.Namespace, event.Task.Name, event.Task.ShareResreq(), attr.share)
 		},
 	})
 }
 
 func (pp *proportionPlugin) OnSessionClose(ssn *framework.Session) {
+	pp.checkFairness(ssn)
+	pp.checkQuotas(ssn)
 	pp.totalResource = nil
 	pp.totalGuarantee = nil
 	pp.queueOpts = nil
+	pp.poolTotals = nil
+	pp.poolGuarantees = nil
+	pp.nodePoolOf = nil
+	pp.reservations = nil
+}
+
+// reservedFor returns the quota reserved in the queue of the job for the other jobs.
+func (pp *proportionPlugin) reservedFor(job *api.JobInfo) *api.Resource {
+	reserved := api.EmptyResource()
+	own := job.PodGroup.Annotations[quota.ReservationAnnotationKey]
+	for id, resources := range pp.reservations[job.Queue] {
+		if id != own {
+			reserved.Add(resources)
+		}
+	}
+	return reserved
+}
+
+// calculateDeserved divides the total resource of a node pool between the queues bound to it by
+// weight, within their capability and request.
+func (pp *proportionPlugin) calculateDeserved(pool string, total *api.Resource) {
+	remaining := total.Clone()
+	meet := map[api.QueueID]struct{}{}
+	for _, attr := range pp.queueOpts {
+		if attr.pool != pool {
+			meet[attr.queueID] = struct{}{}
+		}
+	}
+	for {
+		totalWeight := int32(0)
+		for _, attr := range pp.queueOpts {
+			if _, found := meet[attr.queueID]; found {
+				continue
+			}
+			totalWeight += attr.weight
+		}
+
+		// If no queues, break
+		if totalWeight == 0 {
+			klog.V(4).Infof("Exiting when total weight is 0")
+			break
+		}
+
+		oldRemaining := remaining.Clone()
+		// Calculates the deserved of each Queue.
+		// increasedDeserved is the increased value for attr.deserved of processed queues
+		// decreasedDeserved is the decreased value for attr.deserved of processed queues
+		increasedDeserved := api.EmptyResource()
+		decreasedDeserved := api.EmptyResource()
+		for _, attr := range pp.queueOpts {
+			klog.V(4).Infof("Considering Queue <%s>: weight <%d>, total weight <%d>.",
+				attr.name, attr.weight, totalWeight)
+			if _, found := meet[attr.queueID]; found {
+				continue
+			}
+
+			oldDeserved := attr.deserved.Clone()
+			attr.deserved.Add(remaining.Clone().Multi(float64(attr.weight) / float64(totalWeight)))
+
+			if attr.realCapability != nil {
+				attr.deserved.MinDimensionResource(attr.realCapability, api.Infinity)
+			}
+			attr.deserved.MinDimensionResource(attr.request, api.Zero)
+
+			attr.deserved = helpers.Max(attr.deserved, attr.guarantee)
+			pp.updateShare(attr)
+			klog.V(4).Infof("Format queue <%s> deserved resource to <%v>", attr.name, attr.deserved)
+
+			if attr.request.LessEqual(attr.deserved, api.Zero) {
+				meet[attr.queueID] = struct{}{}
+				klog.V(4).Infof("queue <%s> is meet", attr.name)
+			} else if equality.Semantic.DeepEqual(attr.deserved, oldDeserved) {
+				meet[attr.queueID] = struct{}{}
+				klog.V(4).Infof("queue <%s> is meet cause of the capability", attr.name)
+			}
+
+			klog.V(4).Infof("The attributes of queue <%s> in proportion: deserved <%v>, realCapability <%v>, allocate <%v>, request <%v>, elastic <%v>, share <%0.2f>",
+				attr.name, attr.deserved, attr.realCapability, attr.allocated, attr.request, attr.elastic, attr.share)
+
+			increased, decreased := attr.deserved.Diff(oldDeserved, api.Zero)
+			increasedDeserved.Add(increased)
+			decreasedDeserved.Add(decreased)
+
+			// Record metrics
+			metrics.UpdateQueueDeserved(attr.name, attr.deserved.MilliCPU, attr.deserved.Memory)
+		}
+
+		remaining.Sub(increasedDeserved).Add(decreasedDeserved)
+		klog.V(4).Infof("Remaining resource is  <%s>", remaining)
+		if remaining.IsEmpty() || equality.Semantic.DeepEqual(remaining, oldRemaining) {
+			klog.V(4).Infof("Exiting when remaining is empty or no queue has more resource request:  <%v>", remaining)
+			break
+		}
+	}
 }
 
 func (pp *proportionPlugin) updateShare(attr *queueAttr) {
@@ -15,8 +15,10 @@ package proportion
 
 import (
 	"io"
+	"math"
 	"net/http"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -29,10 +31,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 
+	"volcano.sh/apis/pkg/apis/scheduling"
 	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	"volcano.sh/volcano/cmd/scheduler/app/options"
 	"volcano.sh/volcano/pkg/scheduler/actions/allocate"
 	"volcano.sh/volcano/pkg/scheduler/actions/enqueue"
+	"volcano.sh/volcano/pkg/scheduler/actions/preempt"
 	"volcano.sh/volcano/pkg/scheduler/actions/reclaim"
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/cache"
@@ -40,6 +44,8 @@ import (
 	"volcano.sh/volcano/pkg/scheduler/framework"
 	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
 	"volcano.sh/volcano/pkg/scheduler/plugins/priority"
+	"volcano.sh/volcano/pkg/scheduler/quota"
+	"volcano.sh/volcano/pkg/scheduler/stats"
 	"volcano.sh/volcano/pkg/scheduler/uthelper"
 	"volcano.sh/volcano/pkg/scheduler/util"
 )
@@ -493,3 +499,442 @@ func TestAllocate(t *testing.T) {
 		})
 	}
 }
+
+func TestRebalance(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{PluginName: New, priority.PluginName: priority.New}
+	trueValue := true
+
+	n1 := util.BuildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string))
+
+	high, middle, low := int32(10), int32(5), int32(1)
+	preemptable := map[string]string{schedulingv1beta1.PodPreemptable: "true"}
+	p1 := util.BuildPodWithPriority("ns1", "p1", "n1", apiv1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", preemptable, make(map[string]string), &high)
+	p2 := util.BuildPodWithPriority("ns1", "p2", "n1", apiv1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", preemptable, make(map[string]string), &middle)
+	p3 := util.BuildPodWithPriority("ns1", "p3", "n1", apiv1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", preemptable, make(map[string]string), &low)
+
+	pg1 := util.BuildPodGroup("pg1", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning)
+
+	// the capability of the queue was lowered under its allocated resource
+	q1 := util.BuildQueueWithPriorityAndResourcesQuantity("q1", 1, nil, api.BuildResourceList("1", "8Gi"))
+
+	tests := []struct {
+		uthelper.TestCommonStruct
+		arguments framework.Arguments
+	}{
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:           "rebalance is disabled by default",
+				Plugins:        plugins,
+				Pods:           []*apiv1.Pod{p1, p2, p3},
+				Nodes:          []*apiv1.Node{n1},
+				PodGroups:      []*schedulingv1beta1.PodGroup{pg1},
+				Queues:         []*schedulingv1beta1.Queue{q1},
+				ExpectEvictNum: 0,
+			},
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:           "one task of the lowest priority is evicted per session",
+				Plugins:        plugins,
+				Pods:           []*apiv1.Pod{p1, p2, p3},
+				Nodes:          []*apiv1.Node{n1},
+				PodGroups:      []*schedulingv1beta1.PodGroup{pg1},
+				Queues:         []*schedulingv1beta1.Queue{q1},
+				ExpectEvicted:  []string{"ns1/p3"},
+				ExpectEvictNum: 1,
+			},
+			arguments: framework.Arguments{rebalanceArgument: true},
+		},
+		{
+			TestCommonStruct: uthelper.TestCommonStruct{
+				Name:           "no more tasks than needed to go back under the capability are evicted",
+				Plugins:        plugins,
+				Pods:           []*apiv1.Pod{p1, p2, p3},
+				Nodes:          []*apiv1.Node{n1},
+				PodGroups:      []*schedulingv1beta1.PodGroup{pg1},
+				Queues:         []*schedulingv1beta1.Queue{q1},
+				ExpectEvicted:  []string{"ns1/p3", "ns1/p2"},
+				ExpectEvictNum: 2,
+			},
+			arguments: framework.Arguments{rebalanceArgument: true, rebalanceEvictionsArgument: 5},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			tiers := []conf.Tier{
+				{
+					Plugins: []conf.PluginOption{
+						{
+							Name:             priority.PluginName,
+							EnabledTaskOrder: &trueValue,
+						},
+						{
+							Name:              PluginName,
+							EnabledQueueOrder: &trueValue,
+							EnabledVictim:     &trueValue,
+							Arguments:         test.arguments,
+						},
+					},
+				},
+			}
+			test.RegisterSession(tiers, nil)
+			defer test.Close()
+			test.Run([]framework.Action{preempt.New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDeservedKey(t *testing.T) {
+	buildQueueOpts := func(request string) map[api.QueueID]*queueAttr {
+		return map[api.QueueID]*queueAttr{
+			"q1": {queueID: "q1", weight: 1, guarantee: api.EmptyResource(), request: api.NewResource(api.BuildResourceList(request, "1Gi"))},
+			"q2": {queueID: "q2", weight: 2, guarantee: api.EmptyResource(), request: api.NewResource(api.BuildResourceList("2", "2Gi", []api.ScalarResource{{Name: "nvidia.com/gpu", Value: "1"}}...))},
+		}
+	}
+	totals := func(cpu string) map[string]*api.Resource {
+		return map[string]*api.Resource{defaultNodePool: api.NewResource(api.BuildResourceList(cpu, "16Gi"))}
+	}
+
+	key := deservedKey(totals("8"), buildQueueOpts("1"))
+	if key != deservedKey(totals("8"), buildQueueOpts("1")) {
+		t.Errorf("expected the same key for the same inputs")
+	}
+	if key == deservedKey(totals("8"), buildQueueOpts("2")) {
+		t.Errorf("expected another key when the request of a queue changes")
+	}
+	if key == deservedKey(totals("4"), buildQueueOpts("1")) {
+		t.Errorf("expected another key when the total resource changes")
+	}
+	movedOpts := buildQueueOpts("1")
+	movedOpts["q2"].pool = "gpu"
+	if key == deservedKey(totals("8"), movedOpts) {
+		t.Errorf("expected another key when a queue moves to another node pool")
+	}
+
+	queueOpts := buildQueueOpts("1")
+	queueOpts["q1"].deserved = api.NewResource(api.BuildResourceList("1", "1Gi"))
+	queueOpts["q2"].deserved = api.NewResource(api.BuildResourceList("2", "2Gi"))
+	pp := &proportionPlugin{queueOpts: queueOpts}
+	pp.cacheDeserved(key)
+	deserved, found := pp.cachedDeserved(key)
+	if !found || !deserved["q1"].Equal(queueOpts["q1"].deserved, api.Zero) {
+		t.Errorf("expected the cached deserved resource of q1, got %v", deserved["q1"])
+	}
+	if _, found := pp.cachedDeserved(deservedKey(totals("4"), buildQueueOpts("1"))); found {
+		t.Errorf("expected no cached deserved resources for other inputs")
+	}
+	// the plugins of the other profiles keep their own results
+	if _, found := (&proportionPlugin{}).cachedDeserved(key); found {
+		t.Errorf("expected the cached deserved resources not to be shared between the plugins")
+	}
+}
+
+func TestNodePoolDeserved(t *testing.T) {
+	arguments := framework.Arguments{
+		nodePoolsArgument: map[interface{}]interface{}{
+			"gpu": "pool=gpu",
+		},
+	}
+	pp := New(arguments).(*proportionPlugin)
+	if len(pp.nodePools) != 1 || pp.nodePools[0].name != "gpu" {
+		t.Fatalf("expected the node pool gpu, got %v", pp.nodePools)
+	}
+
+	ssn := &framework.Session{
+		Nodes: map[string]*api.NodeInfo{
+			"n1": api.NewNodeInfo(util.BuildNode("n1", api.BuildResourceList("8", "8Gi"), map[string]string{"pool": "gpu"})),
+			"n2": api.NewNodeInfo(util.BuildNode("n2", api.BuildResourceList("4", "4Gi"), map[string]string{})),
+		},
+	}
+	pp.buildNodePools(ssn)
+	if pp.nodePoolOf["n1"] != "gpu" || pp.nodePoolOf["n2"] != defaultNodePool {
+		t.Fatalf("unexpected node pools of nodes: %v", pp.nodePoolOf)
+	}
+
+	request := api.NewResource(api.BuildResourceList("16", "16Gi"))
+	for _, q := range []struct {
+		id   api.QueueID
+		pool string
+	}{{"q1", "gpu"}, {"q2", "gpu"}, {"q3", defaultNodePool}} {
+		pp.queueOpts[q.id] = &queueAttr{queueID: q.id, name: string(q.id), weight: 1, pool: q.pool,
+			deserved: api.EmptyResource(), allocated: api.EmptyResource(), guarantee: api.EmptyResource(), request: request.Clone()}
+	}
+	for pool, total := range pp.poolTotals {
+		pp.calculateDeserved(pool, total)
+	}
+
+	expected := map[api.QueueID]*api.Resource{
+		"q1": api.NewResource(api.BuildResourceList("4", "4Gi")),
+		"q2": api.NewResource(api.BuildResourceList("4", "4Gi")),
+		"q3": api.NewResource(api.BuildResourceList("4", "4Gi")),
+	}
+	for id, want := range expected {
+		if got := pp.queueOpts[id].deserved; !got.Equal(want, api.Zero) {
+			t.Errorf("expected the deserved resource of %s to be %v, got %v", id, want, got)
+		}
+	}
+}
+
+func TestRecordClusterTotal(t *testing.T) {
+	pp := New(framework.Arguments{}).(*proportionPlugin)
+
+	large := api.NewResource(api.BuildResourceList("8", "16Gi"))
+	small := api.NewResource(api.BuildResourceList("4", "16Gi"))
+
+	if pp.recordClusterTotal(large) {
+		t.Errorf("expected no shrinking in the first session")
+	}
+	if !pp.recordClusterTotal(small) {
+		t.Errorf("expected shrinking once nodes were removed")
+	}
+	if pp.recordClusterTotal(large) {
+		t.Errorf("expected no shrinking once nodes were added back")
+	}
+}
+
+func TestRecordQuotaChanges(t *testing.T) {
+	pp := New(framework.Arguments{}).(*proportionPlugin)
+
+	pp.queueOpts = map[api.QueueID]*queueAttr{"q1": {queueID: "q1", name: "q1", weight: 1}}
+	if pp.recordQuotaChanges() {
+		t.Errorf("expected no change in the first session")
+	}
+	pp.queueOpts = map[api.QueueID]*queueAttr{"q1": {queueID: "q1", name: "q1", weight: 2}}
+	if !pp.recordQuotaChanges() {
+		t.Errorf("expected a change once the weight of the queue was raised")
+	}
+	pp.queueOpts = map[api.QueueID]*queueAttr{"q1": {queueID: "q1", name: "q1", weight: 2,
+		capability: api.NewResource(api.BuildResourceList("4", "4Gi"))}}
+	if !pp.recordQuotaChanges() {
+		t.Errorf("expected a change once the capability of the queue was set")
+	}
+	if pp.recordQuotaChanges() {
+		t.Errorf("expected no change while the quotas of the queue are unchanged")
+	}
+}
+
+func TestReservedFor(t *testing.T) {
+	pp := New(framework.Arguments{}).(*proportionPlugin)
+	pp.reservations = map[api.QueueID]map[string]*api.Resource{
+		"q1": {
+			"r1": api.NewResource(api.BuildResourceList("2", "2Gi")),
+			"r2": api.NewResource(api.BuildResourceList("4", "4Gi")),
+		},
+	}
+	newJob := func(queue api.QueueID, annotations map[string]string) *api.JobInfo {
+		return &api.JobInfo{Queue: queue, PodGroup: &api.PodGroup{PodGroup: scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+		}}}
+	}
+
+	if got := pp.reservedFor(newJob("q1", nil)); !got.Equal(api.NewResource(api.BuildResourceList("6", "6Gi")), api.Zero) {
+		t.Errorf("expected the job without reservation to leave 6 cpus to the reservations, got %v", got)
+	}
+	own := newJob("q1", map[string]string{quota.ReservationAnnotationKey: "r2"})
+	if got := pp.reservedFor(own); !got.Equal(api.NewResource(api.BuildResourceList("2", "2Gi")), api.Zero) {
+		t.Errorf("expected the job of r2 to leave the quota of r1 only, got %v", got)
+	}
+	if got := pp.reservedFor(newJob("q2", nil)); !got.IsEmpty() {
+		t.Errorf("expected no quota reserved in q2, got %v", got)
+	}
+}
+
+func TestFairnessDeviation(t *testing.T) {
+	owner := util.BuildPod("ns1", "p0", "", apiv1.PodPending, api.BuildResourceList("1", "1Gi"), "pg2", nil, nil)
+	pending := api.NewJobInfo("pg2", api.NewTaskInfo(owner))
+	pending.Queue = "q2"
+	pp := &proportionPlugin{
+		totalResource: api.NewResource(api.BuildResourceList("10", "10Gi")),
+		queueOpts: map[api.QueueID]*queueAttr{
+			"q1": {name: "q1", weight: 1, allocated: api.NewResource(api.BuildResourceList("6", "6Gi"))},
+			"q2": {name: "q2", weight: 1, allocated: api.NewResource(api.BuildResourceList("2", "2Gi"))},
+			"q3": {name: "q3", weight: 2, allocated: api.EmptyResource()},
+		},
+	}
+	ssn := &framework.Session{Jobs: map[api.JobID]*api.JobInfo{pending.UID: pending}}
+	// q3 has no demand, q1 and q2 share the allocated resources 3:1 with equal weights
+	queues := pp.queueStats(ssn)
+	if got := stats.FairnessDeviation(queues); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("expected a deviation of 0.25, got %v", got)
+	}
+	if queues["q2"].PendingTasks != 1 || queues["q3"].WeightShare != 0 {
+		t.Errorf("expected the pending tasks of q2 and no weight share for q3, got %+v and %+v", queues["q2"], queues["q3"])
+	}
+
+	alert := &fairnessAlert{}
+	cfg := fairnessConfig{threshold: 0.2, duration: 5 * time.Minute}
+	start := time.Now()
+	for _, step := range []struct {
+		deviation float64
+		after     time.Duration
+		status    string
+	}{
+		{deviation: 0.3, after: 0},
+		{deviation: 0.3, after: 4 * time.Minute},
+		{deviation: 0.3, after: 5 * time.Minute, status: fairnessFiring},
+		{deviation: 0.3, after: 6 * time.Minute},
+		{deviation: 0.1, after: 7 * time.Minute, status: fairnessResolved},
+		{deviation: 0.1, after: 8 * time.Minute},
+		{deviation: 0.3, after: 9 * time.Minute},
+		{deviation: 0.3, after: 13 * time.Minute},
+	} {
+		if status, _ := alert.observe(step.deviation, cfg, start.Add(step.after)); status != step.status {
+			t.Errorf("after %v at deviation %v: expected status %q, got %q", step.after, step.deviation, step.status, status)
+		}
+	}
+}
+
+func TestStrictIsolation(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{PluginName: New}
+	trueValue := true
+	actions := []framework.Action{allocate.New()}
+
+	n1 := util.BuildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string))
+
+	// the strict queue q1 gets only p1 within its deserved resource though its weight entitles it
+	// to p2 too, the elastic queue q2 borrows beyond its deserved resource
+	p1 := util.BuildPod("ns1", "p1", "", apiv1.PodPending, api.BuildResourceList("1", "2Gi"), "pg1", make(map[string]string), make(map[string]string))
+	p2 := util.BuildPod("ns1", "p2", "", apiv1.PodPending, api.BuildResourceList("2", "2Gi"), "pg2", make(map[string]string), make(map[string]string))
+	p3 := util.BuildPod("ns1", "p3", "", apiv1.PodPending, api.BuildResourceList("1", "2Gi"), "pg3", make(map[string]string), make(map[string]string))
+	p4 := util.BuildPod("ns1", "p4", "", apiv1.PodPending, api.BuildResourceList("1", "2Gi"), "pg4", make(map[string]string), make(map[string]string))
+
+	pg1 := util.BuildPodGroup("pg1", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	pg2 := util.BuildPodGroup("pg2", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	pg3 := util.BuildPodGroup("pg3", "ns1", "q2", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	pg4 := util.BuildPodGroup("pg4", "ns1", "q2", 1, nil, schedulingv1beta1.PodGroupInqueue)
+
+	queue1 := util.BuildQueueWithResourcesQuantity("q1", api.BuildResourceList("1", "2Gi"), nil)
+	queue1.Spec.Weight = 3
+	queue1.Annotations = map[string]string{api.QueueIsolation: api.QueueIsolationStrict}
+	queue2 := util.BuildQueueWithResourcesQuantity("q2", api.BuildResourceList("1", "2Gi"), nil)
+
+	tests := []uthelper.TestCommonStruct{
+		{
+			Name:      "strict and elastic queues",
+			Plugins:   plugins,
+			Pods:      []*apiv1.Pod{p1, p2, p3, p4},
+			Nodes:     []*apiv1.Node{n1},
+			PodGroups: []*schedulingv1beta1.PodGroup{pg1, pg2, pg3, pg4},
+			Queues:    []*schedulingv1beta1.Queue{queue1, queue2},
+			ExpectBindMap: map[string]string{
+				"ns1/p1": "n1",
+				"ns1/p3": "n1",
+				"ns1/p4": "n1",
+			},
+			ExpectBindsNum: 3,
+		},
+	}
+
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               PluginName,
+					EnabledQueueOrder:  &trueValue,
+					EnabledAllocatable: &trueValue,
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.RegisterSession(tiers, nil)
+			defer test.Close()
+			test.Run(actions)
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestProfilesShareQueue(t *testing.T) {
+	uthelper.RegisterPlugins(map[string]framework.PluginBuilder{PluginName: New})
+	defer framework.CleanupPluginBuilders()
+
+	n1 := util.BuildNode("n1", api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})
+	// p1 is placed by the session of the top level configuration, p2 by the one of the profile,
+	// both in q1
+	p1 := util.BuildPod("ns1", "p1", "n1", apiv1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", map[string]string{}, map[string]string{})
+	p2 := util.BuildPod("ns1", "p2", "n1", apiv1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg2", map[string]string{}, map[string]string{})
+	p3 := util.BuildPod("ns1", "p3", "", apiv1.PodPending, api.BuildResourceList("1", "1Gi"), "pg3", map[string]string{}, map[string]string{})
+	p2.Spec.SchedulerName = "profile-b"
+
+	pg1 := util.BuildPodGroup("pg1", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning)
+	pg2 := util.BuildPodGroup("pg2", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning)
+	pg3 := util.BuildPodGroup("pg3", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue)
+	queue1 := util.BuildQueue("q1", 1, api.BuildResourceList("2", "2Gi", []api.ScalarResource{{Name: "pods", Value: "2"}}...))
+
+	schedulerCache := cache.NewCustomMockSchedulerCache("mock-test", util.NewFakeBinder(0), nil, &util.FakeStatusUpdater{}, nil, &util.FakeVolumeBinder{}, record.NewFakeRecorder(100))
+	schedulerCache.AddSchedulerNames("profile-b")
+	schedulerCache.AddOrUpdateNode(n1)
+	for _, pod := range []*apiv1.Pod{p1, p2, p3} {
+		schedulerCache.AddPod(pod)
+	}
+	for _, pg := range []*schedulingv1beta1.PodGroup{pg1, pg2, pg3} {
+		schedulerCache.AddPodGroupV1beta1(pg)
+	}
+	schedulerCache.AddQueueV1beta1(queue1)
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               PluginName,
+					EnabledOverused:    &trueValue,
+					EnabledAllocatable: &trueValue,
+				},
+			},
+		},
+	}
+	ssn := framework.OpenSessionWithJobFilter(schedulerCache, "", tiers, nil, func(job *api.JobInfo) bool {
+		return job.GetSchedulerName() != "profile-b"
+	})
+	defer framework.CloseSession(ssn)
+
+	if _, found := ssn.Jobs["ns1/pg2"]; found {
+		t.Fatalf("expected the job of the profile to be left out of the session")
+	}
+	queue := ssn.Queues["q1"]
+	// q1 deserves its capability, which the allocations of both sessions use up
+	if !ssn.Overused(queue) {
+		t.Errorf("expected queue q1 to be overused with the allocations of both profiles")
+	}
+	task := ssn.Jobs["ns1/pg3"].TaskStatusIndex[api.Pending]["ns1-p3"]
+	if ssn.Allocatable(queue, task) {
+		t.Errorf("expected task p3 not to be allocatable beyond the capability of q1")
+	}
+}
+
+func TestExceededResources(t *testing.T) {
+	quota := api.NewResource(api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "nvidia.com/gpu", Value: "2"}}...))
+	for _, tc := range []struct {
+		name     string
+		used     *api.Resource
+		exceeded []string
+	}{
+		{
+			name: "within the quota",
+			used: api.NewResource(api.BuildResourceList("4", "4Gi", []api.ScalarResource{{Name: "nvidia.com/gpu", Value: "2"}}...)),
+		},
+		{
+			name:     "beyond the quota on cpu and gpu",
+			used:     api.NewResource(api.BuildResourceList("6", "4Gi", []api.ScalarResource{{Name: "nvidia.com/gpu", Value: "3"}}...)),
+			exceeded: []string{"cpu", "nvidia.com/gpu"},
+		},
+		{
+			name: "resources the quota does not set are not limited",
+			used: api.NewResource(api.BuildResourceList("1", "1Gi", []api.ScalarResource{{Name: "hugepages-1Gi", Value: "4"}}...)),
+		},
+	} {
+		if got := exceededResources(tc.used, quota); !reflect.DeepEqual(got, tc.exceeded) {
+			t.Errorf("%s: expected %v exceeded, got %v", tc.name, tc.exceeded, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+)
+
+const (
+	// QueueQuotaEnforcementAnnotation sets how the capability of a queue is enforced: "enforce",
+	// the default, or "monitor" to let the queue go beyond its capability and only report it.
+	QueueQuotaEnforcementAnnotation = "volcano.sh/quota-enforcement"
+	// QuotaEnforcementMonitor reports the usage of the queue beyond its capability instead of
+	// rejecting and reclaiming it, to roll quotas out on a cluster without breaking its workloads.
+	QuotaEnforcementMonitor = "monitor"
+
+	// QuotaExceededReason is the reason of the events reporting the usage of a monitored queue
+	// beyond its capability.
+	QuotaExceededReason = "QuotaExceeded"
+	// QuotaExceededCondition is the type of the podgroup condition of the jobs enqueued beyond the
+	// capability of their monitored queue.
+	QuotaExceededCondition scheduling.PodGroupConditionType = "QuotaExceeded"
+	// QuotaRestoredReason is the reason of the event reporting a monitored queue back within its capability.
+	QuotaRestoredReason = "QuotaRestored"
+)
+
+func quotaMonitored(queue *api.QueueInfo) bool {
+	return queue.Queue.Annotations[QueueQuotaEnforcementAnnotation] == QuotaEnforcementMonitor
+}
+
+// exceededResources returns the resources of r beyond the quota; the resources the quota does
+// not set are not limited.
+func exceededResources(r, quota *api.Resource) []string {
+	var exceeded []string
+	for _, rn := range r.ResourceNames() {
+		if rn != v1.ResourceCPU && rn != v1.ResourceMemory {
+			if _, found := quota.ScalarResources[rn]; !found {
+				continue
+			}
+		}
+		if r.Get(rn) > quota.Get(rn) {
+			exceeded = append(exceeded, string(rn))
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded
+}
+
+// recordQuotaExceeded marks the job enqueued beyond the capability of its monitored queue.
+func recordQuotaExceeded(ssn *framework.Session, job *api.JobInfo, attr *queueAttr, exceeded []string) {
+	message := fmt.Sprintf("queue %s is beyond its capability <%v> on %s, enqueued as the quota is only monitored",
+		attr.name, attr.quota, strings.Join(exceeded, ", "))
+	jc := &scheduling.PodGroupCondition{
+		Type:               QuotaExceededCondition,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		TransitionID:       string(ssn.UID),
+		Reason:             QuotaExceededReason,
+		Message:            message,
+	}
+	if err := ssn.UpdatePodGroupCondition(job, jc); err != nil {
+		klog.Errorf("Failed to update condition of job <%s/%s>: %v", job.Namespace, job.Name, err)
+	}
+	ssn.RecordPodGroupEvent(job.PodGroup, v1.EventTypeWarning, QuotaExceededReason, message)
+}
+
+// checkQuotas reports, by metric and by event on the queue, the monitored queues allocated beyond
+// their capability.
+func (pp *proportionPlugin) checkQuotas(ssn *framework.Session) {
+	for queueID, queue := range ssn.Queues {
+		if !quotaMonitored(queue) {
+			if _, found := pp.overQuota[queueID]; found {
+				delete(pp.overQuota, queueID)
+				metrics.UpdateQueueQuotaExceeded(queue.Name, false)
+			}
+			continue
+		}
+		var exceeded []string
+		attr, found := pp.queueOpts[queueID]
+		if found && attr.quota != nil {
+			exceeded = exceededResources(attr.allocated, attr.quota)
+		}
+		over := len(exceeded) != 0
+		metrics.UpdateQueueQuotaExceeded(queue.Name, over)
+
+		if over == pp.overQuota[queueID] {
+			continue
+		}
+		pp.overQuota[queueID] = over
+		if over {
+			klog.V(3).Infof("Monitored queue <%s> allocated <%v> beyond its capability <%v>", attr.name, attr.allocated, attr.quota)
+			ssn.RecordQueueEvent(queue, v1.EventTypeWarning, QuotaExceededReason,
+				fmt.Sprintf("Allocated <%v> beyond capability <%v> on %s", attr.allocated, attr.quota, strings.Join(exceeded, ", ")))
+		} else {
+			ssn.RecordQueueEvent(queue, v1.EventTypeNormal, QuotaRestoredReason, "Allocated back within capability")
+		}
+	}
+}
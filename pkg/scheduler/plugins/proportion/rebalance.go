@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// rebalanceArgument enables the gradual eviction of the tasks of the queues allocated beyond
+	// their capability, e.g. after their capability or weight was lowered
+	rebalanceArgument = "proportion.rebalance"
+	// rebalanceEvictionsArgument is the maximum number of tasks evicted per queue and session
+	rebalanceEvictionsArgument = "proportion.rebalanceEvictionsPerCycle"
+
+	defaultRebalanceEvictions = 1
+)
+
+type queueQuota struct {
+	weight     int32
+	capability *api.Resource
+}
+
+// recordQuotaChanges returns whether the weight or capability of a queue changed since the last
+// session, the queues are then rebalanced towards their new deserved resources.
+func (pp *proportionPlugin) recordQuotaChanges() bool {
+	changed := false
+	quotas := make(map[api.QueueID]queueQuota, len(pp.queueOpts))
+	for queueID, attr := range pp.queueOpts {
+		last, found := pp.lastQuotas[queueID]
+		if found && (last.weight != attr.weight || !equalCapability(last.capability, attr.capability)) {
+			klog.V(3).Infof("Queue <%s> changed from weight <%d> capability <%v> to weight <%d> capability <%v>",
+				attr.name, last.weight, last.capability, attr.weight, attr.capability)
+			changed = true
+		}
+		var capability *api.Resource
+		if attr.capability != nil {
+			capability = attr.capability.Clone()
+		}
+		quotas[queueID] = queueQuota{weight: attr.weight, capability: capability}
+	}
+	pp.lastQuotas = quotas
+	return changed
+}
+
+func equalCapability(l, r *api.Resource) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	return l.Equal(r, api.Zero)
+}
+
+// recordClusterTotal returns whether the cluster shrank since the last session.
+func (pp *proportionPlugin) recordClusterTotal(total *api.Resource) bool {
+	shrank := pp.lastTotal != nil && !pp.lastTotal.LessEqual(total, api.Zero)
+	if shrank {
+		klog.V(3).Infof("The total resource of the cluster shrank from <%v> to <%v>", pp.lastTotal, total)
+	}
+	pp.lastTotal = total.Clone()
+	return shrank
+}
+
+// rebalanceVictims returns, for each queue allocated beyond its capability, the running tasks to
+// evict to bring it back under its capability, at most rebalanceEvictions per queue. After the
+// cluster shrank or the weight or capability of a queue changed, the queues allocated beyond their
+// deserved resource are brought back to it too while other queues are starving, until none is
+// left beyond its deserved resource.
+func (pp *proportionPlugin) rebalanceVictims(ssn *framework.Session) []*api.TaskInfo {
+	queueIDs := make([]string, 0, len(pp.queueOpts))
+	starving := false
+	for queueID, attr := range pp.queueOpts {
+		queueIDs = append(queueIDs, string(queueID))
+		if attr.allocated.LessEqual(attr.deserved, api.Zero) && !attr.request.LessEqual(attr.allocated, api.Zero) {
+			starving = true
+		}
+	}
+	sort.Strings(queueIDs)
+
+	overDeserved := false
+	var victims []*api.TaskInfo
+	for _, queueID := range queueIDs {
+		attr := pp.queueOpts[api.QueueID(queueID)]
+		target := attr.realCapability
+		if pp.rebalancing && !attr.allocated.LessEqual(attr.deserved, api.Infinity) {
+			overDeserved = true
+			if starving {
+				target = attr.deserved
+			}
+		}
+		if attr.allocated.LessEqual(target, api.Infinity) {
+			continue
+		}
+
+		var candidates []*api.TaskInfo
+		for _, job := range ssn.Jobs {
+			if job.Queue != attr.queueID {
+				continue
+			}
+			for _, task := range job.TaskStatusIndex[api.Running] {
+				if task.Preemptable {
+					candidates = append(candidates, task)
+				}
+			}
+		}
+
+		allocated := attr.allocated.Clone()
+		evicted := 0
+		queue := ssn.BuildVictimsPriorityQueue(candidates)
+		for !queue.Empty() && evicted < pp.rebalanceEvictions && !allocated.LessEqual(target, api.Infinity) {
+			victim := queue.Pop().(*api.TaskInfo)
+			allocated.Sub(victim.Resreq)
+			victims = append(victims, victim)
+			evicted++
+		}
+		klog.V(3).Infof("Queue <%s> allocated <%v> beyond <%v>, %d tasks are evicted",
+			attr.name, attr.allocated, target, evicted)
+	}
+
+	if pp.rebalancing && !overDeserved {
+		pp.rebalancing = false
+	}
+	return victims
+}
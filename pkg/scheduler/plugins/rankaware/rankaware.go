@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rankaware
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	k8sFramework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "rankaware"
+
+	// TopologyKeyArgument is the argument holding the node label giving the topology domain of a
+	// node, the zone by default.
+	TopologyKeyArgument = "rankaware.topologyKey"
+	// WeightArgument is the argument holding the weight of the node order of the plugin.
+	WeightArgument = "rankaware.weight"
+
+	// RankTopologyKeyAnnotation is the podgroup annotation overriding the topology key for the job.
+	RankTopologyKeyAnnotation = "volcano.sh/rank-topology-key"
+)
+
+type rankAwarePlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	topologyKey string
+	weight      int
+}
+
+// New return rank aware plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	return &rankAwarePlugin{pluginArguments: arguments}
+}
+
+func (rp *rankAwarePlugin) Name() string {
+	return PluginName
+}
+
+// taskRank returns the rank of the task, or -1 if it has none.
+func taskRank(task *api.TaskInfo) int {
+	if task.Pod == nil {
+		return -1
+	}
+	value, found := task.Pod.Annotations[api.TaskRank]
+	if !found {
+		return -1
+	}
+	rank, err := strconv.Atoi(value)
+	if err != nil || rank < 0 {
+		klog.V(4).Infof("Invalid %s=%s of task <%s/%s>, ignore it", api.TaskRank, value, task.Namespace, task.Name)
+		return -1
+	}
+	return rank
+}
+
+// previousRankNode returns the node of the allocated task of the job with the closest rank
+// below the rank, empty if none.
+func previousRankNode(job *api.JobInfo, rank int) string {
+	closest, node := -1, ""
+	for _, task := range job.Tasks {
+		if !api.AllocatedStatus(task.Status) || len(task.NodeName) == 0 {
+			continue
+		}
+		if r := taskRank(task); r < rank && r > closest {
+			closest, node = r, task.NodeName
+		}
+	}
+	return node
+}
+
+func domainOf(node *api.NodeInfo, key string) string {
+	if node == nil || node.Node == nil {
+		return ""
+	}
+	return node.Node.Labels[key]
+}
+
+func (rp *rankAwarePlugin) OnSessionOpen(ssn *framework.Session) {
+	rp.topologyKey = v1.LabelTopologyZone
+	if key, ok := rp.pluginArguments[TopologyKeyArgument].(string); ok && len(key) != 0 {
+		rp.topologyKey = key
+	}
+	rp.weight = 1
+	rp.pluginArguments.GetInt(&rp.weight, WeightArgument)
+
+	// the lower ranks are placed first, so that they get the best nodes
+	ssn.AddTaskOrderFn(rp.Name(), func(l, r interface{}) int {
+		lv := l.(*api.TaskInfo)
+		rv := r.(*api.TaskInfo)
+		if lv.Job != rv.Job {
+			return 0
+		}
+		lr, rr := taskRank(lv), taskRank(rv)
+		if lr < 0 || rr < 0 || lr == rr {
+			return 0
+		}
+		if lr < rr {
+			return -1
+		}
+		return 1
+	})
+
+	// the next ranks are placed close to the previous ones, in the topology domain of the node
+	// of the closest rank placed
+	ssn.AddBatchNodeOrderFn(rp.Name(), func(task *api.TaskInfo, nodes []*api.NodeInfo) (map[string]float64, error) {
+		rank := taskRank(task)
+		job, found := ssn.Jobs[task.Job]
+		if rank <= 0 || !found {
+			return nil, nil
+		}
+		previous := previousRankNode(job, rank)
+		if len(previous) == 0 {
+			return nil, nil
+		}
+		key := rp.topologyKey
+		if job.PodGroup != nil && len(job.PodGroup.Annotations[RankTopologyKeyAnnotation]) != 0 {
+			key = job.PodGroup.Annotations[RankTopologyKeyAnnotation]
+		}
+		domain := domainOf(ssn.Nodes[previous], key)
+
+		scores := make(map[string]float64, len(nodes))
+		for _, node := range nodes {
+			switch {
+			case node.Name == previous:
+				scores[node.Name] = float64(k8sFramework.MaxNodeScore * int64(rp.weight))
+			case len(domain) != 0 && domainOf(node, key) == domain:
+				scores[node.Name] = float64(k8sFramework.MaxNodeScore*int64(rp.weight)) / 2
+			}
+		}
+		klog.V(5).Infof("Rank %d of task <%s/%s> follows node %s in %s=%s", rank, task.Namespace, task.Name, previous, key, domain)
+		return scores, nil
+	})
+}
+
+func (rp *rankAwarePlugin) OnSessionClose(ssn *framework.Session) {}
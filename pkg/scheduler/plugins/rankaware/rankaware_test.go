@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rankaware
+
+import (
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/actions/allocate"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestRankAwarePlacement(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+	buildNode := func(name, cpu, zone string) *v1.Node {
+		return util.BuildNode(name, api.BuildResourceList(cpu, "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{v1.LabelTopologyZone: zone})
+	}
+	buildRankPod := func(name, nodeName string, phase v1.PodPhase, rank int) *v1.Pod {
+		pod := util.BuildPod("c1", name, nodeName, phase, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string))
+		pod.Annotations[api.TaskRank] = strconv.Itoa(rank)
+		return pod
+	}
+
+	tests := []uthelper.TestCommonStruct{
+		{
+			Name:      "place the next ranks in the zone of the previous ones",
+			Plugins:   plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{util.BuildPodGroup("pg1", "c1", "q1", 3, nil, schedulingv1beta1.PodGroupInqueue)},
+			Pods: []*v1.Pod{
+				buildRankPod("p0", "n2", v1.PodRunning, 0),
+				buildRankPod("p1", "", v1.PodPending, 1),
+				buildRankPod("p2", "", v1.PodPending, 2),
+			},
+			Nodes:          []*v1.Node{buildNode("n1", "2", "zone-a"), buildNode("n2", "1", "zone-b"), buildNode("n3", "2", "zone-b")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p1": "n3", "c1/p2": "n3"},
+			ExpectBindsNum: 2,
+		},
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledTaskOrder: &trueValue,
+					EnabledNodeOrder: &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.RegisterSession(tiers, nil)
+			defer test.Close()
+			test.Run([]framework.Action{allocate.New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -59,6 +59,7 @@ func init() {
 
 	// register victim functions for all strategies here
 	VictimFn["lowNodeUtilization"] = victimsFnForLnu
+	VictimFn["unhealthyNode"] = victimsFnForUnhealthyNode
 }
 
 type reschedulingPlugin struct {
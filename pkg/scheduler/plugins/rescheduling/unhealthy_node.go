@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rescheduling
+
+import (
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/nodehealth"
+)
+
+// DefaultUnhealthyNodeMaxEvictions is the default number of tasks the unhealthyNode strategy
+// evicts at each interval
+const DefaultUnhealthyNodeMaxEvictions = 10
+
+// victimsFnForUnhealthyNode evicts the tasks of the nodes with a true condition among the
+// "conditions" parameter of the strategy, the problems reported by the node problem detector by
+// default, so that they are rescheduled off them. At most "maxEvictions" tasks are evicted at
+// each interval, so that a node going unhealthy does not restart all its jobs at once.
+var victimsFnForUnhealthyNode = func(tasks []*api.TaskInfo) []*api.TaskInfo {
+	victims := make([]*api.TaskInfo, 0)
+
+	conditions := nodehealth.ParseConditions(nil)
+	maxEvictions := DefaultUnhealthyNodeMaxEvictions
+	if config, ok := RegisteredStrategyConfigs["unhealthyNode"].(map[string]interface{}); ok {
+		conditions = nodehealth.ParseConditions(config["conditions"])
+		framework.Arguments(config).GetInt(&maxEvictions, "maxEvictions")
+	}
+	if len(conditions) == 0 {
+		conditions.Insert(nodehealth.DefaultConditions...)
+	}
+
+	for _, task := range tasks {
+		if len(victims) >= maxEvictions {
+			klog.V(3).Infof("Evicted %d tasks from unhealthy nodes, leave the others to the next interval", len(victims))
+			break
+		}
+		node, found := Session.Nodes[task.NodeName]
+		if !found {
+			continue
+		}
+		if unhealthy := nodehealth.UnhealthyConditions(node.Node, conditions); len(unhealthy) != 0 {
+			klog.V(3).Infof("Evict task <%s/%s> from unhealthy node <%s>: %v", task.Namespace, task.Name, task.NodeName, unhealthy)
+			victims = append(victims, task)
+		}
+	}
+	return victims
+}
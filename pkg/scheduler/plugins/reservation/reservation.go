@@ -0,0 +1,345 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+// PluginName indicates name of volcano scheduler plugin.
+const PluginName = "reservation"
+
+// Reservation withholds the capacity of the matching nodes from all queues but
+// the owner queue during the time window. The reservations are read from the
+// Reservation CRD if it is installed, and from the arguments of the plugin.
+//
+// User should specify arguments in the config in this format:
+//
+//	actions: "enqueue, allocate, backfill"
+//	tiers:
+//	- plugins:
+//	  - name: reservation
+//	    arguments:
+//	      reservations:
+//	      - name: weekend-training
+//	        queue: research
+//	        nodeSelector:
+//	          node-type: gpu
+//	        tolerations:
+//	        - key: dedicated
+//	          operator: Equal
+//	          value: gpu
+//	          effect: NoSchedule
+//	        resources:
+//	          nvidia.com/gpu: "64"
+//	        start: "2024-06-01T09:00:00Z"
+//	        end: "2024-06-02T09:00:00Z"
+type Reservation struct {
+	Name         string            `json:"name"`
+	Queue        string            `json:"queue"`
+	NodeSelector map[string]string `json:"nodeSelector"`
+	Tolerations  []v1.Toleration   `json:"tolerations"`
+	Resources    map[string]string `json:"resources"`
+	Start        string            `json:"start"`
+	End          string            `json:"end"`
+}
+
+// reservationState is the bookkeeping of one active reservation in a session.
+type reservationState struct {
+	*api.Reservation
+	// nodes are the names of the nodes the owner queue can use, in the order the capacity is withheld on
+	nodes []string
+	// withheld is the capacity withheld from the other queues by node, the reserved resource
+	// not yet allocated to the owner queue is carved out of the idle resource of the nodes
+	withheld map[string]map[v1.ResourceName]float64
+	// released is the withheld capacity released by the allocated tasks of the owner queue
+	released map[api.TaskID]map[string]map[v1.ResourceName]float64
+}
+
+type reservationPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+	reservations    []Reservation
+}
+
+// New return reservation plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	rp := &reservationPlugin{pluginArguments: arguments}
+	rp.parseArguments(arguments)
+	return rp
+}
+
+func (rp *reservationPlugin) Name() string {
+	return PluginName
+}
+
+func (rp *reservationPlugin) parseArguments(arguments framework.Arguments) {
+	reservations, _ := arguments["reservations"].([]interface{})
+	for _, r := range reservations {
+		reservation := Reservation{}
+		if err := mapstructure.Decode(r, &reservation); err != nil {
+			klog.Errorf("Failed to decode reservation %v: %v", r, err)
+			continue
+		}
+		rp.reservations = append(rp.reservations, reservation)
+	}
+}
+
+// toReservation returns the reservation if it is active at now.
+func toReservation(r Reservation, now time.Time) (*api.Reservation, error) {
+	start, err := time.Parse(time.RFC3339, r.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %v", err)
+	}
+	end, err := time.Parse(time.RFC3339, r.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end: %v", err)
+	}
+	if now.Before(start) || !now.Before(end) {
+		return nil, nil
+	}
+
+	reserved := v1.ResourceList{}
+	for name, value := range r.Resources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity of %s: %v", name, err)
+		}
+		reserved[v1.ResourceName(name)] = quantity
+	}
+
+	return &api.Reservation{
+		Name:         r.Name,
+		Queue:        api.QueueID(r.Queue),
+		NodeSelector: labels.SelectorFromSet(r.NodeSelector),
+		Tolerations:  r.Tolerations,
+		Resources:    reserved,
+	}, nil
+}
+
+// eligible returns whether the owner queue of the reservation can use the node: the
+// capacity of the nodes it cannot schedule onto is not withheld.
+func eligible(r *api.Reservation, node *api.NodeInfo) bool {
+	if node.Node == nil || node.Node.Spec.Unschedulable || !r.NodeSelector.Matches(labels.Set(node.Node.Labels)) {
+		return false
+	}
+	_, untolerated := v1helper.FindMatchingUntoleratedTaint(node.Node.Spec.Taints, r.Tolerations, func(taint *v1.Taint) bool {
+		return taint.Effect == v1.TaintEffectNoSchedule || taint.Effect == v1.TaintEffectNoExecute
+	})
+	return !untolerated
+}
+
+// newReservationState carves the reserved resource not yet allocated to the owner queue out of
+// the idle resource of the eligible nodes, node by node.
+func newReservationState(r *api.Reservation, ssn *framework.Session) *reservationState {
+	state := &reservationState{
+		Reservation: r,
+		withheld:    map[string]map[v1.ResourceName]float64{},
+		released:    map[api.TaskID]map[string]map[v1.ResourceName]float64{},
+	}
+	reserved := api.NewResource(r.Resources)
+	outstanding := map[v1.ResourceName]float64{}
+	for name := range r.Resources {
+		outstanding[name] = reserved.Get(name)
+	}
+
+	for _, node := range ssn.Nodes {
+		if !eligible(r, node) {
+			continue
+		}
+		state.nodes = append(state.nodes, node.Name)
+		for _, task := range node.Tasks {
+			job, found := ssn.Jobs[task.Job]
+			if found && job.Queue == r.Queue && api.AllocatedStatus(task.Status) {
+				for name := range outstanding {
+					outstanding[name] -= task.Resreq.Get(name)
+				}
+			}
+		}
+	}
+	// withhold the capacity on the nodes with most of the reserved resource idle first, so that
+	// the owner queue gets it on as few nodes as possible
+	idle := func(name string) float64 {
+		var sum float64
+		futureIdle := ssn.Nodes[name].FutureIdle()
+		for resourceName := range r.Resources {
+			if total := reserved.Get(resourceName); total > 0 {
+				sum += futureIdle.Get(resourceName) / total
+			}
+		}
+		return sum
+	}
+	sort.Slice(state.nodes, func(i, j int) bool {
+		idleI, idleJ := idle(state.nodes[i]), idle(state.nodes[j])
+		if idleI != idleJ {
+			return idleI > idleJ
+		}
+		return state.nodes[i] < state.nodes[j]
+	})
+	for _, name := range state.nodes {
+		futureIdle := ssn.Nodes[name].FutureIdle()
+		withheld := map[v1.ResourceName]float64{}
+		for resourceName, quantity := range outstanding {
+			if quantity <= 0 {
+				continue
+			}
+			withheld[resourceName] = math.Min(quantity, futureIdle.Get(resourceName))
+			outstanding[resourceName] -= withheld[resourceName]
+		}
+		state.withheld[name] = withheld
+	}
+	return state
+}
+
+// admit checks that the capacity withheld on the node is still idle after the task of another
+// queue is placed.
+func (rs *reservationState) admit(task *api.TaskInfo, node *api.NodeInfo) error {
+	withheld := rs.withheld[node.Name]
+	if len(withheld) == 0 {
+		return nil
+	}
+	futureIdle := node.FutureIdle()
+	for name, quantity := range withheld {
+		if quantity > 0 && futureIdle.Get(name)-task.Resreq.Get(name) < quantity {
+			return fmt.Errorf("%s is reserved by reservation <%s>", name, rs.Name)
+		}
+	}
+	return nil
+}
+
+// allocate releases the capacity withheld for the task of the owner queue, from the node
+// of the task first and then from the other nodes.
+func (rs *reservationState) allocate(task *api.TaskInfo) {
+	if _, found := rs.withheld[task.NodeName]; !found {
+		return
+	}
+	released := map[string]map[v1.ResourceName]float64{}
+	nodes := append([]string{task.NodeName}, rs.nodes...)
+	for name := range rs.Resources {
+		quantity := task.Resreq.Get(name)
+		for _, node := range nodes {
+			if quantity <= 0 {
+				break
+			}
+			withheld := rs.withheld[node]
+			amount := math.Min(quantity, withheld[name])
+			if amount <= 0 {
+				continue
+			}
+			withheld[name] -= amount
+			quantity -= amount
+			if released[node] == nil {
+				released[node] = map[v1.ResourceName]float64{}
+			}
+			released[node][name] += amount
+		}
+	}
+	rs.released[task.UID] = released
+}
+
+// deallocate withholds again the capacity released for the task.
+func (rs *reservationState) deallocate(task *api.TaskInfo) {
+	for node, released := range rs.released[task.UID] {
+		for name, amount := range released {
+			rs.withheld[node][name] += amount
+		}
+	}
+	delete(rs.released, task.UID)
+}
+
+func (rp *reservationPlugin) OnSessionOpen(ssn *framework.Session) {
+	reservations := append([]*api.Reservation{}, ssn.Reservations...)
+	now := time.Now()
+	for _, r := range rp.reservations {
+		reservation, err := toReservation(r, now)
+		if err != nil {
+			klog.Errorf("Reservation <%s> is invalid: %v", r.Name, err)
+			continue
+		}
+		if reservation != nil {
+			reservations = append(reservations, reservation)
+		}
+	}
+	if len(reservations) == 0 {
+		return
+	}
+
+	var states []*reservationState
+	for _, r := range reservations {
+		state := newReservationState(r, ssn)
+		klog.V(4).Infof("Reservation <%s> of queue <%s> is active, reserved <%v>, withheld <%v>",
+			state.Name, state.Queue, state.Resources, state.withheld)
+		states = append(states, state)
+	}
+
+	predicateFn := func(task *api.TaskInfo, node *api.NodeInfo) error {
+		job, found := ssn.Jobs[task.Job]
+		if !found {
+			return nil
+		}
+		for _, state := range states {
+			if job.Queue == state.Queue {
+				continue
+			}
+			if err := state.admit(task, node); err != nil {
+				return api.NewFitErrWithStatus(task, node, &api.Status{
+					Code:   api.Unschedulable,
+					Reason: err.Error(),
+					Plugin: PluginName,
+				})
+			}
+		}
+		return nil
+	}
+	ssn.AddPredicateFn(rp.Name(), predicateFn)
+
+	ownedBy := func(task *api.TaskInfo, state *reservationState) bool {
+		job, found := ssn.Jobs[task.Job]
+		return found && job.Queue == state.Queue
+	}
+	ssn.AddEventHandler(&framework.EventHandler{
+		AllocateFunc: func(event *framework.Event) {
+			for _, state := range states {
+				if ownedBy(event.Task, state) {
+					state.allocate(event.Task)
+				}
+			}
+		},
+		DeallocateFunc: func(event *framework.Event) {
+			for _, state := range states {
+				if ownedBy(event.Task, state) {
+					state.deallocate(event.Task)
+				}
+			}
+		},
+	})
+}
+
+func (rp *reservationPlugin) OnSessionClose(ssn *framework.Session) {}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestReservation(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{PluginName: New}
+
+	n1 := util.BuildNode("n1", api.BuildResourceList("4", "8Gi"), map[string]string{"node-type": "gpu"})
+	n2 := util.BuildNode("n2", api.BuildResourceList("4", "8Gi"), make(map[string]string))
+	n3 := util.BuildNode("n3", api.BuildResourceList("2", "8Gi"), map[string]string{"node-type": "gpu"})
+	tainted := util.BuildNode("n1", api.BuildResourceList("4", "8Gi"), map[string]string{"node-type": "gpu"})
+	tainted.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "training", Effect: v1.TaintEffectNoSchedule}}
+
+	p1 := util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string))
+	p2 := util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg2", make(map[string]string), make(map[string]string))
+
+	pg1 := util.BuildPodGroup("pg1", "c1", "q1", 1, nil, schedulingv1.PodGroupInqueue)
+	pg2 := util.BuildPodGroup("pg2", "c1", "q2", 1, nil, schedulingv1.PodGroupInqueue)
+
+	q1 := util.BuildQueue("q1", 1, nil)
+	q2 := util.BuildQueue("q2", 1, nil)
+
+	now := time.Now()
+	tests := []struct {
+		name        string
+		nodes       []*v1.Node
+		cpu         string
+		tolerations []interface{}
+		start       time.Time
+		end         time.Time
+		expected    map[string]map[string]bool
+	}{
+		{
+			name:  "active reservation withholds matching nodes from other queues",
+			nodes: []*v1.Node{n1, n2},
+			cpu:   "4",
+			start: now.Add(-time.Hour),
+			end:   now.Add(time.Hour),
+			expected: map[string]map[string]bool{
+				"c1/p1": {"n1": true, "n2": true},
+				"c1/p2": {"n1": false, "n2": true},
+			},
+		},
+		{
+			name:  "reservation outside of its window withholds nothing",
+			nodes: []*v1.Node{n1, n2},
+			cpu:   "4",
+			start: now.Add(time.Hour),
+			end:   now.Add(2 * time.Hour),
+			expected: map[string]map[string]bool{
+				"c1/p1": {"n1": true, "n2": true},
+				"c1/p2": {"n1": true, "n2": true},
+			},
+		},
+		{
+			name:  "reservation is carved out of the matching nodes node by node",
+			nodes: []*v1.Node{n1, n3},
+			cpu:   "4",
+			start: now.Add(-time.Hour),
+			end:   now.Add(time.Hour),
+			expected: map[string]map[string]bool{
+				"c1/p1": {"n1": true, "n3": true},
+				"c1/p2": {"n1": false, "n3": true},
+			},
+		},
+		{
+			name:  "nodes with taints not tolerated by the reservation are not withheld",
+			nodes: []*v1.Node{tainted, n3},
+			cpu:   "2",
+			start: now.Add(-time.Hour),
+			end:   now.Add(time.Hour),
+			expected: map[string]map[string]bool{
+				"c1/p2": {"n1": true, "n3": false},
+			},
+		},
+		{
+			name:  "nodes with taints tolerated by the reservation are withheld",
+			nodes: []*v1.Node{tainted, n2},
+			cpu:   "4",
+			tolerations: []interface{}{
+				map[string]interface{}{"key": "dedicated", "operator": "Equal", "value": "training", "effect": "NoSchedule"},
+			},
+			start: now.Add(-time.Hour),
+			end:   now.Add(time.Hour),
+			expected: map[string]map[string]bool{
+				"c1/p2": {"n1": false, "n2": true},
+			},
+		},
+	}
+
+	trueValue := true
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiers := []conf.Tier{
+				{
+					Plugins: []conf.PluginOption{
+						{
+							Name:             PluginName,
+							EnabledPredicate: &trueValue,
+							Arguments: framework.Arguments{
+								"reservations": []interface{}{
+									map[string]interface{}{
+										"name":         "r1",
+										"queue":        "q1",
+										"nodeSelector": map[string]string{"node-type": "gpu"},
+										"tolerations":  test.tolerations,
+										"resources":    map[string]string{"cpu": test.cpu},
+										"start":        test.start.Format(time.RFC3339),
+										"end":          test.end.Format(time.RFC3339),
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			tc := uthelper.TestCommonStruct{
+				Name:      test.name,
+				PodGroups: []*schedulingv1.PodGroup{pg1, pg2},
+				Queues:    []*schedulingv1.Queue{q1, q2},
+				Pods:      []*v1.Pod{p1, p2},
+				Nodes:     test.nodes,
+				Plugins:   plugins,
+			}
+			ssn := tc.RegisterSession(tiers, nil)
+			defer tc.Close()
+
+			for _, job := range ssn.Jobs {
+				for _, task := range job.Tasks {
+					taskID := fmt.Sprintf("%s/%s", task.Namespace, task.Name)
+					for nodeName, expected := range test.expected[taskID] {
+						err := ssn.PredicateFn(task, ssn.Nodes[nodeName])
+						if (err == nil) != expected {
+							t.Errorf("task %s on node %s expect fit %v, but get err %v", taskID, nodeName, expected, err)
+						}
+					}
+				}
+			}
+		})
+	}
+}
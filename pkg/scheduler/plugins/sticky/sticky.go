@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sticky
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	k8sFramework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "sticky"
+
+	// WeightArgument is the argument holding the weight of the node order of the plugin.
+	WeightArgument = "sticky.weight"
+)
+
+type stickyPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	weight int
+	// placements are the previous placements recorded on the podgroups by job
+	placements map[api.JobID]map[string]api.Placement
+}
+
+// New return sticky plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	return &stickyPlugin{pluginArguments: arguments}
+}
+
+func (sp *stickyPlugin) Name() string {
+	return PluginName
+}
+
+// previousPlacement returns where the task ran before it was evicted: the node of the pod it
+// replaces in its gang slot, or the placement recorded on its podgroup at the eviction. A task
+// assumed on a node by the previous leader but not bound when it shut down is placed back on the
+// node nominated on its pod.
+func (sp *stickyPlugin) previousPlacement(ssn *framework.Session, task *api.TaskInfo) (api.Placement, bool) {
+	previous := task.PreviousNode
+	if len(previous) == 0 && task.Pod != nil {
+		previous = task.Pod.Status.NominatedNodeName
+	}
+	if len(previous) != 0 {
+		placement := api.Placement{Node: previous}
+		if node, found := ssn.Nodes[previous]; found && node.Node != nil {
+			placement.Zone = node.Node.Labels[v1.LabelTopologyZone]
+		}
+		return placement, true
+	}
+
+	placement, found := sp.placements[task.Job][task.PlacementKey()]
+	// the placement of an older pod only, not of the evicted pod itself
+	if !found || (task.Pod != nil && task.Pod.CreationTimestamp.Before(&placement.Time)) {
+		return api.Placement{}, false
+	}
+	return placement, true
+}
+
+func (sp *stickyPlugin) OnSessionOpen(ssn *framework.Session) {
+	sp.weight = 1
+	sp.pluginArguments.GetInt(&sp.weight, WeightArgument)
+	sp.placements = map[api.JobID]map[string]api.Placement{}
+	for _, job := range ssn.Jobs {
+		if job.PodGroup == nil {
+			continue
+		}
+		if placements := job.PodGroup.PreviousPlacements(); len(placements) != 0 {
+			sp.placements[job.UID] = placements
+		}
+	}
+
+	// the evicted tasks are placed back on their node, or in their zone, to reuse the data
+	// cached and the images pulled there
+	ssn.AddNodeOrderFn(sp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		placement, found := sp.previousPlacement(ssn, task)
+		if !found {
+			return 0, nil
+		}
+		switch {
+		case node.Name == placement.Node:
+			return float64(k8sFramework.MaxNodeScore * int64(sp.weight)), nil
+		case len(placement.Zone) != 0 && node.Node != nil && node.Node.Labels[v1.LabelTopologyZone] == placement.Zone:
+			klog.V(5).Infof("Task <%s/%s> ran in zone %s before, node %s is in it", task.Namespace, task.Name, placement.Zone, node.Name)
+			return float64(k8sFramework.MaxNodeScore*int64(sp.weight)) / 2, nil
+		}
+		return 0, nil
+	})
+}
+
+func (sp *stickyPlugin) OnSessionClose(ssn *framework.Session) {
+	sp.placements = nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sticky
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/actions/allocate"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/uthelper"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestStickyPlacement(t *testing.T) {
+	plugins := map[string]framework.PluginBuilder{
+		PluginName:      New,
+		gang.PluginName: gang.New,
+	}
+	evicted := time.Now().Add(-time.Minute)
+	buildNode := func(name, zone string) *v1.Node {
+		return util.BuildNode(name, api.BuildResourceList("2", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{v1.LabelTopologyZone: zone})
+	}
+	buildPod := func(name string) *v1.Pod {
+		pod := util.BuildPod("c1", name, "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string))
+		pod.CreationTimestamp = metav1.Now()
+		return pod
+	}
+	buildPodGroup := func(placements map[string]api.Placement) *schedulingv1beta1.PodGroup {
+		pg := util.BuildPodGroup("pg1", "c1", "q1", 1, nil, schedulingv1beta1.PodGroupInqueue)
+		value, err := json.Marshal(placements)
+		if err != nil {
+			t.Fatalf("failed to encode placements: %v", err)
+		}
+		pg.Annotations = map[string]string{api.PodGroupPreviousPlacement: string(value)}
+		return pg
+	}
+
+	tests := []uthelper.TestCommonStruct{
+		{
+			Name:           "place the task back on its node",
+			Plugins:        plugins,
+			PodGroups:      []*schedulingv1beta1.PodGroup{buildPodGroup(map[string]api.Placement{"p1": {Node: "n2", Zone: "zone-b", Time: metav1.NewTime(evicted)}})},
+			Pods:           []*v1.Pod{buildPod("p1")},
+			Nodes:          []*v1.Node{buildNode("n1", "zone-a"), buildNode("n2", "zone-b"), buildNode("n3", "zone-b")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p1": "n2"},
+			ExpectBindsNum: 1,
+		},
+		{
+			Name:           "place the task in its zone when its node is gone",
+			Plugins:        plugins,
+			PodGroups:      []*schedulingv1beta1.PodGroup{buildPodGroup(map[string]api.Placement{"p1": {Node: "n2", Zone: "zone-b", Time: metav1.NewTime(evicted)}})},
+			Pods:           []*v1.Pod{buildPod("p1")},
+			Nodes:          []*v1.Node{buildNode("n1", "zone-a"), buildNode("n3", "zone-b")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p1": "n3"},
+			ExpectBindsNum: 1,
+		},
+		{
+			Name:      "place the task assumed by the previous leader on its nominated node",
+			Plugins:   plugins,
+			PodGroups: []*schedulingv1beta1.PodGroup{buildPodGroup(nil)},
+			Pods: []*v1.Pod{func() *v1.Pod {
+				pod := buildPod("p1")
+				pod.Status.NominatedNodeName = "n3"
+				return pod
+			}()},
+			Nodes:          []*v1.Node{buildNode("n1", "zone-a"), buildNode("n2", "zone-b"), buildNode("n3", "zone-b")},
+			Queues:         []*schedulingv1beta1.Queue{util.BuildQueue("q1", 1, nil)},
+			ExpectBindMap:  map[string]string{"c1/p1": "n3"},
+			ExpectBindsNum: 1,
+		},
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledNodeOrder: &trueValue,
+				},
+				{
+					Name:                gang.PluginName,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.RegisterSession(tiers, nil)
+			defer test.Close()
+			test.Run([]framework.Action{allocate.New()})
+			if err := test.CheckAll(i); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
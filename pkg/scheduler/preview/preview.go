@@ -0,0 +1,495 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+// Path is the HTTP path the preview handler is served on.
+const Path = "/api/v1/preview"
+
+// previewName is the name of the podgroup built for the previewed job.
+const previewName = "preview"
+
+const (
+	// previewQPS and previewBurst limit the preview requests, each of them opens a session
+	// which holds off the scheduling cycle.
+	previewQPS   = 1
+	previewBurst = 5
+
+	// maxRequestBytes is the largest request body decoded.
+	maxRequestBytes = 1 << 20
+	// MaxReplicas is the most replicas of a previewed job, a pod is built for each of them; the
+	// job with more replicas than the pods the nodes hold is not placed.
+	MaxReplicas = 10000
+)
+
+// SessionFunc runs fn in a scheduling session opened on the latest snapshot with the
+// current configuration of the scheduler, the statuses of the session are not written back.
+// The jobs are added to the session before its plugins are opened, so that the plugins find
+// them like any other pending job. It fails if the scheduler is stopped.
+type SessionFunc func(jobs []*api.JobInfo, fn func(ssn *framework.Session)) error
+
+// NewHandler returns a read-only HTTP handler which answers preview requests
+// in the sessions opened by withSession. The user must be allowed to create the jobs of the
+// namespace of the request, and only gets the names of the victims of that namespace.
+func NewHandler(withSession SessionFunc, authorizer *apiauth.Authorizer) http.Handler {
+	limiter := flowcontrol.NewTokenBucketRateLimiter(previewQPS, previewBurst)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		if !limiter.TryAccept() {
+			http.Error(w, "too many preview requests", http.StatusTooManyRequests)
+			return
+		}
+
+		req := &Request{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := validate(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user, err := authorizer.Authenticate(r)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		if err := authorizer.Authorize(r.Context(), user, authorizationv1.ResourceAttributes{
+			Namespace: req.Namespace, Group: "batch.volcano.sh", Verb: "create", Resource: "jobs",
+		}); err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+
+		job, tasks := buildJob(req)
+		var resp *Response
+		if err := withSession([]*api.JobInfo{job}, func(ssn *framework.Session) {
+			resp = EvaluateInSession(ssn, req, job, tasks)
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		redactVictims(resp, req.Namespace)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			klog.Errorf("Failed to encode preview response: %v", err)
+		}
+	})
+}
+
+func validate(req *Request) error {
+	if len(req.Queue) == 0 || len(req.Namespace) == 0 {
+		return fmt.Errorf("queue and namespace are required")
+	}
+	if len(req.Tasks) == 0 {
+		return fmt.Errorf("at least one task is required")
+	}
+	var replicas int64
+	for _, task := range req.Tasks {
+		if task.Replicas <= 0 {
+			return fmt.Errorf("replicas of task <%s> must be positive", task.Name)
+		}
+		replicas += int64(task.Replicas)
+	}
+	if replicas > MaxReplicas {
+		return fmt.Errorf("the job has %d replicas, at most %d are previewed", replicas, MaxReplicas)
+	}
+	return nil
+}
+
+// redactVictims leaves out of the victims the tasks of the other namespaces than the one of the
+// request, only their number is kept.
+func redactVictims(resp *Response, namespace string) {
+	var victims []Victim
+	for _, victim := range resp.Victims {
+		if victim.Namespace == namespace {
+			victims = append(victims, victim)
+			continue
+		}
+		resp.OtherVictims++
+	}
+	resp.Victims = victims
+}
+
+// beyondPodCapacity returns the Wait response of the job with more replicas than the pods the
+// nodes hold, nil if they hold them; the tasks of such a job are not placed.
+func beyondPodCapacity(snapshot *api.ClusterInfo, req *Request) *Response {
+	var replicas, capacity int64
+	for _, task := range req.Tasks {
+		replicas += int64(task.Replicas)
+	}
+	for _, node := range snapshot.Nodes {
+		capacity += int64(node.Allocatable.MaxTaskNum)
+	}
+	if replicas <= capacity {
+		return nil
+	}
+	return &Response{Verdict: Wait, Reason: fmt.Sprintf("the job has %d replicas, the nodes hold %d pods", replicas, capacity)}
+}
+
+// EvaluateInSession predicts what would happen to the job of the request if it was submitted
+// now, the job and its tasks are built by buildJob and the job is one of the jobs of the session.
+// The tasks of the job are only placed on the nodes passing the predicates of the session, and
+// the job is only placed if its queue has the quota left for it by the plugins of the session.
+func EvaluateInSession(ssn *framework.Session, req *Request, job *api.JobInfo, tasks []*api.TaskInfo) *Response {
+	snapshot := &api.ClusterInfo{Nodes: ssn.Nodes, Jobs: ssn.Jobs, Queues: ssn.Queues}
+	if resp := beyondPodCapacity(snapshot, req); resp != nil {
+		return resp
+	}
+
+	resp := evaluate(snapshot, req, tasks, ssn.PredicateFn)
+	if resp.Verdict == Wait {
+		return resp
+	}
+	if reason := quotaReason(ssn, job, tasks); len(reason) != 0 {
+		return &Response{Verdict: Wait, Reason: reason}
+	}
+	return resp
+}
+
+// quotaReason returns why the queue holds the job back although its tasks fit the nodes, empty
+// if the queue is not overused and has the quota left to enqueue the job and to allocate its
+// required tasks.
+func quotaReason(ssn *framework.Session, job *api.JobInfo, tasks []*api.TaskInfo) string {
+	queue := ssn.Queues[job.Queue]
+	if ssn.Overused(queue) {
+		return fmt.Sprintf("queue <%s> is overused", queue.Name)
+	}
+	if !ssn.JobEnqueueable(job) {
+		return fmt.Sprintf("queue <%s> has not the quota left to enqueue the job", queue.Name)
+	}
+	// the required tasks are allocated together, they are checked as one
+	gang := tasks[0].Clone()
+	gang.Resreq = api.EmptyResource()
+	for _, task := range tasks[:job.MinAvailable] {
+		gang.Resreq.Add(task.Resreq)
+	}
+	gang.InitResreq = gang.Resreq.Clone()
+	if !ssn.Allocatable(queue, gang) {
+		return fmt.Sprintf("queue <%s> has not the quota left to allocate the required tasks of the job", queue.Name)
+	}
+	return ""
+}
+
+// Evaluate predicts what would happen to the job if it was submitted now, a task is only
+// placed on the nodes accepted by predicate if it is not nil.
+// The snapshot is only read, all bookkeeping is done on local copies.
+func Evaluate(snapshot *api.ClusterInfo, req *Request, predicate api.PredicateFn) *Response {
+	if resp := beyondPodCapacity(snapshot, req); resp != nil {
+		return resp
+	}
+	_, tasks := buildJob(req)
+	return evaluate(snapshot, req, tasks, predicate)
+}
+
+func evaluate(snapshot *api.ClusterInfo, req *Request, tasks []*api.TaskInfo, predicate api.PredicateFn) *Response {
+	queue, found := snapshot.Queues[api.QueueID(req.Queue)]
+	if !found {
+		return &Response{Verdict: Wait, Reason: fmt.Sprintf("queue <%s> does not exist", req.Queue)}
+	}
+	if queue.Queue != nil && queue.Queue.Status.State != "" && queue.Queue.Status.State != scheduling.QueueStateOpen {
+		return &Response{Verdict: Wait, Reason: fmt.Sprintf("queue <%s> is %s", req.Queue, queue.Queue.Status.State)}
+	}
+
+	e := newEvaluator(snapshot, req, predicate)
+
+	var pending []*api.TaskInfo
+	placed := int32(0)
+	for _, task := range tasks {
+		if e.allocate(task) {
+			placed++
+			continue
+		}
+		pending = append(pending, task)
+	}
+
+	minAvailable := minAvailableOf(req)
+	if placed >= minAvailable {
+		return &Response{Verdict: Run}
+	}
+
+	var victims []Victim
+	for _, task := range pending {
+		if placed >= minAvailable {
+			break
+		}
+		if v, ok := e.preempt(task); ok {
+			victims = append(victims, v...)
+			placed++
+		}
+	}
+	if placed >= minAvailable {
+		return &Response{Verdict: Preempt, Victims: victims}
+	}
+
+	return &Response{
+		Verdict: Wait,
+		Reason: fmt.Sprintf("only %d of %d required tasks can be placed, reclaiming resources from other queues is not evaluated",
+			placed, minAvailable),
+	}
+}
+
+// minAvailableOf returns the gang size of the job, all replicas are required by default.
+func minAvailableOf(req *Request) int32 {
+	var total int32
+	for _, task := range req.Tasks {
+		total += task.Replicas
+	}
+	if req.MinAvailable <= 0 || req.MinAvailable > total {
+		return total
+	}
+	return req.MinAvailable
+}
+
+// minResourcesOf returns the resources of the required replicas of the job, the first ones in
+// the order of the request.
+func minResourcesOf(req *Request) v1.ResourceList {
+	minResources := v1.ResourceList{}
+	left := minAvailableOf(req)
+	for _, spec := range req.Tasks {
+		for i := int32(0); i < spec.Replicas && left > 0; i++ {
+			for name, quantity := range spec.Resources {
+				total := minResources[name]
+				total.Add(quantity)
+				minResources[name] = total
+			}
+			left--
+		}
+	}
+	return minResources
+}
+
+// buildJob builds the job described by the request, with one task per replica in the
+// order of the request. The job and its tasks get random UIDs, so that they can not be
+// mistaken for the jobs and tasks of the cluster.
+func buildJob(req *Request) (*api.JobInfo, []*api.TaskInfo) {
+	pgUID := uuid.NewUUID()
+	uid := api.JobID(fmt.Sprintf("%s/%s-%s", req.Namespace, previewName, pgUID))
+	job := api.NewJobInfo(uid)
+	minResources := minResourcesOf(req)
+	job.SetPodGroup(&api.PodGroup{
+		PodGroup: scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: previewName, Namespace: req.Namespace, UID: pgUID},
+			Spec: scheduling.PodGroupSpec{
+				Queue:        req.Queue,
+				MinMember:    minAvailableOf(req),
+				MinResources: &minResources,
+			},
+			Status: scheduling.PodGroupStatus{Phase: scheduling.PodGroupPending},
+		},
+	})
+	job.Priority = req.Priority
+
+	var tasks []*api.TaskInfo
+	for _, spec := range req.Tasks {
+		for i := int32(0); i < spec.Replicas; i++ {
+			name := fmt.Sprintf("%s-%s-%d", previewName, spec.Name, i)
+			priority := req.Priority
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: req.Namespace,
+					UID:       types.UID(fmt.Sprintf("%s-%s", pgUID, name)),
+				},
+				Spec: v1.PodSpec{
+					Priority:     &priority,
+					NodeSelector: spec.NodeSelector,
+					Tolerations:  spec.Tolerations,
+					Containers: []v1.Container{{
+						Name:      spec.Name,
+						Resources: v1.ResourceRequirements{Requests: spec.Resources},
+					}},
+				},
+				Status: v1.PodStatus{Phase: v1.PodPending},
+			}
+			task := api.NewTaskInfo(pod)
+			task.Job = uid
+			job.AddTaskInfo(task)
+			tasks = append(tasks, task)
+		}
+	}
+	return job, tasks
+}
+
+type evaluator struct {
+	snapshot  *api.ClusterInfo
+	req       *Request
+	predicate api.PredicateFn
+	nodes     []string
+	idle      map[string]*api.Resource
+	evicted   map[api.TaskID]bool
+	occupied  map[api.JobID]int32
+}
+
+func newEvaluator(snapshot *api.ClusterInfo, req *Request, predicate api.PredicateFn) *evaluator {
+	e := &evaluator{
+		snapshot:  snapshot,
+		req:       req,
+		predicate: predicate,
+		idle:      map[string]*api.Resource{},
+		evicted:   map[api.TaskID]bool{},
+		occupied:  map[api.JobID]int32{},
+	}
+	for name, node := range snapshot.Nodes {
+		e.nodes = append(e.nodes, name)
+		e.idle[name] = node.FutureIdle()
+	}
+	sort.Strings(e.nodes)
+	return e
+}
+
+// fits returns whether the task passes the predicates on the node.
+func (e *evaluator) fits(task *api.TaskInfo, name string) bool {
+	if e.predicate == nil {
+		return true
+	}
+	if err := e.predicate(task, e.snapshot.Nodes[name]); err != nil {
+		klog.V(4).Infof("Preview task <%s> does not fit node <%s>: %v", task.Name, name, err)
+		return false
+	}
+	return true
+}
+
+// allocate places the task on the first node which passes the predicates and has
+// enough idle resources.
+func (e *evaluator) allocate(task *api.TaskInfo) bool {
+	for _, name := range e.nodes {
+		if task.Resreq.LessEqual(e.idle[name], api.Zero) && e.fits(task, name) {
+			e.idle[name].Sub(task.Resreq)
+			return true
+		}
+	}
+	return false
+}
+
+// preempt looks for the node which needs the fewest victims to fit the task.
+func (e *evaluator) preempt(task *api.TaskInfo) ([]Victim, bool) {
+	var bestNode string
+	var bestVictims []*api.TaskInfo
+	var bestIdle *api.Resource
+	for _, name := range e.nodes {
+		victims, idle, ok := e.victimsOnNode(name, task)
+		if !ok {
+			continue
+		}
+		if bestIdle == nil || len(victims) < len(bestVictims) {
+			bestNode, bestVictims, bestIdle = name, victims, idle
+		}
+	}
+	if bestIdle == nil {
+		return nil, false
+	}
+
+	e.idle[bestNode] = bestIdle.Sub(task.Resreq)
+	result := make([]Victim, 0, len(bestVictims))
+	for _, victim := range bestVictims {
+		e.evicted[victim.UID] = true
+		e.occupied[victim.Job]--
+		result = append(result, Victim{
+			Namespace: victim.Namespace,
+			Name:      victim.Name,
+			Job:       e.snapshot.Jobs[victim.Job].Name,
+			Node:      bestNode,
+		})
+	}
+	return result, true
+}
+
+// victimsOnNode follows the rules of the preempt action: only running, preemptable
+// tasks of lower priority jobs in the same queue are considered, and no job is
+// shrunk below its minAvailable.
+func (e *evaluator) victimsOnNode(name string, preemptor *api.TaskInfo) ([]*api.TaskInfo, *api.Resource, bool) {
+	if !e.fits(preemptor, name) {
+		return nil, nil, false
+	}
+	resreq := preemptor.Resreq
+	node := e.snapshot.Nodes[name]
+	idle := e.idle[name].Clone()
+	if resreq.LessEqual(idle, api.Zero) {
+		return nil, idle, true
+	}
+
+	var candidates []*api.TaskInfo
+	for _, task := range node.Tasks {
+		if task.Status != api.Running || !task.Preemptable || e.evicted[task.UID] {
+			continue
+		}
+		job, found := e.snapshot.Jobs[task.Job]
+		if !found || string(job.Queue) != e.req.Queue || job.Priority >= e.req.Priority {
+			continue
+		}
+		candidates = append(candidates, task)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		li, ri := e.snapshot.Jobs[candidates[i].Job], e.snapshot.Jobs[candidates[j].Job]
+		if li.Priority != ri.Priority {
+			return li.Priority < ri.Priority
+		}
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority < candidates[j].Priority
+		}
+		return candidates[i].UID < candidates[j].UID
+	})
+
+	occupied := map[api.JobID]int32{}
+	var victims []*api.TaskInfo
+	for _, task := range candidates {
+		job := e.snapshot.Jobs[task.Job]
+		if _, found := occupied[job.UID]; !found {
+			occupied[job.UID] = e.readyTaskNum(job)
+		}
+		if occupied[job.UID] <= job.MinAvailable {
+			continue
+		}
+		occupied[job.UID]--
+		victims = append(victims, task)
+		idle.Add(task.Resreq)
+		if resreq.LessEqual(idle, api.Zero) {
+			return victims, idle, true
+		}
+	}
+	return nil, nil, false
+}
+
+func (e *evaluator) readyTaskNum(job *api.JobInfo) int32 {
+	if _, found := e.occupied[job.UID]; !found {
+		e.occupied[job.UID] = job.ReadyTaskNum()
+	}
+	return e.occupied[job.UID]
+}
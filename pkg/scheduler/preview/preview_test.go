@@ -0,0 +1,286 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/cmd/scheduler/app/options"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/proportion"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestMain(m *testing.M) {
+	options.Default()
+	os.Exit(m.Run())
+}
+
+// newAuthorizer returns the authorizer of the users of the tokens, who may only create the jobs
+// of the namespace ns1.
+func newAuthorizer() *apiauth.Authorizer {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = true
+		review.Status.User = authenticationv1.UserInfo{Username: review.Spec.Token}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = attributes.Resource == "jobs" && attributes.Verb == "create" && attributes.Namespace == "ns1"
+		return true, review, nil
+	})
+	return apiauth.New(client)
+}
+
+func servePreview(handler http.Handler, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, Path, bytes.NewBufferString(body))
+	r.Header.Set("Authorization", "Bearer alice")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func buildSnapshot(runningPriority int32, nodeCPU string) *api.ClusterInfo {
+	node := api.NewNodeInfo(util.BuildNode("n1", api.BuildResourceList(nodeCPU, "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), nil))
+
+	job := api.NewJobInfo("ns1/pg1")
+	job.Name = "pg1"
+	job.Namespace = "ns1"
+	job.Queue = "q1"
+	job.Priority = runningPriority
+	job.MinAvailable = 1
+	for _, name := range []string{"p1", "p2"} {
+		pod := util.BuildPod("ns1", name, "n1", v1.PodRunning, api.BuildResourceList("2", "2Gi"), "pg1", nil, nil)
+		task := api.NewTaskInfo(pod)
+		task.Job = job.UID
+		task.Preemptable = true
+		job.AddTaskInfo(task)
+		node.AddTask(task)
+	}
+
+	return &api.ClusterInfo{
+		Nodes: map[string]*api.NodeInfo{"n1": node},
+		Jobs:  map[api.JobID]*api.JobInfo{job.UID: job},
+		Queues: map[api.QueueID]*api.QueueInfo{
+			"q1": {UID: "q1", Name: "q1", Queue: &scheduling.Queue{Status: scheduling.QueueStatus{State: scheduling.QueueStateOpen}}},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshot  *api.ClusterInfo
+		req       *Request
+		predicate api.PredicateFn
+		expected  *Response
+	}{
+		{
+			name:     "queue does not exist",
+			snapshot: buildSnapshot(1, "4"),
+			req:      &Request{Queue: "q2", Tasks: []TaskSpec{{Name: "t", Replicas: 1}}},
+			expected: &Response{Verdict: Wait, Reason: "queue <q2> does not exist"},
+		},
+		{
+			name:     "more replicas than the nodes hold pods",
+			snapshot: buildSnapshot(1, "6"),
+			req: &Request{Queue: "q1", Priority: 10, Tasks: []TaskSpec{
+				{Name: "t", Replicas: 11, Resources: api.BuildResourceList("1", "1Gi")},
+			}},
+			expected: &Response{Verdict: Wait, Reason: "the job has 11 replicas, the nodes hold 10 pods"},
+		},
+		{
+			name:     "fits into idle resources",
+			snapshot: buildSnapshot(1, "6"),
+			req: &Request{Queue: "q1", Priority: 10, Tasks: []TaskSpec{
+				{Name: "t", Replicas: 1, Resources: api.BuildResourceList("1", "1Gi")},
+			}},
+			expected: &Response{Verdict: Run},
+		},
+		{
+			name:     "preempts one lower priority task",
+			snapshot: buildSnapshot(1, "4"),
+			req: &Request{Queue: "q1", Priority: 10, Tasks: []TaskSpec{
+				{Name: "t", Replicas: 1, Resources: api.BuildResourceList("2", "2Gi")},
+			}},
+			expected: &Response{Verdict: Preempt, Victims: []Victim{
+				{Namespace: "ns1", Name: "p1", Job: "pg1", Node: "n1"},
+			}},
+		},
+		{
+			name:     "does not shrink victim job below minAvailable",
+			snapshot: buildSnapshot(1, "4"),
+			req: &Request{Queue: "q1", Priority: 10, Tasks: []TaskSpec{
+				{Name: "t", Replicas: 2, Resources: api.BuildResourceList("2", "2Gi")},
+			}},
+			expected: &Response{Verdict: Wait, Reason: "only 1 of 2 required tasks can be placed, reclaiming resources from other queues is not evaluated"},
+		},
+		{
+			name:     "higher priority tasks are not preempted",
+			snapshot: buildSnapshot(100, "4"),
+			req: &Request{Queue: "q1", Priority: 10, Tasks: []TaskSpec{
+				{Name: "t", Replicas: 1, Resources: api.BuildResourceList("2", "2Gi")},
+			}},
+			expected: &Response{Verdict: Wait, Reason: "only 0 of 1 required tasks can be placed, reclaiming resources from other queues is not evaluated"},
+		},
+		{
+			name:     "predicates reject the only node",
+			snapshot: buildSnapshot(1, "4"),
+			req: &Request{Queue: "q1", Priority: 10, Tasks: []TaskSpec{
+				{Name: "t", Replicas: 1, Resources: api.BuildResourceList("1", "1Gi"), NodeSelector: map[string]string{"zone": "z2"}},
+			}},
+			predicate: func(task *api.TaskInfo, node *api.NodeInfo) error {
+				if task.Pod.Spec.NodeSelector["zone"] != node.Node.Labels["zone"] {
+					return fmt.Errorf("node selector does not match")
+				}
+				return nil
+			},
+			expected: &Response{Verdict: Wait, Reason: "only 0 of 1 required tasks can be placed, reclaiming resources from other queues is not evaluated"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Evaluate(test.snapshot, test.req, test.predicate)
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	body := `{"queue": "q1", "namespace": "ns1", "tasks": [{"name": "t", "replicas": 1}]}`
+	stopped := func(jobs []*api.JobInfo, fn func(ssn *framework.Session)) error {
+		return fmt.Errorf("scheduler is stopped")
+	}
+	handler := NewHandler(stopped, newAuthorizer())
+
+	codes := map[int]int{}
+	for i := 0; i < previewBurst+1; i++ {
+		codes[servePreview(handler, body).Code]++
+	}
+	if codes[http.StatusServiceUnavailable] != previewBurst {
+		t.Errorf("expected %d requests refused by the stopped scheduler, got %v", previewBurst, codes)
+	}
+	if codes[http.StatusTooManyRequests] != 1 {
+		t.Errorf("expected 1 request rate limited, got %v", codes)
+	}
+}
+
+func TestHandlerLimits(t *testing.T) {
+	withSession := func(jobs []*api.JobInfo, fn func(ssn *framework.Session)) error {
+		t.Errorf("expected the request refused before a session is opened")
+		return nil
+	}
+	handler := NewHandler(withSession, newAuthorizer())
+
+	for name, test := range map[string]struct {
+		body   string
+		status int
+	}{
+		"too many replicas": {
+			body:   fmt.Sprintf(`{"queue": "q1", "namespace": "ns1", "tasks": [{"name": "t", "replicas": %d}]}`, MaxReplicas+1),
+			status: http.StatusBadRequest,
+		},
+		"too large": {
+			body:   `{"queue": "q1", "namespace": "` + strings.Repeat("a", maxRequestBytes) + `"}`,
+			status: http.StatusBadRequest,
+		},
+		"no namespace": {
+			body:   `{"queue": "q1", "tasks": [{"name": "t", "replicas": 1}]}`,
+			status: http.StatusBadRequest,
+		},
+		"other namespace": {
+			body:   `{"queue": "q1", "namespace": "ns2", "tasks": [{"name": "t", "replicas": 1}]}`,
+			status: http.StatusForbidden,
+		},
+	} {
+		if w := servePreview(handler, test.body); w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", name, test.status, w.Code)
+		}
+	}
+}
+
+func TestRedactVictims(t *testing.T) {
+	resp := &Response{Verdict: Preempt, Victims: []Victim{
+		{Namespace: "ns1", Name: "p1", Job: "pg1", Node: "n1"},
+		{Namespace: "ns2", Name: "p2", Job: "pg2", Node: "n1"},
+		{Namespace: "ns3", Name: "p3", Job: "pg3", Node: "n1"},
+	}}
+	redactVictims(resp, "ns1")
+	expected := &Response{Verdict: Preempt, Victims: []Victim{{Namespace: "ns1", Name: "p1", Job: "pg1", Node: "n1"}}, OtherVictims: 2}
+	if !reflect.DeepEqual(resp, expected) {
+		t.Errorf("expected %+v, got %+v", expected, resp)
+	}
+}
+
+func TestEvaluateInSessionQuota(t *testing.T) {
+	framework.RegisterPluginBuilder(proportion.PluginName, proportion.New)
+	trueValue := true
+	n1 := util.BuildNode("n1", api.BuildResourceList("8", "16Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), nil)
+	q1 := util.BuildQueueWithPriorityAndResourcesQuantity("q1", 1, nil, api.BuildResourceList("2", "4Gi"))
+	tiers := []conf.Tier{{Plugins: []conf.PluginOption{{
+		Name:               proportion.PluginName,
+		EnabledJobEnqueued: &trueValue,
+		EnabledOverused:    &trueValue,
+		EnabledAllocatable: &trueValue,
+	}}}}
+
+	for cpu, expected := range map[string]*Response{
+		"1": {Verdict: Run},
+		"4": {Verdict: Wait, Reason: "queue <q1> has not the quota left to enqueue the job"},
+	} {
+		t.Run(cpu, func(t *testing.T) {
+			schedulerCache := cache.NewCustomMockSchedulerCache("preview", util.NewFakeBinder(0), util.NewFakeEvictor(0), &util.FakeStatusUpdater{}, nil, nil, nil)
+			schedulerCache.AddOrUpdateNode(n1)
+			schedulerCache.AddQueueV1beta1(q1)
+			req := &Request{Queue: "q1", Namespace: "ns1", Tasks: []TaskSpec{
+				{Name: "t", Replicas: 1, Resources: api.BuildResourceList(cpu, "1Gi")},
+			}}
+			job, tasks := buildJob(req)
+			preview := framework.OpenPreviewSession(schedulerCache, tiers, nil, []*api.JobInfo{job})
+			defer framework.ClosePreviewSession(preview)
+
+			if got := EvaluateInSession(preview, req, job, tasks); !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected %+v, got %+v", expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preview
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Verdict is the predicted outcome of submitting a job right now.
+type Verdict string
+
+const (
+	// Run means all tasks fit into the idle resources of the cluster, within the quota of the queue.
+	Run Verdict = "Run"
+	// Preempt means the job only fits after evicting lower priority tasks of its queue.
+	Preempt Verdict = "Preempt"
+	// Wait means the job can not be placed now and would stay pending. Reclaiming resources from
+	// other queues is not evaluated, the job may still get them by reclaim.
+	Wait Verdict = "Wait"
+)
+
+// TaskSpec describes a group of identical replicas of the job to preview.
+type TaskSpec struct {
+	Name      string          `json:"name"`
+	Replicas  int32           `json:"replicas"`
+	Resources v1.ResourceList `json:"resources"`
+	// NodeSelector and Tolerations are checked by the predicates of the scheduler.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations  []v1.Toleration   `json:"tolerations,omitempty"`
+}
+
+// Request is the job description posted by the user.
+type Request struct {
+	Namespace string `json:"namespace"`
+	Queue     string `json:"queue"`
+	// Priority is the resolved value of the job's priority class.
+	Priority int32 `json:"priority"`
+	// MinAvailable is the gang size, all replicas are required if it is not set.
+	MinAvailable int32      `json:"minAvailable,omitempty"`
+	Tasks        []TaskSpec `json:"tasks"`
+}
+
+// Victim is a running task that would be evicted to make room for the job.
+type Victim struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Job       string `json:"job"`
+	Node      string `json:"node"`
+}
+
+// Response is the answer returned to the user.
+type Response struct {
+	Verdict Verdict `json:"verdict"`
+	Reason  string  `json:"reason,omitempty"`
+	// Victims are the victims of the namespace of the request, OtherVictims the number of the
+	// victims of the other namespaces, whose names are not disclosed.
+	Victims      []Victim `json:"victims,omitempty"`
+	OtherVictims int      `json:"otherVictims,omitempty"`
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+)
+
+const (
+	// QueuesPath lists the queues, QueuesPath/<name> returns one queue.
+	QueuesPath = "/api/v1/queues"
+	// JobsPath lists the jobs, filtered by the queue, namespace and phase query parameters.
+	JobsPath = "/api/v1/jobs"
+	// UtilizationPath returns the utilization of the cluster.
+	UtilizationPath = "/api/v1/utilization"
+
+	// refreshPeriod is how long a view is served before being built again from the cache, so
+	// that the portals polling the API do not snapshot the cache on each request
+	refreshPeriod = 5 * time.Second
+)
+
+// Server serves the views of the cache, each one shared by the requests of refreshPeriod. The
+// users only see the queues and nodes if they may list them, and the jobs of the namespaces they
+// may list the podgroups of.
+type Server struct {
+	cache      cache.Cache
+	authorizer *apiauth.Authorizer
+	mutex      sync.Mutex
+	view       *View
+}
+
+// NewServer returns the server of the views of the cache.
+func NewServer(c cache.Cache) *Server {
+	return &Server{cache: c}
+}
+
+// Register registers the handlers of the server on the paths of the API.
+func (s *Server) Register(mux apiauth.Mux, authorizer *apiauth.Authorizer) {
+	s.authorizer = authorizer
+	mux.Handle(QueuesPath, s.handler(s.serveQueues))
+	mux.Handle(QueuesPath+"/", s.handler(s.serveQueues))
+	mux.Handle(JobsPath, s.handler(s.serveJobs))
+	mux.Handle(UtilizationPath, s.handler(s.serveUtilization))
+}
+
+func (s *Server) current() *View {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if s.view == nil || now.Sub(s.view.Time) >= refreshPeriod {
+		s.view = Build(s.cache.Snapshot(), now)
+	}
+	return s.view
+}
+
+func (s *Server) handler(serve func(view *View, r *http.Request, user *authenticationv1.UserInfo) (interface{}, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		user, err := s.authorizer.Authenticate(r)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		result, err := serve(s.current(), r, user)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			klog.Errorf("Failed to encode the response of %s: %v", r.URL.Path, err)
+		}
+	})
+}
+
+func (s *Server) serveQueues(view *View, r *http.Request, user *authenticationv1.UserInfo) (interface{}, error) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, QueuesPath), "/")
+	if len(name) == 0 {
+		if err := s.authorizer.Authorize(r.Context(), user, authorizationv1.ResourceAttributes{
+			Group: "scheduling.volcano.sh", Verb: "list", Resource: "queues",
+		}); err != nil {
+			return nil, err
+		}
+		return view.Queues, nil
+	}
+	if err := s.authorizer.Authorize(r.Context(), user, authorizationv1.ResourceAttributes{
+		Group: "scheduling.volcano.sh", Verb: "get", Resource: "queues", Name: name,
+	}); err != nil {
+		return nil, err
+	}
+	for _, queue := range view.Queues {
+		if queue.Name == name {
+			return queue, nil
+		}
+	}
+	return nil, &apiauth.Error{Status: http.StatusNotFound, Message: fmt.Sprintf("queue <%s> not found", name)}
+}
+
+func (s *Server) serveJobs(view *View, r *http.Request, user *authenticationv1.UserInfo) (interface{}, error) {
+	query := r.URL.Query()
+	queue, namespace, phase := query.Get("queue"), query.Get("namespace"), query.Get("phase")
+	// the access of the user to the podgroups of all the namespaces, then of each namespace
+	all, err := s.listsPodGroups(r, user, "")
+	if err != nil {
+		return nil, err
+	}
+	allowed := map[string]bool{}
+	jobs := []JobState{}
+	for _, job := range view.Jobs {
+		if (len(queue) != 0 && job.Queue != queue) ||
+			(len(namespace) != 0 && job.Namespace != namespace) ||
+			(len(phase) != 0 && !strings.EqualFold(job.Phase, phase)) {
+			continue
+		}
+		if !all {
+			lists, found := allowed[job.Namespace]
+			if !found {
+				if lists, err = s.listsPodGroups(r, user, job.Namespace); err != nil {
+					return nil, err
+				}
+				allowed[job.Namespace] = lists
+			}
+			if !lists {
+				continue
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// listsPodGroups returns whether the user may list the podgroups of the namespace, of all the
+// namespaces if empty.
+func (s *Server) listsPodGroups(r *http.Request, user *authenticationv1.UserInfo, namespace string) (bool, error) {
+	return s.authorizer.Allowed(r.Context(), user, authorizationv1.ResourceAttributes{
+		Namespace: namespace, Group: "scheduling.volcano.sh", Verb: "list", Resource: "podgroups",
+	})
+}
+
+func (s *Server) serveUtilization(view *View, r *http.Request, user *authenticationv1.UserInfo) (interface{}, error) {
+	if err := s.authorizer.Authorize(r.Context(), user, authorizationv1.ResourceAttributes{
+		Verb: "list", Resource: "nodes",
+	}); err != nil {
+		return nil, err
+	}
+	return view.Utilization, nil
+}
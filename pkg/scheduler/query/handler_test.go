@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+)
+
+// newAuthorizer returns the authorizer of the users of the tokens "admin", who may list every
+// resource, and "alice", who may only list the podgroups of the namespace ns1.
+func newAuthorizer() *apiauth.Authorizer {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = true
+		review.Status.User = authenticationv1.UserInfo{Username: review.Spec.Token}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "admin" ||
+			(attributes.Resource == "podgroups" && attributes.Verb == "list" && attributes.Namespace == "ns1")
+		return true, review, nil
+	})
+	return apiauth.New(client)
+}
+
+func TestServerAuthorization(t *testing.T) {
+	server := &Server{view: &View{
+		Time:   time.Now().Add(time.Hour),
+		Queues: []QueueState{{Name: "q1"}},
+		Jobs: []JobState{
+			{Namespace: "ns1", Name: "j1", Queue: "q1"},
+			{Namespace: "ns2", Name: "j2", Queue: "q1"},
+		},
+	}}
+	mux := http.NewServeMux()
+	server.Register(mux, newAuthorizer())
+
+	tests := []struct {
+		name   string
+		user   string
+		path   string
+		status int
+		jobs   int
+	}{
+		{name: "no token", path: JobsPath, status: http.StatusUnauthorized},
+		{name: "admin jobs", user: "admin", path: JobsPath, status: http.StatusOK, jobs: 2},
+		{name: "namespaced jobs", user: "alice", path: JobsPath, status: http.StatusOK, jobs: 1},
+		{name: "other namespace", user: "alice", path: JobsPath + "?namespace=ns2", status: http.StatusOK},
+		{name: "admin queues", user: "admin", path: QueuesPath, status: http.StatusOK},
+		{name: "queues", user: "alice", path: QueuesPath, status: http.StatusForbidden},
+		{name: "queue", user: "alice", path: QueuesPath + "/q1", status: http.StatusForbidden},
+		{name: "unknown queue", user: "admin", path: QueuesPath + "/q2", status: http.StatusNotFound},
+		{name: "utilization", user: "alice", path: UtilizationPath, status: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.user != "" {
+				r.Header.Set("Authorization", "Bearer "+test.user)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != test.status {
+				t.Fatalf("expected status %d, got %d: %s", test.status, w.Code, w.Body.String())
+			}
+			if test.status != http.StatusOK || !strings.HasPrefix(test.path, JobsPath) {
+				return
+			}
+			var jobs []JobState
+			if err := json.NewDecoder(w.Body).Decode(&jobs); err != nil {
+				t.Fatalf("failed to decode the jobs: %v", err)
+			}
+			if len(jobs) != test.jobs {
+				t.Errorf("expected %d jobs, got %v", test.jobs, jobs)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package query serves a read-only view of the queues, jobs and utilization of the cluster built
+// from the scheduler cache, for the portals that would otherwise list and watch the API server.
+package query
+
+import (
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+)
+
+// View is the state of the cluster at one time.
+type View struct {
+	Time        time.Time    `json:"time"`
+	Queues      []QueueState `json:"queues"`
+	Jobs        []JobState   `json:"jobs"`
+	Utilization Utilization  `json:"utilization"`
+}
+
+// QueueState is the state of one queue.
+type QueueState struct {
+	Name       string          `json:"name"`
+	Weight     int32           `json:"weight"`
+	State      string          `json:"state,omitempty"`
+	Capability v1.ResourceList `json:"capability,omitempty"`
+	Allocated  v1.ResourceList `json:"allocated"`
+	// PendingJobs are the jobs of the queue whose podgroup is pending or inqueue
+	PendingJobs int `json:"pendingJobs"`
+	RunningJobs int `json:"runningJobs"`
+	// MedianWaitSeconds is the median time the last jobs of the queue waited to run
+	MedianWaitSeconds float64 `json:"medianWaitSeconds,omitempty"`
+}
+
+// JobState is the state of one job.
+type JobState struct {
+	Namespace    string          `json:"namespace"`
+	Name         string          `json:"name"`
+	Queue        string          `json:"queue"`
+	Phase        string          `json:"phase"`
+	MinAvailable int32           `json:"minAvailable"`
+	Tasks        int             `json:"tasks"`
+	PendingTasks int             `json:"pendingTasks"`
+	Request      v1.ResourceList `json:"request"`
+	Allocated    v1.ResourceList `json:"allocated"`
+	Created      time.Time       `json:"created"`
+	// EstimatedStart is, for the pending jobs, when they are expected to run given the median
+	// wait of the last jobs of their queue
+	EstimatedStart *time.Time `json:"estimatedStart,omitempty"`
+}
+
+// Utilization is the allocation of the resources of the ready nodes.
+type Utilization struct {
+	Nodes       int                `json:"nodes"`
+	Allocatable v1.ResourceList    `json:"allocatable"`
+	Used        v1.ResourceList    `json:"used"`
+	Ratio       map[string]float64 `json:"ratio"`
+}
+
+func isPending(job *api.JobInfo) bool {
+	if job.PodGroup == nil {
+		return false
+	}
+	phase := job.PodGroup.Status.Phase
+	return phase == scheduling.PodGroupPending || phase == scheduling.PodGroupInqueue
+}
+
+// Build builds the view of the snapshot of the cache.
+func Build(snapshot *api.ClusterInfo, now time.Time) *View {
+	view := &View{Time: now, Queues: []QueueState{}, Jobs: []JobState{}}
+
+	queues := map[api.QueueID]*QueueState{}
+	allocated := map[api.QueueID]*api.Resource{}
+	waits := map[api.QueueID]time.Duration{}
+	for id, queue := range snapshot.Queues {
+		state := &QueueState{Name: queue.Name, Weight: queue.Weight}
+		if queue.Queue != nil {
+			state.State = string(queue.Queue.Status.State)
+			state.Capability = queue.Queue.Spec.Capability
+		}
+		if p50, _, found := metrics.QueueSchedulingLatency(queue.Name); found {
+			state.MedianWaitSeconds = p50.Seconds()
+			waits[id] = p50
+		}
+		queues[id] = state
+		allocated[id] = api.EmptyResource()
+	}
+
+	for _, job := range snapshot.Jobs {
+		if job.PodGroup == nil {
+			continue
+		}
+		state := JobState{
+			Namespace:    job.Namespace,
+			Name:         job.Name,
+			Queue:        string(job.Queue),
+			Phase:        string(job.PodGroup.Status.Phase),
+			MinAvailable: job.MinAvailable,
+			Tasks:        len(job.Tasks),
+			PendingTasks: len(job.TaskStatusIndex[api.Pending]),
+			Request:      resourceList(job.TotalRequest),
+			Allocated:    resourceList(job.Allocated),
+			Created:      job.CreationTimestamp.Time,
+		}
+		queue := queues[job.Queue]
+		switch {
+		case isPending(job):
+			if wait, found := waits[job.Queue]; found {
+				start := job.CreationTimestamp.Add(wait)
+				if start.Before(now) {
+					start = now
+				}
+				state.EstimatedStart = &start
+			}
+			if queue != nil {
+				queue.PendingJobs++
+			}
+		case job.PodGroup.Status.Phase == scheduling.PodGroupRunning && queue != nil:
+			queue.RunningJobs++
+		}
+		if queue != nil && job.Allocated != nil {
+			allocated[job.Queue].Add(job.Allocated)
+		}
+		view.Jobs = append(view.Jobs, state)
+	}
+	sort.Slice(view.Jobs, func(i, j int) bool {
+		if view.Jobs[i].Namespace != view.Jobs[j].Namespace {
+			return view.Jobs[i].Namespace < view.Jobs[j].Namespace
+		}
+		return view.Jobs[i].Name < view.Jobs[j].Name
+	})
+
+	for id, queue := range queues {
+		queue.Allocated = resourceList(allocated[id])
+		view.Queues = append(view.Queues, *queue)
+	}
+	sort.Slice(view.Queues, func(i, j int) bool {
+		return view.Queues[i].Name < view.Queues[j].Name
+	})
+
+	total, used := api.EmptyResource(), api.EmptyResource()
+	for _, node := range snapshot.Nodes {
+		if !node.Ready() {
+			continue
+		}
+		view.Utilization.Nodes++
+		total.Add(node.Allocatable)
+		used.Add(node.Used)
+	}
+	view.Utilization.Allocatable = resourceList(total)
+	view.Utilization.Used = resourceList(used)
+	view.Utilization.Ratio = map[string]float64{}
+	for _, rn := range total.ResourceNames() {
+		if total.Get(rn) > 0 {
+			view.Utilization.Ratio[string(rn)] = used.Get(rn) / total.Get(rn)
+		}
+	}
+	return view
+}
+
+// resourceList converts the resource of the cache, in milli units except the memory, to quantities.
+func resourceList(r *api.Resource) v1.ResourceList {
+	list := v1.ResourceList{}
+	if r == nil {
+		return list
+	}
+	list[v1.ResourceCPU] = *resource.NewMilliQuantity(int64(r.MilliCPU), resource.DecimalSI)
+	list[v1.ResourceMemory] = *resource.NewQuantity(int64(r.Memory), resource.BinarySI)
+	for name, value := range r.ScalarResources {
+		list[name] = *resource.NewMilliQuantity(int64(value), resource.DecimalSI)
+	}
+	return list
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestBuild(t *testing.T) {
+	now := time.Now()
+	metrics.RecordQueueSchedulingLatency("query-q1", 1, 10*time.Minute)
+
+	newJob := func(name string, phase scheduling.PodGroupPhase, created time.Time, pods ...*v1.Pod) *api.JobInfo {
+		var tasks []*api.TaskInfo
+		for _, pod := range pods {
+			tasks = append(tasks, api.NewTaskInfo(pod))
+		}
+		job := api.NewJobInfo(api.JobID("ns/"+name), tasks...)
+		job.SetPodGroup(&api.PodGroup{PodGroup: scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       scheduling.PodGroupSpec{Queue: "query-q1", MinMember: int32(len(pods))},
+			Status:     scheduling.PodGroupStatus{Phase: phase},
+		}})
+		return job
+	}
+	running := util.BuildPod("ns", "running-0", "n1", v1.PodRunning, api.BuildResourceList("2", "4Gi"), "running", nil, nil)
+	pending := util.BuildPod("ns", "pending-0", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pending", nil, nil)
+	late := util.BuildPod("ns", "late-0", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "late", nil, nil)
+
+	node := api.NewNodeInfo(util.BuildNode("n1", api.BuildResourceList("8", "16Gi"), nil))
+	runningJob := newJob("running", scheduling.PodGroupRunning, now.Add(-time.Hour), running)
+	for _, task := range runningJob.Tasks {
+		node.AddTask(task)
+	}
+
+	snapshot := &api.ClusterInfo{
+		Jobs: map[api.JobID]*api.JobInfo{
+			"ns/running": runningJob,
+			"ns/pending": newJob("pending", scheduling.PodGroupInqueue, now.Add(-time.Minute), pending),
+			"ns/late":    newJob("late", scheduling.PodGroupPending, now.Add(-time.Hour), late),
+		},
+		Nodes: map[string]*api.NodeInfo{"n1": node},
+		Queues: map[api.QueueID]*api.QueueInfo{
+			"query-q1": api.NewQueueInfo(&scheduling.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "query-q1"},
+				Spec:       scheduling.QueueSpec{Weight: 1},
+				Status:     scheduling.QueueStatus{State: scheduling.QueueStateOpen},
+			}),
+		},
+	}
+
+	view := Build(snapshot, now)
+	if len(view.Queues) != 1 {
+		t.Fatalf("expected 1 queue, got %v", view.Queues)
+	}
+	queue := view.Queues[0]
+	if queue.PendingJobs != 2 || queue.RunningJobs != 1 {
+		t.Errorf("expected 2 pending and 1 running jobs, got %d and %d", queue.PendingJobs, queue.RunningJobs)
+	}
+	if cpu := queue.Allocated[v1.ResourceCPU]; cpu.MilliValue() != 2000 {
+		t.Errorf("expected 2 allocated cpus, got %v", cpu.String())
+	}
+	if queue.MedianWaitSeconds != 600 {
+		t.Errorf("expected a median wait of 600s, got %v", queue.MedianWaitSeconds)
+	}
+
+	estimated := map[string]*time.Time{}
+	for _, job := range view.Jobs {
+		estimated[job.Name] = job.EstimatedStart
+	}
+	if start := estimated["pending"]; start == nil || !start.Equal(now.Add(9*time.Minute)) {
+		t.Errorf("expected the pending job to start in 9 minutes, got %v", start)
+	}
+	if start := estimated["late"]; start == nil || !start.Equal(now) {
+		t.Errorf("expected the job past its median wait to start now, got %v", start)
+	}
+	if start := estimated["running"]; start != nil {
+		t.Errorf("expected no estimated start for the running job, got %v", start)
+	}
+
+	if view.Utilization.Nodes != 1 || math.Abs(view.Utilization.Ratio["cpu"]-0.25) > 1e-9 {
+		t.Errorf("expected 1 node with a quarter of its cpus used, got %+v", view.Utilization)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+)
+
+// Path is the HTTP path the reservations are created and listed on, filtered by the queue query
+// parameter; Path/<id> releases one reservation. The users only list and release their own
+// reservations, and only reserve quota for the namespaces they may create jobs in, on the queues
+// which admit their jobs.
+const Path = "/api/v1/reservations"
+
+// Register registers the handlers of the manager on the paths of the API.
+func (m *Manager) Register(mux apiauth.Mux, authorizer *apiauth.Authorizer) {
+	m.authorizer = authorizer
+	mux.Handle(Path, m.handler(m.serveReservations))
+	mux.Handle(Path+"/", m.handler(m.serveReservation))
+}
+
+func (m *Manager) handler(serve func(w http.ResponseWriter, r *http.Request, user *authenticationv1.UserInfo)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := m.authorizer.Authenticate(r)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		serve(w, r, user)
+	})
+}
+
+func (m *Manager) serveReservations(w http.ResponseWriter, r *http.Request, user *authenticationv1.UserInfo) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, m.Reservations(user.Username, r.URL.Query().Get("queue")))
+	case http.MethodPost:
+		req := &Request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(req.Queue) == 0 || len(req.Namespace) == 0 || len(req.Resources) == 0 {
+			http.Error(w, "queue, namespace and resources are required", http.StatusBadRequest)
+			return
+		}
+		if err := m.authorizer.Authorize(r.Context(), user, authorizationv1.ResourceAttributes{
+			Namespace: req.Namespace,
+			Group:     "batch.volcano.sh",
+			Verb:      "create",
+			Resource:  "jobs",
+		}); err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		reservation, err := m.Reserve(*user, req)
+		switch {
+		case errors.Is(err, ErrQueueNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ErrQueueAccessDenied):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, ErrTooManyReservations):
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case errors.Is(err, ErrQuotaExceeded):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeJSON(w, http.StatusCreated, reservation)
+		}
+	default:
+		http.Error(w, "only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (m *Manager) serveReservation(w http.ResponseWriter, r *http.Request, user *authenticationv1.UserInfo) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "only DELETE is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, Path+"/")
+	if len(id) == 0 {
+		http.Error(w, "reservation not found", http.StatusNotFound)
+		return
+	}
+	err := m.Release(user.Username, id)
+	switch {
+	case errors.Is(err, ErrReservationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		klog.Errorf("Failed to encode reservations: %v", err)
+	}
+}
@@ -0,0 +1,487 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota reserves the quota of the queues for the jobs the external submission portals are
+// about to create, so that they do not submit beyond the capability of the queues. The
+// reservations are kept in an annotation of their queue, so that they survive the restarts of the
+// scheduler, and the proportion plugin keeps the quota they hold from the other jobs. The API is
+// only served over HTTP, there is no gRPC service.
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	vcclient "volcano.sh/apis/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+	webhooksutil "volcano.sh/volcano/pkg/webhooks/util"
+)
+
+const (
+	// ReservationAnnotationKey is the annotation of the job, or of its podgroup, giving the ID of the
+	// reservation it was created with; the reservation is released once the requests of the job
+	// cover it.
+	ReservationAnnotationKey = "volcano.sh/quota-reservation"
+	// ReservationsAnnotationKey is the annotation of the queue holding, in JSON, the reservations
+	// outstanding on the queue.
+	ReservationsAnnotationKey = "volcano.sh/quota-reservations"
+
+	// DefaultTTL is how long a reservation is held when the request gives no TTL.
+	DefaultTTL = 5 * time.Minute
+	// MaxTTL is the longest a reservation is held.
+	MaxTTL = time.Hour
+	// MaxReservationsPerNamespace is the most reservations outstanding on a queue for the jobs of
+	// a namespace, and MaxReservationsPerUser the most a user has outstanding on a queue, so that
+	// no one holds the quota of a queue by reserving it repeatedly.
+	MaxReservationsPerNamespace = 32
+	MaxReservationsPerUser      = 8
+
+	// refreshPeriod is how long a snapshot of the cache is used before a new one is taken
+	refreshPeriod = 2 * time.Second
+)
+
+var (
+	// ErrQueueNotFound is returned when the queue of a reservation does not exist.
+	ErrQueueNotFound = errors.New("queue not found")
+	// ErrQuotaExceeded is returned when the queue has not the quota left for a reservation.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrReservationNotFound is returned when the reservation to release does not exist, or is
+	// not owned by the user releasing it.
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrTooManyReservations is returned when the user, or the namespace of the request, has the
+	// most reservations outstanding on the queue already.
+	ErrTooManyReservations = errors.New("too many reservations")
+	// ErrQueueAccessDenied is returned when the user may not submit the jobs of the namespace of
+	// the request to the queue.
+	ErrQueueAccessDenied = errors.New("queue access denied")
+)
+
+// Request is a request for the reservation of the quota of a job.
+type Request struct {
+	Queue     string          `json:"queue"`
+	Namespace string          `json:"namespace"`
+	Job       string          `json:"job,omitempty"`
+	Resources v1.ResourceList `json:"resources"`
+	// TTLSeconds is how long the reservation is held, DefaultTTL if 0
+	TTLSeconds int64 `json:"ttlSeconds,omitempty"`
+}
+
+// Reservation is quota of a queue held for a job until its requests cover it or the reservation
+// expires.
+type Reservation struct {
+	ID        string          `json:"id"`
+	Queue     string          `json:"queue"`
+	Namespace string          `json:"namespace"`
+	Job       string          `json:"job,omitempty"`
+	Resources v1.ResourceList `json:"resources"`
+	Expires   time.Time       `json:"expires"`
+	// Owner is the user who made the reservation, the only one who may release it
+	Owner string `json:"owner"`
+}
+
+// ParseReservations returns the reservations of the queue from its annotations.
+func ParseReservations(queue string, annotations map[string]string) ([]*Reservation, error) {
+	value, found := annotations[ReservationsAnnotationKey]
+	if !found || len(value) == 0 {
+		return nil, nil
+	}
+	var reservations []*Reservation
+	if err := json.Unmarshal([]byte(value), &reservations); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s of queue %s: %v", ReservationsAnnotationKey, queue, err)
+	}
+	return reservations, nil
+}
+
+// JobRequest returns the resources the job claims in its queue: the requests of its tasks, or its
+// minimum resources while its tasks are not created yet.
+func JobRequest(job *api.JobInfo) *api.Resource {
+	request := job.TotalRequest.Clone()
+	if job.PodGroup != nil && job.PodGroup.Spec.MinResources != nil {
+		request.SetMaxResource(api.NewResource(*job.PodGroup.Spec.MinResources))
+	}
+	return request
+}
+
+// Outstanding returns, by ID, the quota the reservations of the queue hold beyond the requests of
+// the jobs created with them, leaving out the reservations expired or covered by their jobs.
+func Outstanding(queue *api.QueueInfo, jobs map[api.JobID]*api.JobInfo, now time.Time) map[string]*api.Resource {
+	if queue.Queue == nil {
+		return nil
+	}
+	reservations, err := ParseReservations(queue.Name, queue.Queue.Annotations)
+	if err != nil {
+		klog.Warningf("Ignored the quota reservations: %v", err)
+		return nil
+	}
+	if len(reservations) == 0 {
+		return nil
+	}
+	requests := jobRequests(queue.UID, jobs)
+	outstanding := map[string]*api.Resource{}
+	for _, r := range reservations {
+		if remaining, held := remaining(r, requests, now); held {
+			outstanding[r.ID] = remaining
+		}
+	}
+	return outstanding
+}
+
+// reservedJob is a job created with a reservation.
+type reservedJob struct {
+	namespace string
+	name      string
+	request   *api.Resource
+}
+
+// jobName returns the name of the job of the podgroup: the name of its controller, e.g. of the
+// Volcano job, or its own name.
+func jobName(pg *api.PodGroup) string {
+	for _, owner := range pg.OwnerReferences {
+		if owner.Controller != nil && *owner.Controller {
+			return owner.Name
+		}
+	}
+	return pg.Name
+}
+
+// jobRequests returns the jobs of the queue created with a reservation, by ID.
+func jobRequests(queue api.QueueID, jobs map[api.JobID]*api.JobInfo) map[string][]reservedJob {
+	requests := map[string][]reservedJob{}
+	for _, job := range jobs {
+		if job.Queue != queue || job.PodGroup == nil {
+			continue
+		}
+		if id, found := job.PodGroup.Annotations[ReservationAnnotationKey]; found {
+			requests[id] = append(requests[id], reservedJob{
+				namespace: job.PodGroup.Namespace,
+				name:      jobName(job.PodGroup),
+				request:   JobRequest(job),
+			})
+		}
+	}
+	return requests
+}
+
+// remaining returns the quota the reservation holds beyond the requests of its jobs, and false
+// if it expired or the requests of its jobs cover it. Only the jobs of the namespace, and of the
+// name, of the reservation count against it: its ID is readable by the users of the queue.
+func remaining(r *Reservation, requests map[string][]reservedJob, now time.Time) (*api.Resource, bool) {
+	if !now.Before(r.Expires) {
+		return nil, false
+	}
+	resreq := api.NewResource(r.Resources)
+	request := api.EmptyResource()
+	created := false
+	for _, job := range requests[r.ID] {
+		if job.namespace != r.Namespace || (len(r.Job) != 0 && job.name != r.Job) {
+			continue
+		}
+		request.Add(job.request)
+		created = true
+	}
+	if !created {
+		return resreq, true
+	}
+	if resreq.LessEqual(request, api.Zero) {
+		return nil, false
+	}
+	left, _ := resreq.Diff(request, api.Zero)
+	return left, true
+}
+
+// Manager grants the reservations against the capability of the queues, less the requests of
+// their jobs and the reservations outstanding.
+type Manager struct {
+	mutex    sync.Mutex
+	now      func() time.Time
+	snapshot func() *api.ClusterInfo
+	client   vcclient.Interface
+	// authorizer authenticates and authorizes the requests of the API
+	authorizer *apiauth.Authorizer
+
+	cluster     *api.ClusterInfo
+	clusterTime time.Time
+}
+
+// NewManager returns a manager granting reservations against the snapshots of the cache, and
+// keeping them in the queues with the client.
+func NewManager(snapshot func() *api.ClusterInfo, client vcclient.Interface) *Manager {
+	return &Manager{
+		now:      time.Now,
+		snapshot: snapshot,
+		client:   client,
+	}
+}
+
+// refresh takes a new snapshot when the current one is too old. It is called with the mutex held.
+func (m *Manager) refresh(now time.Time) {
+	if m.cluster == nil || now.Sub(m.clusterTime) >= refreshPeriod {
+		m.cluster = m.snapshot()
+		m.clusterTime = now
+	}
+}
+
+// update updates the reservations of the queue read from the API server, the ones expired or
+// covered by their jobs are dropped. The queue is patched at the version read, and read again on
+// conflicts.
+func (m *Manager) update(name string, now time.Time, fn func(queue *schedulingv1beta1.Queue, reservations []*Reservation) ([]*Reservation, error)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		queue, err := m.client.SchedulingV1beta1().Queues().Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return ErrQueueNotFound
+		}
+		if err != nil {
+			return err
+		}
+		reservations, err := ParseReservations(queue.Name, queue.Annotations)
+		if err != nil {
+			return err
+		}
+		requests := jobRequests(api.QueueID(queue.Name), m.cluster.Jobs)
+		var held []*Reservation
+		for _, r := range reservations {
+			if _, ok := remaining(r, requests, now); ok {
+				held = append(held, r)
+			}
+		}
+		updated, err := fn(queue, held)
+		if err != nil {
+			return err
+		}
+		return m.patch(queue, updated)
+	})
+}
+
+func (m *Manager) patch(queue *schedulingv1beta1.Queue, reservations []*Reservation) error {
+	var value interface{}
+	if len(reservations) != 0 {
+		data, err := json.Marshal(reservations)
+		if err != nil {
+			return err
+		}
+		value = string(data)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": queue.ResourceVersion,
+			"annotations":     map[string]interface{}{ReservationsAnnotationKey: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = m.client.SchedulingV1beta1().Queues().Patch(context.TODO(), queue.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// Reserve reserves for the user the quota of the request if the queue has it left, and the user
+// may submit the jobs of the namespace of the request to the queue.
+func (m *Manager) Reserve(user authenticationv1.UserInfo, req *Request) (*Reservation, error) {
+	ttl := DefaultTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > MaxTTL {
+		return nil, fmt.Errorf("ttl %v must be positive and at most %v", ttl, MaxTTL)
+	}
+	resreq := api.NewResource(req.Resources)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.refresh(now)
+	if _, found := m.cluster.Queues[api.QueueID(req.Queue)]; !found {
+		return nil, ErrQueueNotFound
+	}
+
+	var reservation *Reservation
+	err := m.update(req.Queue, now, func(queue *schedulingv1beta1.Queue, reservations []*Reservation) ([]*Reservation, error) {
+		if queue.Status.State != "" && queue.Status.State != schedulingv1beta1.QueueStateOpen {
+			return nil, fmt.Errorf("queue %s is %s", queue.Name, queue.Status.State)
+		}
+		if err := webhooksutil.CheckQueueAccess(queue, req.Namespace, user); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrQueueAccessDenied, err)
+		}
+		var ofUser, ofNamespace int
+		for _, r := range reservations {
+			if r.Owner == user.Username {
+				ofUser++
+			}
+			if r.Namespace == req.Namespace {
+				ofNamespace++
+			}
+		}
+		if ofUser >= MaxReservationsPerUser || ofNamespace >= MaxReservationsPerNamespace {
+			return nil, fmt.Errorf("%w: at most %d reservations of a user and %d of a namespace are outstanding on queue %s",
+				ErrTooManyReservations, MaxReservationsPerUser, MaxReservationsPerNamespace, queue.Name)
+		}
+
+		queueID := api.QueueID(queue.Name)
+		capability, limited := m.capability(queue.Spec.Capability)
+		used := api.EmptyResource()
+		for _, job := range m.cluster.Jobs {
+			if job.Queue != queueID || job.PodGroup == nil || job.PodGroup.Status.Phase == scheduling.PodGroupCompleted {
+				continue
+			}
+			used.Add(JobRequest(job))
+		}
+		requests := jobRequests(queueID, m.cluster.Jobs)
+		for _, r := range reservations {
+			if left, held := remaining(r, requests, now); held {
+				used.Add(left)
+			}
+		}
+		used.Add(resreq)
+
+		var exceeded []string
+		for _, rn := range resreq.ResourceNames() {
+			if _, found := limited[rn]; !found {
+				continue
+			}
+			if used.Get(rn) > capability.Get(rn) {
+				exceeded = append(exceeded, string(rn))
+			}
+		}
+		if len(exceeded) != 0 {
+			sort.Strings(exceeded)
+			return nil, fmt.Errorf("%w: queue %s has not enough %s left", ErrQuotaExceeded, queue.Name, strings.Join(exceeded, ", "))
+		}
+
+		reservation = &Reservation{
+			ID:        string(uuid.NewUUID()),
+			Queue:     queue.Name,
+			Namespace: req.Namespace,
+			Job:       req.Job,
+			Resources: req.Resources,
+			Expires:   now.Add(ttl),
+			Owner:     user.Username,
+		}
+		return append(reservations, reservation), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// capability returns the capability of a queue and the resources it limits: the ones of the
+// capability of its spec, or all the resources of the ready nodes when it has none.
+func (m *Manager) capability(capability v1.ResourceList) (*api.Resource, map[v1.ResourceName]struct{}) {
+	limited := map[v1.ResourceName]struct{}{}
+	if len(capability) != 0 {
+		for rn := range capability {
+			limited[rn] = struct{}{}
+		}
+		return api.NewResource(capability), limited
+	}
+
+	total := api.EmptyResource()
+	for _, node := range m.cluster.Nodes {
+		if node.Ready() {
+			total.Add(node.Allocatable)
+		}
+	}
+	for _, rn := range total.ResourceNames() {
+		limited[rn] = struct{}{}
+	}
+	return total, limited
+}
+
+// Release releases the reservation of the user, it returns ErrReservationNotFound if the user
+// has no such reservation.
+func (m *Manager) Release(user, id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.refresh(now)
+	for _, r := range m.reservations(now) {
+		if r.ID != id || r.Owner != user {
+			continue
+		}
+		return m.update(r.Queue, now, func(_ *schedulingv1beta1.Queue, reservations []*Reservation) ([]*Reservation, error) {
+			var kept []*Reservation
+			for _, held := range reservations {
+				if held.ID != id {
+					kept = append(kept, held)
+				}
+			}
+			return kept, nil
+		})
+	}
+	return ErrReservationNotFound
+}
+
+// Reservations returns the reservations of the user outstanding, of the queue if not empty,
+// sorted by ID.
+func (m *Manager) Reservations(user, queue string) []Reservation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.refresh(now)
+	reservations := []Reservation{}
+	for _, r := range m.reservations(now) {
+		if r.Owner == user && (len(queue) == 0 || r.Queue == queue) {
+			reservations = append(reservations, *r)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].ID < reservations[j].ID
+	})
+	return reservations
+}
+
+// reservations returns the reservations outstanding in the queues of the snapshot.
+func (m *Manager) reservations(now time.Time) []*Reservation {
+	var outstanding []*Reservation
+	for _, queue := range m.cluster.Queues {
+		if queue.Queue == nil {
+			continue
+		}
+		reservations, err := ParseReservations(queue.Name, queue.Queue.Annotations)
+		if err != nil {
+			klog.Warningf("Ignored the quota reservations: %v", err)
+			continue
+		}
+		requests := jobRequests(queue.UID, m.cluster.Jobs)
+		for _, r := range reservations {
+			if _, held := remaining(r, requests, now); held {
+				outstanding = append(outstanding, r)
+			}
+		}
+	}
+	return outstanding
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/apis/pkg/client/clientset/versioned/fake"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/util"
+	webhooksutil "volcano.sh/volcano/pkg/webhooks/util"
+)
+
+// newCluster returns the client of the queue q1 of capability 10 cpus, and the snapshots of the
+// cluster with the queue as stored by the client and the jobs.
+func newCluster(t *testing.T, capability v1.ResourceList, jobs map[api.JobID]*api.JobInfo, nodes map[string]*api.NodeInfo) (*fake.Clientset, func() *api.ClusterInfo) {
+	client := fake.NewSimpleClientset(&schedulingv1beta1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       schedulingv1beta1.QueueSpec{Capability: capability},
+		Status:     schedulingv1beta1.QueueStatus{State: schedulingv1beta1.QueueStateOpen},
+	})
+	snapshot := func() *api.ClusterInfo {
+		stored, err := client.SchedulingV1beta1().Queues().Get(context.TODO(), "q1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get the queue: %v", err)
+		}
+		queue := &scheduling.Queue{}
+		if err := schedulingv1beta1.Convert_v1beta1_Queue_To_scheduling_Queue(stored, queue, nil); err != nil {
+			t.Fatalf("failed to convert the queue: %v", err)
+		}
+		queueInfo := api.NewQueueInfo(queue)
+		return &api.ClusterInfo{
+			Queues: map[api.QueueID]*api.QueueInfo{queueInfo.UID: queueInfo},
+			Jobs:   jobs,
+			Nodes:  nodes,
+		}
+	}
+	return client, snapshot
+}
+
+func userInfo(name string) authenticationv1.UserInfo {
+	return authenticationv1.UserInfo{Username: name}
+}
+
+func TestManager(t *testing.T) {
+	now := time.Now()
+	newJob := func(name, cpu string, annotations map[string]string) *api.JobInfo {
+		job := &api.JobInfo{UID: api.JobID("ns/" + name), Queue: "q1", TotalRequest: api.NewResource(api.BuildResourceList(cpu, "1Gi"))}
+		job.PodGroup = &api.PodGroup{PodGroup: scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Annotations: annotations},
+			Status:     scheduling.PodGroupStatus{Phase: scheduling.PodGroupRunning},
+		}}
+		return job
+	}
+	jobs := map[api.JobID]*api.JobInfo{"ns/running": newJob("running", "4", nil)}
+	client, snapshot := newCluster(t, v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")}, jobs, nil)
+	m := NewManager(snapshot, client)
+	m.now = func() time.Time { return now }
+
+	request := func(cpu string, ttl int64) *Request {
+		return &Request{Queue: "q1", Namespace: "ns", Resources: api.BuildResourceList(cpu, "1Gi"), TTLSeconds: ttl}
+	}
+	if _, err := m.Reserve(userInfo("alice"), &Request{Queue: "q2", Resources: api.BuildResourceList("1", "0")}); !errors.Is(err, ErrQueueNotFound) {
+		t.Errorf("expected the reservation in an unknown queue to fail, got %v", err)
+	}
+	first, err := m.Reserve(userInfo("alice"), request("4", 0))
+	if err != nil {
+		t.Fatalf("failed to reserve 4 cpus of the 6 left: %v", err)
+	}
+	if !first.Expires.Equal(now.Add(DefaultTTL)) {
+		t.Errorf("expected the reservation to expire after %v, got %v", DefaultTTL, first.Expires)
+	}
+	if _, err := m.Reserve(userInfo("alice"), request("3", 0)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected the reservation of 3 cpus of the 2 left to fail, got %v", err)
+	}
+	second, err := m.Reserve(userInfo("bob"), request("2", 60))
+	if err != nil {
+		t.Fatalf("failed to reserve the 2 cpus left: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Errorf("expected the reservations to have different IDs, got %s", first.ID)
+	}
+	if _, err := m.Reserve(userInfo("alice"), request("1", 2*int64(MaxTTL/time.Second))); err == nil {
+		t.Errorf("expected a ttl above %v to be rejected", MaxTTL)
+	}
+
+	// the reservations are kept by the queue, a manager of another scheduler sees them
+	other := NewManager(snapshot, client)
+	other.now = m.now
+	if reservations := other.Reservations("alice", "q1"); len(reservations) != 1 || reservations[0].ID != first.ID {
+		t.Fatalf("expected the reservation of alice only, got %v", reservations)
+	}
+	if err := other.Release("alice", second.ID); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("expected alice not to release the reservation of bob, got %v", err)
+	}
+
+	// the podgroup of the job of the first reservation is created before its pods, the
+	// reservation is held until the requests of the job cover it
+	now = now.Add(refreshPeriod)
+	jobs["ns/created"] = newJob("created", "0", map[string]string{ReservationAnnotationKey: first.ID})
+	if reservations := m.Reservations("alice", "q1"); len(reservations) != 1 || reservations[0].ID != first.ID {
+		t.Fatalf("expected the first reservation held while its job has no pods, got %v", reservations)
+	}
+	if _, err := m.Reserve(userInfo("alice"), request("1", 0)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected the quota of the first reservation to be kept, got %v", err)
+	}
+	now = now.Add(refreshPeriod)
+	jobs["ns/created"] = newJob("created", "4", map[string]string{ReservationAnnotationKey: first.ID})
+	if reservations := m.Reservations("alice", "q1"); len(reservations) != 0 {
+		t.Fatalf("expected the first reservation released once its job requests it, got %v", reservations)
+	}
+	if _, err := m.Reserve(userInfo("alice"), request("1", 0)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected the quota of the created job to be kept, got %v", err)
+	}
+
+	// the second reservation expires
+	now = now.Add(time.Minute)
+	if _, err := m.Reserve(userInfo("alice"), request("2", 0)); err != nil {
+		t.Errorf("expected the quota of the expired reservation to be released, got %v", err)
+	}
+	if err := m.Release("bob", second.ID); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("expected the expired reservation to be dropped, got %v", err)
+	}
+}
+
+func TestOutstanding(t *testing.T) {
+	now := time.Now()
+	job := &api.JobInfo{UID: "ns/created", Queue: "q1", TotalRequest: api.NewResource(api.BuildResourceList("1", "0"))}
+	job.PodGroup = &api.PodGroup{PodGroup: scheduling.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "created", Annotations: map[string]string{ReservationAnnotationKey: "created"}},
+	}}
+	queue := api.NewQueueInfo(&scheduling.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1", Annotations: map[string]string{
+		ReservationsAnnotationKey: `[{"id":"pending","queue":"q1","namespace":"ns","resources":{"cpu":"2"},"expires":"` + now.Add(time.Minute).Format(time.RFC3339) + `"},` +
+			`{"id":"created","queue":"q1","namespace":"ns","resources":{"cpu":"4"},"expires":"` + now.Add(time.Minute).Format(time.RFC3339) + `"},` +
+			`{"id":"expired","queue":"q1","resources":{"cpu":"8"},"expires":"` + now.Add(-time.Minute).Format(time.RFC3339) + `"}]`,
+	}}})
+
+	// a job of another namespace copies the ID of the pending reservation from the queue
+	stolen := &api.JobInfo{UID: "other/stolen", Queue: "q1", TotalRequest: api.NewResource(api.BuildResourceList("2", "0"))}
+	stolen.PodGroup = &api.PodGroup{PodGroup: scheduling.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "stolen", Annotations: map[string]string{ReservationAnnotationKey: "pending"}},
+	}}
+
+	outstanding := Outstanding(queue, map[api.JobID]*api.JobInfo{job.UID: job, stolen.UID: stolen}, now)
+	if len(outstanding) != 2 || outstanding["pending"].MilliCPU != 2000 || outstanding["created"].MilliCPU != 3000 {
+		t.Errorf("expected 2 cpus reserved for the pending job and 3 beyond the created job, got %v", outstanding)
+	}
+}
+
+func TestReservationOfOtherJob(t *testing.T) {
+	now := time.Now()
+	newJob := func(namespace, name string) *api.JobInfo {
+		job := &api.JobInfo{UID: api.JobID(namespace + "/" + name), Queue: "q1", TotalRequest: api.NewResource(api.BuildResourceList("4", "0"))}
+		job.PodGroup = &api.PodGroup{PodGroup: scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: map[string]string{ReservationAnnotationKey: "r1"}},
+		}}
+		return job
+	}
+	queue := api.NewQueueInfo(&scheduling.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1", Annotations: map[string]string{
+		ReservationsAnnotationKey: `[{"id":"r1","queue":"q1","namespace":"ns","job":"train","resources":{"cpu":"4"},"expires":"` + now.Add(time.Minute).Format(time.RFC3339) + `"}]`,
+	}}})
+
+	for _, job := range []*api.JobInfo{newJob("other", "train"), newJob("ns", "serve")} {
+		outstanding := Outstanding(queue, map[api.JobID]*api.JobInfo{job.UID: job}, now)
+		if len(outstanding) != 1 || outstanding["r1"].MilliCPU != 4000 {
+			t.Errorf("expected the reservation held against job %s, got %v", job.UID, outstanding)
+		}
+	}
+	job := newJob("ns", "train")
+	if outstanding := Outstanding(queue, map[api.JobID]*api.JobInfo{job.UID: job}, now); len(outstanding) != 0 {
+		t.Errorf("expected the reservation covered by its job, got %v", outstanding)
+	}
+}
+
+func TestCapabilityOfUnlimitedQueue(t *testing.T) {
+	node := api.NewNodeInfo(util.BuildNode("n1", api.BuildResourceList("8", "16Gi"), nil))
+	client, snapshot := newCluster(t, nil, nil, map[string]*api.NodeInfo{node.Name: node})
+	m := NewManager(snapshot, client)
+
+	if _, err := m.Reserve(userInfo("alice"), &Request{Queue: "q1", Resources: api.BuildResourceList("6", "8Gi")}); err != nil {
+		t.Fatalf("failed to reserve within the cluster: %v", err)
+	}
+	if _, err := m.Reserve(userInfo("alice"), &Request{Queue: "q1", Resources: api.BuildResourceList("4", "0")}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected the reservation beyond the cluster to fail, got %v", err)
+	}
+}
+
+func TestReserveLimits(t *testing.T) {
+	client, snapshot := newCluster(t, v1.ResourceList{v1.ResourceCPU: resource.MustParse("100")}, nil, nil)
+	queue, err := client.SchedulingV1beta1().Queues().Get(context.TODO(), "q1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the queue: %v", err)
+	}
+	queue.Annotations = map[string]string{webhooksutil.QueueAllowedServiceAccountsKey: "ns:portal"}
+	if _, err := client.SchedulingV1beta1().Queues().Update(context.TODO(), queue, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update the queue: %v", err)
+	}
+	m := NewManager(snapshot, client)
+	request := &Request{Queue: "q1", Namespace: "ns", Resources: api.BuildResourceList("1", "0")}
+
+	if _, err := m.Reserve(userInfo("system:serviceaccount:ns:other"), request); !errors.Is(err, ErrQueueAccessDenied) {
+		t.Errorf("expected the service account not allowed on the queue to be denied, got %v", err)
+	}
+	portal := userInfo("system:serviceaccount:ns:portal")
+	for i := 0; i < MaxReservationsPerUser; i++ {
+		if _, err := m.Reserve(portal, request); err != nil {
+			t.Fatalf("failed to reserve %d: %v", i, err)
+		}
+	}
+	if _, err := m.Reserve(portal, request); !errors.Is(err, ErrTooManyReservations) {
+		t.Errorf("expected the reservations beyond %d to be refused, got %v", MaxReservationsPerUser, err)
+	}
+}
+
+func TestReserveFailedPatch(t *testing.T) {
+	client, snapshot := newCluster(t, v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")}, nil, nil)
+	forbidden := apierrors.NewForbidden(schedulingv1beta1.Resource("queues"), "q1", errors.New("patch is not allowed"))
+	client.PrependReactor("patch", "queues", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, forbidden
+	})
+	m := NewManager(snapshot, client)
+
+	if _, err := m.Reserve(userInfo("alice"), &Request{Queue: "q1", Namespace: "ns", Resources: api.BuildResourceList("1", "0")}); !apierrors.IsForbidden(err) {
+		t.Fatalf("expected the reservation to fail when the queue cannot be patched, got %v", err)
+	}
+	if reservations := m.Reservations("alice", "q1"); len(reservations) != 0 {
+		t.Errorf("expected no reservation kept, got %v", reservations)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay drives the scheduler offline from the cache events recorded in production,
+// running a session at each recorded scheduling cycle, to reproduce placement issues.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/snapshot"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+const (
+	// maxRecordSize is the maximum size of a recorded event
+	maxRecordSize = 16 * 1024 * 1024
+	// quietPeriod is how long to wait for a new bind or eviction before considering the
+	// decisions of a cycle complete
+	quietPeriod = 100 * time.Millisecond
+)
+
+// CycleResult is the decisions the scheduler made in a replayed cycle.
+type CycleResult struct {
+	Cycle int
+	// Binds is the node of each bound pod, by namespace/name
+	Binds map[string]string
+	// Evicts is the evicted pods, by namespace/name
+	Evicts []string
+}
+
+// replayer feeds the recorded events to a mock cache and schedules at the recorded cycles.
+type replayer struct {
+	actions []framework.Action
+	tiers   []conf.Tier
+	configs []conf.Configuration
+
+	cache   *cache.SchedulerCache
+	binder  *util.FakeBinder
+	evictor *util.FakeEvictor
+	// objects is the last recorded version of each object, for updates
+	objects map[string]interface{}
+	cycle   int
+}
+
+// Replay replays the recording with the scheduler configuration, the default one if empty,
+// as the scheduler of the given name.
+func Replay(r io.Reader, schedulerName, schedulerConf string) ([]*CycleResult, error) {
+	return ReplayFromSnapshot(nil, r, schedulerName, schedulerConf)
+}
+
+// ReplayFromSnapshot replays the recording on top of the state of the cluster in the snapshot,
+// for the recordings started on a running cluster.
+func ReplayFromSnapshot(from *snapshot.Snapshot, r io.Reader, schedulerName, schedulerConf string) ([]*CycleResult, error) {
+	if schedulerConf == "" {
+		schedulerConf = scheduler.DefaultSchedulerConf
+	}
+	actions, tiers, configs, _, err := scheduler.UnmarshalSchedulerConf(schedulerConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scheduler conf: %v", err)
+	}
+
+	rp := &replayer{
+		actions: actions,
+		tiers:   tiers,
+		configs: configs,
+		binder:  util.NewFakeBinder(4096),
+		evictor: util.NewFakeEvictor(4096),
+		objects: map[string]interface{}{},
+	}
+	rp.cache = cache.NewCustomMockSchedulerCache(schedulerName, rp.binder, rp.evictor, &util.FakeStatusUpdater{}, nil, nil, &record.FakeRecorder{})
+	stop := make(chan struct{})
+	defer close(stop)
+	rp.cache.Run(stop)
+	if from != nil {
+		if err := rp.load(from); err != nil {
+			return nil, fmt.Errorf("failed to load snapshot: %v", err)
+		}
+	}
+
+	var results []*CycleResult
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		rec := &cache.EventRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return results, fmt.Errorf("failed to parse record at line %d: %v", line, err)
+		}
+		if rec.Kind == cache.EventKindCycle {
+			results = append(results, rp.runCycle())
+			continue
+		}
+		if err := rp.apply(rec); err != nil {
+			return results, fmt.Errorf("failed to replay record at line %d: %v", line, err)
+		}
+	}
+	return results, scanner.Err()
+}
+
+// apply feeds the recorded event to the cache.
+func (rp *replayer) apply(rec *cache.EventRecord) error {
+	switch rec.Kind {
+	case cache.EventKindPod:
+		pod := &v1.Pod{}
+		if err := json.Unmarshal(rec.Object, pod); err != nil {
+			return err
+		}
+		old := rp.update(rec, pod.Namespace, pod.Name, pod)
+		switch {
+		case rec.Op == cache.EventOpDelete:
+			rp.cache.DeletePod(pod)
+		case old != nil:
+			rp.cache.UpdatePod(old, pod)
+		default:
+			rp.cache.AddPod(pod)
+		}
+	case cache.EventKindNode:
+		node := &v1.Node{}
+		if err := json.Unmarshal(rec.Object, node); err != nil {
+			return err
+		}
+		if rec.Op == cache.EventOpDelete {
+			return rp.cache.RemoveNode(node.Name)
+		}
+		return rp.cache.AddOrUpdateNode(node)
+	case cache.EventKindPodGroup:
+		pg := &schedulingv1beta1.PodGroup{}
+		if err := json.Unmarshal(rec.Object, pg); err != nil {
+			return err
+		}
+		old := rp.update(rec, pg.Namespace, pg.Name, pg)
+		switch {
+		case rec.Op == cache.EventOpDelete:
+			rp.cache.DeletePodGroupV1beta1(pg)
+		case old != nil:
+			rp.cache.UpdatePodGroupV1beta1(old, pg)
+		default:
+			rp.cache.AddPodGroupV1beta1(pg)
+		}
+	case cache.EventKindQueue:
+		queue := &schedulingv1beta1.Queue{}
+		if err := json.Unmarshal(rec.Object, queue); err != nil {
+			return err
+		}
+		old := rp.update(rec, "", queue.Name, queue)
+		switch {
+		case rec.Op == cache.EventOpDelete:
+			rp.cache.DeleteQueueV1beta1(queue)
+		case old != nil:
+			rp.cache.UpdateQueueV1beta1(old, queue)
+		default:
+			rp.cache.AddQueueV1beta1(queue)
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", rec.Kind)
+	}
+	return nil
+}
+
+// load feeds the objects of the snapshot to the cache, as if they were recorded.
+func (rp *replayer) load(s *snapshot.Snapshot) error {
+	for _, queue := range s.Queues {
+		if queue.Queue != nil {
+			rp.objects[fmt.Sprintf("%s//%s", cache.EventKindQueue, queue.Name)] = queue.Queue
+			rp.cache.AddQueueV1beta1(queue.Queue)
+		}
+	}
+	for _, node := range s.Nodes {
+		if node.Node == nil {
+			continue
+		}
+		if err := rp.cache.AddOrUpdateNode(node.Node); err != nil {
+			return err
+		}
+	}
+	for _, job := range s.Jobs {
+		if job.PodGroup != nil {
+			rp.objects[fmt.Sprintf("%s/%s/%s", cache.EventKindPodGroup, job.Namespace, job.Name)] = job.PodGroup
+			rp.cache.AddPodGroupV1beta1(job.PodGroup)
+		}
+		for _, task := range job.Tasks {
+			if task.Pod != nil {
+				rp.objects[fmt.Sprintf("%s/%s/%s", cache.EventKindPod, task.Namespace, task.Name)] = task.Pod
+				rp.cache.AddPod(task.Pod)
+			}
+		}
+	}
+	return nil
+}
+
+// update stores the recorded object and returns its previous version, nil if there is none.
+func (rp *replayer) update(rec *cache.EventRecord, namespace, name string, obj interface{}) interface{} {
+	key := fmt.Sprintf("%s/%s/%s", rec.Kind, namespace, name)
+	old := rp.objects[key]
+	if rec.Op == cache.EventOpDelete {
+		delete(rp.objects, key)
+	} else {
+		rp.objects[key] = obj
+	}
+	return old
+}
+
+// runCycle runs a session and collects its binds and evictions.
+func (rp *replayer) runCycle() *CycleResult {
+	rp.cycle++
+	conf.EnabledActionMap = make(map[string]bool, len(rp.actions))
+	for _, action := range rp.actions {
+		conf.EnabledActionMap[action.Name()] = true
+	}
+
+	ssn := framework.OpenSession(rp.cache, rp.tiers, rp.configs)
+	for _, action := range rp.actions {
+		action.Initialize()
+		ssn.RunAction(action)
+		action.UnInitialize()
+	}
+	framework.CloseSession(ssn)
+
+	result := &CycleResult{Cycle: rp.cycle, Binds: map[string]string{}}
+	for {
+		select {
+		case key := <-rp.binder.Channel:
+			result.Binds[key] = rp.binder.Binds()[key]
+		case key := <-rp.evictor.Channel:
+			result.Evicts = append(result.Evicts, key)
+		case <-time.After(quietPeriod):
+			sort.Strings(result.Evicts)
+			return result
+		}
+	}
+}
+
+// Print writes the decisions of the cycles which made some.
+func Print(w io.Writer, results []*CycleResult) {
+	for _, result := range results {
+		if len(result.Binds) == 0 && len(result.Evicts) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "cycle %d:\n", result.Cycle)
+		pods := make([]string, 0, len(result.Binds))
+		for pod := range result.Binds {
+			pods = append(pods, pod)
+		}
+		sort.Strings(pods)
+		for _, pod := range pods {
+			fmt.Fprintf(w, "  bind %s -> %s\n", pod, result.Binds[pod])
+		}
+		for _, pod := range result.Evicts {
+			fmt.Fprintf(w, "  evict %s\n", pod)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/cmd/scheduler/app/options"
+	_ "volcano.sh/volcano/pkg/scheduler/actions"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+const replayConf = `
+actions: "allocate"
+tiers:
+- plugins:
+  - name: gang
+  - name: predicates
+`
+
+func TestReplay(t *testing.T) {
+	options.Default()
+
+	recording := &bytes.Buffer{}
+	write := func(kind, op string, obj interface{}) {
+		rec := cache.EventRecord{Kind: kind, Op: op}
+		if obj != nil {
+			rec.Object, _ = json.Marshal(obj)
+		}
+		json.NewEncoder(recording).Encode(&rec)
+	}
+
+	pod := util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", nil, nil)
+	write(cache.EventKindNode, cache.EventOpAdd, util.BuildNode("n1", api.BuildResourceList("2", "4Gi", api.ScalarResource{Name: "pods", Value: "10"}), nil))
+	write(cache.EventKindQueue, cache.EventOpAdd, util.BuildQueue("c1", 1, nil))
+	write(cache.EventKindPodGroup, cache.EventOpAdd, util.BuildPodGroup("pg1", "c1", "c1", 1, nil, schedulingv1beta1.PodGroupInqueue))
+	write(cache.EventKindPod, cache.EventOpAdd, pod)
+	write(cache.EventKindCycle, "", nil)
+	// the pod was bound elsewhere by the time of the second cycle
+	bound := pod.DeepCopy()
+	bound.Spec.NodeName = "n1"
+	bound.Status.Phase = v1.PodRunning
+	write(cache.EventKindPod, cache.EventOpUpdate, bound)
+	write(cache.EventKindCycle, "", nil)
+
+	results, err := Replay(recording, "volcano", replayConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 cycles, got %d", len(results))
+	}
+	if !reflect.DeepEqual(results[0].Binds, map[string]string{"c1/p1": "n1"}) {
+		t.Errorf("expected c1/p1 to be bound to n1 in the first cycle, got %v", results[0].Binds)
+	}
+	if len(results[1].Binds) != 0 {
+		t.Errorf("expected no bind in the second cycle, got %v", results[1].Binds)
+	}
+}
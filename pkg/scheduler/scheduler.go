@@ -31,10 +31,12 @@ import (
 
 	"volcano.sh/volcano/cmd/scheduler/app/options"
 	"volcano.sh/volcano/pkg/filewatcher"
+	"volcano.sh/volcano/pkg/scheduler/api"
 	schedcache "volcano.sh/volcano/pkg/scheduler/cache"
 	"volcano.sh/volcano/pkg/scheduler/conf"
 	"volcano.sh/volcano/pkg/scheduler/framework"
 	"volcano.sh/volcano/pkg/scheduler/metrics"
+	"volcano.sh/volcano/pkg/scheduler/stats"
 )
 
 // Scheduler represents a "Volcano Scheduler".
@@ -45,12 +47,20 @@ type Scheduler struct {
 	schedulerConf  string
 	fileWatcher    filewatcher.FileWatcher
 	schedulePeriod time.Duration
-	once           sync.Once
+	// minSchedulePeriod and maxSchedulePeriod bound the adaptive period, which is used when they are set
+	minSchedulePeriod time.Duration
+	maxSchedulePeriod time.Duration
+	once              sync.Once
+
+	// sessionMutex is held for the whole scheduling cycle, stopped is set by Shutdown
+	sessionMutex sync.Mutex
+	stopped      bool
 
 	mutex          sync.Mutex
 	actions        []framework.Action
 	plugins        []conf.Tier
 	configurations []conf.Configuration
+	profiles       []*schedulerProfile
 	metricsConf    map[string]string
 	dumper         schedcache.Dumper
 }
@@ -74,57 +84,179 @@ func NewScheduler(config *rest.Config, opt *options.ServerOption) (*Scheduler, e
 		cache:          cache,
 		schedulePeriod: opt.SchedulePeriod,
 		dumper:         schedcache.Dumper{Cache: cache, RootDir: opt.CacheDumpFileDir},
+
+		minSchedulePeriod: opt.MinSchedulePeriod,
+		maxSchedulePeriod: opt.MaxSchedulePeriod,
 	}
+	scheduler.loadSchedulerConf()
 
 	return scheduler, nil
 }
 
-// Run initializes and starts the Scheduler. It loads the configuration,
+// Run initializes and starts the Scheduler. It watches the configuration,
 // initializes the cache, and begins the scheduling process.
 func (pc *Scheduler) Run(stopCh <-chan struct{}) {
-	pc.loadSchedulerConf()
 	go pc.watchSchedulerConf(stopCh)
 	// Start cache for policy.
 	pc.cache.SetMetricsConf(pc.metricsConf)
 	pc.cache.Run(stopCh)
 	klog.V(2).Infof("Scheduler completes Initialization and start to run")
-	go wait.Until(pc.runOnce, pc.schedulePeriod, stopCh)
+	if pc.minSchedulePeriod > 0 && pc.maxSchedulePeriod > 0 {
+		go pc.runAdaptively(stopCh)
+	} else {
+		go wait.Until(func() { pc.runOnce() }, pc.schedulePeriod, stopCh)
+	}
 	if options.ServerOpts.EnableCacheDumper {
 		pc.dumper.ListenForSignal(stopCh)
 	}
 	go runSchedulerSocket()
 }
 
+// Shutdown stops opening new sessions, waits for the running one and for the
+// in-flight binds and evictions, all within timeout. The tasks still assumed
+// on a node when the timeout expires are nominated to it, for the next leader
+// to place them back there.
+func (pc *Scheduler) Shutdown(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	locked := make(chan struct{})
+	go func() {
+		pc.sessionMutex.Lock()
+		pc.stopped = true
+		pc.sessionMutex.Unlock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+	case <-time.After(timeout):
+		klog.Warningf("Scheduling session still running after %v, shutting down anyway", timeout)
+	}
+
+	tasks := pc.cache.Drain(time.Until(deadline))
+	if len(tasks) == 0 {
+		klog.Infof("Scheduler drained all in-flight binds and evictions")
+		return
+	}
+	klog.Warningf("%d tasks are still assumed on their node after %v, nominated them to it", len(tasks), timeout)
+}
+
+// Cache returns the scheduler cache which backs the scheduling sessions.
+func (pc *Scheduler) Cache() schedcache.Cache {
+	return pc.cache
+}
+
+// PluginConfigured returns whether the plugin is enabled at the top level or in a profile of the
+// configuration loaded last.
+func (pc *Scheduler) PluginConfigured(name string) bool {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	if hasPlugin(pc.plugins, name) {
+		return true
+	}
+	for _, profile := range pc.profiles {
+		if hasPlugin(profile.plugins, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunPreviewSession runs fn in a session opened with the top level configuration and the jobs
+// added to the snapshot, between two scheduling cycles. Nothing of the session is written back,
+// neither to the cluster nor to the plugins and metrics of the scheduling sessions.
+func (pc *Scheduler) RunPreviewSession(jobs []*api.JobInfo, fn func(ssn *framework.Session)) error {
+	pc.sessionMutex.Lock()
+	defer pc.sessionMutex.Unlock()
+	if pc.stopped {
+		return fmt.Errorf("scheduler is stopped")
+	}
+
+	pc.mutex.Lock()
+	plugins := pc.plugins
+	configurations := pc.configurations
+	pc.mutex.Unlock()
+
+	ssn := framework.OpenPreviewSession(pc.cache, plugins, configurations, jobs)
+	defer framework.ClosePreviewSession(ssn)
+	fn(ssn)
+	return nil
+}
+
 // runOnce executes a single scheduling cycle. This function is called periodically
-// as defined by the Scheduler's schedule period.
-func (pc *Scheduler) runOnce() {
+// as defined by the Scheduler's schedule period. It returns the number of tasks left pending.
+func (pc *Scheduler) runOnce() int {
+	pc.sessionMutex.Lock()
+	defer pc.sessionMutex.Unlock()
+	if pc.stopped {
+		return 0
+	}
+
 	klog.V(4).Infof("Start scheduling ...")
 	scheduleStartTime := time.Now()
 	defer klog.V(4).Infof("End scheduling ...")
+	pc.cache.RecordCycle()
+	stats.Default().StartCycle()
+	defer stats.Default().EndCycle()
 
 	pc.mutex.Lock()
 	actions := pc.actions
 	plugins := pc.plugins
 	configurations := pc.configurations
+	profiles := pc.profiles
 	pc.mutex.Unlock()
 
+	defer func() {
+		metrics.UpdateE2eDuration(metrics.Duration(scheduleStartTime))
+	}()
+
+	if len(profiles) == 0 {
+		return pc.runSession("", actions, plugins, configurations, nil)
+	}
+
+	// Jobs of the scheduler names without a profile, or without pods yet, are
+	// scheduled with the top level configuration, then each profile runs in turn.
+	profileNames := map[string]bool{}
+	for _, profile := range profiles {
+		profileNames[profile.schedulerName] = true
+	}
+	pending := pc.runSession("", actions, plugins, configurations, func(job *api.JobInfo) bool {
+		return !profileNames[job.GetSchedulerName()]
+	})
+	for _, profile := range profiles {
+		schedulerName := profile.schedulerName
+		klog.V(4).Infof("Start scheduling profile <%s> ...", schedulerName)
+		pending += pc.runSession(schedulerName, profile.actions, profile.plugins, profile.configurations, func(job *api.JobInfo) bool {
+			return job.GetSchedulerName() == schedulerName
+		})
+	}
+	return pending
+}
+
+// runSession opens a session of the profile for the jobs accepted by jobFilter and executes the
+// actions in it, it returns the number of tasks of these jobs left pending.
+func (pc *Scheduler) runSession(profile string, actions []framework.Action, plugins []conf.Tier, configurations []conf.Configuration,
+	jobFilter func(*api.JobInfo) bool) int {
 	// Load ConfigMap to check which action is enabled.
 	conf.EnabledActionMap = make(map[string]bool)
 	for _, action := range actions {
 		conf.EnabledActionMap[action.Name()] = true
 	}
 
-	ssn := framework.OpenSession(pc.cache, plugins, configurations)
-	defer func() {
-		framework.CloseSession(ssn)
-		metrics.UpdateE2eDuration(metrics.Duration(scheduleStartTime))
-	}()
+	ssn := framework.OpenSessionWithJobFilter(pc.cache, profile, plugins, configurations, jobFilter)
+	defer framework.CloseSession(ssn)
 
 	for _, action := range actions {
 		actionStartTime := time.Now()
-		action.Execute(ssn)
+		ssn.RunAction(action)
 		metrics.UpdateActionDuration(action.Name(), metrics.Duration(actionStartTime))
 	}
+	stats.Default().Record(ssn)
+
+	pending := 0
+	for _, job := range ssn.Jobs {
+		pending += len(job.TaskStatusIndex[api.Pending])
+	}
+	return pending
 }
 
 func (pc *Scheduler) loadSchedulerConf() {
@@ -159,11 +291,25 @@ func (pc *Scheduler) loadSchedulerConf() {
 		klog.Errorf("Scheduler config %s is invalid: %v", config, err)
 		return
 	}
+	profiles, err := unmarshalSchedulerProfiles(config)
+	if err != nil {
+		klog.Errorf("Scheduler profiles in config %s are invalid: %v", config, err)
+		return
+	}
+
+	// the pods of the scheduler names of the profiles are cached too, the names of the
+	// profiles removed later stay registered until the scheduler restarts
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.schedulerName)
+	}
+	pc.cache.AddSchedulerNames(names...)
 
 	pc.mutex.Lock()
 	pc.actions = actions
 	pc.plugins = plugins
 	pc.configurations = configurations
+	pc.profiles = profiles
 	pc.metricsConf = metricsConf
 	pc.mutex.Unlock()
 }
@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot defines the versioned format the state of the scheduler cache is exported in,
+// for the tools which simulate or replay the scheduling and for the support bundles.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"volcano.sh/apis/pkg/apis/scheduling"
+	schedulingscheme "volcano.sh/apis/pkg/apis/scheduling/scheme"
+	"volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// Version is the version of the snapshot format, it changes on incompatible changes only.
+const Version = "v1"
+
+// Snapshot is the state of the scheduler cache. Besides the summary of each object for the
+// tooling, it holds the Kubernetes objects the cache was built from, to load it back.
+type Snapshot struct {
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+	Nodes   []*Node   `json:"nodes"`
+	Queues  []*Queue  `json:"queues"`
+	Jobs    []*Job    `json:"jobs"`
+}
+
+// Node is a node of the cluster.
+type Node struct {
+	Name        string          `json:"name"`
+	Allocatable v1.ResourceList `json:"allocatable,omitempty"`
+	Used        v1.ResourceList `json:"used,omitempty"`
+	Idle        v1.ResourceList `json:"idle,omitempty"`
+	Node        *v1.Node        `json:"node"`
+	// Pods are the pods on the node which belong to no job, e.g. those of the DaemonSets and of
+	// the other schedulers, they use the node too.
+	Pods []*v1.Pod `json:"pods,omitempty"`
+}
+
+// Queue is a queue of the cluster.
+type Queue struct {
+	Name   string         `json:"name"`
+	Weight int32          `json:"weight"`
+	Queue  *v1beta1.Queue `json:"queue,omitempty"`
+}
+
+// Job is a PodGroup and its tasks.
+type Job struct {
+	Namespace    string            `json:"namespace"`
+	Name         string            `json:"name"`
+	Queue        string            `json:"queue"`
+	MinAvailable int32             `json:"minAvailable"`
+	Priority     int32             `json:"priority"`
+	PodGroup     *v1beta1.PodGroup `json:"podGroup,omitempty"`
+	Tasks        []*Task           `json:"tasks,omitempty"`
+}
+
+// Task is a pod of a job.
+type Task struct {
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Status    string          `json:"status"`
+	NodeName  string          `json:"nodeName,omitempty"`
+	Resreq    v1.ResourceList `json:"resreq,omitempty"`
+	Pod       *v1.Pod         `json:"pod"`
+}
+
+// Export returns the snapshot of the cluster, with its objects sorted by name.
+func Export(ci *api.ClusterInfo, now time.Time) (*Snapshot, error) {
+	s := &Snapshot{Version: Version, Time: now}
+
+	jobTasks := map[api.TaskID]bool{}
+	for _, job := range ci.Jobs {
+		for id := range job.Tasks {
+			jobTasks[id] = true
+		}
+	}
+	for _, node := range ci.Nodes {
+		n := &Node{
+			Name:        node.Name,
+			Allocatable: resourceList(node.Allocatable),
+			Used:        resourceList(node.Used),
+			Idle:        resourceList(node.Idle),
+			Node:        node.Node,
+		}
+		for _, task := range node.Tasks {
+			if !jobTasks[task.UID] && task.Pod != nil {
+				n.Pods = append(n.Pods, task.Pod)
+			}
+		}
+		sort.Slice(n.Pods, func(a, b int) bool {
+			if n.Pods[a].Namespace != n.Pods[b].Namespace {
+				return n.Pods[a].Namespace < n.Pods[b].Namespace
+			}
+			return n.Pods[a].Name < n.Pods[b].Name
+		})
+		s.Nodes = append(s.Nodes, n)
+	}
+	sort.Slice(s.Nodes, func(i, j int) bool { return s.Nodes[i].Name < s.Nodes[j].Name })
+
+	for _, queue := range ci.Queues {
+		q := &Queue{Name: queue.Name, Weight: queue.Weight}
+		if queue.Queue != nil {
+			q.Queue = &v1beta1.Queue{}
+			if err := schedulingscheme.Scheme.Convert(queue.Queue, q.Queue, nil); err != nil {
+				return nil, fmt.Errorf("failed to convert queue <%s>: %v", queue.Name, err)
+			}
+		}
+		s.Queues = append(s.Queues, q)
+	}
+	sort.Slice(s.Queues, func(i, j int) bool { return s.Queues[i].Name < s.Queues[j].Name })
+
+	for _, job := range ci.Jobs {
+		j := &Job{
+			Namespace:    job.Namespace,
+			Name:         job.Name,
+			Queue:        string(job.Queue),
+			MinAvailable: job.MinAvailable,
+			Priority:     job.Priority,
+		}
+		if job.PodGroup != nil {
+			j.PodGroup = &v1beta1.PodGroup{}
+			if err := schedulingscheme.Scheme.Convert(&job.PodGroup.PodGroup, j.PodGroup, nil); err != nil {
+				return nil, fmt.Errorf("failed to convert podgroup <%s/%s>: %v", job.Namespace, job.Name, err)
+			}
+		}
+		for _, task := range job.Tasks {
+			j.Tasks = append(j.Tasks, &Task{
+				Namespace: task.Namespace,
+				Name:      task.Name,
+				Status:    task.Status.String(),
+				NodeName:  task.NodeName,
+				Resreq:    resourceList(task.Resreq),
+				Pod:       task.Pod,
+			})
+		}
+		sort.Slice(j.Tasks, func(a, b int) bool { return j.Tasks[a].Name < j.Tasks[b].Name })
+		s.Jobs = append(s.Jobs, j)
+	}
+	sort.Slice(s.Jobs, func(i, j int) bool {
+		if s.Jobs[i].Namespace != s.Jobs[j].Namespace {
+			return s.Jobs[i].Namespace < s.Jobs[j].Namespace
+		}
+		return s.Jobs[i].Name < s.Jobs[j].Name
+	})
+	return s, nil
+}
+
+// Write encodes the snapshot as JSON.
+func Write(w io.Writer, s *Snapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Read decodes a snapshot written by Write, of the same version.
+func Read(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %q, expected %q", s.Version, Version)
+	}
+	return s, nil
+}
+
+// ClusterInfo loads the snapshot back into the cluster info the sessions are opened on.
+func (s *Snapshot) ClusterInfo() (*api.ClusterInfo, error) {
+	ci := &api.ClusterInfo{
+		Jobs:           map[api.JobID]*api.JobInfo{},
+		Nodes:          map[string]*api.NodeInfo{},
+		Queues:         map[api.QueueID]*api.QueueInfo{},
+		NamespaceInfo:  map[api.NamespaceName]*api.NamespaceInfo{},
+		RevocableNodes: map[string]*api.NodeInfo{},
+		CSINodesStatus: map[string]*api.CSINodeStatusInfo{},
+	}
+
+	for _, node := range s.Nodes {
+		if node.Node == nil {
+			return nil, fmt.Errorf("node <%s> has no object", node.Name)
+		}
+		ni := api.NewNodeInfo(node.Node)
+		for _, pod := range node.Pods {
+			if err := ni.AddTask(api.NewTaskInfo(pod)); err != nil {
+				return nil, fmt.Errorf("failed to add pod <%s/%s> to node <%s>: %v", pod.Namespace, pod.Name, node.Name, err)
+			}
+		}
+		ci.Nodes[node.Name] = ni
+		ci.NodeList = append(ci.NodeList, node.Name)
+	}
+
+	for _, queue := range s.Queues {
+		if queue.Queue == nil {
+			return nil, fmt.Errorf("queue <%s> has no object", queue.Name)
+		}
+		q := &scheduling.Queue{}
+		if err := schedulingscheme.Scheme.Convert(queue.Queue, q, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert queue <%s>: %v", queue.Name, err)
+		}
+		ci.Queues[api.QueueID(queue.Name)] = api.NewQueueInfo(q)
+	}
+
+	for _, job := range s.Jobs {
+		jobID := api.JobID(job.Namespace + "/" + job.Name)
+		ji := api.NewJobInfo(jobID)
+		if job.PodGroup != nil {
+			pg := &api.PodGroup{Version: api.PodGroupVersionV1Beta1}
+			if err := schedulingscheme.Scheme.Convert(job.PodGroup, &pg.PodGroup, nil); err != nil {
+				return nil, fmt.Errorf("failed to convert podgroup <%s>: %v", jobID, err)
+			}
+			ji.SetPodGroup(pg)
+		}
+		for _, task := range job.Tasks {
+			if task.Pod == nil {
+				return nil, fmt.Errorf("task <%s/%s> has no object", task.Namespace, task.Name)
+			}
+			ti := api.NewTaskInfo(task.Pod)
+			ti.Job = jobID
+			ji.AddTaskInfo(ti)
+			if node, found := ci.Nodes[ti.NodeName]; found {
+				if err := node.AddTask(ti); err != nil {
+					return nil, fmt.Errorf("failed to add task <%s/%s> to node <%s>: %v", ti.Namespace, ti.Name, node.Name, err)
+				}
+			}
+		}
+		ci.Jobs[jobID] = ji
+	}
+	return ci, nil
+}
+
+// resourceList returns the resource as a resource list, in the units of the Kubernetes objects:
+// the memory and the pods are counted in units, the others in milli units.
+func resourceList(r *api.Resource) v1.ResourceList {
+	if r == nil {
+		return nil
+	}
+	rl := v1.ResourceList{}
+	for _, name := range r.ResourceNames() {
+		switch name {
+		case v1.ResourceMemory, v1.ResourcePods:
+			rl[name] = *resource.NewQuantity(int64(r.Get(name)), resource.BinarySI)
+		default:
+			rl[name] = *resource.NewMilliQuantity(int64(r.Get(name)), resource.DecimalSI)
+		}
+	}
+	return rl
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestRoundTrip(t *testing.T) {
+	s := &Snapshot{
+		Version: Version,
+		Nodes: []*Node{
+			{Name: "n1", Node: util.BuildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{})},
+		},
+		Queues: []*Queue{
+			{Name: "q1", Queue: util.BuildQueue("q1", 1, nil)},
+		},
+		Jobs: []*Job{
+			{
+				Namespace: "ns1",
+				Name:      "pg1",
+				PodGroup:  util.BuildPodGroup("pg1", "ns1", "q1", 2, nil, schedulingv1beta1.PodGroupRunning),
+				Tasks: []*Task{
+					{Namespace: "ns1", Name: "p1", Pod: util.BuildPod("ns1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", map[string]string{}, map[string]string{})},
+					{Namespace: "ns1", Name: "p2", Pod: util.BuildPod("ns1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1Gi"), "pg1", map[string]string{}, map[string]string{})},
+				},
+			},
+		},
+	}
+
+	ci, err := s.ClusterInfo()
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	job, found := ci.Jobs["ns1/pg1"]
+	if !found || job.Queue != "q1" || job.MinAvailable != 2 || len(job.Tasks) != 2 {
+		t.Fatalf("unexpected job %v", job)
+	}
+	if len(ci.Nodes["n1"].Tasks) != 1 || ci.Nodes["n1"].Used.MilliCPU != 1000 {
+		t.Fatalf("expected the running task on node n1, got %v", ci.Nodes["n1"])
+	}
+
+	exported, err := Export(ci, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := Write(buf, exported); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	read, err := Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+
+	if len(read.Nodes) != 1 || read.Nodes[0].Used.Cpu().Cmp(resource.MustParse("1")) != 0 ||
+		read.Nodes[0].Used.Memory().Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("unexpected nodes %v", read.Nodes)
+	}
+	if len(read.Queues) != 1 || read.Queues[0].Name != "q1" || read.Queues[0].Queue == nil {
+		t.Errorf("unexpected queues %v", read.Queues)
+	}
+	if len(read.Jobs) != 1 || len(read.Jobs[0].Tasks) != 2 {
+		t.Fatalf("unexpected jobs %v", read.Jobs)
+	}
+	p1, p2 := read.Jobs[0].Tasks[0], read.Jobs[0].Tasks[1]
+	if p1.Name != "p1" || p1.Status != api.Running.String() || p1.NodeName != "n1" {
+		t.Errorf("unexpected task %+v", p1)
+	}
+	if p2.Name != "p2" || p2.Status != api.Pending.String() || p2.NodeName != "" {
+		t.Errorf("unexpected task %+v", p2)
+	}
+}
+
+func TestRoundTripPodsOutOfJobs(t *testing.T) {
+	s := &Snapshot{
+		Version: Version,
+		Nodes: []*Node{
+			{
+				Name: "n1",
+				Node: util.BuildNode("n1", api.BuildResourceList("4", "8Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), map[string]string{}),
+				Pods: []*v1.Pod{
+					util.BuildPod("kube-system", "ds1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "", map[string]string{}, map[string]string{}),
+				},
+			},
+		},
+		Queues: []*Queue{
+			{Name: "q1", Queue: util.BuildQueue("q1", 1, nil)},
+		},
+		Jobs: []*Job{
+			{
+				Namespace: "ns1",
+				Name:      "pg1",
+				PodGroup:  util.BuildPodGroup("pg1", "ns1", "q1", 1, nil, schedulingv1beta1.PodGroupRunning),
+				Tasks: []*Task{
+					{Namespace: "ns1", Name: "p1", Pod: util.BuildPod("ns1", "p1", "n1", v1.PodRunning, api.BuildResourceList("1", "1Gi"), "pg1", map[string]string{}, map[string]string{})},
+				},
+			},
+		},
+	}
+
+	ci, err := s.ClusterInfo()
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	if len(ci.Nodes["n1"].Tasks) != 2 || ci.Nodes["n1"].Used.MilliCPU != 2000 {
+		t.Fatalf("expected the pod out of jobs and the task on node n1, got %v", ci.Nodes["n1"])
+	}
+
+	exported, err := Export(ci, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := Write(buf, exported); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	read, err := Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	if len(read.Nodes) != 1 || len(read.Nodes[0].Pods) != 1 || read.Nodes[0].Pods[0].Name != "ds1" {
+		t.Fatalf("expected the pod out of jobs on node n1, got %v", read.Nodes)
+	}
+
+	reloaded, err := read.ClusterInfo()
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	used := resourceList(reloaded.Nodes["n1"].Used)
+	if used.Cpu().Cmp(read.Nodes[0].Used[v1.ResourceCPU]) != 0 || used.Memory().Cmp(read.Nodes[0].Used[v1.ResourceMemory]) != 0 {
+		t.Errorf("expected the node to use %v once reloaded, got %v", read.Nodes[0].Used, used)
+	}
+}
+
+func TestReadVersion(t *testing.T) {
+	if _, err := Read(strings.NewReader(`{"version":"v0"}`)); err == nil {
+		t.Errorf("expected an error for an unsupported version")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"encoding/json"
+	"net/http"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/apiauth"
+)
+
+// Path is the HTTP path the statistics of the last cycle are served on.
+const Path = "/stats"
+
+// NewHandler returns the HTTP handler serving the statistics of the last cycle. They cover all
+// the queues, so the user of the request must be allowed to list the queues.
+func NewHandler(r *Recorder, authorizer *apiauth.Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		user, err := authorizer.Authenticate(req)
+		if err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		if err := authorizer.Authorize(req.Context(), user, authorizationv1.ResourceAttributes{
+			Group: "scheduling.volcano.sh", Verb: "list", Resource: "queues",
+		}); err != nil {
+			apiauth.WriteError(w, err)
+			return
+		}
+		stats := r.Last()
+		if stats == nil {
+			http.Error(w, "no scheduling cycle recorded yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			klog.Errorf("Failed to encode stats: %v", err)
+		}
+	})
+}
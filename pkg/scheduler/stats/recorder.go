@@ -0,0 +1,250 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stats aggregates the outcome of each scheduling cycle into the statistics backing a
+// scheduling dashboard: the pending gangs and the shares of the queues, the operations of the actions.
+package stats
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+// gangSizeBuckets are the upper bounds of the buckets the pending gangs are counted in by size.
+var gangSizeBuckets = []int32{1, 2, 4, 8, 16, 32, 64, 128}
+
+// Stats are the statistics of a scheduling cycle.
+type Stats struct {
+	Time    time.Time               `json:"time"`
+	Queues  map[string]*QueueStats  `json:"queues"`
+	Actions map[string]*ActionStats `json:"actions"`
+	// FairnessDeviation is how far the allocated resources are from being shared by the queues with
+	// demand in proportion of their weights: half the sum of the differences between the share of
+	// each queue in the allocated resources and its share in the weights, from 0 to 1
+	FairnessDeviation float64 `json:"fairnessDeviation"`
+}
+
+// QueueStats are the statistics of a queue.
+type QueueStats struct {
+	Weight       int32 `json:"weight"`
+	PendingJobs  int   `json:"pendingJobs"`
+	PendingTasks int   `json:"pendingTasks"`
+	// PendingGangSizes counts the jobs whose gang is not ready by their minAvailable, in buckets
+	// keyed by their upper bound as the le label of the Prometheus histograms
+	PendingGangSizes map[string]int `json:"pendingGangSizes,omitempty"`
+	// AllocatedShare is the dominant share of the queue in the resources of the cluster
+	AllocatedShare float64 `json:"allocatedShare"`
+	// WeightShare is the share of the queue in the weights of the queues with demand
+	WeightShare float64 `json:"weightShare"`
+}
+
+// ActionStats are the operations applied by an action.
+type ActionStats struct {
+	Allocated int `json:"allocated"`
+	Pipelined int `json:"pipelined"`
+	Evicted   int `json:"evicted"`
+}
+
+// queueRecord is what is recorded of a queue in the sessions of the cycle.
+type queueRecord struct {
+	stats     *QueueStats
+	allocated *api.Resource
+}
+
+// Recorder aggregates the sessions of the current cycle while serving the statistics of the last one.
+type Recorder struct {
+	mutex   sync.RWMutex
+	enabled bool
+
+	queues  map[string]*queueRecord
+	actions map[string]*ActionStats
+	total   *api.Resource
+
+	last *Stats
+}
+
+var defaultRecorder = &Recorder{}
+
+// Default returns the recorder of the scheduler process.
+func Default() *Recorder {
+	return defaultRecorder
+}
+
+// Enable starts the recording, the statistics are only recorded once enabled.
+func (r *Recorder) Enable() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.enabled = true
+}
+
+// StartCycle starts aggregating the sessions of a cycle.
+func (r *Recorder) StartCycle() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.enabled {
+		return
+	}
+	r.queues = map[string]*queueRecord{}
+	r.actions = map[string]*ActionStats{}
+	r.total = api.EmptyResource()
+}
+
+// Record adds the session to the cycle, once its actions were executed.
+func (r *Recorder) Record(ssn *framework.Session) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.enabled || r.queues == nil {
+		return
+	}
+
+	// the sessions of the profiles see the same nodes
+	r.total = ssn.TotalResource.Clone()
+	for _, job := range ssn.Jobs {
+		record := r.queue(string(job.Queue))
+		if queue, found := ssn.Queues[job.Queue]; found {
+			record.stats.Weight = queue.Weight
+		}
+		record.allocated.Add(job.Allocated)
+		pending := len(job.TaskStatusIndex[api.Pending])
+		if pending == 0 {
+			continue
+		}
+		record.stats.PendingTasks += pending
+		if !job.IsReady() {
+			record.stats.PendingJobs++
+			record.stats.PendingGangSizes[gangSizeBucket(job.MinAvailable)]++
+		}
+	}
+	for _, entry := range ssn.Journal() {
+		action, found := r.actions[entry.Action]
+		if !found {
+			action = &ActionStats{}
+			r.actions[entry.Action] = action
+		}
+		switch entry.Operation {
+		case framework.Allocate:
+			action.Allocated++
+		case framework.Pipeline:
+			action.Pipelined++
+		case framework.Evict:
+			action.Evicted++
+		}
+	}
+}
+
+// EndCycle makes the statistics of the cycle the ones served.
+func (r *Recorder) EndCycle() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.queues == nil {
+		return
+	}
+	r.last = aggregate(r.queues, r.actions, r.total)
+	r.queues, r.actions, r.total = nil, nil, nil
+}
+
+// Last returns the statistics of the last cycle, nil if none was recorded.
+func (r *Recorder) Last() *Stats {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.last
+}
+
+func (r *Recorder) queue(name string) *queueRecord {
+	record, found := r.queues[name]
+	if !found {
+		record = &queueRecord{
+			stats:     &QueueStats{PendingGangSizes: map[string]int{}},
+			allocated: api.EmptyResource(),
+		}
+		r.queues[name] = record
+	}
+	return record
+}
+
+// aggregate computes the shares of the queues and the fairness deviation of the cycle.
+func aggregate(queues map[string]*queueRecord, actions map[string]*ActionStats, total *api.Resource) *Stats {
+	stats := &Stats{
+		Time:    time.Now(),
+		Queues:  map[string]*QueueStats{},
+		Actions: actions,
+	}
+
+	for name, record := range queues {
+		record.stats.AllocatedShare = DominantShare(record.allocated, total)
+		stats.Queues[name] = record.stats
+	}
+	stats.FairnessDeviation = FairnessDeviation(stats.Queues)
+	return stats
+}
+
+// FairnessDeviation returns how far the allocated resources are from being shared by the queues
+// with demand in proportion of their weights, see Stats.FairnessDeviation, and sets the weight
+// shares of the queues with demand: those with an allocated share or pending tasks.
+func FairnessDeviation(queues map[string]*QueueStats) float64 {
+	var totalWeight int32
+	var totalShare float64
+	for _, queue := range queues {
+		if queue.AllocatedShare > 0 || queue.PendingTasks > 0 {
+			totalWeight += queue.Weight
+			totalShare += queue.AllocatedShare
+		}
+	}
+	if totalWeight == 0 || totalShare == 0 {
+		return 0
+	}
+
+	var deviation float64
+	for _, queue := range queues {
+		if queue.AllocatedShare == 0 && queue.PendingTasks == 0 {
+			continue
+		}
+		queue.WeightShare = float64(queue.Weight) / float64(totalWeight)
+		deviation += math.Abs(queue.AllocatedShare/totalShare - queue.WeightShare)
+	}
+	return deviation / 2
+}
+
+// DominantShare returns the largest share of the allocated resources in the total ones.
+func DominantShare(allocated, total *api.Resource) float64 {
+	var share float64
+	for _, name := range total.ResourceNames() {
+		if name == v1.ResourcePods {
+			continue
+		}
+		if t := total.Get(name); t > 0 {
+			share = math.Max(share, allocated.Get(name)/t)
+		}
+	}
+	return share
+}
+
+// gangSizeBucket returns the bucket of the gang size.
+func gangSizeBucket(size int32) string {
+	for _, bound := range gangSizeBuckets {
+		if size <= bound {
+			return strconv.Itoa(int(bound))
+		}
+	}
+	return "+Inf"
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+func TestAggregate(t *testing.T) {
+	total := api.NewResource(api.BuildResourceList("10", "10Gi"))
+	queues := map[string]*queueRecord{
+		"q1": {
+			stats:     &QueueStats{Weight: 1, PendingTasks: 2, PendingJobs: 1, PendingGangSizes: map[string]int{"2": 1}},
+			allocated: api.NewResource(api.BuildResourceList("6", "2Gi")),
+		},
+		"q2": {
+			stats:     &QueueStats{Weight: 1, PendingGangSizes: map[string]int{}},
+			allocated: api.NewResource(api.BuildResourceList("2", "4Gi")),
+		},
+		"idle": {
+			stats:     &QueueStats{Weight: 2, PendingGangSizes: map[string]int{}},
+			allocated: api.EmptyResource(),
+		},
+	}
+
+	stats := aggregate(queues, map[string]*ActionStats{}, total)
+	if share := stats.Queues["q1"].AllocatedShare; math.Abs(share-0.6) > 1e-9 {
+		t.Errorf("expected q1 dominant share 0.6, got %v", share)
+	}
+	if share := stats.Queues["q2"].AllocatedShare; math.Abs(share-0.4) > 1e-9 {
+		t.Errorf("expected q2 dominant share 0.4, got %v", share)
+	}
+	if share := stats.Queues["idle"].WeightShare; share != 0 {
+		t.Errorf("expected no weight share for the queue without demand, got %v", share)
+	}
+	// q1 holds 60% of the allocated resources, q2 40%, for the same weight
+	if math.Abs(stats.FairnessDeviation-0.1) > 1e-9 {
+		t.Errorf("expected fairness deviation 0.1, got %v", stats.FairnessDeviation)
+	}
+}
+
+func TestGangSizeBucket(t *testing.T) {
+	for size, expected := range map[int32]string{1: "1", 3: "4", 16: "16", 100: "128", 1000: "+Inf"} {
+		if bucket := gangSizeBucket(size); bucket != expected {
+			t.Errorf("size %d: expected bucket %s, got %s", size, expected, bucket)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package harness runs the scheduler against a declaratively described fake cluster, so that
+// actions and plugins can be tested end to end over several scheduling cycles without a real
+// cluster. The api server is replaced by the mock scheduler cache and the kubelet by a fake one
+// which starts the bound pods and removes the evicted ones between two cycles.
+package harness
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+const (
+	// channelBuffer is large enough for the binds and evictions of a cycle not to block
+	channelBuffer = 4096
+	// quietPeriod is how long the fake kubelet waits for a new bind or eviction before
+	// considering the cycle settled
+	quietPeriod = 100 * time.Millisecond
+)
+
+// FakeCluster is a fake cluster the scheduler runs against.
+type FakeCluster struct {
+	cache   *cache.SchedulerCache
+	binder  *util.FakeBinder
+	evictor *util.FakeEvictor
+	updater *statusUpdater
+	stop    chan struct{}
+
+	tiers   []conf.Tier
+	configs []conf.Configuration
+
+	// pods and podGroups are the objects as the api server would store them, by namespace/name
+	pods      map[string]*v1.Pod
+	podGroups map[string]*schedulingv1beta1.PodGroup
+	version   int
+}
+
+// NewFakeCluster creates the cluster declared by spec and registers the plugins, which are
+// enabled by the tiers.
+func NewFakeCluster(spec *ClusterSpec, plugins map[string]framework.PluginBuilder, tiers []conf.Tier, configs []conf.Configuration) *FakeCluster {
+	return NewFaultyFakeCluster(spec, plugins, tiers, configs, nil)
+}
+
+// NewFaultyFakeCluster creates the cluster like NewFakeCluster, with the faults injected in its
+// cache once the declared objects are added, so that the scheduler starts from the whole cluster.
+func NewFaultyFakeCluster(spec *ClusterSpec, plugins map[string]framework.PluginBuilder, tiers []conf.Tier, configs []conf.Configuration,
+	faults *cache.FaultInjector) *FakeCluster {
+	fc := &FakeCluster{
+		binder:    util.NewFakeBinder(channelBuffer),
+		evictor:   util.NewFakeEvictor(channelBuffer),
+		updater:   &statusUpdater{phases: map[string]schedulingv1beta1.PodGroupPhase{}},
+		stop:      make(chan struct{}),
+		tiers:     tiers,
+		configs:   configs,
+		pods:      map[string]*v1.Pod{},
+		podGroups: map[string]*schedulingv1beta1.PodGroup{},
+	}
+	fc.cache = cache.NewCustomMockSchedulerCache("harness-scheduler", fc.binder, fc.evictor, fc.updater, nil, nil, &record.FakeRecorder{})
+
+	for name, plugin := range plugins {
+		framework.RegisterPluginBuilder(name, plugin)
+	}
+	for i := range spec.Nodes {
+		fc.cache.AddOrUpdateNode(spec.Nodes[i].build())
+	}
+	for i := range spec.Queues {
+		fc.cache.AddQueueV1beta1(spec.Queues[i].build())
+	}
+	// the jobs are created a second apart in the order they are declared, which is the order
+	// the scheduler considers the jobs of equal priority in
+	created := time.Now().Add(-time.Duration(len(spec.Jobs)) * time.Second)
+	for i := range spec.Jobs {
+		job := &spec.Jobs[i]
+		pg := job.buildPodGroup()
+		pg.CreationTimestamp = metav1.NewTime(created.Add(time.Duration(i) * time.Second))
+		pg.ResourceVersion = fc.nextVersion()
+		fc.podGroups[key(pg.Namespace, pg.Name)] = pg
+		fc.cache.AddPodGroupV1beta1(pg)
+		for _, pod := range job.buildPods() {
+			pod.ResourceVersion = fc.nextVersion()
+			fc.pods[key(pod.Namespace, pod.Name)] = pod
+			// the cache gets its own copies of the objects, as from an informer, since the
+			// scheduler changes the pods it assumes; the pods of the failed binds are synced
+			// again from the client
+			fc.cache.AddPod(pod.DeepCopy())
+			fc.cache.Client().CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod.DeepCopy(), metav1.CreateOptions{})
+		}
+	}
+	if faults != nil {
+		fc.cache.InjectFaults(faults)
+	}
+	fc.cache.Run(fc.stop)
+	return fc
+}
+
+// RunCycle runs one scheduling cycle with the actions, then lets the fake kubelet apply its
+// binds and evictions. It returns how long the session took, from its opening to its closing.
+func (fc *FakeCluster) RunCycle(actions []framework.Action) time.Duration {
+	if len(actions) == 0 {
+		panic("no actions provided, please specify a list of actions to execute")
+	}
+
+	conf.EnabledActionMap = make(map[string]bool, len(actions))
+	for _, action := range actions {
+		conf.EnabledActionMap[action.Name()] = true
+	}
+
+	start := time.Now()
+	ssn := framework.OpenSession(fc.cache, fc.tiers, fc.configs)
+	for _, action := range actions {
+		action.Initialize()
+		ssn.RunAction(action)
+		action.UnInitialize()
+	}
+	framework.CloseSession(ssn)
+	latency := time.Since(start)
+
+	fc.settle()
+	return latency
+}
+
+// RunCycles runs n scheduling cycles with the actions.
+func (fc *FakeCluster) RunCycles(n int, actions []framework.Action) {
+	for i := 0; i < n; i++ {
+		fc.RunCycle(actions)
+	}
+}
+
+// settle plays the kubelet: bound pods start running on their node, evicted pods are removed,
+// and the PodGroup phases set by the scheduler are stored.
+func (fc *FakeCluster) settle() {
+	for {
+		select {
+		case k := <-fc.binder.Channel:
+			fc.startPod(k, fc.binder.Binds()[k])
+		case k := <-fc.evictor.Channel:
+			fc.removePod(k)
+		case <-time.After(quietPeriod):
+			fc.storePodGroupPhases()
+			return
+		}
+	}
+}
+
+func (fc *FakeCluster) startPod(k, nodeName string) {
+	oldPod, found := fc.pods[k]
+	if !found {
+		return
+	}
+	newPod := oldPod.DeepCopy()
+	newPod.Spec.NodeName = nodeName
+	newPod.Status.Phase = v1.PodRunning
+	newPod.ResourceVersion = fc.nextVersion()
+	fc.pods[k] = newPod
+	fc.cache.UpdatePod(oldPod.DeepCopy(), newPod.DeepCopy())
+}
+
+func (fc *FakeCluster) removePod(k string) {
+	pod, found := fc.pods[k]
+	if !found {
+		return
+	}
+	delete(fc.pods, k)
+	fc.cache.DeletePod(pod.DeepCopy())
+}
+
+func (fc *FakeCluster) storePodGroupPhases() {
+	for k, phase := range fc.updater.takePhases() {
+		oldPG, found := fc.podGroups[k]
+		if !found || oldPG.Status.Phase == phase {
+			continue
+		}
+		newPG := oldPG.DeepCopy()
+		newPG.Status.Phase = phase
+		newPG.ResourceVersion = fc.nextVersion()
+		fc.podGroups[k] = newPG
+		fc.cache.UpdatePodGroupV1beta1(oldPG, newPG)
+	}
+}
+
+func (fc *FakeCluster) nextVersion() string {
+	fc.version++
+	return strconv.Itoa(fc.version)
+}
+
+// Placements returns the node of each pod running in the cluster, by namespace/name.
+func (fc *FakeCluster) Placements() map[string]string {
+	placements := map[string]string{}
+	for k, pod := range fc.pods {
+		if pod.Spec.NodeName != "" {
+			placements[k] = pod.Spec.NodeName
+		}
+	}
+	return placements
+}
+
+// PodGroupPhase returns the phase of the PodGroup of the job.
+func (fc *FakeCluster) PodGroupPhase(namespace, name string) schedulingv1beta1.PodGroupPhase {
+	if pg, found := fc.podGroups[key(namespace, name)]; found {
+		return pg.Status.Phase
+	}
+	return ""
+}
+
+// Evicted returns the pods evicted so far, by namespace/name.
+func (fc *FakeCluster) Evicted() []string {
+	evicted := fc.evictor.Evicts()
+	sort.Strings(evicted)
+	return evicted
+}
+
+// CheckPlacements checks that exactly the expected pods run, each on its expected node.
+func (fc *FakeCluster) CheckPlacements(expected map[string]string) error {
+	got := fc.Placements()
+	if len(got) != len(expected) {
+		return fmt.Errorf("check placements: \nwant: %v\n got: %v", expected, got)
+	}
+	for k, nodeName := range expected {
+		if got[k] != nodeName {
+			return fmt.Errorf("check placements: \nwant: %v->%v\n got: %v->%v", k, nodeName, k, got[k])
+		}
+	}
+	return nil
+}
+
+// Close stops the cache and unregisters the plugins.
+func (fc *FakeCluster) Close() {
+	framework.CleanupPluginBuilders()
+	close(fc.stop)
+}
+
+func key(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// statusUpdater records the PodGroup phases set by the scheduler in place of the api server.
+type statusUpdater struct {
+	sync.Mutex
+	phases map[string]schedulingv1beta1.PodGroupPhase
+}
+
+func (su *statusUpdater) UpdatePodStatus(pod *v1.Pod) (*v1.Pod, error) {
+	return pod, nil
+}
+
+func (su *statusUpdater) UpdatePodGroup(pg *api.PodGroup) (*api.PodGroup, error) {
+	su.Lock()
+	defer su.Unlock()
+	su.phases[key(pg.Namespace, pg.Name)] = schedulingv1beta1.PodGroupPhase(pg.Status.Phase)
+	return pg, nil
+}
+
+func (su *statusUpdater) UpdateQueueStatus(queue *api.QueueInfo) error {
+	return nil
+}
+
+func (su *statusUpdater) takePhases() map[string]schedulingv1beta1.PodGroupPhase {
+	su.Lock()
+	defer su.Unlock()
+	phases := su.phases
+	su.phases = map[string]schedulingv1beta1.PodGroupPhase{}
+	return phases
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/cmd/scheduler/app/options"
+	"volcano.sh/volcano/pkg/scheduler/actions/allocate"
+	"volcano.sh/volcano/pkg/scheduler/actions/enqueue"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/plugins/predicates"
+)
+
+func TestMain(m *testing.M) {
+	options.Default()
+	os.Exit(m.Run())
+}
+
+const gangClusterSpec = `
+nodes:
+- name: n1
+  cpu: "2"
+  memory: 4Gi
+  labels:
+    zone: a
+- name: n2
+  cpu: "2"
+  memory: 4Gi
+  labels:
+    zone: b
+queues:
+- name: default
+jobs:
+- name: tf
+  minMember: 3
+  tasks:
+  - name: ps
+    replicas: 1
+    cpu: "1"
+    memory: 1Gi
+    nodeSelector:
+      zone: a
+  - name: worker
+    replicas: 2
+    cpu: "1"
+    memory: 1Gi
+    nodeSelector:
+      zone: b
+- name: mpi
+  minMember: 4
+  tasks:
+  - name: worker
+    replicas: 4
+    cpu: "1"
+    memory: 1Gi
+`
+
+func TestFakeClusterGang(t *testing.T) {
+	spec, err := LoadClusterSpec([]byte(gangClusterSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:                gang.PluginName,
+					EnabledJobOrder:     &trueValue,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+				{
+					Name:             predicates.PluginName,
+					EnabledPredicate: &trueValue,
+				},
+			},
+		},
+	}
+	plugins := map[string]framework.PluginBuilder{
+		gang.PluginName:       gang.New,
+		predicates.PluginName: predicates.New,
+	}
+
+	fc := NewFakeCluster(spec, plugins, tiers, nil)
+	defer fc.Close()
+
+	fc.RunCycles(2, []framework.Action{enqueue.New(), allocate.New()})
+
+	// the mpi gang does not fit in the cpu left by the tf one, none of its pods is placed
+	if err := fc.CheckPlacements(map[string]string{
+		"default/tf-ps-0":     "n1",
+		"default/tf-worker-0": "n2",
+		"default/tf-worker-1": "n2",
+	}); err != nil {
+		t.Error(err)
+	}
+	if phase := fc.PodGroupPhase("default", "tf"); phase != schedulingv1beta1.PodGroupRunning {
+		t.Errorf("expected PodGroup tf to be %s, got %s", schedulingv1beta1.PodGroupRunning, phase)
+	}
+	if evicted := fc.Evicted(); len(evicted) != 0 {
+		t.Errorf("expected no eviction, got %v", evicted)
+	}
+}
+
+const convergenceClusterSpec = `
+nodes:
+- name: n1
+  cpu: "4"
+  memory: 8Gi
+- name: n2
+  cpu: "4"
+  memory: 8Gi
+queues:
+- name: default
+jobs:
+- name: gang
+  minMember: 3
+  tasks:
+  - name: worker
+    replicas: 3
+    cpu: "1"
+    memory: 1Gi
+- name: single
+  minMember: 1
+  tasks:
+  - name: worker
+    replicas: 4
+    cpu: "1"
+    memory: 1Gi
+`
+
+func TestFakeClusterConvergesUnderFaults(t *testing.T) {
+	spec, err := LoadClusterSpec([]byte(convergenceClusterSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:                gang.PluginName,
+					EnabledJobOrder:     &trueValue,
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
+				},
+				{
+					Name:             predicates.PluginName,
+					EnabledPredicate: &trueValue,
+				},
+			},
+		},
+	}
+	plugins := map[string]framework.PluginBuilder{
+		gang.PluginName:       gang.New,
+		predicates.PluginName: predicates.New,
+	}
+
+	// half the binds fail and each bind request is delayed, as with a flaky api server
+	faults := cache.NewFaultInjector(1)
+	faults.BindFailureRate = 0.5
+	faults.BindDelay = 10 * time.Millisecond
+	fc := NewFaultyFakeCluster(spec, plugins, tiers, nil, faults)
+	defer fc.Close()
+
+	actions := []framework.Action{enqueue.New(), allocate.New()}
+	for i := 0; i < 20 && len(fc.Placements()) < 7; i++ {
+		fc.RunCycle(actions)
+	}
+
+	// every pod ends up running despite the failed binds, without overcommitting a node
+	placements := fc.Placements()
+	if len(placements) != 7 {
+		t.Fatalf("expected the 7 pods to converge to running, got %v", placements)
+	}
+	perNode := map[string]int{}
+	for _, nodeName := range placements {
+		perNode[nodeName]++
+	}
+	for nodeName, pods := range perNode {
+		if pods > 4 {
+			t.Errorf("expected at most 4 pods of 1 cpu on node %s, got %d", nodeName, pods)
+		}
+	}
+
+	// once converged, the next cycles keep the placements as they are
+	fc.RunCycles(3, actions)
+	if err := fc.CheckPlacements(placements); err != nil {
+		t.Errorf("expected the placements to stay stable: %v", err)
+	}
+	if evicted := fc.Evicted(); len(evicted) != 0 {
+		t.Errorf("expected no eviction, got %v", evicted)
+	}
+}
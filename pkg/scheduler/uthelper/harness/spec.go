@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+// ClusterSpec declares the nodes, queues and jobs of a fake cluster.
+type ClusterSpec struct {
+	Nodes  []NodeSpec  `json:"nodes"`
+	Queues []QueueSpec `json:"queues"`
+	Jobs   []JobSpec   `json:"jobs"`
+}
+
+// NodeSpec declares a node and its allocatable resources.
+type NodeSpec struct {
+	Name   string            `json:"name"`
+	CPU    string            `json:"cpu"`
+	Memory string            `json:"memory"`
+	Pods   string            `json:"pods,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// QueueSpec declares a queue.
+type QueueSpec struct {
+	Name       string          `json:"name"`
+	Weight     int32           `json:"weight,omitempty"`
+	Capability v1.ResourceList `json:"capability,omitempty"`
+}
+
+// JobSpec declares a job, i.e. a PodGroup and the pods of its tasks.
+type JobSpec struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Queue     string `json:"queue,omitempty"`
+	MinMember int32  `json:"minMember,omitempty"`
+	// Phase is the initial phase of the PodGroup, Pending if empty
+	Phase schedulingv1beta1.PodGroupPhase `json:"phase,omitempty"`
+	Tasks []TaskSpec                      `json:"tasks"`
+}
+
+// TaskSpec declares the replicas of a task of a job.
+type TaskSpec struct {
+	Name         string            `json:"name"`
+	Replicas     int               `json:"replicas"`
+	CPU          string            `json:"cpu"`
+	Memory       string            `json:"memory"`
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// LoadClusterSpec parses a cluster spec written in YAML or JSON.
+func LoadClusterSpec(data []byte) (*ClusterSpec, error) {
+	spec := &ClusterSpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster spec: %v", err)
+	}
+	return spec, nil
+}
+
+func (ns *NodeSpec) build() *v1.Node {
+	pods := ns.Pods
+	if pods == "" {
+		pods = "110"
+	}
+	return util.BuildNode(ns.Name, api.BuildResourceList(ns.CPU, ns.Memory, api.ScalarResource{Name: "pods", Value: pods}), ns.Labels)
+}
+
+func (qs *QueueSpec) build() *schedulingv1beta1.Queue {
+	weight := qs.Weight
+	if weight == 0 {
+		weight = 1
+	}
+	queue := util.BuildQueue(qs.Name, weight, qs.Capability)
+	queue.Status.State = schedulingv1beta1.QueueStateOpen
+	return queue
+}
+
+func (js *JobSpec) namespace() string {
+	if js.Namespace == "" {
+		return "default"
+	}
+	return js.Namespace
+}
+
+func (js *JobSpec) buildPodGroup() *schedulingv1beta1.PodGroup {
+	queue := js.Queue
+	if queue == "" {
+		queue = "default"
+	}
+	phase := js.Phase
+	if phase == "" {
+		phase = schedulingv1beta1.PodGroupPending
+	}
+	return util.BuildPodGroup(js.Name, js.namespace(), queue, js.MinMember, nil, phase)
+}
+
+// buildPods returns the pods of the job, named <job>-<task>-<index>.
+func (js *JobSpec) buildPods() []*v1.Pod {
+	var pods []*v1.Pod
+	for _, task := range js.Tasks {
+		for i := 0; i < task.Replicas; i++ {
+			name := fmt.Sprintf("%s-%s-%d", js.Name, task.Name, i)
+			pod := util.BuildPod(js.namespace(), name, "", v1.PodPending, api.BuildResourceList(task.CPU, task.Memory),
+				js.Name, nil, task.NodeSelector)
+			pod.Annotations[batch.TaskSpecKey] = task.Name
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
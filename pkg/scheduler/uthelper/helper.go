@@ -140,7 +140,7 @@ func (test *TestCommonStruct) Run(actions []framework.Action) {
 
 	for _, action := range actions {
 		action.Initialize()
-		action.Execute(test.ssn)
+		test.ssn.RunAction(action)
 		action.UnInitialize()
 	}
 }
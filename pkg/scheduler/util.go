@@ -46,15 +46,91 @@ tiers:
 `
 
 func UnmarshalSchedulerConf(confStr string) ([]framework.Action, []conf.Tier, []conf.Configuration, map[string]string, error) {
-	var actions []framework.Action
-
 	schedulerConf := &conf.SchedulerConfiguration{}
 
 	if err := yaml.Unmarshal([]byte(confStr), schedulerConf); err != nil {
 		return nil, nil, nil, nil, err
 	}
-	// Set default settings for each plugin if not set
-	for i, tier := range schedulerConf.Tiers {
+	if err := applyPluginConfDefaults(schedulerConf.Tiers); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	applyResourceWeights(schedulerConf.Tiers, schedulerConf.ResourceWeights)
+
+	actions, err := getActions(schedulerConf.Actions)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return actions, schedulerConf.Tiers, schedulerConf.Configurations, schedulerConf.MetricsConfiguration, nil
+}
+
+// schedulerProfile is the parsed configuration of one scheduler profile.
+type schedulerProfile struct {
+	schedulerName  string
+	actions        []framework.Action
+	plugins        []conf.Tier
+	configurations []conf.Configuration
+}
+
+// hasPlugin returns whether the plugin is in one of the tiers.
+func hasPlugin(tiers []conf.Tier, name string) bool {
+	for _, tier := range tiers {
+		for _, plugin := range tier.Plugins {
+			if plugin.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unmarshalSchedulerProfiles parses the additional scheduler profiles of the configuration.
+func unmarshalSchedulerProfiles(confStr string) ([]*schedulerProfile, error) {
+	schedulerConf := &conf.SchedulerConfiguration{}
+
+	if err := yaml.Unmarshal([]byte(confStr), schedulerConf); err != nil {
+		return nil, err
+	}
+
+	var profiles []*schedulerProfile
+	names := map[string]bool{}
+	for i := range schedulerConf.Profiles {
+		profile := &schedulerConf.Profiles[i]
+		if len(profile.SchedulerName) == 0 {
+			return nil, fmt.Errorf("schedulerName of profile %d is empty", i)
+		}
+		if names[profile.SchedulerName] {
+			return nil, fmt.Errorf("duplicated profile for scheduler %s", profile.SchedulerName)
+		}
+		names[profile.SchedulerName] = true
+
+		if err := applyPluginConfDefaults(profile.Tiers); err != nil {
+			return nil, fmt.Errorf("profile %s: %v", profile.SchedulerName, err)
+		}
+		weights := profile.ResourceWeights
+		if weights == nil {
+			weights = schedulerConf.ResourceWeights
+		}
+		applyResourceWeights(profile.Tiers, weights)
+		actions, err := getActions(profile.Actions)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %v", profile.SchedulerName, err)
+		}
+
+		profiles = append(profiles, &schedulerProfile{
+			schedulerName:  profile.SchedulerName,
+			actions:        actions,
+			plugins:        profile.Tiers,
+			configurations: profile.Configurations,
+		})
+	}
+
+	return profiles, nil
+}
+
+// applyPluginConfDefaults sets default settings for each plugin if not set
+func applyPluginConfDefaults(tiers []conf.Tier) error {
+	for i, tier := range tiers {
 		// drf with hierarchy enabled
 		hdrf := false
 		// proportion enabled
@@ -68,23 +144,49 @@ func UnmarshalSchedulerConf(confStr string) ([]framework.Action, []conf.Tier, []
 			if tier.Plugins[j].Name == "proportion" {
 				proportion = true
 			}
-			plugins.ApplyPluginConfDefaults(&schedulerConf.Tiers[i].Plugins[j])
+			plugins.ApplyPluginConfDefaults(&tiers[i].Plugins[j])
 		}
 		if hdrf && proportion {
-			return nil, nil, nil, nil, fmt.Errorf("proportion and drf with hierarchy enabled conflicts")
+			return fmt.Errorf("proportion and drf with hierarchy enabled conflicts")
 		}
 	}
+	return nil
+}
 
-	actionNames := strings.Split(schedulerConf.Actions, ",")
+// applyResourceWeights gives the resource weights of the configuration to the plugins declared
+// to compare different resources by them, unless the plugin sets its own.
+func applyResourceWeights(tiers []conf.Tier, weights map[string]float64) {
+	if len(weights) == 0 {
+		return
+	}
+	for i := range tiers {
+		for j := range tiers[i].Plugins {
+			plugin := &tiers[i].Plugins[j]
+			if !framework.IsResourceWeighted(plugin.Name) {
+				continue
+			}
+			if plugin.Arguments == nil {
+				plugin.Arguments = map[string]interface{}{}
+			}
+			if _, found := plugin.Arguments[framework.ResourceWeightsKey]; !found {
+				plugin.Arguments[framework.ResourceWeightsKey] = weights
+			}
+		}
+	}
+}
+
+func getActions(actionsConf string) ([]framework.Action, error) {
+	var actions []framework.Action
+
+	actionNames := strings.Split(actionsConf, ",")
 	for _, actionName := range actionNames {
 		if action, found := framework.GetAction(strings.TrimSpace(actionName)); found {
 			actions = append(actions, action)
 		} else {
-			return nil, nil, nil, nil, fmt.Errorf("failed to find Action %s, ignore it", actionName)
+			return nil, fmt.Errorf("failed to find Action %s, ignore it", actionName)
 		}
 	}
-
-	return actions, schedulerConf.Tiers, schedulerConf.Configurations, schedulerConf.MetricsConfiguration, nil
+	return actions, nil
 }
 
 func runSchedulerSocket() {
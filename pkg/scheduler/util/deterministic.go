@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	"volcano.sh/volcano/cmd/scheduler/app/options"
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// tieBreaker is the random source of the deterministic mode, seeded at the start of each session
+var tieBreaker = struct {
+	sync.Mutex
+	rand *rand.Rand
+}{}
+
+// Deterministic returns whether the scheduler runs in deterministic mode.
+func Deterministic() bool {
+	return options.ServerOpts != nil && options.ServerOpts.Deterministic
+}
+
+// ResetTieBreaker starts the tie-breaking of a session over from the configured seed, and the
+// predicates from the first node, in deterministic mode.
+func ResetTieBreaker() {
+	if !Deterministic() {
+		return
+	}
+	tieBreaker.Lock()
+	defer tieBreaker.Unlock()
+	tieBreaker.rand = rand.New(rand.NewSource(options.ServerOpts.DeterministicSeed))
+	lastProcessedNodeIndex = 0
+}
+
+// SortNodesByName sorts the nodes by name in deterministic mode, their order otherwise depends
+// on the order the cache received them.
+func SortNodesByName(nodes []*api.NodeInfo) {
+	if !Deterministic() {
+		return
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+// randIntn returns a random number in [0, n), from the seeded source in deterministic mode.
+func randIntn(n int) int {
+	if !Deterministic() {
+		return rand.Intn(n)
+	}
+	tieBreaker.Lock()
+	defer tieBreaker.Unlock()
+	if tieBreaker.rand == nil {
+		tieBreaker.rand = rand.New(rand.NewSource(options.ServerOpts.DeterministicSeed))
+	}
+	return tieBreaker.rand.Intn(n)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+	"time"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// queueEvictions remembers when the jobs of each queue caused evictions, it is shared by the
+// preempt and reclaim actions and outlives the sessions so that a queue cools down across them.
+var queueEvictions = &evictionHistory{events: map[api.QueueID][]time.Time{}}
+
+type evictionHistory struct {
+	sync.Mutex
+	events map[api.QueueID][]time.Time
+}
+
+// EvictionLimiter bounds the evictions an action executes in a session and the evictions the
+// jobs of a queue cause per minute, a zero limit is unlimited. Evictions are counted when they
+// are attempted, the evictions of a discarded statement are forgotten.
+type EvictionLimiter struct {
+	maxPerCycle          int
+	maxPerQueuePerMinute int
+
+	cycle int
+	now   func() time.Time
+}
+
+// NewEvictionLimiter returns a limiter for one action of one session.
+func NewEvictionLimiter(maxPerCycle, maxPerQueuePerMinute int) *EvictionLimiter {
+	return &EvictionLimiter{
+		maxPerCycle:          maxPerCycle,
+		maxPerQueuePerMinute: maxPerQueuePerMinute,
+		now:                  time.Now,
+	}
+}
+
+// Allow returns whether the jobs of the queue may cause one more eviction.
+func (l *EvictionLimiter) Allow(queue api.QueueID) bool {
+	return l.AllowN(queue, 1)
+}
+
+// AllowN returns whether the jobs of the queue may cause n more evictions.
+func (l *EvictionLimiter) AllowN(queue api.QueueID, n int) bool {
+	if l.maxPerCycle > 0 && l.cycle+n > l.maxPerCycle {
+		return false
+	}
+	if l.maxPerQueuePerMinute <= 0 {
+		return true
+	}
+
+	queueEvictions.Lock()
+	defer queueEvictions.Unlock()
+
+	since := l.now().Add(-time.Minute)
+	events := queueEvictions.events[queue]
+	i := 0
+	for i < len(events) && !events[i].After(since) {
+		i++
+	}
+	queueEvictions.events[queue] = events[i:]
+	return len(events)-i+n <= l.maxPerQueuePerMinute
+}
+
+// Record counts an eviction caused by the jobs of the queue.
+func (l *EvictionLimiter) Record(queue api.QueueID) {
+	l.cycle++
+	if l.maxPerQueuePerMinute <= 0 {
+		return
+	}
+
+	queueEvictions.Lock()
+	defer queueEvictions.Unlock()
+	queueEvictions.events[queue] = append(queueEvictions.events[queue], l.now())
+}
+
+// Forget uncounts the last n evictions caused by the jobs of the queue, whose statement was
+// discarded.
+func (l *EvictionLimiter) Forget(queue api.QueueID, n int) {
+	if n <= 0 {
+		return
+	}
+	l.cycle = max(l.cycle-n, 0)
+	if l.maxPerQueuePerMinute <= 0 {
+		return
+	}
+
+	queueEvictions.Lock()
+	defer queueEvictions.Unlock()
+	events := queueEvictions.events[queue]
+	queueEvictions.events[queue] = events[:max(len(events)-n, 0)]
+}
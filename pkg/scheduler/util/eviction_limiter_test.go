@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+func TestEvictionLimiter(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	perCycle := NewEvictionLimiter(2, 0)
+	if perCycle.AllowN("q1", 3) {
+		t.Errorf("expected 3 evictions at once to be beyond the per cycle limit")
+	}
+	for i := 0; i < 2; i++ {
+		if !perCycle.Allow("q1") {
+			t.Fatalf("expected eviction %d to be allowed", i)
+		}
+		perCycle.Record("q1")
+	}
+	if perCycle.Allow("q2") {
+		t.Errorf("expected the per cycle limit to apply to all queues")
+	}
+	// the evictions of a discarded statement do not count
+	perCycle.Forget("q1", 1)
+	if !perCycle.Allow("q2") {
+		t.Errorf("expected the forgotten eviction not to count against the per cycle limit")
+	}
+
+	first := NewEvictionLimiter(0, 2)
+	first.now = clock
+	first.Record("q-rate")
+	first.Record("q-rate")
+	if first.Allow("q-rate") {
+		t.Errorf("expected q-rate to be throttled")
+	}
+	if !first.Allow("q-other") {
+		t.Errorf("expected q-other not to be throttled")
+	}
+	if first.AllowN("q-other", 3) || !first.AllowN("q-other", 2) {
+		t.Errorf("expected q-other to be allowed 2 evictions at once and no more")
+	}
+
+	// the next session still sees the evictions of the last minute
+	second := NewEvictionLimiter(0, 2)
+	second.now = clock
+	if second.Allow("q-rate") {
+		t.Errorf("expected q-rate to stay throttled across sessions")
+	}
+	now = now.Add(time.Minute + time.Second)
+	if !second.Allow("q-rate") {
+		t.Errorf("expected q-rate to cool down after a minute")
+	}
+	second.Record("q-rate")
+	second.Record("q-rate")
+	second.Forget("q-rate", 2)
+	if !second.Allow("q-rate") {
+		t.Errorf("expected the forgotten evictions not to throttle q-rate")
+	}
+	if got := len(queueEvictions.events[api.QueueID("q-rate")]); got != 0 {
+		t.Errorf("expected expired evictions to be dropped, got %d", got)
+	}
+}
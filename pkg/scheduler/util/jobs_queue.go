@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// JobsQueue orders jobs in two layers: the queues by the queue order, then the jobs of each queue
+// by the job order. A queue is popped to be served and pushed back once its share was updated, so
+// that only the served queue is re-sorted. The queue is kept across the sessions and updated with
+// the changes of the jobs between them, see Update.
+type JobsQueue struct {
+	queues    *PriorityQueue
+	jobs      map[api.QueueID]*PriorityQueue
+	jobLessFn api.LessFn
+}
+
+// NewJobsQueue returns an empty JobsQueue.
+func NewJobsQueue(queueLessFn, jobLessFn api.LessFn) *JobsQueue {
+	return &JobsQueue{
+		queues:    NewPriorityQueue(queueLessFn),
+		jobs:      map[api.QueueID]*PriorityQueue{},
+		jobLessFn: jobLessFn,
+	}
+}
+
+// Push adds the job to the jobs of its queue, the queue is added on its first job.
+func (q *JobsQueue) Push(queue *api.QueueInfo, job *api.JobInfo) {
+	jobs, found := q.jobs[queue.UID]
+	if !found {
+		jobs = NewPriorityQueue(q.jobLessFn)
+		q.jobs[queue.UID] = jobs
+		q.queues.Push(queue)
+	}
+	jobs.Push(job)
+}
+
+// Update prepares the queue for a new session with its queues and pending jobs. The queues and jobs
+// kept are replaced by their version of the session and the ones gone are removed, the new jobs
+// and the ones served in the last session are added, then both layers are reordered by the order
+// functions of the session.
+func (q *JobsQueue) Update(queueLessFn, jobLessFn api.LessFn, queues map[api.QueueID]*api.QueueInfo, jobs []*api.JobInfo) {
+	pending := make(map[api.JobID]*api.JobInfo, len(jobs))
+	for _, job := range jobs {
+		pending[job.UID] = job
+	}
+
+	kept := make(map[api.JobID]bool, len(jobs))
+	q.jobLessFn = jobLessFn
+	for queueID, queueJobs := range q.jobs {
+		queueJobs.Update(jobLessFn, func(it interface{}) (interface{}, bool) {
+			job, found := pending[it.(*api.JobInfo).UID]
+			if !found || job.Queue != queueID {
+				return nil, false
+			}
+			kept[job.UID] = true
+			return job, true
+		})
+	}
+	for _, job := range jobs {
+		if kept[job.UID] {
+			continue
+		}
+		queueJobs, found := q.jobs[job.Queue]
+		if !found {
+			queueJobs = NewPriorityQueue(jobLessFn)
+			q.jobs[job.Queue] = queueJobs
+		}
+		queueJobs.Push(job)
+	}
+	for queueID, queueJobs := range q.jobs {
+		if _, found := queues[queueID]; !found || queueJobs.Empty() {
+			delete(q.jobs, queueID)
+		}
+	}
+
+	waiting := make(map[api.QueueID]bool, len(q.jobs))
+	q.queues.Update(queueLessFn, func(it interface{}) (interface{}, bool) {
+		queueID := it.(*api.QueueInfo).UID
+		if _, found := q.jobs[queueID]; !found {
+			return nil, false
+		}
+		waiting[queueID] = true
+		return queues[queueID], true
+	})
+	for _, job := range jobs {
+		if _, found := q.jobs[job.Queue]; found && !waiting[job.Queue] {
+			waiting[job.Queue] = true
+			q.queues.Push(queues[job.Queue])
+		}
+	}
+}
+
+// PopQueue pops the queue to serve first, nil if none is left.
+func (q *JobsQueue) PopQueue() *api.QueueInfo {
+	if q.queues.Empty() {
+		return nil
+	}
+	return q.queues.Pop().(*api.QueueInfo)
+}
+
+// PushQueue puts back a popped queue, ordered by its updated share.
+func (q *JobsQueue) PushQueue(queue *api.QueueInfo) {
+	q.queues.Push(queue)
+}
+
+// Jobs returns the jobs of the queue, nil if the queue has none.
+func (q *JobsQueue) Jobs(queueID api.QueueID) *PriorityQueue {
+	return q.jobs[queueID]
+}
+
+// Empty returns whether no queue is left to serve.
+func (q *JobsQueue) Empty() bool {
+	return q.queues.Empty()
+}
+
+// Len returns the number of queues left to serve.
+func (q *JobsQueue) Len() int {
+	return q.queues.Len()
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+func TestJobsQueue(t *testing.T) {
+	q1 := &api.QueueInfo{UID: "q1", Name: "q1", Weight: 1}
+	q2 := &api.QueueInfo{UID: "q2", Name: "q2", Weight: 2}
+	queueLessFn := func(l, r interface{}) bool {
+		return l.(*api.QueueInfo).Weight > r.(*api.QueueInfo).Weight
+	}
+	jobLessFn := func(l, r interface{}) bool {
+		return l.(*api.JobInfo).Priority > r.(*api.JobInfo).Priority
+	}
+
+	q := NewJobsQueue(queueLessFn, jobLessFn)
+	q.Push(q1, &api.JobInfo{Name: "j1", Queue: "q1", Priority: 1})
+	q.Push(q2, &api.JobInfo{Name: "j2", Queue: "q2", Priority: 1})
+	q.Push(q2, &api.JobInfo{Name: "j3", Queue: "q2", Priority: 5})
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 queues, got %d", q.Len())
+	}
+
+	queue := q.PopQueue()
+	if queue.UID != "q2" {
+		t.Fatalf("expected q2 served first, got %s", queue.UID)
+	}
+	if job := q.Jobs(queue.UID).Pop().(*api.JobInfo); job.Name != "j3" {
+		t.Errorf("expected j3 served first in q2, got %s", job.Name)
+	}
+
+	// the share of q2 was updated after its job was served
+	q2.Weight = 0
+	q.PushQueue(q2)
+	if queue = q.PopQueue(); queue.UID != "q1" {
+		t.Errorf("expected q1 served once q2 was updated, got %s", queue.UID)
+	}
+	if queue = q.PopQueue(); queue.UID != "q2" {
+		t.Errorf("expected q2 served last, got %s", queue.UID)
+	}
+	if !q.Empty() || q.PopQueue() != nil {
+		t.Errorf("expected no queue left")
+	}
+	if q.Jobs("q3") != nil {
+		t.Errorf("expected no jobs for an unknown queue")
+	}
+}
+
+func TestJobsQueueUpdate(t *testing.T) {
+	queueLessFn := func(l, r interface{}) bool {
+		return l.(*api.QueueInfo).Weight > r.(*api.QueueInfo).Weight
+	}
+	jobLessFn := func(l, r interface{}) bool {
+		return l.(*api.JobInfo).Priority > r.(*api.JobInfo).Priority
+	}
+	queues := map[api.QueueID]*api.QueueInfo{
+		"q1": {UID: "q1", Name: "q1", Weight: 1},
+		"q2": {UID: "q2", Name: "q2", Weight: 2},
+	}
+	q := NewJobsQueue(queueLessFn, jobLessFn)
+	q.Update(queueLessFn, jobLessFn, queues, []*api.JobInfo{
+		{UID: "j1", Name: "j1", Queue: "q1", Priority: 1},
+		{UID: "j2", Name: "j2", Queue: "q2", Priority: 1},
+		{UID: "j3", Name: "j3", Queue: "q2", Priority: 5},
+	})
+
+	// serve j3 of q2, then leave q2 out of the queues as if it was overused
+	if queue := q.PopQueue(); queue.UID != "q2" {
+		t.Fatalf("expected q2 served first, got %s", queue.UID)
+	}
+	if job := q.Jobs("q2").Pop().(*api.JobInfo); job.Name != "j3" {
+		t.Fatalf("expected j3 served first in q2, got %s", job.Name)
+	}
+
+	// next session: j1 is gone, j2 was raised, j3 is still pending, j4 is new and q1 outweighs q2
+	queues = map[api.QueueID]*api.QueueInfo{
+		"q1": {UID: "q1", Name: "q1", Weight: 3},
+		"q2": {UID: "q2", Name: "q2", Weight: 2},
+	}
+	q.Update(queueLessFn, jobLessFn, queues, []*api.JobInfo{
+		{UID: "j2", Name: "j2", Queue: "q2", Priority: 10},
+		{UID: "j3", Name: "j3", Queue: "q2", Priority: 5},
+		{UID: "j4", Name: "j4", Queue: "q1", Priority: 1},
+	})
+
+	var order []string
+	for !q.Empty() {
+		queue := q.PopQueue()
+		if queue != queues[queue.UID] {
+			t.Errorf("expected the queue %s of the new session", queue.UID)
+		}
+		for jobs := q.Jobs(queue.UID); !jobs.Empty(); {
+			order = append(order, jobs.Pop().(*api.JobInfo).Name)
+		}
+	}
+	if strings.Join(order, ",") != "j4,j2,j3" {
+		t.Errorf("expected the jobs served in order j4,j2,j3, got %v", order)
+	}
+}
@@ -97,8 +97,13 @@ func (ph *predicateHelper) PredicateNodes(task *api.TaskInfo, nodes []*api.NodeI
 		}
 	}
 
-	//workqueue.ParallelizeUntil(context.TODO(), 16, len(nodes), checkNode)
-	workqueue.ParallelizeUntil(ctx, 16, allNodes, checkNode)
+	// in deterministic mode the nodes are checked one after the other, so that the same nodes are
+	// found in the same order
+	workers := 16
+	if Deterministic() {
+		workers = 1
+	}
+	workqueue.ParallelizeUntil(ctx, workers, allNodes, checkNode)
 
 	//processedNodes := int(numFoundNodes) + len(filteredNodesStatuses) + len(failedPredicateMap)
 	lastProcessedNodeIndex = (lastProcessedNodeIndex + int(processedNodes)) % allNodes
@@ -56,6 +56,23 @@ func (q *PriorityQueue) Pop() interface{} {
 	return heap.Pop(&q.queue)
 }
 
+// Update replaces the less function of the queue and each of its items by its new version
+// returned by update, which returns false to remove it, then restores the order of the queue.
+func (q *PriorityQueue) Update(lessFn api.LessFn, update func(it interface{}) (interface{}, bool)) {
+	items := q.queue.items[:0]
+	for _, it := range q.queue.items {
+		if it, keep := update(it); keep {
+			items = append(items, it)
+		}
+	}
+	for i := len(items); i < len(q.queue.items); i++ {
+		q.queue.items[i] = nil
+	}
+	q.queue.items = items
+	q.queue.lessFn = lessFn
+	heap.Init(&q.queue)
+}
+
 // Empty check if queue is empty
 func (q *PriorityQueue) Empty() bool {
 	return q.queue.Len() == 0
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"math/rand"
 	"sort"
 	"sync"
 
@@ -156,7 +155,7 @@ func SelectBestNode(nodeScores map[float64][]*api.NodeInfo) *api.NodeInfo {
 		return nil
 	}
 
-	return bestNodes[rand.Intn(len(bestNodes))]
+	return bestNodes[randIntn(len(bestNodes))]
 }
 
 // GetNodeList returns values of the map 'nodes'
@@ -155,3 +155,47 @@ func TestNumFeasibleNodesToFind(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectBestNodeDeterministic(t *testing.T) {
+	defer func(opts *options.ServerOption) { options.ServerOpts = opts }(options.ServerOpts)
+	options.ServerOpts = &options.ServerOption{Deterministic: true, DeterministicSeed: 7}
+
+	var nodes []*api.NodeInfo
+	for _, name := range []string{"node1", "node2", "node3", "node4", "node5", "node6", "node7", "node8"} {
+		nodes = append(nodes, &api.NodeInfo{Name: name})
+	}
+	selectNodes := func() []string {
+		ResetTieBreaker()
+		var selected []string
+		for i := 0; i < 10; i++ {
+			selected = append(selected, SelectBestNode(map[float64][]*api.NodeInfo{1.0: nodes}).Name)
+		}
+		return selected
+	}
+
+	first, second := selectNodes(), selectNodes()
+	if !equality.Semantic.DeepEqual(first, second) {
+		t.Errorf("expected the same nodes selected in each session, got %v and %v", first, second)
+	}
+}
+
+func TestPluginScoresBreakdown(t *testing.T) {
+	scores := NewPluginScores()
+	scores.Record("node1", map[string]float64{"nodeorder": 30, "binpack": 50})
+	scores.Record("node2", map[string]float64{"nodeorder": 40, "binpack": 20})
+	scores.Record("node3", map[string]float64{"nodeorder": 10, "binpack": 10})
+	nodeScores := map[float64][]*api.NodeInfo{
+		80: {{Name: "node1"}},
+		60: {{Name: "node2"}},
+		20: {{Name: "node3"}},
+	}
+
+	expected := "node node1 scored 80.00 (binpack=50.00, nodeorder=30.00), runner-up node2 scored 60.00 (binpack=20.00, nodeorder=40.00)"
+	if breakdown := scores.Breakdown(nodeScores, "node1"); breakdown != expected {
+		t.Errorf("expected %q, got %q", expected, breakdown)
+	}
+	expected = "node node2 scored 60.00 (binpack=20.00, nodeorder=40.00), runner-up node1 scored 80.00 (binpack=50.00, nodeorder=30.00)"
+	if breakdown := scores.Breakdown(nodeScores, "node2"); breakdown != expected {
+		t.Errorf("expected %q, got %q", expected, breakdown)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"volcano.sh/volcano/cmd/scheduler/app/options"
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// ScoreBreakdownVerbosity returns the level of the score breakdowns of the bound tasks: 0 when
+// they are disabled, 1 when they are logged, 2 when they are recorded as events too.
+func ScoreBreakdownVerbosity() int {
+	if options.ServerOpts == nil {
+		return 0
+	}
+	return options.ServerOpts.ScoreBreakdownVerbosity
+}
+
+// PluginScores collects the score given by each plugin to the nodes scored for a task, the node
+// order map functions being called concurrently.
+type PluginScores struct {
+	sync.Mutex
+	scores map[string]map[string]float64
+}
+
+// NewPluginScores returns an empty collection of plugin scores.
+func NewPluginScores() *PluginScores {
+	return &PluginScores{scores: map[string]map[string]float64{}}
+}
+
+// Record adds the scores given by the plugins to the node.
+func (ps *PluginScores) Record(node string, scores map[string]float64) {
+	ps.Lock()
+	defer ps.Unlock()
+	if ps.scores[node] == nil {
+		ps.scores[node] = map[string]float64{}
+	}
+	for plugin, score := range scores {
+		ps.scores[node][plugin] += score
+	}
+}
+
+// Breakdown formats the total score and the plugin scores of the chosen node and of the
+// runner-up, the best scored of the other nodes.
+func (ps *PluginScores) Breakdown(nodeScores map[float64][]*api.NodeInfo, chosen string) string {
+	ps.Lock()
+	defer ps.Unlock()
+
+	chosenScore, runnerUp, runnerUpScore := 0.0, "", 0.0
+	for score, nodes := range nodeScores {
+		for _, node := range nodes {
+			switch {
+			case node.Name == chosen:
+				chosenScore = score
+			case len(runnerUp) == 0 || score > runnerUpScore || (score == runnerUpScore && node.Name < runnerUp):
+				runnerUp, runnerUpScore = node.Name, score
+			}
+		}
+	}
+
+	breakdown := fmt.Sprintf("node %s scored %s", chosen, ps.format(chosen, chosenScore))
+	if len(runnerUp) != 0 {
+		breakdown += fmt.Sprintf(", runner-up %s scored %s", runnerUp, ps.format(runnerUp, runnerUpScore))
+	}
+	return breakdown
+}
+
+// format formats the total score of the node followed by its plugin scores in name order.
+func (ps *PluginScores) format(node string, total float64) string {
+	scores := ps.scores[node]
+	plugins := make([]string, 0, len(scores))
+	for plugin := range scores {
+		plugins = append(plugins, plugin)
+	}
+	sort.Strings(plugins)
+	for i, plugin := range plugins {
+		plugins[i] = fmt.Sprintf("%s=%.2f", plugin, scores[plugin])
+	}
+	return fmt.Sprintf("%.2f (%s)", total, strings.Join(plugins, ", "))
+}
@@ -23,6 +23,7 @@ import (
 
 	_ "volcano.sh/volcano/pkg/scheduler/actions"
 	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
 )
 
 func TestLoadSchedulerConf(t *testing.T) {
@@ -214,3 +215,113 @@ tiers:
 			expectedConfigurations, configurations)
 	}
 }
+
+func TestLoadSchedulerProfiles(t *testing.T) {
+	testCases := []struct {
+		name          string
+		configuration string
+		expectedNames []string
+		expectedErr   bool
+	}{
+		{
+			name: "no profiles",
+			configuration: `
+actions: "enqueue, allocate, backfill"
+tiers:
+- plugins:
+  - name: priority
+`,
+		},
+		{
+			name: "two profiles",
+			configuration: `
+actions: "enqueue, allocate, backfill"
+tiers:
+- plugins:
+  - name: priority
+profiles:
+- schedulerName: interactive
+  actions: "allocate"
+  tiers:
+  - plugins:
+    - name: predicates
+- schedulerName: batch
+  actions: "enqueue, allocate, preempt"
+  tiers:
+  - plugins:
+    - name: gang
+    - name: proportion
+`,
+			expectedNames: []string{"interactive", "batch"},
+		},
+		{
+			name: "profile without scheduler name",
+			configuration: `
+profiles:
+- actions: "allocate"
+`,
+			expectedErr: true,
+		},
+		{
+			name: "duplicated profiles",
+			configuration: `
+profiles:
+- schedulerName: batch
+  actions: "allocate"
+- schedulerName: batch
+  actions: "allocate"
+`,
+			expectedErr: true,
+		},
+		{
+			name: "unknown action in profile",
+			configuration: `
+profiles:
+- schedulerName: batch
+  actions: "unknown"
+`,
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			profiles, err := unmarshalSchedulerProfiles(testCase.configuration)
+			if testCase.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", testCase.expectedErr, err)
+			}
+			var names []string
+			for _, profile := range profiles {
+				names = append(names, profile.schedulerName)
+				for _, tier := range profile.plugins {
+					for _, plugin := range tier.Plugins {
+						if plugin.EnabledJobOrder == nil {
+							t.Errorf("default settings are not applied to plugin %s of profile %s", plugin.Name, profile.schedulerName)
+						}
+					}
+				}
+			}
+			if !equality.Semantic.DeepEqual(names, testCase.expectedNames) {
+				t.Errorf("expected profiles %v, got %v", testCase.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestApplyResourceWeights(t *testing.T) {
+	own := map[string]float64{"nvidia.com/gpu": 8}
+	tiers := []conf.Tier{{Plugins: []conf.PluginOption{
+		{Name: "drf"},
+		{Name: "binpack", Arguments: framework.Arguments{framework.ResourceWeightsKey: own}},
+		{Name: "gang"},
+	}}}
+	weights := map[string]float64{"nvidia.com/gpu": 4, "memory": 0.5}
+	applyResourceWeights(tiers, weights)
+
+	expected := []interface{}{weights, own, nil}
+	for i, plugin := range tiers[0].Plugins {
+		if got := plugin.Arguments[framework.ResourceWeightsKey]; !equality.Semantic.DeepEqual(got, expected[i]) {
+			t.Errorf("expected the resource weights %v for plugin %s, got %v", expected[i], plugin.Name, got)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package template renders the parameterized job specs of job templates.
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+)
+
+// ParametersAnnotation declares the parameters of a job template as comma separated key=value
+// pairs. On a JobTemplate it gives the parameters and their default values, only the templates
+// carrying it are rendered; on a JobFlow the values used for the jobs the flow creates.
+const ParametersAnnotation = "volcano.sh/template-parameters"
+
+// placeholder matches the ${name} references to the parameters in a job spec, and the $${name}
+// escaped ones.
+var placeholder = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// ParseParameters parses comma separated key=value pairs.
+func ParseParameters(value string) (map[string]string, error) {
+	params := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		if err := SetParameter(params, pair); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// SetParameter adds a key=value pair to the parameters.
+func SetParameter(params map[string]string, pair string) error {
+	key, value, found := strings.Cut(pair, "=")
+	key = strings.TrimSpace(key)
+	if !found || len(key) == 0 {
+		return fmt.Errorf("invalid parameter <%s>, expect key=value", pair)
+	}
+	params[key] = strings.TrimSpace(value)
+	return nil
+}
+
+// Render replaces the ${name} references in the string fields of the job spec to the parameters
+// declared by the defaults with their values, falling back to the defaults. The references to
+// other names, e.g. the variables of shell commands, are left untouched, and $${name} renders
+// as ${name}.
+func Render(spec *batch.JobSpec, defaults, params map[string]string) (*batch.JobSpec, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := placeholder.ReplaceAllFunc(data, func(ref []byte) []byte {
+		if bytes.HasPrefix(ref, []byte("$$")) {
+			return ref[1:]
+		}
+		name := string(placeholder.FindSubmatch(ref)[1])
+		value, declared := defaults[name]
+		if !declared {
+			return ref
+		}
+		if param, found := params[name]; found {
+			value = param
+		}
+		// escape the value as it is substituted inside a JSON string
+		escaped, _ := json.Marshal(value)
+		return escaped[1 : len(escaped)-1]
+	})
+
+	result := &batch.JobSpec{}
+	if err := json.Unmarshal(rendered, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
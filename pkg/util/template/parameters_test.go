@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+)
+
+func TestParseParameters(t *testing.T) {
+	params, err := ParseParameters("image=busybox:1.36, queue = q1,,empty=")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"image": "busybox:1.36", "queue": "q1", "empty": ""}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("expected %v, got %v", expected, params)
+	}
+
+	if _, err := ParseParameters("image"); err == nil {
+		t.Errorf("expected an error for a parameter without value")
+	}
+}
+
+func TestRender(t *testing.T) {
+	spec := &batch.JobSpec{
+		Queue: "${queue}",
+		Tasks: []batch.TaskSpec{{
+			Name:     "worker",
+			Replicas: 2,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:    "main",
+						Image:   "${image}",
+						Command: []string{"sh", "-c", "echo ${message} ${HOME} $${message}"},
+					}},
+				},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		defaults map[string]string
+		params   map[string]string
+		queue    string
+		image    string
+		command  string
+	}{
+		{
+			name:     "parameters override the defaults",
+			defaults: map[string]string{"queue": "default", "image": "busybox", "message": "hello"},
+			params:   map[string]string{"image": "busybox:1.36", "message": `say "hi"`},
+			queue:    "default",
+			image:    "busybox:1.36",
+			command:  `echo say "hi" ${HOME} ${message}`,
+		},
+		{
+			name:     "undeclared parameters are left untouched",
+			defaults: map[string]string{"queue": "default"},
+			params:   map[string]string{"image": "busybox"},
+			queue:    "default",
+			image:    "${image}",
+			command:  "echo ${message} ${HOME} ${message}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, err := Render(spec, test.defaults, test.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			container := rendered.Tasks[0].Template.Spec.Containers[0]
+			if rendered.Queue != test.queue || container.Image != test.image || container.Command[2] != test.command {
+				t.Errorf("unexpected rendered spec: queue %s, image %s, command %v", rendered.Queue, container.Image, container.Command)
+			}
+			if rendered.Tasks[0].Replicas != 2 {
+				t.Errorf("expected the replicas to be kept, got %d", rendered.Tasks[0].Replicas)
+			}
+			if spec.Queue != "${queue}" {
+				t.Errorf("expected the template to be unchanged")
+			}
+		})
+	}
+}
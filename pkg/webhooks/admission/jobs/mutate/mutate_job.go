@@ -30,6 +30,7 @@ import (
 	"volcano.sh/volcano/pkg/controllers/job/plugins/distributed-framework/mpi"
 	"volcano.sh/volcano/pkg/controllers/job/plugins/distributed-framework/pytorch"
 	"volcano.sh/volcano/pkg/controllers/job/plugins/distributed-framework/tensorflow"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 	commonutil "volcano.sh/volcano/pkg/util"
 	"volcano.sh/volcano/pkg/webhooks/router"
 	"volcano.sh/volcano/pkg/webhooks/schema"
@@ -211,6 +212,16 @@ func mutateSpec(tasks []v1alpha1.TaskSpec, basePath string, job *v1alpha1.Job) *
 			patched = true
 			tasks[index].MaxRetry = defaultMaxRetry
 		}
+
+		// the pods of a job requiring exclusive nodes tolerate the taint its nodes are fenced with
+		if job.Annotations[schedulingapi.ExclusiveNode] == "true" && !toleratesExclusiveNode(tasks[index].Template.Spec.Tolerations) {
+			patched = true
+			tasks[index].Template.Spec.Tolerations = append(tasks[index].Template.Spec.Tolerations, v1.Toleration{
+				Key:      schedulingapi.ExclusiveNode,
+				Operator: v1.TolerationOpExists,
+				Effect:   v1.TaintEffectNoSchedule,
+			})
+		}
 	}
 	if !patched {
 		return nil
@@ -222,6 +233,15 @@ func mutateSpec(tasks []v1alpha1.TaskSpec, basePath string, job *v1alpha1.Job) *
 	}
 }
 
+func toleratesExclusiveNode(tolerations []v1.Toleration) bool {
+	for _, toleration := range tolerations {
+		if toleration.Key == schedulingapi.ExclusiveNode {
+			return true
+		}
+	}
+	return false
+}
+
 func patchDefaultPlugins(job *v1alpha1.Job) *patchOperation {
 	if job.Spec.Plugins == nil {
 		return nil
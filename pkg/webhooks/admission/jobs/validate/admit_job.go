@@ -23,8 +23,10 @@ import (
 
 	admissionv1 "k8s.io/api/admission/v1"
 	whv1 "k8s.io/api/admissionregistration/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -96,12 +98,32 @@ func AdmitJobs(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 
 	switch ar.Request.Operation {
 	case admissionv1.Create:
-		msg = validateJobCreate(job, &reviewResponse)
+		msg = validateJobCreate(job, ar.Request.UserInfo, &reviewResponse)
 	case admissionv1.Update:
 		oldJob, err := schema.DecodeJob(ar.Request.OldObject, ar.Request.Resource)
 		if err != nil {
 			return util.ToAdmissionResponse(err)
 		}
+		if fallbackQueue := jobhelpers.GangFallbackQueue(job.Annotations); len(fallbackQueue) != 0 &&
+			fallbackQueue != jobhelpers.GangFallbackQueue(oldJob.Annotations) {
+			if err := validateGangFallbackQueue(fallbackQueue, job.Namespace, ar.Request.UserInfo); err != nil {
+				return util.ToAdmissionResponse(err)
+			}
+		}
+		if oldJob.Spec.Queue != job.Spec.Queue {
+			userInfo := &ar.Request.UserInfo
+			if gangFallbackMigration(oldJob, job) {
+				userInfo = nil
+			}
+			if err := validateJobMigration(job, userInfo); err != nil {
+				return util.ToAdmissionResponse(err)
+			}
+		}
+		if util.PriorityBoostChanged(oldJob.Annotations, job.Annotations) {
+			if err := util.CheckPriorityBoost(config.KubeClient, job.Namespace, ar.Request.UserInfo); err != nil {
+				return util.ToAdmissionResponse(err)
+			}
+		}
 		err = validateJobUpdate(oldJob, job)
 		if err != nil {
 			return util.ToAdmissionResponse(err)
@@ -117,7 +139,7 @@ func AdmitJobs(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 	return &reviewResponse
 }
 
-func validateJobCreate(job *v1alpha1.Job, reviewResponse *admissionv1.AdmissionResponse) string {
+func validateJobCreate(job *v1alpha1.Job, userInfo authenticationv1.UserInfo, reviewResponse *admissionv1.AdmissionResponse) string {
 	var msg string
 	taskNames := map[string]string{}
 	var totalReplicas int32
@@ -229,6 +251,20 @@ func validateJobCreate(job *v1alpha1.Job, reviewResponse *admissionv1.AdmissionR
 	} else if queue.Status.State != schedulingv1beta1.QueueStateOpen {
 		msg += fmt.Sprintf(" can only submit job to queue with state `Open`, "+
 			"queue `%s` status is `%s`;", queue.Name, queue.Status.State)
+	} else if err := util.CheckQueueAccess(queue, job.Namespace, userInfo); err != nil {
+		msg += fmt.Sprintf(" %v;", err)
+	}
+
+	if fallbackQueue := jobhelpers.GangFallbackQueue(job.Annotations); len(fallbackQueue) != 0 {
+		if err := validateGangFallbackQueue(fallbackQueue, job.Namespace, userInfo); err != nil {
+			msg += fmt.Sprintf(" %v;", err)
+		}
+	}
+
+	if util.PriorityBoostChanged(nil, job.Annotations) {
+		if err := util.CheckPriorityBoost(config.KubeClient, job.Namespace, userInfo); err != nil {
+			msg += fmt.Sprintf(" %v;", err)
+		}
 	}
 
 	if hasDependenciesBetweenTasks {
@@ -276,6 +312,8 @@ func validateJobUpdate(old, new *v1alpha1.Job) error {
 	// other fields under spec are not allowed to mutate
 	new.Spec.MinAvailable = old.Spec.MinAvailable
 	new.Spec.PriorityClassName = old.Spec.PriorityClassName
+	// the job is migrated to its new queue, checked by validateJobMigration
+	new.Spec.Queue = old.Spec.Queue
 
 	// K8S also permit mutating spec.schedulingGates
 	// We do not support this for vcjob  (More details in design doc pod-scheduling-readiness.md)
@@ -298,9 +336,84 @@ func validateJobUpdate(old, new *v1alpha1.Job) error {
 	}
 
 	if !apiequality.Semantic.DeepEqual(new.Spec, old.Spec) {
-		return fmt.Errorf("job updates may not change fields other than `minAvailable`, `tasks[*].replicas under spec`")
+		return fmt.Errorf("job updates may not change fields other than `minAvailable`, `queue`, `tasks[*].replicas under spec`")
+	}
+
+	return nil
+}
+
+// validateGangFallbackQueue checks that the user submitting the job may submit it to the queue of
+// its gang scheduling fallback: the job controller migrates the job there later, under its own
+// identity.
+func validateGangFallbackQueue(name, namespace string, userInfo authenticationv1.UserInfo) error {
+	queue, err := config.VolcanoClient.SchedulingV1beta1().Queues().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to find gang scheduling fallback queue: %v", err)
 	}
+	return util.CheckQueueAccess(queue, namespace, userInfo)
+}
 
+// gangFallbackMigration returns whether the update is the job controller migrating the job to the
+// queue of its gang scheduling fallback, which the submitter was checked to have access to.
+func gangFallbackMigration(old, new *v1alpha1.Job) bool {
+	fallbackQueue := jobhelpers.GangFallbackQueue(new.Annotations)
+	return len(fallbackQueue) != 0 && new.Spec.Queue == fallbackQueue &&
+		fallbackQueue == jobhelpers.GangFallbackQueue(old.Annotations) &&
+		len(old.Annotations[jobhelpers.GangSchedulingTimedOutKey]) == 0 &&
+		len(new.Annotations[jobhelpers.GangSchedulingTimedOutKey]) != 0
+}
+
+// validateJobMigration checks that the job may move to its new queue: the job must not be
+// finished, the queue must be open to the namespace of the job and to the user unless userInfo is
+// nil, and the minimal resources of the job must fit in the capability of the queue on top of what
+// it has allocated already.
+func validateJobMigration(job *v1alpha1.Job, userInfo *authenticationv1.UserInfo) error {
+	switch job.Status.State.Phase {
+	case v1alpha1.Completed, v1alpha1.Failed, v1alpha1.Terminated:
+		return fmt.Errorf("job <%s/%s> is %s, it can't be migrated", job.Namespace, job.Name, job.Status.State.Phase)
+	}
+
+	queue, err := config.VolcanoClient.SchedulingV1beta1().Queues().Get(context.TODO(), job.Spec.Queue, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to find job queue: %v", err)
+	}
+	if queue.Status.State != schedulingv1beta1.QueueStateOpen {
+		return fmt.Errorf("can only migrate job to queue with state `Open`, queue `%s` status is `%s`", queue.Name, queue.Status.State)
+	}
+	if userInfo != nil {
+		if err := util.CheckQueueAccess(queue, job.Namespace, *userInfo); err != nil {
+			return err
+		}
+	}
+
+	// the podgroup is not created yet if the job was not initiated, it is checked on admission
+	podGroups := config.VolcanoClient.SchedulingV1beta1().PodGroups(job.Namespace)
+	pg, err := podGroups.Get(context.TODO(), job.Name+"-"+string(job.UID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		pg, err = podGroups.Get(context.TODO(), job.Name, metav1.GetOptions{})
+	}
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to find the podgroup of job <%s/%s>: %v", job.Namespace, job.Name, err)
+	}
+	if pg.Spec.MinResources == nil {
+		return nil
+	}
+	for name, capability := range queue.Spec.Capability {
+		request, found := (*pg.Spec.MinResources)[name]
+		if !found {
+			continue
+		}
+		allocated := queue.Status.Allocated[name]
+		need := allocated.DeepCopy()
+		need.Add(request)
+		if need.Cmp(capability) > 0 {
+			return fmt.Errorf("queue `%s` has no room for job <%s/%s>: it needs %s of %s, the queue has %s allocated of a capability of %s",
+				queue.Name, job.Namespace, job.Name, request.String(), name, allocated.String(), capability.String())
+		}
+	}
 	return nil
 }
 
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -31,6 +32,8 @@ import (
 	busv1alpha1 "volcano.sh/apis/pkg/apis/bus/v1alpha1"
 	schedulingv1beta2 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	fakeclient "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+	jobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
+	"volcano.sh/volcano/pkg/webhooks/util"
 )
 
 func TestValidateJobCreate(t *testing.T) {
@@ -1241,7 +1244,7 @@ func TestValidateJobCreate(t *testing.T) {
 				t.Error("Queue Creation Failed")
 			}
 
-			ret := validateJobCreate(&testCase.Job, &testCase.reviewResponse)
+			ret := validateJobCreate(&testCase.Job, authenticationv1.UserInfo{}, &testCase.reviewResponse)
 			//fmt.Printf("test-case name:%s, ret:%v  testCase.reviewResponse:%v \n", testCase.Name, ret,testCase.reviewResponse)
 			if testCase.ExpectErr == true && ret == "" {
 				t.Errorf("Expect error msg :%s, but got nil.", testCase.ret)
@@ -1371,7 +1374,7 @@ func TestValidateJobUpdate(t *testing.T) {
 				new.Spec.Tasks[0].Name = "mutated-name"
 			}
 			if tc.mutateSpec {
-				new.Spec.Queue = "mutated-queue"
+				new.Spec.SchedulerName = "mutated-scheduler"
 			}
 
 			err := validateJobUpdate(old, new)
@@ -1386,6 +1389,113 @@ func TestValidateJobUpdate(t *testing.T) {
 
 }
 
+func TestValidateJobMigration(t *testing.T) {
+	openQueue := func(name string, capability, allocated v1.ResourceList) *schedulingv1beta2.Queue {
+		return &schedulingv1beta2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       schedulingv1beta2.QueueSpec{Weight: 1, Capability: capability},
+			Status:     schedulingv1beta2.QueueStatus{State: schedulingv1beta2.QueueStateOpen, Allocated: allocated},
+		}
+	}
+	closed := openQueue("closed", nil, nil)
+	closed.Status.State = schedulingv1beta2.QueueStateClosed
+
+	testCases := []struct {
+		name      string
+		queue     string
+		phase     v1alpha1.JobPhase
+		expectErr bool
+	}{
+		{name: "migrate to a queue with room", queue: "roomy"},
+		{name: "migrate to a full queue", queue: "full", expectErr: true},
+		{name: "migrate to a closed queue", queue: "closed", expectErr: true},
+		{name: "migrate to a missing queue", queue: "missing", expectErr: true},
+		{name: "migrate a completed job", queue: "roomy", phase: v1alpha1.Completed, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			minResources := v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+			config.VolcanoClient = fakeclient.NewSimpleClientset(
+				openQueue("roomy", v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")}, v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")}),
+				openQueue("full", v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")}, v1.ResourceList{v1.ResourceCPU: resource.MustParse("7")}),
+				closed,
+				&schedulingv1beta2.PodGroup{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "valid-job-uid"},
+					Spec:       schedulingv1beta2.PodGroupSpec{MinMember: 5, MinResources: &minResources},
+				},
+			)
+
+			job := newJob()
+			job.UID = "uid"
+			job.Spec.Queue = tc.queue
+			job.Status.State.Phase = tc.phase
+
+			err := validateJobMigration(job, &authenticationv1.UserInfo{})
+			if err != nil && !tc.expectErr {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+			if err == nil && tc.expectErr {
+				t.Errorf("Expected error, but got none")
+			}
+		})
+	}
+}
+
+func TestValidateGangFallbackQueue(t *testing.T) {
+	config.VolcanoClient = fakeclient.NewSimpleClientset(
+		&schedulingv1beta2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "scavenger"}},
+		&schedulingv1beta2.Queue{ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-a",
+			Annotations: map[string]string{util.QueueAllowedNamespacesKey: "team-a"},
+		}},
+	)
+
+	testCases := []struct {
+		name      string
+		queue     string
+		expectErr bool
+	}{
+		{name: "open fallback queue", queue: "scavenger"},
+		{name: "fallback queue of another namespace", queue: "team-a", expectErr: true},
+		{name: "missing fallback queue", queue: "missing", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateGangFallbackQueue(tc.queue, "default", authenticationv1.UserInfo{Username: "alice"})
+			if (err != nil) != tc.expectErr {
+				t.Errorf("expected error %t, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestGangFallbackMigration(t *testing.T) {
+	old := newJob()
+	old.Annotations = map[string]string{jobhelpers.GangSchedulingFallbackKey: "queue:scavenger"}
+	migrated := func(queue, fallback string) *v1alpha1.Job {
+		job := old.DeepCopy()
+		job.Spec.Queue = queue
+		job.Annotations[jobhelpers.GangSchedulingFallbackKey] = fallback
+		job.Annotations[jobhelpers.GangSchedulingTimedOutKey] = "2024-06-01T00:00:00Z"
+		return job
+	}
+
+	if !gangFallbackMigration(old, migrated("scavenger", "queue:scavenger")) {
+		t.Errorf("expected the migration to the fallback queue recognized")
+	}
+	if gangFallbackMigration(old, migrated("other", "queue:scavenger")) {
+		t.Errorf("expected the migration to another queue checked")
+	}
+	if gangFallbackMigration(old, migrated("other", "queue:other")) {
+		t.Errorf("expected the migration to a fallback queue changed in the same update checked")
+	}
+	timedOut := migrated("default", "queue:scavenger")
+	if gangFallbackMigration(timedOut, migrated("scavenger", "queue:scavenger")) {
+		t.Errorf("expected the migration of a job whose fallback was applied checked")
+	}
+}
+
 func newJob() *v1alpha1.Job {
 	return &v1alpha1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -20,13 +20,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	whv1 "k8s.io/api/admissionregistration/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/webhooks/router"
 	"volcano.sh/volcano/pkg/webhooks/schema"
 	"volcano.sh/volcano/pkg/webhooks/util"
@@ -45,7 +49,7 @@ var service = &router.AdmissionService{
 			Name: "mutatepodgroup.volcano.sh",
 			Rules: []whv1.RuleWithOperations{
 				{
-					Operations: []whv1.OperationType{whv1.Create},
+					Operations: []whv1.OperationType{whv1.Create, whv1.Update},
 					Rule: whv1.Rule{
 						APIGroups:   []string{schedulingv1beta1.SchemeGroupVersion.Group},
 						APIVersions: []string{schedulingv1beta1.SchemeGroupVersion.Version},
@@ -77,10 +81,17 @@ func PodGroups(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 	var patchBytes []byte
 	switch ar.Request.Operation {
 	case admissionv1.Create:
-		patchBytes, err = createPodGroupPatch(podgroup)
+		patchBytes, err = createPodGroupPatch(podgroup, ar.Request.UserInfo)
+	case admissionv1.Update:
+		var oldPodgroup *schedulingv1beta1.PodGroup
+		oldPodgroup, err = schema.DecodePodGroup(ar.Request.OldObject, ar.Request.Resource)
+		if err != nil {
+			return util.ToAdmissionResponse(err)
+		}
+		patchBytes, err = updatePodGroupPatch(oldPodgroup, podgroup, ar.Request.UserInfo)
 	default:
 		return util.ToAdmissionResponse(fmt.Errorf("invalid operation `%s`, "+
-			"expect operation to be `CREATE`", ar.Request.Operation))
+			"expect operation to be `CREATE` or `UPDATE`", ar.Request.Operation))
 	}
 
 	if err != nil {
@@ -101,10 +112,14 @@ func PodGroups(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 	return &reviewResponse
 }
 
-func createPodGroupPatch(podgroup *schedulingv1beta1.PodGroup) ([]byte, error) {
-	var patch []patchOperation
-	if len(podgroup.Spec.Queue) == 0 {
-		queueName := schedulingv1beta1.DefaultQueue
+func createPodGroupPatch(podgroup *schedulingv1beta1.PodGroup, userInfo authenticationv1.UserInfo) ([]byte, error) {
+	patch, err := priorityBoostPatch(nil, podgroup, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	queueName := podgroup.Spec.Queue
+	if len(queueName) == 0 {
+		queueName = schedulingv1beta1.DefaultQueue
 		ns, err := config.KubeClient.CoreV1().Namespaces().Get(context.TODO(), podgroup.Namespace, metav1.GetOptions{})
 		if err == nil {
 			if val, ok := ns.GetAnnotations()[schedulingv1beta1.QueueNameAnnotationKey]; ok {
@@ -117,6 +132,55 @@ func createPodGroupPatch(podgroup *schedulingv1beta1.PodGroup) ([]byte, error) {
 			Value: queueName,
 		})
 	}
+	if err := checkQueueAccess(podgroup, queueName, userInfo); err != nil {
+		return nil, err
+	}
 
 	return json.Marshal(patch)
 }
+
+func updatePodGroupPatch(old, podgroup *schedulingv1beta1.PodGroup, userInfo authenticationv1.UserInfo) ([]byte, error) {
+	patch, err := priorityBoostPatch(old.Annotations, podgroup, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	if podgroup.Spec.Queue != old.Spec.Queue {
+		if err := checkQueueAccess(podgroup, podgroup.Spec.Queue, userInfo); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(patch)
+}
+
+// checkQueueAccess checks whether the user may submit the podgroup to the queue. The queues not
+// created yet are left to the scheduler, which does not schedule their podgroups.
+func checkQueueAccess(podgroup *schedulingv1beta1.PodGroup, queueName string, userInfo authenticationv1.UserInfo) error {
+	queue, err := config.VolcanoClient.SchedulingV1beta1().Queues().Get(context.TODO(), queueName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get queue `%s` of podgroup <%s/%s>: %v", queueName, podgroup.Namespace, podgroup.Name, err)
+	}
+	return util.CheckObjectQueueAccess(queue, podgroup.Namespace, podgroup.OwnerReferences, userInfo, config.ControllerServiceAccounts)
+}
+
+// priorityBoostPatch rejects the boosts by the users not allowed to boost the jobs of the
+// namespace, and records in the audit of the allowed boosts the user who requested them.
+func priorityBoostPatch(oldAnnotations map[string]string, podgroup *schedulingv1beta1.PodGroup, userInfo authenticationv1.UserInfo) ([]patchOperation, error) {
+	if !util.PriorityBoostChanged(oldAnnotations, podgroup.Annotations) {
+		return nil, nil
+	}
+	if err := util.CheckPriorityBoost(config.KubeClient, podgroup.Namespace, userInfo); err != nil {
+		return nil, err
+	}
+	audit, found, err := util.PriorityBoostAudit(podgroup.Annotations, userInfo)
+	if err != nil || !found {
+		return nil, err
+	}
+	return []patchOperation{{
+		Op:    "replace",
+		Path:  "/metadata/annotations/" + strings.ReplaceAll(schedulingapi.JobPriorityBoostAudit, "/", "~1"),
+		Value: audit,
+	}}, nil
+}
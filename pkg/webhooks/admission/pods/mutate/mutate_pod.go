@@ -101,12 +101,15 @@ func Pods(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 
 // createPatch patch pod
 func createPatch(pod *v1.Pod) ([]byte, error) {
+	patch := patchQueueDefaults(pod)
 	if config.ConfigData == nil {
 		klog.V(5).Infof("admission configuration is empty.")
-		return nil, nil
+		if len(patch) == 0 {
+			return nil, nil
+		}
+		return json.Marshal(patch)
 	}
 
-	var patch []patchOperation
 	config.ConfigData.Lock()
 	defer config.ConfigData.Unlock()
 
@@ -23,8 +23,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	schedulinglisters "volcano.sh/apis/pkg/client/listers/scheduling/v1beta1"
 
 	webconfig "volcano.sh/volcano/pkg/webhooks/config"
+	"volcano.sh/volcano/pkg/webhooks/util"
 )
 
 func TestMutatePods(t *testing.T) {
@@ -216,3 +221,87 @@ func TestMutatePods(t *testing.T) {
 		})
 	}
 }
+
+func TestPatchQueueDefaults(t *testing.T) {
+	queue := &schedulingv1beta1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gpu-pool",
+			Annotations: map[string]string{
+				util.QueuePodDefaultsKey: `{"labels":{"pool":"gpu","team":"default"},` +
+					`"tolerations":[{"key":"pool","operator":"Equal","value":"gpu","effect":"NoSchedule"}]}`,
+			},
+		},
+	}
+	pg := &schedulingv1beta1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg1"},
+		Spec:       schedulingv1beta1.PodGroupSpec{Queue: "gpu-pool"},
+	}
+	queues := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	podGroups := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := queues.Add(queue); err != nil {
+		t.Fatalf("failed to add the queue: %v", err)
+	}
+	if err := podGroups.Add(pg); err != nil {
+		t.Fatalf("failed to add the podgroup: %v", err)
+	}
+	config.QueueLister = schedulinglisters.NewQueueLister(queues)
+	config.PodGroupLister = schedulinglisters.NewPodGroupLister(podGroups)
+	defer func() {
+		config.QueueLister = nil
+		config.PodGroupLister = nil
+	}()
+
+	toleration := v1.Toleration{Key: "pool", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: v1.TaintEffectNoSchedule}
+	testCases := []struct {
+		name   string
+		pod    *v1.Pod
+		expect []patchOperation
+	}{
+		{
+			name: "pod of the podgroup of the queue",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ns",
+					Name:        "p1",
+					Labels:      map[string]string{"team": "a"},
+					Annotations: map[string]string{schedulingv1beta1.KubeGroupNameAnnotationKey: "pg1"},
+				},
+			},
+			expect: []patchOperation{
+				{Op: "add", Path: "/metadata/labels", Value: map[string]string{"pool": "gpu", "team": "a"}},
+				{Op: "add", Path: "/spec/tolerations", Value: []v1.Toleration{toleration}},
+			},
+		},
+		{
+			name: "pod already tolerating the pool",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ns",
+					Name:        "p2",
+					Labels:      map[string]string{"pool": "gpu", "team": "a"},
+					Annotations: map[string]string{schedulingv1beta1.QueueNameAnnotationKey: "gpu-pool"},
+				},
+				Spec: v1.PodSpec{Tolerations: []v1.Toleration{toleration}},
+			},
+			expect: nil,
+		},
+		{
+			name: "pod without queue",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p3"},
+			},
+			expect: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			patch := patchQueueDefaults(testCase.pod)
+			patchBytes, _ := json.Marshal(patch)
+			expectBytes, _ := json.Marshal(testCase.expect)
+			if string(patchBytes) != string(expectBytes) {
+				t.Errorf("expected patch %s, got %s", expectBytes, patchBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/klog/v2"
+
+	batch "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+
+	"volcano.sh/volcano/pkg/webhooks/util"
+)
+
+// podQueue returns the queue of the pod: the one set by the job controller or by the user, else
+// the one of its podgroup.
+func podQueue(pod *v1.Pod) string {
+	if queue := pod.Annotations[batch.QueueNameKey]; len(queue) != 0 {
+		return queue
+	}
+	if queue := pod.Annotations[vcv1beta1.QueueNameAnnotationKey]; len(queue) != 0 {
+		return queue
+	}
+	pgName := pod.Annotations[vcv1beta1.KubeGroupNameAnnotationKey]
+	if len(pgName) == 0 {
+		return ""
+	}
+	pg, err := config.PodGroupLister.PodGroups(pod.Namespace).Get(pgName)
+	if err != nil {
+		klog.V(4).Infof("Failed to get podgroup <%s/%s> of pod %s: %v", pod.Namespace, pgName, pod.Name, err)
+		return ""
+	}
+	return pg.Spec.Queue
+}
+
+// patchQueueDefaults patches the pod with the pod defaults of its queue, and applies them to
+// the pod so that the patches computed next include them.
+func patchQueueDefaults(pod *v1.Pod) []patchOperation {
+	if config.PodGroupLister == nil || config.QueueLister == nil {
+		return nil
+	}
+	queueName := podQueue(pod)
+	if len(queueName) == 0 {
+		return nil
+	}
+	queue, err := config.QueueLister.Get(queueName)
+	if err != nil {
+		klog.V(4).Infof("Failed to get queue %s of pod <%s/%s>: %v", queueName, pod.Namespace, pod.Name, err)
+		return nil
+	}
+	value, found := queue.Annotations[util.QueuePodDefaultsKey]
+	if !found {
+		return nil
+	}
+	defaults, err := util.ParsePodDefaults(value)
+	if err != nil {
+		klog.Errorf("Invalid pod defaults of queue %s: %v", queueName, err)
+		return nil
+	}
+
+	var patch []patchOperation
+	if labels, changed := mergeDefaults(pod.Labels, defaults.Labels); changed {
+		pod.Labels = labels
+		patch = append(patch, patchOperation{Op: "add", Path: "/metadata/labels", Value: labels})
+	}
+	if annotations, changed := mergeDefaults(pod.Annotations, defaults.Annotations); changed {
+		pod.Annotations = annotations
+		patch = append(patch, patchOperation{Op: "add", Path: "/metadata/annotations", Value: annotations})
+	}
+	if tolerations, changed := mergeTolerations(pod.Spec.Tolerations, defaults.Tolerations); changed {
+		pod.Spec.Tolerations = tolerations
+		patch = append(patch, patchOperation{Op: "add", Path: "/spec/tolerations", Value: tolerations})
+	}
+	return patch
+}
+
+// mergeDefaults returns the values with the defaults they miss, and whether some were missed.
+func mergeDefaults(values, defaults map[string]string) (map[string]string, bool) {
+	merged := make(map[string]string, len(values)+len(defaults))
+	for key, value := range values {
+		merged[key] = value
+	}
+	changed := false
+	for key, value := range defaults {
+		if _, found := merged[key]; !found {
+			merged[key] = value
+			changed = true
+		}
+	}
+	return merged, changed
+}
+
+func mergeTolerations(tolerations, defaults []v1.Toleration) ([]v1.Toleration, bool) {
+	merged := append([]v1.Toleration{}, tolerations...)
+	changed := false
+	for _, d := range defaults {
+		found := false
+		for i := range tolerations {
+			if equality.Semantic.DeepEqual(tolerations[i], d) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, d)
+			changed = true
+		}
+	}
+	return merged, changed
+}
@@ -25,14 +25,17 @@ import (
 
 	admissionv1 "k8s.io/api/admission/v1"
 	whv1 "k8s.io/api/admissionregistration/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
 
 	"volcano.sh/apis/pkg/apis/helpers"
 	vcv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
 	"volcano.sh/volcano/pkg/webhooks/router"
 	"volcano.sh/volcano/pkg/webhooks/schema"
 	"volcano.sh/volcano/pkg/webhooks/util"
@@ -82,7 +85,7 @@ func AdmitPods(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 
 	switch ar.Request.Operation {
 	case admissionv1.Create:
-		msg = validatePod(pod, &reviewResponse)
+		msg = validatePod(pod, ar.Request.UserInfo, &reviewResponse)
 	default:
 		err := fmt.Errorf("expect operation to be 'CREATE'")
 		return util.ToAdmissionResponse(err)
@@ -99,14 +102,20 @@ allow pods to create when
 1. schedulerName of pod isn't volcano
 2. normal pods whose schedulerName is volcano don't have podgroup.
 3. check pod budget annotations configure
+4. gpu requests of pods whose schedulerName is volcano can be fulfilled by gpu sharing
 */
-func validatePod(pod *v1.Pod, reviewResponse *admissionv1.AdmissionResponse) string {
+func validatePod(pod *v1.Pod, userInfo authenticationv1.UserInfo, reviewResponse *admissionv1.AdmissionResponse) string {
 	if !slices.Contains(config.SchedulerNames, pod.Spec.SchedulerName) {
 		return ""
 	}
 	pgName := ""
 	msg := ""
 
+	if err := validateGPURequest(pod); err != nil {
+		reviewResponse.Allowed = false
+		return err.Error()
+	}
+
 	// vc-job, SN == volcano
 	if pod.Annotations != nil {
 		pgName = pod.Annotations[vcv1beta1.KubeGroupNameAnnotationKey]
@@ -115,7 +124,7 @@ func validatePod(pod *v1.Pod, reviewResponse *admissionv1.AdmissionResponse) str
 		if err := checkPG(pod, pgName, true); err != nil {
 			msg = err.Error()
 			reviewResponse.Allowed = false
-		} else if err := checkPGQueueState(pod, pgName); err != nil {
+		} else if err := checkPGQueueState(pod, pgName, userInfo); err != nil {
 			msg = err.Error()
 			reviewResponse.Allowed = false
 		}
@@ -123,7 +132,7 @@ func validatePod(pod *v1.Pod, reviewResponse *admissionv1.AdmissionResponse) str
 	}
 	if pod.Annotations != nil && pod.Annotations[vcv1beta1.QueueNameAnnotationKey] != "" {
 		queueName := pod.Annotations[vcv1beta1.QueueNameAnnotationKey]
-		if err := checkQueueState(queueName); err != nil {
+		if err := checkQueueState(pod, queueName, userInfo); err != nil {
 			msg = err.Error()
 			reviewResponse.Allowed = false
 			return msg
@@ -156,17 +165,19 @@ func checkPG(pod *v1.Pod, pgName string, isVCJob bool) error {
 	return nil
 }
 
-func checkPGQueueState(pod *v1.Pod, pgName string) error {
+func checkPGQueueState(pod *v1.Pod, pgName string, userInfo authenticationv1.UserInfo) error {
 	pgObj, err := config.VolcanoClient.SchedulingV1beta1().PodGroups(pod.Namespace).Get(context.TODO(), pgName, metav1.GetOptions{})
 	if err == nil {
-		if errQueue := checkQueueState(pgObj.Spec.Queue); errQueue != nil {
+		if errQueue := checkQueueState(pod, pgObj.Spec.Queue, userInfo); errQueue != nil {
 			return fmt.Errorf("failed : %v", errQueue)
 		}
 	}
 	return nil
 }
 
-func checkQueueState(queueName string) error {
+// checkQueueState checks that the queue of the pod is open and that the user creating the pod
+// may submit it to the queue.
+func checkQueueState(pod *v1.Pod, queueName string, userInfo authenticationv1.UserInfo) error {
 	if queueName == "" {
 		return nil
 	}
@@ -177,6 +188,9 @@ func checkQueueState(queueName string) error {
 		return fmt.Errorf(" can only submit job to queue with state `Open`, "+
 			"queue `%s` status is `%s`;", queue.Name, queue.Status.State)
 	}
+	if err := util.CheckObjectQueueAccess(queue, pod.Namespace, pod.OwnerReferences, userInfo, config.ControllerServiceAccounts); err != nil {
+		return fmt.Errorf(" %v;", err)
+	}
 	return nil
 }
 
@@ -203,6 +217,51 @@ func validateAnnotation(pod *v1.Pod) error {
 	return nil
 }
 
+// validateGPURequest rejects the pods which would stay pending forever because of their gpu requests:
+// whole cards and gpu memory requested together, or more gpu memory than any card of the cluster.
+func validateGPURequest(pod *v1.Pod) error {
+	number := gpushare.GetGPUNumberOfPod(pod)
+	memory := gpushare.GetGPUMemoryOfPod(pod)
+	if number > 0 && memory > 0 {
+		return fmt.Errorf("pod <%s/%s> requests both %d whole gpus with %s and %d gpu memory with %s, "+
+			"request either whole gpus or gpu memory of a shared gpu", pod.Namespace, pod.Name,
+			number, gpushare.VolcanoGPUNumber, memory, gpushare.VolcanoGPUResource)
+	}
+	if memory == 0 {
+		return nil
+	}
+
+	// fail closed, the pod would otherwise be admitted without the check
+	if config.NodeLister == nil {
+		return fmt.Errorf("failed to validate the gpu memory of pod <%s/%s>: the nodes are not cached", pod.Namespace, pod.Name)
+	}
+	nodes, err := config.NodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list nodes to validate the gpu memory of pod <%s/%s>: %v", pod.Namespace, pod.Name, err)
+	}
+	var maxCardMemory int64
+	for _, node := range nodes {
+		total, found := node.Status.Capacity[gpushare.VolcanoGPUResource]
+		if !found {
+			continue
+		}
+		cards, found := node.Status.Capacity[gpushare.VolcanoGPUNumber]
+		if !found || cards.Value() == 0 {
+			continue
+		}
+		if cardMemory := total.Value() / cards.Value(); cardMemory > maxCardMemory {
+			maxCardMemory = cardMemory
+		}
+	}
+	// the size of the cards is unknown until a node reports them
+	if maxCardMemory > 0 && int64(memory) > maxCardMemory {
+		return fmt.Errorf("pod <%s/%s> requests %d gpu memory with %s, more than the %d of the largest gpu in the cluster, "+
+			"lower the request or request whole gpus with %s", pod.Namespace, pod.Name,
+			memory, gpushare.VolcanoGPUResource, maxCardMemory, gpushare.VolcanoGPUNumber)
+	}
+	return nil
+}
+
 func recordEvent(err error) {
 	config.Recorder.Eventf(nil, v1.EventTypeWarning, "Admit", "Create pod failed due to %v", err)
 }
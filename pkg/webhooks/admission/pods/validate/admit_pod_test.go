@@ -22,11 +22,17 @@ import (
 	"testing"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	vcschedulingv1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	vcclient "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+	"volcano.sh/volcano/pkg/scheduler/api/devices/nvidia/gpushare"
+	"volcano.sh/volcano/pkg/webhooks/util"
 )
 
 func TestValidatePod(t *testing.T) {
@@ -35,14 +41,15 @@ func TestValidatePod(t *testing.T) {
 	pgName := "podgroup-p1"
 
 	testCases := []struct {
-		Name           string
-		Pod            v1.Pod
-		ExpectErr      bool
-		reviewResponse admissionv1.AdmissionResponse
-		ret            string
-		disabledPG     bool
-		queueName      string
-		queueState     vcschedulingv1.QueueState
+		Name             string
+		Pod              v1.Pod
+		ExpectErr        bool
+		reviewResponse   admissionv1.AdmissionResponse
+		ret              string
+		disabledPG       bool
+		queueName        string
+		queueState       vcschedulingv1.QueueState
+		queueAnnotations map[string]string
 	}{
 		// validate normal pod with default-scheduler
 		{
@@ -112,6 +119,31 @@ func TestValidatePod(t *testing.T) {
 			queueState:     vcschedulingv1.QueueStateClosed,
 			queueName:      "queue-closed",
 		},
+		// validate volcano pod submitted directly to a queue its namespace is not allowed in
+		{
+			Name: "validate pod when its namespace is not allowed in the queue",
+			Pod: v1.Pod{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Pod",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   namespace,
+					Name:        "volcano-pod-restricted",
+					Annotations: map[string]string{vcschedulingv1.QueueNameAnnotationKey: "queue-restricted"},
+				},
+				Spec: v1.PodSpec{
+					SchedulerName: "volcano",
+				},
+			},
+
+			reviewResponse:   admissionv1.AdmissionResponse{Allowed: false},
+			ret:              "namespace `test` is not allowed to submit jobs to queue `queue-restricted`",
+			ExpectErr:        true,
+			queueState:       vcschedulingv1.QueueStateOpen,
+			queueName:        "queue-restricted",
+			queueAnnotations: map[string]string{util.QueueAllowedNamespacesKey: "other"},
+		},
 		// validate volcano pod with volcano scheduler when queue is Open when no pg
 		{
 			Name: "validate pod when volcano queue is open when no pg",
@@ -248,7 +280,8 @@ func TestValidatePod(t *testing.T) {
 		}
 		queue := vcschedulingv1.Queue{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: testCase.queueName,
+				Name:        testCase.queueName,
+				Annotations: testCase.queueAnnotations,
 			},
 			Spec: vcschedulingv1.QueueSpec{
 				Weight: 1,
@@ -277,7 +310,7 @@ func TestValidatePod(t *testing.T) {
 			}
 		}
 
-		ret := validatePod(&testCase.Pod, &testCase.reviewResponse)
+		ret := validatePod(&testCase.Pod, authenticationv1.UserInfo{}, &testCase.reviewResponse)
 
 		if testCase.ExpectErr == true && ret == "" {
 			t.Errorf("%s: test case Expect error msg :%s, but got nil.", testCase.Name, testCase.ret)
@@ -297,3 +330,73 @@ func TestValidatePod(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateGPURequest(t *testing.T) {
+	buildGPUPod := func(limits v1.ResourceList) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "gpu-pod"},
+			Spec: v1.PodSpec{
+				SchedulerName: "volcano",
+				Containers:    []v1.Container{{Resources: v1.ResourceRequirements{Limits: limits}}},
+			},
+		}
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				gpushare.VolcanoGPUResource: resource.MustParse("64000"),
+				gpushare.VolcanoGPUNumber:   resource.MustParse("4"),
+			},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(node)
+	config.NodeLister = listersv1.NewNodeLister(indexer)
+	defer func() { config.NodeLister = nil }()
+
+	testCases := []struct {
+		name   string
+		pod    *v1.Pod
+		errMsg string
+	}{
+		{
+			name: "gpu memory of a shared gpu",
+			pod:  buildGPUPod(v1.ResourceList{gpushare.VolcanoGPUResource: resource.MustParse("8000")}),
+		},
+		{
+			name: "whole gpus",
+			pod:  buildGPUPod(v1.ResourceList{gpushare.VolcanoGPUNumber: resource.MustParse("2")}),
+		},
+		{
+			name: "whole gpus and gpu memory",
+			pod: buildGPUPod(v1.ResourceList{
+				gpushare.VolcanoGPUNumber:   resource.MustParse("1"),
+				gpushare.VolcanoGPUResource: resource.MustParse("8000"),
+			}),
+			errMsg: "request either whole gpus or gpu memory",
+		},
+		{
+			name:   "gpu memory larger than any gpu",
+			pod:    buildGPUPod(v1.ResourceList{gpushare.VolcanoGPUResource: resource.MustParse("20000")}),
+			errMsg: "more than the 16000 of the largest gpu",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := validateGPURequest(testCase.pod)
+			if testCase.errMsg == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if testCase.errMsg != "" && (err == nil || !strings.Contains(err.Error(), testCase.errMsg)) {
+				t.Errorf("expected error containing %q, got %v", testCase.errMsg, err)
+			}
+		})
+	}
+
+	config.NodeLister = nil
+	if err := validateGPURequest(buildGPUPod(v1.ResourceList{gpushare.VolcanoGPUResource: resource.MustParse("8000")})); err == nil {
+		t.Errorf("expected the gpu memory request rejected without the node cache")
+	}
+}
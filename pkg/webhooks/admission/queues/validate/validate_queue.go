@@ -29,6 +29,7 @@ import (
 	"k8s.io/klog/v2"
 
 	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/webhooks/router"
 	"volcano.sh/volcano/pkg/webhooks/schema"
 	"volcano.sh/volcano/pkg/webhooks/util"
@@ -101,6 +102,10 @@ func validateQueue(queue *schedulingv1beta1.Queue) error {
 	errs = append(errs, validateStateOfQueue(queue.Status.State, resourcePath.Child("spec").Child("state"))...)
 	errs = append(errs, validateWeightOfQueue(queue.Spec.Weight, resourcePath.Child("spec").Child("weight"))...)
 	errs = append(errs, validateHierarchicalAttributes(queue, resourcePath.Child("metadata").Child("annotations"))...)
+	errs = append(errs, validateAccessAttributes(queue, resourcePath.Child("metadata").Child("annotations"))...)
+	errs = append(errs, validateQuotaSchedule(queue, resourcePath.Child("metadata").Child("annotations"))...)
+	errs = append(errs, validatePodDefaults(queue, resourcePath.Child("metadata").Child("annotations"))...)
+	errs = append(errs, validateIsolation(queue, resourcePath.Child("metadata").Child("annotations"))...)
 
 	if len(errs) > 0 {
 		return errs.ToAggregate()
@@ -164,6 +169,72 @@ func validateHierarchicalAttributes(queue *schedulingv1beta1.Queue, fldPath *fie
 	return errs
 }
 
+func validateAccessAttributes(queue *schedulingv1beta1.Queue, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	value, found := queue.Annotations[util.QueueAllowedServiceAccountsKey]
+	if !found {
+		return errs
+	}
+
+	for _, sa := range util.ParseQueueAccessList(value) {
+		parts := strings.SplitN(sa, ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			errs = append(errs, field.Invalid(fldPath.Key(util.QueueAllowedServiceAccountsKey), sa,
+				"service account must be in the form of `namespace:name` or `namespace:*`"))
+		}
+	}
+	return errs
+}
+
+func validateQuotaSchedule(queue *schedulingv1beta1.Queue, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	value, found := queue.Annotations[schedulingapi.QuotaScheduleAnnotationKey]
+	if !found {
+		return errs
+	}
+
+	if _, err := schedulingapi.ParseQuotaSchedule(value); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Key(schedulingapi.QuotaScheduleAnnotationKey), value,
+			fmt.Sprintf("invalid quota schedule: %v", err)))
+	}
+	return errs
+}
+
+func validatePodDefaults(queue *schedulingv1beta1.Queue, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	value, found := queue.Annotations[util.QueuePodDefaultsKey]
+	if !found {
+		return errs
+	}
+
+	if _, err := util.ParsePodDefaults(value); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Key(util.QueuePodDefaultsKey), value,
+			fmt.Sprintf("invalid pod defaults: %v", err)))
+	}
+	return errs
+}
+
+func validateIsolation(queue *schedulingv1beta1.Queue, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	value, found := queue.Annotations[schedulingapi.QueueIsolation]
+	if !found {
+		return errs
+	}
+
+	switch value {
+	case schedulingapi.QueueIsolationElastic:
+	case schedulingapi.QueueIsolationStrict:
+		if len(queue.Spec.Deserved) == 0 {
+			errs = append(errs, field.Invalid(fldPath.Key(schedulingapi.QueueIsolation), value,
+				"a strict queue must set its deserved resource"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(fldPath.Key(schedulingapi.QueueIsolation), value,
+			[]string{schedulingapi.QueueIsolationElastic, schedulingapi.QueueIsolationStrict}))
+	}
+	return errs
+}
+
 func validateStateOfQueue(value schedulingv1beta1.QueueState, fldPath *field.Path) field.ErrorList {
 	errs := field.ErrorList{}
 
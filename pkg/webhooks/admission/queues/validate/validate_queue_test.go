@@ -23,13 +23,16 @@ import (
 	"testing"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	fakeclient "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/webhooks/util"
 )
 
@@ -911,3 +914,32 @@ func TestAdmitQueues(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateIsolation(t *testing.T) {
+	deserved := v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}
+	testCases := []struct {
+		Name     string
+		Isolated string
+		Deserved v1.ResourceList
+		Valid    bool
+	}{
+		{Name: "not set", Valid: true},
+		{Name: "elastic", Isolated: schedulingapi.QueueIsolationElastic, Valid: true},
+		{Name: "strict", Isolated: schedulingapi.QueueIsolationStrict, Deserved: deserved, Valid: true},
+		{Name: "strict without deserved", Isolated: schedulingapi.QueueIsolationStrict},
+		{Name: "unknown", Isolated: "hard", Deserved: deserved},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			queue := &schedulingv1beta1.Queue{Spec: schedulingv1beta1.QueueSpec{Deserved: testCase.Deserved}}
+			if testCase.Isolated != "" {
+				queue.Annotations = map[string]string{schedulingapi.QueueIsolation: testCase.Isolated}
+			}
+			errs := validateIsolation(queue, field.NewPath("metadata").Child("annotations"))
+			if valid := len(errs) == 0; valid != testCase.Valid {
+				t.Errorf("expected valid %v, got errors %v", testCase.Valid, errs)
+			}
+		})
+	}
+}
@@ -20,9 +20,11 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	whv1 "k8s.io/api/admissionregistration/v1"
 	"k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
 
 	"volcano.sh/apis/pkg/client/clientset/versioned"
+	schedulinglisters "volcano.sh/apis/pkg/client/listers/scheduling/v1beta1"
 	"volcano.sh/volcano/pkg/webhooks/config"
 )
 
@@ -35,6 +37,14 @@ type AdmissionServiceConfig struct {
 	VolcanoClient  versioned.Interface
 	Recorder       record.EventRecorder
 	ConfigData     *config.AdmissionConfiguration
+	// NodeLister lists the nodes from the cache of the webhook manager
+	NodeLister listersv1.NodeLister
+	// PodGroupLister and QueueLister list the podgroups and the queues from the cache of the webhook manager
+	PodGroupLister schedulinglisters.PodGroupLister
+	QueueLister    schedulinglisters.QueueLister
+	// ControllerServiceAccounts are the service accounts of the controllers trusted to create the
+	// objects of the users, in the form of `namespace:name` or `namespace:*`
+	ControllerServiceAccounts []string
 }
 
 type AdmissionService struct {
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// PriorityBoostVerb is the verb of the podgroups the users must be granted to boost the jobs of
+// a namespace, e.g. by a Role with the rule `verbs: ["boost"]` on `podgroups` of the
+// scheduling.volcano.sh group.
+const PriorityBoostVerb = "boost"
+
+// priorityBoostAnnotations are the annotations only the users allowed to boost jobs may set.
+var priorityBoostAnnotations = []string{
+	schedulingapi.JobPriorityBoost,
+	schedulingapi.JobPriorityBoostExpiry,
+	schedulingapi.JobPriorityBoostAudit,
+}
+
+// PriorityBoostChanged returns whether the boost annotations differ between old and new, any of
+// them set on a new object is a change.
+func PriorityBoostChanged(old, new map[string]string) bool {
+	for _, key := range priorityBoostAnnotations {
+		oldValue, oldFound := old[key]
+		newValue, newFound := new[key]
+		if oldFound != newFound || oldValue != newValue {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPriorityBoost checks whether the user may boost the jobs of the namespace.
+func CheckPriorityBoost(client kubernetes.Interface, namespace string, userInfo authenticationv1.UserInfo) error {
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for key, value := range userInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   userInfo.Username,
+			Groups: userInfo.Groups,
+			UID:    userInfo.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      PriorityBoostVerb,
+				Group:     schedulingv1beta1.SchemeGroupVersion.Group,
+				Resource:  "podgroups",
+			},
+		},
+	}
+	result, err := client.AuthorizationV1().SubjectAccessReviews().Create(context.TODO(), review, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to check whether user `%s` may boost jobs: %v", userInfo.Username, err)
+	}
+	if !result.Status.Allowed {
+		return fmt.Errorf("user `%s` is not allowed to set the annotation `%s` in namespace `%s`",
+			userInfo.Username, schedulingapi.JobPriorityBoost, namespace)
+	}
+	return nil
+}
+
+// PriorityBoostAudit returns the boost audit record of the annotations with its user set to the
+// user who requested the boost, or false if there is no record.
+func PriorityBoostAudit(annotations map[string]string, userInfo authenticationv1.UserInfo) (string, bool, error) {
+	value, found := annotations[schedulingapi.JobPriorityBoostAudit]
+	if !found {
+		return "", false, nil
+	}
+	audit := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(value), &audit); err != nil {
+		return "", false, fmt.Errorf("invalid annotation `%s`: %v", schedulingapi.JobPriorityBoostAudit, err)
+	}
+	audit["user"] = userInfo.Username
+	data, err := json.Marshal(audit)
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+)
+
+func TestCheckPriorityBoost(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "admin" && attributes.Verb == PriorityBoostVerb &&
+			attributes.Resource == "podgroups" && attributes.Namespace == "ns1"
+		return true, review, nil
+	})
+
+	if err := CheckPriorityBoost(client, "ns1", authenticationv1.UserInfo{Username: "admin"}); err != nil {
+		t.Errorf("expected the admin to be allowed to boost jobs, got %v", err)
+	}
+	if err := CheckPriorityBoost(client, "ns1", authenticationv1.UserInfo{Username: "user"}); err == nil {
+		t.Errorf("expected the user not to be allowed to boost jobs")
+	}
+}
+
+func TestPriorityBoostChanged(t *testing.T) {
+	boosted := map[string]string{schedulingapi.JobPriorityBoost: "true"}
+	if !PriorityBoostChanged(nil, boosted) {
+		t.Errorf("expected a change when the boost is set")
+	}
+	if PriorityBoostChanged(boosted, map[string]string{schedulingapi.JobPriorityBoost: "true", "other": "value"}) {
+		t.Errorf("expected no change when only the other annotations change")
+	}
+	if !PriorityBoostChanged(boosted, nil) {
+		t.Errorf("expected a change when the boost is removed")
+	}
+}
+
+func TestPriorityBoostAudit(t *testing.T) {
+	annotations := map[string]string{schedulingapi.JobPriorityBoostAudit: `{"user":"forged","reason":"release blocker"}`}
+	value, found, err := PriorityBoostAudit(annotations, authenticationv1.UserInfo{Username: "admin"})
+	if err != nil || !found {
+		t.Fatalf("expected the audit record, got %v %v", found, err)
+	}
+	audit := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &audit); err != nil {
+		t.Fatalf("failed to decode the audit record %s: %v", value, err)
+	}
+	if audit["user"] != "admin" || audit["reason"] != "release blocker" {
+		t.Errorf("expected the audit record of admin, got %v", audit)
+	}
+}
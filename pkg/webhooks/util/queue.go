@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+const (
+	// QueueAllowedNamespacesKey is the queue annotation listing, comma separated, the namespaces
+	// whose jobs may be submitted to the queue.
+	QueueAllowedNamespacesKey = "volcano.sh/allowed-namespaces"
+	// QueueAllowedServiceAccountsKey is the queue annotation listing, comma separated, the service
+	// accounts allowed to submit to the queue, in the form of `namespace:name`; `namespace:*`
+	// allows all service accounts of the namespace.
+	QueueAllowedServiceAccountsKey = "volcano.sh/allowed-service-accounts"
+	// QueuePodDefaultsKey is the queue annotation holding, in JSON, the PodDefaults injected
+	// into the pods of the jobs submitted to the queue.
+	QueuePodDefaultsKey = "volcano.sh/pod-defaults"
+)
+
+// PodDefaults are the labels, annotations and tolerations of the pods of a queue. The labels
+// and annotations the pods set themselves are kept.
+type PodDefaults struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Tolerations []v1.Toleration   `json:"tolerations,omitempty"`
+}
+
+// ParsePodDefaults parses the value of the QueuePodDefaultsKey annotation.
+func ParsePodDefaults(value string) (*PodDefaults, error) {
+	defaults := &PodDefaults{}
+	if err := json.Unmarshal([]byte(value), defaults); err != nil {
+		return nil, err
+	}
+	return defaults, nil
+}
+
+// ParseQueueAccessList splits a comma separated annotation value, empty entries are dropped.
+func ParseQueueAccessList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) != 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// CheckQueueAccess checks whether the user may submit a job of the namespace to the queue.
+// A queue without access annotations is open to everyone; when both annotations are set,
+// both the namespace and the service account must be allowed.
+func CheckQueueAccess(queue *schedulingv1beta1.Queue, namespace string, userInfo authenticationv1.UserInfo) error {
+	if err := checkQueueNamespaceAccess(queue, namespace); err != nil {
+		return err
+	}
+
+	if value, found := queue.Annotations[QueueAllowedServiceAccountsKey]; found {
+		saNamespace, saName, err := serviceaccount.SplitUsername(userInfo.Username)
+		if err != nil {
+			return fmt.Errorf("user `%s` is not allowed to submit jobs to queue `%s`, only service accounts are allowed",
+				userInfo.Username, queue.Name)
+		}
+		allowed := false
+		for _, sa := range ParseQueueAccessList(value) {
+			parts := strings.SplitN(sa, ":", 2)
+			if len(parts) == 2 && parts[0] == saNamespace && (parts[1] == "*" || parts[1] == saName) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("service account `%s:%s` is not allowed to submit jobs to queue `%s`",
+				saNamespace, saName, queue.Name)
+		}
+	}
+
+	return nil
+}
+
+// CheckObjectQueueAccess checks whether the podgroup or pod of the namespace, with the owners,
+// created by the user may target the queue. The objects created by a controller, e.g. the
+// podgroup of a job or the pods of a ReplicaSet, are created by the service account of the
+// controller rather than by the user who submitted them: only their namespace is checked. The
+// owners are given by the creator of the object, so they are only trusted when the user is one of
+// the controllers, see IsController.
+func CheckObjectQueueAccess(queue *schedulingv1beta1.Queue, namespace string, owners []metav1.OwnerReference, userInfo authenticationv1.UserInfo, controllers []string) error {
+	if IsController(userInfo, controllers) {
+		for _, owner := range owners {
+			if owner.Controller != nil && *owner.Controller {
+				return checkQueueNamespaceAccess(queue, namespace)
+			}
+		}
+	}
+	return CheckQueueAccess(queue, namespace, userInfo)
+}
+
+// IsController returns whether the user is the kube-controller-manager, or one of the service
+// accounts of the controllers, in the form of `namespace:name` where `namespace:*` matches all
+// the service accounts of the namespace.
+func IsController(userInfo authenticationv1.UserInfo, controllers []string) bool {
+	if userInfo.Username == user.KubeControllerManager {
+		return true
+	}
+	saNamespace, saName, err := serviceaccount.SplitUsername(userInfo.Username)
+	if err != nil {
+		return false
+	}
+	for _, sa := range controllers {
+		parts := strings.SplitN(sa, ":", 2)
+		if len(parts) == 2 && parts[0] == saNamespace && (parts[1] == "*" || parts[1] == saName) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkQueueNamespaceAccess(queue *schedulingv1beta1.Queue, namespace string) error {
+	value, found := queue.Annotations[QueueAllowedNamespacesKey]
+	if !found {
+		return nil
+	}
+	for _, ns := range ParseQueueAccessList(value) {
+		if ns == namespace {
+			return nil
+		}
+	}
+	return fmt.Errorf("namespace `%s` is not allowed to submit jobs to queue `%s`", namespace, queue.Name)
+}
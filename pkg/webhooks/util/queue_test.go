@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+func TestCheckQueueAccess(t *testing.T) {
+	buildQueue := func(annotations map[string]string) *schedulingv1beta1.Queue {
+		return &schedulingv1beta1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1", Annotations: annotations},
+		}
+	}
+	saUser := authenticationv1.UserInfo{Username: "system:serviceaccount:team-a:submitter"}
+
+	testCases := []struct {
+		name      string
+		queue     *schedulingv1beta1.Queue
+		namespace string
+		userInfo  authenticationv1.UserInfo
+		expectErr bool
+	}{
+		{
+			name:      "queue without access annotations is open",
+			queue:     buildQueue(nil),
+			namespace: "team-b",
+			userInfo:  authenticationv1.UserInfo{Username: "alice"},
+		},
+		{
+			name:      "namespace allowed",
+			queue:     buildQueue(map[string]string{QueueAllowedNamespacesKey: "team-a, team-b"}),
+			namespace: "team-b",
+		},
+		{
+			name:      "namespace not allowed",
+			queue:     buildQueue(map[string]string{QueueAllowedNamespacesKey: "team-a"}),
+			namespace: "team-b",
+			expectErr: true,
+		},
+		{
+			name:      "service account allowed by name",
+			queue:     buildQueue(map[string]string{QueueAllowedServiceAccountsKey: "team-a:submitter"}),
+			namespace: "team-a",
+			userInfo:  saUser,
+		},
+		{
+			name:      "service account allowed by wildcard",
+			queue:     buildQueue(map[string]string{QueueAllowedServiceAccountsKey: "team-a:*"}),
+			namespace: "team-a",
+			userInfo:  saUser,
+		},
+		{
+			name:      "service account not allowed",
+			queue:     buildQueue(map[string]string{QueueAllowedServiceAccountsKey: "team-b:*"}),
+			namespace: "team-a",
+			userInfo:  saUser,
+			expectErr: true,
+		},
+		{
+			name:      "plain user rejected when service accounts are required",
+			queue:     buildQueue(map[string]string{QueueAllowedServiceAccountsKey: "team-a:*"}),
+			namespace: "team-a",
+			userInfo:  authenticationv1.UserInfo{Username: "alice"},
+			expectErr: true,
+		},
+		{
+			name: "both namespace and service account must be allowed",
+			queue: buildQueue(map[string]string{
+				QueueAllowedNamespacesKey:      "team-b",
+				QueueAllowedServiceAccountsKey: "team-a:*",
+			}),
+			namespace: "team-a",
+			userInfo:  saUser,
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := CheckQueueAccess(testCase.queue, testCase.namespace, testCase.userInfo)
+			if testCase.expectErr != (err != nil) {
+				t.Errorf("expect error: %v, but got: %v", testCase.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckObjectQueueAccess(t *testing.T) {
+	queue := &schedulingv1beta1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1", Annotations: map[string]string{
+			QueueAllowedNamespacesKey:      "team-a",
+			QueueAllowedServiceAccountsKey: "team-a:submitter",
+		}},
+	}
+	controller := true
+	owners := []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "rs1", Controller: &controller}}
+	controllerUser := authenticationv1.UserInfo{Username: "system:serviceaccount:kube-system:replicaset-controller"}
+	controllers := []string{"kube-system:*"}
+
+	if err := CheckObjectQueueAccess(queue, "team-a", nil, controllerUser, controllers); err == nil {
+		t.Errorf("expected the object created by a service account not allowed to be rejected")
+	}
+	if err := CheckObjectQueueAccess(queue, "team-a", owners, controllerUser, controllers); err != nil {
+		t.Errorf("expected the object of a controller in an allowed namespace to be admitted, got %v", err)
+	}
+	if err := CheckObjectQueueAccess(queue, "team-b", owners, controllerUser, controllers); err == nil {
+		t.Errorf("expected the object of a controller in a namespace not allowed to be rejected")
+	}
+
+	// a user adds a controller reference to its own object
+	spoofing := authenticationv1.UserInfo{Username: "system:serviceaccount:team-a:other"}
+	if err := CheckObjectQueueAccess(queue, "team-a", owners, spoofing, controllers); err == nil {
+		t.Errorf("expected the object with a spoofed controller reference to be rejected")
+	}
+	if err := CheckObjectQueueAccess(queue, "team-a", owners, authenticationv1.UserInfo{Username: "alice"}, controllers); err == nil {
+		t.Errorf("expected the object of a user with a spoofed controller reference to be rejected")
+	}
+	if err := CheckObjectQueueAccess(queue, "team-a", owners, authenticationv1.UserInfo{Username: "system:kube-controller-manager"}, nil); err != nil {
+		t.Errorf("expected the object of the kube-controller-manager to be admitted, got %v", err)
+	}
+}